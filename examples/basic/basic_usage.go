@@ -29,6 +29,7 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error opening document: %v", err)
 	}
+	defer doc2.Close()
 
 	// Add more content
 	doc2.AddParagraph("")
@@ -46,6 +47,7 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error opening document: %v", err)
 	}
+	defer doc3.Close()
 
 	count := doc3.ReplaceText("DocxSmith", "DocxSmith Pro")
 	fmt.Printf("Replaced %d occurrence(s)\n", count)
@@ -97,6 +99,7 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error opening document: %v", err)
 	}
+	defer doc5.Close()
 
 	fmt.Printf("Paragraphs: %d\n", doc5.GetParagraphCount())
 	fmt.Printf("Tables: %d\n", doc5.GetTableCount())
@@ -108,6 +111,7 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error opening document: %v", err)
 	}
+	defer doc6.Close()
 
 	fmt.Printf("Paragraphs before deletion: %d\n", doc6.GetParagraphCount())
 	if err := doc6.DeleteParagraph(0); err != nil {
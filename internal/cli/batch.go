@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/converter"
+	"github.com/Palaciodiego008/docxsmith/pkg/operations"
+	"github.com/Palaciodiego008/docxsmith/pkg/template"
+)
+
+// HandleBatch handles the batch command
+func HandleBatch(args []string) {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	dir := fs.String("dir", "", "Directory of input files (required)")
+	op := fs.String("op", "", "Operation: replace, template-render, convert, or watermark (required)")
+	pattern := fs.String("pattern", "*.docx", "Glob matched against file names")
+	recursive := fs.Bool("recursive", false, "Include subdirectories")
+	outDir := fs.String("out", "", "Output directory (default: write next to the inputs)")
+	workers := fs.Int("workers", 0, "Number of files processed at once (default: number of CPUs)")
+	oldText := fs.String("old", "", "Text to replace (replace)")
+	newText := fs.String("new", "", "Replacement text (replace)")
+	dataPath := fs.String("data", "", "Data file path or http(s) URL, JSON or YAML (template-render)")
+	to := fs.String("to", "", "Target format: pdf, md, html, or docx (convert)")
+	engine := fs.String("engine", converter.EngineNative, "DOCX to PDF engine: native or libreoffice (convert)")
+	text := fs.String("text", "", "Watermark text (watermark)")
+	AddDataSourceFlags(fs)
+	AddVerbosityFlags(fs)
+	fs.Parse(args)
+
+	if *dir == "" || *op == "" {
+		fmt.Fprintln(os.Stderr, "Error: -dir and -op are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	opts := operations.BatchOptions{
+		Pattern:   *pattern,
+		Recursive: *recursive,
+		OutputDir: *outDir,
+		Workers:   *workers,
+		Logger:    logger(),
+	}
+
+	var fn operations.BatchFunc
+	switch *op {
+	case "replace":
+		if *oldText == "" {
+			fmt.Fprintln(os.Stderr, "Error: -old is required for replace")
+			os.Exit(1)
+		}
+		fn = operations.ReplaceOperation(*oldText, *newText)
+	case "template-render":
+		if *dataPath == "" {
+			fmt.Fprintln(os.Stderr, "Error: -data is required for template-render")
+			os.Exit(1)
+		}
+		data, err := loadDataFile(*dataPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading data: %v\n", err)
+			os.Exit(1)
+		}
+		fn = operations.TemplateOperation(data, template.DefaultOptions())
+	case "convert":
+		if *to == "" {
+			fmt.Fprintln(os.Stderr, "Error: -to is required for convert")
+			os.Exit(1)
+		}
+		opts.OutputExt = "." + strings.TrimPrefix(strings.ToLower(*to), ".")
+		convertOpts := converter.DefaultOptions()
+		convertOpts.Engine = *engine
+		fn = operations.ConvertOperation(convertOpts)
+	case "watermark":
+		if *text == "" {
+			fmt.Fprintln(os.Stderr, "Error: -text is required for watermark")
+			os.Exit(1)
+		}
+		fn = operations.WatermarkOperation(*text, operations.WatermarkOptions{Logger: opts.Logger})
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown operation: %s\n", *op)
+		os.Exit(1)
+	}
+
+	if opts.OutputDir == "" && opts.OutputExt == "" {
+		fmt.Println("No -out given, input files will be replaced")
+	}
+
+	// Ctrl-C lets the files in progress finish and skips the rest
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var done func()
+	opts.Progress, done = progress()
+	summary, err := operations.BatchContext(ctx, *dir, fn, opts)
+	done()
+	if summary == nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Interrupted, remaining files skipped")
+	}
+
+	// Files are logged as they are processed with -v; failures are always listed
+	for _, r := range summary.Failures() {
+		fmt.Printf("  ✗ %s: %v\n", r.InputPath, r.Err)
+	}
+	fmt.Printf("\nProcessed %d files in %s: %d succeeded, %d failed\n",
+		len(summary.Results), summary.Duration.Round(time.Millisecond), summary.Succeeded, summary.Failed)
+
+	if summary.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/operations"
+)
+
+// HandleBuild handles the build command
+func HandleBuild(args []string) {
+	fs := flag.NewFlagSet("build", flag.ExitOnError)
+	manifest := fs.String("manifest", "", "YAML build manifest (required)")
+	fs.Parse(args)
+
+	if *manifest == "" {
+		fmt.Fprintln(os.Stderr, "Error: -manifest is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	m, err := operations.LoadBuildManifest(*manifest)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading manifest: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Building from %d sources...\n", len(m.Sources))
+	written, err := operations.Build(m)
+	for _, path := range written {
+		fmt.Printf("  Wrote %s\n", path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error building document: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -9,6 +9,7 @@ const Version = "1.0.0"
 
 // Run is the main entry point for the CLI
 func Run(args []string) {
+	args = parseGlobalFlags(args)
 	if len(args) < 1 {
 		PrintUsage()
 		os.Exit(1)
@@ -24,6 +25,10 @@ func Run(args []string) {
 		HandleAdd(args[1:])
 	case "delete":
 		HandleDelete(args[1:])
+	case "move":
+		HandleMove(args[1:])
+	case "snippet":
+		HandleSnippet(args[1:])
 	case "replace":
 		HandleReplace(args[1:])
 	case "find":
@@ -38,6 +43,18 @@ func Run(args []string) {
 		HandleClear(args[1:])
 	case "info":
 		HandleInfo(args[1:])
+	case "outline":
+		HandleOutline(args[1:])
+	case "toc":
+		HandleTOC(args[1:])
+	case "repair":
+		HandleRepair(args[1:])
+	case "fonts":
+		HandleFonts(args[1:])
+	case "compat":
+		HandleCompat(args[1:])
+	case "parts":
+		HandleParts(args[1:])
 
 	// PDF commands
 	case "pdf-create":
@@ -48,6 +65,10 @@ func Run(args []string) {
 		HandlePDFInfo(args[1:])
 	case "pdf-extract":
 		HandlePDFExtract(args[1:])
+	case "pdf-pages":
+		HandlePDFPages(args[1:])
+	case "pdf-set-metadata":
+		HandlePDFSetMetadata(args[1:])
 
 	// Conversion
 	case "convert":
@@ -56,8 +77,14 @@ func Run(args []string) {
 	// Template Engine
 	case "template-render":
 		HandleTemplateRender(args[1:])
+	case "template-render-batch":
+		HandleTemplateRenderBatch(args[1:])
+	case "template-validate":
+		HandleTemplateValidate(args[1:])
 	case "template-variables":
 		HandleTemplateVariables(args[1:])
+	case "template-schema":
+		HandleTemplateSchema(args[1:])
 	case "template-example":
 		HandleTemplateExample(args[1:])
 
@@ -68,6 +95,30 @@ func Run(args []string) {
 		HandleSplit(args[1:])
 	case "merge-info":
 		HandleMergeInfo(args[1:])
+	case "extract-range":
+		HandleExtractRange(args[1:])
+
+	// Watermarks & Redaction
+	case "watermark":
+		HandleWatermark(args[1:])
+	case "redact":
+		HandleRedact(args[1:])
+	case "sanitize":
+		HandleSanitize(args[1:])
+
+	// Signatures
+	case "sign":
+		HandleSign(args[1:])
+	case "sign-verify":
+		HandleSignVerify(args[1:])
+
+	// Workflows
+	case "contract-pack":
+		HandleContractPack(args[1:])
+	case "build":
+		HandleBuild(args[1:])
+	case "batch":
+		HandleBatch(args[1:])
 
 	// Document Diff
 	case "diff":
@@ -91,12 +142,23 @@ func PrintUsage() {
 A powerful tool for manipulating .docx and .pdf files
 
 Usage:
-  docxsmith <command> [options]
+  docxsmith [-json] [-v|-vv] [-no-progress] <command> [options]
+
+Global Options:
+  -json       Print JSON instead of text (info, outline, find, fonts, parts,
+              diff, merge-info, template-variables, template-validate,
+              sign-verify, snippet list)
+  -v, -vv     Log progress, or progress and details, to stderr (batch, merge,
+              split, redact, watermark, template-render-batch)
+  -no-progress
+              Don't draw a progress bar on the terminal (batch, merge, split,
+              convert, template-render-batch)
 
 DOCX Commands:
   create      Create a new DOCX document
   add         Add content to a DOCX document
   delete      Delete content from a DOCX document
+  move        Move paragraphs to another place in a DOCX document
   replace     Replace text in a DOCX document
   find        Find text in a DOCX document
   extract     Extract text from a DOCX document
@@ -104,25 +166,52 @@ DOCX Commands:
   image       Add and manage images in DOCX documents
   clear       Clear all content from a DOCX document
   info        Display DOCX document information
+  outline     Show the heading outline and preview a split by headings
+  toc         Insert a table of contents
+  repair      Fix a damaged DOCX package so Word can open it
+  fonts       List, extract and embed the fonts of a DOCX document
+  compat      Check which features of a DOCX document docxsmith can safely edit
+  parts       List the parts of a DOCX package or dump one as raw XML
+  snippet     Save named fragments to a library and insert them into documents
 
 PDF Commands:
   pdf-create  Create a new PDF document
   pdf-add     Add content to a PDF document
   pdf-info    Display PDF document information
   pdf-extract Extract text from a PDF document
+  pdf-pages   Rotate, reorder and duplicate the pages of a PDF document
+  pdf-set-metadata Edit the title, author, subject and keywords of a PDF
 
 Conversion:
-  convert     Convert between DOCX and PDF formats
+  convert     Convert DOCX to PDF, Markdown or HTML, and PDF to DOCX
 
 Template Engine:
-  template-render     Render a template with data (JSON/YAML)
-  template-variables  List variables in a template
-  template-example    Create example template and data files
+  template-render        Render a template with data (JSON/YAML)
+  template-render-batch  Render a template once per record of a CSV/JSON list (mail merge)
+  template-validate      Check a template and data for problems without rendering
+  template-variables     List variables in a template
+  template-schema        Describe the data a template expects (JSON Schema or skeleton)
+  template-example       Create example template and data files
 
 Merge & Split:
   merge        Merge multiple documents into one
   split        Split a document into multiple files
   merge-info   Show information about merge operation
+  extract-range  Copy a page or paragraph range into a new file
+
+Watermarks & Redaction:
+  watermark    Stamp text such as DRAFT or CONFIDENTIAL across every page
+  redact       Black out or remove text matching patterns, and scrub metadata
+  sanitize     Strip metadata, comments, tracked changes and hidden text
+
+Signatures:
+  sign         Sign a DOCX or PDF document with a PKCS#12 certificate
+  sign-verify  List the signatures of a document and check they are valid
+
+Workflows:
+  contract-pack  Render templates into one signed, numbered PDF with cover and contents
+  build          Assemble a document from the sources listed in a YAML manifest
+  batch          Apply an operation to every matching file in a directory
 
 Comparison:
   diff         Compare two documents and show differences
@@ -135,32 +224,81 @@ Examples:
   # DOCX operations
   docxsmith create -output sample.docx -text "Hello World"
   docxsmith add -input doc.docx -output new.docx -text "New paragraph" -bold
+  docxsmith add -input doc.docx -output new.docx -text "New step" -after-heading "Chapter 2 > Methods"
+  docxsmith move -input doc.docx -output new.docx -start 10 -end 14 -to 2
   docxsmith table -input doc.docx -output new.docx -create -rows 3 -cols 4
   docxsmith image add -input doc.docx -output new.docx -image photo.jpg -width 300 -height 200
   docxsmith image insert -input doc.docx -output new.docx -image logo.png -at 0 -width 150
+  docxsmith info -input doc.docx -detailed
+  docxsmith toc -input report.docx -output report.docx -levels 2 -static
+  docxsmith repair -input broken.docx -output fixed.docx
+  docxsmith fonts -input doc.docx -output new.docx -embed CorpSans-Bold.ttf -name "Corp Sans" -style bold
+  docxsmith compat -input contract.docx
+  docxsmith parts -input doc.docx -extract word/document.xml
+  docxsmith snippet save -library clauses.zip -name signature-block -input letter.docx -start 12 -end 15
+  docxsmith snippet insert -library clauses.zip -name signature-block -input offer.docx -output offer.docx
 
   # PDF operations
   docxsmith pdf-create -output sample.pdf -text "Hello PDF"
   docxsmith pdf-add -input doc.pdf -output new.pdf -text "New text" -bold
   docxsmith pdf-info -input document.pdf
+  docxsmith pdf-pages -input scan.pdf -output fixed.pdf -rotate 2:90 -move 5:1 -duplicate 3
+  docxsmith pdf-set-metadata -input doc.pdf -output doc.pdf -title "Q3 Report" -keywords "finance q3"
 
   # Conversion
   docxsmith convert -input document.docx -output document.pdf
   docxsmith convert -input document.pdf -output document.docx
+  docxsmith convert -input document.docx -output document.pdf -engine libreoffice
 
   # Template Engine
   docxsmith template-example -template invoice.docx -data data.json
   docxsmith template-render -template invoice.docx -data data.json -output result.docx
+  docxsmith template-render -template letter.docx -set Name=Ada -env-prefix DOCX_ -output letter.docx
+  docxsmith template-render-batch -template letter.docx -data customers.csv -output-dir letters -pattern "{Customer}_{n}.docx"
+  docxsmith template-validate -template invoice.docx -data data.json
   docxsmith template-variables -template invoice.docx
+  docxsmith template-schema -template invoice.docx -format yaml -output data.yaml
 
   # Merge & Split
   docxsmith merge -inputs doc1.docx,doc2.docx,doc3.docx -output combined.docx
   docxsmith split -input large.pdf -count 3 -pattern "chapter{n}.pdf"
+  docxsmith outline -input book.docx -split-level 1
   docxsmith split -input book.docx -by-heading -heading-level 1
+  docxsmith split -input scans.pdf -max-size 10MB
+  docxsmith extract-range -input report.pdf -range 10-20 -output excerpt.pdf
+
+  # Watermarks & Redaction
+  docxsmith watermark -input contract.docx -output draft.docx -text DRAFT
+  docxsmith watermark -input report.pdf -text CONFIDENTIAL -color FF0000 -opacity 0.2
+  docxsmith redact -input case.docx -output public.docx -pattern '\d{3}-\d{2}-\d{4}' -pattern 'Jane Doe'
+  docxsmith sanitize -input draft.docx -output publish.docx
+
+  # Signatures
+  docxsmith sign -input contract.pdf -output signed.pdf -cert me.p12 -reason Approved -visible -page 3
+  docxsmith sign-verify -input signed.pdf
+
+  # Batch
+  docxsmith batch -dir contracts -recursive -op replace -old "ACME" -new "Acme Corp" -out updated
+  docxsmith batch -dir reports -op convert -to pdf -workers 4
+  docxsmith -vv batch -dir reports -op convert -to pdf 2> batch.log
+
+  # Contract Pack
+  docxsmith contract-pack -templates terms.docx,pricing.docx -data client.json -output pack.pdf -title "Contract for {{client}}" -watermark DRAFT
+
+  # Build
+  docxsmith build -manifest build.yaml
 
   # Document Comparison
   docxsmith diff -old v1.docx -new v2.docx -output changes.html
   docxsmith diff -old v1.docx -new v2.docx -format markdown -ignore-whitespace
+  docxsmith diff -old v1.docx -new v2.docx -format docx -output changes.docx
+  docxsmith diff -old v1.docx -new v2.docx -format unified -context 2
+
+  # Scripting
+  curl -s https://example.com/report.docx | docxsmith extract -input -
+  docxsmith replace -input in.docx -output - -old ACME -new Acme | docxsmith convert -input - -output - -to pdf > out.pdf
+  docxsmith -json info -input report.docx | jq .paragraphs
+  docxsmith -json find -input report.docx -text invoice | jq '.matches[].index'
 
 For more information on a command:
   docxsmith <command> -help
@@ -1,9 +1,14 @@
 package cli
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"strings"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
 )
 
 // Common error messages
@@ -48,6 +53,86 @@ func PrintInfo(format string, args ...interface{}) {
 	fmt.Printf(format+"\n", args...)
 }
 
+// jsonOutput is set by the global -json flag. Commands that support it
+// print a single JSON document to stdout instead of their usual report.
+var jsonOutput bool
+
+// parseGlobalFlags consumes the flags given before the command name
+func parseGlobalFlags(args []string) []string {
+	for len(args) > 0 {
+		switch args[0] {
+		case "-json", "--json":
+			jsonOutput = true
+		case "-v", "--v":
+			verbosity = max(verbosity, 1)
+		case "-vv", "--vv":
+			verbosity = 2
+		case "-no-progress", "--no-progress":
+			noProgress = true
+		default:
+			return args
+		}
+		args = args[1:]
+	}
+	return args
+}
+
+// AddJSONFlag lets -json also be given after the command name
+func AddJSONFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&jsonOutput, "json", jsonOutput, "Print machine-readable JSON")
+}
+
+// verbosity is set by the global -v (progress) and -vv (details) flags
+var verbosity int
+
+// AddVerbosityFlags lets -v and -vv also be given after the command name
+func AddVerbosityFlags(fs *flag.FlagSet) {
+	fs.BoolFunc("v", "Log progress to stderr", func(string) error {
+		verbosity = max(verbosity, 1)
+		return nil
+	})
+	fs.BoolFunc("vv", "Log progress and details such as timings to stderr", func(string) error {
+		verbosity = 2
+		return nil
+	})
+}
+
+// logger returns the logger operations report to: nil, logging nothing,
+// unless -v asks for progress or -vv for details
+func logger() *slog.Logger {
+	if verbosity == 0 {
+		return nil
+	}
+	level := slog.LevelInfo
+	if verbosity > 1 {
+		level = slog.LevelDebug
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+}
+
+// PrintJSON prints a value as indented JSON
+func PrintJSON(v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		ExitWithError("failed to encode JSON: %v", err)
+	}
+	fmt.Println(string(data))
+}
+
+// StringListFlag collects the values of a flag that may be repeated
+type StringListFlag []string
+
+// String returns the values joined by commas
+func (f *StringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set adds a value
+func (f *StringListFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
 // FormatList formats a list of items for display
 func FormatList(items []string, indent string) string {
 	result := ""
@@ -91,6 +176,38 @@ func AddTextFormattingFlags(fs *flag.FlagSet) (*bool, *bool, *string, *string, *
 	return bold, italic, size, color, align
 }
 
+// AddScopeFlag adds the -include flag of the commands reading and changing
+// text, naming the parts searched besides the body
+func AddScopeFlag(fs *flag.FlagSet) *string {
+	return fs.String("include", "", "Also search tables, headers, footers, footnotes and comments: a comma-separated list of those, or all")
+}
+
+// parseScope parses an -include value into a search scope covering the
+// body and its text boxes and the parts listed
+func parseScope(include string) (docx.SearchScope, error) {
+	scope := docx.DefaultSearchScope()
+	for _, name := range strings.Split(include, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "":
+		case "all":
+			scope = docx.FullSearchScope()
+		case "tables":
+			scope.Tables = true
+		case "headers":
+			scope.Headers = true
+		case "footers":
+			scope.Footers = true
+		case "footnotes", "endnotes":
+			scope.Footnotes = true
+		case "comments":
+			scope.Comments = true
+		default:
+			return scope, fmt.Errorf("unknown part %q in -include, expected tables, headers, footers, footnotes, comments or all", name)
+		}
+	}
+	return scope, nil
+}
+
 // HandleImage handles image-related commands
 func HandleImage(args []string) {
 	err := ImageCommand(args)
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// HandleCompat handles the compat command
+func HandleCompat(args []string) {
+	fs := flag.NewFlagSet("compat", flag.ExitOnError)
+	input := fs.String("input", "", "Input DOCX file (required)")
+	AddJSONFlag(fs)
+	fs.Parse(args)
+
+	if *input == "" {
+		fmt.Fprintln(os.Stderr, "Error: -input is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	doc, err := openDOCX(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening document: %v\n", err)
+		os.Exit(1)
+	}
+	defer doc.Close()
+	report, err := doc.Compatibility()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error checking document: %v\n", err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		out := compatJSON{File: *input, Safe: report.Safe, Features: []featureJSON{}}
+		for _, f := range report.Features {
+			out.Features = append(out.Features, featureJSON{Feature: f.Feature, Count: f.Count, Lost: f.Lost, Support: string(f.Support), Note: f.Note})
+		}
+		PrintJSON(out)
+		return
+	}
+
+	fmt.Printf("Compatibility: %s\n", inputName(*input))
+	if len(report.Features) == 0 {
+		fmt.Println("  Plain text and formatting only")
+	}
+	for _, f := range report.Features {
+		line := fmt.Sprintf("  %-28s %4d  %s", f.Feature, f.Count, f.Support)
+		if f.Lost > 0 {
+			line += fmt.Sprintf(" (%d dropped on save)", f.Lost)
+		}
+		if f.Note != "" {
+			line += " - " + f.Note
+		}
+		fmt.Println(line)
+	}
+	if report.Safe {
+		fmt.Println("Safe to edit: yes")
+	} else {
+		fmt.Println("Safe to edit: no, saving with docxsmith drops the features marked lost")
+	}
+}
+
+// compatJSON is the JSON form of a compatibility report
+type compatJSON struct {
+	File     string        `json:"file"`
+	Safe     bool          `json:"safe"`
+	Features []featureJSON `json:"features"`
+}
+
+type featureJSON struct {
+	Feature string `json:"feature"`
+	Count   int    `json:"count"`
+	Lost    int    `json:"lost"`
+	Support string `json:"support"` // editable, preserved or lost
+	Note    string `json:"note,omitempty"`
+}
@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/docx"
 )
@@ -15,24 +17,48 @@ func HandleAdd(args []string) {
 	output := fs.String("output", "", "Output file path (required)")
 	text := fs.String("text", "", "Text to add (required)")
 	index := fs.Int("at", -1, "Insert at specific index (default: append)")
+	afterHeading := fs.String("after-heading", "", "Insert after the heading at a path, e.g. 'Chapter 2 > Methods'")
+	sectionEnd := fs.Bool("section-end", false, "With -after-heading, insert at the end of the heading's section instead")
 	bold := fs.Bool("bold", false, "Make text bold")
 	italic := fs.Bool("italic", false, "Make text italic")
 	size := fs.String("size", "", "Font size (e.g., '24' for 12pt)")
 	color := fs.String("color", "", "Text color (hex without #, e.g., 'FF0000')")
 	align := fs.String("align", "", "Alignment: left, center, right, both")
+	underline := fs.String("underline", "", "Underline style: single, double, thick, dotted, dash, wave, words")
+	strike := fs.Bool("strike", false, "Strike through text")
+	hidden := fs.Bool("hidden", false, "Format text as hidden")
+	highlight := fs.String("highlight", "", "Highlight color (e.g., 'yellow', 'green', 'lightGray')")
+	superscript := fs.Bool("superscript", false, "Raise text as superscript")
+	subscript := fs.Bool("subscript", false, "Lower text as subscript")
+	font := fs.String("font", "", "Font family (e.g., 'Calibri')")
+	lang := fs.String("lang", "", "Proofing language of the text (e.g., 'es-ES')")
 	fs.Parse(args)
+	useStdout(*output)
 
 	if *input == "" || *output == "" || *text == "" {
 		fmt.Fprintln(os.Stderr, "Error: -input, -output, and -text are required")
 		fs.Usage()
 		os.Exit(1)
 	}
+	if *superscript && *subscript {
+		fmt.Fprintln(os.Stderr, "Error: -superscript and -subscript cannot be combined")
+		os.Exit(1)
+	}
+	if *afterHeading != "" && *index >= 0 {
+		fmt.Fprintln(os.Stderr, "Error: -at and -after-heading cannot be combined")
+		os.Exit(1)
+	}
+	if *highlight != "" && !slices.Contains(docx.HighlightColors, *highlight) {
+		fmt.Fprintf(os.Stderr, "Error: unknown highlight color %q (use one of %s)\n", *highlight, strings.Join(docx.HighlightColors, ", "))
+		os.Exit(1)
+	}
 
-	doc, err := docx.Open(*input)
+	doc, err := openDOCX(*input)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening document: %v\n", err)
 		os.Exit(1)
 	}
+	defer doc.Close()
 
 	// Build paragraph options
 	var opts []docx.ParagraphOption
@@ -51,22 +77,57 @@ func HandleAdd(args []string) {
 	if *align != "" {
 		opts = append(opts, docx.WithAlignment(*align))
 	}
+	if *underline != "" {
+		opts = append(opts, docx.WithUnderline(*underline))
+	}
+	if *strike {
+		opts = append(opts, docx.WithStrike())
+	}
+	if *hidden {
+		opts = append(opts, docx.WithHidden())
+	}
+	if *highlight != "" {
+		opts = append(opts, docx.WithHighlight(*highlight))
+	}
+	if *superscript {
+		opts = append(opts, docx.WithSuperscript())
+	}
+	if *subscript {
+		opts = append(opts, docx.WithSubscript())
+	}
+	if *font != "" {
+		opts = append(opts, docx.WithFont(*font))
+	}
+	if *lang != "" {
+		opts = append(opts, docx.WithLanguage(*lang))
+	}
 
-	if *index >= 0 {
+	switch {
+	case *afterHeading != "" && *sectionEnd:
+		if err := doc.AppendToSection(*afterHeading, docx.NewParagraph(*text, opts...)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error adding paragraph: %v\n", err)
+			os.Exit(1)
+		}
+	case *afterHeading != "":
+		if err := doc.InsertAfterHeading(*afterHeading, docx.NewParagraph(*text, opts...)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error adding paragraph: %v\n", err)
+			os.Exit(1)
+		}
+	case *index >= 0:
 		if err := doc.AddParagraphAt(*index, *text, opts...); err != nil {
 			fmt.Fprintf(os.Stderr, "Error adding paragraph: %v\n", err)
 			os.Exit(1)
 		}
-	} else {
+	default:
 		doc.AddParagraph(*text, opts...)
 	}
 
-	if err := doc.Save(*output); err != nil {
+	if err := saveDOCX(doc, *output); err != nil {
 		fmt.Fprintf(os.Stderr, "Error saving document: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Content added successfully to: %s\n", *output)
+	fmt.Fprintf(messages, "Content added successfully to: %s\n", displayName(*output))
 }
 
 // HandleDelete handles the delete command
@@ -79,6 +140,7 @@ func HandleDelete(args []string) {
 	end := fs.Int("end", -1, "End index for range deletion")
 	table := fs.Int("table", -1, "Table index to delete")
 	fs.Parse(args)
+	useStdout(*output)
 
 	if *input == "" || *output == "" {
 		fmt.Fprintln(os.Stderr, "Error: -input and -output are required")
@@ -86,42 +148,94 @@ func HandleDelete(args []string) {
 		os.Exit(1)
 	}
 
-	doc, err := docx.Open(*input)
+	doc, err := openDOCX(*input)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening document: %v\n", err)
 		os.Exit(1)
 	}
+	defer doc.Close()
 
 	if *start >= 0 && *end >= 0 {
 		if err := doc.DeleteParagraphsRange(*start, *end); err != nil {
 			fmt.Fprintf(os.Stderr, "Error deleting paragraphs: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("Deleted paragraphs %d to %d\n", *start, *end)
+		fmt.Fprintf(messages, "Deleted paragraphs %d to %d\n", *start, *end)
 	} else if *paragraph >= 0 {
 		if err := doc.DeleteParagraph(*paragraph); err != nil {
 			fmt.Fprintf(os.Stderr, "Error deleting paragraph: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("Deleted paragraph %d\n", *paragraph)
+		fmt.Fprintf(messages, "Deleted paragraph %d\n", *paragraph)
 	} else if *table >= 0 {
 		if err := doc.DeleteTable(*table); err != nil {
 			fmt.Fprintf(os.Stderr, "Error deleting table: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("Deleted table %d\n", *table)
+		fmt.Fprintf(messages, "Deleted table %d\n", *table)
 	} else {
 		fmt.Fprintln(os.Stderr, "Error: specify -paragraph, -table, or -start/-end")
 		fs.Usage()
 		os.Exit(1)
 	}
 
-	if err := doc.Save(*output); err != nil {
+	if err := saveDOCX(doc, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving document: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(messages, "Document saved: %s\n", displayName(*output))
+}
+
+// HandleMove handles the move command
+func HandleMove(args []string) {
+	fs := flag.NewFlagSet("move", flag.ExitOnError)
+	input := fs.String("input", "", "Input file path (required)")
+	output := fs.String("output", "", "Output file path (required)")
+	paragraph := fs.Int("paragraph", -1, "Paragraph index to move")
+	start := fs.Int("start", -1, "Start index of a range to move")
+	end := fs.Int("end", -1, "End index of a range to move (inclusive)")
+	to := fs.Int("to", -1, "Index the (first) moved paragraph ends up at (required)")
+	fs.Parse(args)
+	useStdout(*output)
+
+	if *input == "" || *output == "" || *to < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -input, -output and -to are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	doc, err := openDOCX(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening document: %v\n", err)
+		os.Exit(1)
+	}
+	defer doc.Close()
+
+	if *start >= 0 && *end >= 0 {
+		if err := doc.MoveParagraphRange(*start, *end, *to); err != nil {
+			fmt.Fprintf(os.Stderr, "Error moving paragraphs: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(messages, "Moved paragraphs %d to %d to index %d\n", *start, *end, *to)
+	} else if *paragraph >= 0 {
+		if err := doc.MoveParagraph(*paragraph, *to); err != nil {
+			fmt.Fprintf(os.Stderr, "Error moving paragraph: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(messages, "Moved paragraph %d to index %d\n", *paragraph, *to)
+	} else {
+		fmt.Fprintln(os.Stderr, "Error: specify -paragraph or -start/-end")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	if err := saveDOCX(doc, *output); err != nil {
 		fmt.Fprintf(os.Stderr, "Error saving document: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Document saved: %s\n", *output)
+	fmt.Fprintf(messages, "Document saved: %s\n", displayName(*output))
 }
 
 // HandleClear handles the clear command
@@ -130,6 +244,7 @@ func HandleClear(args []string) {
 	input := fs.String("input", "", "Input file path (required)")
 	output := fs.String("output", "", "Output file path (required)")
 	fs.Parse(args)
+	useStdout(*output)
 
 	if *input == "" || *output == "" {
 		fmt.Fprintln(os.Stderr, "Error: -input and -output are required")
@@ -137,18 +252,19 @@ func HandleClear(args []string) {
 		os.Exit(1)
 	}
 
-	doc, err := docx.Open(*input)
+	doc, err := openDOCX(*input)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening document: %v\n", err)
 		os.Exit(1)
 	}
+	defer doc.Close()
 
 	doc.Clear()
 
-	if err := doc.Save(*output); err != nil {
+	if err := saveDOCX(doc, *output); err != nil {
 		fmt.Fprintf(os.Stderr, "Error saving document: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Document cleared and saved: %s\n", *output)
+	fmt.Fprintf(messages, "Document cleared and saved: %s\n", displayName(*output))
 }
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/operations"
+)
+
+// HandleContractPack handles the contract-pack command
+func HandleContractPack(args []string) {
+	fs := flag.NewFlagSet("contract-pack", flag.ExitOnError)
+	templates := fs.String("templates", "", "Comma-separated list of templates, in order (required)")
+	dataPath := fs.String("data", "", "Data file path or http(s) URL (JSON or YAML) (required)")
+	output := fs.String("output", "", "Output PDF file path (required)")
+	docxOutput := fs.String("docx", "", "Also save the assembled DOCX to this path")
+	title := fs.String("title", "", "Cover page title (may use template variables)")
+	subtitle := fs.String("subtitle", "", "Cover page subtitle (may use template variables)")
+	tocTitle := fs.String("toc-title", "Contents", "Title of the table of contents")
+	pageNumbers := fs.String("page-numbers", "Page {n} of {total}", "Page number format, empty to disable")
+	watermark := fs.String("watermark", "", "Watermark text")
+	signKey := fs.String("sign-key", "", "PEM private key to sign the PDF with (writes <output>.sig)")
+	strict := fs.Bool("strict", false, "Strict mode - fail on missing variables")
+	AddDataSourceFlags(fs)
+	fs.Parse(args)
+
+	if *templates == "" || *dataPath == "" || *output == "" {
+		fmt.Fprintln(os.Stderr, "Error: -templates, -data, and -output are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	data, err := loadDataFile(*dataPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading data: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts := operations.DefaultContractPackOptions()
+	for _, t := range strings.Split(*templates, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			opts.Templates = append(opts.Templates, t)
+		}
+	}
+	opts.Data = data
+	opts.OutputPath = *output
+	opts.DOCXPath = *docxOutput
+	opts.CoverTitle = *title
+	opts.CoverSubtitle = *subtitle
+	opts.TOCTitle = *tocTitle
+	opts.PageNumbers = *pageNumbers
+	opts.Watermark = *watermark
+	opts.Render.StrictMode = *strict
+
+	if *signKey != "" {
+		opts.Signer, err = operations.LoadSigningKey(*signKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading signing key: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("Building contract pack from %d templates...\n", len(opts.Templates))
+	result, err := operations.BuildContractPack(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error building contract pack: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Successfully built contract pack: %s (%d pages)\n", result.PDFPath, result.Pages)
+	if result.DOCXPath != "" {
+		fmt.Printf("  DOCX: %s\n", result.DOCXPath)
+	}
+	if result.SignaturePath != "" {
+		fmt.Printf("  Signature: %s\n", result.SignaturePath)
+	}
+}
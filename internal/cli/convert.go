@@ -3,11 +3,14 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/converter"
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
 )
 
 // HandleConvert handles the convert command
@@ -18,7 +21,12 @@ func HandleConvert(args []string) {
 	pageSize := fs.String("page-size", "A4", "Page size (A4, Letter, Legal)")
 	fontSize := fs.Float64("font-size", 12, "Default font size")
 	fontFamily := fs.String("font-family", "Arial", "Default font family")
+	to := fs.String("to", "", "Output format when writing to stdout: pdf, md, html or docx")
+	engine := fs.String("engine", converter.EngineNative, "DOCX to PDF engine: native or libreoffice")
+	fontMap := fs.String("font-map", "", "Fonts to set in other fonts in PDFs, e.g. 'Calibri=Helvetica,Cambria=Times'")
+	fallbackFonts := fs.String("fallback-font", "", "TrueType fonts for characters other fonts lack in PDFs, e.g. 'NotoSansArabic.ttf,NotoSansHebrew.ttf'")
 	fs.Parse(args)
+	useStdout(*output)
 
 	if *input == "" || *output == "" {
 		fmt.Fprintln(os.Stderr, "Error: -input and -output are required")
@@ -26,9 +34,30 @@ func HandleConvert(args []string) {
 		os.Exit(1)
 	}
 
-	// Determine conversion direction based on file extensions
+	// Determine conversion direction based on file extensions, or on the
+	// content and -to for stdin and stdout
 	inputExt := strings.ToLower(filepath.Ext(*input))
+	if _, ok := docx.TypeForExtension(inputExt); ok {
+		inputExt = ".docx" // Macro-enabled documents and templates convert alike
+	}
+	var stdin []byte
+	if *input == stdioPath {
+		var err error
+		if stdin, err = io.ReadAll(os.Stdin); err == nil {
+			inputExt, err = sniffFormat(stdin)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading input: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	outputExt := strings.ToLower(filepath.Ext(*output))
+	if *to != "" {
+		outputExt = "." + strings.TrimPrefix(strings.ToLower(*to), ".")
+	} else if *output == stdioPath {
+		fmt.Fprintln(os.Stderr, "Error: -to is required when writing to stdout")
+		os.Exit(1)
+	}
 
 	opts := converter.ConvertOptions{
 		PageSize:    *pageSize,
@@ -36,31 +65,99 @@ func HandleConvert(args []string) {
 		FontSize:    *fontSize,
 		FontFamily:  *fontFamily,
 		Margins:     [4]float64{20, 20, 20, 20},
+		Engine:      *engine,
+	}
+	if *fallbackFonts != "" {
+		opts.FallbackFonts = strings.Split(*fallbackFonts, ",")
+	}
+	var done func()
+	opts.Progress, done = progress()
+	if *fontMap != "" {
+		opts.FontMap = make(map[string]string)
+		for _, pair := range strings.Split(*fontMap, ",") {
+			from, to, ok := strings.Cut(pair, "=")
+			if !ok || strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+				fmt.Fprintf(os.Stderr, "Error: invalid -font-map entry %q, expected Font=PDFFont\n", pair)
+				os.Exit(1)
+			}
+			opts.FontMap[strings.TrimSpace(from)] = strings.TrimSpace(to)
+		}
 	}
 
-	var err error
+	// The converters read a document and write it to a file or a writer
+	type docxConverter interface {
+		Convert(doc *docx.Document, outputPath string) error
+		ConvertTo(doc *docx.Document, w io.Writer) error
+	}
+	var fromDOCX docxConverter
+	var toPDF *converter.DocxToPDF
 
 	switch {
 	case inputExt == ".docx" && outputExt == ".pdf":
-		fmt.Println("Converting DOCX to PDF...")
-		err = converter.ConvertDocxToPDF(*input, *output, opts)
+		fmt.Fprintln(messages, "Converting DOCX to PDF...")
+		toPDF = converter.NewDocxToPDF(opts)
+		fromDOCX = toPDF
+
+	case inputExt == ".docx" && outputExt == ".md":
+		fmt.Fprintln(messages, "Converting DOCX to Markdown...")
+		fromDOCX = converter.NewDocxToMarkdown(opts)
+
+	case inputExt == ".docx" && outputExt == ".html":
+		fmt.Fprintln(messages, "Converting DOCX to HTML...")
+		fromDOCX = converter.NewDocxToHTML(opts)
 
 	case inputExt == ".pdf" && outputExt == ".docx":
-		fmt.Println("Converting PDF to DOCX...")
-		err = converter.ConvertPDFToDocx(*input, *output, opts)
+		fmt.Fprintln(messages, "Converting PDF to DOCX...")
 
 	default:
 		fmt.Fprintf(os.Stderr, "Error: Unsupported conversion from %s to %s\n", inputExt, outputExt)
 		fmt.Fprintln(os.Stderr, "Supported conversions:")
 		fmt.Fprintln(os.Stderr, "  - .docx to .pdf")
+		fmt.Fprintln(os.Stderr, "  - .docx to .md")
+		fmt.Fprintln(os.Stderr, "  - .docx to .html")
 		fmt.Fprintln(os.Stderr, "  - .pdf to .docx")
 		os.Exit(1)
 	}
 
+	var err error
+	if fromDOCX != nil {
+		var doc *docx.Document
+		if stdin != nil {
+			doc, err = docx.ReadBytes(stdin)
+		} else {
+			doc, err = docx.Open(*input)
+		}
+		if err == nil && *output == stdioPath {
+			err = fromDOCX.ConvertTo(doc, os.Stdout)
+		} else if err == nil {
+			err = fromDOCX.Convert(doc, *output)
+		}
+		if doc != nil {
+			doc.Close()
+		}
+	} else {
+		var doc *pdf.Document
+		if stdin != nil {
+			doc, err = pdf.ReadBytes(stdin)
+		} else {
+			doc, err = pdf.Open(*input)
+		}
+		if err == nil && *output == stdioPath {
+			err = converter.NewPDFToDocx(opts).ConvertTo(doc, os.Stdout)
+		} else if err == nil {
+			err = converter.NewPDFToDocx(opts).Convert(doc, *output)
+		}
+	}
+	done()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error converting document: %v\n", err)
 		os.Exit(1)
 	}
+	if toPDF != nil {
+		for _, warning := range toPDF.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+	}
 
-	fmt.Printf("Conversion successful: %s -> %s\n", *input, *output)
+	fmt.Fprintf(messages, "Conversion successful: %s -> %s\n", inputName(*input), displayName(*output))
 }
@@ -13,7 +13,9 @@ func HandleCreate(args []string) {
 	fs := flag.NewFlagSet("create", flag.ExitOnError)
 	output := fs.String("output", "", "Output file path (required)")
 	text := fs.String("text", "", "Initial text content")
+	lang := fs.String("lang", "", "Proofing language of the document (e.g., 'en-US')")
 	fs.Parse(args)
+	useStdout(*output)
 
 	if *output == "" {
 		fmt.Fprintln(os.Stderr, "Error: -output is required")
@@ -22,16 +24,22 @@ func HandleCreate(args []string) {
 	}
 
 	doc := docx.New()
+	if *lang != "" {
+		if err := doc.SetLanguage(*lang); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	if *text != "" {
 		doc.AddParagraph(*text)
 	} else {
 		doc.AddParagraph("Document created with DocxSmith")
 	}
 
-	if err := doc.Save(*output); err != nil {
+	if err := saveDOCX(doc, *output); err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating document: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Document created successfully: %s\n", *output)
+	fmt.Fprintf(messages, "Document created successfully: %s\n", displayName(*output))
 }
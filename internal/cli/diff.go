@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/diff"
 )
@@ -15,11 +16,14 @@ func HandleDiff(args []string) {
 	// Use common flag helpers
 	oldFile := fs.String("old", "", "Old/original document (required)")
 	newFile := fs.String("new", "", "New/modified document (required)")
-	output := fs.String("output", "", "Output file (default: stdout)")
-	format := fs.String("format", "html", "Output format: html, markdown, text")
+	output := fs.String("output", "", "Output file, or - for stdout (default: stdout)")
+	format := fs.String("format", "html", "Output format: html, markdown, text, json, unified, docx (tracked changes)")
 	ignoreWhitespace := fs.Bool("ignore-whitespace", false, "Ignore whitespace differences")
 	ignoreCase := fs.Bool("ignore-case", false, "Ignore case differences")
 	showStats := fs.Bool("stats", true, "Show statistics")
+	contextLines := fs.Int("context", 3, "Unchanged lines shown around changes in unified output")
+	author := fs.String("author", "DocxSmith", "Author of the tracked changes in docx output")
+	AddJSONFlag(fs)
 
 	if err := fs.Parse(args); err != nil {
 		ExitWithError("Failed to parse flags: %v", err)
@@ -40,17 +44,32 @@ func HandleDiff(args []string) {
 	if err := ValidateFileExists(*newFile); err != nil {
 		ExitWithError("%v", err)
 	}
+	toStdout := *output == "" || *output == stdioPath
+
+	// With -json, changes go to stdout as JSON, or a summary does when
+	// the diff is written to a file
+	if jsonOutput && toStdout {
+		*format = "json"
+	}
+	if *format == "docx" && *output == "" {
+		ExitWithError("-output is required for docx format (use - for stdout)")
+	}
 
 	// Configure diff options
 	opts := diff.DiffOptions{
 		IgnoreWhitespace: *ignoreWhitespace,
 		IgnoreCase:       *ignoreCase,
-		ContextLines:     3,
+		ContextLines:     *contextLines,
 		MinChangeLength:  1,
 	}
 
+	// Output meant for other tools is written alone when sent to stdout
+	machineReadable := jsonOutput || toStdout && (*format == "json" || *format == "unified" || *format == "patch" || *format == "docx")
+
 	// Compare documents
-	PrintInfo("Comparing documents...")
+	if !machineReadable {
+		PrintInfo("Comparing documents...")
+	}
 	result, err := diff.CompareDOCX(*oldFile, *newFile, opts)
 	if err != nil {
 		ExitWithError("Failed to compare documents: %v", err)
@@ -65,8 +84,14 @@ func HandleDiff(args []string) {
 		renderer = diff.NewMarkdownRenderer(*showStats)
 	case "text", "txt":
 		renderer = diff.NewPlainTextRenderer(*showStats, true)
+	case "json":
+		renderer = diff.NewJSONRenderer(true)
+	case "unified", "patch":
+		renderer = diff.NewUnifiedRenderer(opts.ContextLines)
+	case "docx":
+		renderer = diff.NewDOCXRenderer(*author)
 	default:
-		ExitWithError("Unknown format: %s (use: html, markdown, text)", *format)
+		ExitWithError("Unknown format: %s (use: html, markdown, text, json, unified, docx)", *format)
 	}
 
 	// Render diff
@@ -76,16 +101,32 @@ func HandleDiff(args []string) {
 	}
 
 	// Output result
-	if *output != "" {
+	if !toStdout {
 		if err := os.WriteFile(*output, []byte(outputContent), 0644); err != nil {
 			ExitWithError("Failed to write output file: %v", err)
 		}
+		if jsonOutput {
+			PrintJSON(diffSummaryJSON{
+				Output:       *output,
+				Format:       *format,
+				TotalChanges: result.Stats.TotalChanges,
+				Added:        result.Stats.AddedLines,
+				Deleted:      result.Stats.DeletedLines,
+				Modified:     result.Stats.ModifiedLines,
+			})
+			return
+		}
 		PrintSuccess("Diff saved to: %s", *output)
+	} else if *format == "docx" || strings.HasSuffix(outputContent, "\n") || outputContent == "" {
+		fmt.Print(outputContent)
 	} else {
 		fmt.Println(outputContent)
 	}
 
 	// Print summary
+	if machineReadable {
+		return
+	}
 	if result.Stats.TotalChanges == 0 {
 		PrintSuccess("Documents are identical - no changes detected")
 	} else {
@@ -95,3 +136,13 @@ func HandleDiff(args []string) {
 		PrintInfo("  Deleted lines: %d", result.Stats.DeletedLines)
 	}
 }
+
+// diffSummaryJSON is printed by diff -json when the diff is written to a file
+type diffSummaryJSON struct {
+	Output       string `json:"output"`
+	Format       string `json:"format"`
+	TotalChanges int    `json:"totalChanges"`
+	Added        int    `json:"added"`
+	Deleted      int    `json:"deleted"`
+	Modified     int    `json:"modified"`
+}
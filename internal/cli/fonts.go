@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// HandleFonts handles the fonts command
+func HandleFonts(args []string) {
+	fs := flag.NewFlagSet("fonts", flag.ExitOnError)
+	input := fs.String("input", "", "Input DOCX file (required)")
+	output := fs.String("output", "", "Output file path (required with -embed)")
+	embed := fs.String("embed", "", "TrueType or OpenType font file to embed")
+	name := fs.String("name", "", "Font name the document uses, e.g. 'Corporate Sans' (required with -embed)")
+	style := fs.String("style", "regular", "Style of the embedded file: regular, bold, italic, bolditalic")
+	extract := fs.String("extract", "", "Directory to write the embedded font files to")
+	AddJSONFlag(fs)
+	fs.Parse(args)
+	useStdout(*output)
+
+	if *input == "" {
+		fmt.Fprintln(os.Stderr, "Error: -input is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	doc, err := openDOCX(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening document: %v\n", err)
+		os.Exit(1)
+	}
+	defer doc.Close()
+
+	switch {
+	case *embed != "":
+		if *output == "" || *name == "" {
+			fmt.Fprintln(os.Stderr, "Error: -output and -name are required with -embed")
+			fs.Usage()
+			os.Exit(1)
+		}
+		fontStyle, ok := parseFontStyle(*style)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown style %q\n", *style)
+			os.Exit(1)
+		}
+		data, err := os.ReadFile(*embed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading font: %v\n", err)
+			os.Exit(1)
+		}
+		if err := doc.EmbedFont(*name, fontStyle, data); err != nil {
+			fmt.Fprintf(os.Stderr, "Error embedding font: %v\n", err)
+			os.Exit(1)
+		}
+		if err := saveDOCX(doc, *output); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving document: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(messages, "Embedded %s (%s) in: %s\n", *name, fontStyle, displayName(*output))
+
+	case *extract != "":
+		fonts, err := doc.Fonts()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading fonts: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(*extract, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating directory: %v\n", err)
+			os.Exit(1)
+		}
+		count := 0
+		for _, font := range fonts {
+			for _, s := range font.Embedded {
+				data, err := doc.ExtractFont(font.Name, s)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+					continue
+				}
+				path := filepath.Join(*extract, fontFileName(font.Name, s, data))
+				if err := os.WriteFile(path, data, 0644); err != nil {
+					fmt.Fprintf(os.Stderr, "Error writing font: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Printf("  %s\n", path)
+				count++
+			}
+		}
+		fmt.Printf("Extracted %d font file(s) to: %s\n", count, *extract)
+
+	default:
+		fonts, err := doc.Fonts()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading fonts: %v\n", err)
+			os.Exit(1)
+		}
+		if jsonOutput {
+			out := []fontJSON{}
+			for _, font := range fonts {
+				f := fontJSON{Name: font.Name, AltName: font.AltName, Family: font.Family, Embedded: []string{}}
+				for _, s := range font.Embedded {
+					f.Embedded = append(f.Embedded, string(s))
+				}
+				out = append(out, f)
+			}
+			PrintJSON(out)
+			return
+		}
+
+		fmt.Printf("Fonts in %s: %d\n", inputName(*input), len(fonts))
+		for _, font := range fonts {
+			line := "  " + font.Name
+			if font.Family != "" {
+				line += fmt.Sprintf(" (%s)", font.Family)
+			}
+			if len(font.Embedded) > 0 {
+				styles := make([]string, len(font.Embedded))
+				for i, s := range font.Embedded {
+					styles[i] = string(s)
+				}
+				line += " embedded: " + strings.Join(styles, ", ")
+			}
+			fmt.Println(line)
+		}
+	}
+}
+
+// fontJSON is a font in fonts -json output
+type fontJSON struct {
+	Name     string   `json:"name"`
+	AltName  string   `json:"altName,omitempty"`
+	Family   string   `json:"family,omitempty"`
+	Embedded []string `json:"embedded"`
+}
+
+// parseFontStyle parses a -style value, ignoring case and dashes
+func parseFontStyle(s string) (docx.FontStyle, bool) {
+	s = strings.ReplaceAll(s, "-", "")
+	for _, style := range docx.FontStyles {
+		if strings.EqualFold(s, string(style)) {
+			return style, true
+		}
+	}
+	return "", false
+}
+
+// fontFileName names an extracted font file, e.g. Corporate Sans-Bold.ttf
+func fontFileName(name string, style docx.FontStyle, data []byte) string {
+	ext := ".ttf"
+	switch {
+	case strings.HasPrefix(string(data), "OTTO"):
+		ext = ".otf"
+	case strings.HasPrefix(string(data), "ttcf"):
+		ext = ".ttc"
+	}
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	return name + "-" + string(style) + ext
+}
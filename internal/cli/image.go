@@ -41,6 +41,7 @@ func imageAddCommand(args []string) error {
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	useStdout(*outputPath)
 
 	// Validate required flags
 	if *inputPath == "" {
@@ -54,10 +55,11 @@ func imageAddCommand(args []string) error {
 	}
 
 	// Open document
-	doc, err := docx.Open(*inputPath)
+	doc, err := openDOCX(*inputPath)
 	if err != nil {
 		return fmt.Errorf("failed to open document: %v", err)
 	}
+	defer doc.Close()
 
 	// Add image with options
 	var opts []docx.ImageOption
@@ -74,12 +76,12 @@ func imageAddCommand(args []string) error {
 	}
 
 	// Save document
-	err = doc.Save(*outputPath)
+	err = saveDOCX(doc, *outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to save document: %v", err)
 	}
 
-	fmt.Printf("Image added successfully. Document saved as %s\n", *outputPath)
+	fmt.Fprintf(messages, "Image added successfully. Document saved as %s\n", displayName(*outputPath))
 	return nil
 }
 
@@ -99,6 +101,7 @@ func imageInsertCommand(args []string) error {
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	useStdout(*outputPath)
 
 	// Validate required flags
 	if *inputPath == "" {
@@ -121,10 +124,11 @@ func imageInsertCommand(args []string) error {
 	}
 
 	// Open document
-	doc, err := docx.Open(*inputPath)
+	doc, err := openDOCX(*inputPath)
 	if err != nil {
 		return fmt.Errorf("failed to open document: %v", err)
 	}
+	defer doc.Close()
 
 	// Add image with options
 	var opts []docx.ImageOption
@@ -141,12 +145,12 @@ func imageInsertCommand(args []string) error {
 	}
 
 	// Save document
-	err = doc.Save(*outputPath)
+	err = saveDOCX(doc, *outputPath)
 	if err != nil {
 		return fmt.Errorf("failed to save document: %v", err)
 	}
 
-	fmt.Printf("Image inserted at position %d. Document saved as %s\n", pos, *outputPath)
+	fmt.Fprintf(messages, "Image inserted at position %d. Document saved as %s\n", pos, displayName(*outputPath))
 	return nil
 }
 
@@ -168,10 +172,11 @@ func imageCountCommand(args []string) error {
 	}
 
 	// Open document
-	doc, err := docx.Open(*inputPath)
+	doc, err := openDOCX(*inputPath)
 	if err != nil {
 		return fmt.Errorf("failed to open document: %v", err)
 	}
+	defer doc.Close()
 
 	// Get image count
 	count := doc.GetImageCount()
@@ -13,6 +13,8 @@ import (
 func HandleInfo(args []string) {
 	fs := flag.NewFlagSet("info", flag.ExitOnError)
 	input := fs.String("input", "", "Input file path (required)")
+	detailed := fs.Bool("detailed", false, "Also show images, averages, estimated pages and the heading outline")
+	AddJSONFlag(fs)
 	fs.Parse(args)
 
 	if *input == "" {
@@ -21,20 +23,54 @@ func HandleInfo(args []string) {
 		os.Exit(1)
 	}
 
-	doc, err := docx.Open(*input)
+	doc, err := openDOCX(*input)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening document: %v\n", err)
 		os.Exit(1)
 	}
+	defer doc.Close()
 
-	fmt.Printf("Document Information: %s\n", *input)
-	fmt.Printf("  Paragraphs: %d\n", doc.GetParagraphCount())
-	fmt.Printf("  Tables: %d\n", doc.GetTableCount())
+	stats := doc.Stats()
 
-	wordCount := len(strings.Fields(doc.GetText()))
-	charCount := len(doc.GetText())
-	fmt.Printf("  Words: %d\n", wordCount)
-	fmt.Printf("  Characters: %d\n", charCount)
+	if jsonOutput {
+		info := infoJSON{
+			File:       *input,
+			Type:       string(doc.Type()),
+			Macros:     doc.HasMacros(),
+			Paragraphs: stats.Paragraphs,
+			Tables:     stats.Tables,
+			Words:      stats.Words,
+			Characters: stats.Characters,
+			TableSizes: []tableSizeJSON{},
+		}
+		for i, table := range doc.Body.Tables {
+			info.TableSizes = append(info.TableSizes, tableSizeJSON{Index: i, Rows: table.GetRowCount(), Columns: table.GetColumnCount()})
+		}
+		if *detailed {
+			info.Details = newInfoDetailsJSON(stats)
+		}
+		PrintJSON(info)
+		return
+	}
+
+	fmt.Printf("Document Information: %s\n", inputName(*input))
+	if doc.HasMacros() {
+		fmt.Printf("  Type: %s (with macros)\n", doc.Type())
+	} else {
+		fmt.Printf("  Type: %s\n", doc.Type())
+	}
+	fmt.Printf("  Paragraphs: %d\n", stats.Paragraphs)
+	fmt.Printf("  Tables: %d\n", stats.Tables)
+	fmt.Printf("  Words: %d\n", stats.Words)
+	fmt.Printf("  Characters: %d\n", stats.Characters)
+
+	if *detailed {
+		fmt.Printf("  Characters (no spaces): %d\n", stats.CharactersNoSpaces)
+		fmt.Printf("  Empty paragraphs: %d\n", stats.EmptyParagraphs)
+		fmt.Printf("  Images: %d\n", stats.Images)
+		fmt.Printf("  Average words per paragraph: %.1f\n", stats.AverageWordsPerParagraph)
+		fmt.Printf("  Estimated pages: %d\n", stats.EstimatedPages)
+	}
 
 	if doc.GetTableCount() > 0 {
 		fmt.Println("\nTable Details:")
@@ -43,4 +79,64 @@ func HandleInfo(args []string) {
 				i, table.GetRowCount(), table.GetColumnCount())
 		}
 	}
+
+	if *detailed && len(stats.Headings) > 0 {
+		fmt.Println("\nOutline:")
+		for _, h := range stats.Headings {
+			fmt.Printf("  %s%s (paragraph %d)\n", strings.Repeat("  ", h.Level-1), h.Text, h.Index)
+		}
+	}
+}
+
+// infoJSON is the output of info -json
+type infoJSON struct {
+	File       string           `json:"file"`
+	Type       string           `json:"type"` // docx, docm, dotx or dotm
+	Macros     bool             `json:"macros"`
+	Paragraphs int              `json:"paragraphs"`
+	Tables     int              `json:"tables"`
+	Words      int              `json:"words"`
+	Characters int              `json:"characters"`
+	TableSizes []tableSizeJSON  `json:"tableSizes"`
+	Details    *infoDetailsJSON `json:"details,omitempty"`
+}
+
+// tableSizeJSON describes a table in info -json output
+type tableSizeJSON struct {
+	Index   int `json:"index"`
+	Rows    int `json:"rows"`
+	Columns int `json:"columns"`
+}
+
+// infoDetailsJSON holds the statistics added by info -detailed -json
+type infoDetailsJSON struct {
+	CharactersNoSpaces       int           `json:"charactersNoSpaces"`
+	EmptyParagraphs          int           `json:"emptyParagraphs"`
+	Images                   int           `json:"images"`
+	AverageWordsPerParagraph float64       `json:"averageWordsPerParagraph"`
+	EstimatedPages           int           `json:"estimatedPages"`
+	Headings                 []headingJSON `json:"headings"`
+}
+
+// headingJSON is a heading of the outline, by its 0-based paragraph index
+type headingJSON struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	Index int    `json:"index"`
+}
+
+// newInfoDetailsJSON converts document statistics for info -detailed -json
+func newInfoDetailsJSON(stats docx.Stats) *infoDetailsJSON {
+	details := &infoDetailsJSON{
+		CharactersNoSpaces:       stats.CharactersNoSpaces,
+		EmptyParagraphs:          stats.EmptyParagraphs,
+		Images:                   stats.Images,
+		AverageWordsPerParagraph: stats.AverageWordsPerParagraph,
+		EstimatedPages:           stats.EstimatedPages,
+		Headings:                 make([]headingJSON, len(stats.Headings)),
+	}
+	for i, h := range stats.Headings {
+		details.Headings[i] = headingJSON{Level: h.Level, Text: h.Text, Index: h.Index}
+	}
+	return details
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
 	"github.com/Palaciodiego008/docxsmith/pkg/operations"
 	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
 )
@@ -18,6 +19,12 @@ func HandleMerge(args []string) {
 	pageBreaks := fs.Bool("page-breaks", true, "Add page breaks between documents")
 	separator := fs.Bool("separator", false, "Add separator between documents")
 	separatorText := fs.String("separator-text", "---", "Separator text")
+	toc := fs.Bool("toc", false, "Insert a table of contents linking to each document (DOCX only)")
+	tocTitle := fs.String("toc-title", "Contents", "Table of contents heading")
+	sections := fs.Bool("sections", false, "Put each document in its own section with its own headers and footers (DOCX only)")
+	restartNumbering := fs.Bool("restart-page-numbers", false, "Number the pages of each document from 1; implies -sections (DOCX only)")
+	dedupe := fs.Bool("dedupe", false, "Keep one copy of the styles, fonts and images the documents share (DOCX only)")
+	AddVerbosityFlags(fs)
 	fs.Parse(args)
 
 	if *inputs == "" || *output == "" {
@@ -37,18 +44,25 @@ func HandleMerge(args []string) {
 		os.Exit(1)
 	}
 
-	fmt.Printf("Merging %d documents...\n", len(inputFiles))
-
 	// Configure options
 	opts := operations.MergeOptions{
-		AddPageBreaks:      *pageBreaks,
-		AddSeparator:       *separator,
-		SeparatorText:      *separatorText,
-		PreserveFormatting: true,
+		AddPageBreaks:        *pageBreaks,
+		AddSeparator:         *separator,
+		SeparatorText:        *separatorText,
+		PreserveFormatting:   true,
+		GenerateTOC:          *toc,
+		TOCTitle:             *tocTitle,
+		SectionPerDocument:   *sections,
+		RestartPageNumbering: *restartNumbering,
+		Deduplicate:          *dedupe,
+		Logger:               logger(),
 	}
 
 	// Merge documents
+	var done func()
+	opts.Progress, done = progress()
 	err := operations.MergeDocuments(inputFiles, *output, opts)
+	done()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error merging documents: %v\n", err)
 		os.Exit(1)
@@ -61,12 +75,22 @@ func HandleMerge(args []string) {
 func HandleSplit(args []string) {
 	fs := flag.NewFlagSet("split", flag.ExitOnError)
 	input := fs.String("input", "", "Input file path (required)")
-	outputPattern := fs.String("pattern", "{base}_part{n}", "Output filename pattern")
+	outputPattern := fs.String("pattern", "{base}_part{n}", "Output filename pattern; tokens: {n}, {index:03d}, {base}, {title}, {heading}, {date}")
+	sanitize := fs.String("sanitize", "safe", "How values are cleaned up for file names: safe, strict or none")
 	outputDir := fs.String("dir", ".", "Output directory")
+	zipOutput := fs.String("zip", "", "Write the parts into a single zip archive at this path instead of -dir")
 	pages := fs.String("pages", "", "Page ranges (e.g., '1-5,7,9-12')")
 	count := fs.Int("count", 0, "Split into N equal parts")
 	byHeading := fs.Bool("by-heading", false, "Split by heading levels")
 	headingLevel := fs.Int("heading-level", 1, "Heading level to split by (1-6)")
+	byBookmark := fs.Bool("by-bookmark", false, "Split at each bookmark")
+	bySection := fs.Bool("by-section", false, "Split at each section break")
+	maxSize := fs.String("max-size", "", "Maximum size of each part (e.g., '10MB', PDF only)")
+	pagesPerPart := fs.Int("pages-per-part", 0, "Split every N estimated pages (DOCX only)")
+	wordsPerPage := fs.Int("words-per-page", docx.WordsPerPage, "Words a page holds when estimating pages")
+	charsPerPage := fs.Int("chars-per-page", 0, "Characters a page holds when estimating pages; overrides -words-per-page")
+	usePageSize := fs.Bool("use-page-size", false, "Scale the page estimate by each section's page size and margins")
+	AddVerbosityFlags(fs)
 	fs.Parse(args)
 
 	if *input == "" {
@@ -78,7 +102,17 @@ func HandleSplit(args []string) {
 	opts := operations.SplitOptions{
 		OutputPattern: *outputPattern,
 		OutputDir:     *outputDir,
+		Sanitize:      operations.SanitizeMode(*sanitize),
+		ZipOutput:     *zipOutput,
+		PageEstimate: docx.PageEstimateOptions{
+			WordsPerPage:      *wordsPerPage,
+			CharactersPerPage: *charsPerPage,
+			UsePageSize:       *usePageSize,
+		},
+		Logger: logger(),
 	}
+	var done func()
+	opts.Progress, done = progress()
 
 	var outputFiles []string
 	var err error
@@ -86,13 +120,32 @@ func HandleSplit(args []string) {
 	// Determine split method
 	if *byHeading {
 		// Split by headings (DOCX only)
-		fmt.Printf("Splitting by heading level %d...\n", *headingLevel)
 		outputFiles, err = operations.SplitDOCXByHeadings(*input, *headingLevel, opts)
 
+	} else if *byBookmark {
+		// Split by bookmarks (DOCX only)
+		outputFiles, err = operations.SplitDOCXByBookmarks(*input, opts)
+
+	} else if *bySection {
+		// Split by section breaks (DOCX only)
+		outputFiles, err = operations.SplitDOCXBySections(*input, opts)
+
+	} else if *pagesPerPart > 0 {
+		// Split by estimated pages (DOCX only)
+		outputFiles, err = operations.SplitDOCXByEstimatedPages(*input, *pagesPerPart, opts)
+
+	} else if *maxSize != "" {
+		// Split by size budget (PDF only)
+		maxBytes, parseErr := operations.ParseSize(*maxSize)
+		if parseErr != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing max size: %v\n", parseErr)
+			os.Exit(1)
+		}
+
+		outputFiles, err = operations.SplitPDFBySize(*input, maxBytes, opts)
+
 	} else if *count > 0 {
 		// Split into N parts
-		fmt.Printf("Splitting into %d parts...\n", *count)
-
 		// Detect file type
 		if strings.HasSuffix(*input, ".pdf") {
 			outputFiles, err = operations.SplitPDFByCount(*input, *count, opts)
@@ -102,8 +155,6 @@ func HandleSplit(args []string) {
 
 	} else if *pages != "" {
 		// Split by page ranges (PDF only)
-		fmt.Printf("Splitting by page ranges: %s\n", *pages)
-
 		// First, get page count
 		doc, openErr := pdf.Open(*input)
 		if openErr != nil {
@@ -120,26 +171,64 @@ func HandleSplit(args []string) {
 		outputFiles, err = operations.SplitPDFByPages(*input, ranges, opts)
 
 	} else {
-		fmt.Fprintln(os.Stderr, "Error: Must specify one of: -pages, -count, or -by-heading")
+		fmt.Fprintln(os.Stderr, "Error: Must specify one of: -pages, -count, -pages-per-part, -max-size, -by-heading, -by-bookmark, or -by-section")
 		fs.Usage()
 		os.Exit(1)
 	}
 
+	done()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error splitting document: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Successfully split into %d files:\n", len(outputFiles))
+	if *zipOutput != "" {
+		fmt.Printf("Successfully split into %d files in %s:\n", len(outputFiles), *zipOutput)
+	} else {
+		fmt.Printf("Successfully split into %d files:\n", len(outputFiles))
+	}
 	for _, file := range outputFiles {
 		fmt.Printf("  - %s\n", file)
 	}
 }
 
+// HandleExtractRange handles the extract-range command
+func HandleExtractRange(args []string) {
+	fs := flag.NewFlagSet("extract-range", flag.ExitOnError)
+	input := fs.String("input", "", "Input file path (required)")
+	output := fs.String("output", "", "Output file path (required)")
+	rangeStr := fs.String("range", "", "Pages (PDF) or paragraphs (DOCX) to extract, 1-based (e.g., '10-20') (required)")
+	fs.Parse(args)
+
+	if *input == "" || *output == "" || *rangeStr == "" {
+		fmt.Fprintln(os.Stderr, "Error: -input, -output, and -range are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	var start, end int
+	if _, err := fmt.Sscanf(*rangeStr, "%d-%d", &start, &end); err != nil {
+		if _, err := fmt.Sscanf(*rangeStr, "%d", &start); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid range: %s\n", *rangeStr)
+			os.Exit(1)
+		}
+		end = start
+	}
+
+	// Convert to 0-indexed
+	if err := operations.ExtractRange(*input, *output, start-1, end-1); err != nil {
+		fmt.Fprintf(os.Stderr, "Error extracting range: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Successfully extracted %s into: %s\n", *rangeStr, *output)
+}
+
 // HandleMergeInfo handles the merge-info command
 func HandleMergeInfo(args []string) {
 	fs := flag.NewFlagSet("merge-info", flag.ExitOnError)
 	inputs := fs.String("inputs", "", "Comma-separated list of input files (required)")
+	AddJSONFlag(fs)
 	fs.Parse(args)
 
 	if *inputs == "" {
@@ -162,6 +251,10 @@ func HandleMergeInfo(args []string) {
 			os.Exit(1)
 		}
 
+		if jsonOutput {
+			PrintJSON(mergeInfoJSON{Format: "pdf", Files: inputFiles, Documents: info.TotalDocuments, Pages: &info.TotalPages})
+			return
+		}
 		fmt.Printf("Merge Information (PDF):\n")
 		fmt.Printf("  Documents: %d\n", info.TotalDocuments)
 		fmt.Printf("  Total Pages: %d\n", info.TotalPages)
@@ -173,9 +266,25 @@ func HandleMergeInfo(args []string) {
 			os.Exit(1)
 		}
 
+		if jsonOutput {
+			PrintJSON(mergeInfoJSON{Format: "docx", Files: inputFiles, Documents: info.TotalDocuments,
+				Paragraphs: &info.TotalParagraphs, Tables: &info.TotalTables})
+			return
+		}
 		fmt.Printf("Merge Information (DOCX):\n")
 		fmt.Printf("  Documents: %d\n", info.TotalDocuments)
 		fmt.Printf("  Total Paragraphs: %d\n", info.TotalParagraphs)
 		fmt.Printf("  Total Tables: %d\n", info.TotalTables)
 	}
 }
+
+// mergeInfoJSON is the output of merge-info -json; pages are counted for
+// PDF files and paragraphs and tables for DOCX files
+type mergeInfoJSON struct {
+	Format     string   `json:"format"`
+	Files      []string `json:"files"`
+	Documents  int      `json:"documents"`
+	Pages      *int     `json:"pages,omitempty"`
+	Paragraphs *int     `json:"paragraphs,omitempty"`
+	Tables     *int     `json:"tables,omitempty"`
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/operations"
+)
+
+// HandleOutline handles the outline command
+func HandleOutline(args []string) {
+	fs := flag.NewFlagSet("outline", flag.ExitOnError)
+	input := fs.String("input", "", "Input file path (required)")
+	maxLevel := fs.Int("max-level", 9, "Deepest heading level to show")
+	splitLevel := fs.Int("split-level", 0, "Preview the parts of split -by-heading at this heading level")
+	outputPattern := fs.String("pattern", "{base}_part{n}", "Output filename pattern of the split preview")
+	outputDir := fs.String("dir", ".", "Output directory of the split preview")
+	sanitize := fs.String("sanitize", "safe", "How values are cleaned up for file names: safe, strict or none")
+	AddJSONFlag(fs)
+	fs.Parse(args)
+
+	if *input == "" {
+		fmt.Fprintln(os.Stderr, "Error: -input is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	doc, err := openDOCX(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening document: %v\n", err)
+		os.Exit(1)
+	}
+	defer doc.Close()
+
+	outline := trimOutline(doc.GetOutline(), *maxLevel)
+
+	var parts []operations.SplitPart
+	if *splitLevel > 0 {
+		opts := operations.SplitOptions{OutputPattern: *outputPattern, OutputDir: *outputDir, Sanitize: operations.SanitizeMode(*sanitize)}
+		parts, err = operations.PlanSplitDOCXByHeadings(doc, *input, *splitLevel, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error previewing split: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if jsonOutput {
+		out := outlineJSON{File: *input, Headings: newOutlineEntriesJSON(outline)}
+		for i, part := range parts {
+			out.Parts = append(out.Parts, splitPartJSON{
+				Part:   i + 1,
+				Start:  part.Range.Start,
+				End:    part.Range.End,
+				Title:  part.Title,
+				Output: part.Output,
+			})
+		}
+		PrintJSON(out)
+		return
+	}
+
+	fmt.Printf("Outline: %s\n", inputName(*input))
+	if len(outline) == 0 {
+		fmt.Println("  (no headings)")
+	}
+	printOutline(outline)
+
+	if *splitLevel > 0 {
+		fmt.Printf("\nsplit -by-heading -heading-level %d would write %d files:\n", *splitLevel, len(parts))
+		if parts[0].Range.Start > 0 {
+			fmt.Printf("  (paragraphs 0-%d, before the first heading, are left out)\n", parts[0].Range.Start-1)
+		}
+		for i, part := range parts {
+			fmt.Printf("  %d. %s: paragraphs %d-%d, %q\n", i+1, part.Output, part.Range.Start, part.Range.End, part.Title)
+		}
+	}
+}
+
+// HandleTOC handles the toc command
+func HandleTOC(args []string) {
+	fs := flag.NewFlagSet("toc", flag.ExitOnError)
+	input := fs.String("input", "", "Input file path (required)")
+	output := fs.String("output", "", "Output file path (required)")
+	title := fs.String("title", "Contents", "Heading above the table of contents (empty for none)")
+	levels := fs.Int("levels", 3, "Deepest heading level listed (1-9)")
+	at := fs.Int("at", 0, "Paragraph index to insert the table of contents at")
+	static := fs.Bool("static", false, "Fill in the headings, so they show before fields are updated")
+	update := fs.Bool("update", false, "Ask Word to update the table of contents on open")
+	fs.Parse(args)
+	useStdout(*output)
+
+	if *input == "" || *output == "" {
+		fmt.Fprintln(os.Stderr, "Error: -input and -output are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	doc, err := openDOCX(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening document: %v\n", err)
+		os.Exit(1)
+	}
+	defer doc.Close()
+
+	opts := docx.TOCOptions{Index: *at, Title: *title, Levels: *levels, Static: *static, UpdateOnOpen: *update}
+	if err := doc.InsertTOC(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error inserting table of contents: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := saveDOCX(doc, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving document: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(messages, "Table of contents inserted and saved: %s\n", displayName(*output))
+}
+
+// trimOutline drops the headings deeper than maxLevel
+func trimOutline(entries []docx.OutlineEntry, maxLevel int) []docx.OutlineEntry {
+	trimmed := []docx.OutlineEntry{}
+	for _, e := range entries {
+		if e.Level > maxLevel {
+			continue
+		}
+		e.Children = trimOutline(e.Children, maxLevel)
+		trimmed = append(trimmed, e)
+	}
+	return trimmed
+}
+
+// printOutline prints the headings indented by depth
+func printOutline(entries []docx.OutlineEntry) {
+	var walk func(entries []docx.OutlineEntry, depth int)
+	walk = func(entries []docx.OutlineEntry, depth int) {
+		for _, e := range entries {
+			fmt.Printf("  %sH%d %s (paragraph %d)\n", strings.Repeat("  ", depth), e.Level, e.Text, e.Index)
+			walk(e.Children, depth+1)
+		}
+	}
+	walk(entries, 0)
+}
+
+// outlineJSON is the output of outline -json
+type outlineJSON struct {
+	File     string             `json:"file"`
+	Headings []outlineEntryJSON `json:"headings"`
+	Parts    []splitPartJSON    `json:"parts,omitempty"`
+}
+
+// outlineEntryJSON is a heading with its subheadings in outline -json output
+type outlineEntryJSON struct {
+	Level    int                `json:"level"`
+	Text     string             `json:"text"`
+	Index    int                `json:"index"`
+	Children []outlineEntryJSON `json:"children"`
+}
+
+// splitPartJSON is a part of the split preview in outline -json output
+type splitPartJSON struct {
+	Part   int    `json:"part"`
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+	Title  string `json:"title"`
+	Output string `json:"output"`
+}
+
+// newOutlineEntriesJSON converts outline entries for outline -json
+func newOutlineEntriesJSON(entries []docx.OutlineEntry) []outlineEntryJSON {
+	out := make([]outlineEntryJSON, len(entries))
+	for i, e := range entries {
+		out[i] = outlineEntryJSON{Level: e.Level, Text: e.Text, Index: e.Index, Children: newOutlineEntriesJSON(e.Children)}
+	}
+	return out
+}
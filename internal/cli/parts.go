@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// HandleParts handles the parts command
+func HandleParts(args []string) {
+	fs := flag.NewFlagSet("parts", flag.ExitOnError)
+	input := fs.String("input", "", "Input DOCX file (required)")
+	extract := fs.String("extract", "", "Part to dump as it is stored, e.g. word/document.xml")
+	output := fs.String("output", "-", "File to write the extracted part to")
+	AddJSONFlag(fs)
+	fs.Parse(args)
+
+	if *input == "" {
+		fmt.Fprintln(os.Stderr, "Error: -input is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	doc, err := openDOCX(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening document: %v\n", err)
+		os.Exit(1)
+	}
+	defer doc.Close()
+
+	if *extract != "" {
+		data, ok := doc.GetPart(*extract)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: part %s not found; list them with: docxsmith parts -input %s\n", *extract, *input)
+			os.Exit(1)
+		}
+		if err := writeOutput(*output, data); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing part: %v\n", err)
+			os.Exit(1)
+		}
+		if *output != stdioPath {
+			fmt.Printf("Extracted %s (%d bytes) to: %s\n", *extract, len(data), *output)
+		}
+		return
+	}
+
+	parts, err := doc.Parts()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading parts: %v\n", err)
+		os.Exit(1)
+	}
+	pkgRels, err := doc.PartRelationships("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading relationships: %v\n", err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		out := partsJSON{Relationships: relationshipsJSON(pkgRels), Parts: []partJSON{}}
+		for _, part := range parts {
+			out.Parts = append(out.Parts, partJSON{
+				Name:          part.Name,
+				Size:          part.Size,
+				ContentType:   part.ContentType,
+				Relationships: relationshipsJSON(part.Relationships),
+			})
+		}
+		PrintJSON(out)
+		return
+	}
+
+	fmt.Printf("Parts in %s: %d\n", inputName(*input), len(parts))
+	for _, part := range parts {
+		contentType := part.ContentType
+		if contentType == "" {
+			contentType = "(no content type)"
+		}
+		fmt.Printf("  %-36s %9d  %s\n", part.Name, part.Size, contentType)
+		printRelationships(part.Relationships)
+	}
+	if len(pkgRels) > 0 {
+		fmt.Println("Package relationships:")
+		printRelationships(pkgRels)
+	}
+}
+
+// printRelationships prints relationships under the part holding them,
+// naming their type by its last path element, e.g. "styles"
+func printRelationships(rels []docx.Relationship) {
+	for _, rel := range rels {
+		line := fmt.Sprintf("      %-6s %s -> %s", rel.ID, path.Base(rel.Type), rel.Target)
+		if rel.TargetMode == "External" {
+			line += " (external)"
+		}
+		fmt.Println(line)
+	}
+}
+
+// partsJSON is the output of parts -json
+type partsJSON struct {
+	Parts         []partJSON         `json:"parts"`
+	Relationships []relationshipJSON `json:"relationships"` // Of the package
+}
+
+// partJSON is a part in parts -json output
+type partJSON struct {
+	Name          string             `json:"name"`
+	Size          int64              `json:"size"`
+	ContentType   string             `json:"contentType,omitempty"`
+	Relationships []relationshipJSON `json:"relationships,omitempty"`
+}
+
+// relationshipJSON is a relationship in parts -json output
+type relationshipJSON struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Target     string `json:"target"`
+	TargetMode string `json:"targetMode,omitempty"`
+}
+
+// relationshipsJSON converts relationships for JSON output
+func relationshipsJSON(rels []docx.Relationship) []relationshipJSON {
+	out := make([]relationshipJSON, len(rels))
+	for i, rel := range rels {
+		out[i] = relationshipJSON{ID: rel.ID, Type: rel.Type, Target: rel.Target, TargetMode: rel.TargetMode}
+	}
+	return out
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
 )
@@ -17,6 +18,7 @@ func HandlePDFCreate(args []string) {
 	title := fs.String("title", "", "Document title")
 	author := fs.String("author", "", "Document author")
 	fs.Parse(args)
+	useStdout(*output)
 
 	if *output == "" {
 		fmt.Fprintln(os.Stderr, "Error: -output is required")
@@ -41,12 +43,12 @@ func HandlePDFCreate(args []string) {
 		page.AddText("PDF document created with DocxSmith", 20, 30, 12)
 	}
 
-	if err := doc.Save(*output); err != nil {
+	if err := savePDF(doc, *output); err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating PDF: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("PDF created successfully: %s\n", *output)
+	fmt.Fprintf(messages, "PDF created successfully: %s\n", displayName(*output))
 }
 
 // HandlePDFAdd handles adding content to PDF
@@ -60,6 +62,7 @@ func HandlePDFAdd(args []string) {
 	size := fs.Float64("size", 12, "Font size")
 	color := fs.String("color", "000000", "Text color (hex without #)")
 	fs.Parse(args)
+	useStdout(*output)
 
 	if *input == "" || *output == "" || *text == "" {
 		fmt.Fprintln(os.Stderr, "Error: -input, -output, and -text are required")
@@ -67,7 +70,7 @@ func HandlePDFAdd(args []string) {
 		os.Exit(1)
 	}
 
-	doc, err := pdf.Open(*input)
+	doc, err := openPDF(*input)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening PDF: %v\n", err)
 		os.Exit(1)
@@ -94,12 +97,12 @@ func HandlePDFAdd(args []string) {
 	y := 30.0 + float64(len(page.Content))*10
 	page.AddTextStyled(*text, 20, y, style)
 
-	if err := doc.Save(*output); err != nil {
+	if err := savePDF(doc, *output); err != nil {
 		fmt.Fprintf(os.Stderr, "Error saving PDF: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Content added successfully to: %s\n", *output)
+	fmt.Fprintf(messages, "Content added successfully to: %s\n", displayName(*output))
 }
 
 // HandlePDFInfo handles displaying PDF info
@@ -114,13 +117,13 @@ func HandlePDFInfo(args []string) {
 		os.Exit(1)
 	}
 
-	doc, err := pdf.Open(*input)
+	doc, err := openPDF(*input)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening PDF: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("PDF Document Information: %s\n", *input)
+	fmt.Printf("PDF Document Information: %s\n", inputName(*input))
 	fmt.Printf("  Pages: %d\n", doc.GetPageCount())
 
 	if doc.Metadata != nil {
@@ -130,6 +133,18 @@ func HandlePDFInfo(args []string) {
 		if doc.Metadata.Author != "" {
 			fmt.Printf("  Author: %s\n", doc.Metadata.Author)
 		}
+		if doc.Metadata.Subject != "" {
+			fmt.Printf("  Subject: %s\n", doc.Metadata.Subject)
+		}
+		if doc.Metadata.Keywords != "" {
+			fmt.Printf("  Keywords: %s\n", doc.Metadata.Keywords)
+		}
+		if doc.Metadata.Creator != "" {
+			fmt.Printf("  Creator: %s\n", doc.Metadata.Creator)
+		}
+		if !doc.Metadata.Created.IsZero() {
+			fmt.Printf("  Created: %s\n", doc.Metadata.Created.Format("2006-01-02 15:04:05 -07:00"))
+		}
 	}
 
 	// Count total text content
@@ -141,12 +156,67 @@ func HandlePDFInfo(args []string) {
 	fmt.Printf("  Characters: %d\n", charCount)
 }
 
+// HandlePDFSetMetadata handles editing the title, author, subject and
+// keywords of a PDF
+func HandlePDFSetMetadata(args []string) {
+	fs := flag.NewFlagSet("pdf-set-metadata", flag.ExitOnError)
+	input := fs.String("input", "", "Input PDF file path (required)")
+	output := fs.String("output", "", "Output PDF file path (required)")
+	title := fs.String("title", "", "Document title")
+	author := fs.String("author", "", "Document author")
+	subject := fs.String("subject", "", "Document subject")
+	keywords := fs.String("keywords", "", "Document keywords, e.g. \"invoice 2024\"")
+	fs.Parse(args)
+	useStdout(*output)
+
+	// Only the fields given are changed, so a field can also be cleared with ""
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	if *input == "" || *output == "" || !(set["title"] || set["author"] || set["subject"] || set["keywords"]) {
+		fmt.Fprintln(os.Stderr, "Error: -input, -output and one of -title, -author, -subject or -keywords are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	doc, err := openPDF(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening PDF: %v\n", err)
+		os.Exit(1)
+	}
+	if doc.Metadata == nil {
+		doc.Metadata = &pdf.Metadata{Creator: "DocxSmith"}
+	}
+
+	if set["title"] {
+		doc.Metadata.Title = *title
+	}
+	if set["author"] {
+		doc.Metadata.Author = *author
+	}
+	if set["subject"] {
+		doc.Metadata.Subject = *subject
+	}
+	if set["keywords"] {
+		doc.SetKeywords(*keywords)
+	}
+	doc.Metadata.Modified = time.Now()
+
+	if err := savePDF(doc, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving PDF: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(messages, "Metadata updated: %s\n", displayName(*output))
+}
+
 // HandlePDFExtract handles extracting text from PDF
 func HandlePDFExtract(args []string) {
 	fs := flag.NewFlagSet("pdf-extract", flag.ExitOnError)
 	input := fs.String("input", "", "Input PDF file path (required)")
 	output := fs.String("output", "", "Output text file (optional)")
 	fs.Parse(args)
+	useStdout(*output)
 
 	if *input == "" {
 		fmt.Fprintln(os.Stderr, "Error: -input is required")
@@ -154,7 +224,7 @@ func HandlePDFExtract(args []string) {
 		os.Exit(1)
 	}
 
-	doc, err := pdf.Open(*input)
+	doc, err := openPDF(*input)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening PDF: %v\n", err)
 		os.Exit(1)
@@ -163,12 +233,90 @@ func HandlePDFExtract(args []string) {
 	text := doc.GetAllText()
 
 	if *output != "" {
-		if err := os.WriteFile(*output, []byte(text), 0644); err != nil {
+		if err := writeOutput(*output, []byte(text)); err != nil {
 			fmt.Fprintf(os.Stderr, "Error writing output file: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("Text extracted to: %s\n", *output)
+		fmt.Fprintf(messages, "Text extracted to: %s\n", displayName(*output))
 	} else {
 		fmt.Println(text)
 	}
 }
+
+// pageOp is an edit of the pdf-pages command, kept in command line order
+type pageOp struct {
+	kind  string
+	value string
+}
+
+// pageOpFlag records the values of one kind of pdf-pages edit into the
+// list shared by all kinds, so edits apply in the order given
+type pageOpFlag struct {
+	kind string
+	ops  *[]pageOp
+}
+
+func (f pageOpFlag) String() string { return "" }
+
+func (f pageOpFlag) Set(value string) error {
+	*f.ops = append(*f.ops, pageOp{kind: f.kind, value: value})
+	return nil
+}
+
+// HandlePDFPages handles rotating, moving and duplicating PDF pages
+func HandlePDFPages(args []string) {
+	fs := flag.NewFlagSet("pdf-pages", flag.ExitOnError)
+	input := fs.String("input", "", "Input PDF file path (required)")
+	output := fs.String("output", "", "Output PDF file path (required)")
+	var ops []pageOp
+	fs.Var(pageOpFlag{"rotate", &ops}, "rotate", "Turn a page clockwise as page:degrees, e.g. 2:90; repeat for several")
+	fs.Var(pageOpFlag{"move", &ops}, "move", "Move a page as from:to, e.g. 5:1; repeat for several")
+	fs.Var(pageOpFlag{"duplicate", &ops}, "duplicate", "Insert a copy of a page after it; repeat for several")
+	fs.Parse(args)
+	useStdout(*output)
+
+	if *input == "" || *output == "" || len(ops) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: -input, -output and one of -rotate, -move or -duplicate are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	doc, err := openPDF(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening PDF: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Pages are numbered from 1 on the command line
+	for _, op := range ops {
+		var a, b int
+		switch op.kind {
+		case "rotate":
+			if _, err := fmt.Sscanf(op.value, "%d:%d", &a, &b); err != nil {
+				ExitWithError("invalid -rotate value '%s', expected page:degrees", op.value)
+			}
+			err = doc.RotatePage(a-1, b)
+		case "move":
+			if _, err := fmt.Sscanf(op.value, "%d:%d", &a, &b); err != nil {
+				ExitWithError("invalid -move value '%s', expected from:to", op.value)
+			}
+			err = doc.MovePage(a-1, b-1)
+		case "duplicate":
+			if _, err := fmt.Sscanf(op.value, "%d", &a); err != nil {
+				ExitWithError("invalid -duplicate value '%s', expected a page number", op.value)
+			}
+			err = doc.DuplicatePage(a - 1)
+		}
+		if err != nil {
+			ExitWithError("-%s %s: %v", op.kind, op.value, err)
+		}
+		fmt.Fprintf(messages, "Applied -%s %s\n", op.kind, op.value)
+	}
+
+	if err := savePDF(doc, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving PDF: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(messages, "PDF saved: %s (%d pages)\n", displayName(*output), doc.GetPageCount())
+}
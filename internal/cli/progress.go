@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/converter"
+)
+
+// noProgress is set by the global -no-progress flag
+var noProgress bool
+
+// progressBar draws the progress of an operation on one terminal line
+type progressBar struct {
+	mu    sync.Mutex
+	w     io.Writer
+	drawn time.Time // When the bar was last drawn; zero until it is
+}
+
+// progressWidth is the number of characters of a full bar
+const progressWidth = 30
+
+// update redraws the bar, at most ten times a second except for the last step
+func (b *progressBar) update(current, total int, stage string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if total <= 0 || current < total && time.Since(b.drawn) < 100*time.Millisecond {
+		return
+	}
+	filled := progressWidth * min(current, total) / total
+	fmt.Fprintf(b.w, "\r%-8s [%s%s] %d/%d", stage,
+		strings.Repeat("=", filled), strings.Repeat(" ", progressWidth-filled), current, total)
+	b.drawn = time.Now()
+}
+
+// clear erases the bar so the messages that follow start on a clean line
+func (b *progressBar) clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.drawn.IsZero() {
+		fmt.Fprint(b.w, "\r\033[K")
+		b.drawn = time.Time{}
+	}
+}
+
+// progress returns the function operations report their progress to, and a
+// function to call when the operation ends. A bar is drawn on stderr when it
+// is a terminal, unless -v already logs progress there or -no-progress is
+// given; otherwise the progress function is nil and nothing is drawn.
+func progress() (converter.ProgressFunc, func()) {
+	if noProgress || verbosity > 0 || !isTerminal(os.Stderr) {
+		return nil, func() {}
+	}
+	bar := &progressBar{w: os.Stderr}
+	return bar.update, bar.clear
+}
+
+// isTerminal reports whether f is a terminal rather than a file or a pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
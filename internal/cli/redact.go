@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/operations"
+)
+
+// HandleRedact handles the redact command
+func HandleRedact(args []string) {
+	fs := flag.NewFlagSet("redact", flag.ExitOnError)
+	input := fs.String("input", "", "Input DOCX or PDF file (required)")
+	output := fs.String("output", "", "Output file path (required)")
+	var patterns StringListFlag
+	fs.Var(&patterns, "pattern", "Regular expression to redact; repeat for several (required)")
+	remove := fs.Bool("remove", false, "Delete matches instead of blacking them out")
+	keepMetadata := fs.Bool("keep-metadata", false, "Keep document properties and revision history")
+	AddVerbosityFlags(fs)
+	fs.Parse(args)
+
+	if *input == "" || *output == "" || len(patterns) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: -input, -output, and -pattern are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	opts := operations.RedactOptions{
+		Remove:       *remove,
+		KeepMetadata: *keepMetadata,
+		Logger:       logger(),
+	}
+	count, err := operations.Redact(*input, *output, patterns, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error redacting document: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Redacted %d match(es) into: %s\n", count, *output)
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// HandleRepair handles the repair command
+func HandleRepair(args []string) {
+	fs := flag.NewFlagSet("repair", flag.ExitOnError)
+	input := fs.String("input", "", "Damaged DOCX file (required)")
+	output := fs.String("output", "", "Output file path (required unless -dry-run)")
+	dryRun := fs.Bool("dry-run", false, "List the fixes without saving")
+	fs.Parse(args)
+	useStdout(*output)
+
+	if *input == "" || *output == "" && !*dryRun {
+		fmt.Fprintln(os.Stderr, "Error: -input and -output are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	data, err := readInput(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading document: %v\n", err)
+		os.Exit(1)
+	}
+	doc, fixes, err := docx.RepairBytes(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error repairing document: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !*dryRun {
+		if err := saveDOCX(doc, *output); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving document: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	switch {
+	case len(fixes) == 0:
+		fmt.Fprintf(messages, "No problems found in %s\n", inputName(*input))
+	case *dryRun:
+		fmt.Fprintf(messages, "%d problem(s) found in %s:\n", len(fixes), inputName(*input))
+	default:
+		fmt.Fprintf(messages, "Repaired document saved to: %s\n", displayName(*output))
+	}
+	for _, fix := range fixes {
+		fmt.Fprintf(messages, "  %s\n", fix)
+	}
+}
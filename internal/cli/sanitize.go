@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// HandleSanitize handles the sanitize command
+func HandleSanitize(args []string) {
+	fs := flag.NewFlagSet("sanitize", flag.ExitOnError)
+	input := fs.String("input", "", "Input DOCX file (required)")
+	output := fs.String("output", "", "Output file path (required)")
+	keep := fs.String("keep", "", "Comma-separated list of things to keep: properties, comments, tracked-changes, hidden-text, personal-info, custom-xml")
+	fs.Parse(args)
+	useStdout(*output)
+
+	if *input == "" || *output == "" {
+		fmt.Fprintln(os.Stderr, "Error: -input and -output are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	opts := docx.DefaultSanitizeOptions()
+	if *keep != "" {
+		for _, item := range strings.Split(*keep, ",") {
+			switch strings.TrimSpace(item) {
+			case "properties":
+				opts.Properties = false
+			case "comments":
+				opts.Comments = false
+			case "tracked-changes":
+				opts.TrackedChanges = false
+			case "hidden-text":
+				opts.HiddenText = false
+			case "personal-info":
+				opts.PersonalInfo = false
+			case "custom-xml":
+				opts.CustomXML = false
+			default:
+				fmt.Fprintf(os.Stderr, "Error: unknown -keep item: %s\n", item)
+				os.Exit(1)
+			}
+		}
+	}
+
+	doc, err := openDOCX(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening document: %v\n", err)
+		os.Exit(1)
+	}
+	defer doc.Close()
+
+	report := doc.Sanitize(opts)
+	if err := saveDOCX(doc, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving document: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(messages, "Sanitized document saved to: %s\n", displayName(*output))
+	for _, part := range report.RemovedParts {
+		fmt.Fprintf(messages, "  Removed %s\n", part)
+	}
+	if report.HiddenRuns > 0 {
+		fmt.Fprintf(messages, "  Removed %d hidden run(s)\n", report.HiddenRuns)
+	}
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/signature"
+)
+
+// certPasswordEnv names the environment variable -password falls back to,
+// so the password needn't show up in the process list
+const certPasswordEnv = "DOCXSMITH_CERT_PASSWORD"
+
+// HandleSign handles the sign command
+func HandleSign(args []string) {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	input := fs.String("input", "", "DOCX or PDF file to sign (required)")
+	output := fs.String("output", "", "Output file path (required)")
+	cert := fs.String("cert", "", "PKCS#12 certificate and key file, .p12 or .pfx (required)")
+	password := fs.String("password", "", "Certificate password; defaults to $"+certPasswordEnv)
+	reason := fs.String("reason", "", "Reason for signing, e.g. 'Approved'")
+	location := fs.String("location", "", "Where the document is signed (PDF only)")
+	visible := fs.Bool("visible", false, "Draw the signature on a page (PDF only)")
+	page := fs.Int("page", 1, "Page of a visible signature")
+	rect := fs.String("rect", "", "Box of a visible signature in points: left,bottom,right,top")
+	fs.Parse(args)
+	useStdout(*output)
+
+	if *input == "" || *output == "" || *cert == "" {
+		fmt.Fprintln(os.Stderr, "Error: -input, -output and -cert are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	opts := signature.Options{Reason: *reason, Location: *location, Visible: *visible, Page: *page}
+	if *rect != "" {
+		box, err := parseRect(*rect)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -rect: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Rect = box
+		opts.Visible = true
+	}
+
+	certData, err := os.ReadFile(*cert)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading certificate: %v\n", err)
+		os.Exit(1)
+	}
+	pass := *password
+	if pass == "" {
+		pass = os.Getenv(certPasswordEnv)
+	}
+	signer, err := signature.LoadPKCS12(certData, pass)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	data, err := readInput(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading document: %v\n", err)
+		os.Exit(1)
+	}
+	signed, err := signature.Sign(data, signer, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error signing document: %v\n", err)
+		os.Exit(1)
+	}
+	if err := writeOutput(*output, signed); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving document: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(messages, "Signed by %s, saved to: %s\n", signer.Certificate.Subject.CommonName, displayName(*output))
+}
+
+// parseRect reads a box given as "left,bottom,right,top"
+func parseRect(s string) ([4]float64, error) {
+	var box [4]float64
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return box, fmt.Errorf("expected left,bottom,right,top, got %q", s)
+	}
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return box, fmt.Errorf("%q is not a number", part)
+		}
+		box[i] = v
+	}
+	if box[2] <= box[0] || box[3] <= box[1] {
+		return box, fmt.Errorf("right and top must be greater than left and bottom")
+	}
+	return box, nil
+}
+
+type signatureJSON struct {
+	Signer   string `json:"signer"`
+	Issuer   string `json:"issuer,omitempty"`
+	Time     string `json:"time,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Location string `json:"location,omitempty"`
+	Valid    bool   `json:"valid"`
+	Trusted  bool   `json:"trusted"`
+	Partial  bool   `json:"partial,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// HandleSignVerify handles the sign-verify command. It exits with status 1
+// when the document is unsigned or a signature is invalid.
+func HandleSignVerify(args []string) {
+	fs := flag.NewFlagSet("sign-verify", flag.ExitOnError)
+	input := fs.String("input", "", "Signed DOCX or PDF file (required)")
+	AddJSONFlag(fs)
+	fs.Parse(args)
+
+	if *input == "" {
+		fmt.Fprintln(os.Stderr, "Error: -input is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	data, err := readInput(*input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading document: %v\n", err)
+		os.Exit(1)
+	}
+	sigs, err := signature.Verify(data)
+	if err != nil && !errors.Is(err, signature.ErrNotSigned) {
+		fmt.Fprintf(os.Stderr, "Error verifying signatures: %v\n", err)
+		os.Exit(1)
+	}
+
+	allValid := len(sigs) > 0
+	out := []signatureJSON{}
+	for _, s := range sigs {
+		j := signatureJSON{Signer: s.Signer, Reason: s.Reason, Location: s.Location,
+			Valid: s.Valid(), Trusted: s.Trusted, Partial: s.Partial}
+		if s.Certificate != nil {
+			j.Issuer = s.Certificate.Issuer.CommonName
+		}
+		if !s.Time.IsZero() {
+			j.Time = s.Time.Format(time.RFC3339)
+		}
+		if s.Err != nil {
+			j.Error = s.Err.Error()
+			allValid = false
+		}
+		out = append(out, j)
+	}
+
+	if jsonOutput {
+		PrintJSON(out)
+	} else if len(sigs) == 0 {
+		fmt.Printf("%s is not signed\n", inputName(*input))
+	} else {
+		fmt.Printf("Signatures in %s: %d\n", inputName(*input), len(sigs))
+		for i, s := range out {
+			status := "valid"
+			if !s.Valid {
+				status = "INVALID: " + s.Error
+			}
+			fmt.Printf("  %d. %s - %s\n", i+1, s.Signer, status)
+			if s.Time != "" {
+				fmt.Printf("     Signed: %s\n", s.Time)
+			}
+			if s.Reason != "" {
+				fmt.Printf("     Reason: %s\n", s.Reason)
+			}
+			if s.Location != "" {
+				fmt.Printf("     Location: %s\n", s.Location)
+			}
+			if s.Trusted {
+				fmt.Printf("     Certificate: trusted\n")
+			} else {
+				fmt.Printf("     Certificate: not trusted (issued by %s)\n", s.Issuer)
+			}
+			if s.Partial {
+				fmt.Printf("     Covers the document as it was before later changes\n")
+			}
+		}
+	}
+
+	if !allValid {
+		os.Exit(1)
+	}
+}
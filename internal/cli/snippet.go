@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/snippets"
+)
+
+// HandleSnippet handles the snippet command
+func HandleSnippet(args []string) {
+	if err := SnippetCommand(args); err != nil {
+		ExitWithError("%v", err)
+	}
+}
+
+// SnippetCommand handles snippet library operations
+func SnippetCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("snippet command requires subcommand: save, insert, list")
+	}
+
+	switch args[0] {
+	case "save":
+		return snippetSaveCommand(args[1:])
+	case "insert":
+		return snippetInsertCommand(args[1:])
+	case "list":
+		return snippetListCommand(args[1:])
+	default:
+		return fmt.Errorf("unknown snippet subcommand: %s", args[0])
+	}
+}
+
+// snippetSaveCommand adds a paragraph range or a table of a document to a library
+func snippetSaveCommand(args []string) error {
+	fs := flag.NewFlagSet("snippet save", flag.ExitOnError)
+
+	var (
+		libraryPath = fs.String("library", "", "Snippet library file, created if missing (required)")
+		name        = fs.String("name", "", "Snippet name, e.g. signature-block (required)")
+		inputPath   = fs.String("input", "", "Document to take the snippet from (required)")
+		paragraph   = fs.Int("paragraph", -1, "Paragraph index to save")
+		start       = fs.Int("start", -1, "Start index of a paragraph range to save")
+		end         = fs.Int("end", -1, "End index of a paragraph range to save (inclusive)")
+		table       = fs.Int("table", -1, "Table index to save")
+		description = fs.String("description", "", "What the snippet is for")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *libraryPath == "" || *name == "" || *inputPath == "" {
+		return fmt.Errorf("-library, -name and -input are required")
+	}
+
+	lib, err := snippets.Open(*libraryPath)
+	if err != nil {
+		return err
+	}
+	doc, err := openDOCX(*inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to open document: %v", err)
+	}
+	defer doc.Close()
+
+	switch {
+	case *start >= 0 && *end >= 0:
+		err = lib.SaveRange(*name, *description, doc, *start, *end)
+	case *paragraph >= 0:
+		err = lib.SaveRange(*name, *description, doc, *paragraph, *paragraph)
+	case *table >= 0:
+		err = lib.SaveTable(*name, *description, doc, *table)
+	default:
+		return fmt.Errorf("specify -paragraph, -table, or -start/-end")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save snippet: %v", err)
+	}
+
+	if err := lib.Save(*libraryPath); err != nil {
+		return fmt.Errorf("failed to save library: %v", err)
+	}
+	fmt.Fprintf(messages, "Snippet %q saved to %s\n", *name, *libraryPath)
+	return nil
+}
+
+// snippetInsertCommand inserts a snippet from a library into a document
+func snippetInsertCommand(args []string) error {
+	fs := flag.NewFlagSet("snippet insert", flag.ExitOnError)
+
+	var (
+		libraryPath = fs.String("library", "", "Snippet library file (required)")
+		name        = fs.String("name", "", "Snippet name (required)")
+		inputPath   = fs.String("input", "", "Input .docx file path (required)")
+		outputPath  = fs.String("output", "", "Output .docx file path (required)")
+		at          = fs.Int("at", -1, "Paragraph index to insert before; the end of the document when omitted")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	useStdout(*outputPath)
+	if *libraryPath == "" || *name == "" || *inputPath == "" || *outputPath == "" {
+		return fmt.Errorf("-library, -name, -input and -output are required")
+	}
+
+	lib, err := snippets.Open(*libraryPath)
+	if err != nil {
+		return err
+	}
+	doc, err := openDOCX(*inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to open document: %v", err)
+	}
+	defer doc.Close()
+
+	pos := *at
+	if pos < 0 {
+		pos = doc.GetParagraphCount()
+	}
+	if err := lib.InsertSnippet(doc, *name, pos); err != nil {
+		return fmt.Errorf("failed to insert snippet: %v", err)
+	}
+
+	if err := saveDOCX(doc, *outputPath); err != nil {
+		return fmt.Errorf("failed to save document: %v", err)
+	}
+	fmt.Fprintf(messages, "Snippet %q inserted at position %d. Document saved as %s\n", *name, pos, displayName(*outputPath))
+	return nil
+}
+
+// snippetListCommand lists the snippets of a library
+func snippetListCommand(args []string) error {
+	fs := flag.NewFlagSet("snippet list", flag.ExitOnError)
+	libraryPath := fs.String("library", "", "Snippet library file (required)")
+	AddJSONFlag(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *libraryPath == "" {
+		return fmt.Errorf("-library is required")
+	}
+
+	lib, err := snippets.Open(*libraryPath)
+	if err != nil {
+		return err
+	}
+	infos := lib.List()
+
+	if jsonOutput {
+		PrintJSON(infos)
+		return nil
+	}
+	if len(infos) == 0 {
+		fmt.Printf("%s has no snippets\n", *libraryPath)
+		return nil
+	}
+	fmt.Printf("Snippets in %s: %d\n", *libraryPath, len(infos))
+	for _, info := range infos {
+		fmt.Printf("  %s (%s)\n", info.Name, info.Kind)
+		if info.Description != "" {
+			fmt.Printf("     %s\n", info.Description)
+		}
+		if info.Preview != "" {
+			fmt.Printf("     %q\n", info.Preview)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
+	"github.com/Palaciodiego008/docxsmith/pkg/storage"
+	"github.com/Palaciodiego008/docxsmith/pkg/template"
+)
+
+// stdioPath is the file name standing for stdin as -input and stdout as -output
+const stdioPath = "-"
+
+// messages receives progress and success messages. It is stderr when a
+// command writes its output to stdout, so the two don't mix.
+var messages io.Writer = os.Stdout
+
+// useStdout sends messages to stderr if output is stdout
+func useStdout(output string) {
+	if output == stdioPath {
+		messages = os.Stderr
+	}
+}
+
+// displayName names an output file in messages
+func displayName(path string) string {
+	if path == stdioPath {
+		return "stdout"
+	}
+	return path
+}
+
+// inputName names an input file in messages
+func inputName(path string) string {
+	if path == stdioPath {
+		return "stdin"
+	}
+	return path
+}
+
+// readInput reads a whole file, or stdin for "-"
+func readInput(path string) ([]byte, error) {
+	if path == stdioPath {
+		return io.ReadAll(os.Stdin)
+	}
+	return storage.ReadFile(context.Background(), path)
+}
+
+// openDOCX opens a DOCX file or storage URI, or reads one from stdin for "-"
+func openDOCX(path string) (*docx.Document, error) {
+	if path == stdioPath {
+		return docx.ReadFrom(os.Stdin)
+	}
+	return storage.OpenDOCX(context.Background(), path)
+}
+
+// openPDF opens a PDF file or storage URI, or reads one from stdin for "-"
+func openPDF(path string) (*pdf.Document, error) {
+	if path == stdioPath {
+		return pdf.ReadFrom(os.Stdin)
+	}
+	return storage.OpenPDF(context.Background(), path)
+}
+
+// closeDocuments closes documents opened for a command
+func closeDocuments(docs []*docx.Document) {
+	for _, doc := range docs {
+		doc.Close()
+	}
+}
+
+// loadTemplate loads a template file, or reads one from stdin for "-".
+// Partials of a template read from stdin are relative to the working directory.
+func loadTemplate(path string) (*template.Template, error) {
+	if path == stdioPath {
+		doc, err := docx.ReadFrom(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		return template.New(doc), nil
+	}
+	return template.Load(path)
+}
+
+// saveDOCX saves a document to a file or storage URI, or writes it to stdout
+// for "-". The extension of a file (.docx, .docm, .dotx or .dotm) sets the
+// type of Word package.
+func saveDOCX(doc *docx.Document, path string) error {
+	if path == stdioPath {
+		_, err := doc.WriteTo(os.Stdout)
+		return err
+	}
+	if storage.IsRemote(path) {
+		return storage.SaveDOCX(context.Background(), doc, path)
+	}
+	if _, ok := docx.TypeForExtension(filepath.Ext(path)); ok {
+		return doc.SaveAs(path, docx.WithOverwrite())
+	}
+	return doc.Save(path)
+}
+
+// savePDF saves a PDF document to a file or storage URI, or writes it to
+// stdout for "-"
+func savePDF(doc *pdf.Document, path string) error {
+	if path == stdioPath {
+		_, err := doc.WriteTo(os.Stdout)
+		return err
+	}
+	return storage.SavePDF(context.Background(), doc, path)
+}
+
+// writeOutput writes data to a file, or to stdout for "-"
+func writeOutput(path string, data []byte) error {
+	if path == stdioPath {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if storage.IsRemote(path) {
+		return storage.WriteFile(context.Background(), path, data)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// sniffFormat returns the extension matching the content of a file read
+// from stdin, which has no name to go by
+func sniffFormat(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF")):
+		return ".pdf", nil
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return ".docx", nil
+	default:
+		return "", fmt.Errorf("input is neither a DOCX nor a PDF document")
+	}
+}
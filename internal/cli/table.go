@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-
-	"github.com/Palaciodiego008/docxsmith/pkg/docx"
 )
 
 // HandleTable handles the table command
@@ -19,6 +17,7 @@ func HandleTable(args []string) {
 	cols := fs.Int("cols", 2, "Number of columns")
 	setCellText := fs.String("set", "", "Set cell text (format: 'tableIdx,row,col,text')")
 	fs.Parse(args)
+	useStdout(*output)
 
 	if *input == "" || *output == "" {
 		fmt.Fprintln(os.Stderr, "Error: -input and -output are required")
@@ -26,15 +25,16 @@ func HandleTable(args []string) {
 		os.Exit(1)
 	}
 
-	doc, err := docx.Open(*input)
+	doc, err := openDOCX(*input)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening document: %v\n", err)
 		os.Exit(1)
 	}
+	defer doc.Close()
 
 	if *create {
 		table := doc.AddTable(*rows, *cols)
-		fmt.Printf("Created table with %d rows and %d columns\n", *rows, *cols)
+		fmt.Fprintf(messages, "Created table with %d rows and %d columns\n", *rows, *cols)
 
 		// Set header row as example
 		if *rows > 0 && *cols > 0 {
@@ -76,13 +76,13 @@ func HandleTable(args []string) {
 			fmt.Fprintf(os.Stderr, "Error setting cell text: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("Set cell [%d,%d] in table %d to: %s\n", row, col, tableIdx, text)
+		fmt.Fprintf(messages, "Set cell [%d,%d] in table %d to: %s\n", row, col, tableIdx, text)
 	}
 
-	if err := doc.Save(*output); err != nil {
+	if err := saveDOCX(doc, *output); err != nil {
 		fmt.Fprintf(os.Stderr, "Error saving document: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Document saved: %s\n", *output)
+	fmt.Fprintf(messages, "Document saved: %s\n", displayName(*output))
 }
@@ -1,12 +1,15 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/operations"
 	"github.com/Palaciodiego008/docxsmith/pkg/template"
 	"gopkg.in/yaml.v3"
 )
@@ -15,30 +18,65 @@ import (
 func HandleTemplateRender(args []string) {
 	fs := flag.NewFlagSet("template-render", flag.ExitOnError)
 	templatePath := fs.String("template", "", "Template file path (required)")
-	dataPath := fs.String("data", "", "Data file path (JSON or YAML) (required)")
+	dataPath := fs.String("data", "", "Data file path or http(s) URL (JSON or YAML)")
 	output := fs.String("output", "", "Output file path (required)")
 	strict := fs.Bool("strict", false, "Strict mode - fail on missing variables")
 	defaultVal := fs.String("default", "", "Default value for missing variables")
 	keepEmpty := fs.Bool("keep-empty", false, "Keep empty paragraphs")
+	mergeFields := fs.Bool("merge-fields", false, "Also fill Word MERGEFIELD fields from the data")
+	locale := fs.String("locale", "", "Language tag numbers, amounts and dates are formatted for, e.g. de or pt-BR")
+	richValues := fs.Bool("rich-values", false, "Render booleans as checkboxes, lists as bullets and maps as tables")
+	var partials StringListFlag
+	fs.Var(&partials, "partial", "Partial for {{include}} as name=file.docx; repeat for several")
+	var sets StringListFlag
+	fs.Var(&sets, "set", "Data value as Key=Value (Key may be a dotted path); repeat for several")
+	envPrefix := fs.String("env-prefix", "", "Also read data from environment variables starting with this prefix, e.g. DOCX_")
+	AddDataSourceFlags(fs)
 	fs.Parse(args)
+	useStdout(*output)
 
-	if *templatePath == "" || *dataPath == "" || *output == "" {
-		fmt.Fprintln(os.Stderr, "Error: -template, -data, and -output are required")
+	if *templatePath == "" || *output == "" || (*dataPath == "" && len(sets) == 0 && *envPrefix == "") {
+		fmt.Fprintln(os.Stderr, "Error: -template, -output, and -data, -set or -env-prefix are required")
 		fs.Usage()
 		os.Exit(1)
 	}
+	if *templatePath == stdioPath && *dataPath == stdioPath {
+		fmt.Fprintln(os.Stderr, "Error: only one of -template and -data can be read from stdin")
+		os.Exit(1)
+	}
 
 	// Load template
-	tmpl, err := template.Load(*templatePath)
+	tmpl, err := loadTemplate(*templatePath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading template: %v\n", err)
 		os.Exit(1)
 	}
+	defer tmpl.Close()
 
-	// Load data
-	data, err := loadDataFile(*dataPath)
+	// Load partials
+	opened, err := addPartials(tmpl, partials)
+	defer closeDocuments(opened)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading data: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Load data: the file, then the environment, then -set values
+	data := template.Data{}
+	if *dataPath != "" {
+		if data, err = loadDataFile(*dataPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading data: %v\n", err)
+			os.Exit(1)
+		}
+		if data == nil {
+			data = template.Data{}
+		}
+	}
+	if *envPrefix != "" {
+		data.Merge(template.EnvData(*envPrefix))
+	}
+	if err := data.SetPairs(sets); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -47,22 +85,257 @@ func HandleTemplateRender(args []string) {
 		StrictMode:            *strict,
 		DefaultValue:          *defaultVal,
 		RemoveEmptyParagraphs: !*keepEmpty,
+		MergeFields:           *mergeFields,
+		Locale:                *locale,
+		RichValues:            *richValues,
 	}
 
 	// Render
-	err = tmpl.RenderToFile(data, *output, opts)
+	if *output == stdioPath {
+		err = tmpl.RenderTo(data, os.Stdout, opts)
+	} else {
+		err = tmpl.RenderToFile(data, *output, opts)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error rendering template: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Template rendered successfully: %s\n", *output)
+	fmt.Fprintf(messages, "Template rendered successfully: %s\n", displayName(*output))
+}
+
+// HandleTemplateRenderBatch handles the template-render-batch command
+func HandleTemplateRenderBatch(args []string) {
+	fs := flag.NewFlagSet("template-render-batch", flag.ExitOnError)
+	templatePath := fs.String("template", "", "Template file path (required)")
+	dataPath := fs.String("data", "", "Records file: CSV with a header row, or a JSON or YAML list (required)")
+	outputDir := fs.String("output-dir", "", "Directory for one document per record")
+	pattern := fs.String("pattern", "document_{n}.docx", "File name of each document; {n} is the record number, {Field} a field value")
+	sanitize := fs.String("sanitize", "safe", "How values are cleaned up for file names: safe, strict or none")
+	output := fs.String("output", "", "Write all records to this single document instead")
+	noPageBreaks := fs.Bool("no-page-breaks", false, "Don't start each record on a new page of the single document")
+	strict := fs.Bool("strict", false, "Strict mode - fail on missing variables")
+	defaultVal := fs.String("default", "", "Default value for missing variables")
+	keepEmpty := fs.Bool("keep-empty", false, "Keep empty paragraphs")
+	mergeFields := fs.Bool("merge-fields", false, "Also fill Word MERGEFIELD fields from the data")
+	locale := fs.String("locale", "", "Language tag numbers, amounts and dates are formatted for, e.g. de or pt-BR")
+	richValues := fs.Bool("rich-values", false, "Render booleans as checkboxes, lists as bullets and maps as tables")
+	var partials StringListFlag
+	fs.Var(&partials, "partial", "Partial for {{include}} as name=file.docx; repeat for several")
+	AddVerbosityFlags(fs)
+	fs.Parse(args)
+
+	if *templatePath == "" || *dataPath == "" || (*outputDir == "" && *output == "") {
+		fmt.Fprintln(os.Stderr, "Error: -template, -data, and -output-dir or -output are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	tmpl, err := loadTemplate(*templatePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading template: %v\n", err)
+		os.Exit(1)
+	}
+	defer tmpl.Close()
+	opened, err := addPartials(tmpl, partials)
+	defer closeDocuments(opened)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	records, err := operations.LoadRecords(*dataPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading records: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts := operations.DefaultMailMergeOptions()
+	opts.OutputDir = *outputDir
+	opts.FilenamePattern = *pattern
+	opts.Sanitize = operations.SanitizeMode(*sanitize)
+	opts.OutputPath = *output
+	opts.PageBreaks = !*noPageBreaks
+	opts.Render = template.RenderOptions{
+		StrictMode:            *strict,
+		DefaultValue:          *defaultVal,
+		RemoveEmptyParagraphs: !*keepEmpty,
+		MergeFields:           *mergeFields,
+		Locale:                *locale,
+		RichValues:            *richValues,
+	}
+	opts.Logger = logger()
+	var done func()
+	opts.Progress, done = progress()
+
+	result, err := operations.MailMerge(tmpl, records, opts)
+	done()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering records: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *output != "" {
+		fmt.Printf("Rendered %d records into: %s\n", result.Records, *output)
+		return
+	}
+	fmt.Printf("Rendered %d documents into: %s\n", result.Records, *outputDir)
+	for _, path := range result.Paths {
+		fmt.Printf("  - %s\n", path)
+	}
+}
+
+// HandleTemplateValidate handles the template-validate command
+func HandleTemplateValidate(args []string) {
+	fs := flag.NewFlagSet("template-validate", flag.ExitOnError)
+	templatePath := fs.String("template", "", "Template file path (required)")
+	dataPath := fs.String("data", "", "Data file path or http(s) URL (JSON or YAML) (required)")
+	var partials StringListFlag
+	fs.Var(&partials, "partial", "Partial for {{include}} as name=file.docx; repeat for several")
+	AddDataSourceFlags(fs)
+	AddJSONFlag(fs)
+	fs.Parse(args)
+
+	if *templatePath == "" || *dataPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: -template and -data are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	tmpl, err := loadTemplate(*templatePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading template: %v\n", err)
+		os.Exit(1)
+	}
+	defer tmpl.Close()
+	opened, err := addPartials(tmpl, partials)
+	defer closeDocuments(opened)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	data, err := loadDataFile(*dataPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading data: %v\n", err)
+		os.Exit(1)
+	}
+
+	report := tmpl.Validate(data)
+	if jsonOutput {
+		issues := report.Issues
+		if issues == nil {
+			issues = []template.Issue{}
+		}
+		PrintJSON(validateJSON{Template: *templatePath, Valid: report.Valid(), Issues: issues})
+		if !report.Valid() {
+			os.Exit(1)
+		}
+		return
+	}
+	if report.Valid() {
+		fmt.Printf("Template is valid: %s\n", *templatePath)
+		return
+	}
+
+	fmt.Printf("Found %d issue(s) in %s:\n", len(report.Issues), *templatePath)
+	for _, issue := range report.Issues {
+		fmt.Printf("  - %s\n", issue)
+	}
+	os.Exit(1)
+}
+
+// validateJSON is the output of template-validate -json
+type validateJSON struct {
+	Template string           `json:"template"`
+	Valid    bool             `json:"valid"`
+	Issues   []template.Issue `json:"issues"`
+}
+
+// HandleTemplateSchema handles the template-schema command
+func HandleTemplateSchema(args []string) {
+	fs := flag.NewFlagSet("template-schema", flag.ExitOnError)
+	templatePath := fs.String("template", "", "Template file path (required)")
+	format := fs.String("format", "schema", "Output format: schema (JSON Schema), json or yaml (data skeleton)")
+	output := fs.String("output", "", "Output file path (default: stdout)")
+	var partials StringListFlag
+	fs.Var(&partials, "partial", "Partial for {{include}} as name=file.docx; repeat for several")
+	fs.Parse(args)
+
+	if *templatePath == "" {
+		fmt.Fprintln(os.Stderr, "Error: -template is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	tmpl, err := loadTemplate(*templatePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading template: %v\n", err)
+		os.Exit(1)
+	}
+	defer tmpl.Close()
+	opened, err := addPartials(tmpl, partials)
+	defer closeDocuments(opened)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	schema := tmpl.GetSchema()
+	var content []byte
+	switch *format {
+	case "schema":
+		content, err = schema.JSON()
+	case "json":
+		content, err = json.MarshalIndent(schema.Skeleton(), "", "  ")
+	case "yaml":
+		content, err = yaml.Marshal(schema.Skeleton())
+	default:
+		err = fmt.Errorf("unknown format %q (use schema, json or yaml)", *format)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		content = append(content, '\n')
+	}
+
+	if *output == "" || *output == stdioPath {
+		os.Stdout.Write(content)
+		return
+	}
+	if err := os.WriteFile(*output, content, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing schema: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Schema written to: %s\n", *output)
+}
+
+// addPartials registers partials given as name=file.docx, returning the
+// documents it opened for them, which are closed once rendering is done
+func addPartials(tmpl *template.Template, partials []string) ([]*docx.Document, error) {
+	var opened []*docx.Document
+	for _, partial := range partials {
+		name, path, ok := strings.Cut(partial, "=")
+		if !ok || name == "" || path == "" {
+			return opened, fmt.Errorf("invalid partial %q, expected name=file.docx", partial)
+		}
+		doc, err := docx.Open(path)
+		if err != nil {
+			return opened, fmt.Errorf("failed to load partial %s: %w", name, err)
+		}
+		tmpl.AddPartial(name, doc)
+		opened = append(opened, doc)
+	}
+	return opened, nil
 }
 
 // HandleTemplateVariables handles the template-variables command
 func HandleTemplateVariables(args []string) {
 	fs := flag.NewFlagSet("template-variables", flag.ExitOnError)
 	templatePath := fs.String("template", "", "Template file path (required)")
+	AddJSONFlag(fs)
 	fs.Parse(args)
 
 	if *templatePath == "" {
@@ -72,15 +345,23 @@ func HandleTemplateVariables(args []string) {
 	}
 
 	// Load template
-	tmpl, err := template.Load(*templatePath)
+	tmpl, err := loadTemplate(*templatePath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading template: %v\n", err)
 		os.Exit(1)
 	}
+	defer tmpl.Close()
 
 	// Get variables
 	variables := tmpl.GetVariables()
 
+	if jsonOutput {
+		if variables == nil {
+			variables = []string{}
+		}
+		PrintJSON(variablesJSON{Template: *templatePath, Count: len(variables), Variables: variables})
+		return
+	}
 	if len(variables) == 0 {
 		fmt.Println("No variables found in template")
 		return
@@ -92,6 +373,13 @@ func HandleTemplateVariables(args []string) {
 	}
 }
 
+// variablesJSON is the output of template-variables -json
+type variablesJSON struct {
+	Template  string   `json:"template"`
+	Count     int      `json:"count"`
+	Variables []string `json:"variables"`
+}
+
 // HandleTemplateExample handles the template-example command
 func HandleTemplateExample(args []string) {
 	fs := flag.NewFlagSet("template-example", flag.ExitOnError)
@@ -152,28 +440,49 @@ func HandleTemplateExample(args []string) {
 	fmt.Printf("  docxsmith template-render -template %s -data %s -output result.docx\n", *outputTemplate, *outputData)
 }
 
-// loadDataFile loads data from JSON or YAML file
-func loadDataFile(path string) (template.Data, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read data file: %w", err)
-	}
+// dataHeaders, dataToken and dataUser are set by the flags AddDataSourceFlags adds
+var (
+	dataHeaders StringListFlag
+	dataToken   string
+	dataUser    string
+)
 
-	var result template.Data
+// AddDataSourceFlags adds the flags for requesting data from a URL
+func AddDataSourceFlags(fs *flag.FlagSet) {
+	fs.Var(&dataHeaders, "header", "Header sent when -data is a URL, as \"Name: Value\"; repeat for several")
+	fs.StringVar(&dataToken, "bearer-token", "", "Bearer token sent when -data is a URL")
+	fs.StringVar(&dataUser, "user", "", "Basic auth user:password sent when -data is a URL")
+}
 
-	// Try JSON first
-	err = json.Unmarshal(data, &result)
-	if err == nil {
-		return result, nil
+// loadDataFile loads data from a JSON or YAML file, stdin for "-", or an
+// http or https URL
+func loadDataFile(path string) (template.Data, error) {
+	if template.IsURL(path) {
+		opts := template.DefaultFetchOptions()
+		opts.Headers = make(map[string]string)
+		for _, header := range dataHeaders {
+			name, value, ok := strings.Cut(header, ":")
+			if !ok || strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("invalid header %q, expected \"Name: Value\"", header)
+			}
+			opts.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+		opts.BearerToken = dataToken
+		if dataUser != "" {
+			opts.Username, opts.Password, _ = strings.Cut(dataUser, ":")
+		}
+		return template.FetchData(context.Background(), path, opts)
 	}
 
-	// Try YAML
-	err = yaml.Unmarshal(data, &result)
-	if err == nil {
-		return result, nil
+	data, err := readInput(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file: %w", err)
 	}
-
-	return nil, fmt.Errorf("failed to parse data file as JSON or YAML")
+	result, err := template.ParseData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse data file as JSON or YAML")
+	}
+	return result, nil
 }
 
 // createExampleTemplate creates an example template document
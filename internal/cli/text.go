@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/docx"
 )
@@ -16,19 +18,40 @@ func HandleReplace(args []string) {
 	oldText := fs.String("old", "", "Text to replace (required)")
 	newText := fs.String("new", "", "Replacement text (required)")
 	paragraph := fs.Int("paragraph", -1, "Only replace in specific paragraph")
+	bold := fs.Bool("bold", false, "Make the replacements bold (body and tables)")
+	color := fs.String("color", "", "Color the replacements, hex without #, e.g. FF0000 (body and tables)")
+	include := AddScopeFlag(fs)
 	fs.Parse(args)
+	useStdout(*output)
 
 	if *input == "" || *output == "" || *oldText == "" || *newText == "" {
 		fmt.Fprintln(os.Stderr, "Error: -input, -output, -old, and -new are required")
 		fs.Usage()
 		os.Exit(1)
 	}
+	var opts []docx.ParagraphOption
+	if *bold {
+		opts = append(opts, docx.WithBold())
+	}
+	if *color != "" {
+		opts = append(opts, docx.WithColor(strings.TrimPrefix(*color, "#")))
+	}
+	if len(opts) > 0 && *paragraph >= 0 {
+		fmt.Fprintln(os.Stderr, "Error: -bold and -color can't be combined with -paragraph")
+		os.Exit(1)
+	}
+	scope, err := parseScope(*include)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-	doc, err := docx.Open(*input)
+	doc, err := openDOCX(*input)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening document: %v\n", err)
 		os.Exit(1)
 	}
+	defer doc.Close()
 
 	var count int
 	if *paragraph >= 0 {
@@ -37,17 +60,23 @@ func HandleReplace(args []string) {
 			fmt.Fprintf(os.Stderr, "Error replacing text: %v\n", err)
 			os.Exit(1)
 		}
+	} else if len(opts) > 0 {
+		// The body and tables get formatted replacements, the other parts
+		// of the scope plain ones
+		count = doc.ReplaceTextStyled(*oldText, *newText, opts...)
+		scope.Body, scope.Tables = false, false
+		count += doc.ReplaceTextIn(*oldText, *newText, scope)
 	} else {
-		count = doc.ReplaceText(*oldText, *newText)
+		count = doc.ReplaceTextIn(*oldText, *newText, scope)
 	}
 
-	if err := doc.Save(*output); err != nil {
+	if err := saveDOCX(doc, *output); err != nil {
 		fmt.Fprintf(os.Stderr, "Error saving document: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Replaced %d occurrence(s) of '%s' with '%s'\n", count, *oldText, *newText)
-	fmt.Printf("Document saved: %s\n", *output)
+	fmt.Fprintf(messages, "Replaced %d occurrence(s) of '%s' with '%s'\n", count, *oldText, *newText)
+	fmt.Fprintf(messages, "Document saved: %s\n", displayName(*output))
 }
 
 // HandleFind handles the find command
@@ -55,6 +84,8 @@ func HandleFind(args []string) {
 	fs := flag.NewFlagSet("find", flag.ExitOnError)
 	input := fs.String("input", "", "Input file path (required)")
 	text := fs.String("text", "", "Text to find (required)")
+	include := AddScopeFlag(fs)
+	AddJSONFlag(fs)
 	fs.Parse(args)
 
 	if *input == "" || *text == "" {
@@ -62,62 +93,161 @@ func HandleFind(args []string) {
 		fs.Usage()
 		os.Exit(1)
 	}
+	scope, err := parseScope(*include)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-	doc, err := docx.Open(*input)
+	doc, err := openDOCX(*input)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening document: %v\n", err)
 		os.Exit(1)
 	}
-
-	indices := doc.FindText(*text)
-	if len(indices) == 0 {
+	defer doc.Close()
+
+	matches := doc.FindTextIn(*text, scope)
+	if jsonOutput {
+		result := findJSON{File: *input, Text: *text, Count: len(matches), Matches: []findMatchJSON{}}
+		for _, m := range matches {
+			match := findMatchJSON{Index: m.Paragraph, Text: m.Text}
+			if m.Part != "word/document.xml" {
+				match.Part = m.Part
+			}
+			if m.Table >= 0 {
+				match.Table, match.Row, match.Column = &m.Table, &m.Row, &m.Column
+			}
+			result.Matches = append(result.Matches, match)
+		}
+		PrintJSON(result)
+		return
+	}
+	if len(matches) == 0 {
 		fmt.Printf("Text '%s' not found in document\n", *text)
 		return
 	}
 
-	fmt.Printf("Found '%s' in %d paragraph(s):\n", *text, len(indices))
-	for _, idx := range indices {
-		text, err := doc.GetParagraphText(idx)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "  Paragraph %d: Error retrieving text: %v\n", idx, err)
-			continue
-		}
-		preview := text
+	fmt.Printf("Found '%s' in %d paragraph(s):\n", *text, len(matches))
+	for _, m := range matches {
+		preview := m.Text
 		if len(preview) > 80 {
 			preview = preview[:77] + "..."
 		}
-		fmt.Printf("  Paragraph %d: %s\n", idx, preview)
+		switch {
+		case m.Table >= 0:
+			fmt.Printf("  Table %d row %d column %d: %s\n", m.Table, m.Row, m.Column, preview)
+		case m.Part == "word/document.xml":
+			fmt.Printf("  Paragraph %d: %s\n", m.Paragraph, preview)
+		default:
+			fmt.Printf("  %s paragraph %d: %s\n", strings.TrimPrefix(m.Part, "word/"), m.Paragraph, preview)
+		}
 	}
 }
 
+// findJSON is the output of find -json
+type findJSON struct {
+	File    string          `json:"file"`
+	Text    string          `json:"text"`
+	Count   int             `json:"count"`
+	Matches []findMatchJSON `json:"matches"`
+}
+
+// findMatchJSON is a paragraph containing the text, by its 0-based index in
+// the body or, for matches in table cells and in headers and other parts,
+// in the cell or the part
+type findMatchJSON struct {
+	Part   string `json:"part,omitempty"`
+	Table  *int   `json:"table,omitempty"`
+	Row    *int   `json:"row,omitempty"`
+	Column *int   `json:"column,omitempty"`
+	Index  int    `json:"index"`
+	Text   string `json:"text"`
+}
+
 // HandleExtract handles the extract command
 func HandleExtract(args []string) {
 	fs := flag.NewFlagSet("extract", flag.ExitOnError)
 	input := fs.String("input", "", "Input file path (required)")
 	output := fs.String("output", "", "Output text file (optional)")
+	format := fs.String("format", "text", "Output format: text, or json for blocks with their type, style and level")
+	include := AddScopeFlag(fs)
+	skipHidden := fs.Bool("skip-hidden", false, "Leave out text formatted as hidden")
+	AddJSONFlag(fs)
 	fs.Parse(args)
+	useStdout(*output)
 
 	if *input == "" {
 		fmt.Fprintln(os.Stderr, "Error: -input is required")
 		fs.Usage()
 		os.Exit(1)
 	}
+	if jsonOutput {
+		*format = "json"
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q, expected text or json\n", *format)
+		os.Exit(1)
+	}
+	scope, err := parseScope(*include)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *include != "" && *format == "json" {
+		fmt.Fprintln(os.Stderr, "Error: -include is only supported with -format text")
+		os.Exit(1)
+	}
 
-	doc, err := docx.Open(*input)
+	doc, err := openDOCX(*input)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening document: %v\n", err)
 		os.Exit(1)
 	}
+	defer doc.Close()
+	if *skipHidden {
+		// The document isn't saved, so drop hidden text for both formats
+		doc.RemoveHiddenText()
+	}
 
-	text := doc.GetText()
+	text := doc.GetTextIn(scope)
+	if *format == "json" {
+		blocks := []blockJSON{}
+		for _, b := range doc.GetStructuredText() {
+			block := blockJSON{Type: string(b.Type), Text: b.Text, Style: b.Style, Level: b.Level}
+			if b.Type == docx.BlockTableCell {
+				block.Table, block.Row, block.Column = &b.Table, &b.Row, &b.Column
+			} else {
+				block.Paragraph = &b.Paragraph
+			}
+			blocks = append(blocks, block)
+		}
+		data, err := json.MarshalIndent(blocks, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		text = string(data)
+	}
 
 	if *output != "" {
-		if err := os.WriteFile(*output, []byte(text), 0644); err != nil {
+		if err := writeOutput(*output, []byte(text)); err != nil {
 			fmt.Fprintf(os.Stderr, "Error writing output file: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("Text extracted to: %s\n", *output)
+		fmt.Fprintf(messages, "Text extracted to: %s\n", displayName(*output))
 	} else {
 		fmt.Println(text)
 	}
 }
+
+// blockJSON is a block of text in extract -format json output
+type blockJSON struct {
+	Type      string `json:"type"`
+	Text      string `json:"text"`
+	Style     string `json:"style,omitempty"`
+	Level     int    `json:"level,omitempty"`
+	Paragraph *int   `json:"paragraph,omitempty"`
+	Table     *int   `json:"table,omitempty"`
+	Row       *int   `json:"row,omitempty"`
+	Column    *int   `json:"column,omitempty"`
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/operations"
+)
+
+// HandleWatermark handles the watermark command
+func HandleWatermark(args []string) {
+	fs := flag.NewFlagSet("watermark", flag.ExitOnError)
+	input := fs.String("input", "", "Input DOCX or PDF file (required)")
+	output := fs.String("output", "", "Output file path (defaults to input)")
+	text := fs.String("text", "", "Watermark text, e.g. DRAFT or CONFIDENTIAL (required)")
+	color := fs.String("color", "", "Watermark color in hex (default: C0C0C0)")
+	opacity := fs.Float64("opacity", 0, "Watermark opacity from 0 to 1")
+	horizontal := fs.Bool("horizontal", false, "Lay the watermark out horizontally instead of diagonally")
+	AddVerbosityFlags(fs)
+	fs.Parse(args)
+
+	if *input == "" || *text == "" {
+		fmt.Fprintln(os.Stderr, "Error: -input and -text are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	outputPath := *output
+	if outputPath == "" {
+		outputPath = *input
+	}
+
+	opts := operations.WatermarkOptions{
+		Color:      *color,
+		Opacity:    *opacity,
+		Horizontal: *horizontal,
+		Logger:     logger(),
+	}
+	if err := operations.Watermark(*input, outputPath, *text, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error adding watermark: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Watermark %q added: %s\n", *text, outputPath)
+}
@@ -0,0 +1,187 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// EngineNative is the engine of the built-in, pure Go converter
+const EngineNative = "native"
+
+// ErrEngineUnavailable is returned when a conversion engine is unknown or
+// can't run on this machine, such as LibreOffice when it isn't installed
+var ErrEngineUnavailable = errors.New("conversion engine unavailable")
+
+// ConverterBackend converts DOCX documents to PDF in place of the built-in
+// converter, usually by running an external program. Backends are chosen
+// by the Engine of ConvertOptions.
+type ConverterBackend interface {
+	ConvertDocxToPDF(doc *docx.Document, w io.Writer) error
+}
+
+// ContextBackend is a backend whose conversions stop when a context is
+// canceled or its deadline passes. Backends that don't implement it run to
+// the end once started.
+type ContextBackend interface {
+	ConverterBackend
+	ConvertDocxToPDFContext(ctx context.Context, doc *docx.Document, w io.Writer) error
+}
+
+// convertWithBackend converts a document with a backend, passing ctx on to
+// backends that take one
+func convertWithBackend(ctx context.Context, b ConverterBackend, doc *docx.Document, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if cb, ok := b.(ContextBackend); ok {
+		return cb.ConvertDocxToPDFContext(ctx, doc, w)
+	}
+	return b.ConvertDocxToPDF(doc, w)
+}
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]ConverterBackend{"libreoffice": &LibreOffice{}}
+)
+
+// RegisterBackend makes a backend available as the engine with the given
+// name. The LibreOffice backend is registered as "libreoffice".
+func RegisterBackend(name string, backend ConverterBackend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[strings.ToLower(name)] = backend
+}
+
+// Engines returns the names of the registered engines, besides the native one
+func Engines() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// backend returns the backend of an engine, or nil for the native converter
+func backend(engine string) (ConverterBackend, error) {
+	engine = strings.ToLower(engine)
+	if engine == "" || engine == EngineNative {
+		return nil, nil
+	}
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	b, ok := backends[engine]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown engine %q", ErrEngineUnavailable, engine)
+	}
+	return b, nil
+}
+
+// LibreOffice converts documents by running LibreOffice headless. Its
+// layout matches Word's far more closely than the native converter's, at
+// the cost of starting soffice for every conversion.
+type LibreOffice struct {
+	// Path of the soffice program; it is looked up on the PATH and in the
+	// usual install locations when empty
+	Path string
+
+	// Timeout stops conversions that take longer; 0 means two minutes
+	Timeout time.Duration
+}
+
+// sofficeLocations are where LibreOffice installs soffice when it isn't on the PATH
+var sofficeLocations = map[string][]string{
+	"darwin":  {"/Applications/LibreOffice.app/Contents/MacOS/soffice"},
+	"windows": {`C:\Program Files\LibreOffice\program\soffice.exe`, `C:\Program Files (x86)\LibreOffice\program\soffice.exe`},
+	"linux":   {"/usr/lib/libreoffice/program/soffice", "/opt/libreoffice/program/soffice"},
+}
+
+// program returns the path of the soffice program to run
+func (l *LibreOffice) program() (string, error) {
+	if l.Path != "" {
+		return l.Path, nil
+	}
+	for _, name := range []string{"soffice", "libreoffice"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	for _, path := range sofficeLocations[runtime.GOOS] {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%w: LibreOffice (soffice) not found; install it or set its path", ErrEngineUnavailable)
+}
+
+// ConvertDocxToPDF converts a document with LibreOffice. The document and
+// a LibreOffice profile of its own are written to a temporary directory, so
+// conversions can run side by side and don't touch the user's profile.
+func (l *LibreOffice) ConvertDocxToPDF(doc *docx.Document, w io.Writer) error {
+	return l.ConvertDocxToPDFContext(context.Background(), doc, w)
+}
+
+// ConvertDocxToPDFContext converts a document with LibreOffice like
+// ConvertDocxToPDF, killing soffice when ctx is done
+func (l *LibreOffice) ConvertDocxToPDFContext(ctx context.Context, doc *docx.Document, w io.Writer) error {
+	program, err := l.program()
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "docxsmith-soffice-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "document.docx")
+	if err := doc.Save(input); err != nil {
+		return err
+	}
+
+	timeout := l.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+	profile := "file://" + filepath.ToSlash(filepath.Join(dir, "profile"))
+	if !strings.HasPrefix(profile, "file:///") {
+		profile = "file:///" + strings.TrimPrefix(profile, "file://") // Windows paths start with a drive letter
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(runCtx, program, "-env:UserInstallation="+profile, "--headless", "--norestore", "--convert-to", "pdf", "--outdir", dir, input)
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if runCtx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("LibreOffice conversion timed out after %v", timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("LibreOffice conversion failed: %w: %s", err, bytes.TrimSpace(output))
+	}
+
+	// soffice reports some failures only by not writing the output
+	data, err := os.ReadFile(filepath.Join(dir, "document.pdf"))
+	if err != nil {
+		return fmt.Errorf("LibreOffice produced no PDF: %s", bytes.TrimSpace(output))
+	}
+	_, err = w.Write(data)
+	return err
+}
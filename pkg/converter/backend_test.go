@@ -0,0 +1,95 @@
+package converter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// recordingBackend writes a fixed PDF and records the documents it converts
+type recordingBackend struct {
+	converted []*docx.Document
+}
+
+func (b *recordingBackend) ConvertDocxToPDF(doc *docx.Document, w io.Writer) error {
+	b.converted = append(b.converted, doc)
+	_, err := w.Write([]byte("%PDF-1.7 from backend"))
+	return err
+}
+
+func TestConverterBackends(t *testing.T) {
+	b := &recordingBackend{}
+	RegisterBackend("Recording", b)
+
+	opts := DefaultOptions()
+	opts.Engine = "recording"
+	doc := newSampleDocument()
+
+	var buf bytes.Buffer
+	if err := NewDocxToPDF(opts).ConvertTo(doc, &buf); err != nil {
+		t.Fatalf("ConvertTo failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "out.pdf")
+	if err := NewDocxToPDF(opts).Convert(doc, path); err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	written, _ := os.ReadFile(path)
+	if buf.String() != "%PDF-1.7 from backend" || string(written) != buf.String() || len(b.converted) != 2 {
+		t.Errorf("Expected both conversions to go through the backend, got %q and %q", buf.String(), written)
+	}
+
+	// The native converter stays the default
+	buf.Reset()
+	if err := NewDocxToPDF(DefaultOptions()).ConvertTo(doc, &buf); err != nil || len(b.converted) != 2 || !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-1.3")) {
+		t.Errorf("Expected a native conversion, got %q, %v", buf.Bytes()[:min(buf.Len(), 8)], err)
+	}
+
+	opts.Engine = "typesetter"
+	if err := NewDocxToPDF(opts).Convert(doc, filepath.Join(t.TempDir(), "none.pdf")); !errors.Is(err, ErrEngineUnavailable) {
+		t.Errorf("Expected ErrEngineUnavailable for an unknown engine, got %v", err)
+	}
+}
+
+func TestLibreOfficeBackend(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stand-in for soffice is a shell script")
+	}
+
+	// A stand-in for soffice that copies the document it is given as the PDF
+	dir := t.TempDir()
+	soffice := filepath.Join(dir, "soffice")
+	script := `#!/bin/sh
+while [ $# -gt 1 ]; do
+	case "$1" in --outdir) outdir="$2" ;; esac
+	shift
+done
+cp "$1" "$outdir/$(basename "$1" .docx).pdf"
+`
+	if err := os.WriteFile(soffice, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := (&LibreOffice{Path: soffice}).ConvertDocxToPDF(newSampleDocument(), &buf); err != nil {
+		t.Fatalf("ConvertDocxToPDF failed: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("PK")) {
+		t.Errorf("Expected the output soffice wrote, got %q", buf.Bytes()[:min(buf.Len(), 8)])
+	}
+
+	failing := filepath.Join(dir, "failing")
+	if err := os.WriteFile(failing, []byte("#!/bin/sh\necho 'source file could not be loaded' >&2\nexit 1\n"), 0755); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	err := (&LibreOffice{Path: failing}).ConvertDocxToPDF(newSampleDocument(), &buf)
+	if err == nil || !strings.Contains(err.Error(), "could not be loaded") {
+		t.Errorf("Expected the soffice error output, got %v", err)
+	}
+}
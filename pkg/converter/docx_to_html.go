@@ -0,0 +1,196 @@
+package converter
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// DocxToHTML converts a DOCX document to a standalone HTML page
+type DocxToHTML struct {
+	Options ConvertOptions
+}
+
+// NewDocxToHTML creates a new DOCX to HTML converter
+func NewDocxToHTML(opts ConvertOptions) *DocxToHTML {
+	return &DocxToHTML{
+		Options: opts,
+	}
+}
+
+// Convert converts a DOCX document to an HTML file
+func (c *DocxToHTML) Convert(doc *docx.Document, outputPath string) error {
+	if err := os.WriteFile(outputPath, []byte(c.Render(doc)), 0o644); err != nil {
+		return fmt.Errorf("failed to write HTML: %w", err)
+	}
+	return nil
+}
+
+// ConvertTo converts a DOCX document to HTML and writes it to w
+func (c *DocxToHTML) ConvertTo(doc *docx.Document, w io.Writer) error {
+	if _, err := io.WriteString(w, c.Render(doc)); err != nil {
+		return fmt.Errorf("failed to write HTML: %w", err)
+	}
+	return nil
+}
+
+// Render returns the HTML page for a DOCX document
+func (c *DocxToHTML) Render(doc *docx.Document) string {
+	var sb strings.Builder
+
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"UTF-8\">\n")
+	fmt.Fprintf(&sb, "<style>body { font-family: %s, sans-serif; font-size: %gpt; } table { border-collapse: collapse; } td { border: 1px solid #999; padding: 4px; }</style>\n",
+		html.EscapeString(c.Options.FontFamily), c.Options.FontSize)
+	sb.WriteString("</head>\n<body>\n")
+
+	inList := false
+	for _, para := range doc.Body.Paragraphs {
+		isItem := para.Props != nil && para.Props.NumPr != nil
+		if isItem != inList {
+			if isItem {
+				sb.WriteString("<ul>\n")
+			} else {
+				sb.WriteString("</ul>\n")
+			}
+			inList = isItem
+		}
+
+		text := htmlRuns(para)
+		switch level := headingLevel(&para); {
+		case level > 0:
+			fmt.Fprintf(&sb, "<h%d>%s</h%d>\n", level, text, level)
+		case isItem:
+			fmt.Fprintf(&sb, "<li>%s</li>\n", text)
+		default:
+			fmt.Fprintf(&sb, "<p%s>%s</p>\n", htmlAlignment(para), text)
+		}
+	}
+	if inList {
+		sb.WriteString("</ul>\n")
+	}
+
+	for _, table := range doc.Body.Tables {
+		sb.WriteString("<table>\n")
+		for _, row := range tableText(&table) {
+			sb.WriteString("<tr>")
+			for _, cell := range row {
+				fmt.Fprintf(&sb, "<td>%s</td>", html.EscapeString(cell))
+			}
+			sb.WriteString("</tr>\n")
+		}
+		sb.WriteString("</table>\n")
+	}
+
+	sb.WriteString("</body>\n</html>\n")
+	return sb.String()
+}
+
+// htmlRuns returns the escaped text of a paragraph with run formatting applied
+func htmlRuns(para docx.Paragraph) string {
+	var sb strings.Builder
+	write := func(runs []docx.Run) {
+		for _, run := range runs {
+			text := ""
+			for _, t := range run.Text {
+				text += t.Content
+			}
+			if run.Break != nil {
+				text += "\n"
+			}
+			text = strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+
+			if run.Props != nil {
+				if va := run.Props.VertAlign; va != nil && va.Val == "superscript" {
+					text = "<sup>" + text + "</sup>"
+				} else if va != nil && va.Val == "subscript" {
+					text = "<sub>" + text + "</sub>"
+				}
+				if run.Props.Strike != nil {
+					text = "<s>" + text + "</s>"
+				}
+				if u := run.Props.Underline; u != nil && u.Val != "none" {
+					text = "<u>" + text + "</u>"
+				}
+				if run.Props.Italic != nil {
+					text = "<em>" + text + "</em>"
+				}
+				if run.Props.Bold != nil {
+					text = "<strong>" + text + "</strong>"
+				}
+				if h := run.Props.Highlight; h != nil && h.Val != "none" {
+					text = fmt.Sprintf(`<mark style="background-color: %s">%s</mark>`, highlightCSS(h.Val), text)
+				}
+				if run.Props.Color != nil && run.Props.Color.Val != "" && run.Props.Color.Val != "auto" {
+					text = fmt.Sprintf(`<span style="color: #%s">%s</span>`, html.EscapeString(run.Props.Color.Val), text)
+				}
+			}
+			sb.WriteString(text)
+		}
+	}
+
+	for _, child := range para.Content() {
+		switch c := child.(type) {
+		case *docx.Run:
+			write([]docx.Run{*c})
+		case *docx.Hyperlink:
+			if c.Anchor != "" {
+				fmt.Fprintf(&sb, `<a href="#%s">`, html.EscapeString(c.Anchor))
+				write(c.Runs)
+				sb.WriteString("</a>")
+			} else {
+				write(c.Runs)
+			}
+		case *docx.SimpleField:
+			write(c.Runs)
+		}
+	}
+	return sb.String()
+}
+
+// htmlAlignment returns attributes for the paragraph direction and
+// justification, if any. Word takes left and right in right-to-left
+// paragraphs as start and end.
+func htmlAlignment(para docx.Paragraph) string {
+	dir := ""
+	if para.IsRTL() {
+		dir = ` dir="rtl"`
+	}
+	if para.Props == nil || para.Props.Jc == nil {
+		return dir
+	}
+	switch jc := para.Props.Jc.Val; {
+	case jc == "center":
+		return dir + ` style="text-align: center"`
+	case jc == "both":
+		return dir + ` style="text-align: justify"`
+	case (jc == "right" || jc == "end") && dir != "":
+		return dir + ` style="text-align: left"`
+	case jc == "right" || jc == "end":
+		return ` style="text-align: right"`
+	}
+	return dir
+}
+
+// ConvertDocxToHTML converts a DOCX file to HTML
+func ConvertDocxToHTML(inputPath, outputPath string, opts ConvertOptions) error {
+	doc, err := docx.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to open DOCX: %w", err)
+	}
+	defer doc.Close()
+
+	return NewDocxToHTML(opts).Convert(doc, outputPath)
+}
+
+// highlightCSS returns the CSS color of a Word highlight color
+func highlightCSS(color string) string {
+	// Word's dark yellow has no CSS name; the other names match CSS ones
+	if strings.EqualFold(color, "darkYellow") {
+		return "olive"
+	}
+	return html.EscapeString(strings.ToLower(color))
+}
@@ -0,0 +1,188 @@
+package converter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// DocxToMarkdown converts a DOCX document to Markdown
+type DocxToMarkdown struct {
+	Options ConvertOptions
+}
+
+// NewDocxToMarkdown creates a new DOCX to Markdown converter
+func NewDocxToMarkdown(opts ConvertOptions) *DocxToMarkdown {
+	return &DocxToMarkdown{
+		Options: opts,
+	}
+}
+
+// Convert converts a DOCX document to a Markdown file
+func (c *DocxToMarkdown) Convert(doc *docx.Document, outputPath string) error {
+	if err := os.WriteFile(outputPath, []byte(c.Render(doc)), 0o644); err != nil {
+		return fmt.Errorf("failed to write Markdown: %w", err)
+	}
+	return nil
+}
+
+// ConvertTo converts a DOCX document to Markdown and writes it to w
+func (c *DocxToMarkdown) ConvertTo(doc *docx.Document, w io.Writer) error {
+	if _, err := io.WriteString(w, c.Render(doc)); err != nil {
+		return fmt.Errorf("failed to write Markdown: %w", err)
+	}
+	return nil
+}
+
+// Render returns the Markdown for a DOCX document. Headings, list items, bold
+// and italic text and tables are converted; other formatting is dropped.
+func (c *DocxToMarkdown) Render(doc *docx.Document) string {
+	var sb strings.Builder
+
+	for _, para := range doc.Body.Paragraphs {
+		text := markdownRuns(para)
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		if level := headingLevel(&para); level > 0 {
+			sb.WriteString(strings.Repeat("#", level) + " " + text + "\n\n")
+		} else if para.Props != nil && para.Props.NumPr != nil {
+			indent := 0
+			if para.Props.NumPr.ILvl != nil {
+				indent, _ = strconv.Atoi(para.Props.NumPr.ILvl.Val)
+			}
+			sb.WriteString(strings.Repeat("  ", indent) + "- " + text + "\n")
+		} else {
+			sb.WriteString(text + "\n\n")
+		}
+	}
+
+	for _, table := range doc.Body.Tables {
+		rows := tableText(&table)
+		if len(rows) == 0 {
+			continue
+		}
+		for i, row := range rows {
+			for j := range row {
+				row[j] = strings.ReplaceAll(row[j], "|", `\|`)
+			}
+			sb.WriteString("| " + strings.Join(row, " | ") + " |\n")
+			if i == 0 {
+				sb.WriteString("|" + strings.Repeat(" --- |", len(row)) + "\n")
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+// markdownRuns returns the text of a paragraph with bold and italic runs marked up
+func markdownRuns(para docx.Paragraph) string {
+	var sb strings.Builder
+	write := func(runs []docx.Run) {
+		for _, run := range runs {
+			text := ""
+			for _, t := range run.Text {
+				text += t.Content
+			}
+			if strings.TrimSpace(text) == "" {
+				sb.WriteString(text)
+				continue
+			}
+
+			text = markdownEscaper.Replace(text)
+			if run.Props != nil && run.Props.Strike != nil {
+				text = "~~" + text + "~~"
+			}
+			if run.Props != nil && run.Props.Italic != nil {
+				text = "*" + text + "*"
+			}
+			if run.Props != nil && run.Props.Bold != nil {
+				text = "**" + text + "**"
+			}
+			sb.WriteString(text)
+		}
+	}
+
+	for _, child := range para.Content() {
+		switch c := child.(type) {
+		case *docx.Run:
+			write([]docx.Run{*c})
+		case *docx.Hyperlink:
+			write(c.Runs)
+		case *docx.SimpleField:
+			write(c.Runs)
+		}
+	}
+	return sb.String()
+}
+
+// markdownEscaper escapes characters that would otherwise be read as Markdown syntax
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "#", `\#`)
+
+// headingLevel returns the heading level of a paragraph from its style
+// (Title, Heading1-Heading6), or 0 if it is not a heading
+func headingLevel(para *docx.Paragraph) int {
+	if para.Props == nil || para.Props.Style == nil {
+		return 0
+	}
+
+	style := strings.ToLower(strings.ReplaceAll(para.Props.Style.Val, " ", ""))
+	if style == "title" {
+		return 1
+	}
+	if !strings.HasPrefix(style, "heading") {
+		return 0
+	}
+
+	level, err := strconv.Atoi(strings.TrimPrefix(style, "heading"))
+	if err != nil || level < 1 {
+		return 0
+	}
+	if level > 6 {
+		level = 6
+	}
+	return level
+}
+
+// tableText returns the plain text of each cell of a table, row by row
+func tableText(table *docx.Table) [][]string {
+	rows := [][]string{}
+	for _, row := range table.Rows {
+		cells := []string{}
+		for _, cell := range row.Cells {
+			var texts []string
+			for _, p := range cell.Content {
+				text := ""
+				for _, r := range paragraphRuns(p) {
+					for _, t := range r.Text {
+						text += t.Content
+					}
+				}
+				if text != "" {
+					texts = append(texts, text)
+				}
+			}
+			cells = append(cells, strings.Join(texts, " "))
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+// ConvertDocxToMarkdown converts a DOCX file to Markdown
+func ConvertDocxToMarkdown(inputPath, outputPath string, opts ConvertOptions) error {
+	doc, err := docx.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to open DOCX: %w", err)
+	}
+	defer doc.Close()
+
+	return NewDocxToMarkdown(opts).Convert(doc, outputPath)
+}
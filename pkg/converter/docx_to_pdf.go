@@ -1,7 +1,19 @@
 package converter
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"image"
+	_ "image/gif" // Decoders for the picture formats a PDF can hold
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/docx"
 	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
@@ -10,6 +22,10 @@ import (
 // DocxToPDF converts a DOCX document to PDF
 type DocxToPDF struct {
 	Options ConvertOptions
+
+	// Warnings lists what the last conversion couldn't carry over, such as
+	// fonts that were neither embedded nor mapped to a PDF font
+	Warnings []string
 }
 
 // NewDocxToPDF creates a new DOCX to PDF converter
@@ -21,121 +37,699 @@ func NewDocxToPDF(opts ConvertOptions) *DocxToPDF {
 
 // Convert converts a DOCX document to PDF
 func (c *DocxToPDF) Convert(doc *docx.Document, outputPath string) error {
+	return c.ConvertContext(context.Background(), doc, outputPath)
+}
+
+// ConvertContext converts a DOCX document to PDF like Convert, stopping
+// with ctx's error when ctx is canceled or its deadline passes. Nothing is
+// written then.
+func (c *DocxToPDF) ConvertContext(ctx context.Context, doc *docx.Document, outputPath string) error {
+	b, err := backend(c.Options.Engine)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		pdfDoc, err := c.BuildContext(ctx, doc)
+		if err != nil {
+			return err
+		}
+		return pdfDoc.Save(outputPath)
+	}
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	err = convertWithBackend(ctx, b, doc, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(outputPath)
+	}
+	return err
+}
+
+// ConvertTo converts a DOCX document to PDF and writes it to w
+func (c *DocxToPDF) ConvertTo(doc *docx.Document, w io.Writer) error {
+	return c.ConvertToContext(context.Background(), doc, w)
+}
+
+// ConvertToContext converts a DOCX document to PDF and writes it to w like
+// ConvertTo, stopping with ctx's error when ctx is done
+func (c *DocxToPDF) ConvertToContext(ctx context.Context, doc *docx.Document, w io.Writer) error {
+	b, err := backend(c.Options.Engine)
+	if err != nil {
+		return err
+	}
+	if b != nil {
+		return convertWithBackend(ctx, b, doc, w)
+	}
+	pdfDoc, err := c.BuildContext(ctx, doc)
+	if err != nil {
+		return err
+	}
+	_, err = pdfDoc.WriteTo(w)
+	return err
+}
+
+// Build lays out a DOCX document as a PDF document with the native
+// converter, whatever the Engine option. Paragraphs are wrapped
+// to the page width and flow onto new pages as pages fill up, at page
+// breaks and at section breaks, with the page size and margins of each
+// section, or of the options for documents that don't set them. Paragraphs
+// keep their alignment, headings are set larger and in bold, and list items
+// are indented behind their bullets or numbers.
+func (c *DocxToPDF) Build(doc *docx.Document) *pdf.Document {
+	pdfDoc, _ := c.BuildContext(context.Background(), doc)
+	return pdfDoc
+}
+
+// BuildContext lays out a DOCX document as a PDF document like Build,
+// checking ctx between paragraphs and returning its error once it is done
+func (c *DocxToPDF) BuildContext(ctx context.Context, doc *docx.Document) (*pdf.Document, error) {
 	pdfDoc := pdf.New()
+	c.Warnings = nil
 
 	// Set metadata
 	pdfDoc.SetMetadata("Converted from DOCX", "", "")
 
-	// Add a page
-	page := pdfDoc.AddPage()
+	// Fonts embedded in the document go into the PDF too, so text set in
+	// them keeps its look and line lengths
+	embedded := embedFonts(doc, pdfDoc)
+	for _, path := range c.Options.FallbackFonts {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			c.warn(fmt.Sprintf("fallback font %s can't be read: %v", path, err))
+			continue
+		}
+		pdfDoc.AddFallbackFont(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), data)
+	}
 
-	// Current Y position for content
-	currentY := page.Margin.Top
+	layout := &pdfLayout{doc: pdfDoc, geometry: c.pageGeometry(doc.PageSetupAt(0))}
+	layout.newPage()
 
-	// Convert paragraphs
-	for _, para := range doc.Body.Paragraphs {
-		text := ""
-		isBold := false
-		isItalic := false
-		fontSize := c.Options.FontSize
-		color := "000000"
+	lists := &listCounter{levels: doc.ListLevels(), counts: make(map[string][]int)}
 
-		// Extract text and styling from runs
-		for _, run := range para.Runs {
-			for _, t := range run.Text {
-				text += t.Content
-			}
+	// Tables sit between paragraphs, after the number of paragraphs given by their position
+	tables := append([]docx.Table(nil), doc.Body.Tables...)
+	sort.SliceStable(tables, func(i, j int) bool { return tables[i].Position < tables[j].Position })
 
-			// Check for formatting
-			if run.Props != nil {
-				if run.Props.Bold != nil {
-					isBold = true
-				}
-				if run.Props.Italic != nil {
-					isItalic = true
+	for i, para := range doc.Body.Paragraphs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for len(tables) > 0 && tables[0].Position <= i {
+			c.layoutTable(layout, tables[0])
+			tables = tables[1:]
+		}
+
+		// A section starts on a new page unless it is continuous
+		if i > 0 {
+			if prev := doc.Body.Paragraphs[i-1].Props; prev != nil && prev.SectPr != nil {
+				setup := doc.PageSetupAt(i)
+				layout.geometry = c.pageGeometry(setup)
+				if setup.Start != docx.SectionBreakContinuous && setup.Start != docx.SectionBreakNextColumn {
+					layout.pageBreak()
 				}
-				if run.Props.Size != nil && run.Props.Size.Val != "" {
-					// Size in DOCX is in half-points, convert to points
-					var sz float64
-					fmt.Sscanf(run.Props.Size.Val, "%f", &sz)
-					fontSize = sz / 2
+			}
+		}
+		if para.Props != nil && para.Props.PageBreakBefore.Breaks() {
+			layout.pageBreak()
+		}
+
+		runs := paragraphRuns(para)
+		style := c.paragraphStyle(para, runs, embedded)
+		format := lists.format(para, style)
+
+		// Headings stand apart from the text before them
+		if headingLevel(&para) > 0 && layout.used {
+			layout.y += pdf.LineHeight(style.FontSize) * 0.5
+		}
+
+		// Page breaks and pictures split the text into blocks
+		var text strings.Builder
+		placed := false
+		flush := func() {
+			if text.Len() > 0 {
+				layout.addLines(text.String(), style, &format)
+				text.Reset()
+				placed = true
+			}
+		}
+		for _, run := range runs {
+			for _, t := range run.Text {
+				text.WriteString(t.Content)
+			}
+			if run.Tab != nil {
+				text.WriteString(" ")
+			}
+			if run.Break != nil {
+				if run.Break.Type == "page" {
+					flush()
+					layout.pageBreak()
+					placed = true
+				} else {
+					text.WriteString("\n")
 				}
-				if run.Props.Color != nil && run.Props.Color.Val != "" {
-					color = run.Props.Color.Val
+			}
+			if img, ok := doc.RunImage(&run); ok {
+				flush()
+				if layout.addImage(img) {
+					placed = true
 				}
 			}
 		}
+		flush()
+
+		// An empty paragraph still takes up a line
+		if !placed {
+			layout.addLines("", style, &format)
+		}
+		layout.y += style.FontSize * 0.3528 * 0.5
+		c.Options.Progress.report(i+1, len(doc.Body.Paragraphs), "layout")
+	}
+
+	for _, table := range tables {
+		c.layoutTable(layout, table)
+	}
 
-		if text != "" {
-			style := pdf.TextStyle{
-				FontSize:   fontSize,
-				FontFamily: c.Options.FontFamily,
-				Bold:       isBold,
-				Italic:     isItalic,
-				Color:      color,
+	return pdfDoc, nil
+}
+
+// paragraphStyle returns the text style of a paragraph: its alignment, the
+// size of its heading level, and bold, italic, sized, colored or set in a
+// font if any of its runs is
+func (c *DocxToPDF) paragraphStyle(para docx.Paragraph, runs []docx.Run, embedded map[string]bool) pdf.TextStyle {
+	style := pdf.TextStyle{
+		FontSize:   c.Options.FontSize,
+		FontFamily: c.Options.FontFamily,
+		Color:      "000000",
+	}
+	if style.FontSize <= 0 {
+		style.FontSize = 12
+	}
+
+	if para.Props != nil && para.Props.Jc != nil {
+		switch para.Props.Jc.Val {
+		case "center":
+			style.Align = "center"
+		case "right", "end":
+			style.Align = "right"
+		case "both", "distribute":
+			style.Align = "justify"
+		}
+	}
+
+	// Right-to-left paragraphs start at the right margin: Word takes left
+	// and right in them as start and end
+	if para.IsRTL() {
+		style.Direction = "rtl"
+		switch style.Align {
+		case "":
+			style.Align = "right"
+		case "right":
+			style.Align = ""
+		}
+	}
+
+	// Headings are bold and scaled from the body size, unless their runs set a size
+	if level := headingLevel(&para); level > 0 {
+		style.Bold = true
+		if strings.EqualFold(para.Props.Style.Val, "title") {
+			style.FontSize *= titleScale
+		} else {
+			style.FontSize *= headingScales[level-1]
+		}
+	}
+
+	for _, run := range runs {
+		if run.Props == nil {
+			continue
+		}
+
+		// Right-to-left runs are formatted by their complex script properties
+		bold, italic := run.Props.Bold != nil, run.Props.Italic != nil
+		size, font := "", ""
+		if run.Props.Size != nil {
+			size = run.Props.Size.Val
+		}
+		if run.Props.RFonts != nil {
+			font = run.Props.RFonts.ASCII
+		}
+		if run.Props.IsComplexScript() {
+			bold, italic = run.Props.BoldCS != nil, run.Props.ItalicCS != nil
+			size, font = "", ""
+			if run.Props.SizeCS != nil {
+				size = run.Props.SizeCS.Val
+			}
+			if run.Props.RFonts != nil {
+				font = run.Props.RFonts.CS
 			}
+		}
+
+		if bold {
+			style.Bold = true
+		}
+		if italic {
+			style.Italic = true
+		}
+		if size != "" {
+			// Size in DOCX is in half-points, convert to points
+			var sz float64
+			fmt.Sscanf(size, "%f", &sz)
+			style.FontSize = sz / 2
+		}
+		if run.Props.Color != nil && run.Props.Color.Val != "" {
+			style.Color = run.Props.Color.Val
+		}
+		if font != "" {
+			style.FontFamily = c.fontFamily(font, embedded)
+		}
+	}
+	return style
+}
 
-			page.AddTextStyled(text, page.Margin.Left, currentY, style)
-			currentY += fontSize * 1.5 // Line spacing (1.5x font size for readability)
+// standardFonts are the fonts documents use that have a PDF standard font
+// with the same metrics, by lower-cased name
+var standardFonts = map[string]string{
+	"arial":           "Arial",
+	"helvetica":       "Helvetica",
+	"times new roman": "Times",
+	"times":           "Times",
+	"courier new":     "Courier",
+	"courier":         "Courier",
+	"symbol":          "Symbol",
+}
 
-			// Check if we need a new page
-			if currentY > page.Height-page.Margin.Bottom {
-				page = pdfDoc.AddPage()
-				currentY = page.Margin.Top
+// fontFamily returns the PDF font text set in a document's font is drawn
+// in: the font itself if it is embedded, the font FontMap maps it to, or the
+// standard font it matches. Other fonts are replaced by the default family,
+// with a warning.
+func (c *DocxToPDF) fontFamily(name string, embedded map[string]bool) string {
+	if embedded[name] {
+		return name
+	}
+	for from, to := range c.Options.FontMap {
+		if strings.EqualFold(from, name) {
+			if !embedded[to] && standardFonts[strings.ToLower(to)] == "" {
+				c.warn(fmt.Sprintf("font %q is mapped to %q, which is neither a standard PDF font nor embedded", name, to))
 			}
+			return to
+		}
+	}
+	if family, ok := standardFonts[strings.ToLower(name)]; ok {
+		return family
+	}
+	c.warn(fmt.Sprintf("font %q is neither embedded nor mapped; using %s", name, c.Options.FontFamily))
+	return c.Options.FontFamily
+}
+
+// warn adds a warning unless it was already given
+func (c *DocxToPDF) warn(warning string) {
+	for _, w := range c.Warnings {
+		if w == warning {
+			return
+		}
+	}
+	c.Warnings = append(c.Warnings, warning)
+}
+
+// Sizes of titles and of headings by level, relative to the body text
+var (
+	titleScale    = 2.2
+	headingScales = []float64{1.6, 1.35, 1.15, 1, 1, 1}
+)
+
+// paragraphFormat is how the lines of a paragraph are set beside the margin
+type paragraphFormat struct {
+	indent      float64       // Indent of the lines from the left margin, in mm
+	hanging     float64       // How far the marker hangs left of the lines, in mm
+	marker      string        // List bullet or number, drawn before the first line
+	markerStyle pdf.TextStyle // Style the marker is drawn in
+}
+
+// listCounter numbers the items of the lists in a document
+type listCounter struct {
+	levels map[string][]docx.ListLevel // Levels of each list, by numbering ID
+	counts map[string][]int            // Number of the last item at each level, by numbering ID
+}
+
+// format returns the format of a paragraph, numbering it if it is a list
+// item. Lists without a definition are bulleted.
+func (lc *listCounter) format(para docx.Paragraph, style pdf.TextStyle) paragraphFormat {
+	if para.Props == nil || para.Props.NumPr == nil || para.Props.NumPr.NumID == nil || para.Props.NumPr.NumID.Val == "0" {
+		return paragraphFormat{}
+	}
+	numID := para.Props.NumPr.NumID.Val
+	ilvl := 0
+	if para.Props.NumPr.ILvl != nil {
+		ilvl, _ = strconv.Atoi(para.Props.NumPr.ILvl.Val)
+	}
+	ilvl = min(max(ilvl, 0), 8)
+
+	levels, ok := lc.levels[numID]
+	if !ok {
+		levels = make([]docx.ListLevel, 9)
+		for i := range levels {
+			levels[i] = docx.ListLevel{Format: "bullet", Text: "\u2022", Start: 1, Indent: 720 * (i + 1), Hanging: 360}
 		}
+		lc.levels[numID] = levels
 	}
 
-	// Convert tables
-	for _, table := range doc.Body.Tables {
-		// Check if we need a new page for the table
-		estimatedTableHeight := float64(len(table.Rows)) * 8.0
-		if currentY+estimatedTableHeight > page.Height-page.Margin.Bottom {
-			page = pdfDoc.AddPage()
-			currentY = page.Margin.Top
+	// An item restarts the numbering of the levels below it
+	counts, ok := lc.counts[numID]
+	if !ok {
+		counts = make([]int, len(levels))
+		for i := range counts {
+			counts[i] = levels[i].Start - 1
 		}
+		lc.counts[numID] = counts
+	}
+	counts[ilvl]++
+	for i := ilvl + 1; i < len(counts); i++ {
+		counts[i] = levels[i].Start - 1
+	}
+
+	level := levels[ilvl]
+	format := paragraphFormat{
+		indent:      max(twipsToMM(level.Indent), 0),
+		hanging:     twipsToMM(level.Hanging),
+		markerStyle: style,
+	}
+	format.markerStyle.Align = ""
 
-		// Extract table data
-		rows := [][]string{}
-		for _, row := range table.Rows {
-			cells := []string{}
-			for _, cell := range row.Cells {
-				cellText := ""
-				for _, p := range cell.Content {
-					for _, r := range p.Runs {
-						for _, t := range r.Text {
-							cellText += t.Content
-						}
+	if level.Format == "bullet" {
+		// Bullets from symbol fonts, and other characters the core fonts
+		// lack, are drawn as a dot from the ZapfDingbats font
+		format.marker = level.Text
+		if strings.IndexFunc(level.Text, func(r rune) bool { return r < ' ' || r > '~' }) >= 0 {
+			format.marker = "l"
+			format.markerStyle.FontFamily = "ZapfDingbats"
+			format.markerStyle.FontSize *= 0.6
+		}
+		return format
+	}
+
+	format.marker = level.Text
+	for i := 0; i <= ilvl; i++ {
+		n := max(counts[i], levels[i].Start)
+		format.marker = strings.ReplaceAll(format.marker, "%"+strconv.Itoa(i+1), docx.FormatListNumber(n, levels[i].Format))
+	}
+	return format
+}
+
+// tableSpacingAfter is the space after a converted table, in mm
+const tableSpacingAfter = 5.0
+
+// layoutTable places a table, splitting its rows across pages. Columns
+// keep the proportions of the table's grid, shrunk to fit the page.
+func (c *DocxToPDF) layoutTable(layout *pdfLayout, table docx.Table) {
+	// Extract table data; the paragraphs of a cell go on separate lines
+	rows := [][]string{}
+	for _, row := range table.Rows {
+		cells := []string{}
+		for _, cell := range row.Cells {
+			lines := make([]string, len(cell.Content))
+			for i, p := range cell.Content {
+				for _, r := range paragraphRuns(p) {
+					for _, t := range r.Text {
+						lines[i] += t.Content
 					}
 				}
-				cells = append(cells, cellText)
 			}
-			rows = append(rows, cells)
+			cells = append(cells, strings.Join(lines, "\n"))
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return
+	}
+
+	tableContent := pdf.TableContent{
+		X:           layout.page.Margin.Left,
+		Rows:        rows,
+		ColumnWidth: tableColumnWidths(table, len(rows[0]), layout.contentWidth()),
+		HeaderStyle: &pdf.TextStyle{
+			FontSize:   c.Options.FontSize,
+			FontFamily: c.Options.FontFamily,
+			Bold:       true,
+		},
+		CellStyle: &pdf.TextStyle{
+			FontSize:   c.Options.FontSize,
+			FontFamily: c.Options.FontFamily,
+			Bold:       false,
+		},
+	}
+	heights := layout.doc.TableRowHeights(tableContent)
+
+	for start := 0; start < len(rows); {
+		// Take the rows that fit on the page, and at least one on an empty page
+		end, height := start, 0.0
+		for end < len(rows) && layout.y+height+heights[end] <= layout.bottom() {
+			height += heights[end]
+			end++
+		}
+		if end == start {
+			if layout.used {
+				layout.pageBreak()
+				continue
+			}
+			height, end = heights[start], start+1
+		}
+
+		// Rows continued on a later page are all body rows
+		part := tableContent
+		part.X, part.Y = layout.page.Margin.Left, layout.y
+		part.Rows, part.RowHeights = rows[start:end], heights[start:end]
+		if start > 0 {
+			part.HeaderStyle = nil
+		}
+		layout.page.Content = append(layout.page.Content, part)
+		layout.y += height
+		layout.used = true
+
+		start = end
+		if start < len(rows) {
+			layout.pageBreak()
+		}
+	}
+	layout.y += tableSpacingAfter
+}
+
+// tableColumnWidths returns the widths of a table's columns in mm: those of
+// its grid, scaled down to the available width if wider, or equal columns
+// filling the available width if the grid doesn't give them
+func tableColumnWidths(table docx.Table, cols int, available float64) []float64 {
+	widths := make([]float64, cols)
+	total := 0.0
+	if table.Grid != nil && len(table.Grid.Cols) == cols {
+		for i, col := range table.Grid.Cols {
+			w, _ := strconv.Atoi(col.W)
+			widths[i] = twipsToMM(w)
+			total += widths[i]
 		}
+	}
+	for _, w := range widths {
+		if w <= 0 {
+			total = 0
+		}
+	}
+	if total == 0 {
+		for i := range widths {
+			widths[i] = available / float64(cols)
+		}
+		return widths
+	}
+	if total > available {
+		for i := range widths {
+			widths[i] *= available / total
+		}
+	}
+	return widths
+}
+
+// pageGeometry is the size and margins of a page, in mm
+type pageGeometry struct {
+	width, height            float64
+	left, top, right, bottom float64
+}
+
+// pageGeometry converts the page setup of a section, filling in what it
+// doesn't set from the options
+func (c *DocxToPDF) pageGeometry(setup docx.PageSetup) pageGeometry {
+	g := pageGeometry{width: 210, height: 297}
+	switch strings.ToLower(c.Options.PageSize) {
+	case "letter":
+		g.width, g.height = 215.9, 279.4
+	case "legal":
+		g.width, g.height = 215.9, 355.6
+	}
+	if strings.EqualFold(c.Options.Orientation, "landscape") {
+		g.width, g.height = g.height, g.width
+	}
+	g.left, g.top, g.right, g.bottom = c.Options.Margins[0], c.Options.Margins[1], c.Options.Margins[2], c.Options.Margins[3]
+
+	if setup.Width > 0 && setup.Height > 0 {
+		g.width, g.height = twipsToMM(setup.Width), twipsToMM(setup.Height)
+	}
+	// Margins are only taken as a whole, as Word always writes all four
+	if setup.Left > 0 || setup.Top > 0 || setup.Right > 0 || setup.Bottom > 0 {
+		g.left, g.top, g.right, g.bottom = twipsToMM(setup.Left), twipsToMM(abs(setup.Top)), twipsToMM(setup.Right), twipsToMM(abs(setup.Bottom))
+	}
+	return g
+}
+
+// twipsToMM converts twentieths of a point to millimetres
+func twipsToMM(twips int) float64 {
+	return float64(twips) * 25.4 / 1440
+}
+
+// abs returns the absolute value of n; negative top and bottom margins
+// let text run over headers and footers but still set the margin's size
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// pdfLayout places content on the pages of a PDF document from top to
+// bottom, starting a new page when the current one is full
+type pdfLayout struct {
+	doc      *pdf.Document
+	page     *pdf.Page
+	geometry pageGeometry // Geometry of the pages started next
+	y        float64      // Top of the next content on the page, in mm
+	used     bool         // Whether anything was placed on the page
+}
+
+// newPage starts a new page
+func (l *pdfLayout) newPage() {
+	g := l.geometry
+	l.page = l.doc.AddPage()
+	l.page.Width, l.page.Height = g.width, g.height
+	l.page.Margin = pdf.Margin{Left: g.left, Top: g.top, Right: g.right, Bottom: g.bottom}
+	l.y = g.top
+	l.used = false
+}
+
+// pageBreak starts a new page unless nothing was placed on the current one
+func (l *pdfLayout) pageBreak() {
+	if l.used {
+		l.newPage()
+	}
+}
+
+// bottom returns the lowest position content may reach on the page
+func (l *pdfLayout) bottom() float64 {
+	return l.page.Height - l.page.Margin.Bottom
+}
+
+// contentWidth returns the width between the margins of the page
+func (l *pdfLayout) contentWidth() float64 {
+	return l.page.Width - l.page.Margin.Left - l.page.Margin.Right
+}
+
+// addLines places text wrapped to the page width, one line after another,
+// indented by the paragraph's format and preceded by its list marker. The
+// last line of a justified paragraph, and lines ending in a line break, are
+// left-aligned.
+func (l *pdfLayout) addLines(text string, style pdf.TextStyle, format *paragraphFormat) {
+	lineHeight := pdf.LineHeight(style.FontSize)
+	indent := min(format.indent, l.contentWidth()/2)
+	x := l.page.Margin.Left + indent
 
-		// Add table to PDF
-		tableContent := pdf.TableContent{
-			X:    page.Margin.Left,
-			Y:    currentY,
-			Rows: rows,
-			HeaderStyle: &pdf.TextStyle{
-				FontSize:   c.Options.FontSize,
-				FontFamily: c.Options.FontFamily,
-				Bold:       true,
-			},
-			CellStyle: &pdf.TextStyle{
-				FontSize:   c.Options.FontSize,
-				FontFamily: c.Options.FontFamily,
-				Bold:       false,
-			},
+	for _, block := range strings.Split(text, "\n") {
+		lines := l.doc.SplitText(block, style, l.contentWidth()-indent)
+		for i, line := range lines {
+			if l.y+lineHeight > l.bottom() && l.used {
+				l.newPage()
+			}
+			if format.marker != "" {
+				l.page.AddTextStyled(format.marker, max(x-format.hanging, 0), l.y, format.markerStyle)
+				format.marker = ""
+			}
+			if line != "" {
+				lineStyle := style
+				if style.Align == "justify" && i == len(lines)-1 {
+					lineStyle.Align = ""
+					if style.Direction == "rtl" {
+						lineStyle.Align = "right"
+					}
+				}
+				l.page.AddTextStyled(line, x, l.y, lineStyle)
+			}
+			l.y += lineHeight
+			l.used = true
 		}
-		page.Content = append(page.Content, tableContent)
+	}
+}
+
+// addImage places a picture at its size in the document, shrunk to fit the
+// page, reporting false for pictures in formats a PDF can't hold
+func (l *pdfLayout) addImage(img *docx.Image) bool {
+	config, _, err := image.DecodeConfig(bytes.NewReader(img.Data))
+	if err != nil {
+		return false
+	}
 
-		currentY += estimatedTableHeight + 5 // Add some spacing after table
+	// Sizes are in EMUs, or in pixels at 96 dpi for pictures without one
+	width, height := float64(img.Width)/36000, float64(img.Height)/36000
+	if width <= 0 || height <= 0 {
+		width, height = float64(config.Width)*25.4/96, float64(config.Height)*25.4/96
+	}
+	if maxWidth := l.contentWidth(); width > maxWidth {
+		width, height = maxWidth, height*maxWidth/width
+	}
+	if maxHeight := l.bottom() - l.page.Margin.Top; height > maxHeight {
+		width, height = width*maxHeight/height, maxHeight
 	}
 
-	// Save PDF
-	return pdfDoc.Save(outputPath)
+	if l.y+height > l.bottom() && l.used {
+		l.newPage()
+	}
+	l.page.AddImageFromBytes(img.Data, l.page.Margin.Left, l.y, width, height)
+	l.y += height
+	l.used = true
+	return true
+}
+
+// embedFonts adds the fonts embedded in a DOCX document to a PDF document
+// and returns the names of the fonts added
+func embedFonts(doc *docx.Document, pdfDoc *pdf.Document) map[string]bool {
+	styles := map[docx.FontStyle]string{docx.FontRegular: "", docx.FontBold: "B", docx.FontItalic: "I", docx.FontBoldItalic: "BI"}
+
+	embedded := make(map[string]bool)
+	fonts, _ := doc.Fonts()
+	for _, font := range fonts {
+		for _, style := range font.Embedded {
+			data, err := doc.ExtractFont(font.Name, style)
+			if err != nil {
+				continue
+			}
+			pdfDoc.AddFont(font.Name, styles[style], data)
+			embedded[font.Name] = true
+		}
+	}
+	return embedded
+}
+
+// paragraphRuns returns the runs of a paragraph in document order, those of
+// its hyperlinks and field results included
+func paragraphRuns(para docx.Paragraph) []docx.Run {
+	var runs []docx.Run
+	for _, child := range para.Content() {
+		switch c := child.(type) {
+		case *docx.Run:
+			runs = append(runs, *c)
+		case *docx.Hyperlink:
+			runs = append(runs, c.Runs...)
+		case *docx.SimpleField:
+			runs = append(runs, c.Runs...)
+		}
+	}
+	return runs
 }
 
 // ConvertFile converts a DOCX file to PDF
@@ -145,6 +739,7 @@ func ConvertDocxToPDF(inputPath, outputPath string, opts ConvertOptions) error {
 	if err != nil {
 		return fmt.Errorf("failed to open DOCX: %w", err)
 	}
+	defer doc.Close()
 
 	// Convert
 	converter := NewDocxToPDF(opts)
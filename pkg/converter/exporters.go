@@ -0,0 +1,16 @@
+package converter
+
+import "github.com/Palaciodiego008/docxsmith/pkg/docx"
+
+// Register the converters as exporters so docx.Document.SaveAs can write these formats
+func init() {
+	docx.RegisterExporter(".pdf", func(d *docx.Document, path string) error {
+		return NewDocxToPDF(DefaultOptions()).Convert(d, path)
+	})
+	docx.RegisterExporter(".md", func(d *docx.Document, path string) error {
+		return NewDocxToMarkdown(DefaultOptions()).Convert(d, path)
+	})
+	docx.RegisterExporter(".html", func(d *docx.Document, path string) error {
+		return NewDocxToHTML(DefaultOptions()).Convert(d, path)
+	})
+}
@@ -0,0 +1,515 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
+)
+
+func newSampleDocument() *docx.Document {
+	doc := docx.New()
+	doc.AddParagraph("Quarterly <Report>", docx.WithStyle("Heading1"))
+	doc.AddParagraph("Revenue grew", docx.WithBold())
+	table := doc.AddTable(2, 2)
+	table.SetCellText(0, 0, "Region")
+	table.SetCellText(0, 1, "Total")
+	table.SetCellText(1, 0, "North")
+	table.SetCellText(1, 1, "42")
+	return doc
+}
+
+func TestSaveAsFormats(t *testing.T) {
+	tmpDir := t.TempDir()
+	doc := newSampleDocument()
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"out.md", []string{"# Quarterly <Report>", "**Revenue grew**", "| Region | Total |", "| North | 42 |"}},
+		{"out.html", []string{"<h1>Quarterly &lt;Report&gt;</h1>", "<strong>Revenue grew</strong>", "<td>North</td>"}},
+		{"out.pdf", []string{"%PDF"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(tmpDir, tt.file)
+			if err := doc.SaveAs(path); err != nil {
+				t.Fatalf("SaveAs failed: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read output: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("Expected %s to contain %q", tt.file, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertInMemory(t *testing.T) {
+	// Any temp file would fail to be created
+	t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "missing"))
+
+	var pdfBuf bytes.Buffer
+	if err := NewDocxToPDF(DefaultOptions()).ConvertTo(newSampleDocument(), &pdfBuf); err != nil {
+		t.Fatalf("DOCX to PDF failed: %v", err)
+	}
+	pdfDoc, err := pdf.ReadBytes(pdfBuf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to read converted PDF: %v", err)
+	}
+	if !strings.Contains(pdfDoc.GetAllText(), "Revenue grew") {
+		t.Errorf("Expected converted PDF to contain the paragraph text")
+	}
+
+	var docxBuf bytes.Buffer
+	if err := NewPDFToDocx(DefaultOptions()).ConvertTo(pdfDoc, &docxBuf); err != nil {
+		t.Fatalf("PDF to DOCX failed: %v", err)
+	}
+	roundTrip, err := docx.ReadBytes(docxBuf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to read converted DOCX: %v", err)
+	}
+	if !strings.Contains(roundTrip.GetText(), "Revenue grew") {
+		t.Errorf("Expected converted DOCX to contain the paragraph text")
+	}
+
+	var mdBuf bytes.Buffer
+	if err := NewDocxToMarkdown(DefaultOptions()).ConvertTo(newSampleDocument(), &mdBuf); err != nil {
+		t.Fatalf("DOCX to Markdown failed: %v", err)
+	}
+	if !strings.HasPrefix(mdBuf.String(), "# Quarterly") {
+		t.Errorf("Expected Markdown heading, got %q", mdBuf.String())
+	}
+}
+
+func TestConvertProgress(t *testing.T) {
+	var last [2]int
+	stages := map[string]int{}
+	opts := DefaultOptions()
+	opts.Progress = func(current, total int, stage string) {
+		stages[stage]++
+		last = [2]int{current, total}
+	}
+
+	pdfDoc := NewDocxToPDF(opts).Build(newSampleDocument())
+	if stages["layout"] != 2 || last != [2]int{2, 2} {
+		t.Errorf("Expected progress for both paragraphs, got %v ending at %v", stages, last)
+	}
+
+	NewPDFToDocx(opts).Build(pdfDoc)
+	if stages["page"] != pdfDoc.GetPageCount() || last[0] != last[1] {
+		t.Errorf("Expected progress for each page, got %v ending at %v", stages, last)
+	}
+}
+
+func TestConvertContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	output := filepath.Join(t.TempDir(), "out.pdf")
+	if err := NewDocxToPDF(DefaultOptions()).ConvertContext(ctx, newSampleDocument(), output); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("Expected no output after cancellation, got %v", err)
+	}
+
+	pdfDoc := NewDocxToPDF(DefaultOptions()).Build(newSampleDocument())
+	var buf bytes.Buffer
+	if err := NewPDFToDocx(DefaultOptions()).ConvertToContext(ctx, pdfDoc, &buf); !errors.Is(err, context.Canceled) || buf.Len() > 0 {
+		t.Errorf("Expected context.Canceled and no output, got %v and %d bytes", err, buf.Len())
+	}
+
+	// Backends get the context too
+	RegisterBackend("test-context", contextBackend{})
+	opts := DefaultOptions()
+	opts.Engine = "test-context"
+	if err := NewDocxToPDF(opts).ConvertToContext(context.Background(), newSampleDocument(), &buf); err != nil || buf.String() != "context" {
+		t.Errorf("Expected the context-aware backend to be used, got %v, %q", err, buf.String())
+	}
+}
+
+// contextBackend is a backend that writes "context" when converting with a context
+type contextBackend struct{}
+
+func (contextBackend) ConvertDocxToPDF(doc *docx.Document, w io.Writer) error {
+	_, err := io.WriteString(w, "plain")
+	return err
+}
+
+func (contextBackend) ConvertDocxToPDFContext(ctx context.Context, doc *docx.Document, w io.Writer) error {
+	_, err := io.WriteString(w, "context")
+	return err
+}
+
+func TestDocxToPDFEmbeddedFonts(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("Corporate text", docx.WithFont("Corporate Sans"))
+	doc.AddParagraph("Plain text")
+	// Not a font gofpdf can read, so the text falls back to the default font
+	font := append([]byte("\x00\x01\x00\x00"), bytes.Repeat([]byte{'x'}, 60)...)
+	if err := doc.EmbedFont("Corporate Sans", docx.FontRegular, font); err != nil {
+		t.Fatalf("EmbedFont failed: %v", err)
+	}
+
+	pdfDoc := NewDocxToPDF(DefaultOptions()).Build(doc)
+	if len(pdfDoc.Fonts) != 1 || pdfDoc.Fonts[0].Family != "Corporate Sans" || !bytes.Equal(pdfDoc.Fonts[0].Data, font) {
+		t.Fatalf("Expected the embedded font in the PDF, got %+v", pdfDoc.Fonts)
+	}
+	families := []string{}
+	for _, content := range pdfDoc.Pages[0].Content {
+		if text, ok := content.(pdf.TextContent); ok {
+			families = append(families, text.FontFamily)
+		}
+	}
+	if len(families) != 2 || families[0] != "Corporate Sans" || families[1] != "Arial" {
+		t.Errorf("Expected the embedded font for its text only, got %v", families)
+	}
+
+	data, err := pdfDoc.Bytes()
+	if err != nil {
+		t.Fatalf("Expected an unreadable font not to fail the PDF: %v", err)
+	}
+	if converted, err := pdf.ReadBytes(data); err != nil || !strings.Contains(converted.GetAllText(), "Corporate text") {
+		t.Errorf("Expected the text in the PDF, got %v", err)
+	}
+}
+
+func TestDocxToPDFPagination(t *testing.T) {
+	doc := docx.New()
+	doc.Body.SectPr = &docx.RawElement{Inner: []byte(`<w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440"/>`)}
+	doc.AddParagraph("One")
+	doc.AddPageBreak()
+	doc.AddParagraph("Two")
+	doc.AddParagraph("Three", docx.WithPageBreakBefore())
+	table := doc.AddTable(2, 2)
+	table.SetCellText(0, 0, "Cell")
+	if err := doc.AddSectionBreak(docx.SectionBreakNextPage); err != nil {
+		t.Fatalf("AddSectionBreak failed: %v", err)
+	}
+	// The last section is Letter landscape
+	doc.Body.SectPr.Inner = bytes.Replace(doc.Body.SectPr.Inner, []byte(`<w:pgSz w:w="11906" w:h="16838"/>`), []byte(`<w:pgSz w:w="15840" w:h="12240" w:orient="landscape"/>`), 1)
+	doc.AddParagraph(strings.Repeat("wrapped words ", 100))
+	for i := 0; i < 60; i++ {
+		doc.AddParagraph("Filler")
+	}
+
+	pdfDoc := NewDocxToPDF(DefaultOptions()).Build(doc)
+
+	pageText := func(i int) string { return pdfDoc.Pages[i].GetText() }
+	if pdfDoc.GetPageCount() < 5 {
+		t.Fatalf("Expected at least 5 pages, got %d", pdfDoc.GetPageCount())
+	}
+	if !strings.Contains(pageText(0), "One") || strings.Contains(pageText(0), "Two") {
+		t.Errorf("Expected the page break after One, got %q", pageText(0))
+	}
+	if !strings.Contains(pageText(1), "Two") || !strings.HasPrefix(pageText(2), "Three") {
+		t.Errorf("Expected Three to start a page, got %q and %q", pageText(1), pageText(2))
+	}
+	if _, ok := pdfDoc.Pages[2].Content[1].(pdf.TableContent); !ok {
+		t.Errorf("Expected the table after Three, got %+v", pdfDoc.Pages[2].Content)
+	}
+
+	// The first section is A4 with one inch margins
+	if p := pdfDoc.Pages[0]; p.Width < 209.9 || p.Width > 210.1 || p.Margin.Left < 25.3 || p.Margin.Left > 25.5 {
+		t.Errorf("Expected an A4 page with 25.4mm margins, got %vx%v, %+v", p.Width, p.Height, p.Margin)
+	}
+
+	// The landscape section starts on a new page and wraps its long paragraph
+	landscape := pdfDoc.Pages[3]
+	if landscape.Width < 279.3 || landscape.Width > 279.5 || landscape.Height < 215.8 || landscape.Height > 216 {
+		t.Errorf("Expected a Letter landscape page, got %vx%v", landscape.Width, landscape.Height)
+	}
+	lines := 0
+	for _, content := range landscape.Content {
+		if text, ok := content.(pdf.TextContent); ok && strings.HasPrefix(text.Text, "wrapped") {
+			lines++
+			if text.Y > landscape.Height-landscape.Margin.Bottom {
+				t.Errorf("Expected text within the margins, got a line at %v", text.Y)
+			}
+		}
+	}
+	if lines < 5 {
+		t.Errorf("Expected the long paragraph to wrap onto several lines, got %d", lines)
+	}
+	if !strings.Contains(pageText(pdfDoc.GetPageCount()-1), "Filler") {
+		t.Errorf("Expected the filler paragraphs to flow onto later pages")
+	}
+
+	if _, err := pdfDoc.Bytes(); err != nil {
+		t.Fatalf("Failed to render PDF: %v", err)
+	}
+}
+
+func TestDocxToPDFTablesAndImages(t *testing.T) {
+	var picture bytes.Buffer
+	if err := png.Encode(&picture, image.NewRGBA(image.Rect(0, 0, 40, 20))); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+
+	doc := docx.New()
+	doc.AddParagraph("Before the picture")
+	if err := doc.AddImageFromBytes("chart.png", picture.Bytes(), docx.WithImageWidth(200), docx.WithImageHeight(100)); err != nil {
+		t.Fatalf("AddImageFromBytes failed: %v", err)
+	}
+	// Not a format a PDF can hold, so it is left out
+	if err := doc.AddImageFromBytes("icon.bmp", append([]byte("BM"), make([]byte, 60)...)); err != nil {
+		t.Fatalf("AddImageFromBytes failed: %v", err)
+	}
+	table := doc.AddTable(2, 2)
+	table.Grid.Cols[0].W, table.Grid.Cols[1].W = "1440", "4320"
+	table.SetCellText(0, 0, "Item")
+	table.SetCellText(0, 1, "Notes")
+	table.SetCellText(1, 0, "Widget")
+	table.SetCellText(1, 1, strings.Repeat("a long note ", 20))
+
+	pdfDoc := NewDocxToPDF(DefaultOptions()).Build(doc)
+
+	var images []pdf.ImageContent
+	var tables []pdf.TableContent
+	for _, content := range pdfDoc.Pages[0].Content {
+		switch c := content.(type) {
+		case pdf.ImageContent:
+			images = append(images, c)
+		case pdf.TableContent:
+			tables = append(tables, c)
+		}
+	}
+	if len(images) != 1 || !bytes.Equal(images[0].Data, picture.Bytes()) {
+		t.Fatalf("Expected the PNG picture only, got %d images", len(images))
+	}
+	// 200x100 pixels at 96 dpi
+	if w, h := images[0].Width, images[0].Height; w < 52.8 || w > 53.0 || h < 26.4 || h > 26.5 {
+		t.Errorf("Expected the picture at 52.9x26.5mm, got %vx%v", w, h)
+	}
+
+	if len(tables) != 1 || tables[0].Y < images[0].Y+images[0].Height {
+		t.Fatalf("Expected the table below the picture, got %+v", tables)
+	}
+	if widths := tables[0].ColumnWidth; len(widths) != 2 || widths[1] < 2.9*widths[0] || widths[1] > 3.1*widths[0] {
+		t.Errorf("Expected the columns in the grid's 1:3 proportion, got %v", widths)
+	}
+	if heights := tables[0].RowHeights; len(heights) != 2 || heights[1] <= heights[0] {
+		t.Errorf("Expected the wrapped note to make its row taller, got %v", heights)
+	}
+
+	data, err := pdfDoc.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to render PDF: %v", err)
+	}
+	if !bytes.Contains(data, []byte("/Subtype /Image")) {
+		t.Errorf("Expected the picture as an image XObject")
+	}
+	converted, err := pdf.ReadBytes(data)
+	if err != nil {
+		t.Fatalf("Failed to read converted PDF: %v", err)
+	}
+	if text := converted.GetAllText(); !strings.Contains(text, "Widget") || !strings.Contains(text, "Before the picture") {
+		t.Errorf("Expected the paragraph and table text, got %q", text)
+	}
+}
+
+func TestDocxToPDFAlignmentHeadingsAndLists(t *testing.T) {
+	doc := docx.New()
+	doc.SetPart("word/numbering.xml", []byte(`<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/><w:lvlText w:val="%1."/><w:pPr><w:ind w:left="720" w:hanging="360"/></w:pPr></w:lvl><w:lvl w:ilvl="1"><w:numFmt w:val="lowerRoman"/><w:lvlText w:val="%1.%2"/><w:pPr><w:ind w:left="1440" w:hanging="360"/></w:pPr></w:lvl></w:abstractNum>
+<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>
+</w:numbering>`))
+	doc.AddParagraph("Annual Review", docx.WithStyle("Title"))
+	doc.AddParagraph("Overview", docx.WithStyle("Heading1"))
+	doc.AddParagraph("Centered", docx.WithAlignment("center"))
+	doc.AddParagraph("Right", docx.WithAlignment("right"))
+	doc.AddParagraph(strings.Repeat("justified text ", 30), docx.WithAlignment("both"))
+	item := func(text, numID, level string) {
+		doc.AddParagraph(text)
+		doc.Body.Paragraphs[len(doc.Body.Paragraphs)-1].Props = &docx.PProps{NumPr: &docx.NumPr{ILvl: &docx.NumLevel{Val: level}, NumID: &docx.NumID{Val: numID}}}
+	}
+	item("First", "1", "0")
+	item("Nested", "1", "1")
+	item("Nested again", "1", "1")
+	item("Second", "1", "0")
+	item("Bulleted", "7", "0")
+
+	pdfDoc := NewDocxToPDF(DefaultOptions()).Build(doc)
+
+	texts := map[string]pdf.TextContent{}
+	var justified []pdf.TextContent
+	for _, content := range pdfDoc.Pages[0].Content {
+		if text, ok := content.(pdf.TextContent); ok {
+			texts[text.Text] = text
+			if strings.HasPrefix(text.Text, "justified") {
+				justified = append(justified, text)
+			}
+		}
+	}
+
+	title, heading, body := texts["Annual Review"], texts["Overview"], texts["Centered"]
+	if !title.Bold || !heading.Bold || title.FontSize <= heading.FontSize || heading.FontSize <= body.FontSize {
+		t.Errorf("Expected bold headings larger than the body, got %v, %v and %v", title.FontSize, heading.FontSize, body.FontSize)
+	}
+	if body.Align != "center" || texts["Right"].Align != "right" {
+		t.Errorf("Expected centered and right-aligned text, got %q and %q", body.Align, texts["Right"].Align)
+	}
+	if len(justified) < 2 || justified[0].Align != "justify" || justified[len(justified)-1].Align != "" {
+		t.Errorf("Expected justified lines with a left-aligned last line, got %+v", justified)
+	}
+
+	// Items are numbered by level, with nested levels restarting and indented further
+	for _, marker := range []string{"1.", "1.i", "1.ii", "2."} {
+		if _, ok := texts[marker]; !ok {
+			t.Errorf("Expected the list marker %q, got %v", marker, texts)
+		}
+	}
+	if first, nested := texts["First"], texts["Nested"]; nested.X <= first.X || texts["1."].X >= first.X {
+		t.Errorf("Expected the marker before the item and nested items indented, got %v, %v and %v", texts["1."].X, first.X, nested.X)
+	}
+	if bullet, ok := texts["l"]; !ok || bullet.FontFamily != "ZapfDingbats" {
+		t.Errorf("Expected a bullet for the list without a definition, got %+v", bullet)
+	}
+
+	data, err := pdfDoc.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to render PDF: %v", err)
+	}
+	converted, err := pdf.ReadBytes(data)
+	if err != nil {
+		t.Fatalf("Failed to read converted PDF: %v", err)
+	}
+	if text := converted.GetAllText(); !strings.Contains(text, "justified") || !strings.Contains(text, "Nested again") {
+		t.Errorf("Expected the aligned and listed text, got %q", text)
+	}
+}
+
+func TestDocxToPDFFontMap(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("Body", docx.WithFont("Calibri"))
+	doc.AddParagraph("Heading", docx.WithFont("Cambria"))
+	doc.AddParagraph("Code", docx.WithFont("Courier New"))
+	doc.AddParagraph("Brand", docx.WithFont("Corporate Sans"))
+	doc.AddParagraph("More brand", docx.WithFont("Corporate Sans"))
+
+	opts := DefaultOptions()
+	opts.FontMap = map[string]string{"calibri": "Helvetica", "Cambria": "Garamond"}
+	c := NewDocxToPDF(opts)
+	pdfDoc := c.Build(doc)
+
+	families := map[string]string{}
+	for _, content := range pdfDoc.Pages[0].Content {
+		if text, ok := content.(pdf.TextContent); ok {
+			families[text.Text] = text.FontFamily
+		}
+	}
+	if families["Body"] != "Helvetica" || families["Code"] != "Courier" || families["Brand"] != "Arial" {
+		t.Errorf("Expected mapped, standard and default fonts, got %v", families)
+	}
+
+	// Each font the PDF can't use is reported once
+	if len(c.Warnings) != 2 || !strings.Contains(c.Warnings[0], `"Garamond"`) || !strings.Contains(c.Warnings[1], `"Corporate Sans"`) {
+		t.Errorf("Expected warnings for Garamond and Corporate Sans, got %q", c.Warnings)
+	}
+	if c.Build(docx.New()); len(c.Warnings) != 0 {
+		t.Errorf("Expected the warnings to be reset, got %q", c.Warnings)
+	}
+}
+
+func TestDocxToPDFFallbackFonts(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("Résumé and שלום")
+
+	opts := DefaultOptions()
+	opts.FallbackFonts = []string{"missing.ttf"}
+	c := NewDocxToPDF(opts)
+	pdfDoc := c.Build(doc)
+	if len(c.Warnings) != 1 || !strings.Contains(c.Warnings[0], "missing.ttf") {
+		t.Errorf("Expected a warning for the missing font, got %q", c.Warnings)
+	}
+
+	font := "/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf"
+	if _, err := os.Stat(font); err != nil {
+		t.Skip("DejaVu Sans is not installed")
+	}
+	opts.FallbackFonts = []string{font}
+	pdfDoc = NewDocxToPDF(opts).Build(doc)
+	if len(pdfDoc.FallbackFonts) != 1 || pdfDoc.FallbackFonts[0] != "DejaVuSans" {
+		t.Errorf("FallbackFonts = %v", pdfDoc.FallbackFonts)
+	}
+	data, err := pdfDoc.Bytes()
+	if err != nil {
+		t.Fatalf("Error encoding PDF: %v", err)
+	}
+	if !bytes.Contains(data, []byte("/BaseFont /utf8dejavusans")) {
+		t.Error("Expected the fallback font to be embedded")
+	}
+}
+
+func TestDocxToRightToLeft(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("שלום עולם", docx.WithRTL(), docx.WithSize("32"))
+	doc.AddParagraph("סוף", docx.WithRTL(), docx.WithAlignment("right"))
+	doc.AddParagraph("Hello")
+	doc.Body.Paragraphs[0].Runs[0].Props.Size.Val = "20" // Not used by right-to-left runs
+
+	texts := map[string]pdf.TextContent{}
+	for _, content := range NewDocxToPDF(DefaultOptions()).Build(doc).Pages[0].Content {
+		if text, ok := content.(pdf.TextContent); ok {
+			texts[text.Text] = text
+		}
+	}
+	if hello := texts["שלום עולם"]; hello.Direction != "rtl" || hello.Align != "right" || hello.FontSize != 16 {
+		t.Errorf("Expected right-aligned right-to-left text in 16pt, got %+v", hello)
+	}
+	if end := texts["סוף"]; end.Align != "" {
+		t.Errorf("Expected a right-to-left paragraph aligned right to end at the left, got %q", end.Align)
+	}
+	if texts["Hello"].Direction != "" {
+		t.Errorf("Expected left-to-right text, got %q", texts["Hello"].Direction)
+	}
+
+	html := NewDocxToHTML(DefaultOptions()).Render(doc)
+	if !strings.Contains(html, `<p dir="rtl">שלום עולם</p>`) || !strings.Contains(html, `<p dir="rtl" style="text-align: left">סוף</p>`) {
+		t.Errorf("Expected right-to-left paragraphs, got %s", html)
+	}
+}
+
+func TestMarkdownToDocx(t *testing.T) {
+	md := "# Title\n\nSome **bold** and *italic*\ntext with `code`.\n\n- First\n  - Nested\n1. One\n\n> Quoted\n\n| Name | Qty |\n| --- | ---: |\n| Pens | 4 |\n\n```\nx := 1\n```\n"
+	doc := NewMarkdownToDocx(DefaultOptions()).Build(md)
+
+	blocks := doc.GetStructuredText()
+	want := []string{"Title", "Some bold and italic text with code.", "• First", "    • Nested", "1. One", "Quoted", "Name", "Qty", "Pens", "4", "x := 1"}
+	if len(blocks) != len(want) {
+		t.Fatalf("Expected %d blocks, got %+v", len(want), blocks)
+	}
+	for i, b := range blocks {
+		if b.Text != want[i] {
+			t.Errorf("Block %d: expected %q, got %q", i, want[i], b.Text)
+		}
+	}
+	if blocks[0].Type != docx.BlockHeading || blocks[0].Level != 1 {
+		t.Errorf("Expected a level 1 heading, got %+v", blocks[0])
+	}
+
+	runs := doc.Body.Paragraphs[1].Runs
+	if len(runs) != 7 || runs[1].Props == nil || runs[1].Props.Bold == nil || runs[3].Props == nil || runs[3].Props.Italic == nil {
+		t.Errorf("Unexpected runs of the formatted paragraph: %+v", runs)
+	}
+	if len(doc.Body.Tables) != 1 || doc.Body.Tables[0].GetRowCount() != 2 {
+		t.Errorf("Expected a table of 2 rows, got %+v", doc.Body.Tables)
+	}
+}
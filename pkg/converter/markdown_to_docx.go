@@ -0,0 +1,185 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// MarkdownToDocx converts Markdown to a DOCX document
+type MarkdownToDocx struct {
+	Options ConvertOptions
+}
+
+// NewMarkdownToDocx creates a new Markdown to DOCX converter
+func NewMarkdownToDocx(opts ConvertOptions) *MarkdownToDocx {
+	return &MarkdownToDocx{
+		Options: opts,
+	}
+}
+
+// Convert converts a Markdown file to a DOCX document
+func (c *MarkdownToDocx) Convert(inputPath, outputPath string) error {
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read Markdown: %w", err)
+	}
+	return c.Build(string(data)).Save(outputPath)
+}
+
+var (
+	mdHeadingPattern  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdBulletPattern   = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	mdNumberedPattern = regexp.MustCompile(`^(\s*)(\d+)[.)]\s+(.*)$`)
+	mdRulePattern     = regexp.MustCompile(`^\s*((-\s*){3,}|(\*\s*){3,}|(_\s*){3,})$`)
+	mdTableSeparator  = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	mdInlinePattern   = regexp.MustCompile("\\*\\*(.+?)\\*\\*|__(.+?)__|\\*(.+?)\\*|_(.+?)_|`([^`]+)`")
+)
+
+// Build creates a DOCX document from Markdown: headings become Heading1 to
+// Heading6 paragraphs, list items ListParagraph paragraphs starting with a
+// bullet or their number, pipe tables tables, fenced code Courier New
+// paragraphs and quotes Quote paragraphs. Bold, italic and code spans are
+// kept; other inline markup is left as text.
+func (c *MarkdownToDocx) Build(markdown string) *docx.Document {
+	doc := docx.New()
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			doc.Body.Paragraphs = append(doc.Body.Paragraphs, markdownParagraph(strings.Join(para, " ")))
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			fence := trimmed[:3]
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				doc.AddParagraph(lines[i], docx.WithFont("Courier New"))
+			}
+
+		case mdHeadingPattern.MatchString(trimmed):
+			flush()
+			m := mdHeadingPattern.FindStringSubmatch(trimmed)
+			p := markdownParagraph(m[2])
+			docx.WithStyle(fmt.Sprintf("Heading%d", len(m[1])))(&p)
+			doc.Body.Paragraphs = append(doc.Body.Paragraphs, p)
+
+		case mdRulePattern.MatchString(line):
+			flush()
+
+		case mdBulletPattern.MatchString(line):
+			flush()
+			m := mdBulletPattern.FindStringSubmatch(line)
+			doc.Body.Paragraphs = append(doc.Body.Paragraphs, markdownListItem(m[1], "•", m[2]))
+
+		case mdNumberedPattern.MatchString(line):
+			flush()
+			m := mdNumberedPattern.FindStringSubmatch(line)
+			doc.Body.Paragraphs = append(doc.Body.Paragraphs, markdownListItem(m[1], m[2]+".", m[3]))
+
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			p := markdownParagraph(strings.TrimSpace(strings.TrimPrefix(trimmed, ">")))
+			docx.WithStyle("Quote")(&p)
+			docx.WithItalic()(&p)
+			doc.Body.Paragraphs = append(doc.Body.Paragraphs, p)
+
+		case strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && mdTableSeparator.MatchString(lines[i+1]):
+			flush()
+			rows := [][]string{markdownCells(trimmed)}
+			for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+				rows = append(rows, markdownCells(strings.TrimSpace(lines[i])))
+			}
+			i--
+			addMarkdownTable(doc, rows)
+
+		default:
+			para = append(para, trimmed)
+		}
+	}
+	flush()
+	return doc
+}
+
+// markdownListItem returns a list item paragraph, indented two spaces per level
+func markdownListItem(indent, marker, text string) docx.Paragraph {
+	level := len(strings.ReplaceAll(indent, "\t", "  ")) / 2
+	p := markdownParagraph(strings.Repeat("    ", level) + marker + " " + text)
+	docx.WithStyle("ListParagraph")(&p)
+	return p
+}
+
+// markdownParagraph returns a paragraph of Markdown text with its bold,
+// italic and code spans as formatted runs
+func markdownParagraph(text string) docx.Paragraph {
+	var p docx.Paragraph
+	add := func(s string, props *docx.RProps) {
+		if s != "" {
+			p.Runs = append(p.Runs, docx.Run{Props: props, Text: []docx.Text{{Space: "preserve", Content: s}}})
+		}
+	}
+	last := 0
+	for _, m := range mdInlinePattern.FindAllStringSubmatchIndex(text, -1) {
+		add(text[last:m[0]], nil)
+		switch {
+		case m[2] >= 0:
+			add(text[m[2]:m[3]], &docx.RProps{Bold: &docx.Bold{}})
+		case m[4] >= 0:
+			add(text[m[4]:m[5]], &docx.RProps{Bold: &docx.Bold{}})
+		case m[6] >= 0:
+			add(text[m[6]:m[7]], &docx.RProps{Italic: &docx.Italic{}})
+		case m[8] >= 0:
+			add(text[m[8]:m[9]], &docx.RProps{Italic: &docx.Italic{}})
+		default:
+			add(text[m[10]:m[11]], &docx.RProps{RFonts: &docx.RFonts{ASCII: "Courier New", HAnsi: "Courier New"}})
+		}
+		last = m[1]
+	}
+	add(text[last:], nil)
+	if len(p.Runs) == 0 {
+		add(" ", nil)
+	}
+	return p
+}
+
+// markdownCells splits a row of a pipe table into its cells
+func markdownCells(row string) []string {
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+	cells := strings.Split(strings.ReplaceAll(row, `\|`, "\x00"), "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(strings.ReplaceAll(cells[i], "\x00", "|"))
+	}
+	return cells
+}
+
+// addMarkdownTable appends a table of rows, the first in bold as the header
+func addMarkdownTable(doc *docx.Document, rows [][]string) {
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	table := doc.AddTable(len(rows), cols)
+	for r, row := range rows {
+		for c, text := range row {
+			var opts []docx.ParagraphOption
+			if r == 0 {
+				opts = append(opts, docx.WithBold())
+			}
+			_ = table.SetCellText(r, c, text, opts...)
+		}
+	}
+}
@@ -16,6 +16,40 @@ type ConvertOptions struct {
 
 	// Margins specifies page margins in mm (left, top, right, bottom)
 	Margins [4]float64
+
+	// FontMap sets the fonts a document uses, by name, in other fonts, such
+	// as "Calibri" in "Helvetica". Fonts that are neither embedded, mapped
+	// nor one of Arial, Times New Roman and Courier New are set in
+	// FontFamily, and the conversion warns about them.
+	FontMap map[string]string
+
+	// FallbackFonts are TrueType font files to set characters in that the
+	// fonts of their text lack, such as Arabic, Hebrew or CJK characters.
+	// Fonts installed on the system are looked for when none has them.
+	FallbackFonts []string
+
+	// Engine selects the DOCX to PDF converter: "native" (the default) for
+	// the built-in one, "libreoffice" to run LibreOffice, or the name of a
+	// backend added with RegisterBackend. Other options apply to the native
+	// converter only.
+	Engine string
+
+	// Progress, when set, is called as the native converters work through
+	// a document: per paragraph when laying out a DOCX document as PDF,
+	// per page when reading a PDF document into DOCX
+	Progress ProgressFunc
+}
+
+// ProgressFunc receives the progress of a long-running task: current of
+// total steps of the named stage, such as "layout" or "page", are done.
+// Stages may follow one another, each counting from 1 to its own total.
+type ProgressFunc func(current, total int, stage string)
+
+// report calls fn unless it is nil
+func (fn ProgressFunc) report(current, total int, stage string) {
+	if fn != nil {
+		fn(current, total, stage)
+	}
 }
 
 // DefaultOptions returns default conversion options
@@ -1,7 +1,9 @@
 package converter
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/docx"
@@ -22,10 +24,51 @@ func NewPDFToDocx(opts ConvertOptions) *PDFToDocx {
 
 // Convert converts a PDF document to DOCX
 func (c *PDFToDocx) Convert(pdfDoc *pdf.Document, outputPath string) error {
+	return c.ConvertContext(context.Background(), pdfDoc, outputPath)
+}
+
+// ConvertContext converts a PDF document to DOCX like Convert, stopping
+// with ctx's error when ctx is canceled or its deadline passes
+func (c *PDFToDocx) ConvertContext(ctx context.Context, pdfDoc *pdf.Document, outputPath string) error {
+	docxDoc, err := c.BuildContext(ctx, pdfDoc)
+	if err != nil {
+		return err
+	}
+	return docxDoc.Save(outputPath)
+}
+
+// ConvertTo converts a PDF document to DOCX and writes it to w
+func (c *PDFToDocx) ConvertTo(pdfDoc *pdf.Document, w io.Writer) error {
+	return c.ConvertToContext(context.Background(), pdfDoc, w)
+}
+
+// ConvertToContext converts a PDF document to DOCX and writes it to w like
+// ConvertTo, stopping with ctx's error when ctx is done
+func (c *PDFToDocx) ConvertToContext(ctx context.Context, pdfDoc *pdf.Document, w io.Writer) error {
+	docxDoc, err := c.BuildContext(ctx, pdfDoc)
+	if err != nil {
+		return err
+	}
+	_, err = docxDoc.WriteTo(w)
+	return err
+}
+
+// Build creates a DOCX document from the content of a PDF document
+func (c *PDFToDocx) Build(pdfDoc *pdf.Document) *docx.Document {
+	docxDoc, _ := c.BuildContext(context.Background(), pdfDoc)
+	return docxDoc
+}
+
+// BuildContext creates a DOCX document like Build, checking ctx between
+// pages and returning its error once it is done
+func (c *PDFToDocx) BuildContext(ctx context.Context, pdfDoc *pdf.Document) (*docx.Document, error) {
 	docxDoc := docx.New()
 
 	// Process each page
-	for _, page := range pdfDoc.Pages {
+	for i, page := range pdfDoc.Pages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		// Process content
 		for _, content := range page.Content {
 			switch c := content.(type) {
@@ -78,10 +121,10 @@ func (c *PDFToDocx) Convert(pdfDoc *pdf.Document, outputPath string) error {
 				}
 			}
 		}
+		c.Options.Progress.report(i+1, len(pdfDoc.Pages), "page")
 	}
 
-	// Save DOCX
-	return docxDoc.Save(outputPath)
+	return docxDoc, nil
 }
 
 // ConvertFile converts a PDF file to DOCX
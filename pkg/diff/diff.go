@@ -1,7 +1,12 @@
 package diff
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"path"
+	"sort"
 	"strings"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/docx"
@@ -36,12 +41,17 @@ type Change struct {
 	Old      string
 	New      string
 	Position int // Paragraph or line number
-	Context  string
+	// Context locates changes outside of body paragraphs, such as
+	// "table 1, row 2, col 3" or "image in paragraph 4"; empty for paragraphs
+	Context string
 }
 
 // DiffResult represents the result of comparing two documents
 type DiffResult struct {
-	Changes     []Change
+	Changes []Change
+	// Lines holds every line of the comparison in order: the changes along
+	// with the unchanged lines between them, which have type DiffNone
+	Lines       []Change
 	Stats       DiffStats
 	OldDocument string
 	NewDocument string
@@ -100,52 +110,154 @@ func NewDocxDiffer(opts DiffOptions) *DocxDiffer {
 
 // Compare compares two DOCX documents
 func (d *DocxDiffer) Compare(oldPath, newPath string) (*DiffResult, error) {
+	return d.CompareContext(context.Background(), oldPath, newPath)
+}
+
+// CompareContext compares two DOCX documents like Compare, returning ctx's
+// error once it is canceled or its deadline passes. Comparing long
+// documents takes time and memory growing with the product of their
+// lengths, so servers should set a deadline.
+func (d *DocxDiffer) CompareContext(ctx context.Context, oldPath, newPath string) (*DiffResult, error) {
 	// Open documents
 	oldDoc, err := docx.Open(oldPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open old document: %w", err)
 	}
+	defer oldDoc.Close()
 
 	newDoc, err := docx.Open(newPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open new document: %w", err)
 	}
+	defer newDoc.Close()
 
 	// Extract text from paragraphs
 	oldLines := extractLines(oldDoc)
 	newLines := extractLines(newDoc)
 
 	// Compute diff
-	changes := d.computeDiff(oldLines, newLines)
+	lines, err := d.computeDiff(ctx, oldLines, newLines)
+	if err != nil {
+		return nil, err
+	}
+	changes := []Change{}
+	for _, line := range lines {
+		if line.Type != DiffNone {
+			changes = append(changes, line)
+		}
+	}
 
 	// Calculate stats
-	stats := calculateStats(changes)
+	stats := calculateStats(lines)
 
 	return &DiffResult{
 		Changes:     changes,
+		Lines:       lines,
 		Stats:       stats,
 		OldDocument: oldPath,
 		NewDocument: newPath,
 	}, nil
 }
 
-// extractLines extracts text lines from a document
-func extractLines(doc *docx.Document) []string {
-	lines := []string{}
-	for _, para := range doc.Body.Paragraphs {
-		text := ""
-		for _, run := range para.Runs {
-			for _, t := range run.Text {
-				text += t.Content
+// line is a unit of a document that is compared as a whole: a body
+// paragraph, a table cell or an image
+type line struct {
+	kind     byte // 'p' for paragraphs, 'c' for table cells, 'i' for images
+	text     string
+	digest   string // Content hash of images, which are compared by content
+	position int    // Index of the paragraph, or of the one a table follows
+	context  string
+}
+
+// extractLines extracts the paragraphs, table cells and images of a
+// document in reading order. Tables come before the paragraph they precede,
+// and each image follows the paragraph or cell holding it.
+func extractLines(doc *docx.Document) []line {
+	tables := make([]int, len(doc.Body.Tables))
+	for i := range tables {
+		tables[i] = i
+	}
+	sort.SliceStable(tables, func(a, b int) bool {
+		return doc.Body.Tables[tables[a]].Position < doc.Body.Tables[tables[b]].Position
+	})
+
+	lines := []line{}
+	next := 0
+	for i := 0; i <= len(doc.Body.Paragraphs); i++ {
+		for next < len(tables) && (doc.Body.Tables[tables[next]].Position <= i || i == len(doc.Body.Paragraphs)) {
+			lines = append(lines, tableLines(doc, tables[next])...)
+			next++
+		}
+		if i == len(doc.Body.Paragraphs) {
+			break
+		}
+
+		// Paragraph text includes SmartArt node text anchored in the paragraph
+		text, _ := doc.GetParagraphText(i)
+		lines = append(lines, line{kind: 'p', text: text, position: i})
+		lines = append(lines, imageLines(doc, &doc.Body.Paragraphs[i], i, fmt.Sprintf("paragraph %d", i+1))...)
+	}
+	return lines
+}
+
+// tableLines extracts the cells of a table, with their row and column
+func tableLines(doc *docx.Document, index int) []line {
+	table := &doc.Body.Tables[index]
+	var lines []line
+	for r, row := range table.Rows {
+		for c := range row.Cells {
+			context := fmt.Sprintf("table %d, row %d, col %d", index+1, r+1, c+1)
+			var texts []string
+			var images []line
+			for p := range row.Cells[c].Content {
+				para := &row.Cells[c].Content[p]
+				texts = append(texts, paragraphText(para))
+				images = append(images, imageLines(doc, para, table.Position, context)...)
 			}
+			lines = append(lines, line{kind: 'c', text: strings.Join(texts, "\n"), position: table.Position, context: context})
+			lines = append(lines, images...)
+		}
+	}
+	return lines
+}
+
+// imageLines extracts the pictures of a paragraph, identified by a digest
+// of their content so that replacing a picture is reported
+func imageLines(doc *docx.Document, para *docx.Paragraph, position int, location string) []line {
+	var lines []line
+	for r := range para.Runs {
+		img, ok := doc.RunImage(&para.Runs[r])
+		if !ok {
+			continue
 		}
-		lines = append(lines, text)
+		sum := sha256.Sum256(img.Data)
+		digest := hex.EncodeToString(sum[:])
+		lines = append(lines, line{
+			kind:     'i',
+			text:     fmt.Sprintf("[image %s sha256:%s]", path.Base(img.Part), digest[:12]),
+			digest:   digest,
+			position: position,
+			context:  "image in " + location,
+		})
 	}
 	return lines
 }
 
-// computeDiff computes the diff between two sets of lines
-func (d *DocxDiffer) computeDiff(oldLines, newLines []string) []Change {
+// paragraphText returns the text of the runs of a paragraph
+func paragraphText(para *docx.Paragraph) string {
+	var sb strings.Builder
+	for _, r := range para.Runs {
+		for _, t := range r.Text {
+			sb.WriteString(t.Content)
+		}
+	}
+	return sb.String()
+}
+
+// computeDiff computes the diff between two sets of lines, returning
+// every line with the unchanged ones marked DiffNone. ctx is checked for
+// each row of the comparison table.
+func (d *DocxDiffer) computeDiff(ctx context.Context, oldLines, newLines []line) ([]Change, error) {
 	changes := []Change{}
 
 	// Use Myers diff algorithm (simplified implementation)
@@ -160,8 +272,11 @@ func (d *DocxDiffer) computeDiff(oldLines, newLines []string) []Change {
 
 	// Fill DP table
 	for i := 1; i <= oldLen; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		for j := 1; j <= newLen; j++ {
-			if d.linesEqual(oldLines[i-1], newLines[j-1]) {
+			if d.linesMatch(oldLines[i-1], newLines[j-1]) {
 				dp[i][j] = dp[i-1][j-1] + 1
 			} else {
 				dp[i][j] = max(dp[i-1][j], dp[i][j-1])
@@ -172,30 +287,74 @@ func (d *DocxDiffer) computeDiff(oldLines, newLines []string) []Change {
 	// Backtrack to find changes
 	i, j := oldLen, newLen
 	for i > 0 || j > 0 {
-		if i > 0 && j > 0 && d.linesEqual(oldLines[i-1], newLines[j-1]) {
+		if i > 0 && j > 0 && d.linesMatch(oldLines[i-1], newLines[j-1]) {
 			// No change
+			changes = append([]Change{{
+				Type:     DiffNone,
+				Old:      oldLines[i-1].text,
+				New:      newLines[j-1].text,
+				Position: newLines[j-1].position,
+				Context:  newLines[j-1].context,
+			}}, changes...)
 			i--
 			j--
 		} else if j > 0 && (i == 0 || dp[i][j-1] >= dp[i-1][j]) {
 			// Addition
 			changes = append([]Change{{
 				Type:     DiffAdded,
-				New:      newLines[j-1],
-				Position: j - 1,
+				New:      newLines[j-1].text,
+				Position: newLines[j-1].position,
+				Context:  newLines[j-1].context,
 			}}, changes...)
 			j--
 		} else if i > 0 {
 			// Deletion
 			changes = append([]Change{{
 				Type:     DiffDeleted,
-				Old:      oldLines[i-1],
-				Position: i - 1,
+				Old:      oldLines[i-1].text,
+				Position: oldLines[i-1].position,
+				Context:  oldLines[i-1].context,
 			}}, changes...)
 			i--
 		}
 	}
 
-	return changes
+	return pairChanges(changes), nil
+}
+
+// pairChanges merges the deletion and addition of the same table cell or
+// image between two unchanged lines into a modification
+func pairChanges(changes []Change) []Change {
+	result := make([]Change, 0, len(changes))
+	paired := make([]bool, len(changes))
+	for i, change := range changes {
+		if paired[i] {
+			continue
+		}
+		if change.Type == DiffDeleted && change.Context != "" {
+			for j := i + 1; j < len(changes) && changes[j].Type != DiffNone; j++ {
+				if !paired[j] && changes[j].Type == DiffAdded && changes[j].Context == change.Context {
+					change.Type = DiffModified
+					change.New = changes[j].New
+					paired[j] = true
+					break
+				}
+			}
+		}
+		result = append(result, change)
+	}
+	return result
+}
+
+// linesMatch checks if two lines are the same unit with equal content
+func (d *DocxDiffer) linesMatch(a, b line) bool {
+	if a.kind != b.kind {
+		return false
+	}
+	if a.kind == 'i' {
+		return a.digest == b.digest
+	}
+	return d.linesEqual(a.text, b.text)
 }
 
 // linesEqual checks if two lines are equal considering options
@@ -218,6 +377,10 @@ func calculateStats(changes []Change) DiffStats {
 	stats := DiffStats{}
 
 	for _, change := range changes {
+		if change.Type == DiffNone {
+			stats.UnchangedLines++
+			continue
+		}
 		stats.TotalChanges++
 		switch change.Type {
 		case DiffAdded:
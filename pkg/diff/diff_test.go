@@ -1,10 +1,22 @@
 package diff
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/docx"
 )
@@ -340,6 +352,39 @@ func TestEmptyDiff(t *testing.T) {
 	}
 }
 
+func TestCompareContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldDoc := docx.New()
+	newDoc := docx.New()
+	for i := 0; i < 50; i++ {
+		oldDoc.AddParagraph(fmt.Sprintf("Clause %d", i))
+		newDoc.AddParagraph(fmt.Sprintf("Clause %d (amended)", i))
+	}
+	oldPath := filepath.Join(tmpDir, "old.docx")
+	newPath := filepath.Join(tmpDir, "new.docx")
+	if err := oldDoc.Save(oldPath); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := newDoc.Save(newPath); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	differ := NewDocxDiffer(DefaultDiffOptions())
+	result, err := differ.CompareContext(context.Background(), oldPath, newPath)
+	if err != nil {
+		t.Fatalf("CompareContext failed: %v", err)
+	}
+	if result.Stats.TotalChanges == 0 {
+		t.Error("Expected changes")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	if _, err := differ.CompareContext(ctx, oldPath, newPath); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
 func TestHTMLRendererOutput(t *testing.T) {
 	tmpDir := t.TempDir()
 	outputPath := filepath.Join(tmpDir, "diff.html")
@@ -377,3 +422,246 @@ func TestHTMLRendererOutput(t *testing.T) {
 		t.Error("Output file is empty")
 	}
 }
+
+func TestCompareTablesAndImages(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pngData := func(c color.Color) []byte {
+		img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+		img.Set(0, 0, c)
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			t.Fatalf("Failed to encode image: %v", err)
+		}
+		return buf.Bytes()
+	}
+
+	build := func(name, price string, logo color.Color, extraRow bool) string {
+		doc := docx.New()
+		doc.AddParagraph("Price list")
+		rows := 2
+		if extraRow {
+			rows = 3
+		}
+		table := doc.AddTable(rows, 2)
+		table.SetCellText(0, 0, "Product")
+		table.SetCellText(0, 1, "Price")
+		table.SetCellText(1, 0, "Widget")
+		table.SetCellText(1, 1, price)
+		if extraRow {
+			table.SetCellText(2, 0, "Gadget")
+			table.SetCellText(2, 1, "$7")
+		}
+		if err := doc.AddImageFromBytes("logo.png", pngData(logo)); err != nil {
+			t.Fatalf("Failed to add image: %v", err)
+		}
+		path := filepath.Join(tmpDir, name)
+		if err := doc.Save(path); err != nil {
+			t.Fatalf("Failed to save document: %v", err)
+		}
+		return path
+	}
+
+	oldPath := build("old.docx", "$5", color.White, false)
+	newPath := build("new.docx", "$6", color.Black, true)
+
+	result, err := CompareDOCX(oldPath, newPath, DefaultDiffOptions())
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+
+	find := func(typ DiffType, context string) *Change {
+		for i := range result.Changes {
+			if result.Changes[i].Type == typ && result.Changes[i].Context == context {
+				return &result.Changes[i]
+			}
+		}
+		t.Errorf("Expected a %s change at %q, got %+v", typ, context, result.Changes)
+		return nil
+	}
+
+	if c := find(DiffModified, "table 1, row 2, col 2"); c != nil && (c.Old != "$5" || c.New != "$6") {
+		t.Errorf("Expected $5 → $6, got %q → %q", c.Old, c.New)
+	}
+	if c := find(DiffAdded, "table 1, row 3, col 1"); c != nil && c.New != "Gadget" {
+		t.Errorf("Expected Gadget to be added, got %q", c.New)
+	}
+	if c := find(DiffModified, "image in paragraph 2"); c != nil && (c.Old == c.New || !strings.Contains(c.New, "sha256:")) {
+		t.Errorf("Expected the image digest to change, got %q → %q", c.Old, c.New)
+	}
+	if result.Stats.TotalChanges != 4 {
+		t.Errorf("Expected 4 changes, got %d: %+v", result.Stats.TotalChanges, result.Changes)
+	}
+
+	// An identical document has no changes
+	result, err = CompareDOCX(oldPath, oldPath, DefaultDiffOptions())
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	if len(result.Changes) != 0 {
+		t.Errorf("Expected no changes, got %+v", result.Changes)
+	}
+
+	text, _ := NewPlainTextRenderer(false, false).Render(&DiffResult{Changes: []Change{{Type: DiffAdded, New: "Gadget", Context: "table 1, row 3, col 1"}}})
+	if !strings.Contains(text, "Table 1, row 3, col 1: + Gadget") {
+		t.Errorf("Expected the cell location in the output, got:\n%s", text)
+	}
+}
+
+func TestDOCXRenderer(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	oldDoc := docx.New()
+	oldDoc.AddParagraph("Title")
+	oldDoc.AddParagraph("Payment within 30 days.")
+	oldDoc.AddParagraph("Signed <A & B>")
+	oldPath := filepath.Join(tmpDir, "old.docx")
+	oldDoc.Save(oldPath)
+
+	newDoc := docx.New()
+	newDoc.AddParagraph("Title")
+	newDoc.AddParagraph("Payment within 15 days.")
+	newDoc.AddParagraph("Signed <A & B>")
+	newPath := filepath.Join(tmpDir, "new.docx")
+	newDoc.Save(newPath)
+
+	result, err := CompareDOCX(oldPath, newPath, DefaultDiffOptions())
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	if result.Stats.UnchangedLines != 2 {
+		t.Errorf("Expected 2 unchanged lines, got %d", result.Stats.UnchangedLines)
+	}
+
+	renderer := NewDOCXRenderer("Reviewer")
+	data, err := renderer.Bytes(result)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	// The comparison opens as a document
+	if _, err := docx.ReadBytes(data); err != nil {
+		t.Fatalf("Failed to open the comparison: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Failed to read package: %v", err)
+	}
+	var documentXML string
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			rc, _ := f.Open()
+			content, _ := io.ReadAll(rc)
+			rc.Close()
+			documentXML = string(content)
+		}
+	}
+
+	dec := xml.NewDecoder(strings.NewReader(documentXML))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Invalid document XML: %v", err)
+		}
+	}
+
+	for _, want := range []string{
+		`<w:t xml:space="preserve">Title</w:t>`,
+		`w:author="Reviewer"`,
+		`<w:delText xml:space="preserve">Payment within 30 days.</w:delText>`,
+		`<w:t xml:space="preserve">Payment within 15 days.</w:t></w:r></w:ins>`,
+		`Signed &lt;A &amp; B&gt;`,
+	} {
+		if !strings.Contains(documentXML, want) {
+			t.Errorf("Expected %s in document XML:\n%s", want, documentXML)
+		}
+	}
+	if strings.Count(documentXML, "<w:ins ") != 2 || strings.Count(documentXML, "<w:del ") != 2 {
+		t.Errorf("Expected tracked text and paragraph marks for one insertion and one deletion:\n%s", documentXML)
+	}
+}
+
+func TestJSONAndUnifiedRenderers(t *testing.T) {
+	same := func(text string) Change { return Change{Type: DiffNone, Old: text, New: text} }
+	result := &DiffResult{
+		OldDocument: "v1.docx",
+		NewDocument: "v2.docx",
+		Lines: []Change{
+			same("A"), same("B"), same("C"),
+			{Type: DiffDeleted, Old: "D"},
+			{Type: DiffAdded, New: "D2"},
+			same("E"), same("F"), same("G"), same("H"), same("I"), same("J"),
+			{Type: DiffModified, Old: "$5", New: "$6", Context: "table 1, row 2, col 2"},
+			same("K"),
+		},
+	}
+	for _, line := range result.Lines {
+		if line.Type != DiffNone {
+			result.Changes = append(result.Changes, line)
+		}
+	}
+	result.Stats = calculateStats(result.Lines)
+
+	unified, err := NewUnifiedRenderer(2).Render(result)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	want := `--- v1.docx
++++ v2.docx
+@@ -2,5 +2,5 @@
+ B
+ C
+-D
++D2
+ E
+ F
+@@ -9,4 +9,4 @@
+ I
+ J
+-table 1, row 2, col 2: $5
++table 1, row 2, col 2: $6
+ K
+`
+	if unified != want {
+		t.Errorf("Unexpected unified diff:\n%s\nwant:\n%s", unified, want)
+	}
+
+	// Wider context merges the hunks
+	unified, _ = NewUnifiedRenderer(3).Render(result)
+	if strings.Count(unified, "@@ -") != 1 || !strings.Contains(unified, "@@ -1,12 +1,12 @@") {
+		t.Errorf("Expected a single hunk, got:\n%s", unified)
+	}
+
+	if unified, _ := NewUnifiedRenderer(3).Render(&DiffResult{}); unified != "" {
+		t.Errorf("Expected an empty diff, got %q", unified)
+	}
+
+	out, err := NewJSONRenderer(true).Render(result)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	var decoded struct {
+		OldDocument string `json:"oldDocument"`
+		Stats       struct {
+			TotalChanges int `json:"totalChanges"`
+			Modified     int `json:"modified"`
+		} `json:"stats"`
+		Changes []struct {
+			Type    string `json:"type"`
+			Old     string `json:"old"`
+			New     string `json:"new"`
+			Context string `json:"context"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out)
+	}
+	if decoded.OldDocument != "v1.docx" || decoded.Stats.TotalChanges != 3 || decoded.Stats.Modified != 1 || len(decoded.Changes) != 3 {
+		t.Errorf("Unexpected JSON: %s", out)
+	}
+	if c := decoded.Changes[2]; c.Type != "modified" || c.Old != "$5" || c.New != "$6" || c.Context != "table 1, row 2, col 2" {
+		t.Errorf("Unexpected change: %+v", c)
+	}
+}
@@ -0,0 +1,166 @@
+package diff
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// DOCXRenderer renders a diff as a Word document holding the new version,
+// with deletions and insertions recorded as tracked changes, so reviewers
+// can open the comparison in Word and accept or reject each change, as with
+// Word's own Compare feature
+type DOCXRenderer struct {
+	// Author is the name the tracked changes are attributed to
+	Author string
+
+	// Date is the time recorded on the tracked changes
+	Date time.Time
+}
+
+// NewDOCXRenderer creates a new DOCX renderer attributing changes to author
+func NewDOCXRenderer(author string) *DOCXRenderer {
+	return &DOCXRenderer{Author: author, Date: time.Now()}
+}
+
+// Render renders the diff result as the content of a .docx file
+func (r *DOCXRenderer) Render(result *DiffResult) (string, error) {
+	data, err := r.Bytes(result)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Bytes renders the diff result as a .docx file. Paragraphs appear in the
+// order of the comparison. Table cells and images are written as paragraphs
+// labelled with their location.
+func (r *DOCXRenderer) Bytes(result *DiffResult) ([]byte, error) {
+	lines := result.Lines
+	if len(lines) == 0 {
+		lines = result.Changes
+	}
+
+	w := &revisionWriter{author: r.Author, date: r.Date.UTC().Format(time.RFC3339)}
+	if w.author == "" {
+		w.author = "DocxSmith"
+	}
+	for _, line := range lines {
+		w.paragraph(line)
+	}
+
+	documentXML := xml.Header + `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		w.body.String() + `</w:body></w:document>`
+
+	// Take the rest of the package from a new document
+	pkg := docx.New()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range pkg.PartNames() {
+		if name == "word/document.xml" {
+			continue
+		}
+		data, _ := pkg.GetPart(name)
+		if err := writeZipPart(zw, name, data); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeZipPart(zw, "word/document.xml", []byte(documentXML)); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write document: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeZipPart adds a part to the package
+func writeZipPart(zw *zip.Writer, name string, data []byte) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// revisionWriter writes the paragraphs of a comparison as WordprocessingML
+type revisionWriter struct {
+	author string
+	date   string
+	nextID int
+	body   strings.Builder
+}
+
+// paragraph writes a line of the comparison. A paragraph added or deleted
+// as a whole also has its paragraph mark tracked, so that accepting or
+// rejecting the change leaves no empty paragraph behind.
+func (w *revisionWriter) paragraph(line Change) {
+	w.body.WriteString("<w:p>")
+	if line.Context == "" {
+		switch line.Type {
+		case DiffAdded:
+			w.body.WriteString("<w:pPr><w:rPr>" + w.mark("w:ins", "/") + "</w:rPr></w:pPr>")
+		case DiffDeleted:
+			w.body.WriteString("<w:pPr><w:rPr>" + w.mark("w:del", "/") + "</w:rPr></w:pPr>")
+		}
+	} else {
+		label := line.Context
+		w.body.WriteString(`<w:r><w:rPr><w:i/><w:color w:val="808080"/></w:rPr>` +
+			runText("w:t", strings.ToUpper(label[:1])+label[1:]+": ") + "</w:r>")
+	}
+
+	switch line.Type {
+	case DiffAdded:
+		w.revision("w:ins", "w:t", line.New)
+	case DiffDeleted:
+		w.revision("w:del", "w:delText", line.Old)
+	case DiffModified:
+		w.revision("w:del", "w:delText", line.Old)
+		w.revision("w:ins", "w:t", line.New)
+	default:
+		w.body.WriteString("<w:r>" + runText("w:t", line.New) + "</w:r>")
+	}
+	w.body.WriteString("</w:p>")
+}
+
+// revision writes text as a tracked insertion or deletion
+func (w *revisionWriter) revision(element, textElement, text string) {
+	if text == "" {
+		return
+	}
+	w.body.WriteString(w.mark(element, "") + "<w:r>" + runText(textElement, text) + "</w:r></" + element + ">")
+}
+
+// mark opens a tracked change element with a new ID; close is "/" for an
+// empty element
+func (w *revisionWriter) mark(element, close string) string {
+	w.nextID++
+	return fmt.Sprintf(`<%s w:id="%d" w:author="%s" w:date="%s"%s>`, element, w.nextID, escapeXML(w.author), w.date, close)
+}
+
+// runText writes the content of a run, turning line breaks into w:br
+func runText(textElement, text string) string {
+	var sb strings.Builder
+	for i, part := range strings.Split(text, "\n") {
+		if i > 0 {
+			sb.WriteString("<w:br/>")
+		}
+		sb.WriteString("<" + textElement + ` xml:space="preserve">` + escapeXML(part) + "</" + textElement + ">")
+	}
+	return sb.String()
+}
+
+// escapeXML escapes text for use in element content and attributes
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
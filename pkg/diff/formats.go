@@ -0,0 +1,223 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSONRenderer renders diff as JSON for other tools to consume
+type JSONRenderer struct {
+	// Indent pretty-prints the output
+	Indent bool
+}
+
+// NewJSONRenderer creates a new JSON renderer
+func NewJSONRenderer(indent bool) *JSONRenderer {
+	return &JSONRenderer{Indent: indent}
+}
+
+// jsonChange is a change as written by JSONRenderer
+type jsonChange struct {
+	Type     string `json:"type"`
+	Old      string `json:"old,omitempty"`
+	New      string `json:"new,omitempty"`
+	Position int    `json:"position"`
+	Context  string `json:"context,omitempty"`
+}
+
+// jsonResult is a diff result as written by JSONRenderer
+type jsonResult struct {
+	OldDocument string       `json:"oldDocument"`
+	NewDocument string       `json:"newDocument"`
+	Stats       jsonStats    `json:"stats"`
+	Changes     []jsonChange `json:"changes"`
+}
+
+// jsonStats are diff statistics as written by JSONRenderer
+type jsonStats struct {
+	TotalChanges   int `json:"totalChanges"`
+	Added          int `json:"added"`
+	Deleted        int `json:"deleted"`
+	Modified       int `json:"modified"`
+	UnchangedLines int `json:"unchanged"`
+}
+
+// Render renders the diff result as a JSON object with the documents
+// compared, the statistics and the changes. Positions are 1-based, like
+// the line numbers of the other formats.
+func (r *JSONRenderer) Render(result *DiffResult) (string, error) {
+	out := jsonResult{
+		OldDocument: result.OldDocument,
+		NewDocument: result.NewDocument,
+		Stats: jsonStats{
+			TotalChanges:   result.Stats.TotalChanges,
+			Added:          result.Stats.AddedLines,
+			Deleted:        result.Stats.DeletedLines,
+			Modified:       result.Stats.ModifiedLines,
+			UnchangedLines: result.Stats.UnchangedLines,
+		},
+		Changes: make([]jsonChange, len(result.Changes)),
+	}
+	for i, change := range result.Changes {
+		out.Changes[i] = jsonChange{
+			Type:     change.Type.String(),
+			Old:      change.Old,
+			New:      change.New,
+			Position: change.Position + 1,
+			Context:  change.Context,
+		}
+	}
+
+	var data []byte
+	var err error
+	if r.Indent {
+		data, err = json.MarshalIndent(out, "", "  ")
+	} else {
+		data, err = json.Marshal(out)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to encode diff: %w", err)
+	}
+	return string(data), nil
+}
+
+// UnifiedRenderer renders diff in the unified format of git and diff -u,
+// with ---/+++ headers and @@ hunks surrounded by context lines
+type UnifiedRenderer struct {
+	// ContextLines is the number of unchanged lines shown around changes
+	ContextLines int
+}
+
+// NewUnifiedRenderer creates a new unified diff renderer showing
+// contextLines unchanged lines around each change
+func NewUnifiedRenderer(contextLines int) *UnifiedRenderer {
+	return &UnifiedRenderer{ContextLines: max(contextLines, 0)}
+}
+
+// unifiedLine is a line of a unified diff with its 1-based line numbers
+// in the old and new documents
+type unifiedLine struct {
+	op       byte // ' ', '-' or '+'
+	text     string
+	old, new int
+}
+
+// Render renders the diff result as a unified diff. Lines are paragraphs,
+// table cells and images, numbered in document order; cells and images are
+// prefixed with their location. Identical documents give an empty diff.
+func (r *UnifiedRenderer) Render(result *DiffResult) (string, error) {
+	lines := r.lines(result)
+
+	var sb strings.Builder
+	for start := 0; start < len(lines); {
+		// Find the next change and the end of its hunk, merging changes
+		// separated by no more than twice the context
+		first := start
+		for first < len(lines) && lines[first].op == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		last := first
+		for i := first + 1; i < len(lines) && i-last-1 <= 2*r.ContextLines; i++ {
+			if lines[i].op != ' ' {
+				last = i
+			}
+		}
+
+		from := max(first-r.ContextLines, start)
+		to := min(last+r.ContextLines+1, len(lines))
+
+		if sb.Len() == 0 {
+			sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", result.OldDocument, result.NewDocument))
+		}
+		sb.WriteString(hunkHeader(lines[from:to]))
+		for _, line := range lines[from:to] {
+			sb.WriteByte(line.op)
+			sb.WriteString(line.text)
+			sb.WriteByte('\n')
+		}
+		start = to
+	}
+	return sb.String(), nil
+}
+
+// lines numbers the lines of the comparison, splitting modifications into
+// a deletion and an addition
+func (r *UnifiedRenderer) lines(result *DiffResult) []unifiedLine {
+	source := result.Lines
+	if len(source) == 0 {
+		source = result.Changes
+	}
+
+	var lines []unifiedLine
+	oldLine, newLine := 0, 0
+	add := func(op byte, text string, change Change) {
+		if change.Context != "" {
+			text = change.Context + ": " + text
+		}
+		text = strings.ReplaceAll(text, "\n", " ")
+		switch op {
+		case ' ':
+			oldLine++
+			newLine++
+		case '-':
+			oldLine++
+		case '+':
+			newLine++
+		}
+		lines = append(lines, unifiedLine{op: op, text: text, old: oldLine, new: newLine})
+	}
+
+	for _, change := range source {
+		switch change.Type {
+		case DiffAdded:
+			add('+', change.New, change)
+		case DiffDeleted:
+			add('-', change.Old, change)
+		case DiffModified:
+			add('-', change.Old, change)
+			add('+', change.New, change)
+		default:
+			add(' ', change.New, change)
+		}
+	}
+	return lines
+}
+
+// hunkHeader writes the @@ -start,count +start,count @@ line of a hunk
+func hunkHeader(hunk []unifiedLine) string {
+	oldStart, oldCount, newStart, newCount := 0, 0, 0, 0
+	for _, line := range hunk {
+		if line.op != '+' {
+			if oldCount == 0 {
+				oldStart = line.old
+			}
+			oldCount++
+		}
+		if line.op != '-' {
+			if newCount == 0 {
+				newStart = line.new
+			}
+			newCount++
+		}
+	}
+	// Empty ranges start after the line before them
+	if oldCount == 0 {
+		oldStart = hunk[0].old
+	}
+	if newCount == 0 {
+		newStart = hunk[0].new
+	}
+	return fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+}
+
+// hunkRange formats a range of lines, omitting a count of 1 as git does
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
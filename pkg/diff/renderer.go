@@ -137,8 +137,17 @@ func (r *HTMLRenderer) renderChange(change Change) string {
 		text = html.EscapeString(change.Old)
 	}
 
-	return fmt.Sprintf(`<div class="diff-line %s"><span class="position">Line %d:</span>%s</div>`,
-		class, change.Position+1, text)
+	return fmt.Sprintf(`<div class="diff-line %s"><span class="position">%s:</span>%s</div>`,
+		class, html.EscapeString(location(change)), text)
+}
+
+// location describes where a change is: its line, or the table cell or
+// image it concerns
+func location(change Change) string {
+	if change.Context != "" {
+		return strings.ToUpper(change.Context[:1]) + change.Context[1:]
+	}
+	return fmt.Sprintf("Line %d", change.Position+1)
 }
 
 // MarkdownRenderer renders diff as Markdown
@@ -187,11 +196,11 @@ func (r *MarkdownRenderer) Render(result *DiffResult) (string, error) {
 func (r *MarkdownRenderer) renderChange(change Change) string {
 	switch change.Type {
 	case DiffAdded:
-		return fmt.Sprintf("**Line %d** `+` %s\n\n", change.Position+1, change.New)
+		return fmt.Sprintf("**%s** `+` %s\n\n", location(change), change.New)
 	case DiffDeleted:
-		return fmt.Sprintf("**Line %d** `-` ~~%s~~\n\n", change.Position+1, change.Old)
+		return fmt.Sprintf("**%s** `-` ~~%s~~\n\n", location(change), change.Old)
 	case DiffModified:
-		return fmt.Sprintf("**Line %d** `~` ~~%s~~ → %s\n\n", change.Position+1, change.Old, change.New)
+		return fmt.Sprintf("**%s** `~` ~~%s~~ → %s\n\n", location(change), change.Old, change.New)
 	default:
 		return ""
 	}
@@ -263,11 +272,11 @@ func (r *PlainTextRenderer) renderChange(change Change) string {
 	}
 
 	if change.Type == DiffModified {
-		return fmt.Sprintf("[%s] Line %d: %s → %s\n", prefix, change.Position+1, change.Old, change.New)
+		return fmt.Sprintf("[%s] %s: %s → %s\n", prefix, location(change), change.Old, change.New)
 	} else if change.Type == DiffAdded {
-		return fmt.Sprintf("[%s] Line %d: %s %s\n", prefix, change.Position+1, symbol, change.New)
+		return fmt.Sprintf("[%s] %s: %s %s\n", prefix, location(change), symbol, change.New)
 	} else if change.Type == DiffDeleted {
-		return fmt.Sprintf("[%s] Line %d: %s %s\n", prefix, change.Position+1, symbol, change.Old)
+		return fmt.Sprintf("[%s] %s: %s %s\n", prefix, location(change), symbol, change.Old)
 	}
 
 	return ""
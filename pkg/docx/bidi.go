@@ -0,0 +1,62 @@
+package docx
+
+// WithRTL sets the paragraph right to left, for Arabic, Hebrew and other
+// right-to-left text: it starts at the right margin, and its runs are laid
+// out right to left in their complex script formatting
+func WithRTL() ParagraphOption {
+	return func(p *Paragraph) {
+		if p.Props == nil {
+			p.Props = &PProps{}
+		}
+		p.Props.Bidi = &Bidi{}
+		p.forEachRun(func(r *Run) {
+			if r.Props == nil {
+				r.Props = &RProps{}
+			}
+			r.Props.RTL = &RTL{}
+		})
+	}
+}
+
+// WithComplexScriptFont sets the font of the paragraph's complex script
+// text, such as Arabic, leaving the font of other text as it is
+func WithComplexScriptFont(name string) ParagraphOption {
+	return func(p *Paragraph) {
+		p.forEachRun(func(r *Run) {
+			if r.Props == nil {
+				r.Props = &RProps{}
+			}
+			if r.Props.RFonts == nil {
+				r.Props.RFonts = &RFonts{}
+			}
+			r.Props.RFonts.CS = name
+		})
+	}
+}
+
+// IsRTL reports whether the paragraph is set right to left
+func (p *Paragraph) IsRTL() bool {
+	return p.Props != nil && p.Props.Bidi.On()
+}
+
+// On reports whether the property sets its paragraph right to left
+func (b *Bidi) On() bool {
+	return b != nil && b.Val != "false" && b.Val != "0" && b.Val != "off"
+}
+
+// On reports whether the property sets its run right to left
+func (r *RTL) On() bool {
+	return r != nil && r.Val != "false" && r.Val != "0" && r.Val != "off"
+}
+
+// On reports whether the property formats its run as complex script text
+func (c *CS) On() bool {
+	return c != nil && c.Val != "false" && c.Val != "0" && c.Val != "off"
+}
+
+// IsComplexScript reports whether a run's text takes its complex script
+// formatting, BoldCS, ItalicCS, SizeCS and the CS font, as Word gives
+// right-to-left runs, instead of Bold, Italic, Size and the ASCII font
+func (p *RProps) IsComplexScript() bool {
+	return p != nil && (p.RTL.On() || p.CS.On())
+}
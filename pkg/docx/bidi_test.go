@@ -0,0 +1,51 @@
+package docx
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestRTLParagraph(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("مرحبا بالعالم", WithRTL(), WithBold(), WithSize("28"), WithComplexScriptFont("Arial"))
+	doc.AddParagraph("Hello")
+
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	doc2, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+	para, err := xml.Marshal(doc2.Body.Paragraphs[0])
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	for _, want := range []string{"<bidi>", "<bCs>", `<szCs val="28">`, "<rtl>", `cs="Arial"`} {
+		if !strings.Contains(string(para), want) {
+			t.Errorf("Expected %s in %s", want, para)
+		}
+	}
+	rtl, ltr := doc2.Body.Paragraphs[0], doc2.Body.Paragraphs[1]
+	if !rtl.IsRTL() || ltr.IsRTL() {
+		t.Errorf("IsRTL() = %v, %v", rtl.IsRTL(), ltr.IsRTL())
+	}
+	props := rtl.Runs[0].Props
+	if !props.IsComplexScript() || props.BoldCS == nil || props.SizeCS == nil || props.SizeCS.Val != "28" || props.RFonts.CS != "Arial" {
+		t.Errorf("Expected complex script formatting, got %+v", props)
+	}
+	if ltr.Runs[0].Props.IsComplexScript() {
+		t.Error("Expected a left-to-right run")
+	}
+
+	// Properties turned off explicitly
+	off := Paragraph{Props: &PProps{Bidi: &Bidi{Val: "0"}}}
+	if off.IsRTL() || (&RProps{RTL: &RTL{Val: "false"}}).IsComplexScript() {
+		t.Error("Expected properties set to false to be off")
+	}
+	if !(&RProps{CS: &CS{}}).IsComplexScript() {
+		t.Error("Expected w:cs to format a run as complex script")
+	}
+}
@@ -0,0 +1,215 @@
+package docx
+
+import (
+	"encoding/xml"
+	"sort"
+)
+
+// UnmarshalXML decodes the body, recording where tables and content controls
+// sit relative to the paragraphs so the original order can be written back
+func (b *Body) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	b.XMLName = start.Name
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				var p Paragraph
+				if err := dec.DecodeElement(&p, &t); err != nil {
+					return err
+				}
+				b.Paragraphs = append(b.Paragraphs, p)
+			case "tbl":
+				var table Table
+				if err := dec.DecodeElement(&table, &t); err != nil {
+					return err
+				}
+				table.Position = len(b.Paragraphs)
+				b.Tables = append(b.Tables, table)
+			case "sdt":
+				var sdt SDT
+				if err := dec.DecodeElement(&sdt, &t); err != nil {
+					return err
+				}
+				sdt.Position = len(b.Paragraphs)
+				b.SDTs = append(b.SDTs, sdt)
+			case "sectPr":
+				var sectPr RawElement
+				if err := dec.DecodeElement(&sectPr, &t); err != nil {
+					return err
+				}
+				b.SectPr = &sectPr
+			default:
+				if err := dec.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// SpliceParagraphs replaces count paragraphs starting at index with paras.
+// Tables and content controls keep their place relative to the surrounding
+// paragraphs; those that sat at or inside the replaced range end up right
+// after the inserted paragraphs.
+func (b *Body) SpliceParagraphs(index, count int, paras ...Paragraph) {
+	if index < 0 {
+		index = 0
+	}
+	if index > len(b.Paragraphs) {
+		index = len(b.Paragraphs)
+	}
+	if count < 0 {
+		count = 0
+	}
+	if index+count > len(b.Paragraphs) {
+		count = len(b.Paragraphs) - index
+	}
+
+	result := make([]Paragraph, 0, len(b.Paragraphs)-count+len(paras))
+	result = append(result, b.Paragraphs[:index]...)
+	result = append(result, paras...)
+	result = append(result, b.Paragraphs[index+count:]...)
+	b.Paragraphs = result
+
+	shift := func(pos int) int {
+		if pos < index {
+			return pos
+		}
+		return max(pos, index+count) - count + len(paras)
+	}
+	for i := range b.Tables {
+		b.Tables[i].Position = shift(b.Tables[i].Position)
+	}
+	for i := range b.SDTs {
+		b.SDTs[i].Position = shift(b.SDTs[i].Position)
+	}
+}
+
+// removeBlocks removes the tables and content controls placed from position
+// from to position to (inclusive)
+func (b *Body) removeBlocks(from, to int) {
+	tables := b.Tables[:0]
+	for _, t := range b.Tables {
+		if t.Position < from || t.Position > to {
+			tables = append(tables, t)
+		}
+	}
+	b.Tables = tables
+	sdts := b.SDTs[:0]
+	for _, sdt := range b.SDTs {
+		if sdt.Position < from || sdt.Position > to {
+			sdts = append(sdts, sdt)
+		}
+	}
+	b.SDTs = sdts
+}
+
+// content returns the body's paragraphs, tables and content controls in
+// document order, as pointers so they aren't copied to be marshalled
+func (b *Body) content() []interface{} {
+	return blockContent(b.Paragraphs, b.Tables, b.SDTs)
+}
+
+// blockContent merges paragraphs with the tables and content controls
+// placed among them in document order, as pointers
+func blockContent(paragraphs []Paragraph, tables []Table, sdts []SDT) []interface{} {
+	type block struct {
+		position int
+		value    interface{}
+	}
+
+	var blocks []block
+	for i := range tables {
+		blocks = append(blocks, block{tables[i].Position, &tables[i]})
+	}
+	for i := range sdts {
+		blocks = append(blocks, block{sdts[i].Position, &sdts[i]})
+	}
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].position < blocks[j].position })
+
+	content := make([]interface{}, 0, len(paragraphs)+len(blocks))
+	next := 0
+	for i := range paragraphs {
+		for next < len(blocks) && blocks[next].position <= i {
+			content = append(content, blocks[next].value)
+			next++
+		}
+		content = append(content, &paragraphs[i])
+	}
+	for ; next < len(blocks); next++ {
+		content = append(content, blocks[next].value)
+	}
+	return content
+}
+
+// UnmarshalXML decodes the cell, recording where nested tables and content
+// controls sit relative to its paragraphs so the original order can be
+// written back
+func (c *TblCell) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	c.XMLName = start.Name
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "tcPr":
+				var props TcPr
+				if err := dec.DecodeElement(&props, &t); err != nil {
+					return err
+				}
+				c.Props = &props
+			case "p":
+				var p Paragraph
+				if err := dec.DecodeElement(&p, &t); err != nil {
+					return err
+				}
+				c.Content = append(c.Content, p)
+			case "tbl":
+				var table Table
+				if err := dec.DecodeElement(&table, &t); err != nil {
+					return err
+				}
+				table.Position = len(c.Content)
+				c.Tables = append(c.Tables, table)
+			case "sdt":
+				var sdt SDT
+				if err := dec.DecodeElement(&sdt, &t); err != nil {
+					return err
+				}
+				sdt.Position = len(c.Content)
+				c.SDTs = append(c.SDTs, sdt)
+			default:
+				if err := dec.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalXML writes the cell with its paragraphs, nested tables and content
+// controls in document order
+func (c TblCell) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	type cellXML struct {
+		XMLName xml.Name      `xml:"tc"`
+		Props   *TcPr         `xml:"tcPr,omitempty"`
+		Content []interface{} // Paragraphs, tables and content controls in document order
+	}
+	return enc.Encode(cellXML{Props: c.Props, Content: blockContent(c.Content, c.Tables, c.SDTs)})
+}
@@ -0,0 +1,105 @@
+package docx
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RenumberCaptions numbers the captions labelled prefix, e.g. "Figure" or
+// "Table", 1, 2, 3 and so on in the order they appear in the body and its
+// tables, as they should be after content was inserted, deleted or moved.
+// A caption is a paragraph holding a SEQ field for the label, as Word's
+// Insert Caption writes, or one whose text starts with the label and a
+// number followed by punctuation ("Figure 3: Sales") or, in the Caption
+// style, by anything. Chapter numbers such as "Figure 2.1" are left alone.
+// It returns the number of captions.
+func (d *Document) RenumberCaptions(prefix string) int {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return 0
+	}
+	pattern := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(prefix) + `\s+(\d+)`)
+
+	count := 0
+	d.forEachParagraph(func(p *Paragraph) {
+		if p.renumberCaption(prefix, pattern, count+1) {
+			count++
+		}
+	})
+	return count
+}
+
+// renumberCaption gives the paragraph number if it is a caption labelled
+// prefix, reporting whether it is one
+func (p *Paragraph) renumberCaption(prefix string, pattern *regexp.Regexp, number int) bool {
+	num := strconv.Itoa(number)
+	for i := range p.Fields {
+		if f := &p.Fields[i]; seqLabel(f.Instr) == strings.ToLower(prefix) {
+			f.setResult(num)
+			return true
+		}
+	}
+
+	var sb strings.Builder
+	p.forEachRun(func(r *Run) {
+		for _, t := range r.Text {
+			sb.WriteString(t.Content)
+		}
+	})
+	text := sb.String()
+	m := pattern.FindStringSubmatchIndex(text)
+	if m == nil || !captionNumberEnds(text[m[3]:], p.isCaptionStyle()) {
+		return false
+	}
+	if text[m[2]:m[3]] != num {
+		// Inserted at the old number, the new one takes its formatting
+		start := utf8.RuneCountInString(text[:m[2]])
+		p.insertText(start, num, nil, true)
+		p.deleteText(start+len(num), start+len(num)+m[3]-m[2])
+	}
+	return true
+}
+
+// captionNumberEnds reports whether the text after the number of a caption
+// ends it: punctuation or the end of the paragraph, or anything but a
+// chapter number in a paragraph of the Caption style
+func captionNumberEnds(rest string, captionStyle bool) bool {
+	r, size := utf8.DecodeRuneInString(rest)
+	if (r == '.' || r == '-' || r == '–') && size < len(rest) && unicode.IsDigit(rune(rest[size])) {
+		return false
+	}
+	if captionStyle {
+		return true
+	}
+	rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+	r, _ = utf8.DecodeRuneInString(rest)
+	return rest == "" || strings.ContainsRune(":.-–—|", r)
+}
+
+// isCaptionStyle reports whether the paragraph has the Caption style
+func (p *Paragraph) isCaptionStyle() bool {
+	return p.Props != nil && p.Props.Style != nil && strings.EqualFold(p.Props.Style.Val, "caption")
+}
+
+// seqLabel returns the lower-cased label a SEQ field numbers, e.g. figure
+// for SEQ Figure \* ARABIC, or "" for other fields
+func seqLabel(instr string) string {
+	fields := strings.Fields(instr)
+	if len(fields) < 2 || !strings.EqualFold(fields[0], "SEQ") {
+		return ""
+	}
+	return strings.ToLower(fields[1])
+}
+
+// setResult replaces the result of a field with text, formatted as the
+// result was
+func (f *SimpleField) setResult(text string) {
+	var props *RProps
+	if len(f.Runs) > 0 {
+		props = f.Runs[0].Props
+	}
+	f.Runs = []Run{{Props: props, Text: []Text{{Content: text}}}}
+}
@@ -0,0 +1,32 @@
+package docx
+
+import "testing"
+
+func TestRenumberCaptions(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Figure 3: Sales", WithBold())
+	doc.AddParagraph("Figure 7 shows the trend") // Text referring to a figure
+	doc.AddParagraph("Figure 2.1: Chapter numbered")
+	doc.AddParagraph("Figure 9 Costs", WithStyle("Caption"))
+	doc.AddParagraph("Figure ", WithStyle("Caption"))
+	doc.Body.Paragraphs[4].Fields = []SimpleField{{Position: 1, Instr: `SEQ Figure \* ARABIC`, Runs: []Run{{Text: []Text{{Content: "5"}}}}}}
+	doc.AddParagraph("Table 4 - Totals")
+	doc.AddParagraph("Figure 12")
+
+	if got := doc.RenumberCaptions("Figure"); got != 4 {
+		t.Errorf("Expected 4 figure captions, got %d", got)
+	}
+	if got := doc.RenumberCaptions("Table"); got != 1 {
+		t.Errorf("Expected 1 table caption, got %d", got)
+	}
+
+	want := []string{"Figure 1: Sales", "Figure 7 shows the trend", "Figure 2.1: Chapter numbered", "Figure 2 Costs", "Figure 3", "Table 1 - Totals", "Figure 4"}
+	for i, w := range want {
+		if text, _ := doc.GetParagraphText(i); text != w {
+			t.Errorf("Paragraph %d: expected %q, got %q", i, w, text)
+		}
+	}
+	if run := doc.Body.Paragraphs[0].Runs[0]; len(doc.Body.Paragraphs[0].Runs) != 1 || run.Props == nil || run.Props.Bold == nil {
+		t.Errorf("Expected the number to keep the caption's formatting, got %+v", doc.Body.Paragraphs[0].Runs)
+	}
+}
@@ -0,0 +1,314 @@
+package docx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	relTypeChart     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/chart"
+	contentTypeChart = "application/vnd.openxmlformats-officedocument.drawingml.chart+xml"
+	chartGraphicURI  = "http://schemas.openxmlformats.org/drawingml/2006/chart"
+)
+
+// chartExternalPattern matches the references of a chart part to its own
+// parts, such as the embedded workbook holding its data
+var chartExternalPattern = regexp.MustCompile(`(?s)<c:(?:externalData|userShapes)\b[^>]*?(?:/>|>.*?</c:(?:externalData|userShapes)>)`)
+
+// ChartRef references the chart part drawn by a graphic frame
+type ChartRef struct {
+	XMLName xml.Name `xml:"http://schemas.openxmlformats.org/drawingml/2006/chart chart"`
+	ID      string   `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+}
+
+// ChartType is the kind of chart drawn by AddChart
+type ChartType string
+
+// Supported chart types
+const (
+	ChartColumn ChartType = "column" // Vertical bars
+	ChartBar    ChartType = "bar"    // Horizontal bars
+	ChartLine   ChartType = "line"
+	ChartPie    ChartType = "pie" // A single series
+)
+
+// ChartSeries is a named set of values, one per category
+type ChartSeries struct {
+	Name   string
+	Values []float64
+}
+
+// ChartSpec describes the chart to draw and its data
+type ChartSpec struct {
+	Type       ChartType
+	Title      string // Shown above the chart, none if empty
+	Categories []string
+	Series     []ChartSeries
+}
+
+// ChartOptions holds configuration for chart insertion
+type ChartOptions struct {
+	Width  int  // Width in pixels
+	Height int  // Height in pixels
+	Legend bool // Show the legend
+}
+
+// ChartOption is a function type for configuring charts
+type ChartOption func(*ChartOptions)
+
+// WithChartWidth sets the chart width in pixels
+func WithChartWidth(width int) ChartOption {
+	return func(opts *ChartOptions) {
+		opts.Width = width
+	}
+}
+
+// WithChartHeight sets the chart height in pixels
+func WithChartHeight(height int) ChartOption {
+	return func(opts *ChartOptions) {
+		opts.Height = height
+	}
+}
+
+// WithoutChartLegend hides the legend
+func WithoutChartLegend() ChartOption {
+	return func(opts *ChartOptions) {
+		opts.Legend = false
+	}
+}
+
+// AddChart adds a chart drawn from spec at the end of the document. The chart
+// is a native Word chart, with its data in the chart part; there is no
+// embedded workbook, so Word shows the chart but cannot edit its data.
+func (d *Document) AddChart(spec ChartSpec, opts ...ChartOption) error {
+	return d.AddChartAt(len(d.Body.Paragraphs), spec, opts...)
+}
+
+// AddChartAt inserts a chart drawn from spec at a specific paragraph index
+func (d *Document) AddChartAt(index int, spec ChartSpec, opts ...ChartOption) error {
+	if index < 0 || index > len(d.Body.Paragraphs) {
+		return fmt.Errorf("index %d %w", index, ErrIndexOutOfRange)
+	}
+	if err := spec.validate(); err != nil {
+		return err
+	}
+
+	options := &ChartOptions{
+		Width:  480,
+		Height: 288,
+		Legend: true,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	partName := d.nextChartPart()
+	d.SetPart(partName, spec.chartXML(options.Legend))
+	d.registerContentTypeOverride(partName, contentTypeChart)
+	relID := d.addRelationship(relTypeChart, strings.TrimPrefix(partName, "word/"))
+
+	p := Paragraph{Runs: []Run{{Drawing: d.chartDrawing(relID, options)}}}
+	d.Body.SpliceParagraphs(index, 0, p)
+	return nil
+}
+
+// nextChartPart returns the name of the first unused chart part
+func (d *Document) nextChartPart() string {
+	for n := 1; ; n++ {
+		name := fmt.Sprintf("word/charts/chart%d.xml", n)
+		if !d.hasPart(name) {
+			return name
+		}
+	}
+}
+
+// validate checks that the spec describes a chart that can be drawn
+func (s ChartSpec) validate() error {
+	switch s.Type {
+	case ChartColumn, ChartBar, ChartLine, ChartPie:
+	default:
+		return fmt.Errorf("unsupported chart type %q", s.Type)
+	}
+	if len(s.Categories) == 0 {
+		return fmt.Errorf("chart needs at least one category")
+	}
+	if len(s.Series) == 0 {
+		return fmt.Errorf("chart needs at least one series")
+	}
+	if s.Type == ChartPie && len(s.Series) > 1 {
+		return fmt.Errorf("pie chart takes a single series, got %d", len(s.Series))
+	}
+	for _, series := range s.Series {
+		if len(series.Values) != len(s.Categories) {
+			return fmt.Errorf("series %q has %d values for %d categories", series.Name, len(series.Values), len(s.Categories))
+		}
+	}
+	return nil
+}
+
+// chartDrawing creates the inline graphic frame showing the chart part relID
+func (d *Document) chartDrawing(relID string, options *ChartOptions) *Drawing {
+	id := d.getNextImageID()
+	return &Drawing{
+		Inline: &Inline{
+			DistT:      "0",
+			DistB:      "0",
+			DistL:      "0",
+			DistR:      "0",
+			Extent:     &Extent{Cx: strconv.Itoa(options.Width * 9525), Cy: strconv.Itoa(options.Height * 9525)},
+			EffectExt:  &EffectExt{L: "0", T: "0", R: "0", B: "0"},
+			DocPr:      &DocPr{ID: strconv.Itoa(id), Name: fmt.Sprintf("Chart %d", id)},
+			CNvGraphic: &CNvGraphic{},
+			Graphic: &Graphic{
+				GraphicData: &GraphicData{
+					URI:   chartGraphicURI,
+					Chart: &ChartRef{ID: relID},
+				},
+			},
+		},
+	}
+}
+
+// chartXML writes the chart part: the plot of the chart type with its axes,
+// and the series values stored as literals
+func (s ChartSpec) chartXML(legend bool) []byte {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<c:chartSpace xmlns:c="http://schemas.openxmlformats.org/drawingml/2006/chart" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	sb.WriteString(`<c:roundedCorners val="0"/><c:chart>`)
+	if s.Title != "" {
+		fmt.Fprintf(&sb, `<c:title><c:tx><c:rich><a:bodyPr/><a:p><a:r><a:t>%s</a:t></a:r></a:p></c:rich></c:tx><c:overlay val="0"/></c:title><c:autoTitleDeleted val="0"/>`, xmlEscapedString(s.Title))
+	} else {
+		sb.WriteString(`<c:autoTitleDeleted val="1"/>`)
+	}
+	sb.WriteString(`<c:plotArea><c:layout/>`)
+
+	const catAxisID, valAxisID = "500000001", "500000002"
+	axisIDs := `<c:axId val="` + catAxisID + `"/><c:axId val="` + valAxisID + `"/>`
+	switch s.Type {
+	case ChartColumn, ChartBar:
+		dir := "col"
+		if s.Type == ChartBar {
+			dir = "bar"
+		}
+		fmt.Fprintf(&sb, `<c:barChart><c:barDir val="%s"/><c:grouping val="clustered"/><c:varyColors val="0"/>`, dir)
+		s.writeSeries(&sb, `<c:invertIfNegative val="0"/>`, "")
+		sb.WriteString(`<c:gapWidth val="150"/>` + axisIDs + `</c:barChart>`)
+	case ChartLine:
+		sb.WriteString(`<c:lineChart><c:grouping val="standard"/><c:varyColors val="0"/>`)
+		s.writeSeries(&sb, "", `<c:smooth val="0"/>`)
+		sb.WriteString(`<c:marker val="1"/>` + axisIDs + `</c:lineChart>`)
+	case ChartPie:
+		sb.WriteString(`<c:pieChart><c:varyColors val="1"/>`)
+		s.writeSeries(&sb, "", "")
+		sb.WriteString(`<c:firstSliceAng val="0"/></c:pieChart>`)
+	}
+
+	if s.Type != ChartPie {
+		catPos, valPos := "b", "l"
+		if s.Type == ChartBar {
+			catPos, valPos = "l", "b"
+		}
+		fmt.Fprintf(&sb, `<c:catAx><c:axId val="%s"/><c:scaling><c:orientation val="minMax"/></c:scaling><c:delete val="0"/><c:axPos val="%s"/><c:numFmt formatCode="General" sourceLinked="0"/><c:tickLblPos val="nextTo"/><c:crossAx val="%s"/><c:crosses val="autoZero"/><c:auto val="1"/><c:lblAlgn val="ctr"/><c:lblOffset val="100"/><c:noMultiLvlLbl val="0"/></c:catAx>`, catAxisID, catPos, valAxisID)
+		fmt.Fprintf(&sb, `<c:valAx><c:axId val="%s"/><c:scaling><c:orientation val="minMax"/></c:scaling><c:delete val="0"/><c:axPos val="%s"/><c:majorGridlines/><c:numFmt formatCode="General" sourceLinked="0"/><c:tickLblPos val="nextTo"/><c:crossAx val="%s"/><c:crosses val="autoZero"/><c:crossBetween val="between"/></c:valAx>`, valAxisID, valPos, catAxisID)
+	}
+	sb.WriteString(`</c:plotArea>`)
+
+	if legend {
+		sb.WriteString(`<c:legend><c:legendPos val="r"/><c:overlay val="0"/></c:legend>`)
+	}
+	sb.WriteString(`<c:plotVisOnly val="1"/><c:dispBlanksAs val="gap"/></c:chart></c:chartSpace>`)
+	return []byte(sb.String())
+}
+
+// writeSeries writes the series of the chart, with the elements their chart
+// type expects before the categories and after the values
+func (s ChartSpec) writeSeries(sb *strings.Builder, beforeCat, afterVal string) {
+	for i, series := range s.Series {
+		fmt.Fprintf(sb, `<c:ser><c:idx val="%d"/><c:order val="%d"/><c:tx><c:v>%s</c:v></c:tx>`, i, i, xmlEscapedString(series.Name))
+		sb.WriteString(beforeCat)
+
+		fmt.Fprintf(sb, `<c:cat><c:strLit><c:ptCount val="%d"/>`, len(s.Categories))
+		for j, category := range s.Categories {
+			fmt.Fprintf(sb, `<c:pt idx="%d"><c:v>%s</c:v></c:pt>`, j, xmlEscapedString(category))
+		}
+		sb.WriteString(`</c:strLit></c:cat>`)
+
+		fmt.Fprintf(sb, `<c:val><c:numLit><c:formatCode>General</c:formatCode><c:ptCount val="%d"/>`, len(series.Values))
+		for j, value := range series.Values {
+			fmt.Fprintf(sb, `<c:pt idx="%d"><c:v>%s</c:v></c:pt>`, j, strconv.FormatFloat(value, 'f', -1, 64))
+		}
+		sb.WriteString(`</c:numLit></c:val>`)
+
+		sb.WriteString(afterVal)
+		sb.WriteString(`</c:ser>`)
+	}
+}
+
+// drawingChart returns the chart reference of a drawing, or nil if it holds no chart
+func drawingChart(drawing *Drawing) *ChartRef {
+	if drawing == nil || drawing.Inline == nil || drawing.Inline.Graphic == nil {
+		return nil
+	}
+	data := drawing.Inline.Graphic.GraphicData
+	if data == nil {
+		return nil
+	}
+	return data.Chart
+}
+
+// importCharts copies the chart parts drawn in src. Charts keep the data
+// cached in their part; their embedded workbooks are not copied.
+func (d *Document) importCharts(src *Document, maps *importMaps) {
+	_ = src.WalkParagraphs(DefaultMaxDepth, func(p *Paragraph, depth int) error {
+		p.forEachRun(func(r *Run) {
+			chart := drawingChart(r.Drawing)
+			if chart == nil || chart.ID == "" {
+				return
+			}
+			if _, done := maps.rels[chart.ID]; done {
+				return
+			}
+			rel, ok := src.findRelationship(chart.ID)
+			if !ok {
+				return
+			}
+			data, ok := src.GetPart(resolvePartName(rel.Target))
+			if !ok {
+				return
+			}
+
+			partName := d.nextChartPart()
+			d.SetPart(partName, chartExternalPattern.ReplaceAll(data, nil))
+			d.registerContentTypeOverride(partName, contentTypeChart)
+			maps.rels[chart.ID] = d.addRelationship(relTypeChart, strings.TrimPrefix(partName, "word/"))
+		})
+		return nil
+	})
+}
+
+// importChartDrawing copies a chart drawing, pointing it at the imported
+// chart part and giving it a fresh drawing ID
+func (d *Document) importChartDrawing(drawing *Drawing, maps *importMaps) *Drawing {
+	inline := *drawing.Inline
+	graphic := *inline.Graphic
+	graphicData := *graphic.GraphicData
+	chart := *graphicData.Chart
+
+	if newRelID, ok := maps.rels[chart.ID]; ok {
+		chart.ID = newRelID
+	}
+	if inline.DocPr != nil {
+		docPr := *inline.DocPr
+		docPr.ID = strconv.Itoa(d.getNextImageID())
+		inline.DocPr = &docPr
+	}
+
+	graphicData.Chart = &chart
+	graphic.GraphicData = &graphicData
+	inline.Graphic = &graphic
+	return &Drawing{Inline: &inline}
+}
@@ -0,0 +1,133 @@
+package docx
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testChartSpec(chartType ChartType) ChartSpec {
+	return ChartSpec{
+		Type:       chartType,
+		Title:      "Sales & Costs",
+		Categories: []string{"Q1", "Q2", "Q3"},
+		Series: []ChartSeries{
+			{Name: "Sales", Values: []float64{10, 12.5, 9}},
+		},
+	}
+}
+
+func TestAddChart(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Results")
+	if err := doc.AddChart(testChartSpec(ChartColumn)); err != nil {
+		t.Fatalf("AddChart failed: %v", err)
+	}
+	if err := doc.AddChartAt(0, testChartSpec(ChartPie), WithChartWidth(200), WithoutChartLegend()); err != nil {
+		t.Fatalf("AddChartAt failed: %v", err)
+	}
+
+	tmpFile := filepath.Join(t.TempDir(), "chart.docx")
+	if err := doc.Save(tmpFile); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := Open(tmpFile)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	pie := drawingChart(loaded.Body.Paragraphs[0].Runs[0].Drawing)
+	column := drawingChart(loaded.Body.Paragraphs[2].Runs[0].Drawing)
+	if pie == nil || column == nil {
+		t.Fatalf("Expected chart drawings at paragraphs 0 and 2")
+	}
+	if got := loaded.Body.Paragraphs[0].Runs[0].Drawing.Inline.Extent.Cx; got != "1905000" {
+		t.Errorf("Expected a 200px wide chart, got cx %s", got)
+	}
+
+	rel, ok := loaded.findRelationship(column.ID)
+	if !ok || rel.Type != relTypeChart {
+		t.Fatalf("Chart relationship %s not found: %+v", column.ID, rel)
+	}
+	data, ok := loaded.GetPart(resolvePartName(rel.Target))
+	if !ok {
+		t.Fatalf("Chart part %s not found", rel.Target)
+	}
+	chart := string(data)
+	for _, want := range []string{`<c:barDir val="col"/>`, `<a:t>Sales &amp; Costs</a:t>`, `<c:v>Q2</c:v>`, `<c:v>12.5</c:v>`, `<c:legend>`} {
+		if !strings.Contains(chart, want) {
+			t.Errorf("Expected chart part to contain %s", want)
+		}
+	}
+
+	rel, _ = loaded.findRelationship(pie.ID)
+	data, _ = loaded.GetPart(resolvePartName(rel.Target))
+	if !strings.Contains(string(data), "<c:pieChart>") || strings.Contains(string(data), "<c:legend>") {
+		t.Errorf("Expected a pie chart without legend, got %s", data)
+	}
+
+	types, _ := loaded.GetPart("[Content_Types].xml")
+	if !strings.Contains(string(types), `PartName="/word/charts/chart2.xml"`) {
+		t.Errorf("Expected content type override for the second chart")
+	}
+}
+
+func TestAddChartValidation(t *testing.T) {
+	doc := New()
+
+	tests := []struct {
+		name string
+		spec ChartSpec
+	}{
+		{"unknown type", ChartSpec{Type: "radar", Categories: []string{"A"}, Series: []ChartSeries{{Values: []float64{1}}}}},
+		{"no categories", ChartSpec{Type: ChartLine, Series: []ChartSeries{{Values: []float64{1}}}}},
+		{"no series", ChartSpec{Type: ChartLine, Categories: []string{"A"}}},
+		{"value count", ChartSpec{Type: ChartBar, Categories: []string{"A", "B"}, Series: []ChartSeries{{Values: []float64{1}}}}},
+		{"pie series", ChartSpec{Type: ChartPie, Categories: []string{"A"}, Series: []ChartSeries{{Values: []float64{1}}, {Values: []float64{2}}}}},
+	}
+	for _, tt := range tests {
+		if err := doc.AddChart(tt.spec); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+	if err := doc.AddChartAt(5, testChartSpec(ChartLine)); err == nil {
+		t.Errorf("Expected an error for an out of range index")
+	}
+	if len(doc.Body.Paragraphs) != 0 {
+		t.Errorf("Expected no paragraphs after failed inserts, got %d", len(doc.Body.Paragraphs))
+	}
+}
+
+func TestAppendDocumentCharts(t *testing.T) {
+	src := New()
+	if err := src.AddChart(testChartSpec(ChartLine)); err != nil {
+		t.Fatalf("AddChart failed: %v", err)
+	}
+	// A chart saved by Word refers to its embedded workbook
+	part, _ := src.GetPart("word/charts/chart1.xml")
+	src.SetPart("word/charts/chart1.xml", []byte(strings.Replace(string(part), "</c:chartSpace>", `<c:externalData r:id="rId1"><c:autoUpdate val="0"/></c:externalData></c:chartSpace>`, 1)))
+
+	dst := New()
+	if err := dst.AddChart(testChartSpec(ChartBar)); err != nil {
+		t.Fatalf("AddChart failed: %v", err)
+	}
+	if err := dst.AppendDocument(src); err != nil {
+		t.Fatalf("AppendDocument failed: %v", err)
+	}
+
+	chart := drawingChart(dst.Body.Paragraphs[1].Runs[0].Drawing)
+	if chart == nil {
+		t.Fatalf("Expected the appended chart drawing")
+	}
+	rel, ok := dst.findRelationship(chart.ID)
+	if !ok || rel.Target != "charts/chart2.xml" {
+		t.Fatalf("Expected the appended chart to use charts/chart2.xml, got %+v", rel)
+	}
+	data, _ := dst.GetPart("word/charts/chart2.xml")
+	if !strings.Contains(string(data), "<c:lineChart>") || strings.Contains(string(data), "externalData") {
+		t.Errorf("Expected the line chart without its workbook reference, got %s", data)
+	}
+	if got := drawingChart(src.Body.Paragraphs[0].Runs[0].Drawing).ID; got != "rId1" {
+		t.Errorf("Source drawing was modified, chart is %s", got)
+	}
+}
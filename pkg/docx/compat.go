@@ -0,0 +1,194 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Support is how far docxsmith handles a feature of a document
+type Support string
+
+// Levels of support
+const (
+	SupportEditable  Support = "editable"  // Read and changed by docxsmith
+	SupportPreserved Support = "preserved" // Kept as it is when saving, but not changed
+	SupportLost      Support = "lost"      // Dropped, at least in part, when docxsmith saves the document
+)
+
+// FeatureUsage is a feature a document uses and how docxsmith handles it
+type FeatureUsage struct {
+	Feature string // e.g. "Tracked changes"
+	Count   int    // Occurrences in the document
+	Lost    int    // Occurrences dropped when the document is saved
+	Support Support
+	Note    string
+}
+
+// CompatibilityReport lists the features a document uses
+type CompatibilityReport struct {
+	Features []FeatureUsage
+	Safe     bool // Nothing is lost when the document is saved
+}
+
+// bodyFeature is a feature found in the body by the elements marking it,
+// by local name
+type bodyFeature struct {
+	name     string
+	elements []string
+	support  Support
+	note     string
+}
+
+// bodyFeatures are the features Compatibility looks for in the body
+var bodyFeatures = []bodyFeature{
+	{"Tables", []string{"tbl"}, SupportEditable, ""},
+	{"Images", []string{"pic", "imagedata"}, SupportEditable, ""},
+	{"Hyperlinks", []string{"hyperlink"}, SupportEditable, ""},
+	{"Bookmarks", []string{"bookmarkStart"}, SupportEditable, ""},
+	{"Fields", []string{"fldSimple", "instrText"}, SupportEditable, "REF and SEQ results are updated, others kept"},
+	{"Content controls", []string{"sdt"}, SupportEditable, ""},
+	{"Text boxes", []string{"txbxContent"}, SupportEditable, ""},
+	{"Tracked changes", []string{"ins", "del", "moveFrom", "moveTo", "rPrChange", "pPrChange", "sectPrChange", "tblPrChange", "trPrChange", "tcPrChange"},
+		SupportPreserved, "Edits are not tracked"},
+	{"Comments", []string{"commentReference"}, SupportPreserved, ""},
+	{"Footnotes and endnotes", []string{"footnoteReference", "endnoteReference"}, SupportPreserved, ""},
+	{"Charts", []string{"chart"}, SupportPreserved, "Chart data is not edited"},
+	{"SmartArt", []string{"relIds"}, SupportPreserved, "Text is read but not changed"},
+	{"Equations", []string{"oMath"}, SupportPreserved, ""},
+	{"Embedded objects", []string{"object"}, SupportPreserved, ""},
+	{"Imported content (altChunk)", []string{"altChunk"}, SupportPreserved, ""},
+}
+
+// ignoredElements are dropped when saving without changing what the document shows
+var ignoredElements = map[string]bool{"proofErr": true, "lastRenderedPageBreak": true}
+
+var commentPattern = regexp.MustCompile(`<w:comment\b`)
+
+// Compatibility reports the features the document uses, as it was opened
+// or, once saved, as the body is, and which of them docxsmith edits, keeps as they are or
+// drops when saving. Whether markup in the body survives is found by
+// writing the body back and comparing the two. Other markup that would be
+// dropped, such as character styles, is listed as "Other markup".
+func (d *Document) Compatibility() (*CompatibilityReport, error) {
+	// Markup inside a feature is lost with it, so it is left out of the
+	// other markup
+	features := make(map[string]bool)
+	for _, feature := range bodyFeatures {
+		for _, name := range feature.elements {
+			features[name] = true
+		}
+	}
+	written, err := d.marshalDocument()
+	if err != nil {
+		return nil, err
+	}
+	// Saving drops the part as read, which is then the body as it is
+	stored := d.files[documentPart]
+	if len(stored) == 0 {
+		stored = written
+	}
+	var before, after, otherBefore, otherAfter map[string]int
+	for _, count := range []struct {
+		counts *map[string]int
+		data   []byte
+		skip   map[string]bool
+	}{
+		{&before, stored, nil},
+		{&after, written, nil},
+		{&otherBefore, stored, features},
+		{&otherAfter, written, features},
+	} {
+		if *count.counts, err = elementCounts(count.data, count.skip); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &CompatibilityReport{Safe: true}
+	add := func(f FeatureUsage) {
+		if f.Count == 0 {
+			return
+		}
+		if f.Lost > 0 {
+			f.Support = SupportLost
+			report.Safe = false
+		}
+		report.Features = append(report.Features, f)
+	}
+
+	for _, feature := range bodyFeatures {
+		usage := FeatureUsage{Feature: feature.name, Support: feature.support, Note: feature.note}
+		for _, name := range feature.elements {
+			usage.Count += before[name]
+			usage.Lost += max(before[name]-after[name], 0)
+		}
+		add(usage)
+	}
+
+	headers := 0
+	comments := 0
+	for name, data := range d.files {
+		switch {
+		case strings.HasPrefix(name, "word/header") || strings.HasPrefix(name, "word/footer"):
+			headers++
+		case name == "word/comments.xml":
+			comments = len(commentPattern.FindAll(data, -1))
+		}
+	}
+	add(FeatureUsage{Feature: "Headers and footers", Count: headers, Support: SupportEditable})
+	if comments > 0 && before["commentReference"] == 0 {
+		add(FeatureUsage{Feature: "Comments", Count: comments, Support: SupportPreserved})
+	}
+	if d.HasMacros() {
+		add(FeatureUsage{Feature: "Macros", Count: 1, Support: SupportPreserved, Note: "Kept in .docm and .dotm files only"})
+	}
+
+	var dropped []string
+	other := FeatureUsage{Feature: "Other markup"}
+	for name, n := range otherBefore {
+		if lost := n - otherAfter[name]; lost > 0 && !features[name] && !ignoredElements[name] {
+			other.Count += n
+			other.Lost += lost
+			dropped = append(dropped, name)
+		}
+	}
+	sort.Strings(dropped)
+	other.Note = strings.Join(dropped, ", ")
+	add(other)
+	return report, nil
+}
+
+// elementCounts counts the elements of XML markup by local name, leaving
+// out those inside the elements named in skip
+func elementCounts(data []byte, skip map[string]bool) (map[string]int, error) {
+	counts := make(map[string]int)
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	skipping := 0 // Depth inside a skipped element
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			return counts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skipping > 0 {
+				skipping++
+				continue
+			}
+			counts[t.Name.Local]++
+			if skip[t.Name.Local] {
+				skipping = 1
+			}
+		case xml.EndElement:
+			if skipping > 0 {
+				skipping--
+			}
+		}
+	}
+}
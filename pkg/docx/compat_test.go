@@ -0,0 +1,50 @@
+package docx
+
+import "testing"
+
+func TestCompatibility(t *testing.T) {
+	doc := New()
+	report, err := doc.Compatibility()
+	if err != nil {
+		t.Fatalf("Compatibility failed: %v", err)
+	}
+	if !report.Safe {
+		t.Errorf("Expected a new document to be safe, got %+v", report.Features)
+	}
+
+	data := []byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:m="http://schemas.openxmlformats.org/officeDocument/2006/math"><w:body>
+<w:p><w:r><w:t>Kept</w:t></w:r><w:ins w:id="1" w:author="Ann"><w:r><w:t>inserted</w:t></w:r></w:ins></w:p>
+<w:p><w:r><w:rPr><w:rStyle w:val="Strong"/></w:rPr><w:t>styled</w:t></w:r><w:proofErr w:type="spellStart"/></w:p>
+<w:p><w:hyperlink w:anchor="top"><w:r><w:t>link</w:t></w:r></w:hyperlink><m:oMath><m:r><m:t>x</m:t></m:r></m:oMath></w:p>
+<w:tbl><w:tr><w:tc><w:p/></w:tc></w:tr></w:tbl>
+</w:body></w:document>`)
+	doc.SetPart("word/document.xml", data)
+	if err := doc.parseDocument(data); err != nil {
+		t.Fatalf("parseDocument failed: %v", err)
+	}
+
+	if report, err = doc.Compatibility(); err != nil {
+		t.Fatalf("Compatibility failed: %v", err)
+	}
+	if report.Safe {
+		t.Error("Expected tracked changes to make the document unsafe to edit")
+	}
+	features := make(map[string]FeatureUsage)
+	for _, f := range report.Features {
+		features[f.Feature] = f
+	}
+	if f := features["Tracked changes"]; f.Count != 1 || f.Lost != 1 || f.Support != SupportLost {
+		t.Errorf("Expected a lost tracked change, got %+v", f)
+	}
+	if f := features["Other markup"]; f.Support != SupportLost || f.Note != "rStyle" {
+		t.Errorf("Expected the character style reported as lost, got %+v", f)
+	}
+	for name, support := range map[string]Support{"Tables": SupportEditable, "Hyperlinks": SupportEditable, "Equations": SupportPreserved} {
+		if f := features[name]; f.Count != 1 || f.Support != support {
+			t.Errorf("Expected %s to be %s, got %+v", name, support, f)
+		}
+	}
+	if _, ok := features["Images"]; ok {
+		t.Error("Expected only features the document uses")
+	}
+}
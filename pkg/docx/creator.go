@@ -2,6 +2,10 @@ package docx
 
 import (
 	"archive/zip"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
 )
 
 // New creates a new empty document
@@ -48,33 +52,201 @@ func CreateFromTemplate(templatePath string) (*Document, error) {
 	return Open(templatePath)
 }
 
-// Clone creates a deep copy of the document
+// Clone creates a deep copy of the document: its body down to the text and
+// properties of every run, the package parts, headers and footers, and the
+// ID counters. Changes to the copy never affect the original.
 func (d *Document) Clone() *Document {
 	newDoc := &Document{
-		FilePath: d.FilePath,
-		Body: &Body{
-			Paragraphs: make([]Paragraph, len(d.Body.Paragraphs)),
-			Tables:     make([]Table, len(d.Body.Tables)),
-		},
-		files:              make(map[string][]byte),
+		FilePath:           d.FilePath,
+		Body:               deepCopy(d.Body),
+		Styles:             deepCopy(d.Styles),
+		ContentTypes:       deepCopy(d.ContentTypes),
+		Rels:               deepCopy(d.Rels),
+		files:              make(map[string][]byte, len(d.files)),
+		source:             d.source,             // Large media parts are shared, read from the same file
+		limits:             d.limits,             // and within the same limits
 		nextImageID:        d.nextImageID,        // Copy the image ID counter
 		nextRelationshipID: d.nextRelationshipID, // Copy the relationship ID counter
 	}
 
-	// Copy paragraphs
-	copy(newDoc.Body.Paragraphs, d.Body.Paragraphs)
-
-	// Copy tables
-	copy(newDoc.Body.Tables, d.Body.Tables)
-
 	// Copy files
 	for k, v := range d.files {
 		newDoc.files[k] = append([]byte(nil), v...)
 	}
+	if len(d.lazy) > 0 {
+		newDoc.lazy = make(map[string]*zip.File, len(d.lazy))
+		for k, f := range d.lazy {
+			newDoc.lazy[k] = f
+		}
+	}
+
+	// Copy headers and footers set through the header/footer manager
+	if hfs, ok := d.headerFooterMgr.(*HeaderFooterService); ok {
+		newDoc.headerFooterMgr = &HeaderFooterService{
+			document: newDoc,
+			headers:  deepCopy(hfs.headers),
+			footers:  deepCopy(hfs.footers),
+		}
+	}
 
 	return newDoc
 }
 
+// deepCopy copies a value along with everything it points to. Document
+// elements are trees of exported fields, slices and pointers, so nothing is
+// shared between the copy and the original.
+func deepCopy[T any](v T) T {
+	src := reflect.ValueOf(&v).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	copyValue(dst, src)
+	return dst.Interface().(T)
+}
+
+// copyValue deep copies src into dst, which have the same type
+func copyValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.New(src.Type().Elem()))
+		copyValue(dst.Elem(), src.Elem())
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			copyValue(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		iter := src.MapRange()
+		for iter.Next() {
+			value := reflect.New(src.Type().Elem()).Elem()
+			copyValue(value, iter.Value())
+			dst.SetMapIndex(iter.Key(), value)
+		}
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				copyValue(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		value := reflect.New(src.Elem().Type()).Elem()
+		copyValue(value, src.Elem())
+		dst.Set(value)
+	default:
+		dst.Set(src)
+	}
+}
+
+// ExtractRange returns a copy of the document holding only the paragraphs from
+// start to end (inclusive), along with the tables and content controls between
+// them. The copy keeps the whole package, so styles, numbering, headers and
+// footers carry over; images and hyperlinks only used outside the range are
+// dropped. The part takes the page setup of the section its last paragraph
+// belongs to.
+func (d *Document) ExtractRange(start, end int) (*Document, error) {
+	if start < 0 || end >= len(d.Body.Paragraphs) || start > end {
+		return nil, fmt.Errorf("invalid range [%d:%d], document has %d paragraphs", start, end, len(d.Body.Paragraphs))
+	}
+
+	part := d.Clone()
+	part.Body.Paragraphs = append([]Paragraph(nil), d.Body.Paragraphs[start:end+1]...)
+
+	// A block at position start sits before the first paragraph, so it belongs
+	// to the previous range unless this one starts the document
+	inRange := func(pos int) bool {
+		return (pos > start || start == 0) && pos <= end+1
+	}
+	part.Body.Tables = nil
+	for _, t := range d.Body.Tables {
+		if inRange(t.Position) {
+			t.Position -= start
+			part.Body.Tables = append(part.Body.Tables, t)
+		}
+	}
+	part.Body.SDTs = nil
+	for _, sdt := range d.Body.SDTs {
+		if inRange(sdt.Position) {
+			sdt.Position -= start
+			part.Body.SDTs = append(part.Body.SDTs, sdt)
+		}
+	}
+
+	// The part's last section is described by the body instead
+	part.Body.SectPr = d.SectionAt(end)
+	if last := &part.Body.Paragraphs[len(part.Body.Paragraphs)-1]; last.Props != nil && last.Props.SectPr != nil {
+		props := *last.Props
+		props.SectPr = nil
+		last.Props = &props
+	}
+
+	if err := part.pruneRelationships(); err != nil {
+		return nil, err
+	}
+	return part, nil
+}
+
+// ExtractTable returns a copy of the document holding only the table at
+// index, like ExtractRange does for paragraphs
+func (d *Document) ExtractTable(index int) (*Document, error) {
+	if index < 0 || index >= len(d.Body.Tables) {
+		return nil, fmt.Errorf("table index %d %w", index, ErrIndexOutOfRange)
+	}
+
+	part := d.Clone()
+	table := part.Body.Tables[index]
+	table.Position = 0
+	part.Body.Paragraphs = nil
+	part.Body.Tables = []Table{table}
+	part.Body.SDTs = nil
+	part.Body.SectPr = d.SectionAt(d.Body.Tables[index].Position)
+
+	if err := part.pruneRelationships(); err != nil {
+		return nil, err
+	}
+	return part, nil
+}
+
+// pruneRelationships removes image and hyperlink relationships the body no
+// longer references, along with media parts nothing else points to
+func (d *Document) pruneRelationships() error {
+	documentXML, err := d.marshalDocument()
+	if err != nil {
+		return fmt.Errorf("failed to marshal document: %w", err)
+	}
+
+	rels, err := d.GetRelationships()
+	if err != nil {
+		return err
+	}
+
+	content := string(documentXML)
+	for _, rel := range rels {
+		if rel.Type != relTypeImage && rel.Type != relTypeHyperlink {
+			continue
+		}
+		if strings.Contains(content, `"`+rel.ID+`"`) {
+			continue
+		}
+
+		d.removeRelationship(rel.ID)
+		if rel.Type == relTypeImage && !d.isPartReferenced(rel.Target) {
+			d.DeletePart(path.Join("word", rel.Target))
+		}
+	}
+	return nil
+}
+
 // CreateMinimalDocx creates a minimal valid .docx file for testing
 func CreateMinimalDocx(outputPath string) error {
 	doc := New()
@@ -102,6 +274,11 @@ func (d *Document) WriteToZip(w *zip.Writer) error {
 			return err
 		}
 	}
+	for _, f := range d.lazy {
+		if err := w.Copy(f); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
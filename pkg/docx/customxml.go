@@ -0,0 +1,359 @@
+package docx
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	relTypeCustomXML      = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/customXml"
+	relTypeCustomXMLProps = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/customXmlProps"
+
+	contentTypeCustomXMLProps = "application/vnd.openxmlformats-officedocument.customXmlProperties+xml"
+)
+
+var (
+	itemIDPattern        = regexp.MustCompile(`\bds:itemID="([^"]*)"`)
+	sdtTagPattern        = regexp.MustCompile(`<w:tag\s+w:val="([^"]*)"\s*/>`)
+	showingPlcHdrPattern = regexp.MustCompile(`<w:showingPlcHdr\s*/>`)
+
+	// sdtPrAfterBinding matches the first sdtPr child that comes after
+	// w:dataBinding in the schema: the remaining settings and the control type
+	sdtPrAfterBinding = regexp.MustCompile(`<(?:w:(?:label|tabIndex|sdtLocked|text|richText|date|comboBox|dropDownList|picture|docPartObj|docPartList|group|citation|bibliography|equation)|w14:checkbox)\b`)
+)
+
+// SetCustomXMLPart adds a custom XML data part, or replaces the data of the
+// part with the same name. The name is either the store item ID Word gave
+// an existing part, such as "{5E4B5B4F-...}", or any name, from which a
+// stable store item ID is derived. Content controls bound to the part with
+// BindContentControl show its values, and Word writes edits back to it.
+func (d *Document) SetCustomXMLPart(name string, data []byte) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("custom XML part name is required")
+	}
+	if _, err := parseCustomXML(data); err != nil {
+		return fmt.Errorf("invalid custom XML for %q: %w", name, err)
+	}
+
+	storeItemID := customXMLStoreItemID(name)
+	if part, ok := d.customXMLItems()[storeItemID]; ok {
+		d.files[part] = data
+		return nil
+	}
+
+	n := 1
+	for {
+		if _, exists := d.files[fmt.Sprintf("customXml/item%d.xml", n)]; !exists {
+			break
+		}
+		n++
+	}
+	part := fmt.Sprintf("customXml/item%d.xml", n)
+	propsPart := fmt.Sprintf("customXml/itemProps%d.xml", n)
+
+	d.SetPart(part, data)
+	d.SetPart(propsPart, []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<ds:datastoreItem ds:itemID="%s" xmlns:ds="http://schemas.openxmlformats.org/officeDocument/2006/customXml"><ds:schemaRefs/></ds:datastoreItem>`, storeItemID)))
+	d.SetPart(fmt.Sprintf("customXml/_rels/item%d.xml.rels", n), []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+	<Relationship Id="rId1" Type="%s" Target="itemProps%d.xml"/>
+</Relationships>`, relTypeCustomXMLProps, n)))
+	d.registerContentTypeOverride(propsPart, contentTypeCustomXMLProps)
+	d.addRelationship(relTypeCustomXML, "../"+part)
+	return nil
+}
+
+// GetCustomXMLPart returns the data of the custom XML part with the given
+// name, as passed to SetCustomXMLPart
+func (d *Document) GetCustomXMLPart(name string) ([]byte, bool) {
+	part, ok := d.customXMLItems()[customXMLStoreItemID(name)]
+	if !ok {
+		return nil, false
+	}
+	return d.GetPart(part)
+}
+
+// CustomXMLPartIDs returns the store item IDs of the custom XML parts, sorted
+func (d *Document) CustomXMLPartIDs() []string {
+	var ids []string
+	for id := range d.customXMLItems() {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// BindContentControl binds the content controls tagged tag to the node at
+// xpath in the named custom XML part, and fills them with the node's current
+// value. Paths are absolute, with steps such as "invoice", "item[2]" or a
+// final "@currency"; elements in the part's default namespace take the ns0
+// prefix, as in Word. It returns the number of controls bound.
+func (d *Document) BindContentControl(tag, name, xpath string) (int, error) {
+	storeItemID := customXMLStoreItemID(name)
+	part, ok := d.customXMLItems()[storeItemID]
+	if !ok {
+		return 0, fmt.Errorf("custom XML part %q not found", name)
+	}
+
+	root, err := parseCustomXML(d.files[part])
+	if err != nil {
+		return 0, fmt.Errorf("invalid custom XML in %s: %w", part, err)
+	}
+	value, ok := root.find(xpath)
+	if !ok {
+		return 0, fmt.Errorf("no node at %s in custom XML part %q", xpath, name)
+	}
+
+	var attrs bytes.Buffer
+	attrs.WriteString(`<w:dataBinding w:prefixMappings="`)
+	xml.EscapeText(&attrs, []byte(root.prefixMappings()))
+	attrs.WriteString(`" w:xpath="`)
+	xml.EscapeText(&attrs, []byte(xpath))
+	fmt.Fprintf(&attrs, `" w:storeItemID="%s"/>`, storeItemID)
+	binding := attrs.Bytes()
+
+	bound := 0
+	w := newWalker(DefaultMaxDepth)
+	w.sdt = func(s *SDT, depth int) error {
+		if s.Props == nil {
+			return nil
+		}
+		m := sdtTagPattern.FindSubmatch(s.Props.Inner)
+		if m == nil || string(m[1]) != xmlEscapedString(tag) {
+			return nil
+		}
+		// The formatting of placeholder text is not meant for real values
+		keepFormat := !showingPlcHdrPattern.Match(s.Props.Inner)
+		s.Props.Inner = setDataBinding(s.Props.Inner, binding)
+		setSDTText(s, value, keepFormat)
+		bound++
+		return nil
+	}
+	if err := w.walkBody(d.Body); err != nil {
+		return bound, err
+	}
+	if bound == 0 {
+		return 0, fmt.Errorf("no content control tagged %q", tag)
+	}
+	return bound, nil
+}
+
+// customXMLItems maps the store item IDs of the custom XML parts to the
+// part names, e.g. customXml/item1.xml
+func (d *Document) customXMLItems() map[string]string {
+	items := make(map[string]string)
+	for name, data := range d.files {
+		if !strings.HasPrefix(name, "customXml/_rels/") || !strings.HasSuffix(name, ".xml.rels") {
+			continue
+		}
+		var rels Relationships
+		if err := xml.Unmarshal(data, &rels); err != nil {
+			continue
+		}
+		for _, rel := range rels.Relationships {
+			if rel.Type != relTypeCustomXMLProps {
+				continue
+			}
+			propsPart := path.Join("customXml", rel.Target)
+			m := itemIDPattern.FindSubmatch(d.files[propsPart])
+			if m == nil {
+				continue
+			}
+			part := path.Join("customXml", strings.TrimSuffix(path.Base(name), ".rels"))
+			items[strings.ToUpper(string(m[1]))] = part
+		}
+	}
+	return items
+}
+
+// customXMLStoreItemID returns name itself if it is a store item ID, and
+// otherwise an ID derived from it, so the same name always finds the same part
+func customXMLStoreItemID(name string) string {
+	if len(name) == 38 && name[0] == '{' && name[37] == '}' {
+		return strings.ToUpper(name)
+	}
+	sum := sha1.Sum([]byte(name))
+	return fmt.Sprintf("{%X-%X-%X-%X-%X}", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// setDataBinding replaces the data binding of content control properties,
+// or inserts it where the schema expects it, and drops the placeholder flag
+func setDataBinding(props, binding []byte) []byte {
+	props = dataBindingPattern.ReplaceAll(props, nil)
+	props = showingPlcHdrPattern.ReplaceAll(props, nil)
+	at := len(props)
+	if loc := sdtPrAfterBinding.FindIndex(props); loc != nil {
+		at = loc[0]
+	}
+	result := make([]byte, 0, len(props)+len(binding))
+	result = append(result, props[:at]...)
+	result = append(result, binding...)
+	return append(result, props[at:]...)
+}
+
+// setSDTText replaces the content of a control with a paragraph of text,
+// keeping the formatting of its first paragraph and, if keepFormat is set,
+// of its first run
+func setSDTText(s *SDT, text string, keepFormat bool) {
+	p := Paragraph{}
+	run := Run{}
+	if s.Content != nil && len(s.Content.Paragraphs) > 0 {
+		first := s.Content.Paragraphs[0]
+		p.Props = first.Props
+		if keepFormat && len(first.Runs) > 0 {
+			run.Props = first.Runs[0].Props
+		}
+	}
+	run.Text = []Text{{Space: "preserve", Content: text}}
+	p.Runs = []Run{run}
+
+	if s.Content == nil {
+		s.Content = &SDTContent{}
+	}
+	s.Content.Paragraphs = []Paragraph{p}
+}
+
+// xmlEscapedString escapes s as it appears in an attribute value
+func xmlEscapedString(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+// customXMLNode is an element of a custom XML part
+type customXMLNode struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*customXMLNode
+	text     strings.Builder // Text of the element and its descendants
+}
+
+// parseCustomXML parses a custom XML part into its root element
+func parseCustomXML(data []byte) (*customXMLNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var root *customXMLNode
+	var stack []*customXMLNode
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &customXMLNode{name: t.Name, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			} else {
+				return nil, fmt.Errorf("more than one root element")
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("unexpected end element %s", t.Name.Local)
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			for _, node := range stack {
+				node.text.Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no root element")
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("unclosed element %s", stack[len(stack)-1].name.Local)
+	}
+	return root, nil
+}
+
+// find returns the value of the node at an absolute path from this root.
+// Prefixes in the path are not checked against namespaces.
+func (n *customXMLNode) find(xpath string) (string, bool) {
+	steps := strings.Split(strings.TrimPrefix(xpath, "/"), "/")
+	if !strings.HasPrefix(xpath, "/") || len(steps) == 0 {
+		return "", false
+	}
+
+	var current *customXMLNode
+	for i, step := range steps {
+		if strings.HasPrefix(step, "@") {
+			if current == nil || i != len(steps)-1 {
+				return "", false
+			}
+			for _, attr := range current.attrs {
+				if attr.Name.Local == localName(step[1:]) {
+					return attr.Value, true
+				}
+			}
+			return "", false
+		}
+
+		name, index := step, 1
+		if open := strings.Index(step, "["); open >= 0 && strings.HasSuffix(step, "]") {
+			var err error
+			if index, err = strconv.Atoi(step[open+1 : len(step)-1]); err != nil || index < 1 {
+				return "", false
+			}
+			name = step[:open]
+		}
+		name = localName(name)
+
+		candidates := []*customXMLNode{n}
+		if current != nil {
+			candidates = current.children
+		}
+		var next *customXMLNode
+		seen := 0
+		for _, c := range candidates {
+			if c.name.Local == name {
+				if seen++; seen == index {
+					next = c
+					break
+				}
+			}
+		}
+		if next == nil {
+			return "", false
+		}
+		current = next
+	}
+	return current.text.String(), true
+}
+
+// prefixMappings lists the namespaces declared on the root element in the
+// form of w:prefixMappings, mapping a default namespace to ns0
+func (n *customXMLNode) prefixMappings() string {
+	var mappings []string
+	for _, attr := range n.attrs {
+		switch {
+		case attr.Name.Space == "xmlns":
+			mappings = append(mappings, fmt.Sprintf("xmlns:%s='%s'", attr.Name.Local, attr.Value))
+		case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+			mappings = append(mappings, fmt.Sprintf("xmlns:ns0='%s'", attr.Value))
+		}
+	}
+	return strings.Join(mappings, " ")
+}
+
+// localName drops the prefix of a qualified name
+func localName(name string) string {
+	if i := strings.Index(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
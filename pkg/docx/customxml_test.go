@@ -0,0 +1,110 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+// testContentControlDocumentXML has a bound-ready plain text control still
+// showing its placeholder, and a second control inside a table cell
+const testContentControlDocumentXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:body>
+<w:p><w:r><w:t>Customer:</w:t></w:r></w:p>
+<w:sdt>
+  <w:sdtPr><w:alias w:val="Customer"/><w:tag w:val="customer"/><w:id w:val="1"/><w:showingPlcHdr/><w:text/></w:sdtPr>
+  <w:sdtContent><w:p><w:r><w:rPr><w:color w:val="808080"/></w:rPr><w:t>Click here to enter text.</w:t></w:r></w:p></w:sdtContent>
+</w:sdt>
+<w:tbl><w:tr><w:tc>
+  <w:sdt>
+    <w:sdtPr><w:tag w:val="total"/><w:text/></w:sdtPr>
+    <w:sdtContent><w:p><w:r><w:rPr><w:b/></w:rPr><w:t>0</w:t></w:r></w:p></w:sdtContent>
+  </w:sdt>
+</w:tc></w:tr></w:tbl>
+</w:body>
+</w:document>`
+
+func TestCustomXMLBinding(t *testing.T) {
+	doc := New()
+	if err := doc.parseDocument([]byte(testContentControlDocumentXML)); err != nil {
+		t.Fatalf("parseDocument failed: %v", err)
+	}
+
+	data := []byte(`<invoice xmlns="urn:example:invoice" currency="EUR"><customer>Acme &amp; Co</customer><line>10</line><line>32</line></invoice>`)
+	if err := doc.SetCustomXMLPart("invoice", data); err != nil {
+		t.Fatalf("SetCustomXMLPart failed: %v", err)
+	}
+
+	n, err := doc.BindContentControl("customer", "invoice", "/ns0:invoice/ns0:customer")
+	if err != nil || n != 1 {
+		t.Fatalf("BindContentControl(customer) = %d, %v", n, err)
+	}
+	if _, err := doc.BindContentControl("total", "invoice", "/ns0:invoice/ns0:line[2]"); err != nil {
+		t.Fatalf("BindContentControl(total) failed: %v", err)
+	}
+
+	customer := doc.Body.SDTs[0]
+	props := string(customer.Props.Inner)
+	id := customXMLStoreItemID("invoice")
+	wantBinding := `<w:dataBinding w:prefixMappings="xmlns:ns0=&#39;urn:example:invoice&#39;" w:xpath="/ns0:invoice/ns0:customer" w:storeItemID="` + id + `"/>`
+	if !strings.Contains(props, wantBinding+"<w:text/>") {
+		t.Errorf("Expected the binding before the control type, got %s", props)
+	}
+	if strings.Contains(props, "showingPlcHdr") {
+		t.Errorf("Expected the placeholder flag to be dropped, got %s", props)
+	}
+	run := customer.Content.Paragraphs[0].Runs[0]
+	if run.Text[0].Content != "Acme & Co" || run.Props != nil {
+		t.Errorf("Expected the value without placeholder formatting, got %+v", run)
+	}
+
+	total := doc.Body.Tables[0].Rows[0].Cells[0].SDTs[0].Content.Paragraphs[0].Runs[0]
+	if total.Text[0].Content != "32" || total.Props == nil || total.Props.Bold == nil {
+		t.Errorf("Expected the bold value 32, got %+v", total)
+	}
+
+	// Setting the part again replaces its data, and the package reopens with it
+	if err := doc.SetCustomXMLPart("invoice", []byte(`<invoice/>`)); err != nil {
+		t.Fatalf("SetCustomXMLPart failed: %v", err)
+	}
+	packed, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	reopened, err := ReadBytes(packed)
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+	if got, ok := reopened.GetCustomXMLPart("invoice"); !ok || string(got) != `<invoice/>` {
+		t.Errorf("Expected the replaced part, got %q", got)
+	}
+	if ids := reopened.CustomXMLPartIDs(); len(ids) != 1 || ids[0] != id {
+		t.Errorf("Expected one part with ID %s, got %v", id, ids)
+	}
+	if got, ok := reopened.GetCustomXMLPart(strings.ToLower(id)); !ok || string(got) != `<invoice/>` {
+		t.Errorf("Expected the part by store item ID, got %q", got)
+	}
+}
+
+func TestCustomXMLBindingErrors(t *testing.T) {
+	doc := New()
+	if err := doc.parseDocument([]byte(testContentControlDocumentXML)); err != nil {
+		t.Fatalf("parseDocument failed: %v", err)
+	}
+
+	if err := doc.SetCustomXMLPart("data", []byte(`<a><b></a>`)); err == nil {
+		t.Error("Expected an error for malformed XML")
+	}
+	if _, err := doc.BindContentControl("customer", "missing", "/a"); err == nil {
+		t.Error("Expected an error for a missing part")
+	}
+	if err := doc.SetCustomXMLPart("data", []byte(`<a b="1"><c/></a>`)); err != nil {
+		t.Fatalf("SetCustomXMLPart failed: %v", err)
+	}
+	if _, err := doc.BindContentControl("customer", "data", "/a/d"); err == nil {
+		t.Error("Expected an error for a missing node")
+	}
+	if _, err := doc.BindContentControl("nope", "data", "/a/@b"); err == nil {
+		t.Error("Expected an error for a missing content control")
+	}
+}
@@ -0,0 +1,249 @@
+package docx
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// renamedStylePattern matches the IDs AppendDocument gives styles that
+	// clash with one of the document, such as Heading1_2
+	renamedStylePattern = regexp.MustCompile(`^(.+)_\d+$`)
+
+	fontEmbedPattern = regexp.MustCompile(`<w:embed(?:Regular|Bold|Italic|BoldItalic)\b[^>]*/>`)
+)
+
+// DeduplicateReport describes what Deduplicate removed
+type DeduplicateReport struct {
+	Styles []string // IDs of the styles folded into the style they copy
+	Parts  []string // Font and image parts dropped for an identical one
+}
+
+// Deduplicate removes the copies that merging documents made from the same
+// template leaves behind. A style AppendDocument renamed because it clashed,
+// such as Heading1_2, is folded into the style it was renamed from, or an
+// earlier copy, when their definitions are the same; embedded fonts and
+// images with the same content share a single part. Copies are found by
+// content hash, so only exact duplicates are removed.
+func (d *Document) Deduplicate() (*DeduplicateReport, error) {
+	report := &DeduplicateReport{}
+	d.deduplicateStyles(report)
+	if err := d.deduplicateFonts(report); err != nil {
+		return nil, err
+	}
+	d.deduplicateMedia(report)
+	return report, nil
+}
+
+// deduplicateStyles folds renamed copies of styles into the style they copy
+func (d *Document) deduplicateStyles(report *DeduplicateReport) {
+	data, ok := d.files[stylesPart]
+	if !ok {
+		return
+	}
+	blocks := styleBlockPattern.FindAllString(string(data), -1)
+
+	// Folding a style can make the styles based on it equal, so repeat until
+	// nothing changes. Original styles are kept over the copies of them.
+	folded := make(map[string]string)
+	for changed := true; changed; {
+		changed = false
+		kept := make(map[string]string) // Base ID and hash of a definition -> ID of the style kept for it
+		for _, renamed := range []bool{false, true} {
+			for _, block := range blocks {
+				id := styleID(block)
+				m := renamedStylePattern.FindStringSubmatch(id)
+				if _, done := folded[id]; done || (m != nil) != renamed {
+					continue
+				}
+				base := id
+				if m != nil {
+					base = m[1]
+				}
+				key := base + "\x00" + styleHash(block, id, folded)
+				if target, ok := kept[key]; ok && renamed {
+					folded[id] = target
+					report.Styles = append(report.Styles, id)
+					changed = true
+				} else if !ok {
+					kept[key] = id
+				}
+			}
+		}
+	}
+	if len(folded) == 0 {
+		return
+	}
+
+	content := styleBlockPattern.ReplaceAllStringFunc(string(data), func(block string) string {
+		if _, ok := folded[styleID(block)]; ok {
+			return ""
+		}
+		return replaceSubmatchValues(styleRefPattern, block, folded)
+	})
+	d.files[stylesPart] = []byte(content)
+
+	// Point the content using the copies at the styles kept
+	_ = d.WalkParagraphs(DefaultMaxDepth, func(p *Paragraph, depth int) error {
+		if p.Props != nil && p.Props.Style != nil {
+			if id, ok := folded[p.Props.Style.Val]; ok {
+				props := *p.Props
+				props.Style = &PStyle{Val: id}
+				p.Props = &props
+			}
+		}
+		return nil
+	})
+	_ = d.WalkTables(DefaultMaxDepth, func(t *Table, depth int) error {
+		if t.Props != nil && t.Props.Style != nil {
+			if id, ok := folded[t.Props.Style.Val]; ok {
+				props := *t.Props
+				props.Style = &TblStyle{Val: id}
+				t.Props = &props
+			}
+		}
+		return nil
+	})
+	for name, part := range d.files {
+		if path.Ext(name) == ".xml" && name != stylesPart && name != "word/document.xml" {
+			content := replaceSubmatchValues(storyStyleRefPattern, string(part), folded)
+			d.files[name] = []byte(replaceSubmatchValues(styleRefPattern, content, folded))
+		}
+	}
+}
+
+// styleHash returns the content hash of a style definition, leaving out its
+// ID and name and reading references to folded styles as the styles kept
+func styleHash(block, id string, folded map[string]string) string {
+	block = strings.Replace(block, `w:styleId="`+id+`"`, "", 1)
+	block = styleNamePattern.ReplaceAllString(block, "${1}${3}")
+	block = replaceSubmatchValues(styleRefPattern, block, folded)
+	sum := sha256.Sum256([]byte(strings.TrimSpace(block)))
+	return fmt.Sprintf("%x", sum)
+}
+
+// deduplicateFonts points the font table entries embedding the same font
+// file at a single part, which keeps its font key
+func (d *Document) deduplicateFonts(report *DeduplicateReport) error {
+	table, err := d.fontTable()
+	if err != nil {
+		return err
+	}
+	type embedded struct{ id, key string }
+	kept := make(map[[32]byte]embedded)
+	replaced := make(map[string]embedded) // Relationship ID -> embed it now shares
+	var dropped []string
+	for _, f := range table.Fonts {
+		for _, style := range FontStyles {
+			embed := f.embed(style)
+			if embed == nil {
+				continue
+			}
+			if _, done := replaced[embed.ID]; done {
+				continue
+			}
+			part, ok := d.fontPart(embed.ID)
+			if !ok {
+				continue
+			}
+			stored, _ := d.GetPart(part)
+			plain := append([]byte(nil), stored...)
+			if embed.FontKey != "" && obfuscateFont(plain, embed.FontKey) != nil {
+				continue
+			}
+			sum := sha256.Sum256(plain)
+			if k, ok := kept[sum]; ok && k.id != embed.ID {
+				replaced[embed.ID] = k
+				dropped = append(dropped, part)
+				continue
+			}
+			kept[sum] = embedded{embed.ID, embed.FontKey}
+		}
+	}
+	if len(replaced) == 0 {
+		return nil
+	}
+
+	tablePart := d.fontTablePart()
+	d.files[tablePart] = fontEmbedPattern.ReplaceAllFunc(d.files[tablePart], func(tag []byte) []byte {
+		m := referenceIDPattern.FindSubmatch(tag)
+		if m == nil {
+			return tag
+		}
+		k, ok := replaced[string(m[1])]
+		if !ok {
+			return tag
+		}
+		element := strings.Fields(string(tag))[0]
+		return []byte(fmt.Sprintf(`%s r:id="%s" w:fontKey="%s"/>`, element, k.id, k.key))
+	})
+	for _, part := range dropped {
+		d.removePart(part)
+	}
+	report.Parts = append(report.Parts, dropped...)
+	return nil
+}
+
+// deduplicateMedia points the relationships to images with the same content
+// at a single part. Large media left in the file are not compared.
+func (d *Document) deduplicateMedia(report *DeduplicateReport) {
+	var names []string
+	for name := range d.files {
+		if strings.HasPrefix(name, "word/media/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	kept := make(map[[32]byte]string)
+	duplicates := make(map[string]string) // Part -> identical part kept
+	for _, name := range names {
+		sum := sha256.Sum256(d.files[name])
+		if k, ok := kept[sum]; ok {
+			duplicates[name] = k
+			continue
+		}
+		kept[sum] = name
+	}
+	if len(duplicates) == 0 {
+		return
+	}
+
+	for relsName, data := range d.files {
+		if !strings.HasSuffix(relsName, ".rels") {
+			continue
+		}
+		// Targets are relative to the folder holding the _rels folder
+		sourceDir := path.Dir(path.Dir(relsName))
+		d.files[relsName] = relationshipPattern.ReplaceAllFunc(data, func(rel []byte) []byte {
+			m := relationshipTargetPattern.FindSubmatch(rel)
+			if m == nil {
+				return rel
+			}
+			target := string(m[1])
+			if strings.HasPrefix(target, "/") {
+				target = strings.TrimPrefix(target, "/")
+			} else {
+				target = path.Join(sourceDir, target)
+			}
+			k, ok := duplicates[target]
+			if !ok {
+				return rel
+			}
+			newTarget := "/" + k
+			if rest, ok := strings.CutPrefix(k, sourceDir+"/"); ok {
+				newTarget = rest
+			}
+			return []byte(strings.Replace(string(rel), string(m[0]), `Target="`+newTarget+`"`, 1))
+		})
+	}
+	for name := range duplicates {
+		d.DeletePart(name)
+		report.Parts = append(report.Parts, name)
+	}
+	sort.Strings(report.Parts)
+}
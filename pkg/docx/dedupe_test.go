@@ -0,0 +1,91 @@
+package docx
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDeduplicate(t *testing.T) {
+	imagePath := createTestImageFile(t, "dedupe_test.png", createPNGData())
+	defer os.Remove(imagePath)
+
+	// Letters from the same template, whose heading differs from the document's
+	dst := New()
+	if err := dst.AppendDocument(newStyledDocument("")); err != nil {
+		t.Fatalf("AppendDocument failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		letter := newStyledDocument(`<w:rPr><w:b/></w:rPr>`)
+		if err := letter.AddImage(imagePath); err != nil {
+			t.Fatalf("AddImage failed: %v", err)
+		}
+		if err := letter.EmbedFont("Letter Sans", FontRegular, testFont('l')); err != nil {
+			t.Fatalf("EmbedFont failed: %v", err)
+		}
+		if err := dst.AppendDocument(letter); err != nil {
+			t.Fatalf("AppendDocument failed: %v", err)
+		}
+	}
+	if err := dst.EmbedFont("Letter Sans Copy", FontBold, testFont('l')); err != nil {
+		t.Fatalf("EmbedFont failed: %v", err)
+	}
+	if fonts, err := dst.Fonts(); err != nil || len(fonts) != 2 {
+		t.Fatalf("Expected the letter font to be imported, got %+v (%v)", fonts, err)
+	}
+
+	report, err := dst.Deduplicate()
+	if err != nil {
+		t.Fatalf("Deduplicate failed: %v", err)
+	}
+	if len(report.Styles) != 1 || report.Styles[0] != "Heading1_3" {
+		t.Errorf("Expected Heading1_3 to be folded, got %v", report.Styles)
+	}
+	if len(report.Parts) != 2 {
+		t.Errorf("Expected a font and an image part to be dropped, got %v", report.Parts)
+	}
+
+	data, err := dst.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	loaded, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+
+	styles, _ := loaded.GetPart(stylesPart)
+	if strings.Contains(string(styles), `"Heading1_3"`) || !strings.Contains(string(styles), `w:styleId="Heading1_2"`) {
+		t.Errorf("Expected only Heading1_2 to be kept, got %s", styles)
+	}
+	for _, i := range []int{2, 5} {
+		if got := loaded.Body.Paragraphs[i].Props.Style.Val; got != "Heading1_2" {
+			t.Errorf("Expected paragraph %d to use Heading1_2, got %s", i, got)
+		}
+	}
+
+	first := drawingBlip(loaded.Body.Paragraphs[4].Runs[0].Drawing).Embed
+	second := drawingBlip(loaded.Body.Paragraphs[7].Runs[0].Drawing).Embed
+	firstRel, _ := loaded.findRelationship(first)
+	secondRel, _ := loaded.findRelationship(second)
+	if firstRel.Target != secondRel.Target {
+		t.Errorf("Expected both images to share a part, got %s and %s", firstRel.Target, secondRel.Target)
+	}
+	if _, ok := loaded.GetPart(resolvePartName(secondRel.Target)); !ok {
+		t.Errorf("Shared image part %s not found", secondRel.Target)
+	}
+
+	for _, font := range []struct {
+		name  string
+		style FontStyle
+	}{{"Letter Sans", FontRegular}, {"Letter Sans Copy", FontBold}} {
+		got, err := loaded.ExtractFont(font.name, font.style)
+		if err != nil || string(got) != string(testFont('l')) {
+			t.Errorf("Expected %s to extract from the shared part, got error %v", font.name, err)
+		}
+	}
+
+	if report, err := loaded.Deduplicate(); err != nil || len(report.Styles)+len(report.Parts) != 0 {
+		t.Errorf("Expected nothing left to deduplicate, got %+v (%v)", report, err)
+	}
+}
@@ -0,0 +1,130 @@
+package docx
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// RelIds references the parts that make up a SmartArt diagram
+type RelIds struct {
+	XMLName xml.Name `xml:"http://schemas.openxmlformats.org/drawingml/2006/diagram relIds"`
+	DM      string   `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships dm,attr"` // Data model
+	LO      string   `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships lo,attr"` // Layout
+	QS      string   `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships qs,attr"` // Quick style
+	CS      string   `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships cs,attr"` // Colors
+}
+
+// diagramDataModel is the subset of a diagram data part (word/diagrams/dataN.xml) holding node text
+type diagramDataModel struct {
+	Points []diagramPoint `xml:"ptLst>pt"`
+}
+
+// diagramPoint is a node of a SmartArt data model
+type diagramPoint struct {
+	Paragraphs []diagramParagraph `xml:"t>p"`
+}
+
+// diagramParagraph is a DrawingML paragraph inside a diagram node
+type diagramParagraph struct {
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+// GetSmartArtText returns the node texts of all SmartArt diagrams in the document body, in document order
+func (d *Document) GetSmartArtText() []string {
+	var texts []string
+	for i := range d.Body.Paragraphs {
+		texts = append(texts, d.paragraphDiagramText(&d.Body.Paragraphs[i])...)
+	}
+	return texts
+}
+
+// paragraphDiagramText returns the node texts of SmartArt diagrams anchored in a paragraph
+func (d *Document) paragraphDiagramText(p *Paragraph) []string {
+	var texts []string
+	for _, r := range p.Runs {
+		if r.Drawing == nil || r.Drawing.Inline == nil || r.Drawing.Inline.Graphic == nil {
+			continue
+		}
+		data := r.Drawing.Inline.Graphic.GraphicData
+		if data == nil || data.RelIds == nil || data.RelIds.DM == "" {
+			continue
+		}
+		texts = append(texts, d.diagramText(data.RelIds.DM)...)
+	}
+	return texts
+}
+
+// diagramText extracts node texts from the diagram data part referenced by relID
+func (d *Document) diagramText(relID string) []string {
+	rel, ok := d.findRelationship(relID)
+	if !ok {
+		return nil
+	}
+
+	data, ok := d.files[resolvePartName(rel.Target)]
+	if !ok {
+		return nil
+	}
+
+	return diagramNodeTexts(data)
+}
+
+// diagramNodeTexts parses a diagram data part and returns the non-empty node texts
+func diagramNodeTexts(data []byte) []string {
+	var model diagramDataModel
+	if err := xml.Unmarshal(data, &model); err != nil {
+		return nil
+	}
+
+	var texts []string
+	for _, pt := range model.Points {
+		var lines []string
+		for _, para := range pt.Paragraphs {
+			var line strings.Builder
+			for _, r := range para.Runs {
+				line.WriteString(r.Text)
+			}
+			if line.Len() > 0 {
+				lines = append(lines, line.String())
+			}
+		}
+		if len(lines) > 0 {
+			texts = append(texts, strings.Join(lines, " "))
+		}
+	}
+	return texts
+}
+
+// paragraphText returns the visible text of a paragraph, including link, SmartArt and text box text
+func (d *Document) paragraphText(p *Paragraph) string {
+	text := d.paragraphOwnText(p)
+	for _, boxText := range d.paragraphTextBoxText(p) {
+		if text != "" {
+			text += " "
+		}
+		text += boxText
+	}
+	return text
+}
+
+// paragraphOwnText returns the text of a paragraph's runs, links, field results
+// and SmartArt, leaving out text boxes
+func (d *Document) paragraphOwnText(p *Paragraph) string {
+	var sb strings.Builder
+	p.forEachRun(func(r *Run) {
+		for _, t := range r.Text {
+			sb.WriteString(t.Content)
+		}
+	})
+
+	for _, text := range d.paragraphDiagramText(p) {
+		if sb.Len() > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(text)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,76 @@
+package docx
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDiagramDataXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<dgm:dataModel xmlns:dgm="http://schemas.openxmlformats.org/drawingml/2006/diagram" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main">
+  <dgm:ptLst>
+    <dgm:pt modelId="{0}" type="doc"><dgm:t><a:bodyPr/><a:p><a:endParaRPr/></a:p></dgm:t></dgm:pt>
+    <dgm:pt modelId="{1}"><dgm:t><a:bodyPr/><a:p><a:r><a:t>Plan</a:t></a:r></a:p></dgm:t></dgm:pt>
+    <dgm:pt modelId="{2}"><dgm:t><a:bodyPr/><a:p><a:r><a:t>Build </a:t></a:r><a:r><a:t>Prototype</a:t></a:r></a:p></dgm:t></dgm:pt>
+    <dgm:pt modelId="{3}" type="parTrans"/>
+  </dgm:ptLst>
+</dgm:dataModel>`
+
+// newSmartArtDocument creates a document with a paragraph anchoring a SmartArt diagram
+func newSmartArtDocument() *Document {
+	doc := New()
+	doc.AddParagraph("Intro")
+	doc.SetPart("word/diagrams/data1.xml", []byte(testDiagramDataXML))
+	relID := doc.addRelationship("http://schemas.openxmlformats.org/officeDocument/2006/relationships/diagramData", "diagrams/data1.xml")
+
+	doc.Body.Paragraphs = append(doc.Body.Paragraphs, Paragraph{
+		Runs: []Run{{
+			Drawing: &Drawing{Inline: &Inline{Graphic: &Graphic{GraphicData: &GraphicData{
+				URI:    "http://schemas.openxmlformats.org/drawingml/2006/diagram",
+				RelIds: &RelIds{DM: relID},
+			}}}},
+		}},
+	})
+	return doc
+}
+
+func TestSmartArtText(t *testing.T) {
+	doc := newSmartArtDocument()
+
+	texts := doc.GetSmartArtText()
+	if len(texts) != 2 || texts[0] != "Plan" || texts[1] != "Build Prototype" {
+		t.Errorf("Unexpected SmartArt texts: %v", texts)
+	}
+
+	if !strings.Contains(doc.GetText(), "Build Prototype") {
+		t.Errorf("GetText should include SmartArt text, got %q", doc.GetText())
+	}
+
+	indices := doc.FindText("prototype")
+	if len(indices) != 1 || indices[0] != 1 {
+		t.Errorf("Expected SmartArt paragraph 1 to match, got %v", indices)
+	}
+
+	text, err := doc.GetParagraphText(1)
+	if err != nil {
+		t.Fatalf("GetParagraphText failed: %v", err)
+	}
+	if text != "Plan Build Prototype" {
+		t.Errorf("Unexpected paragraph text %q", text)
+	}
+}
+
+func TestSmartArtSurvivesSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "smartart.docx")
+	if err := newSmartArtDocument().Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	doc, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if texts := doc.GetSmartArtText(); len(texts) != 2 {
+		t.Errorf("Expected 2 SmartArt texts after reopening, got %v", texts)
+	}
+}
@@ -0,0 +1,115 @@
+package docx
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// DocumentType is the kind of WordprocessingML package: a document or a
+// template, with or without macros. It sets the content type of the main
+// document part, which Word checks against the file extension.
+type DocumentType string
+
+// Document types, named after their file extensions
+const (
+	TypeDocument             DocumentType = "docx"
+	TypeMacroEnabledDocument DocumentType = "docm"
+	TypeTemplate             DocumentType = "dotx"
+	TypeMacroEnabledTemplate DocumentType = "dotm"
+)
+
+var documentTypeContentTypes = map[DocumentType]string{
+	TypeDocument:             contentTypeMain,
+	TypeMacroEnabledDocument: "application/vnd.ms-word.document.macroEnabled.main+xml",
+	TypeTemplate:             "application/vnd.openxmlformats-officedocument.wordprocessingml.template.main+xml",
+	TypeMacroEnabledTemplate: "application/vnd.ms-word.template.macroEnabledTemplate.main+xml",
+}
+
+// macroRelTypes are the relationships of the main document to parts only
+// macro-enabled documents may hold
+var macroRelTypes = []string{
+	"http://schemas.microsoft.com/office/2006/relationships/vbaProject",
+	"http://schemas.microsoft.com/office/2006/relationships/keyMapCustomizations",
+	"http://schemas.microsoft.com/office/2006/relationships/attachedToolbars",
+}
+
+var mainContentTypePattern = regexp.MustCompile(`<Override\s[^>]*PartName="/word/document\.xml"[^>]*/>`)
+
+// Extension returns the file extension of the type, e.g. ".docm"
+func (t DocumentType) Extension() string {
+	return "." + string(t)
+}
+
+// MacroEnabled reports whether documents of the type may hold macros
+func (t DocumentType) MacroEnabled() bool {
+	return t == TypeMacroEnabledDocument || t == TypeMacroEnabledTemplate
+}
+
+// TypeForExtension returns the document type of a file extension such as
+// ".dotx", reporting false for extensions that aren't Word documents
+func TypeForExtension(ext string) (DocumentType, bool) {
+	t := DocumentType(strings.ToLower(strings.TrimPrefix(ext, ".")))
+	_, ok := documentTypeContentTypes[t]
+	return t, ok
+}
+
+// Type returns the type of the document, read from the content type of its
+// main part. Documents opened from .docm, .dotx and .dotm files keep their
+// type, and their macros, when saved.
+func (d *Document) Type() DocumentType {
+	if override := mainContentTypePattern.Find(d.files[contentTypesPart]); override != nil {
+		for t, ct := range documentTypeContentTypes {
+			if strings.Contains(string(override), `ContentType="`+ct+`"`) {
+				return t
+			}
+		}
+	}
+	return TypeDocument
+}
+
+// HasMacros reports whether the document holds a VBA project
+func (d *Document) HasMacros() bool {
+	return d.hasRelationshipType(macroRelTypes[0])
+}
+
+// SetType changes the type of the document. Changing to a type without
+// macros removes the VBA project and other macro-only parts, since Word
+// refuses documents and templates holding them.
+func (d *Document) SetType(t DocumentType) error {
+	contentType, ok := documentTypeContentTypes[t]
+	if !ok {
+		return fmt.Errorf("%w: document type %q", ErrUnsupportedFormat, t)
+	}
+
+	if !t.MacroEnabled() {
+		rels, _ := d.GetRelationships()
+		for _, rel := range rels {
+			for _, relType := range macroRelTypes {
+				if rel.Type == relType {
+					d.removePartTree(resolvePartName(rel.Target))
+				}
+			}
+		}
+	}
+
+	override := fmt.Sprintf(`<Override PartName="/word/document.xml" ContentType="%s"/>`, contentType)
+	if data, ok := d.files[contentTypesPart]; ok && mainContentTypePattern.Match(data) {
+		d.files[contentTypesPart] = mainContentTypePattern.ReplaceAll(data, []byte(override))
+	} else {
+		d.registerContentTypeOverride("word/document.xml", contentType)
+	}
+	return nil
+}
+
+// removePartTree removes a part along with the parts its own relationships
+// lead to, such as the vbaData.xml of a VBA project
+func (d *Document) removePartTree(name string) {
+	for _, rel := range relationshipPattern.FindAll(d.files[relsPartName(name)], -1) {
+		if m := relationshipTargetPattern.FindSubmatch(rel); m != nil && !strings.Contains(string(rel), `TargetMode="External"`) {
+			d.removePart(relationshipTargetPart(path.Dir(name), string(m[1])))
+		}
+	}
+	d.removePart(name)
+}
@@ -0,0 +1,93 @@
+package docx
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newMacroDocument returns a .docm document holding a VBA project
+func newMacroDocument(t *testing.T) *Document {
+	t.Helper()
+	doc := New()
+	doc.AddParagraph("Macros inside")
+	if err := doc.SetType(TypeMacroEnabledDocument); err != nil {
+		t.Fatalf("SetType failed: %v", err)
+	}
+	doc.SetPart("word/vbaProject.bin", []byte("\xd0\xcf\x11\xe0 vba"))
+	doc.SetPart("word/vbaData.xml", []byte(`<wne:vbaSuppData xmlns:wne="http://schemas.microsoft.com/office/word/2006/wordml"/>`))
+	doc.SetPart("word/_rels/vbaProject.bin.rels", []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.microsoft.com/office/2006/relationships/wordVbaData" Target="vbaData.xml"/></Relationships>`))
+	doc.addRelationship(macroRelTypes[0], "vbaProject.bin")
+	doc.registerContentTypeOverride("word/vbaProject.bin", "application/vnd.ms-office.vbaProject")
+	doc.registerContentTypeOverride("word/vbaData.xml", "application/vnd.ms-word.vbaData+xml")
+	return doc
+}
+
+func TestDocumentTypes(t *testing.T) {
+	dir := t.TempDir()
+	if err := newMacroDocument(t).Save(filepath.Join(dir, "macros.docm")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	doc, err := Open(filepath.Join(dir, "macros.docm"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if doc.Type() != TypeMacroEnabledDocument || !doc.HasMacros() {
+		t.Fatalf("Expected a macro-enabled document with macros, got %s, %v", doc.Type(), doc.HasMacros())
+	}
+	if data, _ := doc.GetPart("word/vbaProject.bin"); string(data) != "\xd0\xcf\x11\xe0 vba" {
+		t.Errorf("Expected the VBA project to be kept intact, got %q", data)
+	}
+
+	// Saving as a macro-free type must not silently drop the macros
+	if err := doc.SaveAs(filepath.Join(dir, "macros.docx")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat saving macros as .docx, got %v", err)
+	}
+
+	if err := doc.SaveAs(filepath.Join(dir, "macros.dotm")); err != nil {
+		t.Fatalf("SaveAs .dotm failed: %v", err)
+	}
+	if doc.Type() != TypeMacroEnabledDocument {
+		t.Errorf("Expected SaveAs to leave the document's type alone, got %s", doc.Type())
+	}
+	template, err := Open(filepath.Join(dir, "macros.dotm"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if template.Type() != TypeMacroEnabledTemplate || !template.HasMacros() {
+		t.Errorf("Expected a macro-enabled template with macros, got %s, %v", template.Type(), template.HasMacros())
+	}
+
+	// Dropping the macros removes the VBA project and everything pointing to it
+	if err := doc.SetType(TypeTemplate); err != nil {
+		t.Fatalf("SetType failed: %v", err)
+	}
+	if doc.Type() != TypeTemplate || doc.HasMacros() {
+		t.Errorf("Expected a template without macros, got %s, %v", doc.Type(), doc.HasMacros())
+	}
+	for _, part := range []string{"word/vbaProject.bin", "word/vbaData.xml", "word/_rels/vbaProject.bin.rels"} {
+		if _, ok := doc.GetPart(part); ok {
+			t.Errorf("Expected %s to be removed", part)
+		}
+	}
+	types, _ := doc.GetPart(contentTypesPart)
+	if strings.Contains(string(types), "vba") || strings.Count(string(types), `PartName="/word/document.xml"`) != 1 {
+		t.Errorf("Expected one main override and no VBA content types, got %s", types)
+	}
+	if err := doc.SaveAs(filepath.Join(dir, "plain.docx")); err != nil {
+		t.Errorf("SaveAs .docx failed after removing macros: %v", err)
+	}
+
+	if err := doc.SetType("odt"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat for an unknown type, got %v", err)
+	}
+	if typ, ok := TypeForExtension(".DOTX"); !ok || typ != TypeTemplate {
+		t.Errorf("Expected .DOTX to be a template, got %s, %v", typ, ok)
+	}
+	if _, ok := TypeForExtension(".pdf"); ok {
+		t.Errorf("Expected .pdf not to be a Word document type")
+	}
+}
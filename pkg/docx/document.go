@@ -5,6 +5,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 )
 
@@ -15,10 +16,14 @@ type Document struct {
 	Styles             *Styles
 	ContentTypes       *ContentTypes
 	Rels               *Relationships
-	files              map[string][]byte // All files in the docx zip
-	nextImageID        int               // Counter for the next image ID (for performance)
-	nextRelationshipID int               // Counter for the next relationship ID (for correctness)
+	files              map[string][]byte    // All files in the docx zip, except those in lazy
+	lazy               map[string]*zip.File // Large media parts left in the archive until needed
+	source             *os.File             // File lazy parts are read from, for documents from Open
+	limits             OpenOptions          // Limits the package was read with, which lazy parts are read within
+	nextImageID        int                  // Counter for the next image ID (for performance)
+	nextRelationshipID int                  // Counter for the next relationship ID (for correctness)
 	headerFooterMgr    HeaderFooterManager
+	styleCache         *styleResolver // Styles as ResolveEffectiveFormat last parsed them
 }
 
 // Body represents the document body
@@ -26,13 +31,80 @@ type Body struct {
 	XMLName    xml.Name    `xml:"body"`
 	Paragraphs []Paragraph `xml:"p"`
 	Tables     []Table     `xml:"tbl"`
+	SDTs       []SDT       `xml:"sdt"`    // Block-level content controls
+	SectPr     *RawElement `xml:"sectPr"` // Properties of the last section
 }
 
-// Paragraph represents a paragraph in the document
+// SDT represents a content control (structured document tag) wrapping block content
+type SDT struct {
+	XMLName  xml.Name    `xml:"sdt"`
+	Position int         `xml:"-"` // Number of paragraphs of the body or cell before the control
+	Props    *RawElement `xml:"sdtPr,omitempty"`
+	EndProps *RawElement `xml:"sdtEndPr,omitempty"`
+	Content  *SDTContent `xml:"sdtContent,omitempty"`
+}
+
+// SDTContent holds the paragraphs, tables and nested controls of a content control
+type SDTContent struct {
+	XMLName    xml.Name    `xml:"sdtContent"`
+	Paragraphs []Paragraph `xml:"p"`
+	Tables     []Table     `xml:"tbl"`
+	SDTs       []SDT       `xml:"sdt"`
+}
+
+// Paragraph represents a paragraph in the document. Links, fields,
+// equations and bookmarks are kept apart from the runs, with their place
+// among them; Content returns them all in document order.
 type Paragraph struct {
-	XMLName xml.Name `xml:"p"`
-	Runs    []Run    `xml:"r"`
-	Props   *PProps  `xml:"pPr,omitempty"`
+	XMLName        xml.Name        `xml:"p"`
+	Props          *PProps         `xml:"pPr,omitempty"`
+	BookmarkStarts []BookmarkStart `xml:"bookmarkStart"`
+	Runs           []Run           `xml:"r"`
+	Hyperlinks     []Hyperlink     `xml:"hyperlink"`
+	Fields         []SimpleField   `xml:"fldSimple"`
+	Math           []Equation      `xml:"http://schemas.openxmlformats.org/officeDocument/2006/math oMath"`     // Equations, kept as they are
+	MathParas      []Equation      `xml:"http://schemas.openxmlformats.org/officeDocument/2006/math oMathPara"` // Display equations
+	BookmarkEnds   []BookmarkEnd   `xml:"bookmarkEnd"`
+}
+
+// Hyperlink represents a link wrapping one or more runs
+type Hyperlink struct {
+	XMLName  xml.Name `xml:"hyperlink"`
+	Position int      `xml:"-"`                                                                                     // Number of the paragraph's own runs before the link
+	ID       string   `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr,omitempty"` // External target
+	Anchor   string   `xml:"anchor,attr,omitempty"`                                                                 // Bookmark name for internal links
+	History  string   `xml:"history,attr,omitempty"`
+	Runs     []Run    `xml:"r"`
+}
+
+// Equation is an Office Math equation, kept as it is
+type Equation struct {
+	Position int `xml:"-"` // Number of the paragraph's own runs before the equation
+	RawElement
+}
+
+// SimpleField is a field, such as TOC or PAGE, with its last computed result
+type SimpleField struct {
+	XMLName  xml.Name `xml:"fldSimple"`
+	Position int      `xml:"-"`                    // Number of the paragraph's own runs before the field
+	Instr    string   `xml:"instr,attr"`           // Field instruction, e.g. TOC \o "1-3"
+	Dirty    string   `xml:"dirty,attr,omitempty"` // "true" asks Word to update the field on open
+	Runs     []Run    `xml:"r"`                    // Result shown until the field is updated
+}
+
+// BookmarkStart marks the beginning of a named bookmark
+type BookmarkStart struct {
+	XMLName  xml.Name `xml:"bookmarkStart"`
+	Position int      `xml:"-"` // Number of the paragraph's own runs before the start
+	ID       string   `xml:"id,attr"`
+	Name     string   `xml:"name,attr"`
+}
+
+// BookmarkEnd marks the end of the bookmark with the matching ID
+type BookmarkEnd struct {
+	XMLName  xml.Name `xml:"bookmarkEnd"`
+	Position int      `xml:"-"` // Number of the paragraph's own runs before the end
+	ID       string   `xml:"id,attr"`
 }
 
 // Run represents a text run
@@ -43,6 +115,26 @@ type Run struct {
 	Tab     *Tab     `xml:"tab,omitempty"`
 	Break   *Break   `xml:"br,omitempty"`
 	Drawing *Drawing `xml:"drawing,omitempty"`
+
+	AlternateContent *AlternateContent `xml:"http://schemas.openxmlformats.org/markup-compatibility/2006 AlternateContent,omitempty"`
+	Pict             *Pict             `xml:"pict,omitempty"` // Legacy VML shape
+
+	FldChar   *FldChar    `xml:"fldChar,omitempty"` // Begins, separates or ends a complex field
+	InstrText []InstrText `xml:"instrText"`         // Instruction of a complex field
+}
+
+// FldChar marks the parts of a complex field: its instruction runs follow
+// "begin", its result runs follow "separate" and it closes with "end"
+type FldChar struct {
+	XMLName xml.Name `xml:"fldChar"`
+	Type    string   `xml:"fldCharType,attr"`
+}
+
+// InstrText holds (part of) the instruction of a complex field, e.g. MERGEFIELD Name
+type InstrText struct {
+	XMLName xml.Name `xml:"instrText"`
+	Space   string   `xml:"space,attr,omitempty"`
+	Content string   `xml:",chardata"`
 }
 
 // Text represents text content
@@ -54,20 +146,36 @@ type Text struct {
 
 // PProps represents paragraph properties
 type PProps struct {
-	XMLName xml.Name `xml:"pPr"`
-	Style   *PStyle  `xml:"pStyle,omitempty"`
-	Jc      *Jc      `xml:"jc,omitempty"` // Justification
-	Spacing *Spacing `xml:"spacing,omitempty"`
+	XMLName         xml.Name         `xml:"pPr"`
+	Style           *PStyle          `xml:"pStyle,omitempty"`
+	PageBreakBefore *PageBreakBefore `xml:"pageBreakBefore,omitempty"` // Start the paragraph on a new page
+	NumPr           *NumPr           `xml:"numPr,omitempty"`           // List numbering
+	Bidi            *Bidi            `xml:"bidi,omitempty"`            // Right-to-left paragraph
+	Jc              *Jc              `xml:"jc,omitempty"`              // Justification
+	Spacing         *Spacing         `xml:"spacing,omitempty"`
+
+	SectPr *RawElement `xml:"sectPr,omitempty"` // Set on the last paragraph of a section
 }
 
 // RProps represents run properties
 type RProps struct {
-	XMLName xml.Name `xml:"rPr"`
-	Bold    *Bold    `xml:"b,omitempty"`
-	Italic  *Italic  `xml:"i,omitempty"`
-	Size    *Size    `xml:"sz,omitempty"`
-	Color   *Color   `xml:"color,omitempty"`
-	RFonts  *RFonts  `xml:"rFonts,omitempty"`
+	XMLName   xml.Name   `xml:"rPr"`
+	RFonts    *RFonts    `xml:"rFonts,omitempty"`
+	Bold      *Bold      `xml:"b,omitempty"`
+	BoldCS    *BoldCS    `xml:"bCs,omitempty"` // Bold complex script text
+	Italic    *Italic    `xml:"i,omitempty"`
+	ItalicCS  *ItalicCS  `xml:"iCs,omitempty"` // Italic complex script text
+	Strike    *Strike    `xml:"strike,omitempty"`
+	Vanish    *Vanish    `xml:"vanish,omitempty"` // Hidden text
+	Color     *Color     `xml:"color,omitempty"`
+	Size      *Size      `xml:"sz,omitempty"`
+	SizeCS    *SizeCS    `xml:"szCs,omitempty"` // Size of complex script text
+	Highlight *Highlight `xml:"highlight,omitempty"`
+	Underline *Underline `xml:"u,omitempty"`
+	VertAlign *VertAlign `xml:"vertAlign,omitempty"` // Superscript or subscript
+	RTL       *RTL       `xml:"rtl,omitempty"`       // Right-to-left text
+	CS        *CS        `xml:"cs,omitempty"`        // Complex script formatting
+	Lang      *Lang      `xml:"lang,omitempty"`      // Proofing language
 }
 
 // Bold represents bold formatting
@@ -75,6 +183,12 @@ type Bold struct {
 	XMLName xml.Name `xml:"b"`
 }
 
+// Vanish marks text as hidden
+type Vanish struct {
+	XMLName xml.Name `xml:"vanish"`
+	Val     string   `xml:"val,attr,omitempty"` // "false" or "0" turns hiding off
+}
+
 // Italic represents italic formatting
 type Italic struct {
 	XMLName xml.Name `xml:"i"`
@@ -86,16 +200,76 @@ type Size struct {
 	Val     string   `xml:"val,attr"`
 }
 
+// BoldCS represents bold formatting of complex script text, such as Arabic
+type BoldCS struct {
+	XMLName xml.Name `xml:"bCs"`
+}
+
+// ItalicCS represents italic formatting of complex script text
+type ItalicCS struct {
+	XMLName xml.Name `xml:"iCs"`
+}
+
+// SizeCS represents the font size of complex script text
+type SizeCS struct {
+	XMLName xml.Name `xml:"szCs"`
+	Val     string   `xml:"val,attr"`
+}
+
+// RTL sets the text of a run right to left
+type RTL struct {
+	XMLName xml.Name `xml:"rtl"`
+	Val     string   `xml:"val,attr,omitempty"` // "false" or "0" turns it off
+}
+
+// CS formats a run with its complex script properties whatever its text
+type CS struct {
+	XMLName xml.Name `xml:"cs"`
+	Val     string   `xml:"val,attr,omitempty"` // "false" or "0" turns it off
+}
+
 // Color represents text color
 type Color struct {
 	XMLName xml.Name `xml:"color"`
 	Val     string   `xml:"val,attr"`
 }
 
+// Strike represents strikethrough formatting
+type Strike struct {
+	XMLName xml.Name `xml:"strike"`
+}
+
+// Highlight represents a text highlight, one of HighlightColors
+type Highlight struct {
+	XMLName xml.Name `xml:"highlight"`
+	Val     string   `xml:"val,attr"`
+}
+
+// HighlightColors lists the highlight colors Word accepts
+var HighlightColors = []string{
+	"yellow", "green", "cyan", "magenta", "blue", "red", "darkBlue", "darkCyan",
+	"darkGreen", "darkMagenta", "darkRed", "darkYellow", "darkGray", "lightGray", "black", "white",
+}
+
+// Underline represents underline formatting
+type Underline struct {
+	XMLName xml.Name `xml:"u"`
+	Val     string   `xml:"val,attr"` // single, double, thick, dotted, dash, wave, words or none
+}
+
+// VertAlign raises or lowers text
+type VertAlign struct {
+	XMLName xml.Name `xml:"vertAlign"`
+	Val     string   `xml:"val,attr"` // superscript, subscript or baseline
+}
+
 // RFonts represents font family
 type RFonts struct {
-	XMLName xml.Name `xml:"rFonts"`
-	ASCII   string   `xml:"ascii,attr"`
+	XMLName  xml.Name `xml:"rFonts"`
+	ASCII    string   `xml:"ascii,attr,omitempty"`
+	HAnsi    string   `xml:"hAnsi,attr,omitempty"`    // Latin text outside ASCII
+	EastAsia string   `xml:"eastAsia,attr,omitempty"` // East Asian text
+	CS       string   `xml:"cs,attr,omitempty"`       // Complex script text
 }
 
 // Tab represents a tab character
@@ -103,9 +277,22 @@ type Tab struct {
 	XMLName xml.Name `xml:"tab"`
 }
 
+// Bidi sets a paragraph right to left, as for Arabic and Hebrew text
+type Bidi struct {
+	XMLName xml.Name `xml:"bidi"`
+	Val     string   `xml:"val,attr,omitempty"` // "false" or "0" turns it off
+}
+
+// PageBreakBefore starts a paragraph on a new page
+type PageBreakBefore struct {
+	XMLName xml.Name `xml:"pageBreakBefore"`
+	Val     string   `xml:"val,attr,omitempty"` // "false" or "0" turns the break off
+}
+
 // Break represents a line break
 type Break struct {
 	XMLName xml.Name `xml:"br"`
+	Type    string   `xml:"type,attr,omitempty"` // page, column, textWrapping (default)
 }
 
 // PStyle represents paragraph style
@@ -114,6 +301,25 @@ type PStyle struct {
 	Val     string   `xml:"val,attr"`
 }
 
+// NumPr represents list numbering properties of a paragraph
+type NumPr struct {
+	XMLName xml.Name  `xml:"numPr"`
+	ILvl    *NumLevel `xml:"ilvl,omitempty"`
+	NumID   *NumID    `xml:"numId,omitempty"`
+}
+
+// NumLevel represents the list level of a numbered paragraph
+type NumLevel struct {
+	XMLName xml.Name `xml:"ilvl"`
+	Val     string   `xml:"val,attr"`
+}
+
+// NumID references a numbering instance in numbering.xml
+type NumID struct {
+	XMLName xml.Name `xml:"numId"`
+	Val     string   `xml:"val,attr"`
+}
+
 // Jc represents text justification
 type Jc struct {
 	XMLName xml.Name `xml:"jc"`
@@ -142,19 +348,29 @@ type ContentTypes struct {
 
 // Relationships represents document relationships
 type Relationships struct {
-	XMLName xml.Name `xml:"Relationships"`
-	// Add relationships
+	XMLName       xml.Name       `xml:"Relationships"`
+	Relationships []Relationship `xml:"Relationship"`
+}
+
+// Relationship represents a single relationship entry in a .rels part
+type Relationship struct {
+	ID         string `xml:"Id,attr"`
+	Type       string `xml:"Type,attr"`
+	Target     string `xml:"Target,attr"`
+	TargetMode string `xml:"TargetMode,attr,omitempty"`
 }
 
 // GetText extracts all text from the document
 func (d *Document) GetText() string {
 	var texts []string
-	for _, p := range d.Body.Paragraphs {
-		for _, r := range p.Runs {
+	for i := range d.Body.Paragraphs {
+		d.Body.Paragraphs[i].forEachRun(func(r *Run) {
 			for _, t := range r.Text {
 				texts = append(texts, t.Content)
 			}
-		}
+		})
+		texts = append(texts, d.paragraphDiagramText(&d.Body.Paragraphs[i])...)
+		texts = append(texts, d.paragraphTextBoxText(&d.Body.Paragraphs[i])...)
 	}
 	return strings.Join(texts, " ")
 }
@@ -164,13 +380,8 @@ func (d *Document) FindText(searchText string) []int {
 	var indices []int
 	searchLower := strings.ToLower(searchText)
 
-	for i, p := range d.Body.Paragraphs {
-		var paragraphText string
-		for _, r := range p.Runs {
-			for _, t := range r.Text {
-				paragraphText += t.Content
-			}
-		}
+	for i := range d.Body.Paragraphs {
+		paragraphText := d.paragraphText(&d.Body.Paragraphs[i])
 		if strings.Contains(strings.ToLower(paragraphText), searchLower) {
 			indices = append(indices, i)
 		}
@@ -181,17 +392,10 @@ func (d *Document) FindText(searchText string) []int {
 // GetParagraphText returns text from a specific paragraph
 func (d *Document) GetParagraphText(index int) (string, error) {
 	if index < 0 || index >= len(d.Body.Paragraphs) {
-		return "", fmt.Errorf("paragraph index %d out of range", index)
+		return "", fmt.Errorf("paragraph index %d %w", index, ErrIndexOutOfRange)
 	}
 
-	var texts []string
-	p := d.Body.Paragraphs[index]
-	for _, r := range p.Runs {
-		for _, t := range r.Text {
-			texts = append(texts, t.Content)
-		}
-	}
-	return strings.Join(texts, ""), nil
+	return d.paragraphText(&d.Body.Paragraphs[index]), nil
 }
 
 // readZipFile reads a file from the zip archive
@@ -217,13 +421,15 @@ func saveZipFile(w *zip.Writer, name string, data []byte) error {
 
 // Header and Footer methods
 
-// SetHeader sets a header with the specified type and content
+// SetHeader sets a header with the specified type and content. When the
+// document is saved it replaces the header of that type of every section.
 func (d *Document) SetHeader(hfType HeaderFooterType, content string, opts ...HeaderFooterOption) error {
 	d.ensureHeaderFooterManager()
 	return d.headerFooterMgr.SetHeader(hfType, content, opts...)
 }
 
-// SetFooter sets a footer with the specified type and content
+// SetFooter sets a footer with the specified type and content. When the
+// document is saved it replaces the footer of that type of every section.
 func (d *Document) SetFooter(hfType HeaderFooterType, content string, opts ...HeaderFooterOption) error {
 	d.ensureHeaderFooterManager()
 	return d.headerFooterMgr.SetFooter(hfType, content, opts...)
@@ -1,8 +1,15 @@
 package docx
 
 import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -77,6 +84,47 @@ func TestAddParagraphWithOptions(t *testing.T) {
 	}
 }
 
+func TestRunFormattingOptions(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Formatted", WithSize("28"), WithUnderline(""), WithStrike(), WithHighlight("yellow"),
+		WithSuperscript(), WithFont("Georgia"), WithBold())
+	doc.AddParagraph("H2O", WithSubscript(), WithUnderline("double"))
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	loaded, err := ReadBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+
+	props := loaded.Body.Paragraphs[0].Runs[0].Props
+	if props == nil || props.Underline == nil || props.Underline.Val != "single" || props.Strike == nil ||
+		props.Highlight == nil || props.Highlight.Val != "yellow" || props.VertAlign == nil || props.VertAlign.Val != "superscript" {
+		t.Fatalf("Expected the formatting to survive a round trip, got %+v", props)
+	}
+	if props.RFonts == nil || props.RFonts.ASCII != "Georgia" || props.RFonts.HAnsi != "Georgia" {
+		t.Errorf("Expected the Georgia font, got %+v", props.RFonts)
+	}
+	props = loaded.Body.Paragraphs[1].Runs[0].Props
+	if props.VertAlign.Val != "subscript" || props.Underline.Val != "double" {
+		t.Errorf("Expected double underlined subscript, got %+v", props)
+	}
+
+	// Word requires run properties in schema order
+	part, _ := loaded.GetPart("word/document.xml")
+	xml := string(part)
+	last := -1
+	for _, name := range []string{"<rFonts", "<b>", "<strike>", "<sz", "<highlight", "<u ", "<vertAlign"} {
+		at := strings.Index(xml, name)
+		if at < last {
+			t.Errorf("Expected %s after the previous run property", name)
+		}
+		last = at
+	}
+}
+
 func TestAddParagraphAt(t *testing.T) {
 	doc := New()
 	doc.AddParagraph("First")
@@ -146,6 +194,58 @@ func TestDeleteParagraphsRange(t *testing.T) {
 	}
 }
 
+func TestMoveParagraph(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("P0")
+	doc.AddParagraph("P1", WithBold())
+	doc.AddTable(1, 1) // Precedes P2
+	for i := 2; i <= 4; i++ {
+		doc.AddParagraph(fmt.Sprintf("P%d", i))
+	}
+	texts := func() string {
+		var out []string
+		for i := 0; i < doc.GetParagraphCount(); i++ {
+			text, _ := doc.GetParagraphText(i)
+			out = append(out, text)
+		}
+		return strings.Join(out, " ")
+	}
+
+	if err := doc.MoveParagraphRange(1, 2, 3); err != nil {
+		t.Fatalf("MoveParagraphRange failed: %v", err)
+	}
+	if got := texts(); got != "P0 P3 P4 P1 P2" {
+		t.Errorf("Unexpected order after moving a range: %s", got)
+	}
+	if pos := doc.Body.Tables[0].Position; pos != 4 {
+		t.Errorf("Expected the table to move with the range to position 4, got %d", pos)
+	}
+	if p := doc.Body.Paragraphs[3]; p.Runs[0].Props == nil || p.Runs[0].Props.Bold == nil {
+		t.Error("Expected the moved paragraph to stay bold")
+	}
+
+	if err := doc.MoveParagraph(4, 0); err != nil {
+		t.Fatalf("MoveParagraph failed: %v", err)
+	}
+	if got := texts(); got != "P2 P0 P3 P4 P1" {
+		t.Errorf("Unexpected order after moving a paragraph: %s", got)
+	}
+	// The table preceded the moved paragraph, so it stays where it was
+	if pos := doc.Body.Tables[0].Position; pos != 5 {
+		t.Errorf("Expected the table to stay at the end, got %d", pos)
+	}
+
+	if err := doc.MoveParagraph(5, 0); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+	if err := doc.MoveParagraphRange(0, 1, 4); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange for a target past the end, got %v", err)
+	}
+	if err := doc.MoveParagraphRange(2, 1, 0); err == nil {
+		t.Error("Expected an error for an inverted range")
+	}
+}
+
 func TestReplaceText(t *testing.T) {
 	doc := New()
 	doc.AddParagraph("Hello world")
@@ -269,6 +369,51 @@ func TestSaveAndOpen(t *testing.T) {
 	}
 }
 
+func TestSaveAs(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := filepath.Join(tmpDir, "out.docx")
+
+	doc := New()
+	doc.AddParagraph("First")
+	if err := doc.SaveAs(target); err != nil {
+		t.Fatalf("SaveAs failed: %v", err)
+	}
+
+	// Existing files are protected unless overwriting is requested
+	doc.AddParagraph("Second")
+	if err := doc.SaveAs(target); !errors.Is(err, ErrFileExists) {
+		t.Errorf("Expected ErrFileExists, got %v", err)
+	}
+	if err := doc.SaveAs(target, WithOverwrite()); err != nil {
+		t.Fatalf("SaveAs with overwrite failed: %v", err)
+	}
+	reopened, err := Open(target)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if reopened.GetParagraphCount() != 2 {
+		t.Errorf("Expected overwritten file to have 2 paragraphs, got %d", reopened.GetParagraphCount())
+	}
+
+	if err := doc.SaveAs(filepath.Join(tmpDir, "out.xyz")); err == nil {
+		t.Error("Expected error for unsupported extension")
+	}
+
+	// A failing exporter must not leave any file behind
+	RegisterExporter(".fail", func(*Document, string) error { return errors.New("boom") })
+	if err := doc.SaveAs(filepath.Join(tmpDir, "out.fail")); err == nil {
+		t.Error("Expected exporter error")
+	}
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 1 {
+		names := []string{}
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("Expected only out.docx in output dir, got %v", names)
+	}
+}
+
 func TestClone(t *testing.T) {
 	doc := New()
 	doc.AddParagraph("Original")
@@ -280,3 +425,434 @@ func TestClone(t *testing.T) {
 		t.Error("Clone should not affect original document")
 	}
 }
+
+func TestCloneIsolation(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Original", WithBold())
+	table := doc.AddTable(1, 1)
+	table.SetCellText(0, 0, "Cell")
+	if err := doc.SetHeader(HeaderTypeDefault, "Header"); err != nil {
+		t.Fatalf("SetHeader failed: %v", err)
+	}
+	doc.files["word/extra.xml"] = []byte("extra")
+
+	cloned := doc.Clone()
+	cloned.Body.Paragraphs[0].Runs[0].Text[0].Content = "Changed"
+	cloned.Body.Paragraphs[0].Runs[0].Props.Bold = nil
+	cloned.Body.Tables[0].Rows[0].Cells[0].Content[0].Runs[0].Text[0].Content = "Changed"
+	cloned.Body.Tables[0].Rows = append(cloned.Body.Tables[0].Rows[:0], TblRow{})
+	cloned.files["word/extra.xml"][0] = 'X'
+	header, err := cloned.GetHeader(HeaderTypeDefault)
+	if err != nil {
+		t.Fatalf("Clone lost the header: %v", err)
+	}
+	header.Paragraphs[0].Runs[0].Text[0].Content = "Changed"
+	cloned.RemoveHeader(HeaderTypeDefault)
+
+	run := doc.Body.Paragraphs[0].Runs[0]
+	if run.Text[0].Content != "Original" || run.Props.Bold == nil {
+		t.Errorf("Changing the clone's runs changed the original: %+v", run)
+	}
+	if got := doc.Body.Tables[0].Rows[0].Cells; len(got) != 1 || got[0].Content[0].Runs[0].Text[0].Content != "Cell" {
+		t.Errorf("Changing the clone's table changed the original: %+v", got)
+	}
+	if string(doc.files["word/extra.xml"]) != "extra" {
+		t.Errorf("Changing the clone's parts changed the original")
+	}
+	if header, _ := doc.GetHeader(HeaderTypeDefault); header.Paragraphs[0].Runs[0].Text[0].Content != "Header" {
+		t.Errorf("Changing the clone's header changed the original")
+	}
+
+	// ID counters carry over, so new parts don't clash with existing ones
+	if cloned.nextImageID != doc.nextImageID || cloned.nextRelationshipID != doc.nextRelationshipID {
+		t.Errorf("Clone didn't copy the ID counters")
+	}
+}
+
+func TestAddBookmarkAndInternalLink(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Target")
+	doc.AddParagraph("Go to target", WithInternalLink("target"), WithBold())
+
+	if err := doc.AddBookmark(0, "target"); err != nil {
+		t.Fatalf("AddBookmark failed: %v", err)
+	}
+	if err := doc.AddBookmark(1, "target"); err == nil {
+		t.Error("Expected error for duplicate bookmark name")
+	}
+	if err := doc.AddBookmark(5, "other"); err == nil {
+		t.Error("Expected error for out of range index")
+	}
+
+	link := doc.Body.Paragraphs[1]
+	if len(link.Runs) != 0 || len(link.Hyperlinks) != 1 || link.Hyperlinks[0].Anchor != "target" {
+		t.Fatalf("Expected paragraph runs to move into a link, got %+v", link)
+	}
+	if link.Hyperlinks[0].Runs[0].Props == nil || link.Hyperlinks[0].Runs[0].Props.Bold == nil {
+		t.Error("Expected run options to apply to linked runs")
+	}
+
+	path := filepath.Join(t.TempDir(), "links.docx")
+	if err := doc.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if text, _ := reopened.GetParagraphText(1); text != "Go to target" {
+		t.Errorf("Expected link text to be readable, got %q", text)
+	}
+	if got := reopened.Body.Paragraphs[0].BookmarkStarts; len(got) != 1 || got[0].Name != "target" {
+		t.Errorf("Expected bookmark to survive save, got %+v", got)
+	}
+}
+
+func TestBodyOrderSurvivesSave(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Before")
+	doc.AddTable(1, 1).SetCellText(0, 0, "Cell")
+	doc.AddParagraph("After")
+	doc.AddParagraphAt(0, "First")
+
+	if got := doc.Body.Tables[0].Position; got != 2 {
+		t.Fatalf("Expected table after 2 paragraphs, got position %d", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "order.docx")
+	if err := doc.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if got := reopened.Body.Tables[0].Position; got != 2 {
+		t.Errorf("Expected table to stay after 2 paragraphs, got position %d", got)
+	}
+
+	if err := reopened.DeleteParagraphsRange(0, 1); err != nil {
+		t.Fatalf("DeleteParagraphsRange failed: %v", err)
+	}
+	if got := reopened.Body.Tables[0].Position; got != 0 {
+		t.Errorf("Expected table to move to the start, got position %d", got)
+	}
+}
+
+func TestExtractRange(t *testing.T) {
+	imagePath := createTestImageFile(t, "extract_range.png", createPNGData())
+	defer os.Remove(imagePath)
+
+	doc := New()
+	doc.AddParagraph("Intro")
+	if err := doc.AddImage(imagePath); err != nil {
+		t.Fatalf("AddImage failed: %v", err)
+	}
+	doc.AddParagraph("Chapter")
+	doc.AddTable(1, 1).SetCellText(0, 0, "Data")
+	doc.AddParagraph("End")
+	doc.SetPart("word/header1.xml", []byte(`<w:hdr/>`))
+
+	part, err := doc.ExtractRange(2, 3)
+	if err != nil {
+		t.Fatalf("ExtractRange failed: %v", err)
+	}
+	if part.GetParagraphCount() != 2 || part.GetTableCount() != 1 {
+		t.Fatalf("Expected 2 paragraphs and 1 table, got %d and %d", part.GetParagraphCount(), part.GetTableCount())
+	}
+	if got := part.Body.Tables[0].Position; got != 1 {
+		t.Errorf("Expected table after the first paragraph, got position %d", got)
+	}
+	if part.GetImageCount() != 0 {
+		t.Errorf("Expected no images in part, got %d", part.GetImageCount())
+	}
+	if _, ok := part.GetPart("word/header1.xml"); !ok {
+		t.Error("Expected other package parts to be kept")
+	}
+	for _, name := range part.PartNames() {
+		if strings.HasPrefix(name, "word/media/") {
+			t.Errorf("Expected unused image %s to be removed", name)
+		}
+	}
+	rels, _ := part.GetRelationships()
+	for _, rel := range rels {
+		if rel.Type == relTypeImage {
+			t.Errorf("Expected unused image relationship %s to be removed", rel.ID)
+		}
+	}
+
+	// The original keeps its image
+	if doc.GetImageCount() != 1 {
+		t.Errorf("Expected original to keep its image, got %d", doc.GetImageCount())
+	}
+
+	intro, err := doc.ExtractRange(0, 1)
+	if err != nil {
+		t.Fatalf("ExtractRange failed: %v", err)
+	}
+	if intro.GetImageCount() != 1 || intro.GetTableCount() != 0 {
+		t.Errorf("Expected the image and no table in the intro, got %d images and %d tables", intro.GetImageCount(), intro.GetTableCount())
+	}
+
+	if _, err := doc.ExtractRange(3, 1); err == nil {
+		t.Error("Expected error for invalid range")
+	}
+}
+
+func TestInMemoryRoundTrip(t *testing.T) {
+	// Any temp file would fail to be created
+	t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "missing"))
+
+	doc := New()
+	doc.AddParagraph("In memory")
+	if err := doc.AddImageFromBytes("logo.png", createPNGData()); err != nil {
+		t.Fatalf("AddImageFromBytes failed: %v", err)
+	}
+	if err := doc.AddImageFromReader("logo.txt", bytes.NewReader(createPNGData())); err == nil {
+		t.Error("Expected error for unsupported image extension")
+	}
+
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := doc.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if n != int64(buf.Len()) || !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("Expected WriteTo to match ToBytes (%d bytes), got %d bytes", len(data), n)
+	}
+
+	reopened, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+	if text, _ := reopened.GetParagraphText(0); text != "In memory" {
+		t.Errorf("Expected 'In memory', got %q", text)
+	}
+	if reopened.GetImageCount() != 1 {
+		t.Errorf("Expected 1 image, got %d", reopened.GetImageCount())
+	}
+
+	if _, err := ReadBytes([]byte("not a zip")); err == nil {
+		t.Error("Expected error for invalid data")
+	}
+}
+
+func TestLargeMediaParts(t *testing.T) {
+	video := make([]byte, 2<<20)
+	rand.New(rand.NewSource(1)).Read(video)
+
+	doc := New()
+	doc.AddParagraph("With a video")
+	doc.SetPart("word/media/video.mp4", video)
+	path := filepath.Join(t.TempDir(), "video.docx")
+	if err := doc.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	opened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer opened.Close()
+	if _, ok := opened.files["word/media/video.mp4"]; ok {
+		t.Error("Expected the video to be left in the file")
+	}
+	if data, ok := opened.GetPart("word/media/video.mp4"); !ok || !bytes.Equal(data, video) {
+		t.Errorf("Expected GetPart to read the video, got %d bytes", len(data))
+	}
+	rc, err := opened.OpenPart("word/media/video.mp4")
+	if err != nil {
+		t.Fatalf("OpenPart failed: %v", err)
+	}
+	streamed, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(streamed, video) {
+		t.Errorf("Expected OpenPart to stream the video, got %d bytes", len(streamed))
+	}
+
+	// Parts left in the file are held to the limits as well
+	if _, err := OpenWithOptions(path, OpenOptions{MaxPartSize: 1 << 20}); !errors.Is(err, ErrPartTooLarge) {
+		t.Errorf("Expected ErrPartTooLarge for a video over the limit, got %v", err)
+	}
+	limited, err := OpenWithOptions(path, OpenOptions{MaxPartSize: 4 << 20})
+	if err != nil {
+		t.Fatalf("OpenWithOptions failed: %v", err)
+	}
+	defer limited.Close()
+	limited.limits.MaxTotalSize = 1 << 20
+	if _, ok := limited.GetPart("word/media/video.mp4"); ok {
+		t.Error("Expected GetPart to read the video within the limits")
+	}
+	if _, err := limited.OpenPart("word/media/video.mp4"); !errors.Is(err, ErrPartTooLarge) {
+		t.Errorf("Expected OpenPart to stream the video within the limits, got %v", err)
+	}
+
+	// Saving copies the video, to another file and over the one it is read from
+	opened.AddParagraph("Edited")
+	clone := opened.Clone()
+	other := filepath.Join(t.TempDir(), "other.docx")
+	for _, target := range []string{other, path} {
+		if err := opened.Save(target); err != nil {
+			t.Fatalf("Save to %s failed: %v", target, err)
+		}
+		reopened, err := Open(target)
+		if err != nil {
+			t.Fatalf("Open %s failed: %v", target, err)
+		}
+		data, _ := reopened.GetPart("word/media/video.mp4")
+		if !bytes.Equal(data, video) || !strings.Contains(reopened.GetText(), "Edited") {
+			t.Errorf("Expected %s to keep the video and the edit, got %d bytes: %q", target, len(data), reopened.GetText())
+		}
+		reopened.Close()
+	}
+	if data, _ := clone.ToBytes(); len(data) < len(video) {
+		t.Errorf("Expected the clone to keep the video, got %d bytes", len(data))
+	}
+}
+
+func TestErrorTypes(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Only paragraph")
+	table := doc.AddTable(1, 1)
+
+	_, replaceErr := doc.ReplaceTextInParagraph(-1, "a", "b")
+	for name, err := range map[string]error{
+		"DeleteParagraph": doc.DeleteParagraph(3),
+		"ReplaceText":     replaceErr,
+		"SetCellText":     table.SetCellText(2, 0, "x"),
+		"AddImageAt":      doc.AddImageAt(9, "missing.png"),
+	} {
+		if !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("%s: expected ErrIndexOutOfRange, got %v", name, err)
+		}
+	}
+
+	if err := doc.AddImageFromBytes("picture.txt", []byte("not an image")); !errors.Is(err, ErrInvalidImageFormat) {
+		t.Errorf("Expected ErrInvalidImageFormat for extension, got %v", err)
+	}
+	if err := doc.AddImageFromBytes("picture.png", []byte("not an image")); !errors.Is(err, ErrInvalidImageFormat) {
+		t.Errorf("Expected ErrInvalidImageFormat for content, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "out.odt")
+	if err := doc.SaveAs(path); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+// repackage returns a copy of a package with parts added or replaced
+func repackage(t testing.TB, data []byte, parts map[string]string) []byte {
+	t.Helper()
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range r.File {
+		if _, ok := parts[f.Name]; ok {
+			continue
+		}
+		if err := zw.Copy(f); err != nil {
+			t.Fatalf("Copy %s failed: %v", f.Name, err)
+		}
+	}
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Create %s failed: %v", name, err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestOpenMalformedPackages(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Hello")
+	valid, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+
+	bomb := `<?xml version="1.0"?><!DOCTYPE lolz [<!ENTITY lol "lol"><!ENTITY lol2 "&lol;&lol;&lol;&lol;">]>` +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:r><w:t>&lol2;</w:t></w:r></w:p></w:body></w:document>`
+	var empty bytes.Buffer
+	zip.NewWriter(&empty).Close()
+	hugeAttribute := `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p w:rsidR="` +
+		strings.Repeat("A", 2<<20) + `"/></w:body></w:document>`
+	opts := OpenOptions{MaxPartSize: 1 << 20}
+	manyLargeParts := map[string]string{}
+	for i := 1; i <= 3; i++ {
+		manyLargeParts[fmt.Sprintf("customXml/item%d.xml", i)] = "<a>" + strings.Repeat("A", 600<<10) + "</a>"
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		opts OpenOptions
+		want error
+	}{
+		{"truncated", valid[:len(valid)/2], OpenOptions{}, ErrInvalidPackage},
+		{"not a zip", []byte("%PDF-1.4"), OpenOptions{}, ErrInvalidPackage},
+		{"no main document", repackage(t, empty.Bytes(), map[string]string{"[Content_Types].xml": "<Types/>"}), OpenOptions{}, ErrInvalidPackage},
+		{"zip slip", repackage(t, valid, map[string]string{"../../evil.sh": "rm -rf /"}), OpenOptions{}, ErrUnsafePartName},
+		{"absolute name", repackage(t, valid, map[string]string{"/etc/passwd": "root"}), OpenOptions{}, ErrUnsafePartName},
+		{"backslash traversal", repackage(t, valid, map[string]string{`word\..\..\evil.xml`: "<x/>"}), OpenOptions{}, ErrUnsafePartName},
+		{"entity bomb", repackage(t, valid, map[string]string{"word/document.xml": bomb}), OpenOptions{}, ErrInvalidPackage},
+		{"huge attribute", repackage(t, valid, map[string]string{"word/document.xml": hugeAttribute}), opts, ErrPartTooLarge},
+		{"too many parts", valid, OpenOptions{MaxParts: 2}, ErrTooManyParts},
+		{"parts too large together", repackage(t, valid, manyLargeParts), OpenOptions{MaxPartSize: 1 << 20, MaxTotalSize: 1 << 20}, ErrPackageTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ReadBytesWithOptions(tt.data, tt.opts)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+			if doc != nil {
+				t.Error("Expected no document")
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "slip.docx")
+	os.WriteFile(path, repackage(t, valid, map[string]string{"../evil": "x"}), 0o644)
+	if _, err := Open(path); !errors.Is(err, ErrUnsafePartName) {
+		t.Errorf("Expected Open to return ErrUnsafePartName, got %v", err)
+	}
+	if _, err := ReadBytesWithOptions(valid, opts); err != nil {
+		t.Errorf("Expected a valid package within the limits to open, got %v", err)
+	}
+	if _, err := ReadBytesWithOptions(repackage(t, valid, manyLargeParts), OpenOptions{MaxTotalSize: 4 << 20}); err != nil {
+		t.Errorf("Expected parts within the total to open, got %v", err)
+	}
+}
+
+func FuzzReadBytes(f *testing.F) {
+	doc := New()
+	doc.AddParagraph("Hello", WithBold())
+	doc.AddTable(2, 2)
+	valid, err := doc.ToBytes()
+	if err != nil {
+		f.Fatalf("ToBytes failed: %v", err)
+	}
+	f.Add(valid)
+	f.Add(valid[:len(valid)-22])
+	f.Add([]byte("PK\x03\x04"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		doc, err := ReadBytesWithOptions(data, OpenOptions{MaxPartSize: 1 << 20, MaxParts: 100})
+		if err == nil && doc.Body == nil {
+			t.Error("Expected a document with a body")
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package docx
+
+import "errors"
+
+// Errors returned by document operations, wrapped with details such as the
+// index or file involved. Check for them with errors.Is.
+var (
+	// ErrIndexOutOfRange is returned for paragraph, table, row, column and
+	// other indexes outside the document
+	ErrIndexOutOfRange = errors.New("out of range")
+
+	// ErrInvalidImageFormat is returned for images whose extension isn't
+	// supported or whose content doesn't match their extension
+	ErrInvalidImageFormat = errors.New("unsupported image format")
+
+	// ErrUnsupportedFormat is returned for files of a type that can't be
+	// read or written, and for formats a function doesn't support, such as
+	// cross-reference formats
+	ErrUnsupportedFormat = errors.New("unsupported format")
+
+	// ErrNotFound is returned for bookmarks, numbering definitions and
+	// other named parts of a document that don't exist
+	ErrNotFound = errors.New("not found")
+
+	// ErrHeadingNotFound is returned for heading paths that match no heading
+	ErrHeadingNotFound = errors.New("heading not found")
+
+	// ErrInvalidPackage is returned for files that aren't a readable .docx
+	// package, such as truncated archives or a main document that isn't XML
+	ErrInvalidPackage = errors.New("invalid package")
+
+	// ErrPartTooLarge is returned for package parts larger than
+	// OpenOptions.MaxPartSize
+	ErrPartTooLarge = errors.New("part too large")
+
+	// ErrPackageTooLarge is returned for packages whose parts read into
+	// memory add up to more than OpenOptions.MaxTotalSize
+	ErrPackageTooLarge = errors.New("package too large")
+
+	// ErrTooManyParts is returned for packages with more parts than
+	// OpenOptions.MaxParts
+	ErrTooManyParts = errors.New("too many parts")
+
+	// ErrUnsafePartName is returned for parts named with an absolute path or
+	// one leading out of the package, such as "../../etc/passwd"
+	ErrUnsafePartName = errors.New("unsafe part name")
+
+	// ErrNotListItem is returned for paragraphs that aren't numbered list
+	// items where one is expected, such as by RestartNumbering
+	ErrNotListItem = errors.New("not a list item")
+
+	// ErrInvalidXPath is returned for XPath expressions QueryXPath and
+	// PatchXML can't parse, or that use what they don't support
+	ErrInvalidXPath = errors.New("invalid XPath expression")
+)
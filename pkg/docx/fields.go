@@ -0,0 +1,392 @@
+package docx
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CrossReferenceFormat is what a cross-reference shows of the paragraph it
+// refers to
+type CrossReferenceFormat string
+
+// Cross-reference formats
+const (
+	CrossReferenceText     CrossReferenceFormat = "text"     // Its text
+	CrossReferenceNumber   CrossReferenceFormat = "number"   // Its list number, e.g. 2.1 for a numbered heading
+	CrossReferencePage     CrossReferenceFormat = "page"     // The page it is on, which Word fills in
+	CrossReferencePosition CrossReferenceFormat = "position" // "above" or "below"
+)
+
+// refNotFound is the result Word gives references to bookmarks that don't exist
+const refNotFound = "Error! Reference source not found."
+
+// AddCrossReference inserts a cross-reference to a bookmarked paragraph
+// into the paragraph at index, before the character at offset, e.g. after
+// "see section ". It is a REF field linking to the bookmark, or PAGEREF for
+// the page, with its result filled in as UpdateFields does; page numbers
+// depend on the layout, so Word fills them in when the document is opened.
+// An offset in the text of a link or another field puts it after them.
+func (d *Document) AddCrossReference(index, offset int, bookmarkName string, format CrossReferenceFormat) error {
+	var instr string
+	switch format {
+	case CrossReferenceText:
+		instr = `REF %s \h`
+	case CrossReferenceNumber:
+		instr = `REF %s \r \h`
+	case CrossReferencePage:
+		instr = `PAGEREF %s \h`
+	case CrossReferencePosition:
+		instr = `REF %s \p \h`
+	default:
+		return fmt.Errorf("cross-reference format %q: %w", format, ErrUnsupportedFormat)
+	}
+
+	if index < 0 || index >= len(d.Body.Paragraphs) {
+		return fmt.Errorf("paragraph index %d %w", index, ErrIndexOutOfRange)
+	}
+	p := &d.Body.Paragraphs[index]
+	if length := paragraphTextLength(p); offset < 0 || offset > length {
+		return fmt.Errorf("offset %d %w (paragraph %d has %d characters)", offset, ErrIndexOutOfRange, index, length)
+	}
+	ctx := d.newFieldContext()
+	if _, ok := ctx.bookmarks[bookmarkName]; !ok {
+		return fmt.Errorf("bookmark %q: %w", bookmarkName, ErrNotFound)
+	}
+
+	field := SimpleField{Instr: fmt.Sprintf(instr, bookmarkName)}
+	if result, ok := ctx.result(field.Instr, p); ok {
+		field.setResult(result)
+	} else {
+		field.Dirty = "true"
+	}
+	p.insertField(offset, field)
+	return nil
+}
+
+// insertField inserts a simple field at a character offset of the
+// paragraph, which must be in range, splitting the run there. An offset in
+// the text of a link or another field puts it after them.
+func (p *Paragraph) insertField(offset int, field SimpleField) {
+	field.Position = len(p.Runs)
+	runs, i, k, at := p.locateOffset(offset, true)
+	if runs == &p.Runs {
+		left, right := splitRun(p.Runs[i], k, at)
+		switch {
+		case !left.hasContent():
+			field.Position = i
+		case !right.hasContent():
+			field.Position = i + 1
+		default:
+			p.SpliceRuns(i, 1, left, right)
+			field.Position = i + 1
+		}
+	} else if runs != nil {
+		for _, e := range p.inlineElements() {
+			if h, ok := e.value.(*Hyperlink); ok && &h.Runs == runs {
+				field.Position = *e.position
+			}
+			if f, ok := e.value.(*SimpleField); ok && &f.Runs == runs {
+				field.Position = *e.position
+			}
+		}
+	}
+	p.Fields = append(p.Fields, field)
+}
+
+// UpdateFields works out the results of the REF and SEQ fields of the body
+// and its tables again, as Word does when fields are updated: references
+// show the current text, list number or position of their bookmark, and
+// sequences such as figure numbers count up in order. Fields that need the
+// page layout, such as PAGE, PAGEREF and TOC, are left as they are. It
+// returns the number of fields updated.
+func (d *Document) UpdateFields() int {
+	ctx := d.newFieldContext()
+	count := 0
+	d.forEachParagraph(func(p *Paragraph) {
+		count += p.updateFields(ctx)
+	})
+	return count
+}
+
+// FlattenFields replaces the fields of the body and its tables with their
+// current results as plain text, so Word no longer updates them. With
+// kinds, e.g. "REF" or "SEQ", only fields of those kinds are flattened. It
+// returns the number of fields flattened.
+func (d *Document) FlattenFields(kinds ...string) int {
+	flatten := func(instr string) bool {
+		kind := fieldKind(instr)
+		return kind != "" && (len(kinds) == 0 || slices.ContainsFunc(kinds, func(k string) bool { return strings.EqualFold(k, kind) }))
+	}
+
+	count := 0
+	d.forEachParagraph(func(p *Paragraph) {
+		for _, runs := range p.complexRunSlices() {
+			p.flattenComplexFields(runs, flatten, &count)
+		}
+		for i := len(p.Fields) - 1; i >= 0; i-- {
+			f := &p.Fields[i]
+			if !flatten(f.Instr) {
+				continue
+			}
+			p.flattenComplexFields(&f.Runs, flatten, &count)
+			p.ReplaceField(i, f.Runs...)
+			count++
+		}
+	})
+	return count
+}
+
+// fieldKind returns the upper-cased kind of a field instruction, e.g. REF
+func fieldKind(instr string) string {
+	if fields := strings.Fields(instr); len(fields) > 0 {
+		return strings.ToUpper(fields[0])
+	}
+	return ""
+}
+
+// fieldContext is what the results of REF and SEQ fields are worked out from
+type fieldContext struct {
+	d         *Document
+	bookmarks map[string]*Paragraph // Paragraph holding each bookmark
+	order     map[*Paragraph]int    // Position of each paragraph in the document
+	numbers   map[*Paragraph]string // List number of each numbered list item
+	sequences map[string]int        // Last number of each SEQ label, lower-cased
+}
+
+// newFieldContext returns the context of the fields of the body and its tables
+func (d *Document) newFieldContext() *fieldContext {
+	ctx := &fieldContext{
+		d:         d,
+		bookmarks: make(map[string]*Paragraph),
+		order:     make(map[*Paragraph]int),
+		numbers:   make(map[*Paragraph]string),
+		sequences: make(map[string]int),
+	}
+	lists := d.newListCounter()
+	d.forEachParagraph(func(p *Paragraph) {
+		ctx.order[p] = len(ctx.order)
+		if number := lists.next(p); number != "" {
+			ctx.numbers[p] = number
+		}
+		for _, b := range p.BookmarkStarts {
+			if _, ok := ctx.bookmarks[b.Name]; !ok {
+				ctx.bookmarks[b.Name] = p
+			}
+		}
+	})
+	return ctx
+}
+
+// seqFormats maps the \* switches of SEQ fields to numbering formats
+var seqFormats = map[string]string{
+	"ARABIC":     "decimal",
+	"ROMAN":      "upperRoman",
+	"roman":      "lowerRoman",
+	"ALPHABETIC": "upperLetter",
+	"alphabetic": "lowerLetter",
+}
+
+// result returns the result of a REF or SEQ field in paragraph p, reporting
+// false for other fields. SEQ fields count, so they must be visited in order.
+func (ctx *fieldContext) result(instr string, p *Paragraph) (string, bool) {
+	tokens := strings.Fields(instr)
+	if len(tokens) < 2 {
+		return "", false
+	}
+	switches := tokens[2:]
+	has := func(name string) bool {
+		return slices.ContainsFunc(switches, func(s string) bool { return strings.EqualFold(s, name) })
+	}
+
+	switch strings.ToUpper(tokens[0]) {
+	case "REF":
+		target, ok := ctx.bookmarks[tokens[1]]
+		switch {
+		case !ok:
+			return refNotFound, true
+		case has(`\p`):
+			if ctx.order[target] < ctx.order[p] {
+				return "above", true
+			}
+			return "below", true
+		case has(`\r`) || has(`\n`) || has(`\w`):
+			return strings.TrimRight(ctx.numbers[target], "."), true
+		}
+		return strings.TrimSpace(ctx.d.paragraphOwnText(target)), true
+
+	case "SEQ":
+		label := strings.ToLower(tokens[1])
+		format := "decimal"
+		next := ctx.sequences[label] + 1
+		for i, s := range switches {
+			switch {
+			case strings.EqualFold(s, `\c`):
+				next = ctx.sequences[label]
+			case strings.EqualFold(s, `\r`) && i+1 < len(switches):
+				if n, err := strconv.Atoi(switches[i+1]); err == nil {
+					next = n
+				}
+			case s == `\*` && i+1 < len(switches):
+				if f, ok := seqFormats[switches[i+1]]; ok {
+					format = f
+				}
+			}
+		}
+		ctx.sequences[label] = next
+		return FormatListNumber(next, format), true
+	}
+	return "", false
+}
+
+// complexRunSlices returns the run slices of a paragraph that can hold
+// complex fields: its own runs and those of its links
+func (p *Paragraph) complexRunSlices() []*[]Run {
+	runs := []*[]Run{&p.Runs}
+	for i := range p.Hyperlinks {
+		runs = append(runs, &p.Hyperlinks[i].Runs)
+	}
+	return runs
+}
+
+// complexField is a field written as runs between a begin and an end field
+// character, with its instruction before a separate character and its
+// result after it
+type complexField struct {
+	instr    string
+	begin    int // Index of the run of the begin character
+	separate int // Index of the run of the separate character, -1 if there is none
+	end      int // Index of the run of the end character
+}
+
+// complexFields returns the complex fields of runs, leaving out fields
+// nested in others
+func complexFields(runs []Run) []complexField {
+	var fields []complexField
+	var f complexField
+	depth := 0
+	for i, r := range runs {
+		if c := r.FldChar; c != nil {
+			switch c.Type {
+			case "begin":
+				if depth == 0 {
+					f = complexField{begin: i, separate: -1}
+				}
+				depth++
+			case "separate":
+				if depth == 1 && f.separate < 0 {
+					f.separate = i
+				}
+			case "end":
+				if depth == 0 {
+					continue
+				}
+				if depth--; depth == 0 {
+					f.end = i
+					fields = append(fields, f)
+				}
+			}
+		}
+		if depth == 1 && f.separate < 0 {
+			for _, it := range r.InstrText {
+				f.instr += it.Content
+			}
+		}
+	}
+	return fields
+}
+
+// fieldUpdate is a complex field with its new result
+type fieldUpdate struct {
+	field  complexField
+	result string
+}
+
+// updateFields updates the REF and SEQ fields of the paragraph, simple or
+// complex, in document order, as SEQ fields count, and returns the number
+// of fields updated
+func (p *Paragraph) updateFields(ctx *fieldContext) int {
+	updates := make(map[*[]Run][]fieldUpdate)
+	collect := func(runs *[]Run, fields []complexField) {
+		for _, f := range fields {
+			if result, ok := ctx.result(f.instr, p); ok {
+				updates[runs] = append(updates[runs], fieldUpdate{f, result})
+			}
+		}
+	}
+
+	count := 0
+	own := complexFields(p.Runs)
+	p.eachChild(func(i int) {
+		n := 0
+		for n < len(own) && own[n].begin == i {
+			n++
+		}
+		collect(&p.Runs, own[:n])
+		own = own[n:]
+	}, func(v interface{}) {
+		switch e := v.(type) {
+		case *Hyperlink:
+			collect(&e.Runs, complexFields(e.Runs))
+		case *SimpleField:
+			if result, ok := ctx.result(e.Instr, p); ok {
+				e.setResult(result)
+				count++
+			}
+		}
+	})
+
+	for _, runs := range p.complexRunSlices() {
+		count += p.updateComplexFields(runs, updates[runs])
+	}
+	return count
+}
+
+// updateComplexFields replaces the results of fields among runs with a
+// run of their new result, formatted as the old one was, and returns the
+// number of fields updated
+func (p *Paragraph) updateComplexFields(runs *[]Run, updates []fieldUpdate) int {
+	// From the last field, so the indexes of the others stay valid
+	for i := len(updates) - 1; i >= 0; i-- {
+		f := updates[i].field
+		head := (*runs)[f.begin:f.end]
+		props := (*runs)[f.begin].Props
+		if f.separate >= 0 {
+			head = (*runs)[f.begin : f.separate+1]
+			for _, r := range (*runs)[f.separate+1 : f.end] {
+				if len(r.Text) > 0 {
+					props = r.Props
+					break
+				}
+			}
+		} else {
+			head = append(head[:len(head):len(head)], Run{Props: props.clone(), FldChar: &FldChar{Type: "separate"}})
+		}
+		shown := Run{Props: props.clone(), Text: []Text{{Space: "preserve", Content: updates[i].result}}}
+		p.spliceRuns(runs, f.begin, f.end-f.begin, slices.Concat(head, []Run{shown})...)
+	}
+	return len(updates)
+}
+
+// flattenComplexFields replaces the complex fields among runs of the
+// paragraph that flatten selects with the runs of their result, adding
+// their number to count
+func (p *Paragraph) flattenComplexFields(runs *[]Run, flatten func(instr string) bool, count *int) {
+	fields := complexFields(*runs)
+
+	// From the last field, so the indexes of the others stay valid
+	for i := len(fields) - 1; i >= 0; i-- {
+		f := fields[i]
+		if !flatten(f.instr) {
+			continue
+		}
+		var result []Run
+		if f.separate >= 0 {
+			result = slices.Clone((*runs)[f.separate+1 : f.end])
+			p.flattenComplexFields(&result, flatten, count)
+		}
+		p.spliceRuns(runs, f.begin, f.end+1-f.begin, result...)
+		*count++
+	}
+}
@@ -0,0 +1,184 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestCrossReferences(t *testing.T) {
+	doc := New()
+	doc.SetPart("word/numbering.xml", []byte(`<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/><w:lvlText w:val="%1."/></w:lvl><w:lvl w:ilvl="1"><w:numFmt w:val="decimal"/><w:lvlText w:val="%1.%2."/></w:lvl></w:abstractNum>
+<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>
+</w:numbering>`))
+	heading := func(text, level string) {
+		doc.AddParagraph(text, WithStyle("Heading1"))
+		doc.Body.Paragraphs[len(doc.Body.Paragraphs)-1].Props.NumPr = &NumPr{ILvl: &NumLevel{Val: level}, NumID: &NumID{Val: "1"}}
+	}
+	heading("Scope", "0")
+	heading("Terms", "0")
+	heading("Payment", "1")
+	if err := doc.AddBookmark(2, "payment"); err != nil {
+		t.Fatalf("AddBookmark failed: %v", err)
+	}
+
+	doc.AddParagraph("See section ")
+	for _, format := range []CrossReferenceFormat{CrossReferenceNumber, CrossReferenceText, CrossReferencePosition, CrossReferencePage} {
+		if err := doc.AddCrossReference(3, 12, "payment", format); err != nil {
+			t.Fatalf("AddCrossReference(%s) failed: %v", format, err)
+		}
+	}
+	if err := doc.AddCrossReference(3, 0, "missing", CrossReferenceText); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing bookmark, got %v", err)
+	}
+	if err := doc.AddCrossReference(3, 0, "payment", "chapter"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat for an unknown format, got %v", err)
+	}
+	if err := doc.AddCrossReference(3, 100, "payment", CrossReferenceText); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange for an offset past the end, got %v", err)
+	}
+	if err := doc.AddCrossReference(9, 0, "payment", CrossReferenceText); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange for a missing paragraph, got %v", err)
+	}
+
+	fields := doc.Body.Paragraphs[3].Fields
+	want := []string{"2.1", "Payment", "above", ""}
+	for i, f := range fields {
+		if text := runsText(f.Runs); text != want[i] {
+			t.Errorf("Field %q: expected %q, got %q", f.Instr, want[i], text)
+		}
+	}
+	if fields[0].Instr != `REF payment \r \h` || fields[3].Instr != `PAGEREF payment \h` || fields[3].Dirty != "true" {
+		t.Errorf("Expected REF and PAGEREF fields, got %+v", fields)
+	}
+
+	// Inserting a section before it renumbers the reference once fields are updated
+	doc.Body.SpliceParagraphs(1, 0, doc.Body.Paragraphs[0])
+	doc.Body.Paragraphs[3].Runs[0].Text[0].Content = "Fees"
+	if got := doc.UpdateFields(); got != 3 {
+		t.Errorf("Expected 3 fields updated, got %d", got)
+	}
+	if text, _ := doc.GetParagraphText(4); text != "See section 3.1Feesabove" {
+		t.Errorf("Expected updated references, got %q", text)
+	}
+
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	reopened, err := ReadFrom(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadFrom failed: %v", err)
+	}
+	if got := reopened.FlattenFields("ref"); got != 3 {
+		t.Errorf("Expected 3 REF fields flattened, got %d", got)
+	}
+	p := reopened.Body.Paragraphs[4]
+	if len(p.Fields) != 1 || len(p.Runs) != 4 || runsText(p.Runs) != "See section 3.1Feesabove" {
+		t.Errorf("Expected the results as runs and the PAGEREF field kept, got %+v", p)
+	}
+}
+
+func TestComplexFields(t *testing.T) {
+	field := func(instr, result string) []Run {
+		return []Run{
+			{FldChar: &FldChar{Type: "begin"}},
+			{InstrText: []InstrText{{Content: instr}}},
+			{FldChar: &FldChar{Type: "separate"}},
+			{Props: &RProps{Bold: &Bold{}}, Text: []Text{{Content: result}}},
+			{FldChar: &FldChar{Type: "end"}},
+		}
+	}
+	doc := New()
+	doc.AddParagraph("Figure ")
+	doc.Body.Paragraphs[0].Runs = append(doc.Body.Paragraphs[0].Runs, field(` SEQ Figure \* ROMAN `, "7")...)
+	doc.AddParagraph("Figure ")
+	doc.Body.Paragraphs[1].Runs = append(doc.Body.Paragraphs[1].Runs, field(` SEQ Figure \* ROMAN `, "1")...)
+	doc.AddParagraph("Date: ")
+	doc.Body.Paragraphs[2].Runs = append(doc.Body.Paragraphs[2].Runs, field(` DATE `, "1 May")...)
+
+	if got := doc.UpdateFields(); got != 2 {
+		t.Errorf("Expected 2 fields updated, got %d", got)
+	}
+	for i, want := range []string{"Figure I", "Figure II"} {
+		if text, _ := doc.GetParagraphText(i); text != want {
+			t.Errorf("Expected %q, got %q", want, text)
+		}
+	}
+	if r := doc.Body.Paragraphs[0].Runs[4]; r.Props == nil || r.Props.Bold == nil {
+		t.Errorf("Expected the result to keep its formatting, got %+v", r)
+	}
+
+	if got := doc.FlattenFields(); got != 3 {
+		t.Errorf("Expected 3 fields flattened, got %d", got)
+	}
+	if runs := doc.Body.Paragraphs[2].Runs; len(runs) != 2 || runsText(runs) != "Date: 1 May" {
+		t.Errorf("Expected the field replaced by its result, got %+v", runs)
+	}
+}
+
+func TestFieldOrder(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Step ")
+	p := &doc.Body.Paragraphs[0]
+	p.Fields = []SimpleField{{Position: 1, Instr: "SEQ Step", Runs: []Run{{Text: []Text{{Content: "5"}}}}}}
+	p.Runs = append(p.Runs,
+		Run{Text: []Text{{Content: ", then step "}}},
+		Run{FldChar: &FldChar{Type: "begin"}},
+		Run{InstrText: []InstrText{{Content: " SEQ Step "}}},
+		Run{FldChar: &FldChar{Type: "separate"}},
+		Run{Text: []Text{{Content: "3"}}},
+		Run{FldChar: &FldChar{Type: "end"}},
+	)
+	doc.AddParagraph("As section  says", WithBold())
+	doc.AddParagraph("Scope")
+	if err := doc.AddBookmark(2, "scope"); err != nil {
+		t.Fatalf("AddBookmark failed: %v", err)
+	}
+	if err := doc.AddCrossReference(1, 11, "scope", CrossReferenceText); err != nil {
+		t.Fatalf("AddCrossReference failed: %v", err)
+	}
+
+	// Numbered in document order, not complex fields first
+	if got := doc.UpdateFields(); got != 3 {
+		t.Errorf("Expected 3 fields updated, got %d", got)
+	}
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	reopened, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+	want := []string{"Step 1, then step 2", "As section Scope says"}
+	for i, w := range want {
+		if text, _ := reopened.GetParagraphText(i); text != w {
+			t.Errorf("Paragraph %d: expected %q, got %q", i, w, text)
+		}
+	}
+
+	if got := reopened.FlattenFields(); got != 3 {
+		t.Errorf("Expected 3 fields flattened, got %d", got)
+	}
+	for i, w := range want {
+		if p := reopened.Body.Paragraphs[i]; len(p.Fields) != 0 || runsText(p.Runs) != w {
+			t.Errorf("Paragraph %d: expected the results in place, got %q", i, runsText(p.Runs))
+		}
+	}
+	if runs := reopened.Body.Paragraphs[1].Runs; len(runs) != 3 || runs[2].Props == nil || runs[2].Props.Bold == nil {
+		t.Errorf("Expected the split run to keep its formatting, got %+v", runs)
+	}
+}
+
+// runsText returns the text of runs
+func runsText(runs []Run) string {
+	var buf bytes.Buffer
+	for _, r := range runs {
+		for _, t := range r.Text {
+			buf.WriteString(t.Content)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,426 @@
+package docx
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultFontTablePart = "word/fontTable.xml"
+
+	relTypeFontTable = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/fontTable"
+	relTypeFont      = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/font"
+	relTypeSettings  = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/settings"
+
+	contentTypeFontTable      = "application/vnd.openxmlformats-officedocument.wordprocessingml.fontTable+xml"
+	contentTypeSettings       = "application/vnd.openxmlformats-officedocument.wordprocessingml.settings+xml"
+	contentTypeObfuscatedFont = "application/vnd.openxmlformats-officedocument.obfuscatedFont"
+)
+
+// FontStyle is one of the four font files Word embeds for a font
+type FontStyle string
+
+// Embedded font styles, in the order Word writes them
+const (
+	FontRegular    FontStyle = "Regular"
+	FontBold       FontStyle = "Bold"
+	FontItalic     FontStyle = "Italic"
+	FontBoldItalic FontStyle = "BoldItalic"
+)
+
+// FontStyles lists the embedded font styles
+var FontStyles = []FontStyle{FontRegular, FontBold, FontItalic, FontBoldItalic}
+
+// Font is a font listed in the document's font table
+type Font struct {
+	Name     string
+	AltName  string      // Name to try when Name is not installed
+	Family   string      // roman, swiss, modern, script, decorative or auto
+	Embedded []FontStyle // Styles whose font files travel with the document
+}
+
+var (
+	// fontsOpenTagPattern matches the root element of the font table
+	fontsOpenTagPattern = regexp.MustCompile(`<w:fonts\b[^>]*>`)
+
+	// settingsBeforeEmbedFonts matches the settings that come before
+	// w:embedTrueTypeFonts in the schema
+	settingsBeforeEmbedFonts = regexp.MustCompile(`<w:(?:writeProtection|view|zoom|removePersonalInformation|removeDateAndTime|doNotDisplayPageBoundaries|displayBackgroundShape|printPostScriptOverText|printFractionalCharacterWidth|printFormsData)\b[^>]*/>`)
+
+	settingsOpenTagPattern = regexp.MustCompile(`<w:settings\b[^>]*>`)
+)
+
+// fontTableXML is the content of the font table part
+type fontTableXML struct {
+	Fonts []fontXML `xml:"font"`
+}
+
+type fontXML struct {
+	Name            string        `xml:"name,attr"`
+	AltName         *fontValXML   `xml:"altName"`
+	Family          *fontValXML   `xml:"family"`
+	EmbedRegular    *fontEmbedXML `xml:"embedRegular"`
+	EmbedBold       *fontEmbedXML `xml:"embedBold"`
+	EmbedItalic     *fontEmbedXML `xml:"embedItalic"`
+	EmbedBoldItalic *fontEmbedXML `xml:"embedBoldItalic"`
+}
+
+type fontValXML struct {
+	Val string `xml:"val,attr"`
+}
+
+type fontEmbedXML struct {
+	ID      string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	FontKey string `xml:"fontKey,attr"`
+}
+
+// embed returns the embedded font file of a style, if any
+func (f fontXML) embed(style FontStyle) *fontEmbedXML {
+	switch style {
+	case FontRegular:
+		return f.EmbedRegular
+	case FontBold:
+		return f.EmbedBold
+	case FontItalic:
+		return f.EmbedItalic
+	case FontBoldItalic:
+		return f.EmbedBoldItalic
+	}
+	return nil
+}
+
+// Fonts returns the fonts listed in the document's font table, with the
+// styles embedded for each
+func (d *Document) Fonts() ([]Font, error) {
+	table, err := d.fontTable()
+	if err != nil {
+		return nil, err
+	}
+
+	fonts := make([]Font, 0, len(table.Fonts))
+	for _, f := range table.Fonts {
+		font := Font{Name: f.Name}
+		if f.AltName != nil {
+			font.AltName = f.AltName.Val
+		}
+		if f.Family != nil {
+			font.Family = f.Family.Val
+		}
+		for _, style := range FontStyles {
+			if f.embed(style) != nil {
+				font.Embedded = append(font.Embedded, style)
+			}
+		}
+		fonts = append(fonts, font)
+	}
+	return fonts, nil
+}
+
+// ExtractFont returns the embedded font file of a font and style, as the
+// plain TrueType or OpenType data Word obfuscates it from
+func (d *Document) ExtractFont(name string, style FontStyle) ([]byte, error) {
+	table, err := d.fontTable()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range table.Fonts {
+		if f.Name != name {
+			continue
+		}
+		embed := f.embed(style)
+		if embed == nil {
+			break
+		}
+		part, ok := d.fontPart(embed.ID)
+		if !ok {
+			return nil, fmt.Errorf("embedded font %q (%s) is missing from the package", name, style)
+		}
+		stored, _ := d.GetPart(part)
+		data := append([]byte(nil), stored...)
+		if embed.FontKey != "" {
+			if err := obfuscateFont(data, embed.FontKey); err != nil {
+				return nil, fmt.Errorf("embedded font %q (%s): %w", name, style, err)
+			}
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("font %q has no embedded %s style", name, style)
+}
+
+// EmbedFont embeds a TrueType or OpenType font file as the given style of
+// the named font, so the document renders in it on computers without the
+// font installed. The font is added to the font table if needed, and an
+// earlier file embedded for the same style is replaced.
+func (d *Document) EmbedFont(name string, style FontStyle, data []byte) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("font name is required")
+	}
+	if fontStyleIndex(style) < 0 {
+		return fmt.Errorf("unknown font style %q", style)
+	}
+	if !isFontData(data) {
+		return fmt.Errorf("%w: font data for %q is not TrueType or OpenType", ErrUnsupportedFormat, name)
+	}
+	if _, err := d.fontTable(); err != nil {
+		return err
+	}
+
+	tablePart := d.fontTablePart()
+	if _, ok := d.files[tablePart]; !ok {
+		d.SetPart(tablePart, []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:fonts xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"></w:fonts>`))
+		d.registerContentTypeOverride(tablePart, contentTypeFontTable)
+		d.addRelationship(relTypeFontTable, strings.TrimPrefix(tablePart, "word/"))
+	}
+
+	// The font key is derived from the data, so embedding the same file twice gives the same part
+	sum := sha1.Sum(data)
+	key := fmt.Sprintf("{%X-%X-%X-%X-%X}", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+	obfuscated := append([]byte(nil), data...)
+	if err := obfuscateFont(obfuscated, key); err != nil {
+		return err
+	}
+
+	n := 1
+	for {
+		if !d.hasPart(fmt.Sprintf("word/fonts/font%d.odttf", n)) {
+			break
+		}
+		n++
+	}
+	fontPart := fmt.Sprintf("word/fonts/font%d.odttf", n)
+	tableDir := path.Dir(tablePart)
+	d.SetPart(fontPart, obfuscated)
+	d.registerContentTypeDefault("odttf", contentTypeObfuscatedFont)
+	relID := d.addPartRelationship(tablePart, relTypeFont, strings.TrimPrefix(fontPart, tableDir+"/"))
+
+	embed := fmt.Sprintf(`<w:embed%s r:id="%s" w:fontKey="%s"/>`, style, relID, key)
+	d.files[tablePart] = d.setFontEmbed(d.files[tablePart], name, style, embed)
+	d.setEmbedTrueTypeFonts()
+	return nil
+}
+
+// fontTable parses the document's font table, which may be missing
+func (d *Document) fontTable() (*fontTableXML, error) {
+	table := &fontTableXML{}
+	data, ok := d.files[d.fontTablePart()]
+	if !ok {
+		return table, nil
+	}
+	if err := xml.Unmarshal(data, table); err != nil {
+		return nil, fmt.Errorf("failed to parse font table: %w", err)
+	}
+	return table, nil
+}
+
+// fontTablePart returns the name of the font table part
+func (d *Document) fontTablePart() string {
+	rels, _ := d.GetRelationships()
+	for _, rel := range rels {
+		if rel.Type == relTypeFontTable {
+			return resolvePartName(rel.Target)
+		}
+	}
+	return defaultFontTablePart
+}
+
+// fontPart returns the name of the font file a font table relationship targets
+func (d *Document) fontPart(relID string) (string, bool) {
+	tablePart := d.fontTablePart()
+	var rels Relationships
+	if err := xml.Unmarshal(d.files[relsPartName(tablePart)], &rels); err != nil {
+		return "", false
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID == relID {
+			part := relationshipTargetPart(path.Dir(tablePart), rel.Target)
+			return part, d.hasPart(part)
+		}
+	}
+	return "", false
+}
+
+// setFontEmbed writes the embed element of a style into the named font's
+// entry in the font table, adding the entry if needed. The file the entry
+// embedded for the style before is removed.
+func (d *Document) setFontEmbed(table []byte, name string, style FontStyle, embed string) []byte {
+	escapedName := xmlEscapedString(name)
+	fontPattern := regexp.MustCompile(`(?s)<w:font\s[^>]*\bw:name="` + regexp.QuoteMeta(escapedName) + `"[^>]*?(?:/>|>.*?</w:font>)`)
+	loc := fontPattern.FindIndex(table)
+	if loc == nil {
+		entry := fmt.Sprintf(`<w:font w:name="%s">%s</w:font>`, escapedName, embed)
+		return []byte(strings.Replace(string(table), "</w:fonts>", entry+"</w:fonts>", 1))
+	}
+
+	font := string(table[loc[0]:loc[1]])
+	if strings.HasSuffix(font, "/>") {
+		font = strings.TrimSuffix(font, "/>") + "></w:font>"
+	}
+
+	// Drop the file embedded before, then insert the element in schema order
+	stylePattern := regexp.MustCompile(`<w:embed` + string(style) + `\s[^>]*/>`)
+	if old := stylePattern.FindString(font); old != "" {
+		if m := regexp.MustCompile(`\br:id="([^"]*)"`).FindStringSubmatch(old); m != nil {
+			if part, ok := d.fontPart(m[1]); ok {
+				d.removePart(part)
+			}
+		}
+		font = stylePattern.ReplaceAllString(font, "")
+	}
+	at := strings.LastIndex(font, "</w:font>")
+	for _, later := range FontStyles {
+		if fontStyleIndex(later) <= fontStyleIndex(style) {
+			continue
+		}
+		if i := strings.Index(font, "<w:embed"+string(later)+" "); i >= 0 && i < at {
+			at = i
+		}
+	}
+	font = font[:at] + embed + font[at:]
+
+	result := make([]byte, 0, len(table)+len(embed))
+	result = append(result, table[:loc[0]]...)
+	result = append(result, font...)
+	result = append(result, table[loc[1]:]...)
+
+	// The r:id attributes need the relationships namespace declared
+	if open := fontsOpenTagPattern.Find(result); open != nil && !strings.Contains(string(open), "xmlns:r=") {
+		tag := strings.TrimSuffix(string(open), ">") + ` xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`
+		result = []byte(strings.Replace(string(result), string(open), tag, 1))
+	}
+	return result
+}
+
+// setEmbedTrueTypeFonts turns on the setting that tells Word to keep the
+// fonts embedded when it saves the document
+func (d *Document) setEmbedTrueTypeFonts() {
+	const setting = `<w:embedTrueTypeFonts/>`
+	settingsPart := ""
+	rels, _ := d.GetRelationships()
+	for _, rel := range rels {
+		if rel.Type == relTypeSettings {
+			settingsPart = resolvePartName(rel.Target)
+		}
+	}
+
+	data, ok := d.files[settingsPart]
+	if !ok {
+		settingsPart = "word/settings.xml"
+		d.SetPart(settingsPart, []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">`+setting+`</w:settings>`))
+		d.registerContentTypeOverride(settingsPart, contentTypeSettings)
+		d.addRelationship(relTypeSettings, "settings.xml")
+		return
+	}
+	if strings.Contains(string(data), "<w:embedTrueTypeFonts") {
+		return
+	}
+
+	at := -1
+	for _, loc := range settingsBeforeEmbedFonts.FindAllIndex(data, -1) {
+		at = loc[1]
+	}
+	if at < 0 {
+		loc := settingsOpenTagPattern.FindIndex(data)
+		if loc == nil {
+			return
+		}
+		at = loc[1]
+	}
+	result := make([]byte, 0, len(data)+len(setting))
+	result = append(result, data[:at]...)
+	result = append(result, setting...)
+	d.files[settingsPart] = append(result, data[at:]...)
+}
+
+// addPartRelationship adds a relationship to the relationships of any part
+// and returns its new ID
+func (d *Document) addPartRelationship(part, relType, target string) string {
+	relsName := relsPartName(part)
+	relsData, ok := d.files[relsName]
+	if !ok {
+		relsData = []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+</Relationships>`)
+	}
+
+	next := 1
+	for _, m := range relationshipIDPattern.FindAllSubmatch(relsData, -1) {
+		if n, err := strconv.Atoi(strings.TrimPrefix(string(m[1]), "rId")); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	relID := fmt.Sprintf("rId%d", next)
+	newRel := fmt.Sprintf(`	<Relationship Id="%s" Type="%s" Target="%s"/>`, relID, relType, target)
+	d.files[relsName] = []byte(strings.Replace(string(relsData), "</Relationships>", newRel+"\n</Relationships>", 1))
+	return relID
+}
+
+// registerContentTypeDefault adds a Default entry for an extension if it is not already registered
+func (d *Document) registerContentTypeDefault(ext, contentType string) {
+	contentTypesData, ok := d.files[contentTypesPart]
+	if !ok {
+		contentTypesData = getDefaultDocxFiles()[contentTypesPart]
+	}
+
+	contentTypesStr := string(contentTypesData)
+	if strings.Contains(contentTypesStr, fmt.Sprintf(`Extension="%s"`, ext)) {
+		return
+	}
+
+	newEntry := fmt.Sprintf(`	<Default Extension="%s" ContentType="%s"/>`, ext, contentType)
+	contentTypesStr = strings.Replace(contentTypesStr, "</Types>", newEntry+"\n</Types>", 1)
+	d.files[contentTypesPart] = []byte(contentTypesStr)
+}
+
+// relsPartName returns the name of the part holding a part's relationships
+func relsPartName(part string) string {
+	return path.Join(path.Dir(part), "_rels", path.Base(part)+".rels")
+}
+
+// obfuscateFont applies, or undoes, the obfuscation of an embedded font:
+// its first 32 bytes are XORed with the font key read backwards
+func obfuscateFont(data []byte, fontKey string) error {
+	digits := strings.NewReplacer("{", "", "}", "", "-", "").Replace(fontKey)
+	key, err := hex.DecodeString(digits)
+	if err != nil || len(key) != 16 {
+		return fmt.Errorf("invalid font key %q", fontKey)
+	}
+	if len(data) < 32 {
+		return fmt.Errorf("font data too short")
+	}
+	for i := 0; i < 32; i++ {
+		data[i] ^= key[15-i%16]
+	}
+	return nil
+}
+
+// isFontData reports whether data starts like a TrueType or OpenType font
+// file or collection
+func isFontData(data []byte) bool {
+	if len(data) < 32 {
+		return false
+	}
+	switch string(data[:4]) {
+	case "\x00\x01\x00\x00", "true", "OTTO", "ttcf":
+		return true
+	}
+	return false
+}
+
+// fontStyleIndex returns the position of a style in FontStyles, or -1
+func fontStyleIndex(style FontStyle) int {
+	for i, s := range FontStyles {
+		if s == style {
+			return i
+		}
+	}
+	return -1
+}
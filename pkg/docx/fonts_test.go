@@ -0,0 +1,116 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testFont returns data that looks like a TrueType font file
+func testFont(fill byte) []byte {
+	return append([]byte("\x00\x01\x00\x00"), bytes.Repeat([]byte{fill}, 60)...)
+}
+
+func TestEmbedFont(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Corporate text", WithFont("Corporate Sans"))
+
+	// Bold first, to check the embeds are still written in schema order
+	if err := doc.EmbedFont("Corporate Sans", FontBold, testFont('b')); err != nil {
+		t.Fatalf("EmbedFont failed: %v", err)
+	}
+	if err := doc.EmbedFont("Corporate Sans", FontRegular, testFont('r')); err != nil {
+		t.Fatalf("EmbedFont failed: %v", err)
+	}
+
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	doc2, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+
+	fonts, err := doc2.Fonts()
+	if err != nil {
+		t.Fatalf("Fonts failed: %v", err)
+	}
+	if len(fonts) != 1 || fonts[0].Name != "Corporate Sans" || len(fonts[0].Embedded) != 2 ||
+		fonts[0].Embedded[0] != FontRegular || fonts[0].Embedded[1] != FontBold {
+		t.Fatalf("Expected Corporate Sans with regular and bold embedded, got %+v", fonts)
+	}
+
+	// Word only reads obfuscated files, and the setting keeps them embedded on save
+	stored, _ := doc2.GetPart("word/fonts/font1.odttf")
+	if bytes.Equal(stored[:32], testFont('b')[:32]) || !bytes.Equal(stored[32:], testFont('b')[32:]) {
+		t.Errorf("Expected only the first 32 bytes to be obfuscated, got %q", stored)
+	}
+	table, _ := doc2.GetPart("word/fontTable.xml")
+	if strings.Index(string(table), "embedRegular") > strings.Index(string(table), "embedBold") {
+		t.Errorf("Expected embedRegular before embedBold, got %s", table)
+	}
+	settings, _ := doc2.GetPart("word/settings.xml")
+	types, _ := doc2.GetPart(contentTypesPart)
+	if !strings.Contains(string(settings), "<w:embedTrueTypeFonts/>") || !strings.Contains(string(types), `Extension="odttf"`) {
+		t.Errorf("Expected the embedding setting and content type, got %s and %s", settings, types)
+	}
+
+	for style, fill := range map[FontStyle]byte{FontRegular: 'r', FontBold: 'b'} {
+		font, err := doc2.ExtractFont("Corporate Sans", style)
+		if err != nil || !bytes.Equal(font, testFont(fill)) {
+			t.Errorf("Expected %s to extract unchanged, got %q, %v", style, font, err)
+		}
+	}
+	if _, err := doc2.ExtractFont("Corporate Sans", FontItalic); err == nil {
+		t.Error("Expected an error extracting a style that isn't embedded")
+	}
+
+	// Embedding a style again replaces its file
+	if err := doc2.EmbedFont("Corporate Sans", FontBold, testFont('B')); err != nil {
+		t.Fatalf("EmbedFont failed: %v", err)
+	}
+	if _, ok := doc2.GetPart("word/fonts/font1.odttf"); ok {
+		t.Error("Expected the replaced font file to be removed")
+	}
+	if font, _ := doc2.ExtractFont("Corporate Sans", FontBold); !bytes.Equal(font, testFont('B')) {
+		t.Errorf("Expected the new bold file, got %q", font)
+	}
+
+	if err := doc2.EmbedFont("Corporate Sans", FontRegular, []byte("<html>")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat for data that isn't a font, got %v", err)
+	}
+}
+
+func TestFontsFromWordFontTable(t *testing.T) {
+	doc := New()
+	doc.SetPart("word/fontTable.xml", []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:fonts xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:font w:name="Calibri"><w:panose1 w:val="020F0502020204030204"/><w:family w:val="swiss"/><w:pitch w:val="variable"/></w:font><w:font w:name="MS Mincho"><w:altName w:val="ＭＳ 明朝"/><w:family w:val="modern"/></w:font></w:fonts>`))
+	doc.SetPart("word/settings.xml", []byte(`<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:zoom w:percent="100"/><w:defaultTabStop w:val="720"/></w:settings>`))
+	doc.addRelationship(relTypeFontTable, "fontTable.xml")
+	doc.addRelationship(relTypeSettings, "settings.xml")
+
+	fonts, err := doc.Fonts()
+	if err != nil {
+		t.Fatalf("Fonts failed: %v", err)
+	}
+	if len(fonts) != 2 || fonts[0].Family != "swiss" || fonts[1].AltName != "ＭＳ 明朝" || len(fonts[0].Embedded) != 0 {
+		t.Fatalf("Unexpected fonts: %+v", fonts)
+	}
+
+	if err := doc.EmbedFont("Calibri", FontRegular, testFont('c')); err != nil {
+		t.Fatalf("EmbedFont failed: %v", err)
+	}
+	table, _ := doc.GetPart("word/fontTable.xml")
+	if !strings.Contains(string(table), `<w:pitch w:val="variable"/><w:embedRegular r:id="rId1"`) || !strings.Contains(string(table), "xmlns:r=") {
+		t.Errorf("Expected the embed after the existing font properties, got %s", table)
+	}
+	settings, _ := doc.GetPart("word/settings.xml")
+	if !strings.Contains(string(settings), `<w:zoom w:percent="100"/><w:embedTrueTypeFonts/><w:defaultTabStop`) {
+		t.Errorf("Expected the setting in schema order, got %s", settings)
+	}
+	if fonts, _ := doc.Fonts(); len(fonts) != 2 || len(fonts[0].Embedded) != 1 {
+		t.Errorf("Expected Calibri to be embedded, got %+v", fonts)
+	}
+}
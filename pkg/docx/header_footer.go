@@ -1,8 +1,13 @@
 package docx
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 )
 
 // HeaderFooterType represents the type of header or footer
@@ -278,3 +283,143 @@ func WithHFFont(font string) HeaderFooterOption {
 		config.Font = font
 	}
 }
+
+var (
+	titlePagePattern = regexp.MustCompile(`<(?:\w+:)?titlePg\b`)
+
+	// sectionAfterTitlePage matches the section properties that come after
+	// w:titlePg
+	sectionAfterTitlePage = regexp.MustCompile(`<(?:\w+:)?(?:textDirection|bidi|rtlGutter|docGrid|printerSettings|sectPrChange)\b`)
+)
+
+// serviceHeaderFooters returns the headers and footers set with SetHeader
+// and SetFooter, headers first and each in the order of their types
+func (d *Document) serviceHeaderFooters() []*HeaderFooter {
+	hfs, ok := d.headerFooterMgr.(*HeaderFooterService)
+	if !ok {
+		return nil
+	}
+	var list []*HeaderFooter
+	for _, set := range []map[HeaderFooterType]*HeaderFooter{hfs.headers, hfs.footers} {
+		types := make([]string, 0, len(set))
+		for t := range set {
+			types = append(types, string(t))
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			list = append(list, set[HeaderFooterType(t)])
+		}
+	}
+	return list
+}
+
+// referenceType returns the type of the section references of a header or
+// footer: default, first or even
+func (hf *HeaderFooter) referenceType() string {
+	t := string(hf.Type)
+	return t[strings.LastIndex(t, "-")+1:]
+}
+
+// writeHeaderFooterParts writes the headers and footers set with SetHeader
+// and SetFooter to the package: each replaces the part of its type in every
+// section, or becomes it in sections without one. First-page headers and
+// footers turn on a different first page; even-page ones show once
+// different odd and even pages are turned on in Word.
+func (d *Document) writeHeaderFooterParts() error {
+	list := d.serviceHeaderFooters()
+	if len(list) == 0 {
+		return nil
+	}
+	sections := d.sections()
+	for _, hf := range list {
+		data, err := marshalHeaderFooter(hf)
+		if err != nil {
+			return err
+		}
+		typ := hf.referenceType()
+		added := "" // Relationship of the part added for sections without one
+		for _, sectPr := range sections {
+			refs := headerReferences(sectPr)
+			if hf.IsFooter {
+				refs = footerReferences(sectPr)
+			}
+			relID, ok := refs[typ]
+			if !ok {
+				if added == "" {
+					added = d.addStoryPart(hf.IsFooter)
+				}
+				relID = added
+				addSectionReference(sectPr, hf.IsFooter, typ, relID)
+			}
+			if typ == "first" && !titlePagePattern.Match(sectPr.Inner) {
+				at := len(sectPr.Inner)
+				if loc := sectionAfterTitlePage.FindIndex(sectPr.Inner); loc != nil {
+					at = loc[0]
+				}
+				sectPr.Inner = slices.Concat(sectPr.Inner[:at:at], []byte("<w:titlePg/>"), sectPr.Inner[at:])
+			}
+			if rel, ok := d.findRelationship(relID); ok {
+				d.files[resolvePartName(rel.Target)] = data
+			}
+		}
+	}
+	return nil
+}
+
+// marshalHeaderFooter returns the part XML of a header or footer
+func marshalHeaderFooter(hf *HeaderFooter) ([]byte, error) {
+	content, err := marshalStory(&Body{Paragraphs: hf.Paragraphs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", hf.Type, err)
+	}
+	root := "hdr"
+	if hf.IsFooter {
+		root = "ftr"
+	}
+	namespaces := make([]string, 0, len(namespacePrefixes))
+	for ns, prefix := range namespacePrefixes {
+		if prefix != "xml" { // Bound without a declaration
+			namespaces = append(namespaces, fmt.Sprintf(` xmlns:%s="%s"`, prefix, ns))
+		}
+	}
+	sort.Strings(namespaces)
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString("<w:" + root + strings.Join(namespaces, "") + ">")
+	buf.Write(content)
+	buf.WriteString("</w:" + root + ">")
+	return buf.Bytes(), nil
+}
+
+// serviceHeaderFooterParts returns the parts the headers and footers set
+// with SetHeader and SetFooter are written to, which Walk visits through
+// the headers and footers themselves
+func (d *Document) serviceHeaderFooterParts() map[string]bool {
+	parts := make(map[string]bool)
+	list := d.serviceHeaderFooters()
+	if len(list) == 0 {
+		return parts
+	}
+	var sections []*RawElement
+	for i := range d.Body.Paragraphs {
+		if props := d.Body.Paragraphs[i].Props; props != nil && props.SectPr != nil {
+			sections = append(sections, props.SectPr)
+		}
+	}
+	if d.Body.SectPr != nil {
+		sections = append(sections, d.Body.SectPr)
+	}
+	for _, hf := range list {
+		for _, sectPr := range sections {
+			refs := headerReferences(sectPr)
+			if hf.IsFooter {
+				refs = footerReferences(sectPr)
+			}
+			if rel, ok := d.findRelationship(refs[hf.referenceType()]); ok {
+				parts[resolvePartName(rel.Target)] = true
+			}
+		}
+	}
+	return parts
+}
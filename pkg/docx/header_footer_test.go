@@ -1,6 +1,7 @@
 package docx
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -538,6 +539,53 @@ func (suite *HeaderFooterTestSuite) TestHeaderFooterOptions() {
 	}
 }
 
+// TestSavedAsParts tests that headers and footers are written to the package
+func (suite *HeaderFooterTestSuite) TestSavedAsParts() {
+	t := suite.T()
+	doc := suite.doc
+	doc.AddParagraph("Body")
+	require.NoError(t, doc.SetHeader(HeaderTypeDefault, "Draft"))
+	require.NoError(t, doc.SetFooter(FooterTypeFirst, "Cover footer", WithHFItalic()))
+
+	data, err := doc.ToBytes()
+	require.NoError(t, err)
+	saved, err := ReadFrom(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	header, ok := saved.GetPart("word/header1.xml")
+	require.True(t, ok, "header part missing")
+	assert.Contains(t, string(header), "Draft")
+	footer, ok := saved.GetPart("word/footer1.xml")
+	require.True(t, ok, "footer part missing")
+	assert.Contains(t, string(footer), "Cover footer")
+	assert.Contains(t, string(saved.SectionAt(0).Inner), "titlePg")
+	rel, ok := saved.findRelationship(footerReferences(saved.SectionAt(0))["first"])
+	require.True(t, ok, "first-page footer reference missing")
+	assert.Equal(t, "footer1.xml", rel.Target)
+
+	// Saving again rewrites the same parts
+	require.NoError(t, doc.SetHeader(HeaderTypeDefault, "Final"))
+	data, err = doc.ToBytes()
+	require.NoError(t, err)
+	saved, err = ReadFrom(bytes.NewReader(data))
+	require.NoError(t, err)
+	header, _ = saved.GetPart("word/header1.xml")
+	assert.Contains(t, string(header), "Final")
+	_, extra := saved.GetPart("word/header2.xml")
+	assert.False(t, extra, "expected no second header part")
+	assert.Len(t, headerReferences(saved.SectionAt(0)), 1)
+
+	// Walk visits the header once
+	headers := 0
+	require.NoError(t, doc.Walk(func(b Block, _ Path) error {
+		if b.Kind == HeaderBlock {
+			headers++
+		}
+		return nil
+	}))
+	assert.Equal(t, 1, headers)
+}
+
 // TestHeaderFooterService tests the service implementation directly
 func (suite *HeaderFooterTestSuite) TestHeaderFooterService() {
 	service := NewHeaderFooterService(suite.doc)
@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -16,6 +17,7 @@ import (
 type Drawing struct {
 	XMLName xml.Name `xml:"http://schemas.openxmlformats.org/wordprocessingml/2006/main drawing"`
 	Inline  *Inline  `xml:"http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing inline"`
+	Anchor  *Anchor  `xml:"http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing anchor"`
 }
 
 // Inline represents an inline drawing
@@ -71,6 +73,9 @@ type GraphicData struct {
 	XMLName xml.Name `xml:"http://schemas.openxmlformats.org/drawingml/2006/main graphicData"`
 	URI     string   `xml:"uri,attr"`
 	Pic     *Pic     `xml:"http://schemas.openxmlformats.org/drawingml/2006/picture pic"`
+	RelIds  *RelIds  `xml:"http://schemas.openxmlformats.org/drawingml/2006/diagram relIds"`
+	Wsp     *Wsp     `xml:"http://schemas.microsoft.com/office/word/2010/wordprocessingShape wsp"`
+	Chart   *ChartRef
 }
 
 // Pic represents a picture
@@ -175,101 +180,130 @@ func WithImageHeight(height int) ImageOption {
 
 // AddImage adds an image to the document
 func (d *Document) AddImage(imagePath string, opts ...ImageOption) error {
-	// Check if file exists first
-	if _, err := os.Stat(imagePath); os.IsNotExist(err) {
-		return fmt.Errorf("image file does not exist: %s", imagePath)
-	}
-
-	// Read image file once
-	imageData, err := os.ReadFile(imagePath)
+	imageData, err := readImageFile(imagePath)
 	if err != nil {
-		return fmt.Errorf("failed to read image file: %v", err)
-	}
-
-	// Validate image file
-	if err := d.validateImageFile(imagePath, imageData); err != nil {
 		return err
 	}
+	return d.insertImage(len(d.Body.Paragraphs), imagePath, imageData, opts)
+}
 
-	// Apply options
-	options := &ImageOptions{
-		Width:  200, // Default width
-		Height: 150, // Default height
-	}
-	for _, opt := range opts {
-		opt(options)
-	}
+// AddImageFromBytes adds an image held in memory to the document. The name
+// (e.g. "logo.png") gives the image format by its extension.
+func (d *Document) AddImageFromBytes(name string, imageData []byte, opts ...ImageOption) error {
+	return d.insertImage(len(d.Body.Paragraphs), name, imageData, opts)
+}
 
-	// Create image paragraph
-	p, err := d.createImageParagraph(imagePath, imageData, options)
+// AddImageFromReader adds an image read from r to the document. The name
+// (e.g. "logo.png") gives the image format by its extension.
+func (d *Document) AddImageFromReader(name string, r io.Reader, opts ...ImageOption) error {
+	imageData, err := io.ReadAll(r)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to read image data: %w", err)
 	}
-
-	// Add to document
-	d.Body.Paragraphs = append(d.Body.Paragraphs, *p)
-	return nil
+	return d.insertImage(len(d.Body.Paragraphs), name, imageData, opts)
 }
 
 // AddImageAt inserts an image at a specific paragraph index
 func (d *Document) AddImageAt(index int, imagePath string, opts ...ImageOption) error {
 	if index < 0 || index > len(d.Body.Paragraphs) {
-		return fmt.Errorf("index %d out of range", index)
+		return fmt.Errorf("index %d %w", index, ErrIndexOutOfRange)
 	}
 
+	imageData, err := readImageFile(imagePath)
+	if err != nil {
+		return err
+	}
+	return d.insertImage(index, imagePath, imageData, opts)
+}
+
+// readImageFile reads an image from disk
+func readImageFile(imagePath string) ([]byte, error) {
 	// Check if file exists first
 	if _, err := os.Stat(imagePath); os.IsNotExist(err) {
-		return fmt.Errorf("image file does not exist: %s", imagePath)
+		return nil, fmt.Errorf("image file does not exist: %s", imagePath)
 	}
 
-	// Read image file once
 	imageData, err := os.ReadFile(imagePath)
 	if err != nil {
-		return fmt.Errorf("failed to read image file: %v", err)
+		return nil, fmt.Errorf("failed to read image file: %v", err)
 	}
+	return imageData, nil
+}
 
+// insertImage validates an image and inserts a paragraph holding it at index
+func (d *Document) insertImage(index int, name string, imageData []byte, opts []ImageOption) error {
 	// Validate image file
-	if err := d.validateImageFile(imagePath, imageData); err != nil {
+	if err := d.validateImageFile(name, imageData); err != nil {
 		return err
 	}
 
 	// Apply options
 	options := &ImageOptions{
-		Width:  200,
-		Height: 150,
+		Width:  200, // Default width
+		Height: 150, // Default height
 	}
 	for _, opt := range opts {
 		opt(options)
 	}
 
 	// Create image paragraph
-	p, err := d.createImageParagraph(imagePath, imageData, options)
+	p, err := d.createImageParagraph(name, imageData, options)
 	if err != nil {
 		return err
 	}
 
 	// Insert at index
-	d.Body.Paragraphs = append(
-		d.Body.Paragraphs[:index],
-		append([]Paragraph{*p}, d.Body.Paragraphs[index:]...)...,
-	)
-
+	d.Body.SpliceParagraphs(index, 0, *p)
 	return nil
 }
 
 // GetImageCount returns the number of images in the document
 func (d *Document) GetImageCount() int {
 	count := 0
-	for _, p := range d.Body.Paragraphs {
-		for _, r := range p.Runs {
+	for i := range d.Body.Paragraphs {
+		d.Body.Paragraphs[i].forEachRun(func(r *Run) {
 			if r.Drawing != nil {
 				count++
 			}
-		}
+		})
 	}
 	return count
 }
 
+// Image is a picture placed in a run
+type Image struct {
+	Name string // Name given to the picture, usually its original file name
+	Part string // Package part holding the picture, e.g. "word/media/image1.png"
+	Data []byte
+
+	// Width and Height are the displayed size in EMUs (914400 per inch)
+	Width, Height int
+}
+
+// RunImage returns the picture drawn by a run of the body, or false if the
+// run holds no picture
+func (d *Document) RunImage(r *Run) (*Image, bool) {
+	blip := drawingBlip(r.Drawing)
+	if blip == nil || blip.Embed == "" {
+		return nil, false
+	}
+	rel, ok := d.findRelationship(blip.Embed)
+	if !ok {
+		return nil, false
+	}
+
+	img := &Image{Part: resolvePartName(rel.Target)}
+	img.Data, _ = d.GetPart(img.Part)
+	if docPr := r.Drawing.Inline.DocPr; docPr != nil {
+		img.Name = docPr.Name
+	}
+	if extent := r.Drawing.Inline.Extent; extent != nil {
+		img.Width, _ = strconv.Atoi(extent.Cx)
+		img.Height, _ = strconv.Atoi(extent.Cy)
+	}
+	return img, true
+}
+
 // validateImageFile validates the image format and content
 func (d *Document) validateImageFile(imagePath string, imageData []byte) error {
 	// Check file extension
@@ -277,20 +311,20 @@ func (d *Document) validateImageFile(imagePath string, imageData []byte) error {
 	supportedFormats := []string{".png", ".jpg", ".jpeg", ".gif", ".bmp", ".webp", ".svg", ".ico", ".tiff", ".tif", ".heic", ".heif"}
 
 	if !slices.Contains(supportedFormats, ext) {
-		return fmt.Errorf("unsupported image format: %s", ext)
+		return fmt.Errorf("%w: %s", ErrInvalidImageFormat, ext)
 	}
 
 	// Validate file content - check if it's actually an image
 	// Read enough bytes to validate all formats (WebP needs 12 bytes)
 	headerSize := min(12, len(imageData))
 	if headerSize < 8 {
-		return fmt.Errorf("image file too small to validate: %s", imagePath)
+		return fmt.Errorf("%w: image file too small to validate: %s", ErrInvalidImageFormat, imagePath)
 	}
 
 	// Check for common image magic numbers
 	header := imageData[:headerSize]
 	if !isValidImageHeader(header, ext) {
-		return fmt.Errorf("file does not appear to be a valid %s image", ext)
+		return fmt.Errorf("%w: file does not appear to be a valid %s image", ErrInvalidImageFormat, ext)
 	}
 
 	return nil
@@ -0,0 +1,793 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	styleBlockPattern     = regexp.MustCompile(`(?s)<w:style\b[^>]*?(?:/>|>.*?</w:style>)`)
+	styleIDPattern        = regexp.MustCompile(`w:styleId="([^"]*)"`)
+	styleRefPattern       = regexp.MustCompile(`(<w:(?:basedOn|next|link|numStyleLink|styleLink)\s+w:val=")([^"]*)(")`)
+	styleNamePattern      = regexp.MustCompile(`(<w:name\s+w:val=")([^"]*)(")`)
+	abstractNumPattern    = regexp.MustCompile(`(?s)<w:abstractNum\b[^>]*>.*?</w:abstractNum>`)
+	numPattern            = regexp.MustCompile(`(?s)<w:num\b[^>]*>.*?</w:num>`)
+	abstractNumIDPattern  = regexp.MustCompile(`(w:abstractNumId=")(\d+)(")`)
+	abstractNumRefPattern = regexp.MustCompile(`(<w:abstractNumId\s+w:val=")(\d+)(")`)
+	numIDPattern          = regexp.MustCompile(`(w:numId=")(\d+)(")`)
+	numRefPattern         = regexp.MustCompile(`(<w:numId\s+w:val=")(\d+)(")`)
+	usedStylePattern      = regexp.MustCompile(`<(?:w:)?(?:pStyle|rStyle|tblStyle|basedOn|next|link|numStyleLink|styleLink)\s+(?:w:)?val="([^"]*)"`)
+	usedNumPattern        = regexp.MustCompile(`<(?:w:)?numId\s+(?:w:)?val="(\d+)"`)
+	storyStyleRefPattern  = regexp.MustCompile(`(<w:(?:pStyle|rStyle|tblStyle)\s+w:val=")([^"]*)(")`)
+	storyReferencePattern = regexp.MustCompile(`<(?:\w+:)?(?:header|footer)Reference\b[^>]*>`)
+)
+
+// importMaps records how identifiers from a source document were renamed
+// while importing its content into the destination document
+type importMaps struct {
+	styles map[string]string // old styleId -> new styleId (only renamed styles)
+	nums   map[string]string // old numId -> new numId
+	rels   map[string]string // old image, hyperlink or chart relationship ID -> new relationship ID
+}
+
+// AppendDocument appends the body of src to the document, importing the styles,
+// numbering definitions and images referenced by the appended content.
+// Conflicting style definitions are renamed and numbering IDs are offset so the
+// appended content keeps its original appearance.
+func (d *Document) AppendDocument(src *Document) error {
+	return d.InsertDocument(len(d.Body.Paragraphs), src)
+}
+
+// InsertDocument inserts the body of src before the paragraph at index,
+// importing its styles, numbering definitions and images like AppendDocument.
+// Tables and content controls sitting right before that paragraph stay
+// before the inserted content. Sections ending inside src keep their
+// headers and footers, which are imported too.
+func (d *Document) InsertDocument(index int, src *Document) error {
+	_, err := d.insertDocument(index, src, false)
+	return err
+}
+
+// insertDocument inserts the body of src like InsertDocument. lastSection
+// also imports the headers and footers of the section properties of the
+// body of src, which the returned maps point importSection at.
+func (d *Document) insertDocument(index int, src *Document, lastSection bool) (*importMaps, error) {
+	if index < 0 || index > len(d.Body.Paragraphs) {
+		return nil, fmt.Errorf("paragraph index %d %w", index, ErrIndexOutOfRange)
+	}
+
+	// Importing recurses into nested content, so refuse pathological documents up front
+	if err := src.CheckDepth(DefaultMaxDepth); err != nil {
+		return nil, fmt.Errorf("cannot import document: %w", err)
+	}
+
+	maps := &importMaps{
+		styles: make(map[string]string),
+		nums:   make(map[string]string),
+		rels:   make(map[string]string),
+	}
+
+	d.importNumbering(src, maps)
+	d.importStyles(src, maps)
+
+	if err := d.importMedia(src, maps); err != nil {
+		return nil, err
+	}
+	d.importLinks(src, maps)
+	d.importCharts(src, maps)
+	d.importFonts(src)
+
+	var sections []*RawElement
+	for _, p := range src.Body.Paragraphs {
+		if p.Props != nil && p.Props.SectPr != nil {
+			sections = append(sections, p.Props.SectPr)
+		}
+	}
+	if lastSection && src.Body.SectPr != nil {
+		sections = append(sections, src.Body.SectPr)
+	}
+	d.importStories(src, sections, maps)
+
+	paras := make([]Paragraph, 0, len(d.Body.Paragraphs)+len(src.Body.Paragraphs))
+	paras = append(paras, d.Body.Paragraphs[:index]...)
+	for _, p := range src.Body.Paragraphs {
+		paras = append(paras, d.importParagraph(p, maps))
+	}
+	d.Body.Paragraphs = append(paras, d.Body.Paragraphs[index:]...)
+
+	inserted := len(src.Body.Paragraphs)
+	for i := range d.Body.Tables {
+		if d.Body.Tables[i].Position > index {
+			d.Body.Tables[i].Position += inserted
+		}
+	}
+	for i := range d.Body.SDTs {
+		if d.Body.SDTs[i].Position > index {
+			d.Body.SDTs[i].Position += inserted
+		}
+	}
+
+	for _, t := range src.Body.Tables {
+		t = d.importTable(t, maps)
+		t.Position += index
+		d.Body.Tables = append(d.Body.Tables, t)
+	}
+	for _, sdt := range src.Body.SDTs {
+		sdt = d.importSDT(sdt, maps)
+		sdt.Position += index
+		d.Body.SDTs = append(d.Body.SDTs, sdt)
+	}
+
+	return maps, nil
+}
+
+// CopyParagraphsFrom inserts a copy of the paragraphs of src from start to
+// end (inclusive), and the tables and content controls between them, before
+// the paragraph at index at. The styles and numbering definitions they use
+// come along, renamed or renumbered where they clash as with
+// AppendDocument, and so do their images, hyperlinks and charts. src is left
+// untouched, so a document can serve as a library of snippets.
+func (d *Document) CopyParagraphsFrom(src *Document, start, end, at int) error {
+	if at < 0 || at > len(d.Body.Paragraphs) {
+		return fmt.Errorf("paragraph index %d %w", at, ErrIndexOutOfRange)
+	}
+	part, err := src.ExtractRange(start, end)
+	if err != nil {
+		return err
+	}
+	if err := part.pruneDefinitions(); err != nil {
+		return err
+	}
+	return d.InsertDocument(at, part)
+}
+
+// CopyTableFrom inserts a copy of the table of src at tableIndex before the
+// paragraph at index at, bringing along what it uses like
+// CopyParagraphsFrom
+func (d *Document) CopyTableFrom(src *Document, tableIndex, at int) error {
+	if at < 0 || at > len(d.Body.Paragraphs) {
+		return fmt.Errorf("paragraph index %d %w", at, ErrIndexOutOfRange)
+	}
+	part, err := src.ExtractTable(tableIndex)
+	if err != nil {
+		return err
+	}
+	if err := part.pruneDefinitions(); err != nil {
+		return err
+	}
+	return d.InsertDocument(at, part)
+}
+
+// pruneDefinitions removes the styles and numbering definitions the body
+// doesn't use, directly or through other styles, so importing the document
+// only brings along what its content needs
+func (d *Document) pruneDefinitions() error {
+	documentXML, err := d.marshalDocument()
+	if err != nil {
+		return fmt.Errorf("failed to marshal document: %w", err)
+	}
+
+	if stylesData, ok := d.files[stylesPart]; ok {
+		blocks := make(map[string]string)
+		for _, block := range styleBlockPattern.FindAllString(string(stylesData), -1) {
+			blocks[styleID(block)] = block
+		}
+		used := make(map[string]bool)
+		queue := usedStylePattern.FindAllSubmatch(documentXML, -1)
+		for len(queue) > 0 {
+			id := string(queue[0][1])
+			queue = queue[1:]
+			if used[id] {
+				continue
+			}
+			used[id] = true
+			queue = append(queue, usedStylePattern.FindAllSubmatch([]byte(blocks[id]), -1)...)
+		}
+		d.files[stylesPart] = []byte(styleBlockPattern.ReplaceAllStringFunc(string(stylesData), func(block string) string {
+			if used[styleID(block)] {
+				return block
+			}
+			return ""
+		}))
+		documentXML = append(documentXML, d.files[stylesPart]...) // Styles can number paragraphs too
+	}
+
+	if numberingData, ok := d.files[numberingPart]; ok {
+		used := make(map[string]bool)
+		for _, m := range usedNumPattern.FindAllSubmatch(documentXML, -1) {
+			used[string(m[1])] = true
+		}
+		usedAbstract := make(map[string]bool)
+		numbering := numPattern.ReplaceAllStringFunc(string(numberingData), func(block string) string {
+			m := numIDPattern.FindStringSubmatch(block)
+			if m == nil || !used[m[2]] {
+				return ""
+			}
+			if ref := abstractNumRefPattern.FindStringSubmatch(block); ref != nil {
+				usedAbstract[ref[2]] = true
+			}
+			return block
+		})
+		numbering = abstractNumPattern.ReplaceAllStringFunc(numbering, func(block string) string {
+			if m := abstractNumIDPattern.FindStringSubmatch(block); m != nil && usedAbstract[m[2]] {
+				return block
+			}
+			return ""
+		})
+		d.files[numberingPart] = []byte(numbering)
+	}
+	return nil
+}
+
+// importNumbering merges numbering.xml from src, offsetting its IDs past the existing ones
+func (d *Document) importNumbering(src *Document, maps *importMaps) {
+	srcData, ok := src.files[numberingPart]
+	if !ok {
+		return
+	}
+
+	dstData, ok := d.files[numberingPart]
+	if !ok {
+		// Nothing to reconcile, adopt the source numbering as-is
+		d.files[numberingPart] = append([]byte(nil), srcData...)
+		if !d.hasRelationshipType(relTypeNumbering) {
+			d.addRelationship(relTypeNumbering, "numbering.xml")
+		}
+		d.registerContentTypeOverride(numberingPart, contentTypeNumbering)
+		return
+	}
+
+	dstStr := string(dstData)
+	absOffset := maxSubmatchInt(abstractNumIDPattern, dstStr) + 1
+	numOffset := max(maxSubmatchInt(numIDPattern, dstStr), 0)
+
+	absMap := make(map[string]string)
+	var abstracts strings.Builder
+	for _, block := range abstractNumPattern.FindAllString(string(srcData), -1) {
+		block = abstractNumIDPattern.ReplaceAllStringFunc(block, func(attr string) string {
+			parts := abstractNumIDPattern.FindStringSubmatch(attr)
+			id, _ := strconv.Atoi(parts[2])
+			newID := strconv.Itoa(id + absOffset)
+			absMap[parts[2]] = newID
+			return parts[1] + newID + parts[3]
+		})
+		abstracts.WriteString(block + "\n")
+	}
+
+	var nums strings.Builder
+	for _, block := range numPattern.FindAllString(string(srcData), -1) {
+		block = numIDPattern.ReplaceAllStringFunc(block, func(attr string) string {
+			parts := numIDPattern.FindStringSubmatch(attr)
+			id, _ := strconv.Atoi(parts[2])
+			newID := strconv.Itoa(id + numOffset)
+			maps.nums[parts[2]] = newID
+			return parts[1] + newID + parts[3]
+		})
+		block = replaceSubmatchValues(abstractNumRefPattern, block, absMap)
+		nums.WriteString(block + "\n")
+	}
+
+	// abstractNum definitions must precede num instances in numbering.xml
+	if loc := numPattern.FindStringIndex(dstStr); loc != nil {
+		dstStr = dstStr[:loc[0]] + abstracts.String() + dstStr[loc[0]:]
+	} else {
+		dstStr = strings.Replace(dstStr, "</w:numbering>", abstracts.String()+"</w:numbering>", 1)
+	}
+	dstStr = strings.Replace(dstStr, "</w:numbering>", nums.String()+"</w:numbering>", 1)
+
+	d.files[numberingPart] = []byte(dstStr)
+}
+
+// importStyles merges styles.xml from src. Styles already present with an identical
+// definition are reused, conflicting definitions are imported under a new ID.
+func (d *Document) importStyles(src *Document, maps *importMaps) {
+	srcData, ok := src.files[stylesPart]
+	if !ok {
+		return
+	}
+
+	dstData, ok := d.files[stylesPart]
+	if !ok {
+		d.files[stylesPart] = []byte(replaceSubmatchValues(numRefPattern, string(srcData), maps.nums))
+		if !d.hasRelationshipType(relTypeStyles) {
+			d.addRelationship(relTypeStyles, "styles.xml")
+		}
+		d.registerContentTypeOverride(stylesPart, contentTypeStyles)
+		return
+	}
+
+	existing := make(map[string]string)
+	for _, block := range styleBlockPattern.FindAllString(string(dstData), -1) {
+		if id := styleID(block); id != "" {
+			existing[id] = block
+		}
+	}
+
+	srcBlocks := styleBlockPattern.FindAllString(string(srcData), -1)
+	taken := make(map[string]bool)
+	for _, block := range srcBlocks {
+		taken[styleID(block)] = true
+	}
+
+	// First pass: pick new IDs for conflicting styles so references can be rewritten
+	for _, block := range srcBlocks {
+		id := styleID(block)
+		if dstBlock, ok := existing[id]; ok && strings.TrimSpace(dstBlock) != strings.TrimSpace(block) {
+			newID := uniqueStyleID(id, existing, taken)
+			taken[newID] = true
+			maps.styles[id] = newID
+		}
+	}
+
+	// Second pass: append new and renamed styles
+	var added strings.Builder
+	for _, block := range srcBlocks {
+		id := styleID(block)
+		newID, renamed := maps.styles[id]
+		if _, exists := existing[id]; exists && !renamed {
+			continue // identical definition already present
+		}
+
+		block = replaceSubmatchValues(styleRefPattern, block, maps.styles)
+		block = replaceSubmatchValues(numRefPattern, block, maps.nums)
+		if renamed {
+			block = strings.Replace(block, `w:styleId="`+id+`"`, `w:styleId="`+newID+`"`, 1)
+			block = styleNamePattern.ReplaceAllString(block, "${1}"+newID+"${3}")
+		}
+		added.WriteString(block + "\n")
+	}
+
+	dstStr := strings.Replace(string(dstData), "</w:styles>", added.String()+"</w:styles>", 1)
+	d.files[stylesPart] = []byte(dstStr)
+}
+
+// importMedia copies the images referenced by src body content into the document
+func (d *Document) importMedia(src *Document, maps *importMaps) error {
+	var refs []string
+	_ = src.WalkParagraphs(DefaultMaxDepth, func(p *Paragraph, depth int) error {
+		p.forEachRun(func(r *Run) {
+			if blip := drawingBlip(r.Drawing); blip != nil && blip.Embed != "" {
+				refs = append(refs, blip.Embed)
+			}
+		})
+		return nil
+	})
+
+	for _, relID := range refs {
+		if _, done := maps.rels[relID]; done {
+			continue
+		}
+
+		rel, ok := src.findRelationship(relID)
+		if !ok {
+			return fmt.Errorf("image relationship %s not found in source document", relID)
+		}
+
+		partName := resolvePartName(rel.Target)
+		data, ok := src.GetPart(partName)
+		if !ok {
+			return fmt.Errorf("image part %s not found in source document", partName)
+		}
+
+		ext := strings.ToLower(path.Ext(partName))
+		imageName := fmt.Sprintf("word/media/image%d%s", d.getNextImageID(), ext)
+		newRelID := fmt.Sprintf("rId%d", d.getNextRelationshipID())
+
+		d.files[imageName] = append([]byte(nil), data...)
+		d.registerImageContentType(ext)
+		d.addImageRelationship(newRelID, imageName)
+		maps.rels[relID] = newRelID
+	}
+
+	return nil
+}
+
+// importParagraph returns a copy of p with style, numbering and image references remapped
+func (d *Document) importParagraph(p Paragraph, maps *importMaps) Paragraph {
+	if p.Props != nil {
+		props := *p.Props
+		if props.Style != nil {
+			if newID, ok := maps.styles[props.Style.Val]; ok {
+				props.Style = &PStyle{Val: newID}
+			}
+		}
+		if props.NumPr != nil && props.NumPr.NumID != nil {
+			if newID, ok := maps.nums[props.NumPr.NumID.Val]; ok {
+				numPr := *props.NumPr
+				numPr.NumID = &NumID{Val: newID}
+				props.NumPr = &numPr
+			}
+		}
+		if props.SectPr != nil {
+			props.SectPr = importSection(props.SectPr, maps)
+		}
+		p.Props = &props
+	}
+
+	p.Runs = d.importRuns(p.Runs, maps)
+
+	if len(p.Hyperlinks) > 0 {
+		links := make([]Hyperlink, len(p.Hyperlinks))
+		for i, h := range p.Hyperlinks {
+			if newRelID, ok := maps.rels[h.ID]; ok {
+				h.ID = newRelID
+			}
+			h.Runs = d.importRuns(h.Runs, maps)
+			links[i] = h
+		}
+		p.Hyperlinks = links
+	}
+
+	if len(p.Fields) > 0 {
+		fields := make([]SimpleField, len(p.Fields))
+		for i, f := range p.Fields {
+			f.Runs = d.importRuns(f.Runs, maps)
+			fields[i] = f
+		}
+		p.Fields = fields
+	}
+
+	return p
+}
+
+// importRuns copies runs, pointing their images, charts and text boxes at
+// the imported parts
+func (d *Document) importRuns(src []Run, maps *importMaps) []Run {
+	if src == nil {
+		return nil
+	}
+	runs := make([]Run, len(src))
+	for i, r := range src {
+		if blip := drawingBlip(r.Drawing); blip != nil {
+			r.Drawing = d.importDrawing(r.Drawing, maps)
+		} else if chart := drawingChart(r.Drawing); chart != nil {
+			r.Drawing = d.importChartDrawing(r.Drawing, maps)
+		}
+		if len(r.textBoxes(true)) > 0 {
+			r = d.importTextBoxRun(r, maps)
+		}
+		r.Text = append([]Text(nil), r.Text...)
+		runs[i] = r
+	}
+	return runs
+}
+
+// importFonts embeds the fonts src embeds that the document doesn't, so
+// text set in them keeps its look. Fonts src can't extract are skipped.
+func (d *Document) importFonts(src *Document) {
+	table, err := src.fontTable()
+	if err != nil {
+		return
+	}
+	own, err := d.fontTable()
+	if err != nil {
+		return
+	}
+	embedded := make(map[string]bool)
+	for _, f := range own.Fonts {
+		for _, style := range FontStyles {
+			if f.embed(style) != nil {
+				embedded[f.Name+"\x00"+string(style)] = true
+			}
+		}
+	}
+	for _, f := range table.Fonts {
+		for _, style := range FontStyles {
+			if f.embed(style) == nil || embedded[f.Name+"\x00"+string(style)] {
+				continue
+			}
+			if data, err := src.ExtractFont(f.Name, style); err == nil {
+				_ = d.EmbedFont(f.Name, style, data)
+			}
+		}
+	}
+}
+
+// importLinks copies the external relationships of hyperlinks in src
+func (d *Document) importLinks(src *Document, maps *importMaps) {
+	_ = src.WalkParagraphs(DefaultMaxDepth, func(p *Paragraph, depth int) error {
+		for _, h := range p.Hyperlinks {
+			if h.ID == "" {
+				continue
+			}
+			if _, done := maps.rels[h.ID]; done {
+				continue
+			}
+			// A dangling link is kept as is rather than failing the whole import
+			if rel, ok := src.findRelationship(h.ID); ok {
+				maps.rels[h.ID] = d.addExternalRelationship(rel.Type, rel.Target)
+			}
+		}
+		return nil
+	})
+}
+
+// importStories copies the headers and footers the sections of src
+// reference, with the images and other parts they use, recording their new
+// relationship IDs in maps
+func (d *Document) importStories(src *Document, sections []*RawElement, maps *importMaps) {
+	for _, sectPr := range sections {
+		for _, footer := range []bool{false, true} {
+			refs := headerReferences(sectPr)
+			if footer {
+				refs = footerReferences(sectPr)
+			}
+			for _, relID := range refs {
+				if _, done := maps.rels[relID]; done {
+					continue
+				}
+				rel, ok := src.findRelationship(relID)
+				if !ok {
+					continue
+				}
+				name := resolvePartName(rel.Target)
+				data, ok := src.GetPart(name)
+				if !ok {
+					continue
+				}
+
+				newRelID := d.addStoryPart(footer)
+				newRel, _ := d.findRelationship(newRelID)
+				newName := resolvePartName(newRel.Target)
+				content := replaceSubmatchValues(storyStyleRefPattern, string(data), maps.styles)
+				content = replaceSubmatchValues(numRefPattern, content, maps.nums)
+				d.files[newName] = []byte(content)
+				d.importStoryRelationships(src, name, newName)
+				maps.rels[relID] = newRelID
+			}
+		}
+	}
+}
+
+// importStoryRelationships copies the relationships of a header or footer
+// part of src to its copy, along with the images and other parts they
+// point to. Images get new names; other parts, which headers and footers
+// rarely use, keep theirs and are only copied when the document lacks them.
+func (d *Document) importStoryRelationships(src *Document, name, newName string) {
+	relsName := func(part string) string {
+		return path.Join(path.Dir(part), "_rels", path.Base(part)+".rels")
+	}
+	data, ok := src.files[relsName(name)]
+	if !ok {
+		return
+	}
+
+	data = relationshipPattern.ReplaceAllFunc(data, func(rel []byte) []byte {
+		m := relationshipTargetPattern.FindSubmatch(rel)
+		if m == nil || bytes.Contains(rel, []byte(`TargetMode="External"`)) {
+			return rel
+		}
+		target := resolvePartName(string(m[1]))
+		content, ok := src.GetPart(target)
+		if !ok {
+			return rel
+		}
+
+		newTarget := target
+		if ext := strings.ToLower(path.Ext(target)); strings.HasPrefix(target, "word/media/") {
+			newTarget = fmt.Sprintf("word/media/image%d%s", d.getNextImageID(), ext)
+			d.registerImageContentType(ext)
+		} else if d.hasPart(target) {
+			return rel
+		}
+		d.files[newTarget] = append([]byte(nil), content...)
+		return bytes.Replace(rel, m[0], []byte(`Target="`+strings.TrimPrefix(newTarget, "word/")+`"`), 1)
+	})
+	d.files[relsName(newName)] = data
+}
+
+// importSection returns a copy of section properties with their header and
+// footer references pointing at the imported parts. References to parts
+// that weren't imported are dropped, since their IDs mean something else in
+// the document.
+func importSection(sectPr *RawElement, maps *importMaps) *RawElement {
+	section := *sectPr
+	section.Attrs = append([]xml.Attr(nil), sectPr.Attrs...)
+	section.Inner = storyReferencePattern.ReplaceAllFunc(sectPr.Inner, func(tag []byte) []byte {
+		m := referenceIDPattern.FindSubmatch(tag)
+		if m == nil {
+			return tag
+		}
+		newID, ok := maps.rels[string(m[1])]
+		if !ok {
+			return nil
+		}
+		return bytes.Replace(tag, m[0], bytes.Replace(m[0], m[1], []byte(newID), 1), 1)
+	})
+	return &section
+}
+
+// importTable returns a copy of t with its style and cell content remapped
+func (d *Document) importTable(t Table, maps *importMaps) Table {
+	if t.Props != nil && t.Props.Style != nil {
+		if newID, ok := maps.styles[t.Props.Style.Val]; ok {
+			props := *t.Props
+			props.Style = &TblStyle{Val: newID}
+			t.Props = &props
+		}
+	}
+
+	rows := make([]TblRow, len(t.Rows))
+	for i, row := range t.Rows {
+		cells := make([]TblCell, len(row.Cells))
+		for j, cell := range row.Cells {
+			content := make([]Paragraph, len(cell.Content))
+			for k, p := range cell.Content {
+				content[k] = d.importParagraph(p, maps)
+			}
+			cell.Content = content
+
+			if len(cell.Tables) > 0 {
+				tables := make([]Table, len(cell.Tables))
+				for k, nested := range cell.Tables {
+					tables[k] = d.importTable(nested, maps)
+				}
+				cell.Tables = tables
+			}
+			if len(cell.SDTs) > 0 {
+				sdts := make([]SDT, len(cell.SDTs))
+				for k, sdt := range cell.SDTs {
+					sdts[k] = d.importSDT(sdt, maps)
+				}
+				cell.SDTs = sdts
+			}
+			cells[j] = cell
+		}
+		row.Cells = cells
+		rows[i] = row
+	}
+	t.Rows = rows
+
+	return t
+}
+
+// importSDT returns a copy of a content control with its content remapped
+func (d *Document) importSDT(sdt SDT, maps *importMaps) SDT {
+	if sdt.Content == nil {
+		return sdt
+	}
+
+	content := &SDTContent{}
+	for _, p := range sdt.Content.Paragraphs {
+		content.Paragraphs = append(content.Paragraphs, d.importParagraph(p, maps))
+	}
+	for _, t := range sdt.Content.Tables {
+		content.Tables = append(content.Tables, d.importTable(t, maps))
+	}
+	for _, nested := range sdt.Content.SDTs {
+		content.SDTs = append(content.SDTs, d.importSDT(nested, maps))
+	}
+	sdt.Content = content
+	return sdt
+}
+
+// importTextBoxRun returns a deep copy of a run holding text boxes, with the
+// text box content remapped like body content
+func (d *Document) importTextBoxRun(r Run, maps *importMaps) Run {
+	// Text boxes hang off several layers of drawing markup; an XML round trip
+	// is the simplest way to copy all of it. Our own structures always marshal,
+	// so a failure here would only leave the run shared with the source.
+	data, err := xml.Marshal(r)
+	if err != nil {
+		return r
+	}
+	var copied Run
+	if err := xml.Unmarshal(data, &copied); err != nil {
+		return r
+	}
+
+	for _, tb := range copied.textBoxes(true) {
+		for i := range tb.Paragraphs {
+			tb.Paragraphs[i] = d.importParagraph(tb.Paragraphs[i], maps)
+		}
+		for i := range tb.Tables {
+			tb.Tables[i] = d.importTable(tb.Tables[i], maps)
+		}
+	}
+	return copied
+}
+
+// importDrawing copies an image drawing, pointing it at the imported relationship
+// and giving it a fresh drawing ID
+func (d *Document) importDrawing(drawing *Drawing, maps *importMaps) *Drawing {
+	inline := *drawing.Inline
+	graphic := *inline.Graphic
+	graphicData := *graphic.GraphicData
+	pic := *graphicData.Pic
+	blipFill := *pic.BlipFill
+	blip := *blipFill.Blip
+
+	if newRelID, ok := maps.rels[blip.Embed]; ok {
+		blip.Embed = newRelID
+	}
+
+	imageID := strconv.Itoa(d.getNextImageID())
+	if inline.DocPr != nil {
+		docPr := *inline.DocPr
+		docPr.ID = imageID
+		inline.DocPr = &docPr
+	}
+	if pic.NvPicPr != nil && pic.NvPicPr.CNvPr != nil {
+		nvPicPr := *pic.NvPicPr
+		cNvPr := *nvPicPr.CNvPr
+		cNvPr.ID = imageID
+		nvPicPr.CNvPr = &cNvPr
+		pic.NvPicPr = &nvPicPr
+	}
+
+	blipFill.Blip = &blip
+	pic.BlipFill = &blipFill
+	graphicData.Pic = &pic
+	graphic.GraphicData = &graphicData
+	inline.Graphic = &graphic
+
+	return &Drawing{Inline: &inline}
+}
+
+// drawingBlip returns the image reference of a drawing, or nil if it holds no picture
+func drawingBlip(drawing *Drawing) *Blip {
+	if drawing == nil || drawing.Inline == nil || drawing.Inline.Graphic == nil {
+		return nil
+	}
+	data := drawing.Inline.Graphic.GraphicData
+	if data == nil || data.Pic == nil || data.Pic.BlipFill == nil {
+		return nil
+	}
+	return data.Pic.BlipFill.Blip
+}
+
+// resolvePartName converts a relationship target of word/document.xml into a part name
+func resolvePartName(target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Clean(path.Join("word", target))
+}
+
+// styleID returns the w:styleId of a style block
+func styleID(block string) string {
+	if m := styleIDPattern.FindStringSubmatch(block); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// uniqueStyleID derives a style ID not used by either document
+func uniqueStyleID(id string, existing map[string]string, taken map[string]bool) string {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s_%d", id, n)
+		if _, ok := existing[candidate]; !ok && !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+// replaceSubmatchValues rewrites the value group (second submatch) of every match using mapping
+func replaceSubmatchValues(pattern *regexp.Regexp, s string, mapping map[string]string) string {
+	if len(mapping) == 0 {
+		return s
+	}
+	return pattern.ReplaceAllStringFunc(s, func(match string) string {
+		parts := pattern.FindStringSubmatch(match)
+		if newVal, ok := mapping[parts[2]]; ok {
+			return parts[1] + newVal + parts[3]
+		}
+		return match
+	})
+}
+
+// maxSubmatchInt returns the largest integer captured by the value group of pattern, or -1
+func maxSubmatchInt(pattern *regexp.Regexp, s string) int {
+	maxVal := -1
+	for _, m := range pattern.FindAllStringSubmatch(s, -1) {
+		if v, err := strconv.Atoi(m[2]); err == nil && v > maxVal {
+			maxVal = v
+		}
+	}
+	return maxVal
+}
@@ -0,0 +1,266 @@
+package docx
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const testStylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:style w:type="paragraph" w:styleId="Normal"><w:name w:val="Normal"/></w:style>
+<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/>%s</w:style>
+</w:styles>`
+
+const testNumberingXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/></w:lvl></w:abstractNum>
+<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>
+</w:numbering>`
+
+func newStyledDocument(headingProps string) *Document {
+	doc := New()
+	doc.SetPart(stylesPart, []byte(strings.Replace(testStylesXML, "%s", headingProps, 1)))
+	doc.SetPart(numberingPart, []byte(testNumberingXML))
+	doc.AddParagraph("Heading", WithStyle("Heading1"))
+	doc.AddParagraph("Item")
+	doc.Body.Paragraphs[1].Props = &PProps{NumPr: &NumPr{NumID: &NumID{Val: "1"}}}
+	return doc
+}
+
+func TestAppendDocumentStyles(t *testing.T) {
+	dst := New()
+	if err := dst.AppendDocument(newStyledDocument("")); err != nil {
+		t.Fatalf("AppendDocument failed: %v", err)
+	}
+
+	// Identical styles are reused
+	if err := dst.AppendDocument(newStyledDocument("")); err != nil {
+		t.Fatalf("AppendDocument failed: %v", err)
+	}
+	if got := dst.Body.Paragraphs[2].Props.Style.Val; got != "Heading1" {
+		t.Errorf("Expected identical style to be reused, got %s", got)
+	}
+
+	// Conflicting styles are renamed
+	if err := dst.AppendDocument(newStyledDocument(`<w:rPr><w:b/></w:rPr>`)); err != nil {
+		t.Fatalf("AppendDocument failed: %v", err)
+	}
+	if got := dst.Body.Paragraphs[4].Props.Style.Val; got != "Heading1_2" {
+		t.Errorf("Expected conflicting style to be renamed to Heading1_2, got %s", got)
+	}
+
+	styles, ok := dst.GetPart(stylesPart)
+	if !ok {
+		t.Fatal("Expected styles part to be imported")
+	}
+	if strings.Count(string(styles), `w:styleId="Heading1"`) != 1 {
+		t.Error("Expected a single Heading1 definition")
+	}
+	if !strings.Contains(string(styles), `w:styleId="Heading1_2"`) {
+		t.Error("Expected renamed Heading1_2 definition")
+	}
+	if !dst.hasRelationshipType(relTypeStyles) {
+		t.Error("Expected styles relationship to be registered")
+	}
+}
+
+func TestAppendDocumentNumbering(t *testing.T) {
+	dst := New()
+	for i := 0; i < 2; i++ {
+		if err := dst.AppendDocument(newStyledDocument("")); err != nil {
+			t.Fatalf("AppendDocument failed: %v", err)
+		}
+	}
+
+	if got := dst.Body.Paragraphs[1].Props.NumPr.NumID.Val; got != "1" {
+		t.Errorf("Expected first list to keep numId 1, got %s", got)
+	}
+	if got := dst.Body.Paragraphs[3].Props.NumPr.NumID.Val; got != "2" {
+		t.Errorf("Expected second list to use numId 2, got %s", got)
+	}
+
+	numbering, _ := dst.GetPart(numberingPart)
+	for _, want := range []string{`w:abstractNumId="1"`, `<w:num w:numId="2"><w:abstractNumId w:val="1"/>`} {
+		if !strings.Contains(string(numbering), want) {
+			t.Errorf("Expected numbering.xml to contain %s", want)
+		}
+	}
+}
+
+func TestAppendDocumentImages(t *testing.T) {
+	imagePath := createTestImageFile(t, "append_test.png", createPNGData())
+	defer os.Remove(imagePath)
+
+	src := New()
+	if err := src.AddImage(imagePath); err != nil {
+		t.Fatalf("AddImage failed: %v", err)
+	}
+
+	dst := New()
+	dst.AddParagraph("Existing")
+	if err := dst.AddImage(imagePath); err != nil {
+		t.Fatalf("AddImage failed: %v", err)
+	}
+	if err := dst.AppendDocument(src); err != nil {
+		t.Fatalf("AppendDocument failed: %v", err)
+	}
+
+	if dst.GetImageCount() != 2 {
+		t.Fatalf("Expected 2 images, got %d", dst.GetImageCount())
+	}
+
+	first := drawingBlip(dst.Body.Paragraphs[1].Runs[0].Drawing).Embed
+	second := drawingBlip(dst.Body.Paragraphs[2].Runs[0].Drawing).Embed
+	if first == second {
+		t.Errorf("Expected imported image to get a new relationship ID, both use %s", first)
+	}
+
+	rel, ok := dst.findRelationship(second)
+	if !ok {
+		t.Fatalf("Relationship %s not found", second)
+	}
+	if _, ok := dst.GetPart(resolvePartName(rel.Target)); !ok {
+		t.Errorf("Imported image part %s not found", rel.Target)
+	}
+
+	// The source document must not be modified
+	if got := drawingBlip(src.Body.Paragraphs[0].Runs[0].Drawing).Embed; got != "rId1" {
+		t.Errorf("Source drawing was modified, embed is %s", got)
+	}
+}
+
+func TestAppendDocumentLinkedImages(t *testing.T) {
+	src := New()
+	if err := src.AddImageFromBytes("logo.png", createPNGData()); err != nil {
+		t.Fatalf("AddImageFromBytes failed: %v", err)
+	}
+	p := &src.Body.Paragraphs[0]
+	p.Hyperlinks = []Hyperlink{{Anchor: "top", Runs: p.Runs}}
+	p.Runs = nil
+
+	dst := New()
+	if err := dst.AddImageFromBytes("logo.png", createPNGData()); err != nil {
+		t.Fatalf("AddImageFromBytes failed: %v", err)
+	}
+	if err := dst.AppendDocument(src); err != nil {
+		t.Fatalf("AppendDocument failed: %v", err)
+	}
+	if dst.GetImageCount() != 2 || dst.Stats().Images != 2 {
+		t.Fatalf("Expected 2 images, got %d", dst.GetImageCount())
+	}
+
+	embed := drawingBlip(dst.Body.Paragraphs[1].Hyperlinks[0].Runs[0].Drawing).Embed
+	if embed == drawingBlip(dst.Body.Paragraphs[0].Runs[0].Drawing).Embed {
+		t.Errorf("Expected the linked image to get a new relationship ID, got %s", embed)
+	}
+	rel, ok := dst.findRelationship(embed)
+	if !ok {
+		t.Fatalf("Relationship %s not found", embed)
+	}
+	if _, ok := dst.GetPart(resolvePartName(rel.Target)); !ok {
+		t.Errorf("Imported image part %s not found", rel.Target)
+	}
+}
+
+func TestInsertDocument(t *testing.T) {
+	dst := New()
+	dst.AddParagraph("Before")
+	dst.AddTable(1, 1).SetCellText(0, 0, "Existing")
+	dst.AddParagraph("After")
+
+	src := newStyledDocument("")
+	src.AddTable(1, 1).SetCellText(0, 0, "Inserted")
+
+	if err := dst.InsertDocument(1, src); err != nil {
+		t.Fatalf("InsertDocument failed: %v", err)
+	}
+
+	var order []string
+	for _, block := range dst.Body.content() {
+		switch b := block.(type) {
+		case *Paragraph:
+			order = append(order, b.Runs[0].Text[0].Content)
+		case *Table:
+			text, _ := b.GetCellText(0, 0)
+			order = append(order, "["+text+"]")
+		}
+	}
+	want := "Before [Existing] Heading Item [Inserted] After"
+	if got := strings.Join(order, " "); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if dst.Body.Paragraphs[1].Props.Style.Val != "Heading1" {
+		t.Error("Expected the inserted styles to be imported")
+	}
+
+	// The inserted text doesn't share storage with the source
+	dst.Body.Paragraphs[1].Runs[0].Text[0].Content = "Changed"
+	if src.Body.Paragraphs[0].Runs[0].Text[0].Content != "Heading" {
+		t.Error("Expected the source to be left untouched")
+	}
+
+	if err := dst.InsertDocument(99, src); err == nil {
+		t.Error("Expected error for out of range index")
+	}
+}
+
+func TestCopyParagraphsFrom(t *testing.T) {
+	imagePath := createTestImageFile(t, "copy_test.png", createPNGData())
+	defer os.Remove(imagePath)
+
+	// The unused Quote style and second list shouldn't be copied
+	src := newStyledDocument(`<w:rPr><w:b/></w:rPr>`)
+	styles, _ := src.GetPart(stylesPart)
+	src.SetPart(stylesPart, []byte(strings.Replace(string(styles), "</w:styles>",
+		`<w:style w:type="paragraph" w:styleId="Quote"><w:name w:val="Quote"/></w:style></w:styles>`, 1)))
+	src.SetPart(numberingPart, []byte(strings.Replace(testNumberingXML, "</w:numbering>",
+		`<w:abstractNum w:abstractNumId="1"><w:lvl w:ilvl="0"><w:numFmt w:val="bullet"/></w:lvl></w:abstractNum>
+<w:num w:numId="2"><w:abstractNumId w:val="1"/></w:num></w:numbering>`, 1)))
+	if err := src.AddImage(imagePath); err != nil {
+		t.Fatalf("AddImage failed: %v", err)
+	}
+	src.AddParagraph("Not copied", WithStyle("Quote"))
+
+	dst := newStyledDocument("")
+	if err := dst.CopyParagraphsFrom(src, 0, 2, 1); err != nil {
+		t.Fatalf("CopyParagraphsFrom failed: %v", err)
+	}
+
+	var texts []string
+	for i := 0; i < dst.GetParagraphCount(); i++ {
+		text, _ := dst.GetParagraphText(i)
+		texts = append(texts, text)
+	}
+	if got := strings.Join(texts, "|"); got != "Heading|Heading|Item||Item" {
+		t.Errorf("Unexpected paragraphs: %q", got)
+	}
+	if got := dst.Body.Paragraphs[1].Props.Style.Val; got != "Heading1_2" {
+		t.Errorf("Expected the clashing style to be renamed, got %s", got)
+	}
+	if got := dst.Body.Paragraphs[2].Props.NumPr.NumID.Val; got != "2" {
+		t.Errorf("Expected the list to be renumbered, got %s", got)
+	}
+	if dst.GetImageCount() != 1 {
+		t.Errorf("Expected the image to be copied, got %d images", dst.GetImageCount())
+	}
+
+	styles, _ = dst.GetPart(stylesPart)
+	if strings.Contains(string(styles), "Quote") || !strings.Contains(string(styles), `w:styleId="Heading1_2"`) {
+		t.Errorf("Expected only the used styles to be copied, got %s", styles)
+	}
+	numbering, _ := dst.GetPart(numberingPart)
+	if strings.Contains(string(numbering), "bullet") {
+		t.Errorf("Expected only the used list to be copied, got %s", numbering)
+	}
+	if src.GetParagraphCount() != 4 {
+		t.Error("Expected the source to be left untouched")
+	}
+
+	if err := dst.CopyParagraphsFrom(src, 0, 9, 0); err == nil {
+		t.Error("Expected error for an out of range source range")
+	}
+	if err := dst.CopyParagraphsFrom(src, 0, 0, 99); err == nil {
+		t.Error("Expected error for an out of range index")
+	}
+}
@@ -0,0 +1,128 @@
+package docx
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Lang is the proofing language of text, as a language tag such as en-US
+type Lang struct {
+	Val      string `xml:"val,attr,omitempty"`      // Latin text
+	EastAsia string `xml:"eastAsia,attr,omitempty"` // East Asian text
+	Bidi     string `xml:"bidi,attr,omitempty"`     // Right-to-left text
+}
+
+var (
+	// languageTagPattern matches language tags such as en, en-US or zh-Hant-TW
+	languageTagPattern = regexp.MustCompile(`^[A-Za-z]{2,8}(-[A-Za-z0-9]{1,8})*$`)
+
+	stylesOpenTagPattern = regexp.MustCompile(`<w:styles\b[^>]*>`)
+	rPrDefaultPattern    = regexp.MustCompile(`(?s)<w:rPrDefault\s*/>|<w:rPrDefault\b[^>]*>.*?</w:rPrDefault>`)
+	docDefaultsPattern   = regexp.MustCompile(`(?s)<w:docDefaults\s*/>|<w:docDefaults\b[^>]*>`)
+	defaultLangPattern   = regexp.MustCompile(`<w:lang\b[^>]*/>`)
+	langValPattern       = regexp.MustCompile(`\bw:val="([^"]*)"`)
+	rPrPattern           = regexp.MustCompile(`<w:rPr\s*/>|</w:rPr>`)
+)
+
+// SetLanguage sets the default proofing language of the document's text, so
+// Word checks spelling and grammar in that language instead of the
+// reader's own. lang is a language tag such as en-US or es-ES. Runs with a
+// language of their own, see WithLanguage, keep it.
+func (d *Document) SetLanguage(lang string) error {
+	if !languageTagPattern.MatchString(lang) {
+		return fmt.Errorf("invalid language tag %q", lang)
+	}
+	val := html.EscapeString(lang)
+
+	data, ok := d.files[stylesPart]
+	if !ok {
+		d.SetPart(stylesPart, []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:docDefaults><w:rPrDefault><w:rPr><w:lang w:val="`+val+`"/></w:rPr></w:rPrDefault></w:docDefaults></w:styles>`))
+		d.registerContentTypeOverride(stylesPart, contentTypeStyles)
+		if !d.hasRelationshipType(relTypeStyles) {
+			d.addRelationship(relTypeStyles, "styles.xml")
+		}
+		return nil
+	}
+
+	styles := string(data)
+	if loc := rPrDefaultPattern.FindStringIndex(styles); loc != nil {
+		d.files[stylesPart] = []byte(styles[:loc[0]] + setDefaultLang(styles[loc[0]:loc[1]], val) + styles[loc[1]:])
+		return nil
+	}
+
+	rPrDefault := `<w:rPrDefault><w:rPr><w:lang w:val="` + val + `"/></w:rPr></w:rPrDefault>`
+	if loc := docDefaultsPattern.FindStringIndex(styles); loc != nil {
+		// rPrDefault comes first among the document defaults
+		tag := styles[loc[0]:loc[1]]
+		if strings.HasSuffix(tag, "/>") {
+			rPrDefault = `<w:docDefaults>` + rPrDefault + `</w:docDefaults>`
+		} else {
+			rPrDefault = tag + rPrDefault
+		}
+		d.files[stylesPart] = []byte(styles[:loc[0]] + rPrDefault + styles[loc[1]:])
+		return nil
+	}
+
+	loc := stylesOpenTagPattern.FindStringIndex(styles)
+	if loc == nil {
+		return fmt.Errorf("styles part has no w:styles element")
+	}
+	d.files[stylesPart] = []byte(styles[:loc[1]] + `<w:docDefaults>` + rPrDefault + `</w:docDefaults>` + styles[loc[1]:])
+	return nil
+}
+
+// setDefaultLang sets the language in a w:rPrDefault element, keeping the
+// East Asian and right-to-left languages it gives
+func setDefaultLang(rPrDefault, val string) string {
+	if lang := defaultLangPattern.FindString(rPrDefault); lang != "" {
+		updated := langValPattern.ReplaceAllLiteralString(lang, `w:val="`+val+`"`)
+		if updated == lang {
+			updated = strings.Replace(lang, "<w:lang", `<w:lang w:val="`+val+`"`, 1)
+		}
+		return strings.Replace(rPrDefault, lang, updated, 1)
+	}
+
+	lang := `<w:lang w:val="` + val + `"/>`
+	if strings.HasSuffix(rPrDefault, "/>") {
+		return `<w:rPrDefault><w:rPr>` + lang + `</w:rPr></w:rPrDefault>`
+	}
+	if loc := rPrPattern.FindStringIndex(rPrDefault); loc != nil {
+		if strings.HasSuffix(rPrDefault[loc[0]:loc[1]], "/>") {
+			return rPrDefault[:loc[0]] + `<w:rPr>` + lang + `</w:rPr>` + rPrDefault[loc[1]:]
+		}
+		return rPrDefault[:loc[0]] + lang + rPrDefault[loc[0]:]
+	}
+	end := strings.LastIndex(rPrDefault, "</w:rPrDefault>")
+	return rPrDefault[:end] + `<w:rPr>` + lang + `</w:rPr>` + rPrDefault[end:]
+}
+
+// Language returns the default proofing language of the document's text,
+// or "" when it has none and Word uses the reader's own
+func (d *Document) Language() string {
+	rPrDefault := rPrDefaultPattern.Find(d.files[stylesPart])
+	lang := defaultLangPattern.Find(rPrDefault)
+	if m := langValPattern.FindSubmatch(lang); m != nil {
+		return html.UnescapeString(string(m[1]))
+	}
+	return ""
+}
+
+// WithLanguage sets the proofing language of the paragraph's runs, e.g.
+// en-US, overriding the document's language for a quote in another
+// language
+func WithLanguage(lang string) ParagraphOption {
+	return func(p *Paragraph) {
+		p.forEachRun(func(r *Run) {
+			if r.Props == nil {
+				r.Props = &RProps{}
+			}
+			if r.Props.Lang == nil {
+				r.Props.Lang = &Lang{}
+			}
+			r.Props.Lang.Val = lang
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetLanguage(t *testing.T) {
+	doc := New()
+	if lang := doc.Language(); lang != "" {
+		t.Fatalf("Expected no language for a new document, got %q", lang)
+	}
+	doc.AddParagraph("Hola", WithLanguage("es-ES"), WithBold())
+
+	if err := doc.SetLanguage("en-GB"); err != nil {
+		t.Fatalf("SetLanguage failed: %v", err)
+	}
+	if err := doc.SetLanguage("en-US"); err != nil {
+		t.Fatalf("SetLanguage failed: %v", err)
+	}
+	if err := doc.SetLanguage(`en"><x`); err == nil {
+		t.Error("Expected an error for an invalid language tag")
+	}
+
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	doc2, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+	if lang := doc2.Language(); lang != "en-US" {
+		t.Errorf("Expected en-US, got %q", lang)
+	}
+	if styles := string(doc2.files[stylesPart]); strings.Count(styles, "<w:lang") != 1 {
+		t.Errorf("Expected one default language, got %s", styles)
+	}
+	if !doc2.hasRelationshipType(relTypeStyles) {
+		t.Error("Expected a relationship to the new styles part")
+	}
+	run := doc2.Body.Paragraphs[0].Runs[0]
+	if run.Props == nil || run.Props.Lang == nil || run.Props.Lang.Val != "es-ES" || run.Props.Bold == nil {
+		t.Errorf("Expected the run to keep its language, got %+v", run.Props)
+	}
+}
+
+func TestSetLanguageExistingStyles(t *testing.T) {
+	tests := []struct {
+		name   string
+		styles string
+		want   string
+	}{
+		{
+			name:   "no defaults",
+			styles: `<w:styles xmlns:w="w"><w:style w:styleId="Normal"/></w:styles>`,
+			want:   `<w:styles xmlns:w="w"><w:docDefaults><w:rPrDefault><w:rPr><w:lang w:val="fr-FR"/></w:rPr></w:rPrDefault></w:docDefaults><w:style w:styleId="Normal"/></w:styles>`,
+		},
+		{
+			name:   "paragraph defaults only",
+			styles: `<w:styles xmlns:w="w"><w:docDefaults><w:pPrDefault/></w:docDefaults></w:styles>`,
+			want:   `<w:styles xmlns:w="w"><w:docDefaults><w:rPrDefault><w:rPr><w:lang w:val="fr-FR"/></w:rPr></w:rPrDefault><w:pPrDefault/></w:docDefaults></w:styles>`,
+		},
+		{
+			name:   "run defaults",
+			styles: `<w:styles xmlns:w="w"><w:docDefaults><w:rPrDefault><w:rPr><w:sz w:val="22"/></w:rPr></w:rPrDefault></w:docDefaults></w:styles>`,
+			want:   `<w:styles xmlns:w="w"><w:docDefaults><w:rPrDefault><w:rPr><w:sz w:val="22"/><w:lang w:val="fr-FR"/></w:rPr></w:rPrDefault></w:docDefaults></w:styles>`,
+		},
+		{
+			name:   "existing language",
+			styles: `<w:styles xmlns:w="w"><w:docDefaults><w:rPrDefault><w:rPr><w:lang w:val="en-US" w:eastAsia="ja-JP" w:bidi="ar-SA"/></w:rPr></w:rPrDefault></w:docDefaults></w:styles>`,
+			want:   `<w:styles xmlns:w="w"><w:docDefaults><w:rPrDefault><w:rPr><w:lang w:val="fr-FR" w:eastAsia="ja-JP" w:bidi="ar-SA"/></w:rPr></w:rPrDefault></w:docDefaults></w:styles>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := New()
+			doc.SetPart(stylesPart, []byte(tt.styles))
+			if err := doc.SetLanguage("fr-FR"); err != nil {
+				t.Fatalf("SetLanguage failed: %v", err)
+			}
+			if got := string(doc.files[stylesPart]); got != tt.want {
+				t.Errorf("Got %s\nwant %s", got, tt.want)
+			}
+			if lang := doc.Language(); lang != "fr-FR" {
+				t.Errorf("Expected fr-FR, got %q", lang)
+			}
+		})
+	}
+}
@@ -0,0 +1,452 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// mathNamespace is the namespace of Office Math (OMML) equations
+const mathNamespace = "http://schemas.openxmlformats.org/officeDocument/2006/math"
+
+// AddEquation appends a paragraph holding an equation, given either as
+// Office Math markup, an m:oMath or m:oMathPara element, or as LaTeX such
+// as `E = mc^2` or `x = \frac{-b \pm \sqrt{b^2 - 4ac}}{2a}`. The LaTeX
+// understood is what formulas mostly need: superscripts and subscripts,
+// fractions, roots, sums and integrals with limits, \left and \right
+// delimiters, \text, function names, Greek letters and common symbols.
+func (d *Document) AddEquation(latexOrOMML string) error {
+	var p Paragraph
+	if source := strings.TrimSpace(latexOrOMML); strings.HasPrefix(source, "<") {
+		math, err := parseOMML(source)
+		if err != nil {
+			return err
+		}
+		if math.XMLName.Local == "oMathPara" {
+			p.MathParas = append(p.MathParas, Equation{RawElement: math})
+		} else {
+			p.Math = append(p.Math, Equation{RawElement: math})
+		}
+	} else {
+		inner, err := latexToOMML(source)
+		if err != nil {
+			return err
+		}
+		p.Math = append(p.Math, Equation{RawElement: RawElement{XMLName: xml.Name{Space: mathNamespace, Local: "oMath"}, Inner: []byte(inner)}})
+	}
+	d.Body.Paragraphs = append(d.Body.Paragraphs, p)
+	return nil
+}
+
+// parseOMML parses a single m:oMath or m:oMathPara element. The m and w
+// prefixes need not be declared.
+func parseOMML(source string) (RawElement, error) {
+	var root struct {
+		Elements []RawElement `xml:",any"`
+	}
+	wrapped := `<root xmlns:m="` + mathNamespace + `" xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` + source + `</root>`
+	if err := xml.Unmarshal([]byte(wrapped), &root); err != nil {
+		return RawElement{}, fmt.Errorf("invalid equation markup: %w", err)
+	}
+	if len(root.Elements) != 1 || root.Elements[0].XMLName.Space != mathNamespace ||
+		(root.Elements[0].XMLName.Local != "oMath" && root.Elements[0].XMLName.Local != "oMathPara") {
+		return RawElement{}, fmt.Errorf("invalid equation markup: expected one m:oMath or m:oMathPara element")
+	}
+	return root.Elements[0], nil
+}
+
+// latexSymbols are the LaTeX commands written as a single character
+var latexSymbols = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ", "varepsilon": "ε",
+	"zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ",
+	"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "pi": "π", "varpi": "ϖ", "rho": "ρ",
+	"sigma": "σ", "tau": "τ", "upsilon": "υ", "phi": "ϕ", "varphi": "φ", "chi": "χ",
+	"psi": "ψ", "omega": "ω", "Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ",
+	"Xi": "Ξ", "Pi": "Π", "Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+
+	"times": "×", "cdot": "⋅", "div": "÷", "pm": "±", "mp": "∓", "leq": "≤", "le": "≤",
+	"geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠", "approx": "≈", "equiv": "≡", "sim": "∼",
+	"propto": "∝", "infty": "∞", "partial": "∂", "nabla": "∇", "to": "→", "rightarrow": "→",
+	"leftarrow": "←", "Rightarrow": "⇒", "Leftarrow": "⇐", "leftrightarrow": "↔",
+	"Leftrightarrow": "⇔", "in": "∈", "notin": "∉", "subset": "⊂", "subseteq": "⊆",
+	"supset": "⊃", "supseteq": "⊇", "cup": "∪", "cap": "∩", "forall": "∀", "exists": "∃",
+	"cdots": "⋯", "ldots": "…", "dots": "…", "prime": "′", "circ": "∘", "emptyset": "∅",
+	"neg": "¬", "wedge": "∧", "vee": "∨", "langle": "⟨", "rangle": "⟩", "ll": "≪", "gg": "≫",
+
+	",": " ", ":": " ", ";": " ", "!": "", " ": " ", "quad": " ", "qquad": "  ",
+	"{": "{", "}": "}", "%": "%", "$": "$", "#": "#", "&": "&", "_": "_", "|": "‖",
+}
+
+// latexOperators are the large operators written with limits below and
+// above, or beside for integrals
+var latexOperators = map[string]string{
+	"sum": "∑", "prod": "∏", "coprod": "∐", "bigcup": "⋃", "bigcap": "⋂",
+	"int": "∫", "iint": "∬", "iiint": "∭", "oint": "∮",
+}
+
+// latexFunctions are the function names set upright; those in limitFunctions
+// take their subscript below
+var (
+	latexFunctions = map[string]bool{
+		"sin": true, "cos": true, "tan": true, "cot": true, "sec": true, "csc": true,
+		"arcsin": true, "arccos": true, "arctan": true, "sinh": true, "cosh": true, "tanh": true,
+		"log": true, "ln": true, "lg": true, "exp": true, "det": true, "gcd": true, "arg": true,
+		"deg": true, "dim": true, "ker": true, "Pr": true,
+	}
+	limitFunctions = map[string]bool{"lim": true, "max": true, "min": true, "sup": true, "inf": true}
+)
+
+// mathPiece is a part of an equation: a run of text in a style ("" for
+// math italic, "p" for upright, "nor" for normal text), or other markup
+type mathPiece struct {
+	text   string
+	style  string
+	markup string
+	isRun  bool
+}
+
+// latexParser converts LaTeX to the content of an m:oMath element
+type latexParser struct {
+	src []rune
+	pos int
+}
+
+// latexToOMML converts a LaTeX formula to Office Math markup
+func latexToOMML(latex string) (string, error) {
+	p := &latexParser{src: []rune(latex)}
+	return p.expr("")
+}
+
+// expr converts the formula up to end: "}", "]", `\right`, or "" for the
+// end of the source
+func (p *latexParser) expr(end string) (string, error) {
+	var pieces []mathPiece
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			if end != "" {
+				return "", fmt.Errorf("invalid LaTeX: missing %s", end)
+			}
+			break
+		}
+		if end != "" && p.consume(end) {
+			break
+		}
+		switch c := p.src[p.pos]; c {
+		case '}', ']':
+			if c == ']' && end != "]" {
+				pieces = append(pieces, mathPiece{text: "]", isRun: true})
+				p.pos++
+				continue
+			}
+			return "", fmt.Errorf("invalid LaTeX: unexpected %c", c)
+		case '^', '_':
+			// Scripts without a base
+			piece, err := p.scripts(mathPiece{})
+			if err != nil {
+				return "", err
+			}
+			pieces = append(pieces, piece)
+			continue
+		}
+
+		piece, err := p.atom()
+		if err != nil {
+			return "", err
+		}
+		if piece, err = p.scripts(piece); err != nil {
+			return "", err
+		}
+		pieces = append(pieces, piece)
+	}
+	return joinMathPieces(pieces), nil
+}
+
+// atom converts the next character, group or command
+func (p *latexParser) atom() (mathPiece, error) {
+	c := p.src[p.pos]
+	switch {
+	case c == '{':
+		p.pos++
+		inner, err := p.expr("}")
+		return mathPiece{markup: inner}, err
+	case c != '\\':
+		p.pos++
+		return mathPiece{text: string(c), isRun: true}, nil
+	}
+
+	name := p.command()
+	if symbol, ok := latexSymbols[name]; ok {
+		return mathPiece{text: symbol, isRun: true}, nil
+	}
+	if chr, ok := latexOperators[name]; ok {
+		return p.operator(chr, !strings.HasSuffix(name, "int"))
+	}
+	if latexFunctions[name] || limitFunctions[name] {
+		return mathPiece{text: name, style: "p", isRun: true}, nil
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac":
+		num, err := p.argument()
+		if err != nil {
+			return mathPiece{}, err
+		}
+		den, err := p.argument()
+		if err != nil {
+			return mathPiece{}, err
+		}
+		return mathPiece{markup: "<m:f><m:num>" + num + "</m:num><m:den>" + den + "</m:den></m:f>"}, nil
+	case "sqrt":
+		degree := ""
+		p.skipSpace()
+		if p.consume("[") {
+			var err error
+			if degree, err = p.expr("]"); err != nil {
+				return mathPiece{}, err
+			}
+		}
+		radicand, err := p.argument()
+		if err != nil {
+			return mathPiece{}, err
+		}
+		if degree == "" {
+			return mathPiece{markup: `<m:rad><m:radPr><m:degHide m:val="1"/></m:radPr><m:deg/><m:e>` + radicand + "</m:e></m:rad>"}, nil
+		}
+		return mathPiece{markup: "<m:rad><m:deg>" + degree + "</m:deg><m:e>" + radicand + "</m:e></m:rad>"}, nil
+	case "left":
+		open, err := p.delimiter()
+		if err != nil {
+			return mathPiece{}, err
+		}
+		inner, err := p.expr(`\right`)
+		if err != nil {
+			return mathPiece{}, err
+		}
+		closing, err := p.delimiter()
+		if err != nil {
+			return mathPiece{}, err
+		}
+		return mathPiece{markup: `<m:d><m:dPr><m:begChr m:val="` + escapeMath(open) + `"/><m:endChr m:val="` + escapeMath(closing) + `"/></m:dPr><m:e>` + inner + "</m:e></m:d>"}, nil
+	case "text", "mathrm", "operatorname":
+		p.skipSpace()
+		if !p.consume("{") {
+			return mathPiece{}, fmt.Errorf("invalid LaTeX: \\%s needs an argument in braces", name)
+		}
+		start := p.pos
+		for p.pos < len(p.src) && p.src[p.pos] != '}' {
+			p.pos++
+		}
+		if p.pos >= len(p.src) {
+			return mathPiece{}, fmt.Errorf("invalid LaTeX: missing }")
+		}
+		text := string(p.src[start:p.pos])
+		p.pos++
+		style := "p"
+		if name == "text" {
+			style = "nor"
+		}
+		return mathPiece{text: text, style: style, isRun: true}, nil
+	}
+	return mathPiece{}, fmt.Errorf("unsupported LaTeX command \\%s", name)
+}
+
+// command reads the name of a command after its backslash: letters, or a
+// single other character
+func (p *latexParser) command() string {
+	p.pos++ // The backslash
+	start := p.pos
+	for p.pos < len(p.src) && unicode.IsLetter(p.src[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start && p.pos < len(p.src) {
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+// scripts adds the superscript and subscript following base, if any. Limit
+// functions such as lim take their subscript below.
+func (p *latexParser) scripts(base mathPiece) (mathPiece, error) {
+	var sub, sup string
+	hasSub, hasSup := false, false
+	for {
+		p.skipSpace()
+		var err error
+		switch {
+		case !hasSub && p.consume("_"):
+			hasSub = true
+			sub, err = p.argument()
+		case !hasSup && p.consume("^"):
+			hasSup = true
+			sup, err = p.argument()
+		default:
+			e := base.xml()
+			switch {
+			case hasSub && !hasSup && base.isRun && limitFunctions[base.text]:
+				return mathPiece{markup: "<m:limLow><m:e>" + e + "</m:e><m:lim>" + sub + "</m:lim></m:limLow>"}, nil
+			case hasSub && hasSup:
+				return mathPiece{markup: "<m:sSubSup><m:e>" + e + "</m:e><m:sub>" + sub + "</m:sub><m:sup>" + sup + "</m:sup></m:sSubSup>"}, nil
+			case hasSub:
+				return mathPiece{markup: "<m:sSub><m:e>" + e + "</m:e><m:sub>" + sub + "</m:sub></m:sSub>"}, nil
+			case hasSup:
+				return mathPiece{markup: "<m:sSup><m:e>" + e + "</m:e><m:sup>" + sup + "</m:sup></m:sSup>"}, nil
+			}
+			return base, nil
+		}
+		if err != nil {
+			return mathPiece{}, err
+		}
+	}
+}
+
+// operator converts a large operator such as a sum with its limits and the
+// term after it
+func (p *latexParser) operator(chr string, limitsBelow bool) (mathPiece, error) {
+	var sub, sup string
+	hasSub, hasSup := false, false
+	for {
+		p.skipSpace()
+		var err error
+		if !hasSub && p.consume("_") {
+			hasSub = true
+			sub, err = p.argument()
+		} else if !hasSup && p.consume("^") {
+			hasSup = true
+			sup, err = p.argument()
+		} else {
+			break
+		}
+		if err != nil {
+			return mathPiece{}, err
+		}
+	}
+
+	// The operator applies to the term after it
+	term := ""
+	p.skipSpace()
+	if p.pos < len(p.src) && !strings.ContainsRune("}]", p.src[p.pos]) && !p.peek(`\right`) {
+		piece, err := p.atom()
+		if err == nil {
+			piece, err = p.scripts(piece)
+		}
+		if err != nil {
+			return mathPiece{}, err
+		}
+		term = piece.xml()
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<m:nary><m:naryPr><m:chr m:val="` + chr + `"/>`)
+	if limitsBelow {
+		sb.WriteString(`<m:limLoc m:val="undOvr"/>`)
+	} else {
+		sb.WriteString(`<m:limLoc m:val="subSup"/>`)
+	}
+	if !hasSub {
+		sb.WriteString(`<m:subHide m:val="1"/>`)
+	}
+	if !hasSup {
+		sb.WriteString(`<m:supHide m:val="1"/>`)
+	}
+	sb.WriteString("</m:naryPr><m:sub>" + sub + "</m:sub><m:sup>" + sup + "</m:sup><m:e>" + term + "</m:e></m:nary>")
+	return mathPiece{markup: sb.String()}, nil
+}
+
+// argument converts the argument of a command or script: a group in
+// braces, or a single character or command
+func (p *latexParser) argument() (string, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return "", fmt.Errorf("invalid LaTeX: missing argument")
+	}
+	if p.consume("{") {
+		return p.expr("}")
+	}
+	piece, err := p.atom()
+	return piece.xml(), err
+}
+
+// delimiter reads the delimiter after \left or \right: a character, an
+// escaped brace or bar, \langle or \rangle, or "." for none
+func (p *latexParser) delimiter() (string, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return "", fmt.Errorf("invalid LaTeX: missing delimiter")
+	}
+	c := p.src[p.pos]
+	switch {
+	case c == '.':
+		p.pos++
+		return "", nil
+	case c == '\\':
+		name := p.command()
+		if symbol, ok := latexSymbols[name]; ok && (len([]rune(name)) == 1 || name == "langle" || name == "rangle") {
+			return symbol, nil
+		}
+		return "", fmt.Errorf("invalid LaTeX: unsupported delimiter \\%s", name)
+	}
+	p.pos++
+	return string(c), nil
+}
+
+func (p *latexParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// peek reports whether the source continues with s
+func (p *latexParser) peek(s string) bool {
+	return strings.HasPrefix(string(p.src[p.pos:]), s)
+}
+
+// consume skips s if the source continues with it, reporting whether it did
+func (p *latexParser) consume(s string) bool {
+	if !p.peek(s) {
+		return false
+	}
+	p.pos += len([]rune(s))
+	return true
+}
+
+// xml returns the markup of a piece
+func (piece mathPiece) xml() string {
+	if !piece.isRun {
+		return piece.markup
+	}
+	var sb strings.Builder
+	sb.WriteString("<m:r>")
+	switch piece.style {
+	case "p":
+		sb.WriteString(`<m:rPr><m:sty m:val="p"/></m:rPr>`)
+	case "nor":
+		sb.WriteString(`<m:rPr><m:nor/></m:rPr>`)
+	}
+	sb.WriteString(`<m:t xml:space="preserve">` + escapeMath(piece.text) + "</m:t></m:r>")
+	return sb.String()
+}
+
+// joinMathPieces returns the markup of pieces, with neighbouring runs of
+// one style merged
+func joinMathPieces(pieces []mathPiece) string {
+	var sb strings.Builder
+	for i := 0; i < len(pieces); i++ {
+		piece := pieces[i]
+		for piece.isRun && i+1 < len(pieces) && pieces[i+1].isRun && pieces[i+1].style == piece.style {
+			i++
+			piece.text += pieces[i].text
+		}
+		sb.WriteString(piece.xml())
+	}
+	return sb.String()
+}
+
+// escapeMath escapes text for markup
+func escapeMath(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
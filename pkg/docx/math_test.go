@@ -0,0 +1,90 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestAddEquation(t *testing.T) {
+	doc := New()
+	formulas := []string{
+		`x = \frac{-b \pm \sqrt{b^2 - 4ac}}{2a}`,
+		`\sum_{i=1}^{n} x_i^2 \leq \left( \int_0^\infty e^{-t} dt \right)`,
+		`\lim_{x \to 0} \frac{\sin x}{x} = 1 \text{ for } x \neq 0`,
+		`<m:oMathPara><m:oMath><m:r><m:t>E=mc</m:t></m:r></m:oMath></m:oMathPara>`,
+	}
+	for _, formula := range formulas {
+		if err := doc.AddEquation(formula); err != nil {
+			t.Fatalf("AddEquation(%q) failed: %v", formula, err)
+		}
+	}
+	for _, bad := range []string{`\frac{1}`, `x^{2`, `\unknown x`, `<w:p/>`, `<m:oMath>`} {
+		if err := doc.AddEquation(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	reopened, err := ReadFrom(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadFrom failed: %v", err)
+	}
+	paras := reopened.Body.Paragraphs
+	if len(paras) != 4 || len(paras[0].Math) != 1 || len(paras[3].MathParas) != 1 {
+		t.Fatalf("Expected the equations to survive saving, got %+v", paras)
+	}
+
+	for i, want := range []string{
+		`<m:f><m:num><m:r><m:t xml:space="preserve">-b±</m:t></m:r><m:rad>`,
+		`<m:nary><m:naryPr><m:chr m:val="∑"/><m:limLoc m:val="undOvr"/></m:naryPr><m:sub><m:r><m:t xml:space="preserve">i=1</m:t></m:r></m:sub>`,
+		`<m:limLow><m:e><m:r><m:rPr><m:sty m:val="p"/></m:rPr><m:t xml:space="preserve">lim</m:t></m:r></m:e>`,
+	} {
+		inner := string(paras[i].Math[0].Inner)
+		if !strings.Contains(inner, want) {
+			t.Errorf("Equation %d: expected %s in %s", i, want, inner)
+		}
+		var root struct{}
+		if err := xml.Unmarshal([]byte(`<root xmlns:m="`+mathNamespace+`">`+inner+`</root>`), &root); err != nil {
+			t.Errorf("Equation %d is not well-formed: %v", i, err)
+		}
+	}
+	if !strings.Contains(string(paras[3].MathParas[0].Inner), "E=mc") {
+		t.Errorf("Expected the markup kept as given, got %s", paras[3].MathParas[0].Inner)
+	}
+}
+
+func TestEquationOrder(t *testing.T) {
+	doc := New()
+	err := doc.parseDocument([]byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:m="http://schemas.openxmlformats.org/officeDocument/2006/math">
+<w:body><w:p><w:r><w:t xml:space="preserve">Let </w:t></w:r><m:oMath><m:r><m:t>A=&#960;r</m:t></m:r></m:oMath><w:r><w:t xml:space="preserve"> be the area.</w:t></w:r></w:p></w:body>
+</w:document>`))
+	if err != nil {
+		t.Fatalf("parseDocument failed: %v", err)
+	}
+
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	reopened, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+	content := reopened.Body.Paragraphs[0].Content()
+	if len(content) != 3 {
+		t.Fatalf("Expected 3 children, got %d", len(content))
+	}
+	if _, ok := content[1].(*Equation); !ok {
+		t.Errorf("Expected the equation between the runs, got %T", content[1])
+	}
+	part, _ := reopened.GetPart(documentPart)
+	body := string(part)
+	if i, j, k := strings.Index(body, "Let "), strings.Index(body, "oMath"), strings.Index(body, " be the area."); i > j || j > k {
+		t.Errorf("Expected the equation between the runs, got %s", body)
+	}
+}
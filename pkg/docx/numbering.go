@@ -0,0 +1,376 @@
+package docx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ListLevel is the format of one level of a list, as numbering.xml defines it
+type ListLevel struct {
+	Format  string // Number format, e.g. decimal, lowerLetter, upperRoman or bullet
+	Text    string // Label, where %1 to %9 stand for the numbers of levels 1 to 9, e.g. "%1.%2."
+	Start   int    // Number of the first item
+	Indent  int    // Indent of the text from the margin, in twips
+	Hanging int    // How far the label hangs left of the text, in twips
+}
+
+// numberingXML is the part of numbering.xml ListLevels reads
+type numberingXML struct {
+	AbstractNums []struct {
+		ID     string     `xml:"abstractNumId,attr"`
+		Levels []levelXML `xml:"lvl"`
+	} `xml:"abstractNum"`
+	Nums []struct {
+		ID         string `xml:"numId,attr"`
+		AbstractID valXML `xml:"abstractNumId"`
+		Overrides  []struct {
+			Level         string    `xml:"ilvl,attr"`
+			StartOverride *valXML   `xml:"startOverride"`
+			Lvl           *levelXML `xml:"lvl"`
+		} `xml:"lvlOverride"`
+	} `xml:"num"`
+}
+
+type levelXML struct {
+	Level   string  `xml:"ilvl,attr"`
+	Start   *valXML `xml:"start"`
+	NumFmt  *valXML `xml:"numFmt"`
+	LvlText *valXML `xml:"lvlText"`
+	Ind     *struct {
+		Left    string `xml:"left,attr"`
+		Start   string `xml:"start,attr"`
+		Hanging string `xml:"hanging,attr"`
+	} `xml:"pPr>ind"`
+}
+
+type valXML struct {
+	Val string `xml:"val,attr"`
+}
+
+// ListLevels returns the levels of each list in the document, keyed by the
+// numbering ID paragraphs refer to in their NumPr. Levels the definitions
+// leave out are decimal, numbered from 1 and indented half an inch more
+// than the level before.
+func (d *Document) ListLevels() map[string][]ListLevel {
+	lists := make(map[string][]ListLevel)
+	var numbering numberingXML
+	if err := xml.Unmarshal(d.files[numberingPart], &numbering); err != nil {
+		return lists
+	}
+
+	abstracts := make(map[string][]levelXML)
+	for _, abstract := range numbering.AbstractNums {
+		abstracts[abstract.ID] = abstract.Levels
+	}
+
+	for _, num := range numbering.Nums {
+		levels := make([]ListLevel, 9)
+		for i := range levels {
+			levels[i] = ListLevel{Format: "decimal", Text: "%" + strconv.Itoa(i+1) + ".", Start: 1, Indent: 720 * (i + 1), Hanging: 360}
+		}
+		for _, lvl := range abstracts[num.AbstractID.Val] {
+			applyLevel(levels, lvl)
+		}
+		for _, override := range num.Overrides {
+			if override.Lvl != nil {
+				applyLevel(levels, *override.Lvl)
+			}
+			if i, err := strconv.Atoi(override.Level); err == nil && i >= 0 && i < len(levels) && override.StartOverride != nil {
+				levels[i].Start, _ = strconv.Atoi(override.StartOverride.Val)
+			}
+		}
+		lists[num.ID] = levels
+	}
+	return lists
+}
+
+// applyLevel sets the level a numbering.xml lvl element defines to what it gives
+func applyLevel(levels []ListLevel, lvl levelXML) {
+	i, err := strconv.Atoi(lvl.Level)
+	if err != nil || i < 0 || i >= len(levels) {
+		return
+	}
+	level := &levels[i]
+	if lvl.NumFmt != nil {
+		level.Format = lvl.NumFmt.Val
+	}
+	if lvl.LvlText != nil {
+		level.Text = lvl.LvlText.Val
+	}
+	if lvl.Start != nil {
+		level.Start, _ = strconv.Atoi(lvl.Start.Val)
+	}
+	if lvl.Ind != nil {
+		left := lvl.Ind.Left
+		if left == "" {
+			left = lvl.Ind.Start
+		}
+		if n, err := strconv.Atoi(left); err == nil {
+			level.Indent = n
+		}
+		if n, err := strconv.Atoi(lvl.Ind.Hanging); err == nil {
+			level.Hanging = n
+		}
+	}
+}
+
+// FormatListNumber writes the number of a list item in a numbering format
+// of numbering.xml, e.g. 4 as "iv" in lowerRoman
+func FormatListNumber(n int, format string) string {
+	switch format {
+	case "none":
+		return ""
+	case "decimalZero":
+		return fmt.Sprintf("%02d", n)
+	case "lowerLetter":
+		return strings.ToLower(letterNumber(n))
+	case "upperLetter":
+		return letterNumber(n)
+	case "lowerRoman":
+		return strings.ToLower(romanNumber(n))
+	case "upperRoman":
+		return romanNumber(n)
+	}
+	return strconv.Itoa(n)
+}
+
+// letterNumber writes n as Word's letter numbering does: A to Z, then AA to ZZ and so on
+func letterNumber(n int) string {
+	if n < 1 {
+		return strconv.Itoa(n)
+	}
+	return strings.Repeat(string(rune('A'+(n-1)%26)), (n-1)/26+1)
+}
+
+// romanNumber writes n in upper case Roman numerals
+func romanNumber(n int) string {
+	if n < 1 || n >= 4000 {
+		return strconv.Itoa(n)
+	}
+	var sb strings.Builder
+	for _, numeral := range []struct {
+		value  int
+		symbol string
+	}{{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"}, {100, "C"}, {90, "XC"}, {50, "L"}, {40, "XL"}, {10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"}} {
+		for n >= numeral.value {
+			sb.WriteString(numeral.symbol)
+			n -= numeral.value
+		}
+	}
+	return sb.String()
+}
+
+// listCounter numbers the list items of a document, visited in order
+type listCounter struct {
+	levels map[string][]ListLevel // Levels of each list, by numbering ID
+	counts map[string][]int       // Number of the last item at each level, by numbering ID
+}
+
+// newListCounter returns a counter for the lists of the document
+func (d *Document) newListCounter() *listCounter {
+	return &listCounter{levels: d.ListLevels(), counts: make(map[string][]int)}
+}
+
+// next counts a paragraph and returns its number as its list shows it, e.g.
+// "2.1." or "b)", or "" for paragraphs that aren't numbered list items
+func (lc *listCounter) next(p *Paragraph) string {
+	if p.Props == nil || p.Props.NumPr == nil || p.Props.NumPr.NumID == nil {
+		return ""
+	}
+	levels, ok := lc.levels[p.Props.NumPr.NumID.Val]
+	if !ok {
+		return ""
+	}
+	ilvl := 0
+	if p.Props.NumPr.ILvl != nil {
+		ilvl, _ = strconv.Atoi(p.Props.NumPr.ILvl.Val)
+	}
+	ilvl = min(max(ilvl, 0), len(levels)-1)
+
+	// An item restarts the numbering of the levels below it
+	counts, ok := lc.counts[p.Props.NumPr.NumID.Val]
+	if !ok {
+		counts = make([]int, len(levels))
+		for i := range counts {
+			counts[i] = levels[i].Start - 1
+		}
+		lc.counts[p.Props.NumPr.NumID.Val] = counts
+	}
+	counts[ilvl]++
+	for i := ilvl + 1; i < len(counts); i++ {
+		counts[i] = levels[i].Start - 1
+	}
+
+	if levels[ilvl].Format == "bullet" {
+		return ""
+	}
+	label := levels[ilvl].Text
+	for i := 0; i <= ilvl; i++ {
+		n := max(counts[i], levels[i].Start)
+		label = strings.ReplaceAll(label, "%"+strconv.Itoa(i+1), FormatListNumber(n, levels[i].Format))
+	}
+	return label
+}
+
+// maxListLevels is the number of levels a list can have
+const maxListLevels = 9
+
+// listItem returns the numbering ID and level of a body paragraph that is
+// numbered directly, rather than through its style
+func (d *Document) listItem(index int) (string, int, error) {
+	if index < 0 || index >= len(d.Body.Paragraphs) {
+		return "", 0, fmt.Errorf("paragraph index %d %w", index, ErrIndexOutOfRange)
+	}
+	numID, level := paragraphNumbering(&d.Body.Paragraphs[index])
+	if numID == "" {
+		return "", 0, fmt.Errorf("paragraph %d: %w", index, ErrNotListItem)
+	}
+	return numID, level, nil
+}
+
+// paragraphNumbering returns the numbering ID and level of a paragraph, or
+// "" when it isn't numbered
+func paragraphNumbering(p *Paragraph) (string, int) {
+	if p.Props == nil || p.Props.NumPr == nil || p.Props.NumPr.NumID == nil || p.Props.NumPr.NumID.Val == "0" {
+		return "", 0
+	}
+	level := 0
+	if p.Props.NumPr.ILvl != nil {
+		level, _ = strconv.Atoi(p.Props.NumPr.ILvl.Val)
+	}
+	return p.Props.NumPr.NumID.Val, level
+}
+
+// SetNumbering makes the paragraph at index an item of the list with the
+// given numbering ID, at a level from 0 to 8. An empty numID removes the
+// paragraph's numbering.
+func (d *Document) SetNumbering(index int, numID string, level int) error {
+	if index < 0 || index >= len(d.Body.Paragraphs) {
+		return fmt.Errorf("paragraph index %d %w", index, ErrIndexOutOfRange)
+	}
+	p := &d.Body.Paragraphs[index]
+	if numID == "" {
+		if p.Props != nil {
+			p.Props.NumPr = nil
+		}
+		return nil
+	}
+	if level < 0 || level >= maxListLevels {
+		return fmt.Errorf("list level %d %w", level, ErrIndexOutOfRange)
+	}
+	if _, ok := d.ListLevels()[numID]; !ok {
+		return fmt.Errorf("numbering %s: %w", numID, ErrNotFound)
+	}
+	if p.Props == nil {
+		p.Props = &PProps{}
+	}
+	p.Props.NumPr = &NumPr{ILvl: &NumLevel{Val: strconv.Itoa(level)}, NumID: &NumID{Val: numID}}
+	return nil
+}
+
+// NewNumbering adds a numbering instance of the same list definition as
+// numID that numbers from the start again, and returns its ID. Give it to
+// SetNumbering to start a separate list formatted like the other.
+func (d *Document) NewNumbering(numID string) (string, error) {
+	levels, ok := d.ListLevels()[numID]
+	if !ok {
+		return "", fmt.Errorf("numbering %s: %w", numID, ErrNotFound)
+	}
+	numbering := string(d.files[numberingPart])
+	abstractID := ""
+	for _, block := range numPattern.FindAllString(numbering, -1) {
+		if m := numIDPattern.FindStringSubmatch(block); m != nil && m[2] == numID {
+			if ref := abstractNumRefPattern.FindStringSubmatch(block); ref != nil {
+				abstractID = ref[2]
+			}
+			break
+		}
+	}
+	if abstractID == "" {
+		return "", fmt.Errorf("list definition of numbering %s: %w", numID, ErrNotFound)
+	}
+
+	// Instances of one definition continue each other's numbering unless
+	// their levels override where they start
+	newID := strconv.Itoa(max(maxSubmatchInt(numIDPattern, numbering), 0) + 1)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<w:num w:numId="%s"><w:abstractNumId w:val="%s"/>`, newID, abstractID)
+	for i, level := range levels {
+		fmt.Fprintf(&sb, `<w:lvlOverride w:ilvl="%d"><w:startOverride w:val="%d"/></w:lvlOverride>`, i, level.Start)
+	}
+	sb.WriteString("</w:num>\n")
+	d.files[numberingPart] = []byte(strings.Replace(numbering, "</w:numbering>", sb.String()+"</w:numbering>", 1))
+	return newID, nil
+}
+
+// RestartNumbering makes the list item at index number from the start of
+// its list again, e.g. 1. The items after it in the same list follow on
+// from it, in a new numbering instance.
+func (d *Document) RestartNumbering(index int) error {
+	numID, _, err := d.listItem(index)
+	if err != nil {
+		return err
+	}
+	newID, err := d.NewNumbering(numID)
+	if err != nil {
+		return err
+	}
+	d.renumberFrom(index, numID, newID)
+	return nil
+}
+
+// ContinueNumbering makes the list item at index, and the items after it in
+// the same list, continue the numbering of the nearest list before it
+// formatted the same way: one of the same list definition, or with the same
+// levels, as lists of merged documents have. Templated and merged documents
+// often restart lists that should go on. Lists whose numbering isn't defined
+// match none.
+func (d *Document) ContinueNumbering(index int) error {
+	numID, _, err := d.listItem(index)
+	if err != nil {
+		return err
+	}
+	lists := d.ListLevels()
+	abstracts := d.abstractNumIDs()
+	for i := index - 1; i >= 0; i-- {
+		prevID, _ := paragraphNumbering(&d.Body.Paragraphs[i])
+		if prevID == "" || prevID == numID {
+			continue
+		}
+		sameDefinition := abstracts[numID] != "" && abstracts[prevID] == abstracts[numID]
+		sameLevels := len(lists[numID]) > 0 && slices.Equal(lists[prevID], lists[numID])
+		if sameDefinition || sameLevels {
+			d.renumberFrom(index, numID, prevID)
+			return nil
+		}
+	}
+	return fmt.Errorf("paragraph %d: list to continue %w", index, ErrNotFound)
+}
+
+// renumberFrom moves the items of list numID from the paragraph at index on
+// to list newID
+func (d *Document) renumberFrom(index int, numID, newID string) {
+	for i := index; i < len(d.Body.Paragraphs); i++ {
+		p := &d.Body.Paragraphs[i]
+		if id, _ := paragraphNumbering(p); id == numID {
+			numPr := *p.Props.NumPr
+			numPr.NumID = &NumID{Val: newID}
+			p.Props.NumPr = &numPr
+		}
+	}
+}
+
+// abstractNumIDs returns the list definition of each numbering instance
+func (d *Document) abstractNumIDs() map[string]string {
+	var numbering numberingXML
+	ids := make(map[string]string)
+	if err := xml.Unmarshal(d.files[numberingPart], &numbering); err == nil {
+		for _, num := range numbering.Nums {
+			ids[num.ID] = num.AbstractID.Val
+		}
+	}
+	return ids
+}
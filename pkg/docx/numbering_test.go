@@ -0,0 +1,149 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestListLevels(t *testing.T) {
+	doc := New()
+	if lists := doc.ListLevels(); len(lists) != 0 {
+		t.Fatalf("Expected no lists without numbering.xml, got %v", lists)
+	}
+
+	doc.SetPart(numberingPart, []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="bullet"/><w:lvlText w:val="`+"\uf0b7"+`"/><w:pPr><w:ind w:left="720" w:hanging="360"/></w:pPr></w:lvl></w:abstractNum>
+<w:abstractNum w:abstractNumId="1"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/><w:lvlText w:val="%1."/></w:lvl><w:lvl w:ilvl="1"><w:numFmt w:val="lowerLetter"/><w:lvlText w:val="%1.%2)"/><w:pPr><w:ind w:start="1080" w:hanging="540"/></w:pPr></w:lvl></w:abstractNum>
+<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>
+<w:num w:numId="2"><w:abstractNumId w:val="1"/><w:lvlOverride w:ilvl="0"><w:startOverride w:val="5"/></w:lvlOverride></w:num>
+</w:numbering>`))
+
+	lists := doc.ListLevels()
+	if len(lists) != 2 {
+		t.Fatalf("Expected 2 lists, got %v", lists)
+	}
+	if l := lists["1"][0]; l.Format != "bullet" || l.Text != "\uf0b7" || l.Indent != 720 || l.Hanging != 360 {
+		t.Errorf("Unexpected bullet level: %+v", l)
+	}
+	if l := lists["2"][0]; l.Format != "decimal" || l.Start != 5 {
+		t.Errorf("Expected the start override to apply, got %+v", l)
+	}
+	if l := lists["2"][1]; l.Format != "lowerLetter" || l.Text != "%1.%2)" || l.Start != 1 || l.Indent != 1080 || l.Hanging != 540 {
+		t.Errorf("Unexpected second level: %+v", l)
+	}
+	if l := lists["2"][2]; l.Format != "decimal" || l.Text != "%3." || l.Indent != 2160 {
+		t.Errorf("Expected defaults for an undefined level, got %+v", l)
+	}
+}
+
+const testNumbering = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="decimal"/><w:lvlText w:val="%1."/></w:lvl><w:lvl w:ilvl="1"><w:start w:val="1"/><w:numFmt w:val="lowerLetter"/><w:lvlText w:val="%2)"/></w:lvl></w:abstractNum>
+<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>
+</w:numbering>`
+
+// numberedDocument returns a document whose paragraphs are list items of
+// numbering 1 at the given levels, or plain paragraphs for levels below 0
+func numberedDocument(t *testing.T, levels ...int) *Document {
+	t.Helper()
+	doc := New()
+	doc.SetPart(numberingPart, []byte(testNumbering))
+	for i, level := range levels {
+		doc.AddParagraph(fmt.Sprintf("Item %d", i))
+		if level >= 0 {
+			if err := doc.SetNumbering(i, "1", level); err != nil {
+				t.Fatalf("SetNumbering failed: %v", err)
+			}
+		}
+	}
+	return doc
+}
+
+// listLabels returns the list label of each body paragraph
+func listLabels(doc *Document) string {
+	counter := doc.newListCounter()
+	labels := make([]string, len(doc.Body.Paragraphs))
+	for i := range doc.Body.Paragraphs {
+		labels[i] = counter.next(&doc.Body.Paragraphs[i])
+	}
+	return strings.Join(labels, " ")
+}
+
+func TestRestartAndContinueNumbering(t *testing.T) {
+	doc := numberedDocument(t, 0, 0, 1, -1, 0, 0)
+	if got := listLabels(doc); got != "1. 2. a)  3. 4." {
+		t.Fatalf("Unexpected labels %q", got)
+	}
+
+	if err := doc.RestartNumbering(4); err != nil {
+		t.Fatalf("RestartNumbering failed: %v", err)
+	}
+	if got := listLabels(doc); got != "1. 2. a)  1. 2." {
+		t.Errorf("Expected the list to restart, got %q", got)
+	}
+	numbering, _ := doc.GetPart(numberingPart)
+	if !strings.Contains(string(numbering), `<w:num w:numId="2"><w:abstractNumId w:val="0"/><w:lvlOverride w:ilvl="0"><w:startOverride w:val="1"/>`) {
+		t.Errorf("Expected a restarting instance of the definition, got %s", numbering)
+	}
+
+	if err := doc.ContinueNumbering(4); err != nil {
+		t.Fatalf("ContinueNumbering failed: %v", err)
+	}
+	if got := listLabels(doc); got != "1. 2. a)  3. 4." {
+		t.Errorf("Expected the list to continue, got %q", got)
+	}
+
+	if err := doc.RestartNumbering(3); !errors.Is(err, ErrNotListItem) {
+		t.Errorf("Expected ErrNotListItem, got %v", err)
+	}
+	if err := doc.ContinueNumbering(0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound with no list before the item, got %v", err)
+	}
+	if err := doc.SetNumbering(3, "7", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an undefined numbering, got %v", err)
+	}
+	if _, err := doc.NewNumbering("7"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an undefined numbering, got %v", err)
+	}
+	if err := doc.SetNumbering(1, "", 0); err != nil || listLabels(doc) != "1.  a)  2. 3." {
+		t.Errorf("Expected the numbering to be removed, got %q (%v)", listLabels(doc), err)
+	}
+}
+
+func TestContinueNumberingUndefined(t *testing.T) {
+	doc := numberedDocument(t, 0, 0, 0)
+	doc.Body.Paragraphs[1].Props.NumPr.NumID.Val = "8"
+	doc.Body.Paragraphs[2].Props.NumPr.NumID.Val = "9"
+
+	// Neither has a definition or levels, which doesn't make them alike
+	if err := doc.ContinueNumbering(2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for lists without numbering, got %v", err)
+	}
+	if id, _ := paragraphNumbering(&doc.Body.Paragraphs[2]); id != "9" {
+		t.Errorf("Expected the item to keep its numbering, got %s", id)
+	}
+}
+
+func TestContinueNumberingAfterMerge(t *testing.T) {
+	doc := numberedDocument(t, 0, 0)
+	if err := doc.InsertDocument(2, numberedDocument(t, 0, 0)); err != nil {
+		t.Fatalf("InsertDocument failed: %v", err)
+	}
+	if got := listLabels(doc); got != "1. 2. 1. 2." {
+		t.Fatalf("Expected the inserted list to start over, got %q", got)
+	}
+	if id, _ := paragraphNumbering(&doc.Body.Paragraphs[2]); id == "1" {
+		t.Fatal("Expected the inserted list to get its own numbering")
+	}
+
+	// The inserted list has a definition of its own, formatted the same way
+	if err := doc.ContinueNumbering(2); err != nil {
+		t.Fatalf("ContinueNumbering failed: %v", err)
+	}
+	if got := listLabels(doc); got != "1. 2. 3. 4." {
+		t.Errorf("Expected the merged lists to be one, got %q", got)
+	}
+}
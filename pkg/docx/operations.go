@@ -2,11 +2,12 @@ package docx
 
 import (
 	"fmt"
-	"strings"
+	"strconv"
 )
 
-// AddParagraph adds a new paragraph to the document
-func (d *Document) AddParagraph(text string, opts ...ParagraphOption) {
+// NewParagraph returns a paragraph of text with the options applied, for
+// functions such as InsertAfterHeading that take paragraphs
+func NewParagraph(text string, opts ...ParagraphOption) Paragraph {
 	p := Paragraph{
 		Runs: []Run{
 			{
@@ -24,39 +25,22 @@ func (d *Document) AddParagraph(text string, opts ...ParagraphOption) {
 	for _, opt := range opts {
 		opt(&p)
 	}
+	return p
+}
 
-	d.Body.Paragraphs = append(d.Body.Paragraphs, p)
+// AddParagraph adds a new paragraph to the document
+func (d *Document) AddParagraph(text string, opts ...ParagraphOption) {
+	d.Body.Paragraphs = append(d.Body.Paragraphs, NewParagraph(text, opts...))
 }
 
 // AddParagraphAt inserts a paragraph at a specific index
 func (d *Document) AddParagraphAt(index int, text string, opts ...ParagraphOption) error {
 	if index < 0 || index > len(d.Body.Paragraphs) {
-		return fmt.Errorf("index %d out of range", index)
-	}
-
-	p := Paragraph{
-		Runs: []Run{
-			{
-				Text: []Text{
-					{
-						Space:   "preserve",
-						Content: text,
-					},
-				},
-			},
-		},
-	}
-
-	// Apply options
-	for _, opt := range opts {
-		opt(&p)
+		return fmt.Errorf("index %d %w", index, ErrIndexOutOfRange)
 	}
 
 	// Insert at index
-	d.Body.Paragraphs = append(
-		d.Body.Paragraphs[:index],
-		append([]Paragraph{p}, d.Body.Paragraphs[index:]...)...,
-	)
+	d.Body.SpliceParagraphs(index, 0, NewParagraph(text, opts...))
 
 	return nil
 }
@@ -64,13 +48,10 @@ func (d *Document) AddParagraphAt(index int, text string, opts ...ParagraphOptio
 // DeleteParagraph removes a paragraph by index
 func (d *Document) DeleteParagraph(index int) error {
 	if index < 0 || index >= len(d.Body.Paragraphs) {
-		return fmt.Errorf("paragraph index %d out of range", index)
+		return fmt.Errorf("paragraph index %d %w", index, ErrIndexOutOfRange)
 	}
 
-	d.Body.Paragraphs = append(
-		d.Body.Paragraphs[:index],
-		d.Body.Paragraphs[index+1:]...,
-	)
+	d.Body.SpliceParagraphs(index, 1)
 
 	return nil
 }
@@ -81,27 +62,84 @@ func (d *Document) DeleteParagraphsRange(start, end int) error {
 		return fmt.Errorf("invalid range [%d:%d]", start, end)
 	}
 
-	d.Body.Paragraphs = append(
-		d.Body.Paragraphs[:start],
-		d.Body.Paragraphs[end+1:]...,
-	)
+	d.Body.SpliceParagraphs(start, end-start+1)
 
 	return nil
 }
 
+// MoveParagraph moves a paragraph so that it ends up at index to, keeping
+// its formatting. Tables and content controls stay with the paragraphs they
+// precede.
+func (d *Document) MoveParagraph(from, to int) error {
+	if from < 0 || from >= len(d.Body.Paragraphs) {
+		return fmt.Errorf("paragraph index %d %w", from, ErrIndexOutOfRange)
+	}
+	return d.MoveParagraphRange(from, from, to)
+}
+
+// MoveParagraphRange moves the paragraphs from start to end (inclusive) so
+// that the first of them ends up at index to, keeping their formatting.
+// Tables and content controls between the moved paragraphs move with them;
+// the others stay with the paragraphs they precede.
+func (d *Document) MoveParagraphRange(start, end, to int) error {
+	n := len(d.Body.Paragraphs)
+	if start < 0 || end >= n || start > end {
+		return fmt.Errorf("invalid range [%d:%d]", start, end)
+	}
+	if to < 0 || to > n-(end-start+1) {
+		return fmt.Errorf("target index %d %w", to, ErrIndexOutOfRange)
+	}
+	d.Body.moveParagraphs(start, end, to)
+	return nil
+}
+
+// moveParagraphs moves the paragraphs from start to end (inclusive) to index
+// to of the paragraphs left once they are taken out
+func (b *Body) moveParagraphs(start, end, to int) {
+	n := len(b.Paragraphs)
+	order := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		if i < start || i > end {
+			order = append(order, i)
+		}
+	}
+	moved := make([]int, 0, end-start+1)
+	for i := start; i <= end; i++ {
+		moved = append(moved, i)
+	}
+	order = append(order[:to], append(moved, order[to:]...)...)
+
+	// newIndex maps old paragraph indexes, and the end of the body, to new ones
+	newIndex := make([]int, n+1)
+	paras := make([]Paragraph, n)
+	for i, old := range order {
+		newIndex[old] = i
+		paras[i] = b.Paragraphs[old]
+	}
+	newIndex[n] = n
+	b.Paragraphs = paras
+
+	// A block at position p precedes paragraph p; one before the first moved
+	// paragraph stays where it is, before the paragraph following the range
+	shift := func(pos int) int {
+		if pos == start {
+			pos = end + 1
+		}
+		return newIndex[pos]
+	}
+	for i := range b.Tables {
+		b.Tables[i].Position = shift(b.Tables[i].Position)
+	}
+	for i := range b.SDTs {
+		b.SDTs[i].Position = shift(b.SDTs[i].Position)
+	}
+}
+
 // ReplaceText replaces all occurrences of old text with new text
 func (d *Document) ReplaceText(oldText, newText string) int {
 	count := 0
 	for i := range d.Body.Paragraphs {
-		for j := range d.Body.Paragraphs[i].Runs {
-			for k := range d.Body.Paragraphs[i].Runs[j].Text {
-				text := &d.Body.Paragraphs[i].Runs[j].Text[k]
-				if strings.Contains(text.Content, oldText) {
-					text.Content = strings.ReplaceAll(text.Content, oldText, newText)
-					count++
-				}
-			}
-		}
+		count += replaceInParagraph(&d.Body.Paragraphs[i], oldText, newText)
 	}
 	return count
 }
@@ -109,28 +147,38 @@ func (d *Document) ReplaceText(oldText, newText string) int {
 // ReplaceTextInParagraph replaces text in a specific paragraph
 func (d *Document) ReplaceTextInParagraph(index int, oldText, newText string) (int, error) {
 	if index < 0 || index >= len(d.Body.Paragraphs) {
-		return 0, fmt.Errorf("paragraph index %d out of range", index)
+		return 0, fmt.Errorf("paragraph index %d %w", index, ErrIndexOutOfRange)
 	}
 
-	count := 0
-	p := &d.Body.Paragraphs[index]
-	for j := range p.Runs {
-		for k := range p.Runs[j].Text {
-			text := &p.Runs[j].Text[k]
-			if strings.Contains(text.Content, oldText) {
-				text.Content = strings.ReplaceAll(text.Content, oldText, newText)
-				count++
-			}
-		}
+	return replaceInParagraph(&d.Body.Paragraphs[index], oldText, newText), nil
+}
+
+// ReplaceTextStyled replaces all occurrences of old text in the body
+// paragraphs and tables with new text formatted by opts, e.g. WithBold() or
+// WithHighlight("red"), and returns the number of replacements. The new
+// text gets a run of its own, keeping the formatting of the text it
+// replaces; occurrences split across runs are found too.
+func (d *Document) ReplaceTextStyled(oldText, newText string, opts ...ParagraphOption) int {
+	if oldText == "" {
+		return 0
 	}
 
-	return count, nil
+	count := 0
+	d.forEachBlock(SearchScope{Body: true, Tables: true}, func(i int, p *Paragraph) {
+		count += p.replaceStyled(oldText, newText, opts)
+	}, func(i int, t *Table) {
+		cellParagraphs(t, func(row, col, index int, p *Paragraph) {
+			count += p.replaceStyled(oldText, newText, opts)
+		})
+	})
+	return count
 }
 
-// Clear removes all paragraphs and tables from the document
+// Clear removes all paragraphs, tables and content controls from the document
 func (d *Document) Clear() {
 	d.Body.Paragraphs = []Paragraph{}
 	d.Body.Tables = []Table{}
+	d.Body.SDTs = nil
 }
 
 // GetParagraphCount returns the number of paragraphs
@@ -146,7 +194,7 @@ func (d *Document) GetTableCount() int {
 // DeleteTable removes a table by index
 func (d *Document) DeleteTable(index int) error {
 	if index < 0 || index >= len(d.Body.Tables) {
-		return fmt.Errorf("table index %d out of range", index)
+		return fmt.Errorf("table index %d %w", index, ErrIndexOutOfRange)
 	}
 
 	d.Body.Tables = append(
@@ -160,51 +208,142 @@ func (d *Document) DeleteTable(index int) error {
 // ParagraphOption is a function type for configuring paragraphs
 type ParagraphOption func(*Paragraph)
 
-// WithBold makes the paragraph text bold
+// WithBold makes the paragraph text bold, complex script text included
 func WithBold() ParagraphOption {
 	return func(p *Paragraph) {
-		for i := range p.Runs {
-			if p.Runs[i].Props == nil {
-				p.Runs[i].Props = &RProps{}
+		p.forEachRun(func(r *Run) {
+			if r.Props == nil {
+				r.Props = &RProps{}
 			}
-			p.Runs[i].Props.Bold = &Bold{}
-		}
+			r.Props.Bold = &Bold{}
+			r.Props.BoldCS = &BoldCS{}
+		})
+	}
+}
+
+// WithHidden formats the paragraph text as hidden: Word doesn't show or
+// print it unless asked to
+func WithHidden() ParagraphOption {
+	return func(p *Paragraph) {
+		p.forEachRun(func(r *Run) {
+			if r.Props == nil {
+				r.Props = &RProps{}
+			}
+			r.Props.Vanish = &Vanish{}
+		})
 	}
 }
 
-// WithItalic makes the paragraph text italic
+// WithItalic makes the paragraph text italic, complex script text included
 func WithItalic() ParagraphOption {
 	return func(p *Paragraph) {
-		for i := range p.Runs {
-			if p.Runs[i].Props == nil {
-				p.Runs[i].Props = &RProps{}
+		p.forEachRun(func(r *Run) {
+			if r.Props == nil {
+				r.Props = &RProps{}
 			}
-			p.Runs[i].Props.Italic = &Italic{}
-		}
+			r.Props.Italic = &Italic{}
+			r.Props.ItalicCS = &ItalicCS{}
+		})
 	}
 }
 
-// WithSize sets the font size (in half-points, e.g., 24 = 12pt)
+// WithSize sets the font size (in half-points, e.g., 24 = 12pt), of
+// complex script text too
 func WithSize(size string) ParagraphOption {
 	return func(p *Paragraph) {
-		for i := range p.Runs {
-			if p.Runs[i].Props == nil {
-				p.Runs[i].Props = &RProps{}
+		p.forEachRun(func(r *Run) {
+			if r.Props == nil {
+				r.Props = &RProps{}
 			}
-			p.Runs[i].Props.Size = &Size{Val: size}
-		}
+			r.Props.Size = &Size{Val: size}
+			r.Props.SizeCS = &SizeCS{Val: size}
+		})
 	}
 }
 
 // WithColor sets the text color (hex without #, e.g., "FF0000" for red)
 func WithColor(color string) ParagraphOption {
 	return func(p *Paragraph) {
-		for i := range p.Runs {
-			if p.Runs[i].Props == nil {
-				p.Runs[i].Props = &RProps{}
+		p.forEachRun(func(r *Run) {
+			if r.Props == nil {
+				r.Props = &RProps{}
 			}
-			p.Runs[i].Props.Color = &Color{Val: color}
-		}
+			r.Props.Color = &Color{Val: color}
+		})
+	}
+}
+
+// WithUnderline underlines the paragraph text. style is a Word underline style
+// such as "single", "double", "thick", "dotted", "dash", "wave" or "words";
+// empty means "single".
+func WithUnderline(style string) ParagraphOption {
+	if style == "" {
+		style = "single"
+	}
+	return func(p *Paragraph) {
+		p.forEachRun(func(r *Run) {
+			if r.Props == nil {
+				r.Props = &RProps{}
+			}
+			r.Props.Underline = &Underline{Val: style}
+		})
+	}
+}
+
+// WithStrike strikes through the paragraph text
+func WithStrike() ParagraphOption {
+	return func(p *Paragraph) {
+		p.forEachRun(func(r *Run) {
+			if r.Props == nil {
+				r.Props = &RProps{}
+			}
+			r.Props.Strike = &Strike{}
+		})
+	}
+}
+
+// WithHighlight highlights the paragraph text in one of HighlightColors, e.g. "yellow"
+func WithHighlight(color string) ParagraphOption {
+	return func(p *Paragraph) {
+		p.forEachRun(func(r *Run) {
+			if r.Props == nil {
+				r.Props = &RProps{}
+			}
+			r.Props.Highlight = &Highlight{Val: color}
+		})
+	}
+}
+
+// WithSuperscript raises the paragraph text as superscript
+func WithSuperscript() ParagraphOption {
+	return withVertAlign("superscript")
+}
+
+// WithSubscript lowers the paragraph text as subscript
+func WithSubscript() ParagraphOption {
+	return withVertAlign("subscript")
+}
+
+func withVertAlign(val string) ParagraphOption {
+	return func(p *Paragraph) {
+		p.forEachRun(func(r *Run) {
+			if r.Props == nil {
+				r.Props = &RProps{}
+			}
+			r.Props.VertAlign = &VertAlign{Val: val}
+		})
+	}
+}
+
+// WithFont sets the font family of the paragraph text, e.g. "Calibri"
+func WithFont(name string) ParagraphOption {
+	return func(p *Paragraph) {
+		p.forEachRun(func(r *Run) {
+			if r.Props == nil {
+				r.Props = &RProps{}
+			}
+			r.Props.RFonts = &RFonts{ASCII: name, HAnsi: name, EastAsia: name, CS: name}
+		})
 	}
 }
 
@@ -227,3 +366,97 @@ func WithStyle(styleName string) ParagraphOption {
 		p.Props.Style = &PStyle{Val: styleName}
 	}
 }
+
+// WithPageBreakBefore starts the paragraph on a new page
+func WithPageBreakBefore() ParagraphOption {
+	return func(p *Paragraph) {
+		if p.Props == nil {
+			p.Props = &PProps{}
+		}
+		p.Props.PageBreakBefore = &PageBreakBefore{}
+	}
+}
+
+// WithInternalLink turns the paragraph text into a link to the named bookmark
+func WithInternalLink(bookmark string) ParagraphOption {
+	return func(p *Paragraph) {
+		p.Hyperlinks = append(p.Hyperlinks, Hyperlink{Anchor: bookmark, History: "1", Runs: p.Runs})
+		p.Runs = nil
+	}
+}
+
+// forEachRun calls fn for every run of the paragraph in document order,
+// including runs inside hyperlinks and field results
+func (p *Paragraph) forEachRun(fn func(r *Run)) {
+	p.eachChild(func(i int) {
+		fn(&p.Runs[i])
+	}, func(v interface{}) {
+		switch e := v.(type) {
+		case *Hyperlink:
+			for i := range e.Runs {
+				fn(&e.Runs[i])
+			}
+		case *SimpleField:
+			for i := range e.Runs {
+				fn(&e.Runs[i])
+			}
+		}
+	})
+}
+
+// runRef is a run of a paragraph: the slice holding it and its index there
+type runRef struct {
+	runs *[]Run
+	i    int
+}
+
+// runRefs returns the runs of the paragraph in document order, those of
+// its links and field results included
+func (p *Paragraph) runRefs() []runRef {
+	var refs []runRef
+	p.eachChild(func(i int) {
+		refs = append(refs, runRef{&p.Runs, i})
+	}, func(v interface{}) {
+		var runs *[]Run
+		switch e := v.(type) {
+		case *Hyperlink:
+			runs = &e.Runs
+		case *SimpleField:
+			runs = &e.Runs
+		default:
+			return
+		}
+		for i := range *runs {
+			refs = append(refs, runRef{runs, i})
+		}
+	})
+	return refs
+}
+
+// AddBookmark wraps the paragraph at index in a bookmark so it can be the target of internal links
+func (d *Document) AddBookmark(index int, name string) error {
+	if index < 0 || index >= len(d.Body.Paragraphs) {
+		return fmt.Errorf("paragraph index %d %w", index, ErrIndexOutOfRange)
+	}
+	if name == "" {
+		return fmt.Errorf("bookmark name is required")
+	}
+
+	nextID := 0
+	for _, p := range d.Body.Paragraphs {
+		for _, b := range p.BookmarkStarts {
+			if b.Name == name {
+				return fmt.Errorf("bookmark %q already exists", name)
+			}
+			if id, err := strconv.Atoi(b.ID); err == nil && id >= nextID {
+				nextID = id + 1
+			}
+		}
+	}
+
+	id := strconv.Itoa(nextID)
+	p := &d.Body.Paragraphs[index]
+	p.BookmarkStarts = append(p.BookmarkStarts, BookmarkStart{ID: id, Name: name})
+	p.BookmarkEnds = append(p.BookmarkEnds, BookmarkEnd{Position: len(p.Runs), ID: id})
+	return nil
+}
@@ -0,0 +1,168 @@
+package docx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OutlineEntry is a heading with the headings nested under it
+type OutlineEntry struct {
+	Heading
+	Children []OutlineEntry
+}
+
+// GetOutline returns the heading hierarchy of the body: each heading
+// holds the lower-level headings that follow it, up to the next heading of
+// its level or higher. Headings without text are left out.
+func (d *Document) GetOutline() []OutlineEntry {
+	return buildOutline(d.headings())
+}
+
+// buildOutline nests headings under the heading before them with a lower level
+func buildOutline(headings []Heading) []OutlineEntry {
+	entries := []OutlineEntry{}
+	for i := 0; i < len(headings); {
+		j := i + 1
+		for j < len(headings) && headings[j].Level > headings[i].Level {
+			j++
+		}
+		entries = append(entries, OutlineEntry{Heading: headings[i], Children: buildOutline(headings[i+1 : j])})
+		i = j
+	}
+	return entries
+}
+
+// headings returns the body's heading paragraphs with text, in order
+func (d *Document) headings() []Heading {
+	headings := []Heading{}
+	for i := range d.Body.Paragraphs {
+		p := &d.Body.Paragraphs[i]
+		level := HeadingLevel(p)
+		if level == 0 {
+			continue
+		}
+		if text := strings.TrimSpace(d.paragraphText(p)); text != "" {
+			headings = append(headings, Heading{Level: level, Text: text, Index: i})
+		}
+	}
+	return headings
+}
+
+// FindHeading returns the heading at a path of heading texts separated by
+// ">", e.g. "Chapter 2 > Methods": a heading with the text of the first
+// part, at any level, then one under it with the text of the next part and
+// so on. Texts are compared ignoring case and extra spaces. The first
+// match in the document wins.
+func (d *Document) FindHeading(path string) (Heading, error) {
+	var parts []string
+	for _, part := range strings.Split(path, ">") {
+		if part = strings.Join(strings.Fields(part), " "); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return Heading{}, fmt.Errorf("empty heading path %q", path)
+	}
+	if h, ok := findHeading(d.GetOutline(), parts, true); ok {
+		return h, nil
+	}
+	return Heading{}, fmt.Errorf("%q: %w", path, ErrHeadingNotFound)
+}
+
+// findHeading looks for the heading path parts among entries, nested
+// anywhere below them if anywhere is set
+func findHeading(entries []OutlineEntry, parts []string, anywhere bool) (Heading, bool) {
+	for _, e := range entries {
+		if strings.EqualFold(strings.Join(strings.Fields(e.Text), " "), parts[0]) {
+			if len(parts) == 1 {
+				return e.Heading, true
+			}
+			if h, ok := findHeading(e.Children, parts[1:], false); ok {
+				return h, true
+			}
+		}
+		if anywhere {
+			if h, ok := findHeading(e.Children, parts, true); ok {
+				return h, true
+			}
+		}
+	}
+	return Heading{}, false
+}
+
+// SectionRange returns the indexes of the first and last paragraphs of the
+// section under the heading at path (see FindHeading): the heading and
+// everything up to the next heading of its level or higher.
+func (d *Document) SectionRange(path string) (start, end int, err error) {
+	h, err := d.FindHeading(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	end = len(d.Body.Paragraphs) - 1
+	for _, next := range d.headings() {
+		if next.Index > h.Index && next.Level <= h.Level {
+			end = next.Index - 1
+			break
+		}
+	}
+	return h.Index, end, nil
+}
+
+// InsertAfterHeading inserts paragraphs right after the heading at path
+// (see FindHeading), so they open its section
+func (d *Document) InsertAfterHeading(path string, paras ...Paragraph) error {
+	h, err := d.FindHeading(path)
+	if err != nil {
+		return err
+	}
+	d.Body.SpliceParagraphs(h.Index+1, 0, paras...)
+	return nil
+}
+
+// AppendToSection inserts paragraphs at the end of the section under the
+// heading at path (see FindHeading), after its subsections and before the
+// next heading of its level or higher
+func (d *Document) AppendToSection(path string, paras ...Paragraph) error {
+	_, end, err := d.SectionRange(path)
+	if err != nil {
+		return err
+	}
+	// Tables and content controls closing the section stay before the new
+	// paragraphs
+	at := end + 1
+	var tables, sdts []int
+	for i, t := range d.Body.Tables {
+		if t.Position == at {
+			tables = append(tables, i)
+		}
+	}
+	for i, sdt := range d.Body.SDTs {
+		if sdt.Position == at {
+			sdts = append(sdts, i)
+		}
+	}
+	d.Body.SpliceParagraphs(at, 0, paras...)
+	for _, i := range tables {
+		d.Body.Tables[i].Position = at
+	}
+	for _, i := range sdts {
+		d.Body.SDTs[i].Position = at
+	}
+	return nil
+}
+
+// ReplaceSection replaces the content of the section under the heading at
+// path (see FindHeading) with paragraphs, keeping the heading. Everything
+// up to the next heading of its level or higher goes, subsections and
+// tables included.
+func (d *Document) ReplaceSection(path string, paras ...Paragraph) error {
+	start, end, err := d.SectionRange(path)
+	if err != nil {
+		return err
+	}
+	// Tables and content controls after the heading, up to the one before
+	// the next heading, are part of the section
+	d.Body.removeBlocks(start+1, end+1)
+	d.Body.SpliceParagraphs(start+1, end-start, paras...)
+	return nil
+}
@@ -0,0 +1,120 @@
+package docx
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestGetOutline(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Preface")
+	doc.AddParagraph("Introduction", WithStyle("Heading1"))
+	doc.AddParagraph("Background", WithStyle("Heading2"))
+	doc.AddParagraph("History", WithStyle("Heading3"))
+	doc.AddParagraph("Scope", WithStyle("Heading2"))
+	doc.AddParagraph("", WithStyle("Heading2"))
+	doc.AddParagraph("Methods", WithStyle("Heading1"))
+	doc.AddParagraph("Notes", WithStyle("Heading3"))
+
+	outline := doc.GetOutline()
+
+	if len(outline) != 2 {
+		t.Fatalf("Expected 2 top-level entries, got %d: %+v", len(outline), outline)
+	}
+	intro := outline[0]
+	if intro.Text != "Introduction" || intro.Level != 1 || intro.Index != 1 {
+		t.Errorf("Unexpected first entry: %+v", intro.Heading)
+	}
+	if len(intro.Children) != 2 || intro.Children[0].Text != "Background" || intro.Children[1].Text != "Scope" {
+		t.Fatalf("Unexpected children of Introduction: %+v", intro.Children)
+	}
+	if len(intro.Children[0].Children) != 1 || intro.Children[0].Children[0].Text != "History" {
+		t.Errorf("Expected History under Background, got %+v", intro.Children[0].Children)
+	}
+
+	// A skipped level nests directly under the heading above it
+	methods := outline[1]
+	if methods.Text != "Methods" || len(methods.Children) != 1 || methods.Children[0].Index != 7 {
+		t.Errorf("Unexpected Methods entry: %+v", methods)
+	}
+
+	if got := New().GetOutline(); len(got) != 0 {
+		t.Errorf("Expected an empty outline, got %+v", got)
+	}
+}
+
+func TestHeadingPaths(t *testing.T) {
+	newDoc := func() *Document {
+		doc := New()
+		doc.AddParagraph("Chapter 1", WithStyle("Heading1"))
+		doc.AddParagraph("Methods", WithStyle("Heading2"))
+		doc.AddParagraph("Old methods")
+		doc.AddParagraph("Chapter 2", WithStyle("Heading1"))
+		doc.AddParagraph("Methods", WithStyle("Heading2"))
+		doc.AddParagraph("Survey")
+		doc.AddParagraph("Results", WithStyle("Heading2"))
+		doc.AddParagraph("Appendix  a", WithStyle("Heading1"))
+		doc.AddParagraph("Data")
+		doc.AddParagraph("Sources", WithStyle("Heading2"))
+		doc.AddParagraph("Archive")
+		doc.AddTable(2, 2)
+		return doc
+	}
+	texts := func(doc *Document) []string {
+		var got []string
+		for i := range doc.Body.Paragraphs {
+			got = append(got, doc.paragraphOwnText(&doc.Body.Paragraphs[i]))
+		}
+		return got
+	}
+
+	doc := newDoc()
+	if h, err := doc.FindHeading("chapter 2 > METHODS"); err != nil || h.Index != 4 {
+		t.Errorf("Expected Chapter 2 > Methods at 4, got %+v, %v", h, err)
+	}
+	if h, err := doc.FindHeading("Methods"); err != nil || h.Index != 1 {
+		t.Errorf("Expected the first Methods at 1, got %+v, %v", h, err)
+	}
+	if h, err := doc.FindHeading("Appendix A > Sources"); err != nil || h.Index != 9 {
+		t.Errorf("Expected Appendix A > Sources at 9, got %+v, %v", h, err)
+	}
+	if _, err := doc.FindHeading("Chapter 1 > Results"); !errors.Is(err, ErrHeadingNotFound) {
+		t.Errorf("Expected ErrHeadingNotFound, got %v", err)
+	}
+	if start, end, err := doc.SectionRange("Chapter 2"); err != nil || start != 3 || end != 6 {
+		t.Errorf("Expected Chapter 2 to span 3 to 6, got %d to %d, %v", start, end, err)
+	}
+
+	if err := doc.InsertAfterHeading("Chapter 2 > Methods", NewParagraph("Interviews")); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.AppendToSection("Chapter 2", NewParagraph("Summary")); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Chapter 1", "Methods", "Old methods", "Chapter 2", "Methods", "Interviews", "Survey", "Results", "Summary", "Appendix  a"}
+	if got := texts(doc); !slices.Equal(got[:len(want)], want) {
+		t.Errorf("Unexpected paragraphs after inserting: %q", got)
+	}
+
+	// The table closing the appendix stays before paragraphs appended to it
+	doc = newDoc()
+	if err := doc.AppendToSection("Appendix A > Sources", NewParagraph("More")); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Body.Tables[0].Position != 11 || texts(doc)[11] != "More" {
+		t.Errorf("Unexpected body after appending: %q, table at %d", texts(doc), doc.Body.Tables[0].Position)
+	}
+
+	doc = newDoc()
+	if err := doc.ReplaceSection("Appendix A", NewParagraph("See online")); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"Chapter 1", "Methods", "Old methods", "Chapter 2", "Methods", "Survey", "Results", "Appendix  a", "See online"}
+	if got := texts(doc); !slices.Equal(got, want) {
+		t.Errorf("Unexpected paragraphs after replacing: %q", got)
+	}
+	if len(doc.Body.Tables) != 0 {
+		t.Errorf("Expected the appendix table removed, got %d tables", len(doc.Body.Tables))
+	}
+}
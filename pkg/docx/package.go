@@ -0,0 +1,277 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Well-known part names and relationship types
+const (
+	documentPart     = "word/document.xml"
+	documentRelsPart = "word/_rels/document.xml.rels"
+	contentTypesPart = "[Content_Types].xml"
+	stylesPart       = "word/styles.xml"
+	numberingPart    = "word/numbering.xml"
+
+	relTypeImage     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image"
+	relTypeStyles    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles"
+	relTypeNumbering = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering"
+	relTypeHyperlink = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+	relTypeHeader    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/header"
+	relTypeFooter    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer"
+
+	contentTypeStyles    = "application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"
+	contentTypeNumbering = "application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"
+	contentTypeHeader    = "application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"
+	contentTypeFooter    = "application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"
+)
+
+// GetPart returns the raw content of a package part (e.g. "word/styles.xml").
+// The main document part is encoded from the body on each call, so it has
+// every edit made so far. Large media parts left in the file by Open are
+// read from it on each call; OpenPart streams them instead.
+func (d *Document) GetPart(name string) ([]byte, bool) {
+	if name == documentPart {
+		data, err := d.marshalDocument()
+		return data, err == nil
+	}
+	if data, ok := d.files[name]; ok {
+		return data, true
+	}
+	if f, ok := d.lazy[name]; ok {
+		data, err := readLimitedPart(f, d.limits.lazyPartLimit())
+		return data, err == nil
+	}
+	return nil, false
+}
+
+// OpenPart returns a reader of the content of a package part, which reads
+// large media parts from the file without holding them in memory
+func (d *Document) OpenPart(name string) (io.ReadCloser, error) {
+	if name == documentPart {
+		data, err := d.marshalDocument()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal document: %w", err)
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	if data, ok := d.files[name]; ok {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	if f, ok := d.lazy[name]; ok {
+		rc, err := openLimitedPart(f, d.limits.lazyPartLimit())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read part %s: %w", name, err)
+		}
+		return rc, nil
+	}
+	return nil, fmt.Errorf("part %s not found", name)
+}
+
+// hasPart reports whether the package has a part, in memory or not
+func (d *Document) hasPart(name string) bool {
+	if _, ok := d.files[name]; ok {
+		return true
+	}
+	_, ok := d.lazy[name]
+	return ok
+}
+
+// SetPart creates or replaces a package part
+func (d *Document) SetPart(name string, data []byte) {
+	if d.files == nil {
+		d.files = make(map[string][]byte)
+	}
+	d.files[name] = data
+	delete(d.lazy, name)
+}
+
+// DeletePart removes a package part
+func (d *Document) DeletePart(name string) {
+	delete(d.files, name)
+	delete(d.lazy, name)
+}
+
+// removePart deletes a part together with its own relationships, the
+// relationships pointing to it and its content type override
+func (d *Document) removePart(name string) {
+	d.DeletePart(name)
+	delete(d.files, path.Join(path.Dir(name), "_rels", path.Base(name)+".rels"))
+
+	for relsName, data := range d.files {
+		if !strings.HasSuffix(relsName, ".rels") {
+			continue
+		}
+		// Targets are relative to the folder holding the _rels folder
+		sourceDir := path.Dir(path.Dir(relsName))
+		data = relationshipPattern.ReplaceAllFunc(data, func(rel []byte) []byte {
+			m := relationshipTargetPattern.FindSubmatch(rel)
+			if m == nil {
+				return rel
+			}
+			target := string(m[1])
+			if strings.HasPrefix(target, "/") {
+				target = strings.TrimPrefix(target, "/")
+			} else {
+				target = path.Join(sourceDir, target)
+			}
+			if target == name {
+				return nil
+			}
+			return rel
+		})
+		d.files[relsName] = data
+	}
+
+	if data, ok := d.files[contentTypesPart]; ok {
+		pattern := regexp.MustCompile(`[ \t]*<Override\s[^>]*PartName="/` + regexp.QuoteMeta(name) + `"[^>]*/>\r?\n?`)
+		d.files[contentTypesPart] = pattern.ReplaceAll(data, nil)
+	}
+}
+
+var (
+	relationshipPattern       = regexp.MustCompile(`[ \t]*<Relationship\s[^>]*/>\r?\n?`)
+	relationshipTargetPattern = regexp.MustCompile(`\bTarget="([^"]*)"`)
+)
+
+// PartNames returns the names of all parts in the package, sorted
+func (d *Document) PartNames() []string {
+	names := make([]string, 0, len(d.files)+len(d.lazy))
+	for name := range d.files {
+		names = append(names, name)
+	}
+	for name := range d.lazy {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetRelationships returns the relationships of the main document part
+func (d *Document) GetRelationships() ([]Relationship, error) {
+	data, ok := d.files[documentRelsPart]
+	if !ok {
+		return nil, nil
+	}
+
+	var rels Relationships
+	if err := xml.Unmarshal(data, &rels); err != nil {
+		return nil, fmt.Errorf("failed to parse document relationships: %w", err)
+	}
+	return rels.Relationships, nil
+}
+
+// findRelationship returns the relationship with the given ID
+func (d *Document) findRelationship(relID string) (Relationship, bool) {
+	rels, err := d.GetRelationships()
+	if err != nil {
+		return Relationship{}, false
+	}
+	for _, rel := range rels {
+		if rel.ID == relID {
+			return rel, true
+		}
+	}
+	return Relationship{}, false
+}
+
+// hasRelationshipType reports whether the main document has a relationship of the given type
+func (d *Document) hasRelationshipType(relType string) bool {
+	rels, err := d.GetRelationships()
+	if err != nil {
+		return false
+	}
+	for _, rel := range rels {
+		if rel.Type == relType {
+			return true
+		}
+	}
+	return false
+}
+
+// addRelationship adds a document relationship and returns its new ID
+func (d *Document) addRelationship(relType, target string) string {
+	relID := fmt.Sprintf("rId%d", d.getNextRelationshipID())
+
+	relsData, ok := d.files[documentRelsPart]
+	if !ok {
+		relsData = []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+</Relationships>`)
+	}
+
+	newRel := fmt.Sprintf(`	<Relationship Id="%s" Type="%s" Target="%s"/>`, relID, relType, target)
+	relsStr := strings.Replace(string(relsData), "</Relationships>", newRel+"\n</Relationships>", 1)
+	d.files[documentRelsPart] = []byte(relsStr)
+
+	return relID
+}
+
+// addExternalRelationship adds a document relationship pointing outside the package and returns its new ID
+func (d *Document) addExternalRelationship(relType, target string) string {
+	relID := fmt.Sprintf("rId%d", d.getNextRelationshipID())
+
+	relsData, ok := d.files[documentRelsPart]
+	if !ok {
+		relsData = []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+</Relationships>`)
+	}
+
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(target))
+	newRel := fmt.Sprintf(`	<Relationship Id="%s" Type="%s" Target="%s" TargetMode="External"/>`, relID, relType, escaped.String())
+	relsStr := strings.Replace(string(relsData), "</Relationships>", newRel+"\n</Relationships>", 1)
+	d.files[documentRelsPart] = []byte(relsStr)
+
+	return relID
+}
+
+// removeRelationship removes a document relationship by ID
+func (d *Document) removeRelationship(relID string) {
+	relsData, ok := d.files[documentRelsPart]
+	if !ok {
+		return
+	}
+
+	pattern := regexp.MustCompile(`[ \t]*<Relationship\s[^>]*\bId="` + regexp.QuoteMeta(relID) + `"[^>]*/>\r?\n?`)
+	d.files[documentRelsPart] = pattern.ReplaceAll(relsData, nil)
+}
+
+// isPartReferenced reports whether any relationship in the package targets
+// the given part, named relative to word/
+func (d *Document) isPartReferenced(target string) bool {
+	for name, data := range d.files {
+		if !strings.HasSuffix(name, ".rels") {
+			continue
+		}
+		if strings.Contains(string(data), `Target="`+target+`"`) {
+			return true
+		}
+	}
+	return false
+}
+
+// registerContentTypeOverride adds an Override entry for a part if it is not already registered
+func (d *Document) registerContentTypeOverride(partName, contentType string) {
+	contentTypesData, ok := d.files[contentTypesPart]
+	if !ok {
+		contentTypesData = getDefaultDocxFiles()[contentTypesPart]
+	}
+
+	contentTypesStr := string(contentTypesData)
+	partEntry := fmt.Sprintf(`PartName="/%s"`, partName)
+	if strings.Contains(contentTypesStr, partEntry) {
+		return
+	}
+
+	newEntry := fmt.Sprintf(`	<Override PartName="/%s" ContentType="%s"/>`, partName, contentType)
+	contentTypesStr = strings.Replace(contentTypesStr, "</Types>", newEntry+"\n</Types>", 1)
+	d.files[contentTypesPart] = []byte(contentTypesStr)
+}
@@ -0,0 +1,288 @@
+package docx
+
+import (
+	"encoding/xml"
+	"slices"
+	"sort"
+)
+
+// UnmarshalXML decodes the paragraph, recording where links, fields,
+// equations and bookmarks sit among its runs so the original order can be
+// written back
+func (p *Paragraph) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	p.XMLName = start.Name
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if err := p.decodeChild(dec, t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func (p *Paragraph) decodeChild(dec *xml.Decoder, t xml.StartElement) error {
+	switch t.Name.Local {
+	case "pPr":
+		var props PProps
+		if err := dec.DecodeElement(&props, &t); err != nil {
+			return err
+		}
+		p.Props = &props
+	case "r":
+		var r Run
+		if err := dec.DecodeElement(&r, &t); err != nil {
+			return err
+		}
+		p.Runs = append(p.Runs, r)
+	case "hyperlink":
+		var h Hyperlink
+		if err := dec.DecodeElement(&h, &t); err != nil {
+			return err
+		}
+		h.Position = len(p.Runs)
+		p.Hyperlinks = append(p.Hyperlinks, h)
+	case "fldSimple":
+		var f SimpleField
+		if err := dec.DecodeElement(&f, &t); err != nil {
+			return err
+		}
+		f.Position = len(p.Runs)
+		p.Fields = append(p.Fields, f)
+	case "bookmarkStart":
+		var b BookmarkStart
+		if err := dec.DecodeElement(&b, &t); err != nil {
+			return err
+		}
+		b.Position = len(p.Runs)
+		p.BookmarkStarts = append(p.BookmarkStarts, b)
+	case "bookmarkEnd":
+		var b BookmarkEnd
+		if err := dec.DecodeElement(&b, &t); err != nil {
+			return err
+		}
+		b.Position = len(p.Runs)
+		p.BookmarkEnds = append(p.BookmarkEnds, b)
+	case "oMath", "oMathPara":
+		if t.Name.Space != mathNamespace {
+			return dec.Skip()
+		}
+		math := Equation{Position: len(p.Runs)}
+		if err := dec.DecodeElement(&math.RawElement, &t); err != nil {
+			return err
+		}
+		if t.Name.Local == "oMathPara" {
+			p.MathParas = append(p.MathParas, math)
+		} else {
+			p.Math = append(p.Math, math)
+		}
+	default:
+		return dec.Skip()
+	}
+	return nil
+}
+
+// MarshalXML writes the paragraph with its runs, links, fields, equations
+// and bookmarks in document order
+func (p Paragraph) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "p"}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if p.Props != nil {
+		if err := enc.EncodeElement(p.Props, xml.StartElement{Name: xml.Name{Local: "pPr"}}); err != nil {
+			return err
+		}
+	}
+	var err error
+	p.eachChild(func(i int) {
+		if err == nil {
+			err = enc.EncodeElement(&p.Runs[i], xml.StartElement{Name: xml.Name{Local: "r"}})
+		}
+	}, func(v interface{}) {
+		if err == nil {
+			err = enc.Encode(v)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// inlineElement is an element of a paragraph other than its own runs, with
+// the number of runs before it
+type inlineElement struct {
+	position *int
+	value    interface{}
+}
+
+// inlineElements returns the elements of the paragraph other than its own
+// runs in document order. Elements at the same position go bookmark starts
+// first, then links, fields and equations, then bookmark ends.
+func (p *Paragraph) inlineElements() []inlineElement {
+	var elements []inlineElement
+	for i := range p.BookmarkStarts {
+		elements = append(elements, inlineElement{&p.BookmarkStarts[i].Position, &p.BookmarkStarts[i]})
+	}
+	for i := range p.Hyperlinks {
+		elements = append(elements, inlineElement{&p.Hyperlinks[i].Position, &p.Hyperlinks[i]})
+	}
+	for i := range p.Fields {
+		elements = append(elements, inlineElement{&p.Fields[i].Position, &p.Fields[i]})
+	}
+	for i := range p.Math {
+		elements = append(elements, inlineElement{&p.Math[i].Position, &p.Math[i]})
+	}
+	for i := range p.MathParas {
+		elements = append(elements, inlineElement{&p.MathParas[i].Position, &p.MathParas[i]})
+	}
+	for i := range p.BookmarkEnds {
+		elements = append(elements, inlineElement{&p.BookmarkEnds[i].Position, &p.BookmarkEnds[i]})
+	}
+	sort.SliceStable(elements, func(i, j int) bool { return *elements[i].position < *elements[j].position })
+	return elements
+}
+
+// eachChild calls run with the index of each of the paragraph's own runs,
+// and element with each of its other elements, in document order
+func (p *Paragraph) eachChild(run func(i int), element func(v interface{})) {
+	elements := p.inlineElements()
+	next := 0
+	for i := range p.Runs {
+		for next < len(elements) && *elements[next].position <= i {
+			element(elements[next].value)
+			next++
+		}
+		run(i)
+	}
+	for ; next < len(elements); next++ {
+		element(elements[next].value)
+	}
+}
+
+// Content returns the runs, links, fields, equations and bookmarks of the
+// paragraph in document order, as *Run, *Hyperlink, *SimpleField,
+// *Equation, *BookmarkStart and *BookmarkEnd values
+func (p *Paragraph) Content() []interface{} {
+	content := make([]interface{}, 0, len(p.Runs))
+	p.eachChild(func(i int) {
+		content = append(content, &p.Runs[i])
+	}, func(v interface{}) {
+		content = append(content, v)
+	})
+	return content
+}
+
+// SpliceRuns replaces count of the paragraph's own runs starting at index
+// with runs. Links, fields, equations and bookmarks keep their place
+// relative to the surrounding runs; those that sat inside the replaced
+// range end up right after the inserted runs.
+func (p *Paragraph) SpliceRuns(index, count int, runs ...Run) {
+	if index < 0 {
+		index = 0
+	}
+	if index > len(p.Runs) {
+		index = len(p.Runs)
+	}
+	if count < 0 {
+		count = 0
+	}
+	if index+count > len(p.Runs) {
+		count = len(p.Runs) - index
+	}
+
+	p.Runs = slices.Concat(p.Runs[:index], runs, p.Runs[index+count:])
+	p.moveElements(func(pos int) int {
+		if pos <= index {
+			return pos
+		}
+		return max(pos, index+count) - count + len(runs)
+	})
+}
+
+// ReplaceRuns replaces the paragraph's own runs with runs built from them,
+// where from[i] is the index of the run runs[i] was built from. Links,
+// fields, equations and bookmarks go before the first new run built from a
+// run at or after their place, or last when there is none.
+func (p *Paragraph) ReplaceRuns(runs []Run, from []int) {
+	p.moveElements(func(pos int) int {
+		for i, f := range from {
+			if f >= pos {
+				return i
+			}
+		}
+		return len(runs)
+	})
+	p.Runs = runs
+}
+
+// spliceRuns replaces count runs of a run slice of the paragraph starting
+// at index, moving the other elements along for its own runs
+func (p *Paragraph) spliceRuns(runs *[]Run, index, count int, inserted ...Run) {
+	if runs == &p.Runs {
+		p.SpliceRuns(index, count, inserted...)
+		return
+	}
+	*runs = slices.Concat((*runs)[:index], inserted, (*runs)[index+count:])
+}
+
+// filterRuns keeps the runs of a run slice of the paragraph keep returns
+// true for, moving the other elements along for its own runs
+func (p *Paragraph) filterRuns(runs *[]Run, keep func(r *Run) bool) {
+	kept := (*runs)[:0]
+	before := make([]int, len(*runs)+1) // Runs kept before each index
+	for i := range *runs {
+		before[i] = len(kept)
+		if keep(&(*runs)[i]) {
+			kept = append(kept, (*runs)[i])
+		}
+	}
+	before[len(*runs)] = len(kept)
+
+	if runs == &p.Runs {
+		p.moveElements(func(pos int) int {
+			return before[min(max(pos, 0), len(before)-1)]
+		})
+	}
+	*runs = kept
+}
+
+// ReplaceField replaces the paragraph's simple field i with runs, in the
+// field's place among the other runs
+func (p *Paragraph) ReplaceField(i int, runs ...Run) {
+	field := &p.Fields[i]
+	pos := field.Position
+
+	// Elements after the field at its position go after the runs
+	var after []*int
+	found := false
+	for _, e := range p.inlineElements() {
+		if found && *e.position == pos {
+			after = append(after, e.position)
+		}
+		found = found || e.value == field
+	}
+
+	p.SpliceRuns(pos, 0, runs...)
+	for _, position := range after {
+		*position += len(runs)
+	}
+	p.Fields = slices.Delete(p.Fields, i, i+1)
+}
+
+// moveElements sets the position of each element other than the
+// paragraph's own runs to move(position)
+func (p *Paragraph) moveElements(move func(pos int) int) {
+	for _, e := range p.inlineElements() {
+		*e.position = move(*e.position)
+	}
+}
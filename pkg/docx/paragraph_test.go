@@ -0,0 +1,97 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+const testInlineDocumentXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<w:body>
+<w:p><w:r><w:t xml:space="preserve">See </w:t></w:r><w:bookmarkStart w:id="0" w:name="terms"/><w:hyperlink r:id="rId9"><w:r><w:t>the terms</w:t></w:r></w:hyperlink><w:bookmarkEnd w:id="0"/><w:r><w:t xml:space="preserve"> for details.</w:t></w:r></w:p>
+</w:body>
+</w:document>`
+
+func newInlineDocument(t *testing.T) *Document {
+	t.Helper()
+	doc := New()
+	if err := doc.parseDocument([]byte(testInlineDocumentXML)); err != nil {
+		t.Fatalf("parseDocument failed: %v", err)
+	}
+	return doc
+}
+
+func TestParagraphOrder(t *testing.T) {
+	doc := newInlineDocument(t)
+	if text, _ := doc.GetParagraphText(0); text != "See the terms for details." {
+		t.Errorf("Expected the link in place, got %q", text)
+	}
+	if text := doc.GetText(); text != "See  the terms  for details." {
+		t.Errorf("Expected the link in place in GetText, got %q", text)
+	}
+
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	reopened, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+	if text, _ := reopened.GetParagraphText(0); text != "See the terms for details." {
+		t.Errorf("Expected the link in place after saving, got %q", text)
+	}
+	part, _ := reopened.GetPart(documentPart)
+	xml := string(part)
+	order := []string{"See ", "<bookmarkStart", "the terms", "<bookmarkEnd", " for details."}
+	for i := 1; i < len(order); i++ {
+		if strings.Index(xml, order[i-1]) > strings.Index(xml, order[i]) {
+			t.Errorf("Expected %q before %q in %s", order[i-1], order[i], xml)
+		}
+	}
+
+	var kinds []string
+	for _, child := range reopened.Body.Paragraphs[0].Content() {
+		switch child.(type) {
+		case *Run:
+			kinds = append(kinds, "r")
+		case *Hyperlink:
+			kinds = append(kinds, "hyperlink")
+		case *BookmarkStart, *BookmarkEnd:
+			kinds = append(kinds, "bookmark")
+		}
+	}
+	if got := strings.Join(kinds, " "); got != "r bookmark hyperlink bookmark r" {
+		t.Errorf("Unexpected content order %s", got)
+	}
+}
+
+func TestParagraphOrderEdits(t *testing.T) {
+	doc := newInlineDocument(t)
+	if err := doc.InsertTextAt(0, 18, "further ", WithBold()); err != nil {
+		t.Fatalf("InsertTextAt failed: %v", err)
+	}
+	if text, _ := doc.GetParagraphText(0); text != "See the terms for further details." {
+		t.Errorf("Unexpected text after inserting: %q", text)
+	}
+	if err := doc.DeleteTextRange(0, 0, 4); err != nil {
+		t.Fatalf("DeleteTextRange failed: %v", err)
+	}
+	p := &doc.Body.Paragraphs[0]
+	if text, _ := doc.GetParagraphText(0); text != "the terms for further details." || p.Hyperlinks[0].Position != 0 {
+		t.Errorf("Expected the link first once the run before it is gone, got %q", text)
+	}
+
+	p.SpliceRuns(0, 1, Run{Text: []Text{{Content: " and "}}}, Run{Text: []Text{{Content: "all "}}})
+	if text, _ := doc.GetParagraphText(0); text != "the terms and all further details." {
+		t.Errorf("Expected the runs after the link, got %q", text)
+	}
+	if p.BookmarkStarts[0].Position != 0 || p.BookmarkEnds[0].Position != 0 {
+		t.Errorf("Expected the bookmark to stay around the link")
+	}
+
+	doc.Sanitize(SanitizeOptions{HiddenText: true})
+	if text, _ := doc.GetParagraphText(0); text != "the terms and all further details." {
+		t.Errorf("Expected sanitizing to keep the order, got %q", text)
+	}
+}
@@ -0,0 +1,111 @@
+package docx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// PartInfo describes a part of the package
+type PartInfo struct {
+	Name        string // e.g. "word/document.xml"
+	Size        int64  // Uncompressed size in bytes
+	ContentType string // From [Content_Types].xml; empty if it gives none
+
+	// Relationships are those the part holds in its .rels part, with
+	// targets as written there
+	Relationships []Relationship
+}
+
+// Parts describes every part of the package, sorted by name. The main
+// document part is described as the body is, edits included.
+func (d *Document) Parts() ([]PartInfo, error) {
+	types, err := d.contentTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	names := d.PartNames()
+	parts := make([]PartInfo, 0, len(names))
+	for _, name := range names {
+		info := PartInfo{Name: name, ContentType: types.contentType(name)}
+		if name == documentPart {
+			data, _ := d.GetPart(name)
+			info.Size = int64(len(data))
+		} else if data, ok := d.files[name]; ok {
+			info.Size = int64(len(data))
+		} else if f, ok := d.lazy[name]; ok {
+			info.Size = int64(f.UncompressedSize64)
+		}
+		if !strings.HasSuffix(name, ".rels") {
+			if info.Relationships, err = d.PartRelationships(name); err != nil {
+				return nil, err
+			}
+		}
+		parts = append(parts, info)
+	}
+	return parts, nil
+}
+
+// PartContentType returns the content type [Content_Types].xml gives a
+// part, from its override or else the default for its extension, or "" if
+// it gives none
+func (d *Document) PartContentType(name string) string {
+	types, err := d.contentTypes()
+	if err != nil {
+		return ""
+	}
+	return types.contentType(name)
+}
+
+// PartRelationships returns the relationships of a part, read from its
+// .rels part; "" names the package itself, whose relationships are in
+// _rels/.rels. Parts without a .rels part have none.
+func (d *Document) PartRelationships(name string) ([]Relationship, error) {
+	relsName := "_rels/.rels"
+	if name != "" {
+		relsName = relsPartName(name)
+	}
+	data, ok := d.files[relsName]
+	if !ok {
+		return nil, nil
+	}
+
+	var rels Relationships
+	if err := xml.Unmarshal(data, &rels); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", relsName, err)
+	}
+	return rels.Relationships, nil
+}
+
+// contentTypes parses [Content_Types].xml; packages without one have no
+// content types
+func (d *Document) contentTypes() (*contentTypesXML, error) {
+	var types contentTypesXML
+	data, ok := d.files[contentTypesPart]
+	if !ok {
+		return &types, nil
+	}
+	if err := xml.Unmarshal(data, &types); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", contentTypesPart, err)
+	}
+	return &types, nil
+}
+
+// contentType returns the content type of a part: its override, or the
+// default for its extension. Both compare without regard to case.
+func (t *contentTypesXML) contentType(name string) string {
+	for _, o := range t.Overrides {
+		if strings.EqualFold(strings.TrimPrefix(o.PartName, "/"), name) {
+			return o.ContentType
+		}
+	}
+	ext := strings.TrimPrefix(path.Ext(name), ".")
+	for _, def := range t.Defaults {
+		if strings.EqualFold(def.Extension, ext) {
+			return def.ContentType
+		}
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package docx
+
+import "testing"
+
+func TestParts(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Logo below")
+	if err := doc.AddImageFromBytes("logo.png", createPNGData()); err != nil {
+		t.Fatalf("AddImageFromBytes failed: %v", err)
+	}
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	loaded, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+
+	parts, err := loaded.Parts()
+	if err != nil {
+		t.Fatalf("Parts failed: %v", err)
+	}
+	if len(parts) != len(loaded.PartNames()) {
+		t.Fatalf("Expected %d parts, got %d", len(loaded.PartNames()), len(parts))
+	}
+	byName := make(map[string]PartInfo)
+	for i, part := range parts {
+		if i > 0 && parts[i-1].Name >= part.Name {
+			t.Errorf("Expected parts sorted by name, got %s before %s", parts[i-1].Name, part.Name)
+		}
+		byName[part.Name] = part
+	}
+
+	main, ok := byName[documentPart]
+	if !ok {
+		t.Fatalf("Main document part not listed")
+	}
+	if main.ContentType != "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml" {
+		t.Errorf("Expected the main document content type, got %q", main.ContentType)
+	}
+	if raw, _ := loaded.GetPart(documentPart); main.Size != int64(len(raw)) {
+		t.Errorf("Expected size %d, got %d", len(raw), main.Size)
+	}
+	image := ""
+	for _, rel := range main.Relationships {
+		if rel.Type == relTypeImage {
+			image = relationshipTargetPart("word", rel.Target)
+		}
+	}
+	if info, ok := byName[image]; !ok || info.ContentType != "image/png" || info.Size == 0 {
+		t.Errorf("Expected the image part to be a PNG found from the document, got %q: %+v", image, info)
+	}
+	if rels := byName[documentRelsPart].Relationships; rels != nil {
+		t.Errorf("Expected .rels parts to have no relationships, got %v", rels)
+	}
+
+	pkg, err := loaded.PartRelationships("")
+	if err != nil || len(pkg) == 0 || pkg[0].Target != documentPart {
+		t.Errorf("Expected the package to point to the main document, got %v (%v)", pkg, err)
+	}
+	if got := loaded.PartContentType("word/MEDIA/other.PNG"); got != "image/png" {
+		t.Errorf("Expected the default for .png regardless of case, got %q", got)
+	}
+	if got := loaded.PartContentType("word/stray.bin"); got != "" {
+		t.Errorf("Expected no content type, got %q", got)
+	}
+
+	loaded.SetPart(contentTypesPart, []byte("<Types"))
+	if _, err := loaded.Parts(); err == nil {
+		t.Error("Expected an error for unreadable content types")
+	}
+}
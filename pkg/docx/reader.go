@@ -2,33 +2,173 @@ package docx
 
 import (
 	"archive/zip"
+	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"strings"
 )
 
-// Open opens and reads a .docx file
-func Open(filePath string) (*Document, error) {
-	doc := &Document{
-		FilePath: filePath,
-		files:    make(map[string][]byte),
+// lazyPartSize is the size from which media parts are left in the archive
+// until they are needed, rather than read into memory when opening
+const lazyPartSize = 1 << 20
+
+// OpenOptions limits what Open and ReadBytes accept, so malformed or hostile
+// packages fail with an error instead of exhausting memory. A zero field
+// uses the limit of DefaultOpenOptions.
+type OpenOptions struct {
+	// MaxPartSize is the largest part, in bytes. Large media parts left in
+	// the file are checked against it when opening and again each time
+	// GetPart or OpenPart reads them.
+	MaxPartSize int64
+
+	// MaxParts is the most parts the package may hold
+	MaxParts int
+
+	// MaxTotalSize is the most bytes read into memory for all parts
+	// together, which small archives that decompress to many parts near
+	// MaxPartSize would otherwise reach. Large media parts left in the
+	// file aren't held in memory, so they aren't counted, but a single
+	// read of one may not exceed it either.
+	MaxTotalSize int64
+}
+
+// DefaultOpenOptions returns the limits Open and ReadBytes use
+func DefaultOpenOptions() OpenOptions {
+	return OpenOptions{
+		MaxPartSize:  256 << 20,
+		MaxParts:     10000,
+		MaxTotalSize: 1 << 30,
 	}
+}
 
+// withDefaults returns the options with zero fields set from
+// DefaultOpenOptions
+func (o OpenOptions) withDefaults() OpenOptions {
+	defaults := DefaultOpenOptions()
+	if o.MaxPartSize <= 0 {
+		o.MaxPartSize = defaults.MaxPartSize
+	}
+	if o.MaxParts <= 0 {
+		o.MaxParts = defaults.MaxParts
+	}
+	if o.MaxTotalSize <= 0 {
+		o.MaxTotalSize = defaults.MaxTotalSize
+	}
+	return o
+}
+
+// lazyPartLimit is the most bytes a single read of a part left in the file
+// may return
+func (o OpenOptions) lazyPartLimit() int64 {
+	o = o.withDefaults()
+	return min(o.MaxPartSize, o.MaxTotalSize)
+}
+
+// Open opens and reads a .docx file. Media parts of a megabyte or more, such
+// as videos and large pictures, are not read into memory: they are read from
+// the file when asked for and copied from it as they are when saving, so
+// the file is kept open while the document has such parts. Close releases
+// it; otherwise it is closed once the document is garbage collected.
+func Open(filePath string) (*Document, error) {
+	return OpenWithOptions(filePath, DefaultOpenOptions())
+}
+
+// OpenWithOptions opens and reads a .docx file like Open, with the given limits
+func OpenWithOptions(filePath string, opts OpenOptions) (*Document, error) {
 	// Open the docx file (which is a zip archive)
-	r, err := zip.OpenReader(filePath)
+	f, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open docx file: %w", err)
 	}
-	defer r.Close()
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open docx file: %w", err)
+	}
+	r, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open docx file: %w: %w", ErrInvalidPackage, err)
+	}
+
+	doc, err := readPackage(r, opts)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if len(doc.lazy) > 0 {
+		doc.source = f
+	} else {
+		f.Close()
+	}
+	doc.FilePath = filePath
+	return doc, nil
+}
+
+// Close releases the file a document read with Open keeps its large media
+// parts in. Those parts can't be read or saved afterwards, so close the
+// document once it is saved or no longer needed; copies made with Clone
+// share the file. Closing a document without such parts does nothing.
+func (d *Document) Close() error {
+	if d.source == nil {
+		return nil
+	}
+	err := d.source.Close()
+	d.source = nil
+	return err
+}
+
+// isLazyPart reports whether a part is left in the archive until needed:
+// large parts other than XML, which is parsed and edited in memory
+func isLazyPart(f *zip.File) bool {
+	ext := path.Ext(f.Name)
+	return f.UncompressedSize64 >= lazyPartSize && ext != ".xml" && ext != ".rels"
+}
+
+// readPackage reads the parts of a .docx archive into memory, except large
+// media parts, and parses the main document
+func readPackage(r *zip.Reader, opts OpenOptions) (*Document, error) {
+	opts = opts.withDefaults()
+	if len(r.File) > opts.MaxParts {
+		return nil, fmt.Errorf("%w: the package has %d parts, the limit is %d", ErrTooManyParts, len(r.File), opts.MaxParts)
+	}
+
+	doc := &Document{
+		files:  make(map[string][]byte),
+		limits: opts,
+	}
 
 	// Read all files from the zip
 	var documentXML []byte
+	var total int64 // Bytes read so far
 	for _, f := range r.File {
-		data, err := readZipFile(f)
+		if !isSafePartName(f.Name) {
+			return nil, fmt.Errorf("%w %q", ErrUnsafePartName, f.Name)
+		}
+		if isLazyPart(f) {
+			if limit := opts.lazyPartLimit(); f.UncompressedSize64 > uint64(limit) {
+				return nil, fmt.Errorf("failed to read file %s: %w: %d bytes, the limit is %d", f.Name, ErrPartTooLarge, f.UncompressedSize64, limit)
+			}
+			if doc.lazy == nil {
+				doc.lazy = make(map[string]*zip.File)
+			}
+			doc.lazy[f.Name] = f
+			continue
+		}
+		// A part may be no larger than what is left of the total
+		limit := min(opts.MaxPartSize, opts.MaxTotalSize-total)
+		data, err := readLimitedPart(f, limit)
+		if errors.Is(err, ErrPartTooLarge) && limit < opts.MaxPartSize && f.UncompressedSize64 <= uint64(opts.MaxPartSize) {
+			return nil, fmt.Errorf("failed to read file %s: %w: the parts add up to more than %d bytes", f.Name, ErrPackageTooLarge, opts.MaxTotalSize)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to read file %s: %w", f.Name, err)
 		}
+		total += int64(len(data))
 		doc.files[f.Name] = data
 
 		// Parse the main document.xml
@@ -38,12 +178,12 @@ func Open(filePath string) (*Document, error) {
 	}
 
 	if documentXML == nil {
-		return nil, fmt.Errorf("document.xml not found in docx file")
+		return nil, fmt.Errorf("%w: document.xml not found in docx file", ErrInvalidPackage)
 	}
 
 	// Parse the XML document
 	if err := doc.parseDocument(documentXML); err != nil {
-		return nil, fmt.Errorf("failed to parse document.xml: %w", err)
+		return nil, fmt.Errorf("failed to parse document.xml: %w: %w", ErrInvalidPackage, err)
 	}
 
 	// Initialize counters based on existing content
@@ -53,6 +193,76 @@ func Open(filePath string) (*Document, error) {
 	return doc, nil
 }
 
+// readLimitedPart reads a part of at most limit bytes, checking both the
+// size the archive declares and the size actually read
+func readLimitedPart(f *zip.File, limit int64) ([]byte, error) {
+	rc, err := openLimitedPart(f, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// openLimitedPart opens a part for reading like readLimitedPart, failing
+// with ErrPartTooLarge once more than limit bytes are read
+func openLimitedPart(f *zip.File, limit int64) (io.ReadCloser, error) {
+	if f.UncompressedSize64 > uint64(limit) {
+		return nil, fmt.Errorf("%w: %d bytes, the limit is %d", ErrPartTooLarge, f.UncompressedSize64, limit)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPackage, err)
+	}
+	return &limitedPartReader{rc: rc, limit: limit, left: limit}, nil
+}
+
+// limitedPartReader reads a part, failing once it is longer than allowed
+type limitedPartReader struct {
+	rc    io.ReadCloser
+	limit int64
+	left  int64 // Bytes that may still be read
+}
+
+func (l *limitedPartReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.rc.Read(p)
+	if int64(n) > l.left {
+		return int(l.left), fmt.Errorf("%w: more than %d bytes", ErrPartTooLarge, l.limit)
+	}
+	l.left -= int64(n)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("%w: %w", ErrInvalidPackage, err)
+	}
+	return n, err
+}
+
+func (l *limitedPartReader) Close() error {
+	return l.rc.Close()
+}
+
+// isSafePartName reports whether a part name stays inside the package: it
+// is neither absolute nor leads up out of it through ".." segments
+func isSafePartName(name string) bool {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if strings.HasPrefix(name, "/") || len(name) > 1 && name[1] == ':' {
+		return false
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
 // parseDocument parses the main document.xml content
 func (d *Document) parseDocument(data []byte) error {
 	// Define the document structure with namespace
@@ -78,23 +288,19 @@ func (d *Document) parseDocument(data []byte) error {
 	return nil
 }
 
-// ReadBytes reads a .docx file from bytes
+// ReadBytes reads a .docx file from bytes without touching the filesystem
 func ReadBytes(data []byte) (*Document, error) {
-	// Create a temporary file
-	tmpFile, err := os.CreateTemp("", "docx-*.docx")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
-	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
+	return ReadBytesWithOptions(data, DefaultOpenOptions())
+}
 
-	// Write data to temp file
-	if _, err := tmpFile.Write(data); err != nil {
-		return nil, fmt.Errorf("failed to write to temp file: %w", err)
+// ReadBytesWithOptions reads a .docx file from bytes like ReadBytes, with the
+// given limits
+func ReadBytesWithOptions(data []byte, opts OpenOptions) (*Document, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open docx data: %w: %w", ErrInvalidPackage, err)
 	}
-
-	// Open the temp file
-	return Open(tmpFile.Name())
+	return readPackage(r, opts)
 }
 
 // ReadFrom reads a .docx document from an io.Reader
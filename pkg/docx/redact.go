@@ -0,0 +1,205 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// RedactMode selects how redacted text is hidden
+type RedactMode int
+
+const (
+	// RedactBlackout replaces each redacted character with a black box (█)
+	RedactBlackout RedactMode = iota
+	// RedactRemove deletes redacted text
+	RedactRemove
+)
+
+// redactionChar is drawn in place of each redacted character
+const redactionChar = "█"
+
+// Redact hides every match of pattern in the body, including tables, content
+// controls and text boxes, and in headers, footers, footnotes, endnotes and
+// comments. In the body a match may span several runs of a paragraph; in the
+// other parts each run is matched on its own. Returns the number of matches.
+func (d *Document) Redact(pattern *regexp.Regexp, mode RedactMode) int {
+	count := 0
+	w := newWalker(DefaultMaxDepth)
+	w.paragraph = func(p *Paragraph, depth int) error {
+		count += redactParagraph(p, pattern, mode)
+		return nil
+	}
+	_ = w.walkBody(d.Body)
+
+	// The VML copies of text boxes are redacted too, without counting twice
+	w = newWalker(DefaultMaxDepth)
+	w.includeFallback = true
+	w.paragraph = func(p *Paragraph, depth int) error {
+		redactParagraph(p, pattern, mode)
+		return nil
+	}
+	_ = w.walkBody(d.Body)
+
+	for name, data := range d.files {
+		if storyPartPattern.MatchString(name) {
+			var n int
+			d.files[name], n = redactPart(data, pattern, mode)
+			count += n
+		}
+	}
+	return count
+}
+
+// RemovePersonalInfo removes the author and other identifying document
+// properties, the names recorded on comments and tracked changes, and the
+// revision history: tracked changes are accepted and revision IDs dropped.
+func (d *Document) RemovePersonalInfo() {
+	if data, ok := d.files[corePropertiesPart]; ok {
+		d.files[corePropertiesPart] = removeElements(data, "creator", "lastModifiedBy", "title", "subject",
+			"description", "keywords", "category", "revision", "lastPrinted", "contentStatus", "identifier")
+	}
+	if data, ok := d.files[appPropertiesPart]; ok {
+		d.files[appPropertiesPart] = removeElements(data, "Company", "Manager", "Template", "HyperlinkBase")
+	}
+	if data, ok := d.files[settingsPart]; ok {
+		d.files[settingsPart] = removeElements(data, "rsids", "attachedTemplate")
+	}
+
+	for name, data := range d.files {
+		if storyPartPattern.MatchString(name) {
+			d.files[name] = removeRevisionInfo(data)
+		}
+	}
+}
+
+const (
+	corePropertiesPart = "docProps/core.xml"
+	appPropertiesPart  = "docProps/app.xml"
+	settingsPart       = "word/settings.xml"
+)
+
+var (
+	// storyPartPattern matches the parts holding text outside the body
+	storyPartPattern = regexp.MustCompile(`^word/(header\d*|footer\d*|footnotes|endnotes|comments)\.xml$`)
+
+	runTextPattern       = regexp.MustCompile(`(<w:(?:t|delText)\b[^>/]*>)([^<]*)(</w:(?:t|delText)>)`)
+	deletionPattern      = regexp.MustCompile(`(?s)<w:del\b[^>]*?(?:/>|>.*?</w:del>)`)
+	insertionTagPattern  = regexp.MustCompile(`</?w:ins\b[^>]*>`)
+	rsidAttrPattern      = regexp.MustCompile(`\s+w:rsid\w*="[^"]*"`)
+	authorAttrPattern    = regexp.MustCompile(`\bw:author="[^"]*"`)
+	initialsAttrPattern  = regexp.MustCompile(`\s+w:initials="[^"]*"`)
+	elementPatternFormat = `(?s)<(?:\w+:)?%s\b[^>]*?(?:/>|>.*?</(?:\w+:)?%s>)`
+)
+
+// redactParagraph redacts the matches in the runs and links of a paragraph
+func redactParagraph(p *Paragraph, pattern *regexp.Regexp, mode RedactMode) int {
+	var texts []*Text
+	var full strings.Builder
+	p.forEachRun(func(r *Run) {
+		for k := range r.Text {
+			texts = append(texts, &r.Text[k])
+			full.WriteString(r.Text[k].Content)
+		}
+	})
+
+	var matches [][]int
+	for _, m := range pattern.FindAllStringIndex(full.String(), -1) {
+		if m[0] < m[1] {
+			matches = append(matches, m)
+		}
+	}
+	if len(matches) == 0 {
+		return 0
+	}
+
+	offset := 0
+	for _, t := range texts {
+		start, end := offset, offset+len(t.Content)
+		offset = end
+
+		var b strings.Builder
+		pos := start
+		for _, m := range matches {
+			lo, hi := max(m[0], start), min(m[1], end)
+			if lo >= hi {
+				continue
+			}
+			b.WriteString(t.Content[pos-start : lo-start])
+			if mode == RedactBlackout {
+				b.WriteString(strings.Repeat(redactionChar, utf8.RuneCountInString(t.Content[lo-start:hi-start])))
+			}
+			pos = hi
+		}
+		if pos == start {
+			continue
+		}
+		b.WriteString(t.Content[pos-start:])
+		t.Content = b.String()
+		t.Space = "preserve"
+	}
+	return len(matches)
+}
+
+// redactPart redacts the matches in the run text of a raw XML part
+func redactPart(data []byte, pattern *regexp.Regexp, mode RedactMode) ([]byte, int) {
+	count := 0
+	result := mapPartText(data, runTextPattern, func(text string) string {
+		return pattern.ReplaceAllStringFunc(text, func(match string) string {
+			count++
+			if mode == RedactBlackout {
+				return strings.Repeat(redactionChar, utf8.RuneCountInString(match))
+			}
+			return ""
+		})
+	})
+	return result, count
+}
+
+// mapPartText replaces the text of each element of a raw XML part matching
+// pattern with what fn returns for it. The pattern captures the start tag,
+// the escaped text and the end tag.
+func mapPartText(data []byte, pattern *regexp.Regexp, fn func(text string) string) []byte {
+	return pattern.ReplaceAllFunc(data, func(element []byte) []byte {
+		m := pattern.FindSubmatch(element)
+		text := html.UnescapeString(string(m[2]))
+		mapped := fn(text)
+		if mapped == text {
+			return element
+		}
+
+		var escaped bytes.Buffer
+		xml.EscapeText(&escaped, []byte(mapped))
+		return append(append(append([]byte(nil), m[1]...), escaped.Bytes()...), m[3]...)
+	})
+}
+
+// removeRevisionInfo accepts the tracked changes in a raw XML part and drops
+// revision IDs. Comment authors are renamed "Author", as Word does, since
+// the attribute is required.
+func removeRevisionInfo(data []byte) []byte {
+	data = acceptRevisions(data)
+	data = rsidAttrPattern.ReplaceAll(data, nil)
+	data = authorAttrPattern.ReplaceAll(data, []byte(`w:author="Author"`))
+	return initialsAttrPattern.ReplaceAll(data, nil)
+}
+
+// acceptRevisions accepts the tracked insertions and deletions in a raw XML part
+func acceptRevisions(data []byte) []byte {
+	data = deletionPattern.ReplaceAll(data, nil)
+	return insertionTagPattern.ReplaceAll(data, nil)
+}
+
+// removeElements removes the elements with the given local names from raw XML
+func removeElements(data []byte, names ...string) []byte {
+	for _, name := range names {
+		name = regexp.QuoteMeta(name)
+		pattern := regexp.MustCompile(fmt.Sprintf(elementPatternFormat, name, name))
+		data = pattern.ReplaceAll(data, nil)
+	}
+	return data
+}
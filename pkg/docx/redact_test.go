@@ -0,0 +1,60 @@
+package docx
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Patient: Jane ")
+	// The name is split across runs, as Word often does
+	doc.Body.Paragraphs[0].Runs = append(doc.Body.Paragraphs[0].Runs, Run{Text: []Text{{Content: "Doe, SSN 123-45-6789"}}})
+	table := doc.AddTable(1, 1)
+	table.SetCellText(0, 0, "Contact Jane Doe")
+	doc.SetPart("word/footer1.xml", []byte(`<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>Prepared for Jane Doe &amp; family</w:t></w:r></w:p></w:ftr>`))
+
+	count := doc.Redact(regexp.MustCompile(`Jane Doe|\d{3}-\d{2}-\d{4}`), RedactBlackout)
+	if count != 4 {
+		t.Errorf("Expected 4 matches, got %d", count)
+	}
+
+	text, _ := doc.GetParagraphText(0)
+	if text != "Patient: ████████, SSN ███████████" {
+		t.Errorf("Unexpected redacted text: %q", text)
+	}
+	if cell, _ := table.GetCellText(0, 0); cell != "Contact ████████" {
+		t.Errorf("Unexpected redacted cell: %q", cell)
+	}
+	footer, _ := doc.GetPart("word/footer1.xml")
+	if !strings.Contains(string(footer), "Prepared for ████████ &amp; family") {
+		t.Errorf("Unexpected redacted footer: %s", footer)
+	}
+
+	doc.Redact(regexp.MustCompile(`Patient: `), RedactRemove)
+	if text, _ := doc.GetParagraphText(0); !strings.HasPrefix(text, "████") {
+		t.Errorf("Expected matched text to be removed, got %q", text)
+	}
+}
+
+func TestRemovePersonalInfo(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Body")
+	doc.SetPart(corePropertiesPart, []byte(`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:creator>Jane Doe</dc:creator><cp:lastModifiedBy>John Roe</cp:lastModifiedBy><cp:revision>7</cp:revision></cp:coreProperties>`))
+	doc.SetPart("word/header1.xml", []byte(`<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p w:rsidR="00A1"><w:ins w:id="1" w:author="Jane Doe"><w:r><w:t>Kept</w:t></w:r></w:ins><w:del w:id="2" w:author="Jane Doe"><w:r><w:delText>Secret</w:delText></w:r></w:del></w:p></w:hdr>`))
+
+	doc.RemovePersonalInfo()
+
+	core, _ := doc.GetPart(corePropertiesPart)
+	for _, gone := range []string{"Jane Doe", "John Roe", "revision"} {
+		if strings.Contains(string(core), gone) {
+			t.Errorf("Expected %q to be removed from core properties: %s", gone, core)
+		}
+	}
+	header, _ := doc.GetPart("word/header1.xml")
+	want := `<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>Kept</w:t></w:r></w:p></w:hdr>`
+	if string(header) != want {
+		t.Errorf("Expected tracked changes to be accepted, got: %s", header)
+	}
+}
@@ -0,0 +1,432 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	relTypeOfficeDocument = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"
+	rootRelsPart          = "_rels/.rels"
+	contentTypeMain       = "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"
+)
+
+// contentTypesByRelType gives the content type of a part from the type of the
+// relationship targeting it
+var contentTypesByRelType = map[string]string{
+	relTypeOfficeDocument: contentTypeMain,
+	relTypeStyles:         contentTypeStyles,
+	relTypeNumbering:      contentTypeNumbering,
+	relTypeHeader:         contentTypeHeader,
+	relTypeChart:          contentTypeChart,
+	relTypeCustomXMLProps: contentTypeCustomXMLProps,
+	"http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer": "application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml",
+	relTypeSettings:  contentTypeSettings,
+	relTypeFontTable: contentTypeFontTable,
+	"http://schemas.openxmlformats.org/officeDocument/2006/relationships/webSettings":         "application/vnd.openxmlformats-officedocument.wordprocessingml.webSettings+xml",
+	"http://schemas.openxmlformats.org/officeDocument/2006/relationships/footnotes":           "application/vnd.openxmlformats-officedocument.wordprocessingml.footnotes+xml",
+	"http://schemas.openxmlformats.org/officeDocument/2006/relationships/endnotes":            "application/vnd.openxmlformats-officedocument.wordprocessingml.endnotes+xml",
+	"http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments":            "application/vnd.openxmlformats-officedocument.wordprocessingml.comments+xml",
+	"http://schemas.openxmlformats.org/officeDocument/2006/relationships/theme":               "application/vnd.openxmlformats-officedocument.theme+xml",
+	"http://schemas.openxmlformats.org/officeDocument/2006/relationships/extended-properties": "application/vnd.openxmlformats-officedocument.extended-properties+xml",
+	"http://schemas.openxmlformats.org/officeDocument/2006/relationships/custom-properties":   "application/vnd.openxmlformats-officedocument.custom-properties+xml",
+	"http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties":   "application/vnd.openxmlformats-package.core-properties+xml",
+}
+
+// contentTypesByExtension gives the content type of parts stored under a
+// Default entry
+var contentTypesByExtension = map[string]string{
+	"rels":  "application/vnd.openxmlformats-package.relationships+xml",
+	"xml":   "application/xml",
+	"png":   "image/png",
+	"jpg":   "image/jpeg",
+	"jpeg":  "image/jpeg",
+	"gif":   "image/gif",
+	"bmp":   "image/bmp",
+	"tif":   "image/tiff",
+	"tiff":  "image/tiff",
+	"svg":   "image/svg+xml",
+	"emf":   "image/x-emf",
+	"wmf":   "image/x-wmf",
+	"webp":  "image/webp",
+	"xlsx":  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"bin":   "application/vnd.openxmlformats-officedocument.oleObject",
+	"odttf": contentTypeObfuscatedFont,
+}
+
+// mainDocumentPattern matches the root element of a WordprocessingML main document
+var mainDocumentPattern = regexp.MustCompile(`<(?:\w+:)?document\b[^>]*"http://schemas\.openxmlformats\.org/wordprocessingml/2006/main"`)
+
+// contentTypesXML is the content of [Content_Types].xml
+type contentTypesXML struct {
+	XMLName   xml.Name              `xml:"http://schemas.openxmlformats.org/package/2006/content-types Types"`
+	Defaults  []contentTypeDefault  `xml:"Default"`
+	Overrides []contentTypeOverride `xml:"Override"`
+}
+
+type contentTypeDefault struct {
+	Extension   string `xml:"Extension,attr"`
+	ContentType string `xml:"ContentType,attr"`
+}
+
+type contentTypeOverride struct {
+	PartName    string `xml:"PartName,attr"`
+	ContentType string `xml:"ContentType,attr"`
+}
+
+// Repair reads a damaged or nonstandard .docx file that Open or Word may
+// refuse, fixes its package structure and returns the document along with a
+// description of each fix. Saving the document writes the repaired package.
+func Repair(filePath string) (*Document, []string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open docx file: %w", err)
+	}
+	doc, fixes, err := RepairBytes(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc.FilePath = filePath
+	return doc, fixes, nil
+}
+
+// RepairBytes is Repair for a package held in memory. It fixes:
+//   - unreadable entries, which are dropped
+//   - part names with backslashes or a leading slash
+//   - XML parts starting with a byte order mark or whitespace
+//   - a main document stored under another name, or missing package relationships
+//   - relationships to parts that don't exist, and the header and footer
+//     references using them
+//   - parts no relationship leads to
+//   - missing, stale or unreadable content type entries
+func RepairBytes(data []byte) (*Document, []string, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open docx data: %w", err)
+	}
+
+	doc := &Document{files: make(map[string][]byte)}
+	var fixes []string
+	fixf := func(format string, args ...interface{}) {
+		fixes = append(fixes, fmt.Sprintf(format, args...))
+	}
+
+	for _, f := range r.File {
+		if strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		content, err := readZipFile(f)
+		if err != nil {
+			fixf("dropped unreadable part %s: %v", f.Name, err)
+			continue
+		}
+
+		name := strings.TrimLeft(strings.ReplaceAll(f.Name, `\`, "/"), "/")
+		if name != f.Name {
+			fixf("renamed part %s to %s", f.Name, name)
+		}
+		if _, exists := doc.files[name]; exists {
+			fixf("dropped duplicate part %s", f.Name)
+			continue
+		}
+
+		if ext := path.Ext(name); ext == ".xml" || ext == ".rels" {
+			trimmed := bytes.TrimLeft(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")), " \t\r\n")
+			if len(trimmed) != len(content) {
+				fixf("removed byte order mark or leading whitespace from %s", name)
+				content = trimmed
+			}
+		}
+		doc.files[name] = content
+	}
+
+	if err := doc.repairMainDocument(fixf); err != nil {
+		return nil, nil, err
+	}
+	removed := doc.repairRelationships(fixf)
+	doc.removeStrayParts(fixf)
+	doc.repairContentTypes(fixf)
+
+	if err := doc.parseDocument(doc.files["word/document.xml"]); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse document.xml: %w", err)
+	}
+	for _, sectPr := range doc.sections() {
+		for _, relID := range removed {
+			pattern := regexp.MustCompile(`<(?:\w+:)?(?:header|footer)Reference\b[^>]*\b\w+:id="` + regexp.QuoteMeta(relID) + `"[^>]*/>`)
+			if pattern.Match(sectPr.Inner) {
+				sectPr.Inner = pattern.ReplaceAll(sectPr.Inner, nil)
+				fixf("removed section reference to missing relationship %s", relID)
+			}
+		}
+	}
+	if len(doc.Body.SectPr.Inner) == 0 && len(doc.Body.SectPr.Attrs) == 0 {
+		doc.Body.SectPr = nil
+	}
+
+	doc.initializeImageID()
+	doc.initializeRelationshipID()
+	return doc, fixes, nil
+}
+
+// repairMainDocument makes sure the main document is word/document.xml and
+// that the package relationships lead to it
+func (d *Document) repairMainDocument(fixf func(string, ...interface{})) error {
+	if _, ok := d.files["word/document.xml"]; !ok {
+		// Look for the main document under another name, in the word folder
+		var candidates []string
+		for name, content := range d.files {
+			if path.Dir(name) == "word" && path.Ext(name) == ".xml" && mainDocumentPattern.Match(content) {
+				candidates = append(candidates, name)
+			}
+		}
+		if len(candidates) == 0 {
+			return fmt.Errorf("document.xml not found in docx file")
+		}
+		sort.Strings(candidates)
+		name := candidates[0]
+		d.files["word/document.xml"] = d.files[name]
+		delete(d.files, name)
+		relsName := "word/_rels/" + path.Base(name) + ".rels"
+		if rels, ok := d.files[relsName]; ok {
+			d.files[documentRelsPart] = rels
+			delete(d.files, relsName)
+		}
+		fixf("renamed main document %s to word/document.xml", name)
+	}
+
+	rels, ok := d.files[rootRelsPart]
+	if !ok {
+		rels = []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+</Relationships>`)
+		fixf("created missing package relationships")
+	}
+	withoutMain := relationshipPattern.ReplaceAllFunc(rels, func(rel []byte) []byte {
+		if bytes.Contains(rel, []byte(`Type="`+relTypeOfficeDocument+`"`)) {
+			return nil
+		}
+		return rel
+	})
+	main := fmt.Sprintf(`	<Relationship Id="rIdMain" Type="%s" Target="word/document.xml"/>`, relTypeOfficeDocument)
+	if !bytes.Contains(rels, []byte(`Target="word/document.xml"`)) && !bytes.Contains(rels, []byte(`Target="/word/document.xml"`)) {
+		rels = bytes.Replace(withoutMain, []byte("</Relationships>"), []byte(main+"\n</Relationships>"), 1)
+		fixf("pointed the package relationships at word/document.xml")
+	}
+	d.files[rootRelsPart] = rels
+
+	if _, ok := d.files[documentRelsPart]; !ok {
+		d.files[documentRelsPart] = getDefaultDocxFiles()[documentRelsPart]
+		fixf("created missing %s", documentRelsPart)
+	}
+	return nil
+}
+
+// repairRelationships removes relationships to parts missing from the
+// package and returns the IDs removed from the main document's
+func (d *Document) repairRelationships(fixf func(string, ...interface{})) []string {
+	var removed []string
+	for _, relsName := range d.PartNames() {
+		if !strings.HasSuffix(relsName, ".rels") {
+			continue
+		}
+		sourceDir := path.Dir(path.Dir(relsName))
+		d.files[relsName] = relationshipPattern.ReplaceAllFunc(d.files[relsName], func(rel []byte) []byte {
+			if bytes.Contains(rel, []byte(`TargetMode="External"`)) {
+				return rel
+			}
+			m := relationshipTargetPattern.FindSubmatch(rel)
+			if m == nil {
+				return rel
+			}
+			target := relationshipTargetPart(sourceDir, string(m[1]))
+			if _, ok := d.findPart(target); ok {
+				return rel
+			}
+			fixf("removed relationship in %s to missing part %s", relsName, target)
+			if relsName == documentRelsPart {
+				if id := relationshipIDPattern.FindSubmatch(rel); id != nil {
+					removed = append(removed, string(id[1]))
+				}
+			}
+			return nil
+		})
+	}
+	return removed
+}
+
+var (
+	relationshipIDPattern   = regexp.MustCompile(`\bId="([^"]*)"`)
+	relationshipTypePattern = regexp.MustCompile(`\bType="([^"]*)"`)
+)
+
+// relationshipTargetPart converts the target of a relationship from a part in
+// sourceDir into a part name
+func relationshipTargetPart(sourceDir, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return strings.TrimPrefix(path.Join(sourceDir, target), "./")
+}
+
+// findPart returns the name of the part a relationship target names, which
+// may be percent-encoded or differ in case
+func (d *Document) findPart(name string) (string, bool) {
+	if _, ok := d.files[name]; ok {
+		return name, true
+	}
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		if _, ok := d.files[unescaped]; ok {
+			return unescaped, true
+		}
+		name = unescaped
+	}
+	for partName := range d.files {
+		if strings.EqualFold(partName, name) {
+			return partName, true
+		}
+	}
+	return "", false
+}
+
+// removeStrayParts removes the parts no relationship leads to, starting from
+// the package relationships
+func (d *Document) removeStrayParts(fixf func(string, ...interface{})) {
+	reachable := map[string]bool{contentTypesPart: true, rootRelsPart: true}
+	queue := []string{rootRelsPart}
+	for len(queue) > 0 {
+		relsName := queue[0]
+		queue = queue[1:]
+		sourceDir := path.Dir(path.Dir(relsName))
+		for _, rel := range relationshipPattern.FindAll(d.files[relsName], -1) {
+			m := relationshipTargetPattern.FindSubmatch(rel)
+			if m == nil || bytes.Contains(rel, []byte(`TargetMode="External"`)) {
+				continue
+			}
+			target, ok := d.findPart(relationshipTargetPart(sourceDir, string(m[1])))
+			if !ok || reachable[target] {
+				continue
+			}
+			reachable[target] = true
+			ownRels := path.Join(path.Dir(target), "_rels", path.Base(target)+".rels")
+			if _, ok := d.files[ownRels]; ok {
+				reachable[ownRels] = true
+				queue = append(queue, ownRels)
+			}
+		}
+	}
+
+	for _, name := range d.PartNames() {
+		if !reachable[name] {
+			delete(d.files, name)
+			fixf("removed stray part %s", name)
+		}
+	}
+}
+
+// repairContentTypes rewrites [Content_Types].xml so every part has exactly
+// one content type and no entry names a missing part
+func (d *Document) repairContentTypes(fixf func(string, ...interface{})) {
+	var types contentTypesXML
+	if data, ok := d.files[contentTypesPart]; !ok {
+		fixf("created missing %s", contentTypesPart)
+	} else if err := xml.Unmarshal(data, &types); err != nil {
+		fixf("rebuilt unreadable %s: %v", contentTypesPart, err)
+		types = contentTypesXML{}
+	}
+
+	defaults := make(map[string]string)
+	for _, def := range types.Defaults {
+		defaults[strings.ToLower(def.Extension)] = def.ContentType
+	}
+	overrides := make(map[string]string)
+	for _, o := range types.Overrides {
+		name := strings.TrimPrefix(o.PartName, "/")
+		if _, ok := d.files[name]; !ok {
+			fixf("removed content type of missing part %s", name)
+			continue
+		}
+		overrides[name] = o.ContentType
+	}
+
+	// Content types implied by the relationships leading to each part
+	byRel := make(map[string]string)
+	for relsName, data := range d.files {
+		if !strings.HasSuffix(relsName, ".rels") {
+			continue
+		}
+		sourceDir := path.Dir(path.Dir(relsName))
+		for _, rel := range relationshipPattern.FindAll(data, -1) {
+			target := relationshipTargetPattern.FindSubmatch(rel)
+			typ := relationshipTypePattern.FindSubmatch(rel)
+			if target == nil || typ == nil {
+				continue
+			}
+			name, found := d.findPart(relationshipTargetPart(sourceDir, string(target[1])))
+			if ct, ok := contentTypesByRelType[string(typ[1])]; ok && found {
+				byRel[name] = ct
+			}
+		}
+	}
+
+	for _, ext := range []string{"rels", "xml"} {
+		if _, ok := defaults[ext]; !ok {
+			defaults[ext] = contentTypesByExtension[ext]
+			fixf("added content type for .%s parts", ext)
+		}
+	}
+	for _, name := range d.PartNames() {
+		if name == contentTypesPart {
+			continue
+		}
+		ct, typed := overrides[name]
+		if !typed {
+			ct, typed = defaults[strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))]
+		}
+		want, known := byRel[name]
+
+		switch {
+		case known && ct != want && !(name == "word/document.xml" && strings.HasSuffix(ct, ".main+xml")):
+			overrides[name] = want
+			fixf("set content type of %s to %s", name, want)
+		case !typed:
+			ext := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+			if byExt, ok := contentTypesByExtension[ext]; ok {
+				defaults[ext] = byExt
+				fixf("added content type for .%s parts", ext)
+			} else {
+				overrides[name] = "application/octet-stream"
+				fixf("set content type of %s to application/octet-stream", name)
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` + "\n")
+	for _, ext := range sortedKeys(defaults) {
+		fmt.Fprintf(&sb, "\t<Default Extension=\"%s\" ContentType=\"%s\"/>\n", xmlEscapedString(ext), xmlEscapedString(defaults[ext]))
+	}
+	for _, name := range sortedKeys(overrides) {
+		fmt.Fprintf(&sb, "\t<Override PartName=\"/%s\" ContentType=\"%s\"/>\n", xmlEscapedString(name), xmlEscapedString(overrides[name]))
+	}
+	sb.WriteString("</Types>")
+	d.files[contentTypesPart] = []byte(sb.String())
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
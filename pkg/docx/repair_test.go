@@ -0,0 +1,130 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// damagedPackage builds a package Word refuses to open: the main document
+// starts with a byte order mark, a part name uses backslashes, the image has
+// no content type, a header relationship leads nowhere and a stray part has
+// no content type either
+func damagedPackage(t *testing.T) []byte {
+	t.Helper()
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+<Override PartName="/word/header1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"/>
+</Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`},
+		{"word/document.xml", "\xef\xbb\xbf" + `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<w:body><w:p><w:r><w:t>Rescued</w:t></w:r></w:p>
+<w:sectPr><w:headerReference w:type="default" r:id="rId2"/><w:pgSz w:w="12240" w:h="15840"/></w:sectPr></w:body></w:document>`},
+		{`word\_rels\document.xml.rels`, `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image%201.png"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://example.com" TargetMode="External"/>
+</Relationships>`},
+		{"word/media/image 1.png", "\x89PNG"},
+		{"word/stray.bin", "leftover"},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, p := range parts {
+		w, err := zw.Create(p.name)
+		if err != nil {
+			t.Fatalf("Create %s failed: %v", p.name, err)
+		}
+		w.Write([]byte(p.content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestRepairBytes(t *testing.T) {
+	doc, fixes, err := RepairBytes(damagedPackage(t))
+	if err != nil {
+		t.Fatalf("RepairBytes failed: %v", err)
+	}
+	report := strings.Join(fixes, "\n")
+	for _, want := range []string{
+		`renamed part word\_rels\document.xml.rels to word/_rels/document.xml.rels`,
+		"removed byte order mark or leading whitespace from word/document.xml",
+		"removed relationship in word/_rels/document.xml.rels to missing part word/header1.xml",
+		"removed stray part word/stray.bin",
+		"removed content type of missing part word/header1.xml",
+		"added content type for .png parts",
+		"added content type for .rels parts",
+		"removed section reference to missing relationship rId2",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected fix %q, got:\n%s", want, report)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	repaired, err := ReadBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Repaired package failed to open: %v", err)
+	}
+	if text := repaired.GetText(); !strings.Contains(text, "Rescued") {
+		t.Errorf("Expected the text to survive, got %q", text)
+	}
+	if _, ok := repaired.GetPart("word/media/image 1.png"); !ok {
+		t.Errorf("Expected the percent-encoded image target to be kept")
+	}
+	if rel, ok := repaired.findRelationship("rId3"); !ok || rel.TargetMode != "External" {
+		t.Errorf("Expected the external hyperlink to be kept")
+	}
+	if strings.Contains(string(repaired.Body.SectPr.Inner), "headerReference") {
+		t.Errorf("Expected the dangling header reference to be removed")
+	}
+
+	// A repaired package needs no further fixes
+	if _, fixes, err := RepairBytes(buf.Bytes()); err != nil || len(fixes) != 0 {
+		t.Errorf("Expected no fixes for a repaired package, got %v, %v", fixes, err)
+	}
+}
+
+func TestRepairBytesRenamedMainDocument(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("word/document2.xml")
+	w.Write([]byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:r><w:t>Main</w:t></w:r></w:p></w:body></w:document>`))
+	zw.Close()
+
+	doc, fixes, err := RepairBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("RepairBytes failed: %v", err)
+	}
+	if doc.GetText() != "Main" {
+		t.Errorf("Expected the renamed main document, got %q", doc.GetText())
+	}
+	report := strings.Join(fixes, "\n")
+	if !strings.Contains(report, "renamed main document word/document2.xml") || !strings.Contains(report, "created missing package relationships") {
+		t.Errorf("Unexpected fixes:\n%s", report)
+	}
+	types, _ := doc.GetPart(contentTypesPart)
+	if !strings.Contains(string(types), `<Override PartName="/word/document.xml" ContentType="`+contentTypeMain+`"/>`) {
+		t.Errorf("Expected the main document content type, got %s", types)
+	}
+
+	if _, _, err := RepairBytes([]byte("not a zip")); err == nil {
+		t.Errorf("Expected an error for data that isn't a zip archive")
+	}
+}
@@ -0,0 +1,197 @@
+package docx
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SanitizeOptions selects what Sanitize removes
+type SanitizeOptions struct {
+	Properties     bool // Core, extended and custom document properties
+	Comments       bool // Comments and the people who wrote them
+	TrackedChanges bool // Tracked insertions are accepted and deletions dropped
+	HiddenText     bool // Runs formatted as hidden
+	PersonalInfo   bool // Author names, revision IDs and the template path
+	CustomXML      bool // Custom XML data parts and the bindings to them
+}
+
+// DefaultSanitizeOptions returns options removing everything Sanitize can
+func DefaultSanitizeOptions() SanitizeOptions {
+	return SanitizeOptions{
+		Properties:     true,
+		Comments:       true,
+		TrackedChanges: true,
+		HiddenText:     true,
+		PersonalInfo:   true,
+		CustomXML:      true,
+	}
+}
+
+// SanitizeReport describes what Sanitize removed
+type SanitizeReport struct {
+	RemovedParts []string // Package parts deleted
+	HiddenRuns   int      // Hidden runs deleted
+}
+
+// Sanitize removes metadata, comments, tracked changes, hidden text and other
+// content that is easy to publish by accident, so the document can be shared
+// safely
+func (d *Document) Sanitize(opts SanitizeOptions) *SanitizeReport {
+	report := &SanitizeReport{}
+	removeParts := func(match func(name string) bool) {
+		for _, name := range d.PartNames() {
+			if _, exists := d.files[name]; exists && match(name) {
+				d.removePart(name)
+				report.RemovedParts = append(report.RemovedParts, name)
+			}
+		}
+	}
+
+	if opts.PersonalInfo {
+		d.RemovePersonalInfo()
+	}
+
+	if opts.Properties {
+		removeParts(func(name string) bool {
+			return name == corePropertiesPart || name == appPropertiesPart || name == customPropertiesPart
+		})
+	}
+
+	if opts.Comments {
+		removeParts(func(name string) bool {
+			return commentPartPattern.MatchString(name)
+		})
+		d.transformStoryParts(func(data []byte) []byte {
+			return commentMarkupPattern.ReplaceAll(data, nil)
+		})
+	}
+
+	if opts.TrackedChanges {
+		d.transformStoryParts(acceptRevisions)
+	}
+
+	if opts.HiddenText {
+		report.HiddenRuns += d.RemoveHiddenText()
+	}
+
+	if opts.CustomXML {
+		removeParts(func(name string) bool {
+			return strings.HasPrefix(name, "customXml/")
+		})
+		d.transformStoryParts(func(data []byte) []byte {
+			return dataBindingPattern.ReplaceAll(data, nil)
+		})
+		w := newWalker(DefaultMaxDepth)
+		w.sdt = func(s *SDT, depth int) error {
+			if s.Props != nil {
+				s.Props.Inner = dataBindingPattern.ReplaceAll(s.Props.Inner, nil)
+			}
+			return nil
+		}
+		_ = w.walkBody(d.Body)
+	}
+
+	return report
+}
+
+const customPropertiesPart = "docProps/custom.xml"
+
+var (
+	commentPartPattern   = regexp.MustCompile(`^word/(comments\w*|people)\.xml$`)
+	commentMarkupPattern = regexp.MustCompile(`<w:comment(?:RangeStart|RangeEnd|Reference)\b[^>]*/>`)
+	dataBindingPattern   = regexp.MustCompile(`<w:dataBinding\b[^>]*/>`)
+)
+
+// transformStoryParts applies fn to each part holding text outside the body
+func (d *Document) transformStoryParts(fn func([]byte) []byte) {
+	for name, data := range d.files {
+		if storyPartPattern.MatchString(name) {
+			d.files[name] = fn(data)
+		}
+	}
+}
+
+// RemoveHiddenText deletes the runs formatted as hidden from the body and the
+// other parts holding text, and returns how many were deleted
+func (d *Document) RemoveHiddenText() int {
+	count := 0
+	visible := func(r *Run) bool {
+		if r.Props != nil && r.Props.Vanish.hidden() {
+			count++
+			return false
+		}
+		return true
+	}
+
+	w := newWalker(DefaultMaxDepth)
+	w.includeFallback = true
+	w.paragraph = func(p *Paragraph, depth int) error {
+		for _, runs := range p.runSlices() {
+			p.filterRuns(runs, visible)
+		}
+		return nil
+	}
+	_ = w.walkBody(d.Body)
+
+	d.transformStoryParts(func(data []byte) []byte {
+		var n int
+		data, n = removeHiddenRunsXML(data)
+		count += n
+		return data
+	})
+	return count
+}
+
+// hidden reports whether the vanish property hides text
+func (v *Vanish) hidden() bool {
+	return v != nil && v.Val != "false" && v.Val != "0" && v.Val != "off"
+}
+
+// removeHiddenRunsXML deletes the runs whose properties hide them from raw
+// XML. It walks the elements rather than the bytes, so a hidden run holding
+// a text box or drawing goes as a whole, along with the runs inside it.
+// Malformed XML is left as it is.
+func removeHiddenRunsXML(data []byte) ([]byte, int) {
+	root, err := parseXMLTree(data)
+	if err != nil {
+		return data, 0
+	}
+
+	var edits []xmlEdit
+	var visit func(n *xmlTreeNode)
+	visit = func(n *xmlTreeNode) {
+		if n.name == "w:r" && hiddenRunXML(n) {
+			edits = append(edits, xmlEdit{n.start, n.end, ""})
+			return
+		}
+		for _, c := range n.children {
+			visit(c)
+		}
+	}
+	visit(root)
+	if len(edits) == 0 {
+		return data, 0
+	}
+	return applyXMLEdits(data, edits)
+}
+
+// hiddenRunXML reports whether a run's own properties, which come first,
+// hide it
+func hiddenRunXML(run *xmlTreeNode) bool {
+	if len(run.children) == 0 || run.children[0].name != "w:rPr" {
+		return false
+	}
+	for _, prop := range run.children[0].children {
+		if prop.name != "w:vanish" {
+			continue
+		}
+		v := &Vanish{}
+		for _, a := range prop.attrs {
+			if a.name == "w:val" {
+				v.Val = a.value
+			}
+		}
+		return v.hidden()
+	}
+	return false
+}
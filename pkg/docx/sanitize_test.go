@@ -0,0 +1,141 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Visible")
+	doc.Body.Paragraphs[0].Runs = append(doc.Body.Paragraphs[0].Runs,
+		Run{Props: &RProps{Vanish: &Vanish{}}, Text: []Text{{Content: " internal note"}}},
+		Run{Props: &RProps{Vanish: &Vanish{Val: "false"}}, Text: []Text{{Content: " shown"}}})
+
+	doc.SetPart(corePropertiesPart, []byte(`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties"/>`))
+	doc.SetPart("word/comments.xml", []byte(`<w:comments xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"/>`))
+	doc.SetPart("customXml/item1.xml", []byte(`<data>secret</data>`))
+	doc.addRelationship("http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments", "comments.xml")
+	doc.addRelationship("http://schemas.openxmlformats.org/officeDocument/2006/relationships/customXml", "../customXml/item1.xml")
+	doc.registerContentTypeOverride("word/comments.xml", "application/vnd.openxmlformats-officedocument.wordprocessingml.comments+xml")
+	doc.SetPart("word/footer1.xml", []byte(`<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p>`+
+		`<w:commentRangeStart w:id="0"/><w:r><w:t>Footer</w:t></w:r><w:commentRangeEnd w:id="0"/>`+
+		`<w:r><w:rPr><w:vanish/></w:rPr><w:t>hidden</w:t></w:r>`+
+		`<w:ins w:id="1" w:author="Jane"><w:r><w:t> added</w:t></w:r></w:ins></w:p></w:ftr>`))
+
+	report := doc.Sanitize(DefaultSanitizeOptions())
+
+	if report.HiddenRuns != 2 {
+		t.Errorf("Expected 2 hidden runs removed, got %d", report.HiddenRuns)
+	}
+	if text, _ := doc.GetParagraphText(0); text != "Visible shown" {
+		t.Errorf("Expected hidden text to be removed, got %q", text)
+	}
+	for _, name := range []string{corePropertiesPart, "word/comments.xml", "customXml/item1.xml"} {
+		if _, exists := doc.GetPart(name); exists {
+			t.Errorf("Expected %s to be removed", name)
+		}
+	}
+	if len(report.RemovedParts) != 3 {
+		t.Errorf("Expected 3 removed parts, got %v", report.RemovedParts)
+	}
+
+	rels, _ := doc.GetPart(documentRelsPart)
+	contentTypes, _ := doc.GetPart(contentTypesPart)
+	if strings.Contains(string(rels), "comments.xml") || strings.Contains(string(rels), "customXml") {
+		t.Errorf("Expected relationships to removed parts to be dropped: %s", rels)
+	}
+	if strings.Contains(string(contentTypes), "comments.xml") {
+		t.Errorf("Expected content type override to be dropped: %s", contentTypes)
+	}
+
+	footer, _ := doc.GetPart("word/footer1.xml")
+	want := `<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>Footer</w:t></w:r><w:r><w:t> added</w:t></w:r></w:p></w:ftr>`
+	if string(footer) != want {
+		t.Errorf("Unexpected sanitized footer: %s", footer)
+	}
+
+	// The sanitized package still opens
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	if _, err := ReadBytes(data); err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+}
+
+func TestSanitizeKeepsUnselected(t *testing.T) {
+	doc := New()
+	doc.SetPart(corePropertiesPart, []byte(`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties"/>`))
+
+	report := doc.Sanitize(SanitizeOptions{Comments: true})
+	if _, exists := doc.GetPart(corePropertiesPart); !exists || len(report.RemovedParts) != 0 {
+		t.Errorf("Expected properties to be kept, removed %v", report.RemovedParts)
+	}
+}
+
+func TestHiddenText(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Public")
+	doc.AddParagraph("Reviewer notes", WithHidden())
+	doc.Body.Paragraphs[0].Runs = append(doc.Body.Paragraphs[0].Runs,
+		Run{Props: &RProps{Vanish: &Vanish{}}, Text: []Text{{Content: " draft"}}})
+	doc.SetPart("word/header1.xml", []byte(`<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p>`+
+		`<w:r><w:t>Header</w:t></w:r><w:r><w:rPr><w:vanish/></w:rPr><w:t> secret</w:t></w:r></w:p></w:hdr>`))
+
+	scope := FullSearchScope()
+	if got := doc.GetTextIn(scope); got != "Public draft Reviewer notes Header secret" {
+		t.Errorf("Expected hidden text by default, got %q", got)
+	}
+	scope.SkipHidden = true
+	if got := doc.GetTextIn(scope); got != "Public Header" {
+		t.Errorf("Expected hidden text to be left out, got %q", got)
+	}
+	if matches := doc.FindTextIn("notes", scope); len(matches) != 0 {
+		t.Errorf("Expected no match in hidden text, got %v", matches)
+	}
+	if matches := doc.FindTextIn("public", scope); len(matches) != 1 || matches[0].Paragraph != 0 {
+		t.Errorf("Expected the first paragraph, got %v", matches)
+	}
+	if text, _ := doc.GetParagraphText(0); text != "Public draft" {
+		t.Errorf("Expected extraction to leave the document unchanged, got %q", text)
+	}
+
+	if n := doc.RemoveHiddenText(); n != 3 {
+		t.Errorf("Expected 3 hidden runs removed, got %d", n)
+	}
+	if got := doc.GetTextIn(FullSearchScope()); got != "Public Header" {
+		t.Errorf("Expected hidden text to be removed, got %q", got)
+	}
+}
+
+func TestHiddenTextBoxInHeader(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Body")
+	header := `<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:wps="http://schemas.microsoft.com/office/word/2010/wordprocessingShape"><w:p>` +
+		`<w:r><w:rPr><w:vanish/></w:rPr><w:drawing><wps:wsp><wps:txbx><w:txbxContent><w:p>` +
+		`<w:r><w:t>Boxed</w:t></w:r></w:p></w:txbxContent></wps:txbx></wps:wsp></w:drawing><w:t>Label</w:t></w:r>` +
+		`<w:r><w:rPr><w:b/><w:vanish w:val="false"/></w:rPr><w:t>Shown</w:t></w:r>` +
+		`<w:r><w:drawing><wps:wsp><wps:txbx><w:txbxContent><w:p><w:r><w:rPr><w:vanish/></w:rPr><w:t>Inner</w:t></w:r>` +
+		`<w:r><w:t>Kept</w:t></w:r></w:p></w:txbxContent></wps:txbx></wps:wsp></w:drawing></w:r></w:p></w:hdr>`
+	doc.SetPart("word/header1.xml", []byte(header))
+
+	if n := doc.RemoveHiddenText(); n != 2 {
+		t.Errorf("Expected 2 hidden runs removed, got %d", n)
+	}
+	data, _ := doc.GetPart("word/header1.xml")
+	if _, err := parseXMLTree(data); err != nil {
+		t.Fatalf("Expected the header to stay well-formed, got %v in %s", err, data)
+	}
+	for _, gone := range []string{"Boxed", "Label", "Inner"} {
+		if strings.Contains(string(data), gone) {
+			t.Errorf("Expected %q to be removed from %s", gone, data)
+		}
+	}
+	for _, kept := range []string{"Shown", "Kept", "<w:drawing>"} {
+		if !strings.Contains(string(data), kept) {
+			t.Errorf("Expected %q to be kept in %s", kept, data)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package docx
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SearchScope selects the parts of a document GetTextIn, FindTextIn and
+// ReplaceTextIn read and change. In headers, footers, footnotes and comments
+// text is replaced one run at a time, as Redact does, leaving tracked
+// deletions as they are.
+type SearchScope struct {
+	Body      bool // Body paragraphs
+	Tables    bool // Cells of body tables, nested tables included
+	TextBoxes bool // Text boxes anchored in body paragraphs, and in table cells with Tables
+	Headers   bool
+	Footers   bool
+	Footnotes bool // Footnotes and endnotes
+	Comments  bool
+
+	// SkipHidden leaves out text formatted as hidden. GetTextIn and
+	// FindTextIn honor it; ReplaceTextIn replaces hidden text either way.
+	SkipHidden bool
+}
+
+// DefaultSearchScope returns the scope of GetText, FindText and ReplaceText:
+// the body paragraphs and their text boxes, leaving out tables
+func DefaultSearchScope() SearchScope {
+	return SearchScope{Body: true, TextBoxes: true}
+}
+
+// FullSearchScope returns a scope covering every part of a document holding text
+func FullSearchScope() SearchScope {
+	return SearchScope{Body: true, Tables: true, TextBoxes: true, Headers: true, Footers: true, Footnotes: true, Comments: true}
+}
+
+// TextMatch is a paragraph holding the text FindTextIn looked for
+type TextMatch struct {
+	Part string // Name of the part, e.g. word/document.xml or word/header1.xml
+
+	// Paragraph is the index of the paragraph in the body, in its table
+	// cell, or in the part for parts other than the body
+	Paragraph int
+
+	// Table, Row and Column locate the cell of a body table holding the
+	// paragraph; -1 for paragraphs outside tables. Paragraphs of nested
+	// tables count as paragraphs of the cell they are in.
+	Table  int
+	Row    int
+	Column int
+
+	Text string // Text of the paragraph
+}
+
+var (
+	partParagraphEndPattern = regexp.MustCompile(`</w:p>`)
+	partTextPattern         = regexp.MustCompile(`(<w:t\b[^>/]*>)([^<]*)(</w:t>)`)
+)
+
+// storyParts returns the names of the parts outside the body in a scope, in
+// the order headers, footers, footnotes, endnotes and comments
+func (d *Document) storyParts(scope SearchScope) []string {
+	var names []string
+	for name := range d.files {
+		if !storyPartPattern.MatchString(name) {
+			continue
+		}
+		base := strings.TrimPrefix(name, "word/")
+		switch {
+		case strings.HasPrefix(base, "header") && scope.Headers,
+			strings.HasPrefix(base, "footer") && scope.Footers,
+			(strings.HasPrefix(base, "footnotes") || strings.HasPrefix(base, "endnotes")) && scope.Footnotes,
+			strings.HasPrefix(base, "comments") && scope.Comments:
+			names = append(names, name)
+		}
+	}
+
+	order := []string{"header", "footer", "footnotes", "endnotes", "comments"}
+	rank := func(name string) int {
+		for i, prefix := range order {
+			if strings.HasPrefix(name, "word/"+prefix) {
+				return i
+			}
+		}
+		return len(order)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if ri, rj := rank(names[i]), rank(names[j]); ri != rj {
+			return ri < rj
+		}
+		return naturalLess(names[i], names[j])
+	})
+	return names
+}
+
+// naturalLess orders part names with numbers by value, so header2.xml comes
+// before header10.xml
+func naturalLess(a, b string) bool {
+	trim := func(s string) string { return strings.TrimRight(strings.TrimSuffix(s, ".xml"), "0123456789") }
+	if ta, tb := trim(a), trim(b); ta == tb && len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// partParagraphs returns the text of each paragraph of a raw XML part,
+// including paragraphs without text
+func partParagraphs(data []byte) []string {
+	chunks := partParagraphEndPattern.Split(string(data), -1)
+	paragraphs := make([]string, 0, len(chunks))
+	for _, chunk := range chunks[:len(chunks)-1] {
+		var sb strings.Builder
+		for _, m := range partTextPattern.FindAllStringSubmatch(chunk, -1) {
+			sb.WriteString(html.UnescapeString(m[2]))
+		}
+		paragraphs = append(paragraphs, sb.String())
+	}
+	return paragraphs
+}
+
+// bodyParagraphText returns the text of a body paragraph in a scope
+func (d *Document) bodyParagraphText(p *Paragraph, scope SearchScope) string {
+	var texts []string
+	if scope.Body {
+		if text := d.paragraphOwnText(p); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	if scope.TextBoxes {
+		texts = append(texts, d.paragraphTextBoxText(p)...)
+	}
+	return strings.Join(texts, " ")
+}
+
+// cellParagraphs calls fn for each paragraph in the cells of a table, those
+// of nested tables and content controls included, with the row and column
+// of its cell and its index among the paragraphs of the cell
+func cellParagraphs(t *Table, fn func(row, col, index int, p *Paragraph)) {
+	for r := range t.Rows {
+		for c := range t.Rows[r].Cells {
+			index := 0
+			var visit func(paragraphs []Paragraph, tables []Table, sdts []SDT)
+			visit = func(paragraphs []Paragraph, tables []Table, sdts []SDT) {
+				for i := range paragraphs {
+					fn(r, c, index, &paragraphs[i])
+					index++
+				}
+				for i := range tables {
+					for _, row := range tables[i].Rows {
+						for _, cell := range row.Cells {
+							visit(cell.Content, cell.Tables, cell.SDTs)
+						}
+					}
+				}
+				for _, sdt := range sdts {
+					if sdt.Content != nil {
+						visit(sdt.Content.Paragraphs, sdt.Content.Tables, sdt.Content.SDTs)
+					}
+				}
+			}
+			cell := &t.Rows[r].Cells[c]
+			visit(cell.Content, cell.Tables, cell.SDTs)
+		}
+	}
+}
+
+// forEachBlock calls paragraph for each body paragraph and, with the Tables
+// scope, table for each body table where it stands among them
+func (d *Document) forEachBlock(scope SearchScope, paragraph func(i int, p *Paragraph), table func(i int, t *Table)) {
+	var tables []int
+	if scope.Tables {
+		for i := range d.Body.Tables {
+			tables = append(tables, i)
+		}
+		sort.SliceStable(tables, func(i, j int) bool {
+			return d.Body.Tables[tables[i]].Position < d.Body.Tables[tables[j]].Position
+		})
+	}
+
+	for i := range d.Body.Paragraphs {
+		for len(tables) > 0 && d.Body.Tables[tables[0]].Position <= i {
+			table(tables[0], &d.Body.Tables[tables[0]])
+			tables = tables[1:]
+		}
+		paragraph(i, &d.Body.Paragraphs[i])
+	}
+	for _, i := range tables {
+		table(i, &d.Body.Tables[i])
+	}
+}
+
+// forEachParagraph calls fn for each paragraph of the body and its tables,
+// in the order they appear
+func (d *Document) forEachParagraph(fn func(p *Paragraph)) {
+	d.forEachBlock(SearchScope{Body: true, Tables: true}, func(i int, p *Paragraph) {
+		fn(p)
+	}, func(i int, t *Table) {
+		cellParagraphs(t, func(row, col, index int, p *Paragraph) {
+			fn(p)
+		})
+	})
+}
+
+// GetTextIn returns the text of the parts of a document in a scope: the
+// body first, with tables where they stand, then headers, footers,
+// footnotes, endnotes and comments, with paragraphs separated by spaces as
+// GetText does
+func (d *Document) GetTextIn(scope SearchScope) string {
+	if scope.SkipHidden {
+		scope.SkipHidden = false
+		return d.withoutHiddenText().GetTextIn(scope)
+	}
+	var texts []string
+	cellScope := SearchScope{Body: true, TextBoxes: scope.TextBoxes}
+	d.forEachBlock(scope, func(i int, p *Paragraph) {
+		if text := d.bodyParagraphText(p, scope); text != "" {
+			texts = append(texts, text)
+		}
+	}, func(i int, t *Table) {
+		cellParagraphs(t, func(row, col, index int, p *Paragraph) {
+			if text := d.bodyParagraphText(p, cellScope); text != "" {
+				texts = append(texts, text)
+			}
+		})
+	})
+	for _, name := range d.storyParts(scope) {
+		for _, text := range partParagraphs(d.files[name]) {
+			if text != "" {
+				texts = append(texts, text)
+			}
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+// FindTextIn returns the paragraphs holding searchText in the parts of a
+// document in a scope, ignoring case like FindText
+func (d *Document) FindTextIn(searchText string, scope SearchScope) []TextMatch {
+	if scope.SkipHidden {
+		scope.SkipHidden = false
+		return d.withoutHiddenText().FindTextIn(searchText, scope)
+	}
+	var matches []TextMatch
+	searchLower := strings.ToLower(searchText)
+	contains := func(text string) bool { return strings.Contains(strings.ToLower(text), searchLower) }
+
+	cellScope := SearchScope{Body: true, TextBoxes: scope.TextBoxes}
+	d.forEachBlock(scope, func(i int, p *Paragraph) {
+		if text := d.bodyParagraphText(p, scope); contains(text) {
+			matches = append(matches, TextMatch{Part: documentPart, Paragraph: i, Table: -1, Row: -1, Column: -1, Text: text})
+		}
+	}, func(i int, t *Table) {
+		cellParagraphs(t, func(row, col, index int, p *Paragraph) {
+			if text := d.bodyParagraphText(p, cellScope); contains(text) {
+				matches = append(matches, TextMatch{Part: documentPart, Paragraph: index, Table: i, Row: row, Column: col, Text: text})
+			}
+		})
+	})
+	for _, name := range d.storyParts(scope) {
+		for i, text := range partParagraphs(d.files[name]) {
+			if contains(text) {
+				matches = append(matches, TextMatch{Part: name, Paragraph: i, Table: -1, Row: -1, Column: -1, Text: text})
+			}
+		}
+	}
+	return matches
+}
+
+// withoutHiddenText returns a copy of the document to read text from with
+// its hidden runs deleted. Deleting runs leaves paragraphs in place, so
+// matches found in it locate paragraphs of the document too.
+func (d *Document) withoutHiddenText() *Document {
+	c := &Document{
+		Body:   deepCopy(d.Body),
+		Rels:   d.Rels,
+		files:  make(map[string][]byte, len(d.files)),
+		lazy:   d.lazy,
+		limits: d.limits,
+	}
+	// Parts RemoveHiddenText changes are replaced, not written to
+	for k, v := range d.files {
+		c.files[k] = v
+	}
+	c.RemoveHiddenText()
+	return c
+}
+
+// ReplaceTextIn replaces text in the parts of a document in a scope and
+// returns the number of text elements changed, as ReplaceText does
+func (d *Document) ReplaceTextIn(oldText, newText string, scope SearchScope) int {
+	if oldText == "" {
+		return 0
+	}
+
+	count := 0
+	replace := func(p *Paragraph, runs bool) {
+		if runs {
+			count += replaceInRuns(p, oldText, newText)
+		}
+		if scope.TextBoxes {
+			count += replaceInTextBoxes(p, oldText, newText)
+		}
+	}
+	d.forEachBlock(scope, func(i int, p *Paragraph) {
+		replace(p, scope.Body)
+	}, func(i int, t *Table) {
+		cellParagraphs(t, func(row, col, index int, p *Paragraph) {
+			replace(p, true)
+		})
+	})
+	for _, name := range d.storyParts(scope) {
+		d.files[name] = mapPartText(d.files[name], partTextPattern, func(text string) string {
+			if !strings.Contains(text, oldText) {
+				return text
+			}
+			count++
+			return strings.ReplaceAll(text, oldText, newText)
+		})
+	}
+	return count
+}
@@ -0,0 +1,86 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchScopes(t *testing.T) {
+	doc := newTextBoxDocument(t)
+	doc.SetPart("word/header1.xml", []byte(`<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>ACME &amp; Co</w:t></w:r></w:p></w:hdr>`))
+	doc.SetPart("word/footer1.xml", []byte(`<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>Page</w:t></w:r></w:p><w:p><w:r><w:t xml:space="preserve">ACME </w:t></w:r><w:r><w:t>confidential</w:t></w:r></w:p></w:ftr>`))
+	doc.SetPart("word/footnotes.xml", []byte(`<w:footnotes xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:footnote w:id="1"><w:p><w:r><w:t>See ACME filings</w:t></w:r><w:del><w:r><w:delText>ACME</w:delText></w:r></w:del></w:p></w:footnote></w:footnotes>`))
+	doc.SetPart("word/comments.xml", []byte(`<w:comments xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:comment w:id="0"><w:p><w:r><w:t>Check with ACME</w:t></w:r></w:p></w:comment></w:comments>`))
+
+	if text := doc.GetTextIn(DefaultSearchScope()); text != doc.GetText() {
+		t.Errorf("Expected the default scope to match GetText, got %q and %q", text, doc.GetText())
+	}
+	want := "Body text Callout ACME Legacy frame ACME & Co Page ACME confidential See ACME filings Check with ACME"
+	if text := doc.GetTextIn(FullSearchScope()); text != want {
+		t.Errorf("Expected all the text in order, got %q", text)
+	}
+
+	matches := doc.FindTextIn("acme", SearchScope{TextBoxes: true, Headers: true, Footers: true})
+	if len(matches) != 3 || matches[0].Part != "word/document.xml" || matches[0].Paragraph != 1 ||
+		matches[1].Part != "word/header1.xml" || matches[2].Part != "word/footer1.xml" || matches[2].Paragraph != 1 || matches[2].Text != "ACME confidential" {
+		t.Errorf("Unexpected matches: %+v", matches)
+	}
+
+	// Only the parts in the scope change
+	if count := doc.ReplaceTextIn("ACME", "Acme", SearchScope{Headers: true, Footnotes: true}); count != 2 {
+		t.Errorf("Expected 2 replacements, got %d", count)
+	}
+	header, _ := doc.GetPart("word/header1.xml")
+	footnotes, _ := doc.GetPart("word/footnotes.xml")
+	footer, _ := doc.GetPart("word/footer1.xml")
+	if !strings.Contains(string(header), "Acme &amp; Co") || !strings.Contains(string(footnotes), "See Acme filings") ||
+		!strings.Contains(string(footnotes), "<w:delText>ACME</w:delText>") || !strings.Contains(string(footer), "ACME") {
+		t.Errorf("Unexpected parts after replacing: %s %s %s", header, footnotes, footer)
+	}
+	if strings.Contains(doc.GetText(), "Acme") {
+		t.Errorf("Expected the body to be left alone, got %q", doc.GetText())
+	}
+
+	if count := doc.ReplaceTextIn("ACME", "Acme", FullSearchScope()); count != 3 || strings.Contains(doc.GetTextIn(FullSearchScope()), "ACME") {
+		t.Errorf("Expected the rest replaced, got %d: %q", count, doc.GetTextIn(FullSearchScope()))
+	}
+}
+
+func TestSearchScopeTables(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Agreement between ACME and the Client")
+	table := doc.AddTable(2, 2)
+	table.SetCellText(0, 0, "Party")
+	table.SetCellText(0, 1, "ACME Ltd")
+	nested := Table{Rows: []TblRow{{Cells: []TblCell{{Content: []Paragraph{{Runs: []Run{{Text: []Text{{Content: "Signed for ACME"}}}}}}}}}}}
+	table.Rows[1].Cells[1].Tables = append(table.Rows[1].Cells[1].Tables, nested)
+	doc.AddParagraph("End")
+
+	if text := doc.GetTextIn(SearchScope{Body: true, Tables: true}); text != "Agreement between ACME and the Client Party ACME Ltd Signed for ACME End" {
+		t.Errorf("Expected the table text where the table stands, got %q", text)
+	}
+
+	if matches := doc.FindText("acme"); len(matches) != 1 {
+		t.Errorf("Expected FindText to keep leaving tables out, got %v", matches)
+	}
+	matches := doc.FindTextIn("acme", SearchScope{Tables: true})
+	if len(matches) != 2 {
+		t.Fatalf("Expected 2 matches in the table, got %+v", matches)
+	}
+	if m := matches[0]; m.Table != 0 || m.Row != 0 || m.Column != 1 || m.Paragraph != 0 || m.Text != "ACME Ltd" {
+		t.Errorf("Unexpected first match: %+v", m)
+	}
+	if m := matches[1]; m.Row != 1 || m.Column != 1 || m.Paragraph != 1 || m.Text != "Signed for ACME" {
+		t.Errorf("Expected the nested table's paragraph in its cell, got %+v", m)
+	}
+
+	if count := doc.ReplaceTextIn("ACME", "Acme", SearchScope{Body: true, Tables: true}); count != 3 {
+		t.Errorf("Expected 3 replacements, got %d", count)
+	}
+	if cell, _ := table.GetCellText(0, 1); cell != "Acme Ltd" {
+		t.Errorf("Expected the cell text replaced, got %q", cell)
+	}
+	if text := doc.GetTextIn(FullSearchScope()); strings.Contains(text, "ACME") {
+		t.Errorf("Expected every ACME replaced, got %q", text)
+	}
+}
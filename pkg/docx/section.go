@@ -0,0 +1,250 @@
+package docx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// SectionBreakType is how a section starts relative to the previous one
+type SectionBreakType string
+
+// Section break types
+const (
+	SectionBreakNextPage   SectionBreakType = "nextPage"
+	SectionBreakContinuous SectionBreakType = "continuous" // On the same page
+	SectionBreakEvenPage   SectionBreakType = "evenPage"
+	SectionBreakOddPage    SectionBreakType = "oddPage"
+	SectionBreakNextColumn SectionBreakType = "nextColumn"
+)
+
+var (
+	sectionTypePattern = regexp.MustCompile(`<(?:\w+:)?type\b[^>]*?(?:/>|>\s*</(?:\w+:)?type>)`)
+	sectionValPattern  = regexp.MustCompile(`\b(?:\w+:)?val="([^"]*)"`)
+	pgSzPattern        = regexp.MustCompile(`<(?:\w+:)?pgSz\b[^>]*>`)
+	pgMarPattern       = regexp.MustCompile(`<(?:\w+:)?pgMar\b[^>]*>`)
+	pgNumTypePattern   = regexp.MustCompile(`<(?:\w+:)?pgNumType\b[^>]*?(?:/>|>\s*</(?:\w+:)?pgNumType>)`)
+	pgNumStartPattern  = regexp.MustCompile(`\s(?:\w+:)?start="[^"]*"`)
+
+	// sectionAfterTypePattern matches the first section property that comes after w:type
+	sectionAfterTypePattern = regexp.MustCompile(`<(?:\w+:)?(?:pgSz|pgMar|paperSrc|pgBorders|lnNumType|pgNumType|cols|formProt|vAlign|noEndnote|titlePg|textDirection|bidi|rtlGutter|docGrid|printerSettings|sectPrChange)\b`)
+
+	// sectionAfterPgNumTypePattern matches the first section property that comes after w:pgNumType
+	sectionAfterPgNumTypePattern = regexp.MustCompile(`<(?:\w+:)?(?:cols|formProt|vAlign|noEndnote|titlePg|textDirection|bidi|rtlGutter|docGrid|printerSettings|sectPrChange)\b`)
+)
+
+// SectionBreaks returns the indices of the paragraphs that end a section.
+// The last section of a document has its properties on the body instead and
+// is not included.
+func (d *Document) SectionBreaks() []int {
+	var breaks []int
+	for i, p := range d.Body.Paragraphs {
+		if p.Props != nil && p.Props.SectPr != nil {
+			breaks = append(breaks, i)
+		}
+	}
+	return breaks
+}
+
+// SectionAt returns the properties of the section containing the paragraph at
+// index, or nil if the document doesn't define any
+func (d *Document) SectionAt(index int) *RawElement {
+	for i := index; i >= 0 && i < len(d.Body.Paragraphs); i++ {
+		if p := d.Body.Paragraphs[i]; p.Props != nil && p.Props.SectPr != nil {
+			return p.Props.SectPr
+		}
+	}
+	return d.Body.SectPr
+}
+
+// PageSetup is the page size and margins of a section, in twentieths of a
+// point (twips) as Word stores them. Sizes the section doesn't set are zero.
+type PageSetup struct {
+	Width, Height            int
+	Top, Right, Bottom, Left int // Margins
+
+	// Start is how the section starts; empty means on a new page
+	Start SectionBreakType
+}
+
+// PageSetupAt returns the page setup of the section containing the
+// paragraph at index
+func (d *Document) PageSetupAt(index int) PageSetup {
+	sectPr := d.SectionAt(index)
+	if sectPr == nil {
+		return PageSetup{}
+	}
+	setup := PageSetup{
+		Width:  sectionAttr(sectPr.Inner, pgSzPattern, "w"),
+		Height: sectionAttr(sectPr.Inner, pgSzPattern, "h"),
+		Top:    sectionAttr(sectPr.Inner, pgMarPattern, "top"),
+		Right:  sectionAttr(sectPr.Inner, pgMarPattern, "right"),
+		Bottom: sectionAttr(sectPr.Inner, pgMarPattern, "bottom"),
+		Left:   sectionAttr(sectPr.Inner, pgMarPattern, "left"),
+	}
+	if tag := sectionTypePattern.Find(sectPr.Inner); tag != nil {
+		if m := sectionValPattern.FindSubmatch(tag); m != nil {
+			setup.Start = SectionBreakType(m[1])
+		}
+	}
+	return setup
+}
+
+// sectionAttr returns a whole-number attribute of the first element of
+// section properties matching pattern, or 0
+func sectionAttr(inner []byte, pattern *regexp.Regexp, attr string) int {
+	tag := pattern.Find(inner)
+	if tag == nil {
+		return 0
+	}
+	m := regexp.MustCompile(`\b(?:\w+:)?` + attr + `="(-?\d+)"`).FindSubmatch(tag)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(string(m[1]))
+	return n
+}
+
+// AddPageBreak adds a paragraph holding a hard page break at the end of the
+// document, so the content added next starts on a new page
+func (d *Document) AddPageBreak() {
+	d.Body.Paragraphs = append(d.Body.Paragraphs, Paragraph{
+		Runs: []Run{{Break: &Break{Type: "page"}}},
+	})
+}
+
+// AddSectionBreak ends the current section with an empty paragraph, the way
+// Word marks a section break, and starts a new one as set by typ. Both
+// sections keep the page setup, headers and footers of the current section.
+func (d *Document) AddSectionBreak(typ SectionBreakType) error {
+	if err := checkSectionBreakType(typ); err != nil {
+		return err
+	}
+
+	if d.Body.SectPr == nil {
+		d.Body.SectPr = &RawElement{}
+	}
+	ended := *d.Body.SectPr
+	ended.Attrs = append([]xml.Attr(nil), ended.Attrs...)
+	ended.Inner = append([]byte(nil), ended.Inner...)
+	d.Body.Paragraphs = append(d.Body.Paragraphs, Paragraph{Props: &PProps{SectPr: &ended}})
+
+	// The type belongs to the section it starts, which is now the body's
+	d.Body.SectPr.Inner = setSectionType(d.Body.SectPr.Inner, typ)
+	return nil
+}
+
+// Breaks reports whether the property starts its paragraph on a new page
+func (b *PageBreakBefore) Breaks() bool {
+	return b != nil && b.Val != "false" && b.Val != "0" && b.Val != "off"
+}
+
+// setSectionType replaces the w:type of section properties, keeping the
+// order of elements the schema requires
+func setSectionType(inner []byte, typ SectionBreakType) []byte {
+	inner = sectionTypePattern.ReplaceAll(inner, nil)
+	element := []byte(fmt.Sprintf(`<w:type w:val="%s"/>`, typ))
+
+	at := len(inner)
+	if loc := sectionAfterTypePattern.FindIndex(inner); loc != nil {
+		at = loc[0]
+	}
+	result := make([]byte, 0, len(inner)+len(element))
+	result = append(result, inner[:at]...)
+	result = append(result, element...)
+	return append(result, inner[at:]...)
+}
+
+// checkSectionBreakType returns an error for unknown section break types
+func checkSectionBreakType(typ SectionBreakType) error {
+	switch typ {
+	case SectionBreakNextPage, SectionBreakContinuous, SectionBreakEvenPage, SectionBreakOddPage, SectionBreakNextColumn:
+		return nil
+	}
+	return fmt.Errorf("unsupported section break type %q", typ)
+}
+
+// AppendSection appends the body of src like AppendDocument, in sections of
+// its own carrying the page setup, headers and footers of src. The first
+// starts as set by typ unless it is the first content of the document.
+// Headers and footers src lacks are left empty rather than continuing those
+// of the section before, as Word would otherwise show them.
+func (d *Document) AppendSection(src *Document, typ SectionBreakType) error {
+	if err := checkSectionBreakType(typ); err != nil {
+		return err
+	}
+
+	// End the current section with an empty paragraph, the way Word marks a section break
+	var previous *RawElement
+	if len(d.Body.Paragraphs) > 0 || len(d.Body.Tables) > 0 || len(d.Body.SDTs) > 0 {
+		previous = d.Body.SectPr
+		if previous == nil {
+			previous = &RawElement{}
+		}
+		d.Body.Paragraphs = append(d.Body.Paragraphs, Paragraph{Props: &PProps{SectPr: previous}})
+	}
+
+	start := len(d.Body.Paragraphs)
+	maps, err := d.insertDocument(start, src, true)
+	if err != nil {
+		if previous != nil {
+			d.Body.Paragraphs = d.Body.Paragraphs[:start-1]
+		}
+		return err
+	}
+	d.Body.SectPr = &RawElement{}
+	if src.Body.SectPr != nil {
+		d.Body.SectPr = importSection(src.Body.SectPr, maps)
+	}
+
+	if previous != nil {
+		first := d.SectionAt(start)
+		first.Inner = setSectionType(first.Inner, typ)
+		for _, footer := range []bool{false, true} {
+			before, refs := headerReferences(previous), headerReferences(first)
+			if footer {
+				before, refs = footerReferences(previous), footerReferences(first)
+			}
+			for refType := range before {
+				if _, ok := refs[refType]; !ok {
+					addSectionReference(first, footer, refType, d.addStoryPart(footer))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RestartPageNumbering numbers the pages of the section containing the
+// paragraph at index from start, rather than continuing from the section
+// before. An index equal to the paragraph count selects the last section.
+func (d *Document) RestartPageNumbering(index, start int) error {
+	if index < 0 || index > len(d.Body.Paragraphs) {
+		return fmt.Errorf("paragraph index %d %w", index, ErrIndexOutOfRange)
+	}
+	if d.Body.SectPr == nil {
+		d.Body.SectPr = &RawElement{}
+	}
+	sectPr := d.SectionAt(index)
+	sectPr.Inner = setPageNumberStart(sectPr.Inner, start)
+	return nil
+}
+
+// setPageNumberStart sets the start of the w:pgNumType of section
+// properties, adding one where the schema requires it if there is none
+func setPageNumberStart(inner []byte, start int) []byte {
+	if tag := pgNumTypePattern.FindIndex(inner); tag != nil {
+		element := pgNumStartPattern.ReplaceAll(inner[tag[0]:tag[1]], nil)
+		element = regexp.MustCompile(`^<(?:\w+:)?pgNumType\b`).ReplaceAll(element, []byte(fmt.Sprintf(`$0 w:start="%d"`, start)))
+		return slices.Concat(inner[:tag[0]], element, inner[tag[1]:])
+	}
+
+	element := []byte(fmt.Sprintf(`<w:pgNumType w:start="%d"/>`, start))
+	at := len(inner)
+	if loc := sectionAfterPgNumTypePattern.FindIndex(inner); loc != nil {
+		at = loc[0]
+	}
+	return slices.Concat(inner[:at], element, inner[at:])
+}
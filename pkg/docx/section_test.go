@@ -0,0 +1,168 @@
+package docx
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPageAndSectionBreaks(t *testing.T) {
+	doc := New()
+	doc.Body.SectPr = &RawElement{Inner: []byte(`<w:pgSz w:w="12240" w:h="15840"/><w:pgMar w:top="1440"/>`)}
+	doc.AddParagraph("Cover")
+	doc.AddPageBreak()
+	doc.AddParagraph("Chapter", WithPageBreakBefore())
+	if err := doc.AddSectionBreak(SectionBreakOddPage); err != nil {
+		t.Fatalf("AddSectionBreak failed: %v", err)
+	}
+	doc.AddParagraph("Appendix")
+	if err := doc.AddSectionBreak(SectionBreakContinuous); err != nil {
+		t.Fatalf("AddSectionBreak failed: %v", err)
+	}
+	if err := doc.AddSectionBreak("sideways"); err == nil {
+		t.Errorf("Expected an error for an unknown section break type")
+	}
+
+	tmpFile := filepath.Join(t.TempDir(), "breaks.docx")
+	if err := doc.Save(tmpFile); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := Open(tmpFile)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	if r := loaded.Body.Paragraphs[1].Runs; len(r) != 1 || r[0].Break == nil || r[0].Break.Type != "page" {
+		t.Errorf("Expected a page break paragraph, got %+v", loaded.Body.Paragraphs[1])
+	}
+	if props := loaded.Body.Paragraphs[2].Props; props == nil || !props.PageBreakBefore.Breaks() {
+		t.Errorf("Expected the chapter to start on a new page")
+	}
+
+	breaks := loaded.SectionBreaks()
+	if len(breaks) != 2 || breaks[0] != 3 || breaks[1] != 5 {
+		t.Fatalf("Expected section breaks at 3 and 5, got %v", breaks)
+	}
+	first := string(loaded.Body.Paragraphs[3].Props.SectPr.Inner)
+	second := string(loaded.Body.Paragraphs[5].Props.SectPr.Inner)
+	last := string(loaded.Body.SectPr.Inner)
+	if strings.Contains(first, "type") || !strings.Contains(first, "pgSz") {
+		t.Errorf("Expected the first section to keep the page setup without a type, got %s", first)
+	}
+	if !strings.Contains(second, `<w:type w:val="oddPage"/><w:pgSz`) {
+		t.Errorf("Expected the second section to start on an odd page, got %s", second)
+	}
+	if !strings.Contains(last, `<w:type w:val="continuous"/><w:pgSz`) || strings.Contains(last, "oddPage") {
+		t.Errorf("Expected the last section to be continuous, got %s", last)
+	}
+
+	if pages := loaded.Stats().EstimatedPages; pages != 3 {
+		t.Errorf("Expected 3 estimated pages, got %d", pages)
+	}
+}
+
+func TestPageSetupAt(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Portrait")
+	if setup := doc.PageSetupAt(0); setup != (PageSetup{}) {
+		t.Errorf("Expected no page setup without section properties, got %+v", setup)
+	}
+
+	doc.Body.SectPr = &RawElement{Inner: []byte(`<w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="-1440" w:right="1134" w:bottom="1440" w:left="1134" w:header="708"/>`)}
+	if err := doc.AddSectionBreak(SectionBreakContinuous); err != nil {
+		t.Fatalf("AddSectionBreak failed: %v", err)
+	}
+	doc.AddParagraph("Continued")
+
+	want := PageSetup{Width: 11906, Height: 16838, Top: -1440, Right: 1134, Bottom: 1440, Left: 1134}
+	if setup := doc.PageSetupAt(0); setup != want {
+		t.Errorf("Expected %+v, got %+v", want, setup)
+	}
+	want.Start = SectionBreakContinuous
+	if setup := doc.PageSetupAt(2); setup != want {
+		t.Errorf("Expected %+v, got %+v", want, setup)
+	}
+}
+
+func TestAppendSectionAndRestartPageNumbering(t *testing.T) {
+	dir := t.TempDir()
+	report := New()
+	report.Body.SectPr = &RawElement{Inner: []byte(`<w:pgSz w:w="15840" w:h="12240" w:orient="landscape"/><w:pgNumType w:fmt="lowerRoman"/><w:cols w:space="720"/>`)}
+	report.AddParagraph("Report body")
+	report.SetHeader(HeaderTypeDefault, "Report A")
+	report.SetFooter(FooterTypeDefault, "Report A footer")
+	path := filepath.Join(dir, "report.docx")
+	if err := report.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	report, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	notes := New()
+	notes.Body.SectPr = &RawElement{Inner: []byte(`<w:pgSz w:w="12240" w:h="15840"/><w:cols w:space="720"/>`)}
+	notes.AddParagraph("Notes body")
+
+	doc := New()
+	for _, src := range []*Document{report, notes} {
+		if err := doc.AppendSection(src, SectionBreakNextPage); err != nil {
+			t.Fatalf("AppendSection failed: %v", err)
+		}
+	}
+	if err := doc.AppendSection(notes, "sideways"); err == nil {
+		t.Errorf("Expected an error for an unknown section break type")
+	}
+	if err := doc.RestartPageNumbering(0, 1); err != nil {
+		t.Fatalf("RestartPageNumbering failed: %v", err)
+	}
+	if err := doc.RestartPageNumbering(2, 1); err != nil {
+		t.Fatalf("RestartPageNumbering failed: %v", err)
+	}
+	if err := doc.RestartPageNumbering(4, 1); err == nil {
+		t.Errorf("Expected an error for an index out of range")
+	}
+
+	out := filepath.Join(dir, "bound.docx")
+	if err := doc.Save(out); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := Open(out)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if breaks := loaded.SectionBreaks(); len(breaks) != 1 || breaks[0] != 1 {
+		t.Fatalf("Expected a section break after the report, got %v", breaks)
+	}
+
+	first := loaded.Body.Paragraphs[1].Props.SectPr
+	if inner := string(first.Inner); !strings.Contains(inner, `landscape`) || !strings.Contains(inner, `<w:pgNumType w:start="1" w:fmt="lowerRoman"/>`) {
+		t.Errorf("Expected the report's page setup with numbering restarted, got %s", inner)
+	}
+	storyText := func(sectPr *RawElement, footer bool) string {
+		refs := headerReferences(sectPr)
+		if footer {
+			refs = footerReferences(sectPr)
+		}
+		rel, ok := loaded.findRelationship(refs["default"])
+		if !ok {
+			t.Fatalf("Expected a default reference, got %s", sectPr.Inner)
+		}
+		data, _ := loaded.GetPart(resolvePartName(rel.Target))
+		return string(data)
+	}
+	if !strings.Contains(storyText(first, false), "Report A") || !strings.Contains(storyText(first, true), "Report A footer") {
+		t.Errorf("Expected the report section to keep its header and footer")
+	}
+
+	last := loaded.Body.SectPr
+	if inner := string(last.Inner); !strings.Contains(inner, `<w:type w:val="nextPage"/><w:pgSz w:w="12240"`) ||
+		!strings.Contains(inner, `<w:pgNumType w:start="1"/><w:cols`) {
+		t.Errorf("Expected the notes section on a new page with numbering restarted, got %s", inner)
+	}
+	if strings.Contains(storyText(last, false), "Report A") || strings.Contains(storyText(last, true), "Report A") {
+		t.Errorf("Expected the notes section not to continue the report's header and footer")
+	}
+	if text := loaded.GetText(); !strings.Contains(text, "Report body") || !strings.Contains(text, "Notes body") {
+		t.Errorf("Expected both bodies, got %q", text)
+	}
+}
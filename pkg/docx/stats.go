@@ -0,0 +1,242 @@
+package docx
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WordsPerPage is the number of words of a typical page of text, used to
+// estimate page counts
+const WordsPerPage = 500
+
+// Stats summarizes the content of a document
+type Stats struct {
+	Words              int
+	Characters         int // Including spaces
+	CharactersNoSpaces int
+	Paragraphs         int // Body paragraphs, as GetParagraphCount
+	EmptyParagraphs    int // Body paragraphs without text
+	Tables             int // Body tables, as GetTableCount
+	Images             int
+
+	// Headings lists the heading paragraphs of the body, in order
+	Headings []Heading
+
+	// AverageWordsPerParagraph is taken over the paragraphs with text
+	AverageWordsPerParagraph float64
+
+	// EstimatedPages assumes WordsPerPage words per page, and at least one
+	// page per hard page break or paragraph starting a new page
+	EstimatedPages int
+}
+
+// Heading is a heading paragraph of the body
+type Heading struct {
+	Level int    // 1 for Heading1, 2 for Heading2 and so on
+	Text  string // Text of the heading
+	Index int    // Index of the paragraph in the body
+}
+
+// HeadingLevel returns the heading level of a paragraph from its style,
+// Heading1 to Heading9 (or "heading 1" and the like), or 0 if it is not a
+// heading
+func HeadingLevel(p *Paragraph) int {
+	if p.Props == nil || p.Props.Style == nil {
+		return 0
+	}
+	style := strings.ToLower(strings.ReplaceAll(p.Props.Style.Val, " ", ""))
+	if !strings.HasPrefix(style, "heading") {
+		return 0
+	}
+	level, err := strconv.Atoi(strings.TrimPrefix(style, "heading"))
+	if err != nil || level < 1 || level > 9 {
+		return 0
+	}
+	return level
+}
+
+// Stats counts the words, characters, paragraphs, tables and images of
+// the document and lists its headings. Words and characters include the
+// text of tables, content controls and text boxes.
+func (d *Document) Stats() Stats {
+	stats := Stats{
+		Paragraphs: len(d.Body.Paragraphs),
+		Tables:     len(d.Body.Tables),
+	}
+
+	textParagraphs, pageBreaks := 0, 0
+	_ = d.WalkParagraphs(0, func(p *Paragraph, depth int) error {
+		text := d.paragraphOwnText(p)
+		words := len(strings.Fields(text))
+		stats.Words += words
+		stats.Characters += utf8.RuneCountInString(text)
+		for _, r := range text {
+			if !unicode.IsSpace(r) {
+				stats.CharactersNoSpaces++
+			}
+		}
+		if words > 0 {
+			textParagraphs++
+		}
+
+		if p.Props != nil && p.Props.PageBreakBefore.Breaks() {
+			pageBreaks++
+		}
+		p.forEachRun(func(r *Run) {
+			if r.Drawing != nil {
+				stats.Images++
+			}
+			if r.Break != nil && r.Break.Type == "page" {
+				pageBreaks++
+			}
+		})
+		return nil
+	})
+
+	for i := range d.Body.Paragraphs {
+		if strings.TrimSpace(d.paragraphText(&d.Body.Paragraphs[i])) == "" {
+			stats.EmptyParagraphs++
+		}
+	}
+	stats.Headings = d.headings()
+
+	if textParagraphs > 0 {
+		stats.AverageWordsPerParagraph = float64(stats.Words) / float64(textParagraphs)
+	}
+	stats.EstimatedPages = max((stats.Words+WordsPerPage-1)/WordsPerPage, pageBreaks+1)
+	return stats
+}
+
+// PageEstimateOptions configures EstimatePages. Zero fields use the defaults.
+type PageEstimateOptions struct {
+	// WordsPerPage is the number of words a page holds; defaults to WordsPerPage
+	WordsPerPage int
+
+	// CharactersPerPage, when set, measures pages in characters, spaces
+	// included, instead of words; it suits text without spaces between words
+	CharactersPerPage int
+
+	// UsePageSize scales what a page holds by the text area of its section,
+	// the page less its margins, against a US Letter page with 1 inch margins
+	UsePageSize bool
+}
+
+// DefaultPageEstimateOptions returns default page estimate options
+func DefaultPageEstimateOptions() PageEstimateOptions {
+	return PageEstimateOptions{WordsPerPage: WordsPerPage}
+}
+
+// letterTextArea is the text area of a US Letter page with 1 inch margins, in square twips
+const letterTextArea = (12240 - 2*1440) * (15840 - 2*1440)
+
+// EstimatePages returns the page each body paragraph starts on, from 1, as
+// a word processor would lay them out if every page held the same amount
+// of text. Tables and content controls fill the page before the paragraph
+// that follows them. Page breaks, paragraphs starting a new page and
+// sections starting on a new, odd or even page start a page.
+func (d *Document) EstimatePages(opts PageEstimateOptions) []int {
+	if opts.WordsPerPage <= 0 {
+		opts.WordsPerPage = WordsPerPage
+	}
+	measure := func(text string) float64 {
+		if opts.CharactersPerPage > 0 {
+			return float64(utf8.RuneCountInString(text)) / float64(opts.CharactersPerPage)
+		}
+		return float64(len(strings.Fields(text))) / float64(opts.WordsPerPage)
+	}
+	// size returns the share of a page text takes in the section of the paragraph at index
+	size := func(index int, text string) float64 {
+		share := measure(text)
+		if opts.UsePageSize {
+			setup := d.PageSetupAt(index)
+			width := setup.Width - max(setup.Left, 0) - max(setup.Right, 0)
+			height := setup.Height - max(setup.Top, 0) - max(setup.Bottom, 0)
+			if width > 0 && height > 0 {
+				share *= float64(letterTextArea) / float64(width*height)
+			}
+		}
+		return share
+	}
+
+	// Text of the tables and content controls, by the paragraph they come before
+	before := make(map[int]string)
+	collect := func(position int, tables []Table, sdts []SDT) {
+		w := newWalker(DefaultMaxDepth)
+		w.paragraph = func(p *Paragraph, depth int) error {
+			before[position] += " " + d.paragraphOwnText(p)
+			return nil
+		}
+		_ = w.walkBlocks(nil, tables, sdts, 0)
+	}
+	for _, t := range d.Body.Tables {
+		collect(t.Position, []Table{t}, nil)
+	}
+	for _, sdt := range d.Body.SDTs {
+		collect(sdt.Position, nil, []SDT{sdt})
+	}
+
+	page, used := 1, 0.0
+	placed := false // Whether anything is on the current page
+	newPage := func() {
+		if placed || used > 0 {
+			page++
+		}
+		used, placed = 0, false
+	}
+	place := func(share float64) {
+		used += share
+		placed = true
+		for used >= 1 {
+			page++
+			used--
+			placed = used > 0
+		}
+	}
+
+	starts := make([]int, len(d.Body.Paragraphs))
+	for i := range d.Body.Paragraphs {
+		p := &d.Body.Paragraphs[i]
+		if text, ok := before[i]; ok {
+			place(size(i, text))
+		}
+		if p.Props != nil && p.Props.PageBreakBefore.Breaks() {
+			newPage()
+		}
+		starts[i] = page
+		placed = true
+
+		// Text after a page break in the paragraph goes on the next page
+		rest := size(i, d.paragraphOwnText(p))
+		var text strings.Builder
+		p.forEachRun(func(r *Run) {
+			if r.Break != nil && r.Break.Type == "page" {
+				share := min(size(i, text.String()), rest)
+				place(share)
+				rest -= share
+				text.Reset()
+				newPage()
+			}
+			for _, t := range r.Text {
+				text.WriteString(t.Content)
+			}
+		})
+		place(rest)
+		if box := d.paragraphTextBoxText(p); len(box) > 0 {
+			place(size(i, strings.Join(box, " ")))
+		}
+
+		if p.Props != nil && p.Props.SectPr != nil {
+			switch next := d.PageSetupAt(i + 1).Start; next {
+			case SectionBreakContinuous, SectionBreakNextColumn:
+			default:
+				newPage()
+				if next == SectionBreakOddPage && page%2 == 0 || next == SectionBreakEvenPage && page%2 == 1 {
+					page++ // A blank page
+				}
+			}
+		}
+	}
+	return starts
+}
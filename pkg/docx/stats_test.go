@@ -0,0 +1,89 @@
+package docx
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Report", WithStyle("Heading1"))
+	doc.AddParagraph("The quick brown fox jumps.")
+	doc.AddParagraph("")
+	doc.AddParagraph("Details", WithStyle("heading 2"))
+	doc.AddParagraph("Über café")
+	table := doc.AddTable(1, 2)
+	table.SetCellText(0, 0, "cell one")
+	if err := doc.AddImageFromBytes("logo.png", createPNGData()); err != nil {
+		t.Fatalf("AddImageFromBytes failed: %v", err)
+	}
+
+	stats := doc.Stats()
+
+	// Report, 5 words, Details, 2 words, and 2 in the table
+	if stats.Words != 11 {
+		t.Errorf("Expected 11 words, got %d", stats.Words)
+	}
+	want := len([]rune("Report" + "The quick brown fox jumps." + "Details" + "Über café" + "cell one"))
+	if stats.Characters != want {
+		t.Errorf("Expected %d characters, got %d", want, stats.Characters)
+	}
+	if stats.CharactersNoSpaces != want-6 {
+		t.Errorf("Expected %d characters without spaces, got %d", want-6, stats.CharactersNoSpaces)
+	}
+	if stats.Paragraphs != 6 || stats.EmptyParagraphs != 2 || stats.Tables != 1 || stats.Images != 1 {
+		t.Errorf("Unexpected counts: %+v", stats)
+	}
+	if stats.AverageWordsPerParagraph != 11.0/5 {
+		t.Errorf("Expected %.2f words per paragraph, got %.2f", 11.0/5, stats.AverageWordsPerParagraph)
+	}
+	if stats.EstimatedPages != 1 {
+		t.Errorf("Expected 1 page, got %d", stats.EstimatedPages)
+	}
+
+	wantHeadings := []Heading{{Level: 1, Text: "Report", Index: 0}, {Level: 2, Text: "Details", Index: 3}}
+	if len(stats.Headings) != len(wantHeadings) {
+		t.Fatalf("Expected headings %+v, got %+v", wantHeadings, stats.Headings)
+	}
+	for i, h := range wantHeadings {
+		if stats.Headings[i] != h {
+			t.Errorf("Heading %d: expected %+v, got %+v", i, h, stats.Headings[i])
+		}
+	}
+}
+
+func TestEstimatePages(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("one two three four five six")
+	doc.AddParagraph("one two three four five six") // Runs over onto page 2
+	doc.AddParagraph("chapter", WithPageBreakBefore())
+	table := doc.AddTable(1, 1)
+	table.SetCellText(0, 0, "one two three four five six seven eight nine ten") // Fills page 3
+	doc.AddParagraph("after the table")
+	if err := doc.AddSectionBreak(SectionBreakEvenPage); err != nil {
+		t.Fatalf("AddSectionBreak failed: %v", err)
+	}
+	doc.AddParagraph("appendix")
+
+	starts := doc.EstimatePages(PageEstimateOptions{WordsPerPage: 10})
+	want := []int{1, 1, 3, 4, 4, 6}
+	if len(starts) != len(want) {
+		t.Fatalf("Expected %d pages, got %v", len(want), starts)
+	}
+	for i := range want {
+		if starts[i] != want[i] {
+			t.Errorf("Expected pages %v, got %v", want, starts)
+			break
+		}
+	}
+
+	// Half the usual text area holds half the words
+	narrow := New()
+	narrow.Body.SectPr = &RawElement{Inner: []byte(`<w:pgSz w:w="12240" w:h="15840"/><w:pgMar w:top="1440" w:right="6120" w:bottom="1440" w:left="1440"/>`)}
+	for i := 0; i < 3; i++ {
+		narrow.AddParagraph("one two three four five")
+	}
+	if starts := narrow.EstimatePages(PageEstimateOptions{WordsPerPage: 10, UsePageSize: true}); starts[2] != 3 {
+		t.Errorf("Expected the third paragraph on page 3, got %v", starts)
+	}
+	if starts := narrow.EstimatePages(PageEstimateOptions{CharactersPerPage: 46}); starts[2] != 2 {
+		t.Errorf("Expected the third paragraph on page 2 at 46 characters a page, got %v", starts)
+	}
+}
@@ -0,0 +1,123 @@
+package docx
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BlockType is the kind of a block of text
+type BlockType string
+
+// Block types
+const (
+	BlockHeading   BlockType = "heading"
+	BlockParagraph BlockType = "paragraph"
+	BlockListItem  BlockType = "list-item"
+	BlockTableCell BlockType = "table-cell"
+)
+
+// TextBlock is the text of a paragraph or table cell, with its place in the
+// structure of the document
+type TextBlock struct {
+	Type  BlockType
+	Text  string
+	Style string // Paragraph style ID, e.g. Heading2 or ListParagraph
+
+	// Level is the heading level, 1 for Heading1, or the list level,
+	// 1 for top-level items; 0 for other blocks
+	Level int
+
+	// Paragraph is the index of the body paragraph, or -1 for table cells
+	Paragraph int
+
+	// Table, Row and Column locate table cells: the index of the table in
+	// the body and of the cell's row and column; -1 for paragraphs
+	Table  int
+	Row    int
+	Column int
+}
+
+// GetStructuredText returns the text of the body as blocks in reading order:
+// headings, paragraphs and list items, with tables where they stand among
+// them, one block per cell. Paragraphs without text are left out. The text
+// of a cell's paragraphs and nested tables is joined with line breaks.
+func (d *Document) GetStructuredText() []TextBlock {
+	var blocks []TextBlock
+
+	// Tables sit between paragraphs, after the number of paragraphs given by their position
+	tables := make([]int, len(d.Body.Tables))
+	for i := range tables {
+		tables[i] = i
+	}
+	sort.SliceStable(tables, func(i, j int) bool {
+		return d.Body.Tables[tables[i]].Position < d.Body.Tables[tables[j]].Position
+	})
+	addTables := func(before int) {
+		for len(tables) > 0 && (before < 0 || d.Body.Tables[tables[0]].Position <= before) {
+			blocks = append(blocks, d.tableBlocks(tables[0])...)
+			tables = tables[1:]
+		}
+	}
+
+	for i := range d.Body.Paragraphs {
+		addTables(i)
+		p := &d.Body.Paragraphs[i]
+		text := d.paragraphText(p)
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		block := TextBlock{Type: BlockParagraph, Text: text, Paragraph: i, Table: -1, Row: -1, Column: -1}
+		if p.Props != nil && p.Props.Style != nil {
+			block.Style = p.Props.Style.Val
+		}
+		if level := HeadingLevel(p); level > 0 {
+			block.Type, block.Level = BlockHeading, level
+		} else if p.Props != nil && p.Props.NumPr != nil && (p.Props.NumPr.NumID == nil || p.Props.NumPr.NumID.Val != "0") {
+			block.Type, block.Level = BlockListItem, 1
+			if p.Props.NumPr.ILvl != nil {
+				if n, err := strconv.Atoi(p.Props.NumPr.ILvl.Val); err == nil && n >= 0 {
+					block.Level = n + 1
+				}
+			}
+		}
+		blocks = append(blocks, block)
+	}
+	addTables(-1)
+
+	return blocks
+}
+
+// tableBlocks returns a block for each cell of a body table
+func (d *Document) tableBlocks(index int) []TextBlock {
+	var blocks []TextBlock
+	for r, row := range d.Body.Tables[index].Rows {
+		for c := range row.Cells {
+			blocks = append(blocks, TextBlock{
+				Type:      BlockTableCell,
+				Text:      strings.Join(d.cellLines(&row.Cells[c]), "\n"),
+				Paragraph: -1,
+				Table:     index,
+				Row:       r,
+				Column:    c,
+			})
+		}
+	}
+	return blocks
+}
+
+// cellLines returns the text of each paragraph of a cell with text, those
+// of its nested tables included
+func (d *Document) cellLines(cell *TblCell) []string {
+	var lines []string
+	w := newWalker(DefaultMaxDepth)
+	w.paragraph = func(p *Paragraph, depth int) error {
+		if text := d.paragraphOwnText(p); strings.TrimSpace(text) != "" {
+			lines = append(lines, text)
+		}
+		return nil
+	}
+	_ = w.walkBlocks(cell.Content, cell.Tables, cell.SDTs, 0)
+	return lines
+}
@@ -0,0 +1,35 @@
+package docx
+
+import "testing"
+
+func TestGetStructuredText(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Report", WithStyle("Heading1"))
+	doc.AddParagraph("Introduction text")
+	doc.AddParagraph("")
+	table := doc.AddTable(1, 2)
+	table.SetCellText(0, 0, "Region")
+	table.SetCellText(0, 1, "Total")
+	table.Rows[0].Cells[1].Content = append(table.Rows[0].Cells[1].Content, Paragraph{Runs: []Run{{Text: []Text{{Content: "42"}}}}})
+	doc.AddParagraph("First point", WithStyle("ListParagraph"))
+	doc.Body.Paragraphs[3].Props.NumPr = &NumPr{ILvl: &NumLevel{Val: "1"}, NumID: &NumID{Val: "3"}}
+	doc.AddParagraph("Details", WithStyle("heading 2"))
+
+	blocks := doc.GetStructuredText()
+	want := []TextBlock{
+		{Type: BlockHeading, Text: "Report", Style: "Heading1", Level: 1, Paragraph: 0, Table: -1, Row: -1, Column: -1},
+		{Type: BlockParagraph, Text: "Introduction text", Paragraph: 1, Table: -1, Row: -1, Column: -1},
+		{Type: BlockTableCell, Text: "Region", Paragraph: -1, Table: 0, Row: 0, Column: 0},
+		{Type: BlockTableCell, Text: "Total\n42", Paragraph: -1, Table: 0, Row: 0, Column: 1},
+		{Type: BlockListItem, Text: "First point", Style: "ListParagraph", Level: 2, Paragraph: 3, Table: -1, Row: -1, Column: -1},
+		{Type: BlockHeading, Text: "Details", Style: "heading 2", Level: 2, Paragraph: 4, Table: -1, Row: -1, Column: -1},
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("Expected %d blocks, got %+v", len(want), blocks)
+	}
+	for i := range want {
+		if blocks[i] != want[i] {
+			t.Errorf("Block %d: expected %+v, got %+v", i, want[i], blocks[i])
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EffectiveFormat is the formatting text is shown with once styles are
+// applied: document defaults, then each style a paragraph style is based
+// on, then the style itself and direct formatting
+type EffectiveFormat struct {
+	Style     string // ID of the paragraph style, the default one when none is set
+	Bold      bool
+	Italic    bool
+	Size      float64 // Font size in points
+	Font      string  // Font of Latin text, with theme fonts resolved
+	Color     string  // Hex color such as "FF0000", or "auto"
+	Alignment string  // left, center, right or both (justified)
+}
+
+// Word's formatting where neither defaults nor styles give any
+const (
+	defaultFontSize = 10.0
+	defaultFont     = "Times New Roman"
+)
+
+// stylesXML is the part of styles.xml ResolveEffectiveFormat reads
+type stylesXML struct {
+	DocDefaults struct {
+		RPr *formatRPrXML `xml:"rPrDefault>rPr"`
+		PPr *formatPPrXML `xml:"pPrDefault>pPr"`
+	} `xml:"docDefaults"`
+	Styles []styleXML `xml:"style"`
+}
+
+type styleXML struct {
+	Type    string        `xml:"type,attr"`
+	ID      string        `xml:"styleId,attr"`
+	Default string        `xml:"default,attr"`
+	BasedOn *valXML       `xml:"basedOn"`
+	PPr     *formatPPrXML `xml:"pPr"`
+	RPr     *formatRPrXML `xml:"rPr"`
+}
+
+type formatPPrXML struct {
+	Jc *valXML `xml:"jc"`
+}
+
+type formatRPrXML struct {
+	RFonts *struct {
+		ASCII      string `xml:"ascii,attr"`
+		ASCIITheme string `xml:"asciiTheme,attr"`
+	} `xml:"rFonts"`
+	Bold   *valXML `xml:"b"`
+	Italic *valXML `xml:"i"`
+	Size   *valXML `xml:"sz"`
+	Color  *valXML `xml:"color"`
+}
+
+// styleResolver holds the styles of a document, parsed once for as long as
+// styles.xml doesn't change
+type styleResolver struct {
+	data          []byte // styles.xml as parsed
+	defaults      EffectiveFormat
+	styles        map[string]styleXML
+	defaultPStyle string
+}
+
+var themeFontPattern = regexp.MustCompile(`(?s)<a:(major|minor)Font>.*?<a:latin\s+typeface="([^"]*)"`)
+
+// styleResolver returns the resolver for the current styles of the document
+func (d *Document) styleResolver() *styleResolver {
+	data := d.files[stylesPart]
+	if d.styleCache != nil && bytes.Equal(d.styleCache.data, data) {
+		return d.styleCache
+	}
+
+	r := &styleResolver{
+		data:     data,
+		defaults: EffectiveFormat{Size: defaultFontSize, Font: defaultFont, Color: "auto", Alignment: "left"},
+		styles:   make(map[string]styleXML),
+	}
+	var parsed stylesXML
+	if err := xml.Unmarshal(data, &parsed); err == nil {
+		themeFonts := d.themeFonts()
+		applyPPr(&r.defaults, parsed.DocDefaults.PPr)
+		applyRPr(&r.defaults, parsed.DocDefaults.RPr, themeFonts)
+		for _, s := range parsed.Styles {
+			if s.RPr != nil && s.RPr.RFonts != nil && s.RPr.RFonts.ASCII == "" {
+				s.RPr.RFonts.ASCII = themeFonts[themeFontKind(s.RPr.RFonts.ASCIITheme)]
+			}
+			r.styles[s.ID] = s
+			if s.Type == "paragraph" && s.Default != "" && isOn(s.Default) && r.defaultPStyle == "" {
+				r.defaultPStyle = s.ID
+			}
+		}
+	}
+	d.styleCache = r
+	return r
+}
+
+// themeFonts returns the major and minor Latin fonts of the document's theme
+func (d *Document) themeFonts() map[string]string {
+	fonts := make(map[string]string)
+	part := "word/theme/theme1.xml"
+	if rels, err := d.GetRelationships(); err == nil {
+		for _, rel := range rels {
+			if strings.HasSuffix(rel.Type, "/theme") {
+				part = resolvePartName(rel.Target)
+			}
+		}
+	}
+	for _, m := range themeFontPattern.FindAllSubmatch(d.files[part], -1) {
+		fonts[string(m[1])] = string(m[2])
+	}
+	return fonts
+}
+
+// themeFontKind returns "major" or "minor" for theme font references such
+// as minorHAnsi
+func themeFontKind(ref string) string {
+	if strings.HasPrefix(ref, "major") {
+		return "major"
+	}
+	if strings.HasPrefix(ref, "minor") {
+		return "minor"
+	}
+	return ""
+}
+
+// paragraphFormat returns the format of a paragraph style, from the defaults
+// down its basedOn chain
+func (r *styleResolver) paragraphFormat(styleID string) EffectiveFormat {
+	var chain []styleXML
+	seen := make(map[string]bool)
+	for id := styleID; id != "" && !seen[id]; {
+		seen[id] = true
+		s, ok := r.styles[id]
+		if !ok {
+			break
+		}
+		chain = append(chain, s)
+		id = ""
+		if s.BasedOn != nil {
+			id = s.BasedOn.Val
+		}
+	}
+
+	f := r.defaults
+	f.Style = styleID
+	for i := len(chain) - 1; i >= 0; i-- {
+		applyPPr(&f, chain[i].PPr)
+		applyRPr(&f, chain[i].RPr, nil)
+	}
+	return f
+}
+
+func applyPPr(f *EffectiveFormat, pPr *formatPPrXML) {
+	if pPr != nil && pPr.Jc != nil {
+		f.Alignment = normalizeAlignment(pPr.Jc.Val)
+	}
+}
+
+func applyRPr(f *EffectiveFormat, rPr *formatRPrXML, themeFonts map[string]string) {
+	if rPr == nil {
+		return
+	}
+	if rPr.RFonts != nil {
+		if font := rPr.RFonts.ASCII; font != "" {
+			f.Font = font
+		} else if font := themeFonts[themeFontKind(rPr.RFonts.ASCIITheme)]; font != "" {
+			f.Font = font
+		}
+	}
+	if rPr.Bold != nil {
+		f.Bold = isOn(rPr.Bold.Val)
+	}
+	if rPr.Italic != nil {
+		f.Italic = isOn(rPr.Italic.Val)
+	}
+	if rPr.Size != nil {
+		if n, err := strconv.ParseFloat(rPr.Size.Val, 64); err == nil {
+			f.Size = n / 2
+		}
+	}
+	if rPr.Color != nil && rPr.Color.Val != "" {
+		f.Color = rPr.Color.Val
+	}
+}
+
+// isOn reports whether an on/off value is on: when empty too, as for <w:b/>
+func isOn(val string) bool {
+	return val != "false" && val != "0" && val != "off"
+}
+
+// normalizeAlignment names justification by the values of the first edition
+// of the standard, which start and end replaced
+func normalizeAlignment(jc string) string {
+	switch jc {
+	case "start":
+		return "left"
+	case "end":
+		return "right"
+	}
+	return jc
+}
+
+// ResolveEffectiveFormat returns the formatting the paragraph at index is
+// shown with: that of its style, following the styles it is based on back
+// to the document defaults, and its own alignment. Runs may override it;
+// see ResolveRunFormat.
+func (d *Document) ResolveEffectiveFormat(index int) (EffectiveFormat, error) {
+	if index < 0 || index >= len(d.Body.Paragraphs) {
+		return EffectiveFormat{}, fmt.Errorf("paragraph index %d %w", index, ErrIndexOutOfRange)
+	}
+	return d.paragraphFormat(&d.Body.Paragraphs[index]), nil
+}
+
+// ResolveRunFormat returns the formatting a run of the paragraph at index is
+// shown with: the paragraph's, changed by the run's own properties
+func (d *Document) ResolveRunFormat(index, run int) (EffectiveFormat, error) {
+	if index < 0 || index >= len(d.Body.Paragraphs) {
+		return EffectiveFormat{}, fmt.Errorf("paragraph index %d %w", index, ErrIndexOutOfRange)
+	}
+	p := &d.Body.Paragraphs[index]
+	if run < 0 || run >= len(p.Runs) {
+		return EffectiveFormat{}, fmt.Errorf("run index %d %w", run, ErrIndexOutOfRange)
+	}
+	f := d.paragraphFormat(p)
+	if props := p.Runs[run].Props; props != nil {
+		if props.RFonts != nil && props.RFonts.ASCII != "" {
+			f.Font = props.RFonts.ASCII
+		}
+		if props.Bold != nil {
+			f.Bold = true
+		}
+		if props.Italic != nil {
+			f.Italic = true
+		}
+		if props.Size != nil {
+			if n, err := strconv.ParseFloat(props.Size.Val, 64); err == nil {
+				f.Size = n / 2
+			}
+		}
+		if props.Color != nil && props.Color.Val != "" {
+			f.Color = props.Color.Val
+		}
+	}
+	return f, nil
+}
+
+func (d *Document) paragraphFormat(p *Paragraph) EffectiveFormat {
+	r := d.styleResolver()
+	styleID := r.defaultPStyle
+	if p.Props != nil && p.Props.Style != nil && p.Props.Style.Val != "" {
+		styleID = p.Props.Style.Val
+	}
+	f := r.paragraphFormat(styleID)
+	if p.Props != nil && p.Props.Jc != nil {
+		f.Alignment = normalizeAlignment(p.Props.Jc.Val)
+	}
+	return f
+}
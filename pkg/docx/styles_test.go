@@ -0,0 +1,76 @@
+package docx
+
+import (
+	"errors"
+	"testing"
+)
+
+const formatTestStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:asciiTheme="minorHAnsi" w:hAnsiTheme="minorHAnsi"/><w:sz w:val="22"/></w:rPr></w:rPrDefault></w:docDefaults>
+<w:style w:type="character" w:styleId="Strong"><w:name w:val="Strong"/><w:rPr><w:b/></w:rPr></w:style>
+<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:pPr><w:jc w:val="start"/></w:pPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading1"><w:basedOn w:val="Normal"/><w:rPr><w:rFonts w:asciiTheme="majorHAnsi"/><w:b/><w:color w:val="2F5496"/><w:sz w:val="32"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="Heading2"><w:basedOn w:val="Heading1"/><w:pPr><w:jc w:val="center"/></w:pPr><w:rPr><w:b w:val="0"/><w:i/><w:sz w:val="26"/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="LoopA"><w:basedOn w:val="LoopB"/><w:rPr><w:i/></w:rPr></w:style>
+<w:style w:type="paragraph" w:styleId="LoopB"><w:basedOn w:val="LoopA"/><w:rPr><w:rFonts w:ascii="Consolas"/></w:rPr></w:style>
+</w:styles>`
+
+const formatTestTheme = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<a:theme xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><a:themeElements><a:fontScheme name="Office">
+<a:majorFont><a:latin typeface="Calibri Light"/><a:ea typeface=""/></a:majorFont>
+<a:minorFont><a:latin typeface="Calibri"/><a:ea typeface=""/></a:minorFont>
+</a:fontScheme></a:themeElements></a:theme>`
+
+func TestResolveEffectiveFormat(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Plain")
+	if f, err := doc.ResolveEffectiveFormat(0); err != nil || f.Size != 10 || f.Font != "Times New Roman" || f.Alignment != "left" || f.Bold {
+		t.Errorf("Expected Word's formatting without styles, got %+v (%v)", f, err)
+	}
+
+	doc.SetPart(stylesPart, []byte(formatTestStyles))
+	doc.SetPart("word/theme/theme1.xml", []byte(formatTestTheme))
+	doc.AddParagraph("Title", WithStyle("Heading1"))
+	doc.AddParagraph("Subtitle", WithStyle("Heading2"), WithAlignment("right"))
+	doc.AddParagraph("Loop", WithStyle("LoopA"))
+	doc.AddParagraph("Unknown", WithStyle("Missing"))
+
+	tests := []struct {
+		index int
+		want  EffectiveFormat
+	}{
+		{0, EffectiveFormat{Style: "Normal", Size: 11, Font: "Calibri", Color: "auto", Alignment: "left"}},
+		{1, EffectiveFormat{Style: "Heading1", Bold: true, Size: 16, Font: "Calibri Light", Color: "2F5496", Alignment: "left"}},
+		{2, EffectiveFormat{Style: "Heading2", Italic: true, Size: 13, Font: "Calibri Light", Color: "2F5496", Alignment: "right"}},
+		{3, EffectiveFormat{Style: "LoopA", Italic: true, Size: 11, Font: "Consolas", Color: "auto", Alignment: "left"}},
+		{4, EffectiveFormat{Style: "Missing", Size: 11, Font: "Calibri", Color: "auto", Alignment: "left"}},
+	}
+	for _, tt := range tests {
+		got, err := doc.ResolveEffectiveFormat(tt.index)
+		if err != nil {
+			t.Fatalf("ResolveEffectiveFormat(%d) failed: %v", tt.index, err)
+		}
+		if got != tt.want {
+			t.Errorf("Paragraph %d: expected %+v, got %+v", tt.index, tt.want, got)
+		}
+	}
+
+	doc.AddParagraph("Emphasis", WithStyle("Heading2"), WithBold(), WithSize("40"), WithFont("Georgia"))
+	if f, err := doc.ResolveRunFormat(5, 0); err != nil || !f.Bold || !f.Italic || f.Size != 20 || f.Font != "Georgia" {
+		t.Errorf("Expected the run's own formatting on top of the style, got %+v (%v)", f, err)
+	}
+
+	// Changed styles are picked up
+	doc.SetPart(stylesPart, []byte(`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"/>`))
+	if f, _ := doc.ResolveEffectiveFormat(1); f.Bold || f.Size != 10 {
+		t.Errorf("Expected the new styles to be used, got %+v", f)
+	}
+
+	if _, err := doc.ResolveEffectiveFormat(6); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+	if _, err := doc.ResolveRunFormat(0, 3); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange for a run, got %v", err)
+	}
+}
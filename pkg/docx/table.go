@@ -1,16 +1,22 @@
 package docx
 
 import (
+	"cmp"
 	"encoding/xml"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // Table represents a table in the document
 type Table struct {
-	XMLName xml.Name `xml:"tbl"`
-	Props   *TblPr   `xml:"tblPr,omitempty"`
-	Grid    *TblGrid `xml:"tblGrid,omitempty"`
-	Rows    []TblRow `xml:"tr"`
+	XMLName  xml.Name `xml:"tbl"`
+	Position int      `xml:"-"` // Number of paragraphs of the body or cell before the table
+	Props    *TblPr   `xml:"tblPr,omitempty"`
+	Grid     *TblGrid `xml:"tblGrid,omitempty"`
+	Rows     []TblRow `xml:"tr"`
 }
 
 // TblPr represents table properties
@@ -57,19 +63,50 @@ type TrPr struct {
 	XMLName xml.Name `xml:"trPr"`
 }
 
-// TblCell represents a table cell
+// TblCell represents a table cell. Nested tables and content controls are
+// kept apart from the paragraphs, with their place among them.
 type TblCell struct {
 	XMLName xml.Name    `xml:"tc"`
 	Props   *TcPr       `xml:"tcPr,omitempty"`
 	Content []Paragraph `xml:"p"`
+	Tables  []Table     `xml:"tbl"` // Nested tables
+	SDTs    []SDT       `xml:"sdt"`
 }
 
 // TcPr represents cell properties
 type TcPr struct {
-	XMLName xml.Name  `xml:"tcPr"`
-	Width   *TblWidth `xml:"tcW,omitempty"`
+	XMLName       xml.Name       `xml:"tcPr"`
+	Width         *TcWidth       `xml:"tcW,omitempty"`
+	TextDirection *TextDirection `xml:"textDirection,omitempty"`
+	VAlign        *VAlign        `xml:"vAlign,omitempty"`
 }
 
+// TcWidth represents cell width
+type TcWidth struct {
+	XMLName xml.Name `xml:"tcW"`
+	Type    string   `xml:"type,attr"`
+	W       string   `xml:"w,attr"`
+}
+
+// TextDirection represents the direction of text flow in a cell
+type TextDirection struct {
+	XMLName xml.Name `xml:"textDirection"`
+	Val     string   `xml:"val,attr"`
+}
+
+// VAlign represents the vertical alignment of a cell's content
+type VAlign struct {
+	XMLName xml.Name `xml:"vAlign"`
+	Val     string   `xml:"val,attr"`
+}
+
+// Text directions accepted by TblCell.SetTextDirection
+const (
+	TextDirectionHorizontal = "lrTb" // left to right, top to bottom
+	TextDirectionDown       = "tbRl" // rotated 90°, top to bottom
+	TextDirectionUp         = "btLr" // rotated 270°, bottom to top
+)
+
 // AddTable adds a new table to the document
 func (d *Document) AddTable(rows, cols int) *Table {
 	table := Table{
@@ -107,17 +144,19 @@ func (d *Document) AddTable(rows, cols int) *Table {
 		}
 	}
 
+	table.Position = len(d.Body.Paragraphs)
 	d.Body.Tables = append(d.Body.Tables, table)
 	return &d.Body.Tables[len(d.Body.Tables)-1]
 }
 
-// SetCellText sets the text content of a cell
-func (t *Table) SetCellText(row, col int, text string) error {
+// SetCellText sets the text content of a cell. Options format the cell's
+// first paragraph the same way they format paragraphs added to the body.
+func (t *Table) SetCellText(row, col int, text string, opts ...ParagraphOption) error {
 	if row < 0 || row >= len(t.Rows) {
-		return fmt.Errorf("row index %d out of range", row)
+		return fmt.Errorf("row index %d %w", row, ErrIndexOutOfRange)
 	}
 	if col < 0 || col >= len(t.Rows[row].Cells) {
-		return fmt.Errorf("column index %d out of range", col)
+		return fmt.Errorf("column index %d %w", col, ErrIndexOutOfRange)
 	}
 
 	cell := &t.Rows[row].Cells[col]
@@ -135,29 +174,65 @@ func (t *Table) SetCellText(row, col int, text string) error {
 		},
 	}
 
+	for _, opt := range opts {
+		opt(&cell.Content[0])
+	}
+
 	return nil
 }
 
-// GetCellText gets the text content of a cell
-func (t *Table) GetCellText(row, col int) (string, error) {
+// Cell returns the cell at row and col so its properties can be changed
+func (t *Table) Cell(row, col int) (*TblCell, error) {
 	if row < 0 || row >= len(t.Rows) {
-		return "", fmt.Errorf("row index %d out of range", row)
+		return nil, fmt.Errorf("row index %d %w", row, ErrIndexOutOfRange)
 	}
 	if col < 0 || col >= len(t.Rows[row].Cells) {
-		return "", fmt.Errorf("column index %d out of range", col)
+		return nil, fmt.Errorf("column index %d %w", col, ErrIndexOutOfRange)
 	}
+	return &t.Rows[row].Cells[col], nil
+}
 
-	cell := t.Rows[row].Cells[col]
-	var text string
-	for _, p := range cell.Content {
-		for _, r := range p.Runs {
-			for _, t := range r.Text {
-				text += t.Content
-			}
-		}
+// SetVerticalAlignment aligns the content of the cell to its "top", "center"
+// or "bottom" edge
+func (c *TblCell) SetVerticalAlignment(align string) error {
+	switch align {
+	case "top", "center", "bottom":
+	default:
+		return fmt.Errorf("invalid vertical alignment %q", align)
 	}
+	if c.Props == nil {
+		c.Props = &TcPr{}
+	}
+	c.Props.VAlign = &VAlign{Val: align}
+	return nil
+}
 
-	return text, nil
+// SetTextDirection sets the direction text flows in the cell, one of
+// TextDirectionHorizontal, TextDirectionDown or TextDirectionUp
+func (c *TblCell) SetTextDirection(dir string) error {
+	switch dir {
+	case TextDirectionHorizontal, TextDirectionDown, TextDirectionUp:
+	default:
+		return fmt.Errorf("invalid text direction %q", dir)
+	}
+	if c.Props == nil {
+		c.Props = &TcPr{}
+	}
+	c.Props.TextDirection = &TextDirection{Val: dir}
+	return nil
+}
+
+// GetCellText gets the text content of a cell, including links, field
+// results and nested tables
+func (t *Table) GetCellText(row, col int) (string, error) {
+	if row < 0 || row >= len(t.Rows) {
+		return "", fmt.Errorf("row index %d %w", row, ErrIndexOutOfRange)
+	}
+	if col < 0 || col >= len(t.Rows[row].Cells) {
+		return "", fmt.Errorf("column index %d %w", col, ErrIndexOutOfRange)
+	}
+
+	return cellText(&t.Rows[row].Cells[col]), nil
 }
 
 // AddRow adds a new row to the table
@@ -166,13 +241,42 @@ func (t *Table) AddRow() {
 		return
 	}
 
-	cols := len(t.Rows[0].Cells)
-	newRow := TblRow{
+	t.Rows = append(t.Rows, newRow(len(t.Rows[0].Cells)))
+}
+
+// InsertRowAt inserts an empty row before the row at index; an index equal
+// to the row count appends it. The row has as many cells as the first row.
+func (t *Table) InsertRowAt(index int) error {
+	if index < 0 || index > len(t.Rows) {
+		return fmt.Errorf("row index %d %w", index, ErrIndexOutOfRange)
+	}
+	if len(t.Rows) == 0 {
+		return nil
+	}
+
+	t.Rows = slices.Insert(t.Rows, index, newRow(len(t.Rows[0].Cells)))
+	return nil
+}
+
+// DuplicateRow inserts a copy of the row at index right after it, with the
+// row and cell properties and the formatted content of the original
+func (t *Table) DuplicateRow(index int) error {
+	if index < 0 || index >= len(t.Rows) {
+		return fmt.Errorf("row index %d %w", index, ErrIndexOutOfRange)
+	}
+
+	t.Rows = slices.Insert(t.Rows, index+1, deepCopy(t.Rows[index]))
+	return nil
+}
+
+// newRow returns a row of cols cells, each holding an empty paragraph
+func newRow(cols int) TblRow {
+	row := TblRow{
 		Cells: make([]TblCell, cols),
 	}
 
 	for i := 0; i < cols; i++ {
-		newRow.Cells[i] = TblCell{
+		row.Cells[i] = TblCell{
 			Content: []Paragraph{
 				{
 					Runs: []Run{
@@ -187,13 +291,13 @@ func (t *Table) AddRow() {
 		}
 	}
 
-	t.Rows = append(t.Rows, newRow)
+	return row
 }
 
 // DeleteRow deletes a row from the table
 func (t *Table) DeleteRow(index int) error {
 	if index < 0 || index >= len(t.Rows) {
-		return fmt.Errorf("row index %d out of range", index)
+		return fmt.Errorf("row index %d %w", index, ErrIndexOutOfRange)
 	}
 
 	t.Rows = append(t.Rows[:index], t.Rows[index+1:]...)
@@ -212,3 +316,118 @@ func (t *Table) GetColumnCount() int {
 	}
 	return len(t.Rows[0].Cells)
 }
+
+// SortRows reorders the rows of the table by the text of column col.
+// With numeric set, cells are compared as numbers and cells that don't
+// parse as one sort after those that do, in either direction. The sort is
+// stable, so rows with equal keys keep their order. skipHeader leaves the
+// first row in place.
+func (t *Table) SortRows(col int, numeric, desc, skipHeader bool) error {
+	if col < 0 || col >= t.GetColumnCount() {
+		return fmt.Errorf("column index %d %w", col, ErrIndexOutOfRange)
+	}
+
+	rows := t.Rows
+	if skipHeader && len(rows) > 0 {
+		rows = rows[1:]
+	}
+
+	// Each row's key is worked out once, not on every comparison
+	type sortKey struct {
+		row      TblRow
+		text     string
+		number   float64
+		isNumber bool
+	}
+	keys := make([]sortKey, len(rows))
+	for i, r := range rows {
+		keys[i].row = r
+		if col < len(r.Cells) {
+			keys[i].text = strings.ToLower(cellText(&r.Cells[col]))
+		}
+		if numeric {
+			keys[i].number, keys[i].isNumber = cellNumber(keys[i].text)
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, b := &keys[i], &keys[j]
+		c := strings.Compare(a.text, b.text)
+		if numeric {
+			if a.isNumber != b.isNumber {
+				return a.isNumber // text that isn't a number goes last
+			}
+			if a.isNumber {
+				c = cmp.Compare(a.number, b.number)
+			}
+		}
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+	for i := range keys {
+		rows[i] = keys[i].row
+	}
+	return nil
+}
+
+// FilterRows removes the rows for which keep returns false and reports how
+// many were removed. keep gets the index of the row and the text of its
+// cells; returning true for row 0 keeps a header row.
+func (t *Table) FilterRows(keep func(row int, cells []string) bool) int {
+	kept := t.Rows[:0]
+	for i, r := range t.Rows {
+		cells := make([]string, len(r.Cells))
+		for j := range r.Cells {
+			cells[j] = cellText(&r.Cells[j])
+		}
+		if keep(i, cells) {
+			kept = append(kept, r)
+		}
+	}
+	removed := len(t.Rows) - len(kept)
+	t.Rows = kept
+	return removed
+}
+
+// cellText returns the text of a cell: every run of its paragraphs, links
+// and field results included, and of the tables and content controls
+// among them, in document order.
+func cellText(cell *TblCell) string {
+	var sb strings.Builder
+	writeBlockText(&sb, cell.Content, cell.Tables, cell.SDTs)
+	return sb.String()
+}
+
+// writeBlockText writes the text of paragraphs, tables and content
+// controls to sb in document order
+func writeBlockText(sb *strings.Builder, paragraphs []Paragraph, tables []Table, sdts []SDT) {
+	for _, block := range blockContent(paragraphs, tables, sdts) {
+		switch b := block.(type) {
+		case *Paragraph:
+			b.forEachRun(func(r *Run) {
+				for _, t := range r.Text {
+					sb.WriteString(t.Content)
+				}
+			})
+		case *Table:
+			for i := range b.Rows {
+				for j := range b.Rows[i].Cells {
+					c := &b.Rows[i].Cells[j]
+					writeBlockText(sb, c.Content, c.Tables, c.SDTs)
+				}
+			}
+		case *SDT:
+			if b.Content != nil {
+				writeBlockText(sb, b.Content.Paragraphs, b.Content.Tables, b.Content.SDTs)
+			}
+		}
+	}
+}
+
+// cellNumber parses cell text as a number, ignoring thousands separators
+// and surrounding spaces.
+func cellNumber(text string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(text), ",", ""), 64)
+	return f, err == nil
+}
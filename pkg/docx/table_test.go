@@ -1,6 +1,9 @@
 package docx
 
 import (
+	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -201,3 +204,255 @@ func TestEmptyTableOperations(t *testing.T) {
 		t.Error("AddRow on empty table should not add rows")
 	}
 }
+
+func TestSortRows(t *testing.T) {
+	doc := New()
+	table := doc.AddTable(5, 2)
+	for i, v := range []string{"Amount", "1,200", "n/a", "35", "9.5"} {
+		table.SetCellText(i, 0, v)
+		table.SetCellText(i, 1, fmt.Sprintf("row %d", i))
+	}
+
+	column := func() []string {
+		var got []string
+		for i := range table.Rows {
+			text, _ := table.GetCellText(i, 0)
+			got = append(got, text)
+		}
+		return got
+	}
+
+	if err := table.SortRows(0, true, false, true); err != nil {
+		t.Fatalf("SortRows failed: %v", err)
+	}
+	if got := strings.Join(column(), "|"); got != "Amount|9.5|35|1,200|n/a" {
+		t.Errorf("ascending numeric sort = %s", got)
+	}
+	if text, _ := table.GetCellText(1, 1); text != "row 4" {
+		t.Errorf("cells should move with their row, got %q", text)
+	}
+
+	if err := table.SortRows(0, true, true, true); err != nil {
+		t.Fatalf("SortRows failed: %v", err)
+	}
+	if got := strings.Join(column(), "|"); got != "Amount|1,200|35|9.5|n/a" {
+		t.Errorf("descending numeric sort = %s", got)
+	}
+
+	if err := table.SortRows(0, false, false, false); err != nil {
+		t.Fatalf("SortRows failed: %v", err)
+	}
+	if got := strings.Join(column(), "|"); got != "1,200|35|9.5|Amount|n/a" {
+		t.Errorf("text sort = %s", got)
+	}
+
+	if err := table.SortRows(2, false, false, false); err == nil {
+		t.Error("Expected error for out of range column, got nil")
+	}
+}
+
+func TestCellTextLinksAndNestedTables(t *testing.T) {
+	doc := New()
+	table := doc.AddTable(4, 1)
+	table.SetCellText(0, 0, "Amount")
+	table.SetCellText(1, 0, "10")
+
+	// A cell holding only a link, and one holding only a nested table
+	linked := &table.Rows[2].Cells[0]
+	linked.Content = []Paragraph{{Hyperlinks: []Hyperlink{{Anchor: "total", Runs: []Run{{Text: []Text{{Content: "2"}}}}}}}}
+	nested := &table.Rows[3].Cells[0]
+	nested.Content = []Paragraph{{}}
+	nested.Tables = []Table{{Rows: []TblRow{{Cells: []TblCell{{Content: []Paragraph{{Runs: []Run{{Text: []Text{{Content: "5"}}}}}}}}}}}}
+
+	if text, _ := table.GetCellText(2, 0); text != "2" {
+		t.Errorf("Expected the link text, got %q", text)
+	}
+	if text, _ := table.GetCellText(3, 0); text != "5" {
+		t.Errorf("Expected the nested table text, got %q", text)
+	}
+
+	if err := table.SortRows(0, true, false, true); err != nil {
+		t.Fatalf("SortRows failed: %v", err)
+	}
+	var got []string
+	for i := range table.Rows {
+		text, _ := table.GetCellText(i, 0)
+		got = append(got, text)
+	}
+	if strings.Join(got, "|") != "Amount|2|5|10" {
+		t.Errorf("Expected links and nested tables to sort by their text, got %q", got)
+	}
+
+	var seen []string
+	table.FilterRows(func(row int, cells []string) bool {
+		seen = append(seen, cells[0])
+		return true
+	})
+	if strings.Join(seen, "|") != "Amount|2|5|10" {
+		t.Errorf("Expected FilterRows to see the same text, got %q", seen)
+	}
+}
+
+func TestFilterRows(t *testing.T) {
+	doc := New()
+	table := doc.AddTable(4, 2)
+	for i, v := range []string{"Status", "open", "closed", "open"} {
+		table.SetCellText(i, 0, v)
+	}
+
+	removed := table.FilterRows(func(row int, cells []string) bool {
+		return row == 0 || cells[0] == "open"
+	})
+	if removed != 1 {
+		t.Errorf("Expected 1 row removed, got %d", removed)
+	}
+	if table.GetRowCount() != 3 {
+		t.Fatalf("Expected 3 rows, got %d", table.GetRowCount())
+	}
+	for i, want := range []string{"Status", "open", "open"} {
+		if text, _ := table.GetCellText(i, 0); text != want {
+			t.Errorf("row %d: expected %q, got %q", i, want, text)
+		}
+	}
+}
+
+func TestCellFormatting(t *testing.T) {
+	doc := New()
+	table := doc.AddTable(2, 2)
+
+	if err := table.SetCellText(0, 0, "Total", WithBold(), WithAlignment("right")); err != nil {
+		t.Fatalf("Error setting cell text: %v", err)
+	}
+	cell, err := table.Cell(0, 0)
+	if err != nil {
+		t.Fatalf("Cell failed: %v", err)
+	}
+	if err := cell.SetVerticalAlignment("center"); err != nil {
+		t.Fatalf("SetVerticalAlignment failed: %v", err)
+	}
+	if err := cell.SetTextDirection(TextDirectionUp); err != nil {
+		t.Fatalf("SetTextDirection failed: %v", err)
+	}
+	if err := cell.SetVerticalAlignment("middle"); err == nil {
+		t.Error("Expected error for invalid vertical alignment, got nil")
+	}
+	if err := cell.SetTextDirection("sideways"); err == nil {
+		t.Error("Expected error for invalid text direction, got nil")
+	}
+	if _, err := table.Cell(2, 0); err == nil {
+		t.Error("Expected error for out of range row, got nil")
+	}
+
+	path := filepath.Join(t.TempDir(), "cells.docx")
+	if err := doc.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	got := reopened.Body.Tables[0].Rows[0].Cells[0]
+	if got.Props == nil || got.Props.VAlign == nil || got.Props.VAlign.Val != "center" {
+		t.Errorf("vertical alignment not kept: %+v", got.Props)
+	}
+	if got.Props == nil || got.Props.TextDirection == nil || got.Props.TextDirection.Val != "btLr" {
+		t.Errorf("text direction not kept: %+v", got.Props)
+	}
+	p := got.Content[0]
+	if p.Props == nil || p.Props.Jc == nil || p.Props.Jc.Val != "right" {
+		t.Error("cell paragraph alignment not kept")
+	}
+	if p.Runs[0].Props == nil || p.Runs[0].Props.Bold == nil {
+		t.Error("cell text should be bold")
+	}
+}
+
+func TestInsertRowAt(t *testing.T) {
+	doc := New()
+	table := doc.AddTable(2, 3)
+	table.SetCellText(0, 0, "Row 0")
+	table.SetCellText(1, 0, "Row 1")
+
+	if err := table.InsertRowAt(1); err != nil {
+		t.Fatalf("InsertRowAt failed: %v", err)
+	}
+	if table.GetRowCount() != 3 {
+		t.Fatalf("Expected 3 rows, got %d", table.GetRowCount())
+	}
+	if len(table.Rows[1].Cells) != 3 {
+		t.Errorf("Inserted row should have 3 columns, got %d", len(table.Rows[1].Cells))
+	}
+	for i, want := range []string{"Row 0", "", "Row 1"} {
+		if text, _ := table.GetCellText(i, 0); text != want {
+			t.Errorf("row %d: expected %q, got %q", i, want, text)
+		}
+	}
+
+	if err := table.InsertRowAt(3); err != nil {
+		t.Errorf("InsertRowAt at the end failed: %v", err)
+	}
+	if err := table.InsertRowAt(5); err == nil {
+		t.Error("Expected error for out of range row, got nil")
+	}
+}
+
+func TestDuplicateRow(t *testing.T) {
+	doc := New()
+	table := doc.AddTable(2, 2)
+	table.SetCellText(1, 0, "Item", WithBold())
+	cell, _ := table.Cell(1, 0)
+	cell.SetVerticalAlignment("bottom")
+
+	if err := table.DuplicateRow(1); err != nil {
+		t.Fatalf("DuplicateRow failed: %v", err)
+	}
+	if table.GetRowCount() != 3 {
+		t.Fatalf("Expected 3 rows, got %d", table.GetRowCount())
+	}
+
+	copied := &table.Rows[2].Cells[0]
+	if copied.Props == nil || copied.Props.VAlign == nil || copied.Props.VAlign.Val != "bottom" {
+		t.Error("cell properties should be copied")
+	}
+	if copied.Content[0].Runs[0].Props == nil || copied.Content[0].Runs[0].Props.Bold == nil {
+		t.Error("run formatting should be copied")
+	}
+
+	table.SetCellText(2, 0, "Other")
+	if text, _ := table.GetCellText(1, 0); text != "Item" {
+		t.Errorf("changing the copy changed the original: %q", text)
+	}
+
+	if err := table.DuplicateRow(3); err == nil {
+		t.Error("Expected error for out of range row, got nil")
+	}
+}
+
+func TestNestedTableOrder(t *testing.T) {
+	doc := New()
+	err := doc.parseDocument([]byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:body><w:tbl><w:tr><w:tc><w:p><w:r><w:t>Before</w:t></w:r></w:p><w:tbl><w:tr><w:tc><w:p><w:r><w:t>Inner</w:t></w:r></w:p></w:tc></w:tr></w:tbl><w:p><w:r><w:t>After</w:t></w:r></w:p></w:tc></w:tr></w:tbl><w:p/></w:body>
+</w:document>`))
+	if err != nil {
+		t.Fatalf("parseDocument failed: %v", err)
+	}
+
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	reopened, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+	cell := reopened.Body.Tables[0].Rows[0].Cells[0]
+	if len(cell.Content) != 2 || len(cell.Tables) != 1 || cell.Tables[0].Position != 1 {
+		t.Fatalf("Expected the nested table between the paragraphs, got %+v", cell)
+	}
+	part, _ := reopened.GetPart(documentPart)
+	body := string(part)
+	if i, j, k := strings.Index(body, "Before"), strings.Index(body, "Inner"), strings.Index(body, "After"); i > j || j > k {
+		t.Errorf("Expected the nested table between the paragraphs, got %s", body)
+	}
+}
@@ -0,0 +1,143 @@
+package docx
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// markerPattern matches a paragraph marking the start of a tagged section,
+// <!-- internal -->, or its end, <!-- /internal --> or <!-- end internal -->
+var markerPattern = regexp.MustCompile(`^<!--\s*(/|end\s+)?([\w.-]+)\s*-->$`)
+
+// TagSection marks the paragraphs from start to end (inclusive) as a
+// section tagged tag, with a hidden bookmark named _tag (or _tag_2 and so
+// on when the tag is used more than once), for RemoveTaggedSections
+func (d *Document) TagSection(start, end int, tag string) error {
+	if start < 0 || end >= len(d.Body.Paragraphs) || start > end {
+		return fmt.Errorf("invalid range [%d:%d]", start, end)
+	}
+	if !markerPattern.MatchString("<!-- " + tag + " -->") {
+		return fmt.Errorf("invalid tag %q: use letters, digits, dots, dashes and underscores", tag)
+	}
+
+	names := make(map[string]bool)
+	nextID := 0
+	for _, p := range d.Body.Paragraphs {
+		for _, b := range p.BookmarkStarts {
+			names[b.Name] = true
+			if id, err := strconv.Atoi(b.ID); err == nil && id >= nextID {
+				nextID = id + 1
+			}
+		}
+	}
+	name := "_" + tag
+	for n := 2; names[name]; n++ {
+		name = fmt.Sprintf("_%s_%d", tag, n)
+	}
+
+	id := strconv.Itoa(nextID)
+	first, last := &d.Body.Paragraphs[start], &d.Body.Paragraphs[end]
+	first.BookmarkStarts = append(first.BookmarkStarts, BookmarkStart{ID: id, Name: name})
+	last.BookmarkEnds = append(last.BookmarkEnds, BookmarkEnd{Position: len(last.Runs), ID: id})
+	return nil
+}
+
+// RemoveTaggedSections removes the sections tagged with any of tags, so one
+// master document can give several variants, such as an internal and a
+// client version. A section is either the paragraphs a bookmark spans,
+// named for its tag as TagSection does (_internal, _internal_2, or just
+// internal), or the paragraphs between marker paragraphs reading
+// <!-- internal --> and <!-- /internal -->. Whole paragraphs are removed,
+// with the tables and content controls between them. Marker paragraphs are
+// removed for every tag, so none show in the variant. Tags are compared
+// ignoring case. It returns the number of sections removed.
+func (d *Document) RemoveTaggedSections(tags []string) (int, error) {
+	removeTag := func(tag string) bool {
+		return slices.ContainsFunc(tags, func(t string) bool { return strings.EqualFold(t, tag) })
+	}
+
+	type span struct{ start, end int }
+	var sections, markers []span
+	count := 0
+
+	// Bookmarked sections
+	starts := make(map[string]int) // Paragraph of the start of each bookmark to remove, by ID
+	for i, p := range d.Body.Paragraphs {
+		for _, b := range p.BookmarkStarts {
+			if removeTag(bookmarkTag(b.Name)) {
+				starts[b.ID] = i
+			}
+		}
+		for _, b := range p.BookmarkEnds {
+			if start, ok := starts[b.ID]; ok {
+				sections = append(sections, span{start, i})
+				delete(starts, b.ID)
+				count++
+			}
+		}
+	}
+
+	// Marked sections
+	open := make(map[string][]int) // Paragraphs of the open markers, by lower-cased tag
+	for i := range d.Body.Paragraphs {
+		m := markerPattern.FindStringSubmatch(strings.TrimSpace(d.paragraphOwnText(&d.Body.Paragraphs[i])))
+		if m == nil {
+			continue
+		}
+		markers = append(markers, span{i, i})
+		tag := strings.ToLower(m[2])
+		if m[1] == "" {
+			open[tag] = append(open[tag], i)
+			continue
+		}
+		stack := open[tag]
+		if len(stack) == 0 {
+			return 0, fmt.Errorf("paragraph %d: end of section %q without a start", i, m[2])
+		}
+		start := stack[len(stack)-1]
+		open[tag] = stack[:len(stack)-1]
+		if removeTag(tag) {
+			sections = append(sections, span{start, i})
+			count++
+		}
+	}
+	for tag, stack := range open {
+		if len(stack) > 0 {
+			return 0, fmt.Errorf("paragraph %d: section %q is not closed", stack[0], tag)
+		}
+	}
+
+	// Merged and removed from the last, so the indexes of the others stay valid
+	spans := append(sections, markers...)
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var merged []span
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.start <= merged[n-1].end {
+			merged[n-1].end = max(merged[n-1].end, s.end)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	for i := len(merged) - 1; i >= 0; i-- {
+		s := merged[i]
+		d.Body.removeBlocks(s.start+1, s.end)
+		d.Body.SpliceParagraphs(s.start, s.end-s.start+1)
+	}
+	return count, nil
+}
+
+// bookmarkTag returns the tag of a bookmark marking a tagged section:
+// _internal_2 and _internal are tagged internal
+func bookmarkTag(name string) string {
+	name = strings.TrimPrefix(name, "_")
+	if i := strings.LastIndex(name, "_"); i > 0 {
+		if _, err := strconv.Atoi(name[i+1:]); err == nil {
+			name = name[:i]
+		}
+	}
+	return name
+}
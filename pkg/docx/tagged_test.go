@@ -0,0 +1,75 @@
+package docx
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRemoveTaggedSections(t *testing.T) {
+	newDoc := func() *Document {
+		doc := New()
+		doc.AddParagraph("Offer")                 // 0
+		doc.AddParagraph("Our margin is 40%")     // 1
+		doc.AddParagraph("Cost breakdown")        // 2
+		doc.AddParagraph("Terms")                 // 3
+		doc.AddParagraph("<!-- client -->")       // 4
+		doc.AddParagraph("Thank you for asking")  // 5
+		doc.AddParagraph("<!-- /client -->")      // 6
+		doc.AddParagraph("<!-- Internal -->")     // 7
+		doc.AddParagraph("Approved by finance")   // 8
+		doc.AddParagraph("<!-- end internal -->") // 9
+		doc.AddParagraph("Signature")             // 10
+		doc.AddTable(2, 2).Position = 2
+		if err := doc.TagSection(1, 2, "internal"); err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	texts := func(doc *Document) []string {
+		var got []string
+		for i := range doc.Body.Paragraphs {
+			got = append(got, doc.paragraphOwnText(&doc.Body.Paragraphs[i]))
+		}
+		return got
+	}
+
+	doc := newDoc()
+	if err := doc.TagSection(3, 3, "internal"); err != nil {
+		t.Fatal(err)
+	}
+	if name := doc.Body.Paragraphs[3].BookmarkStarts[0].Name; name != "_internal_2" {
+		t.Errorf("Expected the second section bookmarked _internal_2, got %q", name)
+	}
+	count, err := doc.RemoveTaggedSections([]string{"INTERNAL"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 sections removed, got %d", count)
+	}
+	if want := []string{"Offer", "Thank you for asking", "Signature"}; !slices.Equal(texts(doc), want) {
+		t.Errorf("Expected %q, got %q", want, texts(doc))
+	}
+	if len(doc.Body.Tables) != 0 {
+		t.Errorf("Expected the table inside the section removed, got %d", len(doc.Body.Tables))
+	}
+
+	doc = newDoc()
+	if count, err := doc.RemoveTaggedSections([]string{"client"}); err != nil || count != 1 {
+		t.Errorf("Expected 1 section removed, got %d, %v", count, err)
+	}
+	want := []string{"Offer", "Our margin is 40%", "Cost breakdown", "Terms", "Approved by finance", "Signature"}
+	if !slices.Equal(texts(doc), want) {
+		t.Errorf("Expected %q, got %q", want, texts(doc))
+	}
+	if len(doc.Body.Tables) != 1 || doc.Body.Tables[0].Position != 2 {
+		t.Errorf("Expected the table kept at 2, got %+v", doc.Body.Tables)
+	}
+
+	doc = New()
+	doc.AddParagraph("<!-- draft -->")
+	doc.AddParagraph("Notes")
+	if _, err := doc.RemoveTaggedSections([]string{"draft"}); err == nil {
+		t.Error("Expected an error for a section that is not closed")
+	}
+}
@@ -0,0 +1,261 @@
+package docx
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// Anchor represents a floating drawing positioned relative to the page or text.
+// Positioning and wrapping settings are preserved as-is; only the graphic is modeled.
+type Anchor struct {
+	XMLName  xml.Name     `xml:"http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing anchor"`
+	Attrs    []xml.Attr   `xml:",any,attr"`
+	Settings []RawElement `xml:",any"`
+	Graphic  *Graphic     `xml:"http://schemas.openxmlformats.org/drawingml/2006/main graphic"`
+}
+
+// Wsp represents a WordprocessingML shape, such as a text box
+type Wsp struct {
+	XMLName xml.Name     `xml:"http://schemas.microsoft.com/office/word/2010/wordprocessingShape wsp"`
+	Attrs   []xml.Attr   `xml:",any,attr"`
+	Props   []RawElement `xml:",any"`
+	Txbx    *WspTxbx     `xml:"http://schemas.microsoft.com/office/word/2010/wordprocessingShape txbx"`
+	BodyPr  *RawElement  `xml:"http://schemas.microsoft.com/office/word/2010/wordprocessingShape bodyPr"`
+}
+
+// WspTxbx holds the text of a shape
+type WspTxbx struct {
+	XMLName xml.Name     `xml:"http://schemas.microsoft.com/office/word/2010/wordprocessingShape txbx"`
+	Attrs   []xml.Attr   `xml:",any,attr"`
+	Content *TxbxContent `xml:"http://schemas.openxmlformats.org/wordprocessingml/2006/main txbxContent"`
+}
+
+// TxbxContent is the content of a text box: regular paragraphs and tables
+type TxbxContent struct {
+	XMLName    xml.Name    `xml:"http://schemas.openxmlformats.org/wordprocessingml/2006/main txbxContent"`
+	Paragraphs []Paragraph `xml:"p"`
+	Tables     []Table     `xml:"tbl"`
+}
+
+// AlternateContent lets a run provide a modern drawing with a legacy VML fallback
+type AlternateContent struct {
+	XMLName  xml.Name    `xml:"http://schemas.openxmlformats.org/markup-compatibility/2006 AlternateContent"`
+	Choice   *MCChoice   `xml:"http://schemas.openxmlformats.org/markup-compatibility/2006 Choice"`
+	Fallback *MCFallback `xml:"http://schemas.openxmlformats.org/markup-compatibility/2006 Fallback"`
+}
+
+// MCChoice is the preferred representation of alternate content
+type MCChoice struct {
+	XMLName  xml.Name `xml:"http://schemas.openxmlformats.org/markup-compatibility/2006 Choice"`
+	Requires string   `xml:"Requires,attr"`
+	Drawing  *Drawing `xml:"http://schemas.openxmlformats.org/wordprocessingml/2006/main drawing"`
+}
+
+// MCFallback is the representation used by consumers that don't support the choice
+type MCFallback struct {
+	XMLName xml.Name `xml:"http://schemas.openxmlformats.org/markup-compatibility/2006 Fallback"`
+	Pict    *Pict    `xml:"http://schemas.openxmlformats.org/wordprocessingml/2006/main pict"`
+}
+
+// Pict represents a legacy VML picture, which may contain a text box
+type Pict struct {
+	XMLName xml.Name     `xml:"http://schemas.openxmlformats.org/wordprocessingml/2006/main pict"`
+	Attrs   []xml.Attr   `xml:",any,attr"`
+	Extra   []RawElement `xml:",any"`
+	Shape   *VShape      `xml:"urn:schemas-microsoft-com:vml shape"`
+	Rect    *VShape      `xml:"urn:schemas-microsoft-com:vml rect"`
+}
+
+// VShape represents a VML shape (v:shape or v:rect)
+type VShape struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr   `xml:",any,attr"`
+	Extra   []RawElement `xml:",any"`
+	Textbox *VTextbox    `xml:"urn:schemas-microsoft-com:vml textbox"`
+}
+
+// VTextbox holds the text of a VML shape
+type VTextbox struct {
+	XMLName xml.Name     `xml:"urn:schemas-microsoft-com:vml textbox"`
+	Attrs   []xml.Attr   `xml:",any,attr"`
+	Content *TxbxContent `xml:"http://schemas.openxmlformats.org/wordprocessingml/2006/main txbxContent"`
+}
+
+// RawElement preserves an element that is not modeled, including its children
+type RawElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   []byte     `xml:",innerxml"`
+}
+
+// MarshalXML writes the element back with its original attributes and content
+func (e RawElement) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	if e.XMLName.Local != "" {
+		start.Name = e.XMLName
+	}
+	start.Attr = nil
+	for _, attr := range e.Attrs {
+		// Namespace declarations are written verbatim; the encoder would otherwise
+		// treat "xmlns" as a namespace URL and invent a prefix for it
+		if attr.Name.Space == "xmlns" {
+			attr.Name = xml.Name{Local: "xmlns:" + attr.Name.Local}
+		} else if attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+			continue
+		}
+		start.Attr = append(start.Attr, attr)
+	}
+
+	return enc.EncodeElement(struct {
+		Inner []byte `xml:",innerxml"`
+	}{e.Inner}, start)
+}
+
+// graphicData returns the graphic data of an inline or floating drawing
+func (dr *Drawing) graphicData() *GraphicData {
+	if dr == nil {
+		return nil
+	}
+	if dr.Inline != nil && dr.Inline.Graphic != nil {
+		return dr.Inline.Graphic.GraphicData
+	}
+	if dr.Anchor != nil && dr.Anchor.Graphic != nil {
+		return dr.Anchor.Graphic.GraphicData
+	}
+	return nil
+}
+
+// textBox returns the text box content of a drawing, if any
+func (dr *Drawing) textBox() *TxbxContent {
+	data := dr.graphicData()
+	if data == nil || data.Wsp == nil || data.Wsp.Txbx == nil {
+		return nil
+	}
+	return data.Wsp.Txbx.Content
+}
+
+// textBoxes returns the text box contents of a VML picture
+func (p *Pict) textBoxes() []*TxbxContent {
+	var boxes []*TxbxContent
+	for _, shape := range []*VShape{p.Shape, p.Rect} {
+		if shape != nil && shape.Textbox != nil && shape.Textbox.Content != nil {
+			boxes = append(boxes, shape.Textbox.Content)
+		}
+	}
+	return boxes
+}
+
+// textBoxes returns the text boxes anchored in a run. The VML fallback of
+// alternate content duplicates the preferred drawing, so it is only included
+// when includeFallback is set or the choice holds no text box.
+func (r *Run) textBoxes(includeFallback bool) []*TxbxContent {
+	var boxes []*TxbxContent
+	if tb := r.Drawing.textBox(); tb != nil {
+		boxes = append(boxes, tb)
+	}
+	if r.Pict != nil {
+		boxes = append(boxes, r.Pict.textBoxes()...)
+	}
+
+	if ac := r.AlternateContent; ac != nil {
+		var choice *TxbxContent
+		if ac.Choice != nil {
+			choice = ac.Choice.Drawing.textBox()
+		}
+		if choice != nil {
+			boxes = append(boxes, choice)
+		}
+		if ac.Fallback != nil && ac.Fallback.Pict != nil && (includeFallback || choice == nil) {
+			boxes = append(boxes, ac.Fallback.Pict.textBoxes()...)
+		}
+	}
+	return boxes
+}
+
+// textBoxes returns the text boxes anchored in a paragraph
+func (p *Paragraph) textBoxes(includeFallback bool) []*TxbxContent {
+	var boxes []*TxbxContent
+	p.forEachRun(func(r *Run) {
+		boxes = append(boxes, r.textBoxes(includeFallback)...)
+	})
+	return boxes
+}
+
+// GetTextBoxText returns the text of every text box in the document body, one entry per text box
+func (d *Document) GetTextBoxText() []string {
+	var texts []string
+	for i := range d.Body.Paragraphs {
+		texts = append(texts, d.paragraphTextBoxText(&d.Body.Paragraphs[i])...)
+	}
+	return texts
+}
+
+// paragraphTextBoxText returns the text of the text boxes anchored in a paragraph.
+// Text boxes nested deeper than DefaultMaxDepth are ignored.
+func (d *Document) paragraphTextBoxText(p *Paragraph) []string {
+	var texts []string
+	for _, tb := range p.textBoxes(false) {
+		var parts []string
+		w := newWalker(DefaultMaxDepth)
+		w.paragraph = func(p *Paragraph, depth int) error {
+			if text := d.paragraphOwnText(p); text != "" {
+				parts = append(parts, text)
+			}
+			return nil
+		}
+		_ = w.walkBlocks(tb.Paragraphs, tb.Tables, nil, 1)
+
+		if text := strings.Join(parts, " "); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+// replaceInParagraph replaces text in the runs, links and text boxes of a paragraph
+// and returns the number of text elements changed
+func replaceInParagraph(p *Paragraph, oldText, newText string) int {
+	return replaceInRuns(p, oldText, newText) + replaceInTextBoxes(p, oldText, newText)
+}
+
+// replaceInTextBoxes replaces text in the text boxes anchored in a paragraph
+// and returns the number of text elements changed
+func replaceInTextBoxes(p *Paragraph, oldText, newText string) int {
+	count := 0
+
+	// Both the drawing and its VML fallback are updated so they stay in sync,
+	// but only the text box that is displayed counts towards the total
+	primary := make(map[*TxbxContent]bool)
+	for _, tb := range p.textBoxes(false) {
+		primary[tb] = true
+	}
+	for _, tb := range p.textBoxes(true) {
+		n := 0
+		w := newWalker(DefaultMaxDepth)
+		w.includeFallback = true
+		w.paragraph = func(p *Paragraph, depth int) error {
+			n += replaceInRuns(p, oldText, newText)
+			return nil
+		}
+		_ = w.walkBlocks(tb.Paragraphs, tb.Tables, nil, 1)
+
+		if primary[tb] {
+			count += n
+		}
+	}
+	return count
+}
+
+// replaceInRuns replaces text in the runs and links of a paragraph, not descending into text boxes
+func replaceInRuns(p *Paragraph, oldText, newText string) int {
+	count := 0
+	p.forEachRun(func(r *Run) {
+		for k := range r.Text {
+			text := &r.Text[k]
+			if strings.Contains(text.Content, oldText) {
+				text.Content = strings.ReplaceAll(text.Content, oldText, newText)
+				count++
+			}
+		}
+	})
+	return count
+}
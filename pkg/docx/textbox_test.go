@@ -0,0 +1,117 @@
+package docx
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testTextBoxDocumentXML mirrors how Word stores a floating text box: a DrawingML
+// shape with a VML fallback holding a copy of the same content
+const testTextBoxDocumentXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+  xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"
+  xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"
+  xmlns:wps="http://schemas.microsoft.com/office/word/2010/wordprocessingShape"
+  xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006"
+  xmlns:v="urn:schemas-microsoft-com:vml">
+<w:body>
+<w:p><w:r><w:t>Body text</w:t></w:r></w:p>
+<w:p><w:r><mc:AlternateContent>
+  <mc:Choice Requires="wps"><w:drawing>
+    <wp:anchor distT="0" distB="0" behindDoc="0" locked="0">
+      <wp:simplePos x="0" y="0"/>
+      <wp:positionH relativeFrom="column"><wp:posOffset>123</wp:posOffset></wp:positionH>
+      <wp:positionV relativeFrom="paragraph"><wp:posOffset>0</wp:posOffset></wp:positionV>
+      <wp:extent cx="100" cy="100"/>
+      <wp:wrapSquare wrapText="bothSides"/>
+      <wp:docPr id="1" name="Text Box 1"/>
+      <a:graphic><a:graphicData uri="http://schemas.microsoft.com/office/word/2010/wordprocessingShape">
+        <wps:wsp>
+          <wps:cNvSpPr txBox="1"/>
+          <wps:spPr><a:prstGeom prst="rect"/></wps:spPr>
+          <wps:txbx><w:txbxContent><w:p><w:r><w:t>Callout ACME</w:t></w:r></w:p></w:txbxContent></wps:txbx>
+          <wps:bodyPr rot="0"/>
+        </wps:wsp>
+      </a:graphicData></a:graphic>
+    </wp:anchor>
+  </w:drawing></mc:Choice>
+  <mc:Fallback><w:pict><v:shape id="Text Box 1" style="position:absolute">
+    <v:textbox><w:txbxContent><w:p><w:r><w:t>Callout ACME</w:t></w:r></w:p></w:txbxContent></v:textbox>
+  </v:shape></w:pict></mc:Fallback>
+</mc:AlternateContent></w:r></w:p>
+<w:p><w:r><w:pict><v:rect><v:textbox><w:txbxContent><w:p><w:r><w:t>Legacy frame</w:t></w:r></w:p></w:txbxContent></v:textbox></v:rect></w:pict></w:r></w:p>
+</w:body>
+</w:document>`
+
+func newTextBoxDocument(t *testing.T) *Document {
+	t.Helper()
+	doc := New()
+	if err := doc.parseDocument([]byte(testTextBoxDocumentXML)); err != nil {
+		t.Fatalf("parseDocument failed: %v", err)
+	}
+	return doc
+}
+
+func TestTextBoxText(t *testing.T) {
+	doc := newTextBoxDocument(t)
+
+	texts := doc.GetTextBoxText()
+	if len(texts) != 2 || texts[0] != "Callout ACME" || texts[1] != "Legacy frame" {
+		t.Errorf("Unexpected text box texts: %v", texts)
+	}
+
+	// The VML fallback must not duplicate the text
+	if got := strings.Count(doc.GetText(), "Callout"); got != 1 {
+		t.Errorf("Expected text box text once in GetText, found %d times in %q", got, doc.GetText())
+	}
+
+	if indices := doc.FindText("acme"); len(indices) != 1 || indices[0] != 1 {
+		t.Errorf("Expected text box paragraph 1 to match, got %v", indices)
+	}
+	if text, _ := doc.GetParagraphText(2); text != "Legacy frame" {
+		t.Errorf("Unexpected paragraph text %q", text)
+	}
+}
+
+func TestTextBoxReplaceText(t *testing.T) {
+	doc := newTextBoxDocument(t)
+
+	if count := doc.ReplaceText("ACME", "Globex"); count != 1 {
+		t.Errorf("Expected 1 replacement, got %d", count)
+	}
+
+	ac := doc.Body.Paragraphs[1].Runs[0].AlternateContent
+	fallback := ac.Fallback.Pict.Shape.Textbox.Content.Paragraphs[0].Runs[0].Text[0].Content
+	if fallback != "Callout Globex" {
+		t.Errorf("Expected VML fallback to be updated, got %q", fallback)
+	}
+
+	count, err := doc.ReplaceTextInParagraph(2, "Legacy", "Old")
+	if err != nil || count != 1 {
+		t.Errorf("Expected 1 replacement in paragraph 2, got %d (%v)", count, err)
+	}
+}
+
+func TestTextBoxSurvivesSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "textbox.docx")
+	if err := newTextBoxDocument(t).Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	doc, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if texts := doc.GetTextBoxText(); len(texts) != 2 || texts[0] != "Callout ACME" {
+		t.Errorf("Expected text boxes after reopening, got %v", texts)
+	}
+
+	// Positioning is not modeled but must be preserved
+	xmlData, _ := doc.GetPart("word/document.xml")
+	for _, want := range []string{"<wp:posOffset>123</wp:posOffset>", `relativeFrom="column"`, `rot="0"`} {
+		if !strings.Contains(string(xmlData), want) {
+			t.Errorf("Expected saved document to contain %s", want)
+		}
+	}
+}
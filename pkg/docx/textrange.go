@@ -0,0 +1,289 @@
+package docx
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Character offsets count the characters (runes) of a paragraph's text as
+// GetParagraphText returns it for the runs, links and field results, leaving
+// out text boxes and SmartArt.
+
+// InsertTextAt inserts text into the paragraph at index, before the
+// character at offset; an offset equal to the length of the paragraph's
+// text appends to it. Without options the text takes the formatting of the
+// text it is inserted into (at a boundary between two runs, the first of
+// them). With options the run holding that text is split, and the inserted
+// text gets a run of its own with the same formatting plus the options, e.g.
+// WithBold. Options that set paragraph properties, such as WithAlignment,
+// are ignored.
+func (d *Document) InsertTextAt(index, offset int, text string, opts ...ParagraphOption) error {
+	if index < 0 || index >= len(d.Body.Paragraphs) {
+		return fmt.Errorf("paragraph index %d %w", index, ErrIndexOutOfRange)
+	}
+	p := &d.Body.Paragraphs[index]
+	if length := paragraphTextLength(p); offset < 0 || offset > length {
+		return fmt.Errorf("offset %d %w (paragraph %d has %d characters)", offset, ErrIndexOutOfRange, index, length)
+	}
+	p.insertText(offset, text, opts, false)
+	return nil
+}
+
+// insertText inserts text at a character offset of the paragraph, which
+// must be in range. At a boundary between two runs, the text goes into the
+// first of them, or the second if next is set.
+func (p *Paragraph) insertText(offset int, text string, opts []ParagraphOption, next bool) {
+	if text == "" {
+		return
+	}
+
+	runs, i, k, at := p.locateOffset(offset, next)
+	if runs == nil {
+		// No text to insert into yet
+		p.Runs = append(p.Runs, newTextRun(nil, text, opts))
+		return
+	}
+	if len(opts) == 0 {
+		t := &(*runs)[i].Text[k]
+		t.Content = t.Content[:at] + text + t.Content[at:]
+		t.Space = "preserve"
+		return
+	}
+
+	left, right := splitRun((*runs)[i], k, at)
+	inserted := []Run{newTextRun(left.Props, text, opts)}
+	if left.hasContent() {
+		inserted = append([]Run{left}, inserted...)
+	}
+	if right.hasContent() {
+		inserted = append(inserted, right)
+	}
+	p.spliceRuns(runs, i, 1, inserted...)
+}
+
+// DeleteTextRange deletes the characters of the paragraph at index from
+// offset start up to, but not including, offset end. Runs left without
+// content are removed, along with links left without runs; the formatting
+// of the remaining text is kept.
+func (d *Document) DeleteTextRange(index, start, end int) error {
+	if index < 0 || index >= len(d.Body.Paragraphs) {
+		return fmt.Errorf("paragraph index %d %w", index, ErrIndexOutOfRange)
+	}
+	p := &d.Body.Paragraphs[index]
+	if length := paragraphTextLength(p); start < 0 || end > length || start > end {
+		return fmt.Errorf("invalid range [%d:%d] for paragraph %d with %d characters", start, end, index, length)
+	}
+	p.deleteText(start, end)
+	return nil
+}
+
+// deleteText deletes the characters of the paragraph from offset start up
+// to end, which must be in range
+func (p *Paragraph) deleteText(start, end int) {
+	if start == end {
+		return
+	}
+
+	pos := 0
+	emptied := make(map[*Run]bool)
+	for _, ref := range p.runRefs() {
+		r := &(*ref.runs)[ref.i]
+		changed := false
+		for k := range r.Text {
+			t := &r.Text[k]
+			n := utf8.RuneCountInString(t.Content)
+			lo, hi := max(start, pos)-pos, min(end, pos+n)-pos
+			pos += n
+			if lo >= hi {
+				continue
+			}
+			t.Content = t.Content[:runeByteOffset(t.Content, lo)] + t.Content[runeByteOffset(t.Content, hi):]
+			t.Space = "preserve"
+			changed = true
+		}
+		if changed && !r.hasContent() {
+			emptied[r] = true
+		}
+	}
+	for _, runs := range p.runSlices() {
+		p.filterRuns(runs, func(r *Run) bool { return !emptied[r] })
+	}
+
+	links := p.Hyperlinks[:0]
+	for _, h := range p.Hyperlinks {
+		if len(h.Runs) > 0 {
+			links = append(links, h)
+		}
+	}
+	p.Hyperlinks = links
+}
+
+// replaceStyled replaces the occurrences of old text in the runs of the
+// paragraph with new text formatted by opts and returns their number
+func (p *Paragraph) replaceStyled(oldText, newText string, opts []ParagraphOption) int {
+	var sb strings.Builder
+	p.forEachRun(func(r *Run) {
+		for _, t := range r.Text {
+			sb.WriteString(t.Content)
+		}
+	})
+	text := sb.String()
+
+	// Character offsets of the matches, replaced from the last so the
+	// offsets of the others stay valid
+	var matches []int
+	for pos := 0; ; {
+		i := strings.Index(text[pos:], oldText)
+		if i < 0 {
+			break
+		}
+		matches = append(matches, utf8.RuneCountInString(text[:pos+i]))
+		pos += i + len(oldText)
+	}
+	oldLength, newLength := utf8.RuneCountInString(oldText), utf8.RuneCountInString(newText)
+	for i := len(matches) - 1; i >= 0; i-- {
+		start := matches[i]
+		// Inserted at the start of the match, the new text takes its formatting
+		p.insertText(start, newText, opts, true)
+		p.deleteText(start+newLength, start+newLength+oldLength)
+	}
+	return len(matches)
+}
+
+// runSlices returns the run slices of a paragraph: its own runs, then those
+// of its links and field results
+func (p *Paragraph) runSlices() []*[]Run {
+	slices := []*[]Run{&p.Runs}
+	for i := range p.Hyperlinks {
+		slices = append(slices, &p.Hyperlinks[i].Runs)
+	}
+	for i := range p.Fields {
+		slices = append(slices, &p.Fields[i].Runs)
+	}
+	return slices
+}
+
+// paragraphTextLength returns the number of characters of a paragraph's runs
+func paragraphTextLength(p *Paragraph) int {
+	n := 0
+	p.forEachRun(func(r *Run) {
+		for _, t := range r.Text {
+			n += utf8.RuneCountInString(t.Content)
+		}
+	})
+	return n
+}
+
+// locateOffset finds the text element holding the character at offset: the
+// run slice, the run's index in it, the text's index in the run and the
+// byte offset in the text. An offset at the end of a text element is placed
+// there rather than at the start of the next one, unless next is set and
+// there is a next one. runs is nil when the paragraph has no text elements.
+func (p *Paragraph) locateOffset(offset int, next bool) (runs *[]Run, i, k, at int) {
+	pos := 0
+	for _, ref := range p.runRefs() {
+		r := &(*ref.runs)[ref.i]
+		for l := range r.Text {
+			content := r.Text[l].Content
+			n := utf8.RuneCountInString(content)
+			runs, i, k, at = ref.runs, ref.i, l, len(content)
+			if offset < pos+n || (offset == pos+n && !next) {
+				return runs, i, k, runeByteOffset(content, offset-pos)
+			}
+			pos += n
+		}
+	}
+	// The end of the last text element, if any
+	return runs, i, k, at
+}
+
+// runeByteOffset returns the byte offset of the nth rune of s
+func runeByteOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(s)
+}
+
+// splitRun splits a run at byte offset at of its text element k. Both
+// halves keep the run's formatting; tabs, breaks and drawings, which follow
+// the text of a run, go with the second.
+func splitRun(r Run, k, at int) (left, right Run) {
+	left = Run{Props: r.Props.clone()}
+	right = r
+	right.Props = r.Props.clone()
+
+	left.Text = append(left.Text, r.Text[:k]...)
+	if at > 0 {
+		left.Text = append(left.Text, Text{Space: "preserve", Content: r.Text[k].Content[:at]})
+	}
+	right.Text = nil
+	if rest := r.Text[k].Content[at:]; rest != "" {
+		right.Text = append(right.Text, Text{Space: "preserve", Content: rest})
+	}
+	right.Text = append(right.Text, r.Text[k+1:]...)
+	return left, right
+}
+
+// newTextRun returns a run of text with a copy of props, formatted by the
+// run options among opts
+func newTextRun(props *RProps, text string, opts []ParagraphOption) Run {
+	p := Paragraph{Runs: []Run{{
+		Props: props.clone(),
+		Text:  []Text{{Space: "preserve", Content: text}},
+	}}}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	var r Run
+	p.forEachRun(func(run *Run) { r = *run })
+	return r
+}
+
+// hasContent reports whether a run holds any text or other content
+func (r *Run) hasContent() bool {
+	for _, t := range r.Text {
+		if t.Content != "" {
+			return true
+		}
+	}
+	return r.Tab != nil || r.Break != nil || r.Drawing != nil || r.AlternateContent != nil ||
+		r.Pict != nil || r.FldChar != nil || len(r.InstrText) > 0
+}
+
+// clone returns a copy of the run properties that shares nothing with them
+func (rp *RProps) clone() *RProps {
+	if rp == nil {
+		return nil
+	}
+	c := *rp
+	c.RFonts = clonePtr(rp.RFonts)
+	c.Bold = clonePtr(rp.Bold)
+	c.BoldCS = clonePtr(rp.BoldCS)
+	c.Italic = clonePtr(rp.Italic)
+	c.ItalicCS = clonePtr(rp.ItalicCS)
+	c.Strike = clonePtr(rp.Strike)
+	c.Vanish = clonePtr(rp.Vanish)
+	c.Color = clonePtr(rp.Color)
+	c.Size = clonePtr(rp.Size)
+	c.SizeCS = clonePtr(rp.SizeCS)
+	c.Highlight = clonePtr(rp.Highlight)
+	c.Underline = clonePtr(rp.Underline)
+	c.VertAlign = clonePtr(rp.VertAlign)
+	c.RTL = clonePtr(rp.RTL)
+	c.CS = clonePtr(rp.CS)
+	c.Lang = clonePtr(rp.Lang)
+	return &c
+}
+
+func clonePtr[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
@@ -0,0 +1,115 @@
+package docx
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInsertTextAt(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Hello world", WithItalic())
+	doc.AddParagraph("")
+
+	if err := doc.InsertTextAt(0, 5, ","); err != nil {
+		t.Fatalf("InsertTextAt failed: %v", err)
+	}
+	if err := doc.InsertTextAt(0, 7, "big ", WithBold()); err != nil {
+		t.Fatalf("InsertTextAt failed: %v", err)
+	}
+	if got, _ := doc.GetParagraphText(0); got != "Hello, big world" {
+		t.Errorf("Unexpected text: %q", got)
+	}
+
+	runs := doc.Body.Paragraphs[0].Runs
+	if len(runs) != 3 || runs[1].Text[0].Content != "big " {
+		t.Fatalf("Expected the run to be split in three, got %+v", runs)
+	}
+	if runs[1].Props.Bold == nil || runs[1].Props.Italic == nil {
+		t.Error("Expected the inserted run to be bold and keep the italics")
+	}
+	if runs[0].Props.Bold != nil || runs[2].Props.Bold != nil || runs[2].Props.Italic == nil {
+		t.Error("Expected the surrounding runs to keep their formatting")
+	}
+
+	if err := doc.InsertTextAt(1, 0, "Café"); err != nil {
+		t.Fatalf("InsertTextAt failed: %v", err)
+	}
+	if err := doc.InsertTextAt(1, 4, "!"); err != nil {
+		t.Fatalf("InsertTextAt failed: %v", err)
+	}
+	if got, _ := doc.GetParagraphText(1); got != "Café!" {
+		t.Errorf("Expected offsets to count characters, got %q", got)
+	}
+
+	if err := doc.InsertTextAt(0, 17, "x"); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+}
+
+func TestDeleteTextRange(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Dear ")
+	p := &doc.Body.Paragraphs[0]
+	p.Runs = append(p.Runs,
+		Run{Props: &RProps{Bold: &Bold{}}, Text: []Text{{Content: "Mr. "}}},
+		Run{Text: []Text{{Content: "Smith"}}},
+	)
+	p.Hyperlinks = []Hyperlink{{ID: "rId9", Position: len(p.Runs), Runs: []Run{{Text: []Text{{Content: " (profile)"}}}}}}
+
+	if err := doc.DeleteTextRange(0, 5, 9); err != nil {
+		t.Fatalf("DeleteTextRange failed: %v", err)
+	}
+	if got, _ := doc.GetParagraphText(0); got != "Dear Smith (profile)" {
+		t.Errorf("Unexpected text: %q", got)
+	}
+	if len(p.Runs) != 2 {
+		t.Errorf("Expected the emptied run to be removed, got %d runs", len(p.Runs))
+	}
+
+	if err := doc.DeleteTextRange(0, 7, 20); err != nil {
+		t.Fatalf("DeleteTextRange failed: %v", err)
+	}
+	if got, _ := doc.GetParagraphText(0); got != "Dear Sm" || len(p.Hyperlinks) != 0 {
+		t.Errorf("Expected the text and the emptied link to go, got %q with %d links", got, len(p.Hyperlinks))
+	}
+
+	if err := doc.DeleteTextRange(0, 3, 8); err == nil {
+		t.Error("Expected an error for a range past the end of the paragraph")
+	}
+}
+
+func TestReplaceTextStyled(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Pay the fee before the due date", WithItalic())
+	doc.AddTable(1, 1).SetCellText(0, 0, "fee: 10")
+	p := &doc.Body.Paragraphs[0]
+	p.Runs = append(p.Runs, Run{Text: []Text{{Content: " or a late f"}}}, Run{Text: []Text{{Content: "ee applies"}}})
+
+	count := doc.ReplaceTextStyled("fee", "charge", WithBold(), WithColor("FF0000"))
+	if count != 3 {
+		t.Errorf("Expected 3 replacements, got %d", count)
+	}
+	if got, _ := doc.GetParagraphText(0); got != "Pay the charge before the due date or a late charge applies" {
+		t.Errorf("Unexpected text: %q", got)
+	}
+	if got := doc.GetTextIn(SearchScope{Tables: true}); got != "charge: 10" {
+		t.Errorf("Unexpected table text: %q", got)
+	}
+
+	styled := 0
+	for _, r := range p.Runs {
+		if len(r.Text) == 0 || r.Text[0].Content != "charge" {
+			continue
+		}
+		styled++
+		if r.Props == nil || r.Props.Bold == nil || r.Props.Color == nil || r.Props.Color.Val != "FF0000" {
+			t.Errorf("Expected the replacement to be bold and red, got %+v", r.Props)
+		}
+	}
+	if styled != 2 {
+		t.Errorf("Expected 2 runs of their own for the replacements, got %d", styled)
+	}
+	if p.Runs[1].Props.Italic == nil {
+		t.Error("Expected the replacement to keep the formatting of the text it replaced")
+	}
+}
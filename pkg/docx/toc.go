@@ -0,0 +1,77 @@
+package docx
+
+import "fmt"
+
+// tocPlaceholder is shown in place of the entries until Word updates the field
+const tocPlaceholder = "Right-click and choose Update Field to build the table of contents."
+
+// TOCOptions holds configuration for InsertTOC
+type TOCOptions struct {
+	Index        int    // Body paragraph the table of contents is inserted before
+	Title        string // Heading above the table of contents, none if empty
+	Levels       int    // Deepest heading level listed, from 1 to 9
+	Static       bool   // Fill in the headings, so the entries show before Word updates the field
+	UpdateOnOpen bool   // Ask Word to update the field when the document is opened
+}
+
+// DefaultTOCOptions returns options for a "Contents" table of headings 1 to 3
+// at the top of the document
+func DefaultTOCOptions() TOCOptions {
+	return TOCOptions{
+		Title:  "Contents",
+		Levels: 3,
+	}
+}
+
+// InsertTOC inserts a table of contents: a TOC field listing the headings
+// down to opts.Levels, which Word fills in with page numbers when fields are
+// updated. Until then the field shows either a hint to update it or, with
+// opts.Static, the current headings, without page numbers.
+func (d *Document) InsertTOC(opts TOCOptions) error {
+	if opts.Index < 0 || opts.Index > len(d.Body.Paragraphs) {
+		return fmt.Errorf("index %d %w", opts.Index, ErrIndexOutOfRange)
+	}
+	if opts.Levels < 1 || opts.Levels > 9 {
+		return fmt.Errorf("table of contents levels must be between 1 and 9, got %d", opts.Levels)
+	}
+
+	field := SimpleField{Instr: fmt.Sprintf(`TOC \o "1-%d" \h \z \u`, opts.Levels)}
+	if opts.UpdateOnOpen {
+		field.Dirty = "true"
+	}
+	if opts.Static {
+		field.Runs = d.tocEntryRuns(opts.Levels)
+	}
+	if len(field.Runs) == 0 {
+		field.Runs = []Run{{Text: []Text{{Content: tocPlaceholder}}}}
+	}
+
+	paras := []Paragraph{{Fields: []SimpleField{field}}}
+	if opts.Title != "" {
+		title := Paragraph{Runs: []Run{{Text: []Text{{Space: "preserve", Content: opts.Title}}}}}
+		WithStyle("TOCHeading")(&title)
+		paras = append([]Paragraph{title}, paras...)
+	}
+
+	d.Body.SpliceParagraphs(opts.Index, 0, paras...)
+	return nil
+}
+
+// tocEntryRuns lists the headings down to maxLevel as runs, one line per
+// heading indented by a tab per level below the first
+func (d *Document) tocEntryRuns(maxLevel int) []Run {
+	var runs []Run
+	for _, h := range d.headings() {
+		if h.Level > maxLevel {
+			continue
+		}
+		if len(runs) > 0 {
+			runs = append(runs, Run{Break: &Break{}})
+		}
+		for i := 1; i < h.Level; i++ {
+			runs = append(runs, Run{Tab: &Tab{}})
+		}
+		runs = append(runs, Run{Text: []Text{{Space: "preserve", Content: h.Text}}})
+	}
+	return runs
+}
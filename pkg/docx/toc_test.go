@@ -0,0 +1,79 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInsertTOC(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Introduction", WithStyle("Heading1"))
+	doc.AddParagraph("Background", WithStyle("Heading2"))
+	doc.AddParagraph("Detail", WithStyle("Heading4"))
+	doc.AddParagraph("Body text")
+
+	opts := DefaultTOCOptions()
+	opts.Static = true
+	opts.UpdateOnOpen = true
+	if err := doc.InsertTOC(opts); err != nil {
+		t.Fatalf("InsertTOC failed: %v", err)
+	}
+
+	if got := doc.GetParagraphCount(); got != 6 {
+		t.Fatalf("Expected 6 paragraphs, got %d", got)
+	}
+	if text, _ := doc.GetParagraphText(0); text != "Contents" {
+		t.Errorf("Expected the title first, got %q", text)
+	}
+
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	reopened, err := ReadFrom(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadFrom failed: %v", err)
+	}
+
+	fields := reopened.Body.Paragraphs[1].Fields
+	if len(fields) != 1 {
+		t.Fatalf("Expected a TOC field, got %+v", reopened.Body.Paragraphs[1])
+	}
+	if fields[0].Instr != `TOC \o "1-3" \h \z \u` || fields[0].Dirty != "true" {
+		t.Errorf("Unexpected field: instr %q, dirty %q", fields[0].Instr, fields[0].Dirty)
+	}
+
+	// Heading 4 is below the levels listed
+	text, _ := reopened.GetParagraphText(1)
+	if text != "IntroductionBackground" {
+		t.Errorf("Expected the static entries, got %q", text)
+	}
+	if !strings.Contains(reopened.GetText(), "Background") {
+		t.Error("Expected GetText to include the entries")
+	}
+}
+
+func TestInsertTOCPlaceholder(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Introduction", WithStyle("Heading1"))
+
+	opts := TOCOptions{Index: 1, Levels: 2}
+	if err := doc.InsertTOC(opts); err != nil {
+		t.Fatalf("InsertTOC failed: %v", err)
+	}
+	if doc.GetParagraphCount() != 2 {
+		t.Fatalf("Expected no title paragraph, got %d paragraphs", doc.GetParagraphCount())
+	}
+	if text, _ := doc.GetParagraphText(1); text != tocPlaceholder {
+		t.Errorf("Expected the placeholder, got %q", text)
+	}
+
+	if err := doc.InsertTOC(TOCOptions{Index: 5, Levels: 3}); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+	if err := doc.InsertTOC(TOCOptions{Levels: 0}); err == nil {
+		t.Error("Expected an error for 0 levels")
+	}
+}
@@ -0,0 +1,131 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxDepth is the deepest nesting of tables, content controls and text
+// boxes that traversal will follow. Real documents rarely go past a handful of
+// levels; the limit protects against crafted documents.
+const DefaultMaxDepth = 64
+
+// ErrMaxDepthExceeded is returned when content is nested deeper than the traversal limit
+var ErrMaxDepthExceeded = errors.New("maximum nesting depth exceeded")
+
+// ParagraphVisitor is called for each paragraph found during traversal.
+// depth is 0 for body paragraphs and grows by one for each enclosing table cell,
+// content control or text box.
+type ParagraphVisitor func(p *Paragraph, depth int) error
+
+// TableVisitor is called for each table found during traversal
+type TableVisitor func(t *Table, depth int) error
+
+// walker traverses block content with a depth limit
+type walker struct {
+	maxDepth        int
+	includeFallback bool // Also visit the VML copies of text boxes
+	paragraph       ParagraphVisitor
+	table           TableVisitor
+	sdt             func(s *SDT, depth int) error
+}
+
+// WalkParagraphs visits every paragraph of the body, including paragraphs inside
+// tables, nested tables, content controls and text boxes. Within a container,
+// paragraphs are visited before tables and content controls. A maxDepth of 0 or
+// less uses DefaultMaxDepth. Traversal stops at the first error returned by fn.
+func (d *Document) WalkParagraphs(maxDepth int, fn ParagraphVisitor) error {
+	w := newWalker(maxDepth)
+	w.paragraph = fn
+	return w.walkBody(d.Body)
+}
+
+// WalkTables visits every table of the body, including nested tables and tables
+// inside content controls and text boxes
+func (d *Document) WalkTables(maxDepth int, fn TableVisitor) error {
+	w := newWalker(maxDepth)
+	w.table = fn
+	return w.walkBody(d.Body)
+}
+
+// CheckDepth returns ErrMaxDepthExceeded if the body nests content deeper than maxDepth
+func (d *Document) CheckDepth(maxDepth int) error {
+	return newWalker(maxDepth).walkBody(d.Body)
+}
+
+func newWalker(maxDepth int) *walker {
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	return &walker{maxDepth: maxDepth}
+}
+
+func (w *walker) walkBody(body *Body) error {
+	if body == nil {
+		return nil
+	}
+	return w.walkBlocks(body.Paragraphs, body.Tables, body.SDTs, 0)
+}
+
+func (w *walker) walkBlocks(paragraphs []Paragraph, tables []Table, sdts []SDT, depth int) error {
+	if depth > w.maxDepth {
+		return fmt.Errorf("%w: depth %d, limit %d", ErrMaxDepthExceeded, depth, w.maxDepth)
+	}
+
+	for i := range paragraphs {
+		if err := w.walkParagraph(&paragraphs[i], depth); err != nil {
+			return err
+		}
+	}
+	for i := range tables {
+		if err := w.walkTable(&tables[i], depth); err != nil {
+			return err
+		}
+	}
+	for i := range sdts {
+		if w.sdt != nil {
+			if err := w.sdt(&sdts[i], depth); err != nil {
+				return err
+			}
+		}
+		if sdts[i].Content == nil {
+			continue
+		}
+		c := sdts[i].Content
+		if err := w.walkBlocks(c.Paragraphs, c.Tables, c.SDTs, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) walkParagraph(p *Paragraph, depth int) error {
+	if w.paragraph != nil {
+		if err := w.paragraph(p, depth); err != nil {
+			return err
+		}
+	}
+	for _, tb := range p.textBoxes(w.includeFallback) {
+		if err := w.walkBlocks(tb.Paragraphs, tb.Tables, nil, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) walkTable(t *Table, depth int) error {
+	if w.table != nil {
+		if err := w.table(t, depth); err != nil {
+			return err
+		}
+	}
+	for i := range t.Rows {
+		for j := range t.Rows[i].Cells {
+			cell := &t.Rows[i].Cells[j]
+			if err := w.walkBlocks(cell.Content, cell.Tables, cell.SDTs, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// nestedTable builds a table whose single cell nests another table, levels deep
+func nestedTable(levels int) Table {
+	t := Table{Rows: []TblRow{{Cells: []TblCell{{
+		Content: []Paragraph{{Runs: []Run{{Text: []Text{{Content: fmt.Sprintf("level %d", levels)}}}}}},
+	}}}}}
+	if levels > 1 {
+		t.Rows[0].Cells[0].Tables = []Table{nestedTable(levels - 1)}
+	}
+	return t
+}
+
+// deepDocumentXML generates document.xml with tables nested levels deep inside a content control
+func deepDocumentXML(levels int) string {
+	var sb strings.Builder
+	sb.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+	sb.WriteString(`<w:sdt><w:sdtPr><w:alias w:val="Wrapper"/></w:sdtPr><w:sdtContent>`)
+	sb.WriteString(strings.Repeat(`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>cell</w:t></w:r></w:p>`, levels))
+	sb.WriteString(strings.Repeat(`</w:tc></w:tr></w:tbl>`, levels))
+	sb.WriteString(`</w:sdtContent></w:sdt></w:body></w:document>`)
+	return sb.String()
+}
+
+func TestWalkParagraphs(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Top")
+	doc.Body.Tables = append(doc.Body.Tables, nestedTable(3))
+	doc.Body.SDTs = append(doc.Body.SDTs, SDT{Content: &SDTContent{
+		Paragraphs: []Paragraph{{Runs: []Run{{Text: []Text{{Content: "Controlled"}}}}}},
+	}})
+
+	var texts []string
+	var depths []int
+	err := doc.WalkParagraphs(0, func(p *Paragraph, depth int) error {
+		texts = append(texts, doc.paragraphText(p))
+		depths = append(depths, depth)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkParagraphs failed: %v", err)
+	}
+
+	wantTexts := []string{"Top", "level 3", "level 2", "level 1", "Controlled"}
+	wantDepths := []int{0, 1, 2, 3, 1}
+	if fmt.Sprint(texts) != fmt.Sprint(wantTexts) || fmt.Sprint(depths) != fmt.Sprint(wantDepths) {
+		t.Errorf("Unexpected traversal: texts %v depths %v", texts, depths)
+	}
+
+	tables := 0
+	if err := doc.WalkTables(0, func(*Table, int) error { tables++; return nil }); err != nil || tables != 3 {
+		t.Errorf("Expected 3 tables, got %d (%v)", tables, err)
+	}
+
+	// Errors from the visitor stop the traversal
+	stop := errors.New("stop")
+	visited := 0
+	err = doc.WalkParagraphs(0, func(*Paragraph, int) error { visited++; return stop })
+	if !errors.Is(err, stop) || visited != 1 {
+		t.Errorf("Expected traversal to stop after first paragraph, visited %d (%v)", visited, err)
+	}
+}
+
+func TestWalkParagraphsDepthLimit(t *testing.T) {
+	doc := New()
+	doc.Body.Tables = append(doc.Body.Tables, nestedTable(DefaultMaxDepth+10))
+
+	err := doc.WalkParagraphs(0, func(*Paragraph, int) error { return nil })
+	if !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Errorf("Expected ErrMaxDepthExceeded, got %v", err)
+	}
+	if err := doc.CheckDepth(DefaultMaxDepth + 10); err != nil {
+		t.Errorf("Expected a higher limit to accept the document, got %v", err)
+	}
+	if err := doc.CheckDepth(5); !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Errorf("Expected limit of 5 to be exceeded, got %v", err)
+	}
+}
+
+func TestPathologicalNesting(t *testing.T) {
+	doc := New()
+	if err := doc.parseDocument([]byte(deepDocumentXML(500))); err != nil {
+		t.Fatalf("parseDocument failed: %v", err)
+	}
+
+	if err := doc.CheckDepth(0); !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Errorf("Expected ErrMaxDepthExceeded, got %v", err)
+	}
+
+	dst := New()
+	if err := dst.AppendDocument(doc); !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Errorf("Expected AppendDocument to refuse the document, got %v", err)
+	}
+
+	// Content controls and nested tables are kept when saving
+	path := filepath.Join(t.TempDir(), "deep.docx")
+	if err := doc.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if len(reopened.Body.SDTs) != 1 || len(reopened.Body.SDTs[0].Content.Tables) != 1 {
+		t.Errorf("Expected content control with a table to survive save, got %+v", reopened.Body.SDTs)
+	}
+}
+
+func TestNestedTextBoxesAreBounded(t *testing.T) {
+	const levels = DefaultMaxDepth + 20
+	var sb strings.Builder
+	sb.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:v="urn:schemas-microsoft-com:vml"><w:body>`)
+	for i := 0; i < levels; i++ {
+		fmt.Fprintf(&sb, `<w:p><w:r><w:t>box%d</w:t></w:r><w:r><w:pict><v:shape><v:textbox><w:txbxContent>`, i)
+	}
+	sb.WriteString(`<w:p/>`)
+	for i := 0; i < levels; i++ {
+		sb.WriteString(`</w:txbxContent></v:textbox></v:shape></w:pict></w:r></w:p>`)
+	}
+	sb.WriteString(`</w:body></w:document>`)
+
+	doc := New()
+	if err := doc.parseDocument([]byte(sb.String())); err != nil {
+		t.Fatalf("parseDocument failed: %v", err)
+	}
+
+	text, err := doc.GetParagraphText(0)
+	if err != nil {
+		t.Fatalf("GetParagraphText failed: %v", err)
+	}
+	if !strings.Contains(text, "box1 ") || strings.Contains(text, fmt.Sprintf("box%d", levels-1)) {
+		t.Errorf("Expected text boxes beyond the depth limit to be skipped, got %d chars", len(text))
+	}
+	if count := doc.ReplaceText("box", "frame"); count == 0 || count >= levels {
+		t.Errorf("Expected replacement to stop at the depth limit, got %d", count)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("intro")
+	doc.AddTable(1, 2).SetCellText(0, 1, "cell")
+	doc.AddParagraph("outro")
+	doc.SetPart("word/header1.xml", []byte(`<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>acme</w:t></w:r></w:p></w:hdr>`))
+	doc.SetPart("word/footer1.xml", []byte(`<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>page</w:t></w:r></w:p></w:ftr>`))
+	if err := doc.SetFooter(FooterTypeDefault, "confidential"); err != nil {
+		t.Fatalf("SetFooter failed: %v", err)
+	}
+
+	var paths []string
+	err := doc.Walk(func(block Block, path Path) error {
+		if block.Kind != RunBlock {
+			paths = append(paths, path.String())
+			return nil
+		}
+		for i := range block.Run.Text {
+			block.Run.Text[i].Content = strings.ToUpper(block.Run.Text[i].Content)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	want := []string{
+		"word/document.xml/p[0]",
+		"word/document.xml/tbl[0]",
+		"word/document.xml/tbl[0]/tc[0,0]",
+		"word/document.xml/tbl[0]/tc[0,0]/p[0]",
+		"word/document.xml/tbl[0]/tc[0,1]",
+		"word/document.xml/tbl[0]/tc[0,1]/p[0]",
+		"word/document.xml/p[1]",
+		"word/header1.xml",
+		"word/header1.xml/p[0]",
+		"word/footer1.xml",
+		"word/footer1.xml/p[0]",
+		"footer-default",
+		"footer-default/p[0]",
+	}
+	if got := strings.Join(paths, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("Unexpected walk order:\n%s", got)
+	}
+
+	if got := doc.GetTextIn(FullSearchScope()); got != "INTRO CELL OUTRO ACME PAGE" {
+		t.Errorf("Expected the runs to be changed, got %q", got)
+	}
+	if footer, _ := doc.GetFooter(FooterTypeDefault); footer.Paragraphs[0].Runs[0].Text[0].Content != "CONFIDENTIAL" {
+		t.Error("Expected the footer set with SetFooter to be changed")
+	}
+	header, _ := doc.GetPart("word/header1.xml")
+	if _, err := ReadBytes(mustBytes(t, doc)); err != nil || !strings.HasPrefix(string(header), `<w:hdr xmlns:w=`) {
+		t.Errorf("Expected the header to be rewritten in place, got %s (%v)", header, err)
+	}
+
+	// SkipChildren skips a block's content; other errors stop the walk
+	visited := 0
+	err = doc.Walk(func(block Block, path Path) error {
+		visited++
+		if block.Kind == TableBlock || block.Kind == ParagraphBlock {
+			return SkipChildren
+		}
+		return nil
+	})
+	if err != nil || visited != 9 {
+		t.Errorf("Expected 9 blocks to be visited, got %d (%v)", visited, err)
+	}
+	stop := errors.New("stop")
+	err = doc.Walk(func(block Block, path Path) error {
+		if block.Kind == CellBlock {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected the walk to stop with the error, got %v", err)
+	}
+}
+
+func mustBytes(t *testing.T, doc *Document) []byte {
+	t.Helper()
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	return data
+}
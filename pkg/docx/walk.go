@@ -0,0 +1,527 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SkipChildren can be returned by a WalkFunc to skip the content of the
+// block it was called for; Walk carries on with the next block
+var SkipChildren = errors.New("skip children")
+
+// BlockKind tells what a Block holds
+type BlockKind int
+
+const (
+	ParagraphBlock      BlockKind = iota // Block.Paragraph is set
+	RunBlock                             // Block.Run is set
+	TableBlock                           // Block.Table is set
+	CellBlock                            // Block.Cell is set
+	ContentControlBlock                  // Block.ContentControl is set
+	TextBoxBlock                         // Block.TextBox is set
+	HeaderBlock                          // A header; Block.HeaderFooter is set for those added with SetHeader
+	FooterBlock                          // A footer; Block.HeaderFooter is set for those added with SetFooter
+)
+
+// pathNames are the names of the kinds of blocks in paths
+var pathNames = map[BlockKind]string{
+	ParagraphBlock:      "p",
+	RunBlock:            "r",
+	TableBlock:          "tbl",
+	CellBlock:           "tc",
+	ContentControlBlock: "sdt",
+	TextBoxBlock:        "txbx",
+	HeaderBlock:         "hdr",
+	FooterBlock:         "ftr",
+}
+
+// String returns the name of the kind of block, as used in paths
+func (k BlockKind) String() string {
+	if name, ok := pathNames[k]; ok {
+		return name
+	}
+	return "BlockKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Block is an element of a document visited by Walk. Changes made through
+// its pointers are kept.
+type Block struct {
+	Kind           BlockKind
+	Paragraph      *Paragraph
+	Run            *Run
+	Table          *Table
+	Cell           *TblCell
+	ContentControl *SDT
+	TextBox        *TxbxContent
+	HeaderFooter   *HeaderFooter
+}
+
+// PathElem is a step of a Path: the kind of a block and its index among the
+// blocks of that kind in its container. Runs are numbered across a
+// paragraph's runs, then those of its hyperlinks and fields.
+type PathElem struct {
+	Kind  BlockKind
+	Index int // For cells, the row
+	Col   int // For cells, the column
+}
+
+// Path locates a block visited by Walk: the part holding it, such as
+// word/document.xml or word/header1.xml, and the steps from the part's
+// content down to the block. Headers and footers added with SetHeader and
+// SetFooter aren't parts yet and are named by their type instead, such as
+// header-default.
+type Path struct {
+	Part  string
+	Elems []PathElem
+}
+
+// String returns the path in a readable form, e.g.
+// word/document.xml/tbl[0]/tc[1,2]/p[0]/r[3]
+func (p Path) String() string {
+	var sb strings.Builder
+	sb.WriteString(p.Part)
+	for _, e := range p.Elems {
+		if e.Kind == CellBlock {
+			fmt.Fprintf(&sb, "/%s[%d,%d]", e.Kind, e.Index, e.Col)
+		} else {
+			fmt.Fprintf(&sb, "/%s[%d]", e.Kind, e.Index)
+		}
+	}
+	return sb.String()
+}
+
+// Depth returns the number of tables, content controls and text boxes
+// enclosing the block, as the depth of WalkParagraphs counts them
+func (p Path) Depth() int {
+	depth := 0
+	for _, e := range p.Elems {
+		if e.Kind == CellBlock || e.Kind == ContentControlBlock || e.Kind == TextBoxBlock {
+			depth++
+		}
+	}
+	return depth
+}
+
+// child returns the path of a block inside the one p leads to. The steps
+// are copied, so a WalkFunc may keep the paths it is given.
+func (p Path) child(kind BlockKind, index, col int) Path {
+	elems := make([]PathElem, len(p.Elems), len(p.Elems)+1)
+	copy(elems, p.Elems)
+	return Path{Part: p.Part, Elems: append(elems, PathElem{Kind: kind, Index: index, Col: col})}
+}
+
+// WalkFunc is called by Walk for each block it visits
+type WalkFunc func(block Block, path Path) error
+
+// Walk visits the content of the document in document order: the body, then
+// the headers, then the footers. Each paragraph is visited before its runs,
+// and the text boxes of a run after the run; each table before its cells,
+// row by row, and each cell before its content. In table cells, content
+// controls and text boxes, paragraphs come before tables and content
+// controls, since their relative order isn't kept. Returning SkipChildren
+// from fn skips the content of a block; any other error stops the walk and
+// is returned. Changes made to headers and footers read from the package
+// are written back to their parts.
+func (d *Document) Walk(fn WalkFunc) error {
+	w := &blockWalker{fn: fn, maxDepth: DefaultMaxDepth}
+	if err := w.walkBody(d.Body, Path{Part: documentPart}); err != nil {
+		return err
+	}
+
+	// Parts saved from headers and footers set with SetHeader and SetFooter
+	// are visited through them
+	owned := d.serviceHeaderFooterParts()
+	for _, footer := range []bool{false, true} {
+		kind := HeaderBlock
+		if footer {
+			kind = FooterBlock
+		}
+		for _, name := range d.headerFooterParts(footer) {
+			if owned[name] {
+				continue
+			}
+			if err := d.walkHeaderFooterPart(w, name, kind); err != nil {
+				return err
+			}
+		}
+		if hfs, ok := d.headerFooterMgr.(*HeaderFooterService); ok {
+			set := hfs.headers
+			if footer {
+				set = hfs.footers
+			}
+			types := make([]string, 0, len(set))
+			for t := range set {
+				types = append(types, string(t))
+			}
+			sort.Strings(types)
+			for _, t := range types {
+				hf := set[HeaderFooterType(t)]
+				path := Path{Part: t}
+				err := w.visit(Block{Kind: kind, HeaderFooter: hf}, path, func() error {
+					return w.walkBlocks(hf.Paragraphs, nil, nil, path, 0)
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	headerFooterPartPattern = regexp.MustCompile(`^word/(?:header|footer)(\d*)\.xml$`)
+	storyRootPattern        = regexp.MustCompile(`(?s)^.*?<((?:\w+:)?(?:hdr|ftr))\b[^>]*?(/?)>`)
+	storyEndPattern         = regexp.MustCompile(`</(?:\w+:)?(?:hdr|ftr)>\s*$`)
+)
+
+// headerFooterParts returns the names of the header or footer parts of the
+// package, in the order of their numbers
+func (d *Document) headerFooterParts(footer bool) []string {
+	prefix := "word/header"
+	if footer {
+		prefix = "word/footer"
+	}
+	var names []string
+	for _, name := range d.PartNames() {
+		if strings.HasPrefix(name, prefix) && headerFooterPartPattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	number := func(name string) int {
+		n, _ := strconv.Atoi(headerFooterPartPattern.FindStringSubmatch(name)[1])
+		return n
+	}
+	sort.SliceStable(names, func(i, j int) bool { return number(names[i]) < number(names[j]) })
+	return names
+}
+
+// walkHeaderFooterPart walks the content of a header or footer part, and
+// writes it back if the walk changed it
+func (d *Document) walkHeaderFooterPart(w *blockWalker, name string, kind BlockKind) error {
+	data := d.files[name]
+	var story Body
+	if err := xml.Unmarshal(data, &story); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	before, err := marshalStory(&story)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+
+	path := Path{Part: name}
+	walkErr := w.visit(Block{Kind: kind}, path, func() error {
+		return w.walkBody(&story, path)
+	})
+
+	// Changes made before an error are kept too
+	after, err := marshalStory(&story)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	if bytes.Equal(before, after) {
+		return walkErr
+	}
+	root := storyRootPattern.FindSubmatch(data)
+	if root == nil {
+		return fmt.Errorf("failed to rewrite %s: root element not found", name)
+	}
+	start, closing := root[0], storyEndPattern.Find(data[len(root[0]):])
+	if len(root[2]) > 0 {
+		// An empty root such as <w:hdr/> gets an end tag
+		start = append(append([]byte(nil), start[:len(start)-2]...), '>')
+		closing = []byte("</" + string(root[1]) + ">")
+	}
+	if closing == nil {
+		return fmt.Errorf("failed to rewrite %s: end of root element not found", name)
+	}
+	rewritten := append(append([]byte(nil), start...), after...)
+	d.files[name] = append(rewritten, closing...)
+	return walkErr
+}
+
+// marshalStory marshals the paragraphs, tables and content controls of a
+// header or footer as the prefixed markup Word writes
+func marshalStory(story *Body) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, block := range story.content() {
+		data, err := xml.Marshal(block)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	return prefixMarkup(buf.Bytes())
+}
+
+// namespacePrefixes maps the namespaces of document content to the prefixes
+// their parts declare
+var namespacePrefixes = map[string]string{
+	"http://schemas.openxmlformats.org/wordprocessingml/2006/main":           "w",
+	"http://schemas.openxmlformats.org/officeDocument/2006/relationships":    "r",
+	"http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing": "wp",
+	"http://schemas.openxmlformats.org/drawingml/2006/main":                  "a",
+	"http://schemas.openxmlformats.org/drawingml/2006/picture":               "pic",
+	"http://schemas.microsoft.com/office/word/2010/wordprocessingShape":      "wps",
+	"http://schemas.openxmlformats.org/markup-compatibility/2006":            "mc",
+	"urn:schemas-microsoft-com:vml":                                          "v",
+	"urn:schemas-microsoft-com:office:office":                                "o",
+	"urn:schemas-microsoft-com:office:word":                                  "w10",
+	"http://schemas.openxmlformats.org/officeDocument/2006/math":             "m",
+	"http://www.w3.org/XML/1998/namespace":                                   "xml",
+}
+
+// prefixMarkup rewrites marshaled content so elements and attributes carry
+// the prefixes their parts declare for their namespaces: w: for the
+// WordprocessingML that encoding/xml leaves unqualified, a: for DrawingML,
+// and so on. Namespaces without a known prefix keep their declarations.
+func prefixMarkup(data []byte) ([]byte, error) {
+	const mainNamespace = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	type scope struct {
+		def      string            // Default namespace
+		prefixes map[string]string // Prefixes declared on the element
+	}
+	stack := []scope{{def: mainNamespace}}
+	resolve := func(prefix string) (string, bool) {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if uri, ok := stack[i].prefixes[prefix]; ok {
+				return uri, true
+			}
+		}
+		return "", false
+	}
+	qualify := func(name xml.Name, uri string) string {
+		if p := namespacePrefixes[uri]; p != "" {
+			return p + ":" + name.Local
+		}
+		if name.Space != "" {
+			return name.Space + ":" + name.Local
+		}
+		return name.Local
+	}
+	elementNamespace := func(name xml.Name) string {
+		if name.Space == "" {
+			return stack[len(stack)-1].def
+		}
+		uri, _ := resolve(name.Space)
+		return uri
+	}
+
+	var buf bytes.Buffer
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var names []string
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			return buf.Bytes(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			frame := scope{def: stack[len(stack)-1].def, prefixes: make(map[string]string)}
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					frame.def = a.Value
+				case a.Name.Space == "xmlns":
+					frame.prefixes[a.Name.Local] = a.Value
+				}
+			}
+			stack = append(stack, frame)
+
+			uri := elementNamespace(t.Name)
+			name := qualify(t.Name, uri)
+			names = append(names, name)
+			buf.WriteString("<" + name)
+			for _, a := range t.Attr {
+				var attr string
+				switch {
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					if namespacePrefixes[a.Value] != "" {
+						continue // Declared on the root
+					}
+					attr = "xmlns"
+				case a.Name.Space == "xmlns":
+					if namespacePrefixes[a.Value] != "" {
+						continue
+					}
+					attr = "xmlns:" + a.Name.Local
+				case a.Name.Space == "" && uri == mainNamespace:
+					// Attributes of WordprocessingML elements are qualified too
+					if a.Name.Local == "space" {
+						attr = "xml:space"
+					} else {
+						attr = "w:" + a.Name.Local
+					}
+				case a.Name.Space == "":
+					attr = a.Name.Local
+				default:
+					attrURI, _ := resolve(a.Name.Space)
+					attr = qualify(a.Name, attrURI)
+				}
+				buf.WriteString(" " + attr + `="`)
+				xml.EscapeText(&buf, []byte(a.Value))
+				buf.WriteString(`"`)
+			}
+			buf.WriteString(">")
+		case xml.EndElement:
+			buf.WriteString("</" + names[len(names)-1] + ">")
+			names = names[:len(names)-1]
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			xml.EscapeText(&buf, t)
+		}
+	}
+}
+
+// blockWalker calls a WalkFunc for blocks and their content, with a depth limit
+type blockWalker struct {
+	fn       WalkFunc
+	maxDepth int
+}
+
+// visit calls fn for a block and then children for its content, unless fn
+// returns an error. SkipChildren only skips the content.
+func (w *blockWalker) visit(block Block, path Path, children func() error) error {
+	if err := w.fn(block, path); err != nil {
+		if errors.Is(err, SkipChildren) {
+			return nil
+		}
+		return err
+	}
+	if children == nil {
+		return nil
+	}
+	return children()
+}
+
+// walkBody visits a body's paragraphs, tables and content controls in
+// document order
+func (w *blockWalker) walkBody(body *Body, path Path) error {
+	if body == nil {
+		return nil
+	}
+	type block struct {
+		position int
+		visit    func() error
+	}
+	var blocks []block
+	for i := range body.Tables {
+		i := i
+		blocks = append(blocks, block{body.Tables[i].Position, func() error {
+			return w.walkTable(&body.Tables[i], path.child(TableBlock, i, 0), 0)
+		}})
+	}
+	for i := range body.SDTs {
+		i := i
+		blocks = append(blocks, block{body.SDTs[i].Position, func() error {
+			return w.walkSDT(&body.SDTs[i], path.child(ContentControlBlock, i, 0), 0)
+		}})
+	}
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].position < blocks[j].position })
+
+	for i := range body.Paragraphs {
+		for len(blocks) > 0 && blocks[0].position <= i {
+			if err := blocks[0].visit(); err != nil {
+				return err
+			}
+			blocks = blocks[1:]
+		}
+		if err := w.walkParagraph(&body.Paragraphs[i], path.child(ParagraphBlock, i, 0), 0); err != nil {
+			return err
+		}
+	}
+	for _, b := range blocks {
+		if err := b.visit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkBlocks visits the paragraphs, then the tables, then the content
+// controls of a container at depth
+func (w *blockWalker) walkBlocks(paragraphs []Paragraph, tables []Table, sdts []SDT, path Path, depth int) error {
+	if depth > w.maxDepth {
+		return fmt.Errorf("%w: depth %d, limit %d", ErrMaxDepthExceeded, depth, w.maxDepth)
+	}
+	for i := range paragraphs {
+		if err := w.walkParagraph(&paragraphs[i], path.child(ParagraphBlock, i, 0), depth); err != nil {
+			return err
+		}
+	}
+	for i := range tables {
+		if err := w.walkTable(&tables[i], path.child(TableBlock, i, 0), depth); err != nil {
+			return err
+		}
+	}
+	for i := range sdts {
+		if err := w.walkSDT(&sdts[i], path.child(ContentControlBlock, i, 0), depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *blockWalker) walkParagraph(p *Paragraph, path Path, depth int) error {
+	return w.visit(Block{Kind: ParagraphBlock, Paragraph: p}, path, func() error {
+		var runs []*Run
+		p.forEachRun(func(r *Run) { runs = append(runs, r) })
+		for i, r := range runs {
+			runPath := path.child(RunBlock, i, 0)
+			err := w.visit(Block{Kind: RunBlock, Run: r}, runPath, func() error {
+				for j, tb := range r.textBoxes(false) {
+					tbPath := runPath.child(TextBoxBlock, j, 0)
+					err := w.visit(Block{Kind: TextBoxBlock, TextBox: tb}, tbPath, func() error {
+						return w.walkBlocks(tb.Paragraphs, tb.Tables, nil, tbPath, depth+1)
+					})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (w *blockWalker) walkTable(t *Table, path Path, depth int) error {
+	return w.visit(Block{Kind: TableBlock, Table: t}, path, func() error {
+		for i := range t.Rows {
+			for j := range t.Rows[i].Cells {
+				cell := &t.Rows[i].Cells[j]
+				cellPath := path.child(CellBlock, i, j)
+				err := w.visit(Block{Kind: CellBlock, Cell: cell}, cellPath, func() error {
+					return w.walkBlocks(cell.Content, cell.Tables, cell.SDTs, cellPath, depth+1)
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (w *blockWalker) walkSDT(sdt *SDT, path Path, depth int) error {
+	return w.visit(Block{Kind: ContentControlBlock, ContentControl: sdt}, path, func() error {
+		if sdt.Content == nil {
+			return nil
+		}
+		c := sdt.Content
+		return w.walkBlocks(c.Paragraphs, c.Tables, c.SDTs, path, depth+1)
+	})
+}
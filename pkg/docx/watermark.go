@@ -0,0 +1,297 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// watermarkShapeID prefixes the shape id Word gives text watermarks
+const watermarkShapeID = "PowerPlusWaterMarkObject"
+
+// WatermarkOptions holds configuration for text watermarks
+type WatermarkOptions struct {
+	Font       string  // Font family
+	Color      string  // Hex color, e.g. "C0C0C0"
+	Opacity    float64 // 0 (invisible) to 1 (opaque)
+	Horizontal bool    // Horizontal instead of diagonal
+}
+
+// WatermarkOption is a function type for configuring watermarks
+type WatermarkOption func(*WatermarkOptions)
+
+// WithWatermarkFont sets the watermark font family
+func WithWatermarkFont(font string) WatermarkOption {
+	return func(opts *WatermarkOptions) {
+		opts.Font = font
+	}
+}
+
+// WithWatermarkColor sets the watermark color (hex, e.g. "FF0000")
+func WithWatermarkColor(color string) WatermarkOption {
+	return func(opts *WatermarkOptions) {
+		opts.Color = color
+	}
+}
+
+// WithWatermarkOpacity sets the watermark opacity, from 0 to 1
+func WithWatermarkOpacity(opacity float64) WatermarkOption {
+	return func(opts *WatermarkOptions) {
+		opts.Opacity = opacity
+	}
+}
+
+// WithWatermarkHorizontal lays the watermark out horizontally instead of diagonally
+func WithWatermarkHorizontal() WatermarkOption {
+	return func(opts *WatermarkOptions) {
+		opts.Horizontal = true
+	}
+}
+
+// SetWatermark adds a text watermark (e.g. "DRAFT" or "CONFIDENTIAL") behind
+// the content of every page. Like Word, the watermark lives in the page
+// headers: it is added to each header the sections use, and a header is
+// created for sections that have none. An existing watermark is replaced.
+func (d *Document) SetWatermark(text string, opts ...WatermarkOption) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("watermark text is required")
+	}
+
+	options := &WatermarkOptions{
+		Font:    "Calibri",
+		Color:   "C0C0C0",
+		Opacity: 0.5,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.Opacity <= 0 || options.Opacity > 1 {
+		return fmt.Errorf("watermark opacity must be between 0 and 1, got %g", options.Opacity)
+	}
+
+	if err := d.RemoveWatermark(); err != nil {
+		return err
+	}
+
+	paragraph := watermarkParagraph(text, options)
+	stamped := make(map[string]bool) // Sections may share headers
+	for _, sectPr := range d.sections() {
+		refs := headerReferences(sectPr)
+		if _, ok := refs["default"]; !ok {
+			refs["default"] = d.addHeaderPart(sectPr)
+		}
+
+		for _, relID := range refs {
+			rel, ok := d.findRelationship(relID)
+			if !ok {
+				continue
+			}
+			part := "word/" + strings.TrimPrefix(rel.Target, "/word/")
+			data, ok := d.files[part]
+			if !ok || stamped[part] {
+				continue
+			}
+			d.files[part] = insertIntoHeader(data, paragraph)
+			stamped[part] = true
+		}
+	}
+	return nil
+}
+
+// RemoveWatermark removes text watermarks from all headers, including those
+// added by Word
+func (d *Document) RemoveWatermark() error {
+	for name, data := range d.files {
+		if !headerPartPattern.MatchString(name) || !bytes.Contains(data, []byte(watermarkShapeID)) {
+			continue
+		}
+		d.files[name] = removeWatermarkParagraphs(data)
+	}
+	return nil
+}
+
+// HasWatermark reports whether any header holds a text watermark
+func (d *Document) HasWatermark() bool {
+	for name, data := range d.files {
+		if headerPartPattern.MatchString(name) && bytes.Contains(data, []byte(watermarkShapeID)) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	headerPartPattern      = regexp.MustCompile(`^word/header\d*\.xml$`)
+	headerReferencePattern = regexp.MustCompile(`<(?:\w+:)?headerReference\b[^>]*>`)
+	footerReferencePattern = regexp.MustCompile(`<(?:\w+:)?footerReference\b[^>]*>`)
+	referenceTypePattern   = regexp.MustCompile(`\b(?:\w+:)?type="(\w+)"`)
+	referenceIDPattern     = regexp.MustCompile(`\b\w+:id="([^"]+)"`)
+	headerRootPattern      = regexp.MustCompile(`<(?:\w+:)?hdr\b[^>]*>`)
+	headerEndPattern       = regexp.MustCompile(`</(?:\w+:)?hdr>\s*$`)
+)
+
+// sections returns the properties of every section, creating the body's if
+// the document has none
+func (d *Document) sections() []*RawElement {
+	var sections []*RawElement
+	for i := range d.Body.Paragraphs {
+		if props := d.Body.Paragraphs[i].Props; props != nil && props.SectPr != nil {
+			sections = append(sections, props.SectPr)
+		}
+	}
+	if d.Body.SectPr == nil {
+		d.Body.SectPr = &RawElement{}
+	}
+	return append(sections, d.Body.SectPr)
+}
+
+// headerReferences returns the relationship IDs of a section's headers by type
+func headerReferences(sectPr *RawElement) map[string]string {
+	return sectionReferences(sectPr, headerReferencePattern)
+}
+
+// footerReferences returns the relationship IDs of a section's footers by type
+func footerReferences(sectPr *RawElement) map[string]string {
+	return sectionReferences(sectPr, footerReferencePattern)
+}
+
+// sectionReferences returns the relationship IDs of the references of a
+// section matching pattern, by type
+func sectionReferences(sectPr *RawElement, pattern *regexp.Regexp) map[string]string {
+	refs := make(map[string]string)
+	for _, tag := range pattern.FindAll(sectPr.Inner, -1) {
+		typ := "default"
+		if m := referenceTypePattern.FindSubmatch(tag); m != nil {
+			typ = string(m[1])
+		}
+		if m := referenceIDPattern.FindSubmatch(tag); m != nil {
+			refs[typ] = string(m[1])
+		}
+	}
+	return refs
+}
+
+// addHeaderPart creates an empty header part, makes it the default header of
+// the section and returns its relationship ID
+func (d *Document) addHeaderPart(sectPr *RawElement) string {
+	relID := d.addStoryPart(false)
+	addSectionReference(sectPr, false, "default", relID)
+	return relID
+}
+
+// addStoryPart creates an empty header or footer part and returns its
+// relationship ID
+func (d *Document) addStoryPart(footer bool) string {
+	kind, root, relType, contentType := "header", "hdr", relTypeHeader, contentTypeHeader
+	if footer {
+		kind, root, relType, contentType = "footer", "ftr", relTypeFooter, contentTypeFooter
+	}
+	n := 1
+	for {
+		if _, exists := d.files[fmt.Sprintf("word/%s%d.xml", kind, n)]; !exists {
+			break
+		}
+		n++
+	}
+	target := fmt.Sprintf("%s%d.xml", kind, n)
+
+	d.files["word/"+target] = []byte(xml.Header + `<w:` + root + ` xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"></w:` + root + `>`)
+	d.registerContentTypeOverride("word/"+target, contentType)
+	return d.addRelationship(relType, target)
+}
+
+// addSectionReference makes the part of a relationship the header or footer
+// of a type of the section
+func addSectionReference(sectPr *RawElement, footer bool, typ, relID string) {
+	element := "headerReference"
+	if footer {
+		element = "footerReference"
+	}
+	// Header and footer references come first in section properties
+	ref := fmt.Sprintf(`<w:%s w:type="%s" r:id="%s"/>`, element, typ, relID)
+	sectPr.Inner = append([]byte(ref), sectPr.Inner...)
+}
+
+// insertIntoHeader appends a paragraph to a header part, declaring the
+// namespaces the watermark markup uses
+func insertIntoHeader(data []byte, paragraph string) []byte {
+	root := headerRootPattern.Find(data)
+	end := headerEndPattern.FindIndex(data)
+	if root == nil || end == nil {
+		return data
+	}
+
+	newRoot := string(root)
+	for prefix, ns := range map[string]string{
+		"w":   "http://schemas.openxmlformats.org/wordprocessingml/2006/main",
+		"v":   "urn:schemas-microsoft-com:vml",
+		"o":   "urn:schemas-microsoft-com:office:office",
+		"w10": "urn:schemas-microsoft-com:office:word",
+	} {
+		if !strings.Contains(newRoot, "xmlns:"+prefix+"=") {
+			newRoot = strings.TrimSuffix(newRoot, ">") + fmt.Sprintf(` xmlns:%s="%s">`, prefix, ns)
+		}
+	}
+
+	var out bytes.Buffer
+	body := data[:end[0]]
+	out.Write(bytes.Replace(body, root, []byte(newRoot), 1))
+	out.WriteString(paragraph)
+	out.Write(data[end[0]:])
+	return out.Bytes()
+}
+
+// removeWatermarkParagraphs removes the paragraphs holding watermark shapes from header XML
+func removeWatermarkParagraphs(data []byte) []byte {
+	s := string(data)
+	for {
+		idx := strings.Index(s, watermarkShapeID)
+		if idx < 0 {
+			return []byte(s)
+		}
+
+		start := max(strings.LastIndex(s[:idx], "<w:p>"), strings.LastIndex(s[:idx], "<w:p "))
+		end := strings.Index(s[idx:], "</w:p>")
+		if start < 0 || end < 0 {
+			return []byte(s)
+		}
+		s = s[:start] + s[idx+end+len("</w:p>"):]
+	}
+}
+
+// watermarkParagraph returns the header paragraph holding a watermark shape,
+// in the VML markup Word itself writes for text watermarks
+func watermarkParagraph(text string, opts *WatermarkOptions) string {
+	rotation := "rotation:315;"
+	if opts.Horizontal {
+		rotation = ""
+	}
+
+	var escapedText, escapedFont strings.Builder
+	xml.EscapeText(&escapedText, []byte(text))
+	xml.EscapeText(&escapedFont, []byte(opts.Font))
+
+	// Scale the shape with the text so short and long words keep their proportions
+	width := 468.0
+	height := width / float64(max(len([]rune(text)), 4)) * 1.6
+
+	return `<w:p><w:pPr><w:pStyle w:val="Header"/></w:pPr><w:r><w:rPr><w:noProof/></w:rPr><w:pict>` +
+		`<v:shapetype id="_x0000_t136" coordsize="21600,21600" o:spt="136" adj="10800" path="m@7,l@8,m@5,21600l@6,21600e">` +
+		`<v:formulas><v:f eqn="sum #0 0 10800"/><v:f eqn="prod #0 2 1"/><v:f eqn="sum 21600 0 @1"/><v:f eqn="sum 0 0 @2"/>` +
+		`<v:f eqn="sum 21600 0 @3"/><v:f eqn="if @0 @3 0"/><v:f eqn="if @0 21600 @1"/><v:f eqn="if @0 0 @2"/>` +
+		`<v:f eqn="if @0 @4 21600"/><v:f eqn="mid @5 @6"/><v:f eqn="mid @8 @5"/><v:f eqn="mid @7 @8"/>` +
+		`<v:f eqn="mid @6 @7"/><v:f eqn="sum @6 0 @5"/></v:formulas>` +
+		`<v:path textpathok="t" o:connecttype="custom" o:connectlocs="@9,0;@10,10800;@11,21600;@12,10800" o:connectangles="270,180,90,0"/>` +
+		`<v:textpath on="t" fitshape="t"/><v:handles><v:h position="#0,bottomRight" xrange="6629,14971"/></v:handles>` +
+		`<o:lock v:ext="edit" text="t" shapetype="t"/></v:shapetype>` +
+		`<v:shape id="` + watermarkShapeID + `1" o:spid="_x0000_s2049" type="#_x0000_t136" ` +
+		`style="position:absolute;margin-left:0;margin-top:0;width:` + strconv.FormatFloat(width, 'f', 1, 64) + `pt;height:` + strconv.FormatFloat(height, 'f', 1, 64) + `pt;` + rotation +
+		`z-index:-251657216;mso-position-horizontal:center;mso-position-horizontal-relative:margin;mso-position-vertical:center;mso-position-vertical-relative:margin" ` +
+		`o:allowincell="f" fillcolor="#` + opts.Color + `" stroked="f">` +
+		`<v:fill opacity="` + strconv.FormatFloat(opts.Opacity, 'f', -1, 64) + `"/>` +
+		`<v:textpath style="font-family:&quot;` + escapedFont.String() + `&quot;;font-size:1pt" string="` + escapedText.String() + `"/>` +
+		`<w10:wrap anchorx="margin" anchory="margin"/></v:shape></w:pict></w:r></w:p>`
+}
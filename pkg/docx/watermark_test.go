@@ -0,0 +1,101 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetWatermark(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("First section")
+	doc.AddParagraph("Second section")
+	doc.Body.Paragraphs[0].Props = &PProps{SectPr: &RawElement{}}
+
+	if err := doc.SetWatermark("DRAFT & FINAL", WithWatermarkColor("FF0000"), WithWatermarkOpacity(0.25)); err != nil {
+		t.Fatalf("SetWatermark failed: %v", err)
+	}
+
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	doc2, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+	if !doc2.HasWatermark() {
+		t.Fatal("Expected watermark to survive save")
+	}
+
+	// Each section gets its own default header holding the watermark
+	for _, index := range []int{0, 1} {
+		refs := headerReferences(doc2.SectionAt(index))
+		rel, ok := doc2.findRelationship(refs["default"])
+		if !ok {
+			t.Fatalf("Section %d: expected a default header, got %v", index, refs)
+		}
+		header, _ := doc2.GetPart("word/" + rel.Target)
+		for _, want := range []string{`string="DRAFT &amp; FINAL"`, `fillcolor="#FF0000"`, `opacity="0.25"`, "rotation:315", `xmlns:v=`} {
+			if !strings.Contains(string(header), want) {
+				t.Errorf("Section %d: expected header to contain %s, got: %s", index, want, header)
+			}
+		}
+	}
+	contentTypes, _ := doc2.GetPart(contentTypesPart)
+	if !strings.Contains(string(contentTypes), contentTypeHeader) {
+		t.Error("Expected header content type to be registered")
+	}
+
+	// Setting a watermark again replaces it instead of stacking another
+	if err := doc2.SetWatermark("COPY", WithWatermarkHorizontal()); err != nil {
+		t.Fatalf("SetWatermark failed: %v", err)
+	}
+	rel, _ := doc2.findRelationship(headerReferences(doc2.SectionAt(1))["default"])
+	header, _ := doc2.GetPart("word/" + rel.Target)
+	if n := strings.Count(string(header), watermarkShapeID); n != 1 {
+		t.Errorf("Expected 1 watermark shape, got %d", n)
+	}
+	if strings.Contains(string(header), "rotation") {
+		t.Error("Expected horizontal watermark")
+	}
+
+	if err := doc2.RemoveWatermark(); err != nil {
+		t.Fatalf("RemoveWatermark failed: %v", err)
+	}
+	if doc2.HasWatermark() {
+		t.Error("Expected watermark to be removed")
+	}
+}
+
+func TestSetWatermarkSharedHeader(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("First section")
+	doc.AddParagraph("Second section")
+	if err := doc.SetWatermark("DRAFT"); err != nil {
+		t.Fatalf("SetWatermark failed: %v", err)
+	}
+
+	// A second section reusing the body's header is stamped only once
+	shared := &RawElement{Inner: append([]byte(nil), doc.Body.SectPr.Inner...)}
+	doc.Body.Paragraphs[0].Props = &PProps{SectPr: shared}
+	if err := doc.SetWatermark("DRAFT"); err != nil {
+		t.Fatalf("SetWatermark failed: %v", err)
+	}
+	header, _ := doc.GetPart("word/header1.xml")
+	if n := strings.Count(string(header), watermarkShapeID); n != 1 {
+		t.Errorf("Expected 1 watermark shape, got %d", n)
+	}
+	if _, exists := doc.GetPart("word/header2.xml"); exists {
+		t.Error("Expected no extra header part")
+	}
+}
+
+func TestSetWatermarkErrors(t *testing.T) {
+	doc := New()
+	if err := doc.SetWatermark("  "); err == nil {
+		t.Error("Expected error for empty text")
+	}
+	if err := doc.SetWatermark("DRAFT", WithWatermarkOpacity(1.5)); err == nil {
+		t.Error("Expected error for invalid opacity")
+	}
+}
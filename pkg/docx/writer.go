@@ -2,105 +2,333 @@ package docx
 
 import (
 	"archive/zip"
+	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 )
 
 // Save saves the document to a file
 func (d *Document) Save(filePath string) error {
+	if d.savesOverSource(filePath) {
+		// Large media parts are still read from the file: write the new one
+		// beside it and swap them only once it is complete
+		return d.SaveAs(filePath, WithOverwrite())
+	}
+
 	// Create output file
 	outFile, err := os.Create(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to create output file: %w", err)
 	}
-	defer outFile.Close()
 
-	// Create zip writer
-	zipWriter := zip.NewWriter(outFile)
-	defer zipWriter.Close()
+	if _, err := d.WriteTo(outFile); err != nil {
+		outFile.Close()
+		return err
+	}
+	if err := outFile.Close(); err != nil {
+		return fmt.Errorf("failed to close output file: %w", err)
+	}
 
-	// Marshal the body back to XML
-	documentXML, err := d.marshalDocument()
+	return nil
+}
+
+// savesOverSource reports whether filePath is the file large media parts of
+// the document are read from
+func (d *Document) savesOverSource(filePath string) bool {
+	if d.source == nil {
+		return false
+	}
+	target, err := os.Stat(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to marshal document: %w", err)
+		return false
+	}
+	source, err := d.source.Stat()
+	return err == nil && os.SameFile(source, target)
+}
+
+// WriteTo writes the document as a .docx archive to w. Everything is built in
+// memory, so it works where the filesystem is read-only; large media parts
+// left in the file by Open are copied from it as they are, without being
+// decompressed.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+
+	// Create zip writer
+	zipWriter := zip.NewWriter(cw)
+
+	// Headers and footers set with SetHeader and SetFooter become parts,
+	// referenced from the section properties written with the body
+	if err := d.writeHeaderFooterParts(); err != nil {
+		return cw.n, err
 	}
 
-	// Update the document.xml in files map
-	d.files["word/document.xml"] = documentXML
+	if !d.hasPart(documentPart) {
+		d.SetPart(documentPart, nil)
+	}
 
-	// Write all files back to the zip
-	for name, data := range d.files {
-		if err := saveZipFile(zipWriter, name, data); err != nil {
-			return fmt.Errorf("failed to save file %s: %w", name, err)
+	// Write all files back to the zip, in a stable order
+	for _, name := range d.PartNames() {
+		if name == documentPart {
+			if err := d.writeDocumentPart(zipWriter); err != nil {
+				return cw.n, err
+			}
+			continue
+		}
+		if f, ok := d.lazy[name]; ok {
+			if err := zipWriter.Copy(f); err != nil {
+				return cw.n, fmt.Errorf("failed to copy file %s: %w", name, err)
+			}
+			continue
+		}
+		if err := saveZipFile(zipWriter, name, d.files[name]); err != nil {
+			return cw.n, fmt.Errorf("failed to save file %s: %w", name, err)
 		}
 	}
 
+	if err := zipWriter.Close(); err != nil {
+		return cw.n, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return cw.n, nil
+}
+
+// writeDocumentPart encodes the body straight into the archive. The part
+// as read is stale from then on, so it is dropped rather than replaced with
+// a copy of the body.
+func (d *Document) writeDocumentPart(zw *zip.Writer) error {
+	fw, err := zw.Create(documentPart)
+	if err != nil {
+		return fmt.Errorf("failed to save file %s: %w", documentPart, err)
+	}
+	if err := d.encodeDocument(fw); err != nil {
+		return fmt.Errorf("failed to marshal document: %w", err)
+	}
+	d.SetPart(documentPart, nil)
 	return nil
 }
 
-// SaveAs saves the document to a new file
-func (d *Document) SaveAs(filePath string) error {
-	return d.Save(filePath)
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// ErrFileExists is returned by SaveAs when the target exists and overwriting was not requested
+var ErrFileExists = errors.New("file already exists")
+
+// Exporter writes a document to a file in a format other than DOCX
+type Exporter func(d *Document, filePath string) error
+
+var (
+	exportersMu sync.RWMutex
+	exporters   = make(map[string]Exporter)
+)
+
+// RegisterExporter makes a format available to SaveAs for files with the given
+// extension (e.g. ".pdf"). The converter package registers PDF, Markdown and
+// HTML exporters when imported.
+func RegisterExporter(ext string, fn Exporter) {
+	exportersMu.Lock()
+	defer exportersMu.Unlock()
+	exporters[strings.ToLower(ext)] = fn
+}
+
+// SaveOption configures SaveAs
+type SaveOption func(*saveOptions)
+
+type saveOptions struct {
+	overwrite bool
+}
+
+// WithOverwrite allows SaveAs to replace an existing file
+func WithOverwrite() SaveOption {
+	return func(o *saveOptions) {
+		o.overwrite = true
+	}
+}
+
+// SaveAs saves the document to a new file, choosing the format from the file
+// extension. Saving as .docx, .docm, .dotx or .dotm writes that type of Word
+// package (see SetType) without changing the document; a document with macros
+// can only be saved as .docm or .dotm. Other formats need a registered
+// exporter (see RegisterExporter). An existing file is only replaced when WithOverwrite is
+// given. The output is written to a temporary file in the same directory and
+// moved into place, so a failed save never leaves a partial file behind.
+func (d *Document) SaveAs(filePath string, opts ...SaveOption) error {
+	o := saveOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ext := strings.ToLower(filepath.Ext(filePath))
+	write := d.Save
+	if t, ok := TypeForExtension(ext); ok {
+		if t != d.Type() {
+			if d.HasMacros() && !t.MacroEnabled() {
+				return fmt.Errorf("%w: %s files cannot hold the document's macros; save as .docm or .dotm, or remove them with SetType", ErrUnsupportedFormat, ext)
+			}
+			converted := d.Clone()
+			if err := converted.SetType(t); err != nil {
+				return err
+			}
+			write = converted.Save
+		}
+	} else {
+		exportersMu.RLock()
+		exporter, ok := exporters[ext]
+		exportersMu.RUnlock()
+		if !ok {
+			return fmt.Errorf("%w: cannot save %q files (PDF, Markdown and HTML need the converter package)", ErrUnsupportedFormat, ext)
+		}
+		write = func(path string) error { return exporter(d, path) }
+	}
+
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(filePath); err == nil {
+		if !o.overwrite {
+			return fmt.Errorf("%w: %s", ErrFileExists, filePath)
+		}
+		mode = info.Mode().Perm()
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.tmp"+ext)
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(tmpPath)
+
+	if err := write(tmpPath); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+
+	if !o.overwrite {
+		// A hard link fails if the target appeared since the check above
+		if err := os.Link(tmpPath, filePath); err == nil {
+			return nil
+		} else if os.IsExist(err) {
+			return fmt.Errorf("%w: %s", ErrFileExists, filePath)
+		}
+		// Fall back to rename on filesystems without hard links
+	}
+
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		return fmt.Errorf("failed to move file into place: %w", err)
+	}
+	return nil
 }
 
 // marshalDocument marshals the document body to XML
 func (d *Document) marshalDocument() ([]byte, error) {
+	buf := getXMLBuffer()
+	defer putXMLBuffer(buf)
+	if err := d.encodeDocument(buf); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+// xmlBuffers holds buffers reused between calls to marshalDocument, so the
+// body of a large document isn't built in a freshly grown buffer every time
+var xmlBuffers = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// maxPooledBuffer is the capacity above which buffers aren't kept for reuse
+const maxPooledBuffer = 64 << 20
+
+func getXMLBuffer() *bytes.Buffer {
+	buf := xmlBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putXMLBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBuffer {
+		xmlBuffers.Put(buf)
+	}
+}
+
+// encodeDocument writes the document body as XML to w
+func (d *Document) encodeDocument(w io.Writer) error {
 	// Define the document structure with namespace
 	type WBody struct {
-		XMLName    xml.Name    `xml:"w:body"`
-		Paragraphs []Paragraph `xml:"w:p"`
-		Tables     []Table     `xml:"w:tbl"`
+		XMLName xml.Name      `xml:"w:body"`
+		Content []interface{} // Paragraphs, tables and content controls in document order
+		SectPr  *RawElement   `xml:"w:sectPr,omitempty"`
 	}
 
+	// Prefixes used by preserved drawing, VML and math markup are declared on the root
 	type WDocument struct {
-		XMLName xml.Name `xml:"w:document"`
-		Xmlns   string   `xml:"xmlns:w,attr"`
-		XmlnsR  string   `xml:"xmlns:r,attr"`
-		Body    WBody    `xml:"w:body"`
+		XMLName  xml.Name `xml:"w:document"`
+		Xmlns    string   `xml:"xmlns:w,attr"`
+		XmlnsR   string   `xml:"xmlns:r,attr"`
+		XmlnsWP  string   `xml:"xmlns:wp,attr"`
+		XmlnsA   string   `xml:"xmlns:a,attr"`
+		XmlnsPic string   `xml:"xmlns:pic,attr"`
+		XmlnsWPS string   `xml:"xmlns:wps,attr"`
+		XmlnsMC  string   `xml:"xmlns:mc,attr"`
+		XmlnsV   string   `xml:"xmlns:v,attr"`
+		XmlnsO   string   `xml:"xmlns:o,attr"`
+		XmlnsW10 string   `xml:"xmlns:w10,attr"`
+		XmlnsM   string   `xml:"xmlns:m,attr"`
+		Body     WBody    `xml:"w:body"`
 	}
 
 	doc := WDocument{
-		Xmlns:  "http://schemas.openxmlformats.org/wordprocessingml/2006/main",
-		XmlnsR: "http://schemas.openxmlformats.org/officeDocument/2006/relationships",
+		Xmlns:    "http://schemas.openxmlformats.org/wordprocessingml/2006/main",
+		XmlnsR:   "http://schemas.openxmlformats.org/officeDocument/2006/relationships",
+		XmlnsWP:  "http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing",
+		XmlnsA:   "http://schemas.openxmlformats.org/drawingml/2006/main",
+		XmlnsPic: "http://schemas.openxmlformats.org/drawingml/2006/picture",
+		XmlnsWPS: "http://schemas.microsoft.com/office/word/2010/wordprocessingShape",
+		XmlnsMC:  "http://schemas.openxmlformats.org/markup-compatibility/2006",
+		XmlnsV:   "urn:schemas-microsoft-com:vml",
+		XmlnsO:   "urn:schemas-microsoft-com:office:office",
+		XmlnsW10: "urn:schemas-microsoft-com:office:word",
+		XmlnsM:   mathNamespace,
 		Body: WBody{
-			Paragraphs: d.Body.Paragraphs,
-			Tables:     d.Body.Tables,
+			Content: d.Body.content(),
+			SectPr:  d.Body.SectPr,
 		},
 	}
 
-	// Marshal with proper XML header
-	output, err := xml.MarshalIndent(doc, "", "  ")
-	if err != nil {
-		return nil, err
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
 	}
-
-	// Add XML header
-	xmlHeader := []byte(xml.Header)
-	return append(xmlHeader, output...), nil
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
 }
 
 // ToBytes returns the document as bytes
 func (d *Document) ToBytes() ([]byte, error) {
-	// Create a temporary file
-	tmpFile, err := os.CreateTemp("", "docx-*.docx")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	// Size the buffer from the uncompressed parts, an upper bound in practice
+	size := 0
+	for _, data := range d.files {
+		size += len(data)
 	}
-	tmpPath := tmpFile.Name()
-	tmpFile.Close()
-	defer os.Remove(tmpPath)
-
-	// Save to temp file
-	if err := d.Save(tmpPath); err != nil {
-		return nil, err
+	for _, f := range d.lazy {
+		size += int(f.CompressedSize64)
 	}
 
-	// Read back the file
-	data, err := os.ReadFile(tmpPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read temp file: %w", err)
+	var buf bytes.Buffer
+	buf.Grow(size)
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, err
 	}
-
-	return data, nil
+	return buf.Bytes(), nil
 }
@@ -0,0 +1,115 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// largeDocument builds a document of many formatted paragraphs and tables
+func largeDocument(paragraphs int) *Document {
+	doc := New()
+	for i := 0; i < paragraphs; i++ {
+		doc.AddParagraph(fmt.Sprintf("Paragraph %d of a long report, with enough text to be realistic.", i), WithBold(), WithStyle("Normal"))
+		if i%100 == 0 {
+			table := doc.AddTable(10, 4)
+			for r := 0; r < 10; r++ {
+				for c := 0; c < 4; c++ {
+					table.SetCellText(r, c, fmt.Sprintf("R%dC%d", r, c))
+				}
+			}
+		}
+	}
+	return doc
+}
+
+func TestWriteToDocumentPart(t *testing.T) {
+	doc := largeDocument(300)
+	var first bytes.Buffer
+	if _, err := doc.WriteTo(&first); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(first.Bytes()), int64(first.Len()))
+	if err != nil {
+		t.Fatalf("Invalid archive: %v", err)
+	}
+	var written []byte
+	for _, f := range archive.File {
+		if f.Name == documentPart {
+			written, _ = readZipFile(f)
+		}
+	}
+	stored, _ := doc.GetPart(documentPart)
+	if len(written) == 0 || !bytes.Equal(written, stored) {
+		t.Fatalf("Expected the document part to be the saved body (%d and %d bytes)", len(written), len(stored))
+	}
+	if len(doc.files[documentPart]) != 0 {
+		t.Errorf("Expected the saved body not to be kept, got %d bytes", len(doc.files[documentPart]))
+	}
+
+	// Buffers reused by later calls don't change what was returned
+	kept := bytes.Clone(stored)
+	largeDocument(50).GetPart(documentPart)
+	if !bytes.Equal(stored, kept) {
+		t.Error("Expected the document part not to share a pooled buffer")
+	}
+
+	// Edits since the save show in the part
+	doc.AddParagraph("Added after saving")
+	if part, _ := doc.GetPart(documentPart); !bytes.Contains(part, []byte("Added after saving")) {
+		t.Error("Expected the document part to follow the body once saved")
+	}
+
+	// The part is the body even before the first save
+	opened, err := ReadBytes(first.Bytes())
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+	opened.AddParagraph("Added before saving")
+	if part, _ := opened.GetPart(documentPart); !bytes.Contains(part, []byte("Added before saving")) {
+		t.Error("Expected the document part to follow the body before saving")
+	}
+
+	var second bytes.Buffer
+	if _, err := doc.WriteTo(&second); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	reopened, err := ReadBytes(second.Bytes())
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+	if reopened.GetParagraphCount() != 301 || len(reopened.Body.Tables) != 3 {
+		t.Errorf("Expected 301 paragraphs and 3 tables, got %d and %d", reopened.GetParagraphCount(), len(reopened.Body.Tables))
+	}
+}
+
+func BenchmarkGetDocumentPart(b *testing.B) {
+	for _, n := range []int{1000, 10000} {
+		doc := largeDocument(n)
+		b.Run(fmt.Sprintf("paragraphs=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, ok := doc.GetPart(documentPart); !ok {
+					b.Fatal("GetPart failed")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkWriteTo(b *testing.B) {
+	for _, n := range []int{1000, 10000} {
+		doc := largeDocument(n)
+		b.Run(fmt.Sprintf("paragraphs=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := doc.WriteTo(io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,728 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// XMLNode is an element, attribute or text QueryXPath found
+type XMLNode struct {
+	// Name is the name of an element as written, e.g. "w:t", of an
+	// attribute with a leading "@", e.g. "@w:val", or "text()" for text
+	Name string
+
+	// Value is the text of an element and its descendants, the value of an
+	// attribute or the text itself
+	Value string
+
+	// XML is the markup of an element as it is in the part; empty for
+	// attributes and text
+	XML string
+}
+
+// QueryXPath returns the nodes of a part an XPath expression selects, in
+// document order. Parts are queried as they are stored, except the main
+// document part, which is always the body as it is, edits included.
+//
+// The expression is a path of steps separated by / or // (any depth),
+// starting from the root of the part, or from its root element when it
+// doesn't start with /. A step is an element name, * for any element, ..
+// for the parent or ., and the last step may be @name for an attribute or
+// text() for the text of the elements selected. Prefixes are compared as
+// written, without resolving namespaces; a name without one matches any
+// prefix. Element steps take predicates:
+//
+//	[2], [last()]               position among the matches of the step
+//	[@w:val], [w:rPr]           has the attribute or child element
+//	[@w:val='Heading1']         attribute, child, text() or . equals (or !=) a string
+//	[contains(., 'Total')]      contains and starts-with
+//	[not(w:rPr)]                negation
+//
+// Predicates look at the element and its children only: rather than
+// //w:p[w:pPr/w:pStyle/@w:val='Title'], write
+// //w:pStyle[@w:val='Title']/../.., or //pStyle[@val='Title']/../.. to
+// match the main document both as Word and as docxsmith write it.
+func (d *Document) QueryXPath(part, expr string) ([]XMLNode, error) {
+	path, err := parseXPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := d.GetPart(part)
+	if !ok {
+		return nil, fmt.Errorf("part %s not found", part)
+	}
+	root, err := parseXMLTree(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", part, err)
+	}
+
+	var nodes []XMLNode
+	elements, last := path.selectElements(root)
+	for _, n := range elements {
+		switch last.axis {
+		case axisAttribute:
+			for _, a := range n.attrs {
+				if last.matches(a.name) {
+					nodes = append(nodes, XMLNode{Name: "@" + a.name, Value: a.value})
+				}
+			}
+		case axisText:
+			if n.text.Len() > 0 {
+				nodes = append(nodes, XMLNode{Name: "text()", Value: n.text.String()})
+			}
+		default:
+			nodes = append(nodes, XMLNode{Name: n.name, Value: n.value.String(), XML: string(data[n.start:n.end])})
+		}
+	}
+	return nodes, nil
+}
+
+// PatchXML changes the nodes of a part an XPath expression selects, as
+// QueryXPath would select them, and returns how many it changed. When the
+// last step is @name, the attribute is set to newValue on every element
+// selected, and added where missing; when it is text(), the content of the
+// elements is replaced with newValue as text. Otherwise the elements are
+// replaced with newValue as markup, or removed when it is empty; where
+// selected elements nest, the outer one is replaced. The part must still be
+// well-formed afterwards. Patching the main document part patches the body
+// as it is and reloads it, so paragraphs and tables held from before are
+// stale.
+func (d *Document) PatchXML(part, expr, newValue string) (int, error) {
+	path, err := parseXPath(expr)
+	if err != nil {
+		return 0, err
+	}
+	data, ok := d.GetPart(part)
+	if !ok {
+		return 0, fmt.Errorf("part %s not found", part)
+	}
+	root, err := parseXMLTree(data)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", part, err)
+	}
+
+	elements, last := path.selectElements(root)
+	var edits []xmlEdit
+	for _, n := range elements {
+		switch last.axis {
+		case axisAttribute:
+			if last.name == "*" {
+				return 0, fmt.Errorf("%w: %s: PatchXML needs the name of the attribute", ErrInvalidXPath, expr)
+			}
+			edits = append(edits, n.setAttr(last.name, newValue))
+		case axisText:
+			edits = append(edits, n.setText(newValue))
+		default:
+			edits = append(edits, xmlEdit{n.start, n.end, newValue})
+		}
+	}
+	if len(edits) == 0 {
+		return 0, nil
+	}
+
+	patched, count := applyXMLEdits(data, edits)
+	if _, err := parseXMLTree(patched); err != nil {
+		return 0, fmt.Errorf("patch would leave %s malformed: %w", part, err)
+	}
+	if part == documentPart {
+		if err := d.parseDocument(patched); err != nil {
+			return 0, fmt.Errorf("failed to parse patched document: %w", err)
+		}
+	}
+	d.SetPart(part, patched)
+	return count, nil
+}
+
+// xmlTreeNode is an element of a part, with where it is in the part
+type xmlTreeNode struct {
+	name     string // As written, e.g. "w:p"; empty for the root of the part
+	attrs    []xmlTreeAttr
+	parent   *xmlTreeNode
+	children []*xmlTreeNode
+	text     strings.Builder // Text directly inside the element
+	value    strings.Builder // Text of the element and its descendants
+
+	start, end  int // The whole element
+	tagEnd      int // End of the start tag
+	closeStart  int // Start of the end tag; tagEnd for empty elements
+	selfClosing bool
+}
+
+// xmlTreeAttr is an attribute of an element, with where its value is
+type xmlTreeAttr struct {
+	name, value string
+	start, end  int // The value, inside its quotes
+}
+
+// xmlAttrPattern matches the attributes of a start tag
+var xmlAttrPattern = regexp.MustCompile(`([^\s=<>/]+)\s*=\s*("[^"]*"|'[^']*')`)
+
+// parseXMLTree parses a part into a tree of its elements, under a node
+// standing for the part itself
+func parseXMLTree(data []byte) (*xmlTreeNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	root := &xmlTreeNode{end: len(data)}
+	current := root
+	offset := 0
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		next := int(dec.InputOffset())
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if current == root && len(root.children) > 0 {
+				return nil, fmt.Errorf("more than one root element")
+			}
+			n := &xmlTreeNode{
+				name:        qualifiedName(t.Name),
+				parent:      current,
+				start:       offset,
+				tagEnd:      next,
+				selfClosing: bytes.HasSuffix(data[offset:next], []byte("/>")),
+			}
+			for i, m := range xmlAttrPattern.FindAllSubmatchIndex(data[offset:next], -1) {
+				if i >= len(t.Attr) {
+					break
+				}
+				n.attrs = append(n.attrs, xmlTreeAttr{
+					name:  qualifiedName(t.Attr[i].Name),
+					value: t.Attr[i].Value,
+					start: offset + m[4] + 1,
+					end:   offset + m[5] - 1,
+				})
+			}
+			current.children = append(current.children, n)
+			current = n
+		case xml.EndElement:
+			if current == root || qualifiedName(t.Name) != current.name {
+				return nil, fmt.Errorf("unexpected end element %s", qualifiedName(t.Name))
+			}
+			current.closeStart = offset
+			if current.selfClosing {
+				current.closeStart = current.tagEnd
+			}
+			current.end = next
+			current = current.parent
+		case xml.CharData:
+			if current != root {
+				current.text.Write(t)
+				for n := current; n != root; n = n.parent {
+					n.value.Write(t)
+				}
+			}
+		}
+		offset = next
+	}
+	if current != root {
+		return nil, fmt.Errorf("unclosed element %s", current.name)
+	}
+	if len(root.children) == 0 {
+		return nil, fmt.Errorf("no root element")
+	}
+	return root, nil
+}
+
+// qualifiedName returns a name as written, with its prefix
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// xmlEdit replaces the bytes from start to end of a part with text
+type xmlEdit struct {
+	start, end int
+	text       string
+}
+
+// setAttr returns the edit setting an attribute of the element, adding it
+// at the end of the start tag if missing
+func (n *xmlTreeNode) setAttr(name, value string) xmlEdit {
+	test := xpathStep{name: name}
+	for _, a := range n.attrs {
+		if test.matches(a.name) {
+			return xmlEdit{a.start, a.end, xmlEscapedString(value)}
+		}
+	}
+	at := n.tagEnd - 1
+	if n.selfClosing {
+		at--
+	}
+	return xmlEdit{at, at, fmt.Sprintf(` %s="%s"`, name, xmlEscapedString(value))}
+}
+
+// setText returns the edit replacing the content of the element with text
+func (n *xmlTreeNode) setText(text string) xmlEdit {
+	if n.selfClosing {
+		return xmlEdit{n.tagEnd - 2, n.tagEnd, ">" + xmlEscapedString(text) + "</" + n.name + ">"}
+	}
+	return xmlEdit{n.tagEnd, n.closeStart, xmlEscapedString(text)}
+}
+
+// applyXMLEdits applies edits to data, skipping those inside another, and
+// returns the result and how many were applied
+func applyXMLEdits(data []byte, edits []xmlEdit) ([]byte, int) {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var out bytes.Buffer
+	pos, count := 0, 0
+	for _, e := range edits {
+		if e.start < pos {
+			continue
+		}
+		out.Write(data[pos:e.start])
+		out.WriteString(e.text)
+		pos = e.end
+		count++
+	}
+	out.Write(data[pos:])
+	return out.Bytes(), count
+}
+
+// XPath step axes
+const (
+	axisChild      = iota // name, *
+	axisDescendant        // //name
+	axisSelf              // .
+	axisParent            // ..
+	axisAttribute         // @name, last step only
+	axisText              // text(), last step only
+)
+
+// xpathStep is a step of an XPath expression
+type xpathStep struct {
+	axis  int
+	name  string // Name test of element and attribute steps; "*" for any
+	preds []xpathPredicate
+}
+
+// xpathPredicate is a predicate of an element step
+type xpathPredicate struct {
+	position int  // [n]; 0 for other predicates
+	last     bool // [last()]
+	negate   bool // not(...)
+
+	operand string // "@name", "text()", "." or a child name
+	fn      string // "=", "!=", "contains", "starts-with"; empty to test existence
+	literal string
+}
+
+// xpathPath is a parsed XPath expression
+type xpathPath struct {
+	relative bool // Starts from the root element rather than the root of the part
+	steps    []xpathStep
+}
+
+// parseXPath parses the XPath subset QueryXPath describes
+func parseXPath(expr string) (*xpathPath, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s: %s", ErrInvalidXPath, expr, fmt.Sprintf(format, args...))
+	}
+	s := strings.TrimSpace(expr)
+	if s == "" {
+		return nil, invalid("empty expression")
+	}
+	path := &xpathPath{relative: !strings.HasPrefix(s, "/")}
+
+	// Split into steps at slashes outside predicates and quotes
+	descendant := false
+	for len(s) > 0 {
+		if strings.HasPrefix(s, "//") {
+			descendant, s = true, s[2:]
+		} else if strings.HasPrefix(s, "/") {
+			s = s[1:]
+		}
+		end, depth := 0, 0
+		var quote byte
+		for ; end < len(s); end++ {
+			c := s[end]
+			switch {
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+			case c == '\'' || c == '"':
+				quote = c
+			case c == '[':
+				depth++
+			case c == ']':
+				depth--
+			case c == '/' && depth == 0:
+				goto split
+			}
+		}
+	split:
+		if quote != 0 || depth != 0 {
+			return nil, invalid("unbalanced brackets or quotes")
+		}
+		text := strings.TrimSpace(s[:end])
+		s = s[end:]
+		if text == "" {
+			return nil, invalid("empty step")
+		}
+
+		step, err := parseXPathStep(text)
+		if err != nil {
+			return nil, invalid("%v", err)
+		}
+		if descendant {
+			if step.axis != axisChild && step.axis != axisAttribute && step.axis != axisText {
+				return nil, invalid("// before %s", text)
+			}
+			if step.axis == axisChild {
+				step.axis = axisDescendant
+			} else {
+				// //@name and //text() select from any element
+				path.steps = append(path.steps, xpathStep{axis: axisDescendant, name: "*"})
+			}
+		}
+		descendant = false
+		if n := len(path.steps); n > 0 && (path.steps[n-1].axis == axisAttribute || path.steps[n-1].axis == axisText) {
+			return nil, invalid("%s must be the last step", path.steps[n-1].String())
+		}
+		path.steps = append(path.steps, step)
+	}
+	if len(path.steps) == 0 {
+		return nil, invalid("no steps")
+	}
+	return path, nil
+}
+
+// parseXPathStep parses a step and its predicates
+func parseXPathStep(text string) (xpathStep, error) {
+	name, rest := text, ""
+	if open := strings.IndexByte(text, '['); open >= 0 {
+		name, rest = strings.TrimSpace(text[:open]), text[open:]
+	}
+
+	var step xpathStep
+	switch {
+	case name == ".":
+		step.axis = axisSelf
+	case name == "..":
+		step.axis = axisParent
+	case name == "text()":
+		step.axis = axisText
+	case strings.HasPrefix(name, "@"):
+		step.axis, step.name = axisAttribute, name[1:]
+	default:
+		step.axis, step.name = axisChild, name
+	}
+	if (step.axis == axisChild || step.axis == axisAttribute) && !isXPathName(step.name) {
+		return step, fmt.Errorf("invalid name %q", name)
+	}
+
+	for rest != "" {
+		end := predicateEnd(rest)
+		if !strings.HasPrefix(rest, "[") || end < 0 {
+			return step, fmt.Errorf("invalid predicate %q", rest)
+		}
+		if step.axis != axisChild {
+			return step, fmt.Errorf("predicates only apply to elements")
+		}
+		pred, err := parseXPathPredicate(strings.TrimSpace(rest[1:end]))
+		if err != nil {
+			return step, err
+		}
+		step.preds = append(step.preds, pred)
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+	return step, nil
+}
+
+// predicateEnd returns the index of the bracket closing the predicate s
+// starts with, or -1
+func predicateEnd(s string) int {
+	var quote byte
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseXPathPredicate parses the inside of a predicate
+func parseXPathPredicate(s string) (xpathPredicate, error) {
+	var pred xpathPredicate
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 1 {
+			return pred, fmt.Errorf("positions start at 1")
+		}
+		pred.position = n
+		return pred, nil
+	}
+	if s == "last()" {
+		pred.last = true
+		return pred, nil
+	}
+	if inner, ok := xpathCall(s, "not"); ok {
+		pred, err := parseXPathPredicate(strings.TrimSpace(inner))
+		if err != nil {
+			return pred, err
+		}
+		if pred.position > 0 || pred.last {
+			return pred, fmt.Errorf("not() takes no position")
+		}
+		pred.negate = !pred.negate
+		return pred, nil
+	}
+
+	for _, fn := range []string{"contains", "starts-with"} {
+		args, ok := xpathCall(s, fn)
+		if !ok {
+			continue
+		}
+		comma := strings.IndexByte(args, ',')
+		if comma < 0 {
+			return pred, fmt.Errorf("%s() takes two arguments", fn)
+		}
+		literal, ok := xpathLiteral(strings.TrimSpace(args[comma+1:]))
+		if !ok {
+			return pred, fmt.Errorf("%s() takes a quoted string", fn)
+		}
+		pred.operand, pred.fn, pred.literal = strings.TrimSpace(args[:comma]), fn, literal
+		return pred, checkXPathOperand(pred.operand)
+	}
+
+	pred.operand = s
+	if i := strings.IndexAny(s, "!="); i >= 0 {
+		pred.operand, pred.fn = strings.TrimSpace(s[:i]), "="
+		value := s[i+1:]
+		if s[i] == '!' {
+			if !strings.HasPrefix(value, "=") {
+				return pred, fmt.Errorf("invalid predicate %q", s)
+			}
+			pred.fn, value = "!=", value[1:]
+		}
+		literal, ok := xpathLiteral(strings.TrimSpace(value))
+		if !ok {
+			return pred, fmt.Errorf("compare with a quoted string or a number in %q", s)
+		}
+		pred.literal = literal
+	}
+	return pred, checkXPathOperand(pred.operand)
+}
+
+// xpathCall returns the arguments of a call to fn, if s is one
+func xpathCall(s, fn string) (string, bool) {
+	if !strings.HasPrefix(s, fn+"(") || !strings.HasSuffix(s, ")") {
+		return "", false
+	}
+	return s[len(fn)+1 : len(s)-1], true
+}
+
+// xpathLiteral unquotes a string literal; numbers are taken as they are
+func xpathLiteral(s string) (string, bool) {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return s, true
+	}
+	return "", false
+}
+
+// checkXPathOperand checks what a predicate tests
+func checkXPathOperand(operand string) error {
+	if operand == "." || operand == "text()" || isXPathName(strings.TrimPrefix(operand, "@")) {
+		return nil
+	}
+	return fmt.Errorf("invalid predicate operand %q", operand)
+}
+
+// isXPathName reports whether s is a name, with or without prefix, or *
+func isXPathName(s string) bool {
+	if s == "*" {
+		return true
+	}
+	return s != "" && !strings.ContainsAny(s, " \t\r\n/[]()@='\"!,") && !strings.HasPrefix(s, ":") && !strings.HasSuffix(s, ":")
+}
+
+// String returns the step as written in an expression
+func (s xpathStep) String() string {
+	switch s.axis {
+	case axisAttribute:
+		return "@" + s.name
+	case axisText:
+		return "text()"
+	}
+	return s.name
+}
+
+// matches reports whether a name written in a part matches the name test of
+// the step: a prefixed test compares the whole name, others the local name
+func (s xpathStep) matches(name string) bool {
+	switch {
+	case s.name == "*":
+		return true
+	case strings.Contains(s.name, ":"):
+		return name == s.name
+	}
+	return localName(name) == s.name
+}
+
+// selectElements returns the elements the element steps of the path
+// select, in document order, and the last step
+func (p *xpathPath) selectElements(root *xmlTreeNode) ([]*xmlTreeNode, xpathStep) {
+	context := []*xmlTreeNode{root}
+	if p.relative {
+		context = root.children
+	}
+	steps := p.steps
+	last := steps[len(steps)-1]
+	if last.axis == axisAttribute || last.axis == axisText {
+		steps = steps[:len(steps)-1]
+	}
+
+	for _, step := range steps {
+		seen := make(map[*xmlTreeNode]bool)
+		var next []*xmlTreeNode
+		add := func(n *xmlTreeNode) {
+			if n != nil && !seen[n] {
+				seen[n] = true
+				next = append(next, n)
+			}
+		}
+		for _, n := range context {
+			switch step.axis {
+			case axisSelf:
+				add(n)
+			case axisParent:
+				add(n.parent)
+			case axisChild:
+				for _, c := range step.filter(n.children) {
+					add(c)
+				}
+			case axisDescendant:
+				n.walk(func(d *xmlTreeNode) {
+					for _, c := range step.filter(d.children) {
+						add(c)
+					}
+				})
+			}
+		}
+		sort.SliceStable(next, func(i, j int) bool { return next[i].start < next[j].start })
+		context = next
+	}
+
+	// The root of the part is not an element
+	elements := context[:0:0]
+	for _, n := range context {
+		if n != root {
+			elements = append(elements, n)
+		}
+	}
+	return elements, last
+}
+
+// walk calls fn for the node and each of its descendants, in document order
+func (n *xmlTreeNode) walk(fn func(*xmlTreeNode)) {
+	fn(n)
+	for _, c := range n.children {
+		c.walk(fn)
+	}
+}
+
+// filter returns the candidates matching the name test and predicates of
+// the step, each predicate counting positions among those left by the
+// ones before it
+func (s xpathStep) filter(candidates []*xmlTreeNode) []*xmlTreeNode {
+	var matched []*xmlTreeNode
+	for _, c := range candidates {
+		if s.matches(c.name) {
+			matched = append(matched, c)
+		}
+	}
+	for _, pred := range s.preds {
+		var kept []*xmlTreeNode
+		for i, c := range matched {
+			if pred.holds(c, i+1, len(matched)) {
+				kept = append(kept, c)
+			}
+		}
+		matched = kept
+	}
+	return matched
+}
+
+// holds reports whether the predicate holds for an element at a position
+// among size matches
+func (p xpathPredicate) holds(n *xmlTreeNode, position, size int) bool {
+	switch {
+	case p.position > 0:
+		return position == p.position
+	case p.last:
+		return position == size
+	}
+
+	var values []string
+	switch {
+	case p.operand == ".":
+		values = []string{n.value.String()}
+	case p.operand == "text()":
+		if n.text.Len() > 0 {
+			values = []string{n.text.String()}
+		}
+	case strings.HasPrefix(p.operand, "@"):
+		test := xpathStep{name: p.operand[1:]}
+		for _, a := range n.attrs {
+			if test.matches(a.name) {
+				values = append(values, a.value)
+			}
+		}
+	default:
+		test := xpathStep{name: p.operand}
+		for _, c := range n.children {
+			if test.matches(c.name) {
+				values = append(values, c.value.String())
+			}
+		}
+	}
+
+	result := false
+	switch p.fn {
+	case "":
+		result = len(values) > 0
+	case "=", "!=":
+		for _, v := range values {
+			if (v == p.literal) == (p.fn == "=") {
+				result = true
+			}
+		}
+	case "contains", "starts-with":
+		first := ""
+		if len(values) > 0 {
+			first = values[0]
+		}
+		if p.fn == "contains" {
+			result = strings.Contains(first, p.literal)
+		} else {
+			result = strings.HasPrefix(first, p.literal)
+		}
+	}
+	return result != p.negate
+}
@@ -0,0 +1,185 @@
+package docx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const xpathTestPart = `<?xml version="1.0" encoding="UTF-8"?>
+<c:catalog xmlns:c="urn:catalog" xmlns:x="urn:extra">
+  <c:item id="1" c:kind='book'><c:name>Go &amp; XML</c:name><c:price>10</c:price></c:item>
+  <c:item id="2"><c:name>Word Tips</c:name><c:price>12</c:price><c:note/></c:item>
+  <x:item id="3"><c:name>Other</c:name></x:item>
+</c:catalog>`
+
+func TestQueryXPath(t *testing.T) {
+	doc := New()
+	doc.SetPart("customXml/item1.xml", []byte(xpathTestPart))
+
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"/c:catalog/c:item/c:name", []string{"Go & XML", "Word Tips"}},
+		{"//c:name", []string{"Go & XML", "Word Tips", "Other"}},
+		{"item/name", []string{"Go & XML", "Word Tips", "Other"}},
+		{"//c:item[2]/c:name", []string{"Word Tips"}},
+		{"//*[last()]/c:name", []string{"Other"}},
+		{"//c:item[@id='2']/c:price", []string{"12"}},
+		{"//c:item[c:note]/@id", []string{"2"}},
+		{"//c:item[not(c:note)]/@id", []string{"1"}},
+		{"//c:name[contains(., 'Tips')]/../@id", []string{"2"}},
+		{"//c:name[starts-with(text(), 'Go')]/text()", []string{"Go & XML"}},
+		{"//c:item[c:price!='10'][1]/@id", []string{"2"}},
+		{"//@c:kind", []string{"book"}},
+		{"//c:note/text()", nil},
+	}
+	for _, tt := range tests {
+		nodes, err := doc.QueryXPath("customXml/item1.xml", tt.expr)
+		if err != nil {
+			t.Errorf("%s: QueryXPath failed: %v", tt.expr, err)
+			continue
+		}
+		var got []string
+		for _, n := range nodes {
+			got = append(got, n.Value)
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: expected %q, got %q", tt.expr, tt.want, got)
+		}
+	}
+
+	nodes, err := doc.QueryXPath("customXml/item1.xml", "//c:item[1]")
+	if err != nil || len(nodes) != 1 || !strings.HasPrefix(nodes[0].XML, `<c:item id="1" c:kind='book'>`) || !strings.HasSuffix(nodes[0].XML, "</c:item>") {
+		t.Errorf("Expected the markup of the first item as written, got %+v (%v)", nodes, err)
+	}
+
+	// The main document part is the body as docxsmith writes it, which
+	// doesn't prefix the elements inside w:body
+	rescued, _, err := RepairBytes(damagedPackage(t))
+	if err != nil {
+		t.Fatalf("RepairBytes failed: %v", err)
+	}
+	nodes, err = rescued.QueryXPath(documentPart, "/w:document/w:body/sectPr/pgSz/@w")
+	if err != nil || len(nodes) != 1 || nodes[0].Name != "@w:w" || nodes[0].Value != "12240" {
+		t.Errorf("Expected the page width, got %+v (%v)", nodes, err)
+	}
+	doc.AddParagraph("Hello")
+	doc.AddParagraph("World", WithStyle("Heading1"))
+	if _, err := doc.ToBytes(); err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	nodes, err = doc.QueryXPath(documentPart, "//pStyle[@val='Heading1']/../../r/t")
+	if err != nil || len(nodes) != 1 || nodes[0].Value != "World" {
+		t.Errorf("Expected the saved body to be queried, got %+v (%v)", nodes, err)
+	}
+
+	for _, expr := range []string{"", "/", "//c:item[", "//c:item[0]", "//@id/c:name", "//c:item[@id=2x]", "c:item/./[1]"} {
+		if _, err := doc.QueryXPath("customXml/item1.xml", expr); !errors.Is(err, ErrInvalidXPath) {
+			t.Errorf("%q: expected ErrInvalidXPath, got %v", expr, err)
+		}
+	}
+	if _, err := doc.QueryXPath("word/missing.xml", "//w:t"); err == nil {
+		t.Error("Expected an error for a missing part")
+	}
+}
+
+func TestPatchXML(t *testing.T) {
+	doc := New()
+	doc.SetPart("customXml/item1.xml", []byte(xpathTestPart))
+	part := "customXml/item1.xml"
+
+	if n, err := doc.PatchXML(part, "//item/@c:kind", `a "good" one`); err != nil || n != 3 {
+		t.Fatalf("Expected the attribute set on 3 items, got %d (%v)", n, err)
+	}
+	if n, err := doc.PatchXML(part, "//c:note/text()", "Signed"); err != nil || n != 1 {
+		t.Fatalf("Expected the empty note to get text, got %d (%v)", n, err)
+	}
+	if n, err := doc.PatchXML(part, "//c:name[.='Go & XML']/text()", "Go <3"); err != nil || n != 1 {
+		t.Fatalf("Expected a name to change, got %d (%v)", n, err)
+	}
+	if n, err := doc.PatchXML(part, "//x:item", ""); err != nil || n != 1 {
+		t.Fatalf("Expected the item to be removed, got %d (%v)", n, err)
+	}
+	if n, err := doc.PatchXML(part, "//c:item", "<c:item/>"); err != nil || n != 2 {
+		t.Fatalf("Expected both items replaced, got %d (%v)", n, err)
+	}
+
+	data, _ := doc.GetPart(part)
+	if strings.Count(string(data), "<c:item/>") != 2 || strings.Contains(string(data), "x:item") {
+		t.Errorf("Unexpected part after patching: %s", data)
+	}
+	doc.SetPart(part, []byte(xpathTestPart))
+	doc.PatchXML(part, "//item/@c:kind", `a "good" one`)
+	doc.PatchXML(part, "//c:note/text()", "Signed")
+	data, _ = doc.GetPart(part)
+	for _, want := range []string{
+		`<c:item id="1" c:kind='a &#34;good&#34; one'>`, // Quotes as written are kept
+		`<c:item id="2" c:kind="a &#34;good&#34; one">`,
+		`<c:note>Signed</c:note>`,
+		`<x:item id="3" c:kind="a &#34;good&#34; one">`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %s in %s", want, data)
+		}
+	}
+
+	if _, err := doc.PatchXML(part, "//c:item", "<c:item>"); err == nil {
+		t.Error("Expected an error for a patch leaving the part malformed")
+	}
+	if after, _ := doc.GetPart(part); string(after) != string(data) {
+		t.Error("Expected a rejected patch to leave the part as it was")
+	}
+	if _, err := doc.PatchXML(part, "//c:item/@*", "x"); !errors.Is(err, ErrInvalidXPath) {
+		t.Errorf("Expected ErrInvalidXPath for an attribute without a name, got %v", err)
+	}
+
+	// Patching the main document reloads the body
+	doc.AddParagraph("Draft")
+	doc.AddParagraph("Keep")
+	if n, err := doc.PatchXML(documentPart, "//t[.='Draft']/text()", "Final"); err != nil || n != 1 {
+		t.Fatalf("Expected a paragraph to change, got %d (%v)", n, err)
+	}
+	if text, _ := doc.GetParagraphText(0); text != "Final" {
+		t.Errorf("Expected the body to be reloaded, got %q", text)
+	}
+	if n, err := doc.PatchXML(documentPart, "//p[r/t='Keep']", ""); !errors.Is(err, ErrInvalidXPath) || n != 0 {
+		t.Errorf("Expected predicates not to look at grandchildren, got %d (%v)", n, err)
+	}
+}
+
+func TestPatchXMLKeepsEdits(t *testing.T) {
+	doc := New()
+	doc.AddParagraph("Title")
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	opened, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+
+	// Edits made since the document was read or created are in the part
+	for _, d := range []*Document{New(), opened} {
+		d.AddParagraph("Draft")
+		d.AddParagraph("Keep")
+		want := d.GetParagraphCount()
+		if n, err := d.PatchXML(documentPart, "//w:body/@w:foo", "x"); err != nil || n != 1 {
+			t.Fatalf("Expected the body to be patched, got %d (%v)", n, err)
+		}
+		if n, err := d.PatchXML(documentPart, "//t[.='Draft']/text()", "Final"); err != nil || n != 1 {
+			t.Fatalf("Expected a paragraph to change, got %d (%v)", n, err)
+		}
+		if got := d.GetParagraphCount(); got != want {
+			t.Errorf("Expected %d paragraphs after patching, got %d", want, got)
+		}
+		if text, _ := d.GetParagraphText(want - 2); text != "Final" {
+			t.Errorf("Expected the patched paragraph, got %q", text)
+		}
+		if nodes, err := d.QueryXPath(documentPart, "//t[.='Keep']"); err != nil || len(nodes) != 1 {
+			t.Errorf("Expected the added paragraph to be queried, got %+v (%v)", nodes, err)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/converter"
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/template"
+)
+
+// BatchFunc processes one file of a batch, reading inputPath and writing outputPath
+type BatchFunc func(inputPath, outputPath string) error
+
+// BatchOptions holds options for batch processing
+type BatchOptions struct {
+	// Pattern is a glob matched against file names (e.g. "*.docx"); all files match when empty
+	Pattern string
+
+	// Recursive includes files in subdirectories
+	Recursive bool
+
+	// OutputDir receives the results, mirroring the input directory layout.
+	// When empty, results are written next to the inputs, replacing them
+	// unless OutputExt changes the extension.
+	OutputDir string
+
+	// OutputExt replaces the extension of output files (e.g. ".pdf" when converting)
+	OutputExt string
+
+	// Workers is the number of files processed at once; defaults to the number of CPUs
+	Workers int
+
+	// Logger receives a record for each file processed; nil logs nothing
+	Logger *slog.Logger
+
+	// Progress, when set, is called as each file is done, whether it
+	// succeeded or not. Calls come from the workers but never overlap.
+	Progress ProgressFunc
+}
+
+// BatchResult is the outcome of processing one file
+type BatchResult struct {
+	InputPath  string
+	OutputPath string
+	Err        error
+	Duration   time.Duration
+}
+
+// BatchSummary is the outcome of a batch run
+type BatchSummary struct {
+	// Results has one entry per matched file, sorted by input path
+	Results   []BatchResult
+	Succeeded int
+	Failed    int
+	Duration  time.Duration
+}
+
+// Failures returns the results of the files that could not be processed
+func (s *BatchSummary) Failures() []BatchResult {
+	var failures []BatchResult
+	for _, r := range s.Results {
+		if r.Err != nil {
+			failures = append(failures, r)
+		}
+	}
+	return failures
+}
+
+// Batch applies fn to every file in dir matching opts.Pattern using a pool
+// of workers. A file that fails doesn't stop the others; its error is
+// reported in the summary. An error is only returned when dir can't be read
+// or the pattern is invalid.
+func Batch(dir string, fn BatchFunc, opts BatchOptions) (*BatchSummary, error) {
+	return BatchContext(context.Background(), dir, fn, opts)
+}
+
+// BatchContext runs a batch like Batch until ctx is canceled or its
+// deadline passes. Files being processed then are finished, the others are
+// skipped with ctx's error as their result, and the summary is returned
+// along with ctx's error.
+func BatchContext(ctx context.Context, dir string, fn BatchFunc, opts BatchOptions) (*BatchSummary, error) {
+	start := time.Now()
+
+	files, err := findBatchFiles(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	logger := loggerOrDiscard(opts.Logger)
+	logger.Info("batch started", "dir", dir, "files", len(files), "workers", workers)
+
+	results := make([]BatchResult, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[i] = BatchResult{InputPath: files[i], Err: err}
+					continue
+				}
+				r := runBatchFile(dir, files[i], fn, opts)
+				if r.Err != nil {
+					logger.Warn("file failed", "input", r.InputPath, "error", r.Err, "duration", r.Duration)
+				} else {
+					logger.Info("file processed", "input", r.InputPath, "output", r.OutputPath, "duration", r.Duration)
+				}
+				results[i] = r
+
+				mu.Lock()
+				done++
+				reportProgress(opts.Progress, done, len(files), "process")
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range files {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			results[i] = BatchResult{InputPath: files[i], Err: ctx.Err()}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	summary := &BatchSummary{Results: results, Duration: time.Since(start)}
+	for _, r := range results {
+		if r.Err != nil {
+			summary.Failed++
+		} else {
+			summary.Succeeded++
+		}
+	}
+	logger.Info("batch finished", "succeeded", summary.Succeeded, "failed", summary.Failed, elapsed(start))
+	return summary, ctx.Err()
+}
+
+// findBatchFiles returns the files in dir matching the batch pattern, sorted
+func findBatchFiles(dir string, opts BatchOptions) ([]string, error) {
+	if opts.Pattern != "" {
+		if _, err := filepath.Match(opts.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", opts.Pattern, err)
+		}
+	}
+
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && !opts.Recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if opts.Pattern != "" {
+			if ok, _ := filepath.Match(opts.Pattern, d.Name()); !ok {
+				return nil
+			}
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// runBatchFile processes one file and records the outcome
+func runBatchFile(dir, inputPath string, fn BatchFunc, opts BatchOptions) BatchResult {
+	start := time.Now()
+	result := BatchResult{InputPath: inputPath}
+
+	outputPath := inputPath
+	if opts.OutputDir != "" {
+		rel, err := filepath.Rel(dir, inputPath)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		outputPath = filepath.Join(opts.OutputDir, rel)
+	}
+	if opts.OutputExt != "" {
+		outputPath = strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + opts.OutputExt
+	}
+	result.OutputPath = outputPath
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		result.Err = fmt.Errorf("failed to create output directory: %w", err)
+	} else {
+		result.Err = fn(inputPath, outputPath)
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// ReplaceOperation returns a batch operation replacing text in DOCX files
+func ReplaceOperation(oldText, newText string) BatchFunc {
+	return func(inputPath, outputPath string) error {
+		doc, err := docx.Open(inputPath)
+		if err != nil {
+			return err
+		}
+		defer doc.Close()
+		doc.ReplaceText(oldText, newText)
+		return doc.Save(outputPath)
+	}
+}
+
+// TemplateOperation returns a batch operation rendering DOCX templates with data
+func TemplateOperation(data template.Data, opts template.RenderOptions) BatchFunc {
+	return func(inputPath, outputPath string) error {
+		tmpl, err := template.Load(inputPath)
+		if err != nil {
+			return err
+		}
+		defer tmpl.Close()
+		return tmpl.RenderToFile(data, outputPath, opts)
+	}
+}
+
+// ConvertOperation returns a batch operation converting files to the format
+// of the output extension: DOCX to PDF, Markdown or HTML, and PDF to DOCX.
+// Use it with BatchOptions.OutputExt.
+func ConvertOperation(opts converter.ConvertOptions) BatchFunc {
+	return func(inputPath, outputPath string) error {
+		from := fileFormat(inputPath)
+		to := strings.ToLower(filepath.Ext(outputPath))
+
+		switch {
+		case from == ".docx" && to == ".pdf":
+			return converter.ConvertDocxToPDF(inputPath, outputPath, opts)
+		case from == ".docx" && to == ".md":
+			return converter.ConvertDocxToMarkdown(inputPath, outputPath, opts)
+		case from == ".docx" && to == ".html":
+			return converter.ConvertDocxToHTML(inputPath, outputPath, opts)
+		case from == ".pdf" && to == ".docx":
+			return converter.ConvertPDFToDocx(inputPath, outputPath, opts)
+		default:
+			return fmt.Errorf("%w: cannot convert %s to %s", ErrUnsupportedFormat, from, to)
+		}
+	}
+}
+
+// WatermarkOperation returns a batch operation stamping a watermark on DOCX and PDF files
+func WatermarkOperation(text string, opts WatermarkOptions) BatchFunc {
+	return func(inputPath, outputPath string) error {
+		return Watermark(inputPath, outputPath, text, opts)
+	}
+}
@@ -0,0 +1,202 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/converter"
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+func createBatchDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	for _, name := range []string{"a.docx", filepath.Join("sub", "b.docx")} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		doc := docx.New()
+		doc.AddParagraph("Hello OLDNAME")
+		if err := doc.Save(path); err != nil {
+			t.Fatalf("Failed to save %s: %v", name, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.docx"), []byte("not a zip"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	return dir
+}
+
+func TestBatchReplace(t *testing.T) {
+	dir := createBatchDir(t)
+	outDir := filepath.Join(t.TempDir(), "out")
+
+	summary, err := Batch(dir, ReplaceOperation("OLDNAME", "NEWNAME"), BatchOptions{
+		Pattern:   "*.docx",
+		Recursive: true,
+		OutputDir: outDir,
+		Workers:   2,
+	})
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+
+	if len(summary.Results) != 3 || summary.Succeeded != 2 || summary.Failed != 1 {
+		t.Fatalf("Expected 2 successes and 1 failure out of 3, got %+v", summary)
+	}
+	failures := summary.Failures()
+	if len(failures) != 1 || filepath.Base(failures[0].InputPath) != "broken.docx" {
+		t.Errorf("Expected broken.docx to fail, got %+v", failures)
+	}
+
+	for _, name := range []string{"a.docx", filepath.Join("sub", "b.docx")} {
+		doc, err := docx.Open(filepath.Join(outDir, name))
+		if err != nil {
+			t.Fatalf("Failed to open output %s: %v", name, err)
+		}
+		if text, _ := doc.GetParagraphText(0); text != "Hello NEWNAME" {
+			t.Errorf("Expected replaced text in %s, got %q", name, text)
+		}
+	}
+
+	// Non-recursive runs skip subdirectories
+	summary, err = Batch(dir, ReplaceOperation("OLDNAME", "NEWNAME"), BatchOptions{Pattern: "*.docx", OutputDir: outDir})
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+	if len(summary.Results) != 2 {
+		t.Errorf("Expected 2 files without recursion, got %d", len(summary.Results))
+	}
+}
+
+func TestBatchLogging(t *testing.T) {
+	dir := createBatchDir(t)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	_, err := Batch(dir, ReplaceOperation("OLDNAME", "NEWNAME"), BatchOptions{
+		Pattern:   "*.docx",
+		OutputDir: filepath.Join(t.TempDir(), "out"),
+		Logger:    logger,
+	})
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+
+	log := buf.String()
+	for _, want := range []string{
+		`msg="batch started"`,
+		`msg="file processed" input=` + filepath.Join(dir, "a.docx"),
+		`level=WARN msg="file failed" input=` + filepath.Join(dir, "broken.docx"),
+		`msg="batch finished" succeeded=1 failed=1`,
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("Expected log to contain %q, got:\n%s", want, log)
+		}
+	}
+}
+
+func TestBatchProgress(t *testing.T) {
+	dir := createBatchDir(t)
+	var calls []string
+
+	_, err := Batch(dir, ReplaceOperation("OLDNAME", "NEWNAME"), BatchOptions{
+		Pattern:   "*.docx",
+		Recursive: true,
+		OutputDir: filepath.Join(t.TempDir(), "out"),
+		Workers:   3,
+		Progress: func(current, total int, stage string) {
+			calls = append(calls, fmt.Sprintf("%s %d/%d", stage, current, total))
+		},
+	})
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+
+	// Failed files count too
+	want := []string{"process 1/3", "process 2/3", "process 3/3"}
+	if strings.Join(calls, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Expected progress %v, got %v", want, calls)
+	}
+}
+
+func TestBatchContext(t *testing.T) {
+	dir := createBatchDir(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The first file cancels the batch; the others are skipped
+	processed := 0
+	summary, err := BatchContext(ctx, dir, func(inputPath, outputPath string) error {
+		processed++
+		cancel()
+		return nil
+	}, BatchOptions{Pattern: "*.docx", Recursive: true, OutputDir: t.TempDir(), Workers: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if processed != 1 || summary.Succeeded != 1 || summary.Failed != 2 {
+		t.Errorf("Expected 1 file processed and 2 skipped, got %d processed and %+v", processed, summary)
+	}
+	for _, r := range summary.Failures() {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("Expected %s to be skipped, got %v", r.InputPath, r.Err)
+		}
+	}
+}
+
+func TestBatchConvert(t *testing.T) {
+	dir := createBatchDir(t)
+	os.Remove(filepath.Join(dir, "broken.docx"))
+
+	summary, err := Batch(dir, ConvertOperation(converter.DefaultOptions()), BatchOptions{
+		Pattern:   "*.docx",
+		Recursive: true,
+		OutputExt: ".md",
+	})
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+	if summary.Failed != 0 {
+		t.Fatalf("Expected no failures, got %+v", summary.Failures())
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "sub", "b.md"))
+	if err != nil {
+		t.Fatalf("Expected Markdown next to the input: %v", err)
+	}
+	if !strings.Contains(string(data), "Hello OLDNAME") {
+		t.Errorf("Unexpected Markdown: %s", data)
+	}
+}
+
+func TestBatchErrors(t *testing.T) {
+	if _, err := Batch(filepath.Join(t.TempDir(), "missing"), ReplaceOperation("a", "b"), BatchOptions{}); err == nil {
+		t.Error("Expected error for missing directory")
+	}
+	if _, err := Batch(t.TempDir(), ReplaceOperation("a", "b"), BatchOptions{Pattern: "[invalid"}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+
+	dir := createBatchDir(t)
+	summary, err := Batch(dir, WatermarkOperation("DRAFT", WatermarkOptions{}), BatchOptions{Pattern: "*.txt", OutputDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+	if summary.Failed != 1 {
+		t.Errorf("Expected watermarking a text file to be reported as a failure, got %+v", summary)
+	}
+}
@@ -0,0 +1,249 @@
+package operations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/converter"
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/template"
+	"gopkg.in/yaml.v3"
+)
+
+// BuildManifest describes a document assembled from sources, so the same
+// document can be built again from the manifest and its files. Paths are
+// relative to the directory of the manifest.
+type BuildManifest struct {
+	// Sources are the parts of the document, in order
+	Sources []BuildSource `yaml:"sources"`
+
+	// Data is the JSON or YAML data file templates are rendered with,
+	// unless they name their own
+	Data string `yaml:"data"`
+
+	// Header and Footer are the text of the header and footer of every page
+	Header string `yaml:"header"`
+	Footer string `yaml:"footer"`
+
+	// PageBreaks starts each source after the first on a new page; true
+	// when not set
+	PageBreaks *bool `yaml:"page_breaks"`
+
+	// TOC inserts a table of contents linking to the start of each source,
+	// headed TOCTitle
+	TOC      bool   `yaml:"toc"`
+	TOCTitle string `yaml:"toc_title"`
+
+	// PageNumbers is the page number format of PDF outputs, e.g.
+	// "Page {n} of {total}"; pages are not numbered when empty
+	PageNumbers string `yaml:"page_numbers"`
+
+	// Outputs are the files to write: .docx (or .docm, .dotx, .dotm) and .pdf
+	Outputs []string `yaml:"outputs"`
+
+	dir string // Directory paths are relative to
+}
+
+// BuildSource is a part of a built document: a DOCX fragment, a Markdown
+// file, or a template rendered with data. Exactly one of DOCX, Markdown and
+// Template is set.
+type BuildSource struct {
+	DOCX     string `yaml:"docx"`
+	Markdown string `yaml:"markdown"`
+	Template string `yaml:"template"`
+
+	// Data is the data file of a template, instead of the manifest's
+	Data string `yaml:"data"`
+}
+
+// LoadBuildManifest reads a YAML build manifest. Unknown keys are errors,
+// so misspelled ones don't go unnoticed.
+func LoadBuildManifest(path string) (*BuildManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var m BuildManifest
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", filepath.Base(path), err)
+	}
+	m.dir = filepath.Dir(path)
+	return &m, nil
+}
+
+// path returns a path of the manifest relative to its directory
+func (m *BuildManifest) path(p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(m.dir, p)
+}
+
+// Build assembles the document a manifest describes and writes its outputs,
+// returning their paths
+func Build(m *BuildManifest) ([]string, error) {
+	if len(m.Sources) == 0 {
+		return nil, fmt.Errorf("no sources in manifest")
+	}
+	if len(m.Outputs) == 0 {
+		return nil, fmt.Errorf("no outputs in manifest")
+	}
+
+	doc, err := m.Assemble()
+	if err != nil {
+		return nil, err
+	}
+
+	var written []string
+	for _, output := range m.Outputs {
+		path := m.path(output)
+		if dir := filepath.Dir(path); dir != "." {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return written, fmt.Errorf("failed to create output directory: %w", err)
+			}
+		}
+		switch fileFormat(path) {
+		case ".docx":
+			err = doc.Save(path)
+		case ".pdf":
+			pdfDoc := converter.NewDocxToPDF(converter.DefaultOptions()).Build(doc)
+			if m.PageNumbers != "" {
+				pdfDoc.SetPageNumbers(m.PageNumbers)
+			}
+			err = pdfDoc.Save(path)
+		default:
+			err = fmt.Errorf("%s: %w", output, ErrUnsupportedFormat)
+		}
+		if err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// Assemble reads, renders and merges the sources of a manifest and sets
+// its header and footer, without writing the outputs
+func (m *BuildManifest) Assemble() (*docx.Document, error) {
+	var defaultData template.Data
+	if m.Data != "" {
+		var err error
+		if defaultData, err = loadData(m.path(m.Data)); err != nil {
+			return nil, err
+		}
+	}
+
+	// The merged document copies what it needs, so the files sources are
+	// read from are closed once it is built
+	docs := make([]*docx.Document, len(m.Sources))
+	defer func() {
+		for _, doc := range docs {
+			if doc != nil {
+				doc.Close()
+			}
+		}
+	}()
+	names := make([]string, len(m.Sources))
+	for i, src := range m.Sources {
+		doc, name, err := m.source(src, defaultData)
+		if err != nil {
+			return nil, fmt.Errorf("source %d: %w", i+1, err)
+		}
+		docs[i], names[i] = doc, name
+	}
+
+	opts := DefaultMergeOptions()
+	opts.AddPageBreaks = m.PageBreaks == nil || *m.PageBreaks
+	opts.GenerateTOC = m.TOC
+	if m.TOCTitle != "" {
+		opts.TOCTitle = m.TOCTitle
+	}
+	doc, err := MergeDOCXDocuments(docs, names, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Header != "" {
+		if err := doc.SetHeader(docx.HeaderTypeDefault, m.Header); err != nil {
+			return nil, err
+		}
+	}
+	if m.Footer != "" {
+		if err := doc.SetFooter(docx.FooterTypeDefault, m.Footer); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// source reads one source of a manifest, returning it and its path
+func (m *BuildManifest) source(src BuildSource, defaultData template.Data) (*docx.Document, string, error) {
+	set := 0
+	for _, p := range []string{src.DOCX, src.Markdown, src.Template} {
+		if p != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, "", fmt.Errorf("set exactly one of docx, markdown and template")
+	}
+	if src.Data != "" && src.Template == "" {
+		return nil, "", fmt.Errorf("data is only used with template")
+	}
+
+	switch {
+	case src.DOCX != "":
+		doc, err := docx.Open(m.path(src.DOCX))
+		return doc, src.DOCX, err
+
+	case src.Markdown != "":
+		text, err := os.ReadFile(m.path(src.Markdown))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read Markdown: %w", err)
+		}
+		return converter.NewMarkdownToDocx(converter.DefaultOptions()).Build(string(text)), src.Markdown, nil
+	}
+
+	data := defaultData
+	if src.Data != "" {
+		var err error
+		if data, err = loadData(m.path(src.Data)); err != nil {
+			return nil, "", err
+		}
+	}
+	if data == nil {
+		data = template.Data{}
+	}
+	tmpl, err := template.Load(m.path(src.Template))
+	if err != nil {
+		return nil, "", err
+	}
+	doc, err := tmpl.Render(data, template.DefaultOptions())
+	if err != nil {
+		tmpl.Close()
+		return nil, "", fmt.Errorf("failed to render %s: %w", src.Template, err)
+	}
+	// The rendered document shares the template's file: closing it closes both
+	return doc, src.Template, nil
+}
+
+// loadData reads template data from a JSON or YAML file
+func loadData(path string) (template.Data, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data: %w", err)
+	}
+	var result template.Data
+	if err := json.Unmarshal(data, &result); err == nil {
+		return result, nil
+	}
+	if err := yaml.Unmarshal(data, &result); err == nil {
+		return result, nil
+	}
+	return nil, fmt.Errorf("failed to parse %s as JSON or YAML", filepath.Base(path))
+}
@@ -0,0 +1,91 @@
+package operations
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
+)
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cover := docx.New()
+	cover.AddParagraph("Annual Report", docx.WithStyle("Title"))
+	if err := cover.Save(filepath.Join(dir, "cover.docx")); err != nil {
+		t.Fatal(err)
+	}
+	letter := docx.New()
+	letter.AddParagraph("Dear {{name}},")
+	if err := letter.Save(filepath.Join(dir, "letter.docx")); err != nil {
+		t.Fatal(err)
+	}
+	write("intro.md", "# Introduction\n\nSales grew **12%**.\n")
+	write("data.yaml", "name: Shareholders\n")
+	write("build.yaml", `sources:
+  - docx: cover.docx
+  - markdown: intro.md
+  - template: letter.docx
+data: data.yaml
+header: ACME Corp
+toc: true
+page_numbers: "Page {n}"
+outputs:
+  - out/report.docx
+  - out/report.pdf
+`)
+
+	m, err := LoadBuildManifest(filepath.Join(dir, "build.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	written, err := Build(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 2 {
+		t.Fatalf("Expected 2 outputs, got %v", written)
+	}
+
+	doc, err := docx.Open(filepath.Join(dir, "out", "report.docx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := doc.GetText()
+	for _, want := range []string{"Contents", "Annual Report", "Introduction", "12%", "Dear Shareholders,"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in the built document, got %q", want, text)
+		}
+	}
+	if strings.Index(text, "Introduction") > strings.Index(text, "Dear") {
+		t.Errorf("Expected the sources in manifest order, got %q", text)
+	}
+	if header, ok := doc.GetPart("word/header1.xml"); !ok || !strings.Contains(string(header), "ACME Corp") {
+		t.Errorf("Expected the header saved, got %q", header)
+	}
+	if _, err := pdf.Open(filepath.Join(dir, "out", "report.pdf")); err != nil {
+		t.Errorf("Expected a readable PDF: %v", err)
+	}
+
+	// Typos and unknown outputs are reported
+	write("typo.yaml", "sources:\n  - docs: cover.docx\noutputs: [out.docx]\n")
+	if _, err := LoadBuildManifest(filepath.Join(dir, "typo.yaml")); err == nil {
+		t.Error("Expected an error for an unknown key")
+	}
+	write("odd.yaml", "sources:\n  - docx: cover.docx\noutputs: [out.odt]\n")
+	if m, err = LoadBuildManifest(filepath.Join(dir, "odd.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Build(m); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+package operations
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/converter"
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/template"
+)
+
+// ContractPackOptions holds options for building a contract pack
+type ContractPackOptions struct {
+	// Templates are the DOCX templates to render, in order
+	Templates []string
+
+	// Data is the data every template is rendered with
+	Data template.Data
+
+	// OutputPath is the path of the PDF to write
+	OutputPath string
+
+	// DOCXPath, when set, also saves the assembled DOCX before conversion
+	DOCXPath string
+
+	// CoverTitle is the title of the cover page; no cover page is added when
+	// empty. Both cover lines may use template variables.
+	CoverTitle string
+
+	// CoverSubtitle is an optional line under the cover title
+	CoverSubtitle string
+
+	// TOCTitle is the heading of the table of contents
+	TOCTitle string
+
+	// PageNumbers is the page number format (see pdf.Document.SetPageNumbers);
+	// pages are not numbered when empty
+	PageNumbers string
+
+	// Watermark is text drawn across every page; no watermark when empty
+	Watermark string
+
+	// Signer, when set, signs the PDF. The detached signature is written next
+	// to it with a ".sig" extension.
+	Signer crypto.Signer
+
+	// Render configures template rendering
+	Render template.RenderOptions
+
+	// Convert configures the PDF conversion
+	Convert converter.ConvertOptions
+}
+
+// DefaultContractPackOptions returns default contract pack options
+func DefaultContractPackOptions() ContractPackOptions {
+	return ContractPackOptions{
+		TOCTitle:    "Contents",
+		PageNumbers: "Page {n} of {total}",
+		Render:      template.DefaultOptions(),
+		Convert:     converter.DefaultOptions(),
+	}
+}
+
+// ContractPackResult describes the files written by BuildContractPack
+type ContractPackResult struct {
+	PDFPath       string
+	DOCXPath      string
+	SignaturePath string
+	Pages         int
+}
+
+// BuildContractPack renders each template with the same data, merges the
+// results behind a cover page and a table of contents, converts them to PDF,
+// stamps page numbers and a watermark, and signs the result
+func BuildContractPack(opts ContractPackOptions) (*ContractPackResult, error) {
+	if len(opts.Templates) == 0 {
+		return nil, fmt.Errorf("no templates provided")
+	}
+	if opts.OutputPath == "" {
+		return nil, fmt.Errorf("output path is required")
+	}
+
+	// Render every template
+	rendered := make([]*docx.Document, len(opts.Templates))
+	for i, path := range opts.Templates {
+		tmpl, err := template.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		defer tmpl.Close() // Rendered documents read large media from it until saved
+		doc, err := tmpl.Render(opts.Data, opts.Render)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", path, err)
+		}
+		rendered[i] = doc
+	}
+
+	// Merge them behind a table of contents
+	mergeOpts := DefaultMergeOptions()
+	mergeOpts.GenerateTOC = true
+	if opts.TOCTitle != "" {
+		mergeOpts.TOCTitle = opts.TOCTitle
+	}
+	pack, err := MergeDOCXDocuments(rendered, opts.Templates, mergeOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.CoverTitle != "" {
+		if err := insertCoverPage(pack, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &ContractPackResult{PDFPath: opts.OutputPath}
+	if opts.DOCXPath != "" {
+		if err := pack.Save(opts.DOCXPath); err != nil {
+			return nil, fmt.Errorf("failed to save DOCX: %w", err)
+		}
+		result.DOCXPath = opts.DOCXPath
+	}
+
+	// Convert and stamp
+	pdfDoc := converter.NewDocxToPDF(opts.Convert).Build(pack)
+	if opts.PageNumbers != "" {
+		pdfDoc.SetPageNumbers(opts.PageNumbers)
+	}
+	if opts.Watermark != "" {
+		pdfDoc.SetWatermark(opts.Watermark)
+	}
+	result.Pages = pdfDoc.GetPageCount()
+
+	data, err := pdfDoc.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(opts.OutputPath, data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to save PDF: %w", err)
+	}
+
+	if opts.Signer != nil {
+		signature, err := signDetached(opts.Signer, data)
+		if err != nil {
+			return nil, err
+		}
+		result.SignaturePath = opts.OutputPath + ".sig"
+		if err := os.WriteFile(result.SignaturePath, signature, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to save signature: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// insertCoverPage adds the cover title and subtitle, rendered with the pack
+// data, at the top of the document followed by a page break
+func insertCoverPage(doc *docx.Document, opts ContractPackOptions) error {
+	cover := docx.New()
+	cover.AddParagraph(opts.CoverTitle, docx.WithStyle("Title"), docx.WithBold(), docx.WithSize("56"), docx.WithAlignment("center"))
+	if opts.CoverSubtitle != "" {
+		cover.AddParagraph(opts.CoverSubtitle, docx.WithStyle("Subtitle"), docx.WithSize("32"), docx.WithAlignment("center"))
+	}
+
+	renderOpts := opts.Render
+	renderOpts.RemoveEmptyParagraphs = false
+	rendered, err := template.New(cover).Render(opts.Data, renderOpts)
+	if err != nil {
+		return fmt.Errorf("failed to render cover page: %w", err)
+	}
+
+	paras := append(rendered.Body.Paragraphs, pageBreakParagraph())
+	doc.Body.SpliceParagraphs(0, 0, paras...)
+	return nil
+}
+
+// signDetached signs the SHA-256 digest of data (Ed25519 keys sign data itself)
+func signDetached(signer crypto.Signer, data []byte) ([]byte, error) {
+	var signature []byte
+	var err error
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		signature, err = signer.Sign(rand.Reader, data, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(data)
+		signature, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	return signature, nil
+}
+
+// LoadSigningKey reads a PEM-encoded RSA, ECDSA or Ed25519 private key
+// (PKCS#8, PKCS#1 or SEC 1)
+func LoadSigningKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		var key interface{}
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key in %s: %w", filepath.Base(path), err)
+		}
+
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported key type %T", key)
+		}
+		return signer, nil
+	}
+
+	return nil, fmt.Errorf("no private key found in %s", filepath.Base(path))
+}
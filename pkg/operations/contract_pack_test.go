@@ -0,0 +1,136 @@
+package operations
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
+	"github.com/Palaciodiego008/docxsmith/pkg/template"
+)
+
+func TestBuildContractPack(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	agreement := docx.New()
+	agreement.AddParagraph("Service Agreement", docx.WithStyle("Heading1"))
+	agreement.AddParagraph("This agreement is made with {{client}}.")
+	agreementPath := filepath.Join(tmpDir, "agreement.docx")
+	if err := agreement.Save(agreementPath); err != nil {
+		t.Fatalf("Failed to save template: %v", err)
+	}
+
+	pricing := docx.New()
+	pricing.AddParagraph("Pricing", docx.WithStyle("Heading1"))
+	pricing.AddParagraph("{{client}} pays {{amount}} per month.")
+	pricingPath := filepath.Join(tmpDir, "pricing.docx")
+	if err := pricing.Save(pricingPath); err != nil {
+		t.Fatalf("Failed to save template: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	opts := DefaultContractPackOptions()
+	opts.Templates = []string{agreementPath, pricingPath}
+	opts.Data = template.Data{"client": "Acme Corp", "amount": "$500"}
+	opts.OutputPath = filepath.Join(tmpDir, "pack.pdf")
+	opts.DOCXPath = filepath.Join(tmpDir, "pack.docx")
+	opts.CoverTitle = "Contract for {{client}}"
+	opts.Watermark = "CONFIDENTIAL"
+	opts.Signer = key
+
+	result, err := BuildContractPack(opts)
+	if err != nil {
+		t.Fatalf("BuildContractPack failed: %v", err)
+	}
+	if result.Pages == 0 || result.SignaturePath != opts.OutputPath+".sig" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// The assembled DOCX starts with the cover page, then the table of contents
+	assembled, err := docx.Open(result.DOCXPath)
+	if err != nil {
+		t.Fatalf("Failed to open assembled DOCX: %v", err)
+	}
+	wantStart := []string{"Contract for Acme Corp", "", "Contents", "Service Agreement", "Pricing"}
+	for i, want := range wantStart {
+		if text, _ := assembled.GetParagraphText(i); text != want {
+			t.Errorf("Paragraph %d: expected %q, got %q", i, want, text)
+		}
+	}
+
+	pdfDoc, err := pdf.Open(result.PDFPath)
+	if err != nil {
+		t.Fatalf("Failed to open PDF: %v", err)
+	}
+	text := pdfDoc.GetAllText()
+	for _, want := range []string{"Contract for Acme Corp", "Acme Corp pays $500 per month.", "Page 1 of", "CONFIDENTIAL"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected PDF to contain %q, got: %s", want, text)
+		}
+	}
+
+	data, _ := os.ReadFile(result.PDFPath)
+	signature, err := os.ReadFile(result.SignaturePath)
+	if err != nil {
+		t.Fatalf("Failed to read signature: %v", err)
+	}
+	digest := sha256.Sum256(data)
+	if !ecdsa.VerifyASN1(&key.PublicKey, digest[:], signature) {
+		t.Error("Expected signature to verify against the PDF")
+	}
+
+	opts.Templates = nil
+	if _, err := BuildContractPack(opts); err == nil {
+		t.Error("Expected error without templates")
+	}
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	keyPath := filepath.Join(tmpDir, "key.pem")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	signer, err := LoadSigningKey(keyPath)
+	if err != nil {
+		t.Fatalf("LoadSigningKey failed: %v", err)
+	}
+	signature, err := signDetached(signer, []byte("payload"))
+	if err != nil {
+		t.Fatalf("signDetached failed: %v", err)
+	}
+	if !ed25519.Verify(priv.Public().(ed25519.PublicKey), []byte("payload"), signature) {
+		t.Error("Expected Ed25519 signature to verify")
+	}
+
+	certPath := filepath.Join(tmpDir, "cert.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{1}}), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := LoadSigningKey(certPath); err == nil {
+		t.Error("Expected error for file without a private key")
+	}
+}
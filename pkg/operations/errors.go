@@ -0,0 +1,29 @@
+package operations
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// Errors returned by operations, wrapped with details. They are the docx
+// package's errors, so errors.Is matches either name.
+var (
+	// ErrIndexOutOfRange is returned for pages and paragraphs outside the document
+	ErrIndexOutOfRange = docx.ErrIndexOutOfRange
+
+	// ErrUnsupportedFormat is returned for input files of a type the
+	// operation doesn't handle and for unsupported conversions
+	ErrUnsupportedFormat = docx.ErrUnsupportedFormat
+)
+
+// fileFormat returns the lower-cased extension of a file, with Word macro and
+// template files (.docm, .dotx, .dotm) reported as ".docx"
+func fileFormat(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if _, ok := docx.TypeForExtension(ext); ok {
+		return ".docx"
+	}
+	return ext
+}
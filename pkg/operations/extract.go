@@ -0,0 +1,67 @@
+package operations
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
+)
+
+// ExtractRange copies a range of a document into a single new file: pages
+// for a PDF, paragraphs for a DOCX. start and end are 0-based and inclusive.
+// DOCX output keeps the tables between the paragraphs along with the styles,
+// headers and images they use.
+func ExtractRange(inputPath, outputPath string, start, end int) error {
+	switch fileFormat(inputPath) {
+	case ".docx":
+		return ExtractDOCXRange(inputPath, outputPath, ParagraphRange{Start: start, End: end})
+	case ".pdf":
+		return ExtractPDFRange(inputPath, outputPath, PageRange{Start: start, End: end})
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, filepath.Ext(inputPath))
+	}
+}
+
+// ExtractDOCXRange copies a range of paragraphs of a DOCX into a new file
+func ExtractDOCXRange(inputPath, outputPath string, r ParagraphRange) error {
+	doc, err := openDOCX(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to open document: %w", err)
+	}
+	defer doc.Close()
+
+	part, err := doc.ExtractRange(r.Start, r.End)
+	if err != nil {
+		return err
+	}
+
+	if err := saveDOCX(part, outputPath); err != nil {
+		return fmt.Errorf("failed to save extracted document: %w", err)
+	}
+	return nil
+}
+
+// ExtractPDFRange copies a range of pages of a PDF into a new file
+func ExtractPDFRange(inputPath, outputPath string, r PageRange) error {
+	doc, err := openPDF(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to open PDF: %w", err)
+	}
+
+	totalPages := doc.GetPageCount()
+	if r.Start < 0 || r.End >= totalPages || r.Start > r.End {
+		return fmt.Errorf("invalid page range [%d:%d], document has %d pages", r.Start, r.End, totalPages)
+	}
+
+	part := pdf.New()
+	part.Metadata = doc.Metadata
+	for _, page := range doc.Pages[r.Start : r.End+1] {
+		copyPDFPage(part, page)
+	}
+	copyPDFBookmarks(part, doc, r.Start, r.End, 0, 0)
+
+	if err := savePDF(part, outputPath); err != nil {
+		return fmt.Errorf("failed to save extracted PDF: %w", err)
+	}
+	return nil
+}
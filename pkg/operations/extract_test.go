@@ -0,0 +1,83 @@
+package operations
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
+)
+
+func TestExtractRangeDOCX(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	doc := docx.New()
+	for i := 0; i < 10; i++ {
+		doc.AddParagraph(fmt.Sprintf("Paragraph %d", i+1))
+		if i == 5 {
+			doc.AddTable(1, 1).SetCellText(0, 0, "Inside")
+		}
+	}
+	inputPath := filepath.Join(tmpDir, "input.docx")
+	if err := doc.Save(inputPath); err != nil {
+		t.Fatalf("Failed to save test document: %v", err)
+	}
+
+	outputPath := filepath.Join(tmpDir, "range.docx")
+	if err := ExtractRange(inputPath, outputPath, 4, 7); err != nil {
+		t.Fatalf("ExtractRange failed: %v", err)
+	}
+
+	part, err := docx.Open(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to open extracted document: %v", err)
+	}
+	if part.GetParagraphCount() != 4 || part.GetTableCount() != 1 {
+		t.Errorf("Expected 4 paragraphs and 1 table, got %d and %d", part.GetParagraphCount(), part.GetTableCount())
+	}
+	if text, _ := part.GetParagraphText(0); text != "Paragraph 5" {
+		t.Errorf("Expected 'Paragraph 5' first, got %q", text)
+	}
+
+	if err := ExtractRange(inputPath, outputPath, 8, 12); err == nil {
+		t.Error("Expected error for range past the end")
+	}
+}
+
+func TestExtractRangePDF(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	doc := pdf.New()
+	for i := 0; i < 6; i++ {
+		doc.AddPage().AddText(fmt.Sprintf("Page %d", i+1), 20, 30, 12)
+	}
+	inputPath := filepath.Join(tmpDir, "input.pdf")
+	if err := doc.Save(inputPath); err != nil {
+		t.Fatalf("Failed to save test PDF: %v", err)
+	}
+
+	outputPath := filepath.Join(tmpDir, "range.pdf")
+	if err := ExtractRange(inputPath, outputPath, 2, 4); err != nil {
+		t.Fatalf("ExtractRange failed: %v", err)
+	}
+
+	part, err := pdf.Open(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to open extracted PDF: %v", err)
+	}
+	if part.GetPageCount() != 3 {
+		t.Errorf("Expected 3 pages, got %d", part.GetPageCount())
+	}
+	if text := part.GetAllText(); !strings.Contains(text, "Page 3") || strings.Contains(text, "Page 2") {
+		t.Errorf("Expected pages 3-5, got: %s", text)
+	}
+
+	if err := ExtractRange(inputPath, outputPath, -1, 2); err == nil {
+		t.Error("Expected error for negative start")
+	}
+	if err := ExtractRange(filepath.Join(tmpDir, "notes.txt"), outputPath, 0, 1); err == nil {
+		t.Error("Expected error for unsupported file type")
+	}
+}
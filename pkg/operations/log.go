@@ -0,0 +1,36 @@
+package operations
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Operations report their progress to the *slog.Logger set in their
+// options: files processed at Info level, and the documents opened, records
+// rendered and parts written at Debug level, with paragraph counts and
+// timings. Nothing is logged when no logger is set.
+
+// discardHandler drops every record
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// discardLogger is used by operations whose options set no logger
+var discardLogger = slog.New(discardHandler{})
+
+// loggerOrDiscard returns l, or a logger dropping everything when l is nil
+func loggerOrDiscard(l *slog.Logger) *slog.Logger {
+	if l == nil {
+		return discardLogger
+	}
+	return l
+}
+
+// elapsed is a duration attribute for the time since start, rounded for reading
+func elapsed(start time.Time) slog.Attr {
+	return slog.Duration("duration", time.Since(start).Round(time.Microsecond))
+}
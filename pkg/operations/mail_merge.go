@@ -0,0 +1,264 @@
+package operations
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/storage"
+	"github.com/Palaciodiego008/docxsmith/pkg/template"
+	"gopkg.in/yaml.v3"
+)
+
+// MailMergeOptions holds options for rendering a template once per record
+type MailMergeOptions struct {
+	// OutputDir receives one document per record, named after FilenamePattern
+	OutputDir string
+
+	// FilenamePattern names the document of each record. {n} or {index} is
+	// the record number, starting at 1 and formatted as in {index:03d}, and
+	// {Field} the value of a field of the record, such as
+	// "{Customer}_{n}.docx". Fields may be dotted paths. Unless the record
+	// has fields of those names, {title} is the document title, {heading}
+	// its first heading and {date} today, as in {date:2006-01-02}.
+	FilenamePattern string
+
+	// Sanitize is how values are cleaned up for file names; empty is SanitizeSafe
+	Sanitize SanitizeMode
+
+	// OutputPath, when set, writes all records to this single document
+	// instead, one after the other
+	OutputPath string
+
+	// PageBreaks starts each record on a new page of the single document
+	PageBreaks bool
+
+	// Render configures template rendering
+	Render template.RenderOptions
+
+	// Logger receives a record for each record rendered and document
+	// written; nil logs nothing
+	Logger *slog.Logger
+
+	// Progress, when set, is called as each record is rendered and written
+	Progress ProgressFunc
+}
+
+// DefaultMailMergeOptions returns default mail merge options
+func DefaultMailMergeOptions() MailMergeOptions {
+	return MailMergeOptions{
+		FilenamePattern: "document_{n}.docx",
+		PageBreaks:      true,
+		Render:          template.DefaultOptions(),
+	}
+}
+
+// MailMergeResult describes the files written by MailMerge
+type MailMergeResult struct {
+	// Paths lists the documents written, in record order
+	Paths   []string
+	Records int
+}
+
+// MailMerge renders a template with each record, writing either one
+// document per record to opts.OutputDir or a single document with every
+// record to opts.OutputPath. It stops at the first record that fails.
+func MailMerge(tmpl *template.Template, records []template.Data, opts MailMergeOptions) (*MailMergeResult, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no records provided")
+	}
+	if opts.OutputPath == "" && opts.OutputDir == "" {
+		return nil, fmt.Errorf("output path or output directory is required")
+	}
+
+	start := time.Now()
+	logger := loggerOrDiscard(opts.Logger)
+	rendered := make([]*docx.Document, len(records))
+	for i, record := range records {
+		recordStart := time.Now()
+		doc, err := tmpl.Render(record, opts.Render)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render record %d: %w", i+1, err)
+		}
+		logger.Debug("record rendered", "record", i+1, "paragraphs", len(doc.Body.Paragraphs), elapsed(recordStart))
+		rendered[i] = doc
+		reportProgress(opts.Progress, i+1, len(records), "render")
+	}
+
+	result := &MailMergeResult{Records: len(records)}
+
+	if opts.OutputPath != "" {
+		names := make([]string, len(rendered))
+		for i := range names {
+			names[i] = fmt.Sprintf("record %d", i+1)
+		}
+		mergeOpts := DefaultMergeOptions()
+		mergeOpts.AddPageBreaks = opts.PageBreaks
+		mergeOpts.Logger = opts.Logger
+		mergeOpts.Progress = opts.Progress
+		merged, err := MergeDOCXDocuments(rendered, names, mergeOpts)
+		if err != nil {
+			return nil, err
+		}
+		if err := saveDOCX(merged, opts.OutputPath); err != nil {
+			return nil, fmt.Errorf("failed to save %s: %w", opts.OutputPath, err)
+		}
+		result.Paths = []string{opts.OutputPath}
+		logger.Info("mail merge finished", "records", len(records), "output", opts.OutputPath, elapsed(start))
+		return result, nil
+	}
+
+	pattern := opts.FilenamePattern
+	if pattern == "" {
+		pattern = DefaultMailMergeOptions().FilenamePattern
+	}
+
+	// Name every document first so a bad pattern doesn't leave a partial run
+	paths := make([]string, len(records))
+	seen := make(map[string]int)
+	for i, record := range records {
+		name, err := recordFilename(pattern, record, i+1, rendered[i], opts.Sanitize)
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := seen[name]; ok {
+			return nil, fmt.Errorf("records %d and %d are both named %q", prev, i+1, name)
+		}
+		seen[name] = i + 1
+		paths[i] = storage.Join(opts.OutputDir, name)
+	}
+
+	if !storage.IsRemote(opts.OutputDir) {
+		if err := os.MkdirAll(opts.OutputDir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	for i, doc := range rendered {
+		if err := saveDOCX(doc, paths[i]); err != nil {
+			return nil, fmt.Errorf("failed to save %s: %w", paths[i], err)
+		}
+		logger.Info("document written", "record", i+1, "output", paths[i])
+		reportProgress(opts.Progress, i+1, len(rendered), "write")
+	}
+	result.Paths = paths
+	logger.Info("mail merge finished", "records", len(records), "dir", opts.OutputDir, elapsed(start))
+	return result, nil
+}
+
+// unsafeFilenameChars matches characters that can't appear in file names
+var unsafeFilenameChars = regexp.MustCompile(`[<>:"/\\|?*\x00-\x1f]+`)
+
+// RecordFilename expands a filename pattern for a record: {n} becomes the
+// record number and {Field} the field's value, with characters that can't
+// appear in file names replaced by underscores
+func RecordFilename(pattern string, record template.Data, n int) (string, error) {
+	return recordFilename(pattern, record, n, nil, SanitizeSafe)
+}
+
+// recordFilename expands a filename pattern for a record rendered as doc,
+// which gives {title} and {heading} when set
+func recordFilename(pattern string, record template.Data, n int, doc *docx.Document, mode SanitizeMode) (string, error) {
+	tokens := nameTokens{n: n}
+	if doc != nil {
+		tokens.title, tokens.heading = docxTitle(doc), firstHeading(doc)
+	}
+	var missing string
+	tokens.field = func(key string) (string, bool) {
+		value, ok := recordField(record, key)
+		if !ok && missing == "" && key != "date" && (doc == nil || key != "title" && key != "heading") {
+			missing = key
+		}
+		return strings.TrimSpace(value), ok
+	}
+
+	name, err := expandPattern(pattern, tokens, mode)
+	if err != nil {
+		return "", err
+	}
+	if missing != "" {
+		return "", fmt.Errorf("record %d has no field %q for the filename", n, missing)
+	}
+	if strings.Trim(name, ". ") == "" {
+		return "", fmt.Errorf("record %d has an empty filename", n)
+	}
+	return name, nil
+}
+
+// recordField looks up a dotted field path in a record
+func recordField(record template.Data, path string) (string, bool) {
+	var value interface{} = map[string]interface{}(record)
+	for _, key := range strings.Split(path, ".") {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if value, ok = fields[key]; !ok {
+			return "", false
+		}
+	}
+	if value == nil {
+		return "", true
+	}
+	return fmt.Sprint(value), true
+}
+
+// LoadRecords reads mail merge records from a CSV file, whose header row
+// names the fields, or from a JSON or YAML list of objects
+func LoadRecords(path string) ([]template.Data, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read records: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return ParseCSVRecords(data)
+	}
+
+	var records []template.Data
+	if err := json.Unmarshal(data, &records); err == nil {
+		return records, nil
+	}
+	if err := yaml.Unmarshal(data, &records); err == nil {
+		return records, nil
+	}
+	return nil, fmt.Errorf("failed to parse records as a JSON or YAML list of objects")
+}
+
+// ParseCSVRecords parses CSV data whose first row holds the field names
+// into one record per following row. Values are kept as text.
+func ParseCSVRecords(data []byte) ([]template.Data, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("CSV has no header row")
+	}
+
+	header := rows[0]
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+		if header[i] == "" {
+			return nil, fmt.Errorf("CSV column %d has no name", i+1)
+		}
+	}
+
+	records := make([]template.Data, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := make(template.Data, len(header))
+		for i, name := range header {
+			record[name] = row[i]
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,141 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/template"
+)
+
+func TestMailMerge(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	letter := docx.New()
+	letter.AddParagraph("Dear {{Customer}},")
+	letter.AddParagraph("Your balance is {{Balance | currency}}.")
+	tmpl := template.New(letter)
+
+	records, err := ParseCSVRecords([]byte("Customer, Balance\nAcme Corp,1200\n\"Smith/Sons\",35.5\n"))
+	if err != nil {
+		t.Fatalf("ParseCSVRecords failed: %v", err)
+	}
+	if len(records) != 2 || records[1]["Customer"] != "Smith/Sons" {
+		t.Fatalf("Unexpected records: %v", records)
+	}
+
+	opts := DefaultMailMergeOptions()
+	opts.OutputDir = filepath.Join(tmpDir, "letters")
+	opts.FilenamePattern = "{Customer}_{n}.docx"
+	result, err := MailMerge(tmpl, records, opts)
+	if err != nil {
+		t.Fatalf("MailMerge failed: %v", err)
+	}
+
+	want := []string{"Acme Corp_1.docx", "Smith_Sons_2.docx"}
+	texts := []string{"Your balance is $1,200.00.", "Your balance is $35.50."}
+	if len(result.Paths) != len(want) {
+		t.Fatalf("Expected %d documents, got %v", len(want), result.Paths)
+	}
+	for i, path := range result.Paths {
+		if filepath.Base(path) != want[i] {
+			t.Errorf("Document %d: expected %s, got %s", i+1, want[i], path)
+		}
+		doc, err := docx.Open(path)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", path, err)
+		}
+		if got := doc.GetText(); !strings.Contains(got, texts[i]) {
+			t.Errorf("Document %d: expected %q, got %q", i+1, texts[i], got)
+		}
+	}
+
+	// A single document holds every record, one per page
+	opts.OutputPath = filepath.Join(tmpDir, "letters.docx")
+	result, err = MailMerge(tmpl, records, opts)
+	if err != nil {
+		t.Fatalf("MailMerge to one document failed: %v", err)
+	}
+	doc, err := docx.Open(result.Paths[0])
+	if err != nil {
+		t.Fatalf("Failed to open merged document: %v", err)
+	}
+	got := doc.GetText()
+	if !strings.Contains(got, texts[0]) || !strings.Contains(got, texts[1]) {
+		t.Errorf("Merged document is missing records: %q", got)
+	}
+}
+
+func TestMailMergeProgress(t *testing.T) {
+	letter := docx.New()
+	letter.AddParagraph("Dear {{Customer}},")
+	records := []template.Data{{"Customer": "Acme"}, {"Customer": "Globex"}}
+
+	var calls []string
+	opts := DefaultMailMergeOptions()
+	opts.OutputPath = filepath.Join(t.TempDir(), "letters.docx")
+	opts.Progress = func(current, total int, stage string) {
+		calls = append(calls, fmt.Sprintf("%s %d/%d", stage, current, total))
+	}
+	if _, err := MailMerge(template.New(letter), records, opts); err != nil {
+		t.Fatalf("MailMerge failed: %v", err)
+	}
+
+	want := "render 1/2, render 2/2, merge 1/2, merge 2/2"
+	if got := strings.Join(calls, ", "); got != want {
+		t.Errorf("Expected progress %q, got %q", want, got)
+	}
+}
+
+func TestMailMergeErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	tmpl := template.New(docx.New())
+	records := []template.Data{{"Name": "A"}, {"Name": "A"}}
+
+	opts := DefaultMailMergeOptions()
+	opts.OutputDir = tmpDir
+	opts.FilenamePattern = "{Name}.docx"
+	if _, err := MailMerge(tmpl, records, opts); err == nil || !strings.Contains(err.Error(), "both named") {
+		t.Errorf("Expected a duplicate name error, got %v", err)
+	}
+
+	opts.FilenamePattern = "{Missing}_{n}.docx"
+	if _, err := MailMerge(tmpl, records, opts); err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Errorf("Expected a missing field error, got %v", err)
+	}
+
+	if _, err := MailMerge(tmpl, nil, opts); err == nil {
+		t.Error("Expected an error without records")
+	}
+}
+
+func TestLoadRecords(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	jsonPath := filepath.Join(tmpDir, "records.json")
+	os.WriteFile(jsonPath, []byte(`[{"Customer": {"Name": "Acme"}, "Total": 10}]`), 0o644)
+	records, err := LoadRecords(jsonPath)
+	if err != nil {
+		t.Fatalf("LoadRecords failed: %v", err)
+	}
+	name, err := RecordFilename("{Customer.Name}-{Total}-{n}.docx", records[0], 1)
+	if err != nil || name != "Acme-10-1.docx" {
+		t.Errorf("Expected Acme-10-1.docx, got %q (%v)", name, err)
+	}
+
+	objectPath := filepath.Join(tmpDir, "object.json")
+	os.WriteFile(objectPath, []byte(`{"Customer": "Acme"}`), 0o644)
+	if _, err := LoadRecords(objectPath); err == nil {
+		t.Error("Expected an error for data that isn't a list")
+	}
+
+	csvPath := filepath.Join(tmpDir, "records.csv")
+	os.WriteFile(csvPath, []byte("\ufeffName,City\nAna,Lima\n"), 0o644)
+	records, err = LoadRecords(csvPath)
+	if err != nil || len(records) != 1 || records[0]["Name"] != "Ana" || records[0]["City"] != "Lima" {
+		t.Errorf("Unexpected CSV records: %v (%v)", records, err)
+	}
+}
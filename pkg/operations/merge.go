@@ -1,15 +1,21 @@
 package operations
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/docx"
 	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
+	"github.com/Palaciodiego008/docxsmith/pkg/storage"
 )
 
 // MergeOptions holds options for merging documents
 type MergeOptions struct {
-	// AddPageBreaks adds page breaks between documents
+	// AddPageBreaks starts each document on a new page
 	AddPageBreaks bool
 
 	// AddSeparator adds a separator paragraph between documents
@@ -20,6 +26,33 @@ type MergeOptions struct {
 
 	// PreserveFormatting attempts to preserve source formatting
 	PreserveFormatting bool
+
+	// GenerateTOC inserts a table of contents at the top of the merged
+	// document linking to the start of each source document
+	GenerateTOC bool
+
+	// TOCTitle is the heading of the generated table of contents
+	TOCTitle string
+
+	// SectionPerDocument puts each document in a section of its own, keeping
+	// its page setup, headers and footers. Sections start on a new page when
+	// AddPageBreaks is set and on the same page otherwise.
+	SectionPerDocument bool
+
+	// RestartPageNumbering numbers the pages of each document from 1; it
+	// implies SectionPerDocument
+	RestartPageNumbering bool
+
+	// Deduplicate folds the style definitions, embedded fonts and images
+	// the documents share into one copy, keeping the merged file small when
+	// they come from the same template
+	Deduplicate bool
+
+	// Logger receives a record for each document merged; nil logs nothing
+	Logger *slog.Logger
+
+	// Progress, when set, is called as each document is opened and merged
+	Progress ProgressFunc
 }
 
 // DefaultMergeOptions returns default merge options
@@ -29,23 +62,89 @@ func DefaultMergeOptions() MergeOptions {
 		AddSeparator:       false,
 		SeparatorText:      "---",
 		PreserveFormatting: true,
+		TOCTitle:           "Contents",
 	}
 }
 
 // MergeDOCX merges multiple DOCX documents into one
 func MergeDOCX(inputPaths []string, outputPath string, opts MergeOptions) error {
+	return MergeDOCXContext(context.Background(), inputPaths, outputPath, opts)
+}
+
+// MergeDOCXContext merges DOCX documents like MergeDOCX, checking ctx
+// between documents and returning its error once it is canceled or its
+// deadline passes. Nothing is saved then.
+func MergeDOCXContext(ctx context.Context, inputPaths []string, outputPath string, opts MergeOptions) error {
 	if len(inputPaths) == 0 {
 		return fmt.Errorf("no input files provided")
 	}
 
+	start := time.Now()
+	logger := loggerOrDiscard(opts.Logger)
+	docs := make([]*docx.Document, len(inputPaths))
+	for i, path := range inputPaths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		doc, err := storage.OpenDOCX(ctx, path)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		defer doc.Close()
+		logger.Debug("document opened", "input", path, "paragraphs", len(doc.Body.Paragraphs), "tables", len(doc.Body.Tables))
+		docs[i] = doc
+		reportProgress(opts.Progress, i+1, len(inputPaths), "open")
+	}
+
+	result, err := mergeDOCXDocuments(ctx, docs, inputPaths, opts)
+	if err != nil {
+		return err
+	}
+
+	// Save the merged document
+	if err := storage.SaveDOCX(ctx, result, outputPath); err != nil {
+		return err
+	}
+	logger.Info("documents merged", "output", outputPath, "documents", len(docs), "paragraphs", len(result.Body.Paragraphs), elapsed(start))
+	return nil
+}
+
+// MergeDOCXDocuments merges documents already in memory into a new one. names
+// identify the documents in errors and, when a document has no heading, give
+// its table of contents entry.
+func MergeDOCXDocuments(docs []*docx.Document, names []string, opts MergeOptions) (*docx.Document, error) {
+	return mergeDOCXDocuments(context.Background(), docs, names, opts)
+}
+
+// mergeDOCXDocuments merges documents in memory, checking ctx before each one
+func mergeDOCXDocuments(ctx context.Context, docs []*docx.Document, names []string, opts MergeOptions) (*docx.Document, error) {
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no documents provided")
+	}
+	if len(names) != len(docs) {
+		return nil, fmt.Errorf("got %d names for %d documents", len(names), len(docs))
+	}
+
 	// Create a new document for the result
 	result := docx.New()
+	var tocEntries []tocEntry
 
 	// Process each input document
-	for i, path := range inputPaths {
-		doc, err := docx.Open(path)
-		if err != nil {
-			return fmt.Errorf("failed to open %s: %w", path, err)
+	for i, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		name := names[i]
+
+		// Start each document after the first on a new page. The break goes on
+		// the first paragraph that follows, unless the document starts with a
+		// table or has no paragraphs. Sections start on a new page themselves.
+		sections := opts.SectionPerDocument || opts.RestartPageNumbering
+		breakBefore := i > 0 && opts.AddPageBreaks && !sections
+		boundary := len(result.Body.Paragraphs)
+		if breakBefore && !opts.AddSeparator && !startsWithParagraph(doc) {
+			result.AddPageBreak()
+			breakBefore = false
 		}
 
 		// Add separator before document (except first)
@@ -54,29 +153,144 @@ func MergeDOCX(inputPaths []string, outputPath string, opts MergeOptions) error
 			result.AddParagraph("")
 		}
 
-		// Copy all paragraphs
-		for _, para := range doc.Body.Paragraphs {
-			result.Body.Paragraphs = append(result.Body.Paragraphs, para)
+		// Copy content along with the styles, numbering and media it references
+		start := len(result.Body.Paragraphs)
+		if sections {
+			typ := docx.SectionBreakContinuous
+			if opts.AddPageBreaks {
+				typ = docx.SectionBreakNextPage
+			}
+			if err := result.AppendSection(doc, typ); err != nil {
+				return nil, fmt.Errorf("failed to merge %s: %w", name, err)
+			}
+			// Skip the paragraph ending the section before
+			start = len(result.Body.Paragraphs) - len(doc.Body.Paragraphs)
+			if opts.RestartPageNumbering {
+				if err := result.RestartPageNumbering(start, 1); err != nil {
+					return nil, fmt.Errorf("failed to merge %s: %w", name, err)
+				}
+			}
+		} else if err := result.AppendDocument(doc); err != nil {
+			return nil, fmt.Errorf("failed to merge %s: %w", name, err)
+		}
+		loggerOrDiscard(opts.Logger).Debug("document appended", "name", name, "paragraphs", len(result.Body.Paragraphs)-start)
+
+		if opts.GenerateTOC {
+			// Documents without paragraphs still need an anchor for their entry
+			if len(result.Body.Paragraphs) == start {
+				result.AddParagraph("")
+			}
+			bookmark := fmt.Sprintf("_MergedDoc%d", i+1)
+			if err := result.AddBookmark(start, bookmark); err != nil {
+				return nil, fmt.Errorf("failed to bookmark %s: %w", name, err)
+			}
+			tocEntries = append(tocEntries, tocEntry{title: documentTitle(doc, name), bookmark: bookmark})
 		}
 
-		// Copy all tables
-		for _, table := range doc.Body.Tables {
-			result.Body.Tables = append(result.Body.Tables, table)
+		if breakBefore {
+			docx.WithPageBreakBefore()(&result.Body.Paragraphs[boundary])
 		}
+		reportProgress(opts.Progress, i+1, len(docs), "merge")
+	}
 
-		// Add page break after document (except last)
-		if i < len(inputPaths)-1 && opts.AddPageBreaks {
-			// Add empty paragraph as page break placeholder
-			result.AddParagraph("")
+	if opts.GenerateTOC {
+		if err := insertTOC(result, tocEntries, opts); err != nil {
+			return nil, err
 		}
 	}
 
-	// Save the merged document
-	return result.Save(outputPath)
+	if opts.Deduplicate {
+		report, err := result.Deduplicate()
+		if err != nil {
+			return nil, fmt.Errorf("failed to deduplicate merged document: %w", err)
+		}
+		loggerOrDiscard(opts.Logger).Debug("duplicates removed", "styles", len(report.Styles), "parts", len(report.Parts))
+	}
+
+	return result, nil
+}
+
+// tocEntry is a line of the generated table of contents
+type tocEntry struct {
+	title    string
+	bookmark string
+}
+
+// insertTOC adds the table of contents at the top of the document, followed by a page break
+func insertTOC(doc *docx.Document, entries []tocEntry, opts MergeOptions) error {
+	title := opts.TOCTitle
+	if title == "" {
+		title = "Contents"
+	}
+
+	if err := doc.AddParagraphAt(0, title, docx.WithStyle("TOCHeading"), docx.WithBold(), docx.WithSize("32")); err != nil {
+		return fmt.Errorf("failed to insert table of contents: %w", err)
+	}
+	for i, entry := range entries {
+		if err := doc.AddParagraphAt(i+1, entry.title, docx.WithStyle("TOC1"), docx.WithInternalLink(entry.bookmark), docx.WithColor("0563C1")); err != nil {
+			return fmt.Errorf("failed to insert table of contents: %w", err)
+		}
+	}
+
+	doc.Body.SpliceParagraphs(len(entries)+1, 0, pageBreakParagraph())
+	return nil
+}
+
+// documentTitle returns the text of the first heading in doc, or the file name without extension
+func documentTitle(doc *docx.Document, path string) string {
+	for i, para := range doc.Body.Paragraphs {
+		if para.Props == nil || para.Props.Style == nil {
+			continue
+		}
+		style := strings.ToLower(para.Props.Style.Val)
+		if !strings.HasPrefix(style, "heading") && style != "title" {
+			continue
+		}
+		if text, err := doc.GetParagraphText(i); err == nil && strings.TrimSpace(text) != "" {
+			return strings.TrimSpace(text)
+		}
+	}
+
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// startsWithParagraph reports whether the body of doc starts with a paragraph
+// rather than a table or content control
+func startsWithParagraph(doc *docx.Document) bool {
+	if len(doc.Body.Paragraphs) == 0 {
+		return false
+	}
+	for _, t := range doc.Body.Tables {
+		if t.Position == 0 {
+			return false
+		}
+	}
+	for _, s := range doc.Body.SDTs {
+		if s.Position == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// pageBreakParagraph returns a paragraph holding a hard page break
+func pageBreakParagraph() docx.Paragraph {
+	return docx.Paragraph{
+		Runs: []docx.Run{
+			{Break: &docx.Break{Type: "page"}},
+		},
+	}
 }
 
 // MergePDF merges multiple PDF documents into one
 func MergePDF(inputPaths []string, outputPath string) error {
+	return mergePDF(context.Background(), inputPaths, outputPath, nil)
+}
+
+// mergePDF merges PDF documents, checking ctx before each one and reporting
+// each one opened to progress
+func mergePDF(ctx context.Context, inputPaths []string, outputPath string, progress ProgressFunc) error {
 	if len(inputPaths) == 0 {
 		return fmt.Errorf("no input files provided")
 	}
@@ -85,51 +299,56 @@ func MergePDF(inputPaths []string, outputPath string) error {
 	result := pdf.New()
 
 	// Process each input PDF
-	for _, path := range inputPaths {
-		doc, err := pdf.Open(path)
+	for i, path := range inputPaths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		doc, err := storage.OpenPDF(ctx, path)
 		if err != nil {
 			return fmt.Errorf("failed to open %s: %w", path, err)
 		}
 
-		// Copy all pages
+		// Copy all pages, with a bookmark leading to the first one above
+		// the document's own bookmarks
+		offset := result.GetPageCount()
 		for _, page := range doc.Pages {
-			newPage := result.AddPage()
-
-			// Copy page properties
-			newPage.Width = page.Width
-			newPage.Height = page.Height
-			newPage.Margin = page.Margin
-
-			// Copy content
-			newPage.Content = append(newPage.Content, page.Content...)
+			copyPDFPage(result, page)
+		}
+		if len(doc.Pages) > 0 {
+			base := filepath.Base(path)
+			result.AddBookmark(strings.TrimSuffix(base, filepath.Ext(base)), offset, 0)
+			copyPDFBookmarks(result, doc, 0, len(doc.Pages)-1, offset, 1)
 		}
+		reportProgress(progress, i+1, len(inputPaths), "open")
 	}
 
 	// Save the merged PDF
-	return result.Save(outputPath)
+	return storage.SavePDF(ctx, result, outputPath)
 }
 
 // MergeDocuments is a convenience function that detects file type and merges accordingly
 func MergeDocuments(inputPaths []string, outputPath string, opts MergeOptions) error {
+	return MergeDocumentsContext(context.Background(), inputPaths, outputPath, opts)
+}
+
+// MergeDocumentsContext merges DOCX or PDF documents like MergeDocuments,
+// checking ctx between documents and returning its error once it is
+// canceled or its deadline passes. Nothing is saved then.
+func MergeDocumentsContext(ctx context.Context, inputPaths []string, outputPath string, opts MergeOptions) error {
 	if len(inputPaths) == 0 {
 		return fmt.Errorf("no input files provided")
 	}
 
 	// Detect file type from first input
-	firstPath := inputPaths[0]
-	if len(firstPath) < 4 {
-		return fmt.Errorf("invalid file path: %s", firstPath)
-	}
-
-	ext := firstPath[len(firstPath)-4:]
+	ext := fileFormat(inputPaths[0])
 
 	switch ext {
-	case "docx":
-		return MergeDOCX(inputPaths, outputPath, opts)
+	case ".docx":
+		return MergeDOCXContext(ctx, inputPaths, outputPath, opts)
 	case ".pdf":
-		return MergePDF(inputPaths, outputPath)
+		return mergePDF(ctx, inputPaths, outputPath, opts.Progress)
 	default:
-		return fmt.Errorf("unsupported file type: %s", ext)
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, ext)
 	}
 }
 
@@ -148,13 +367,14 @@ func GetMergeDOCXInfo(inputPaths []string) (*MergeInfo, error) {
 	}
 
 	for _, path := range inputPaths {
-		doc, err := docx.Open(path)
+		doc, err := openDOCX(path)
 		if err != nil {
 			return nil, fmt.Errorf("failed to open %s: %w", path, err)
 		}
 
 		info.TotalParagraphs += doc.GetParagraphCount()
 		info.TotalTables += doc.GetTableCount()
+		doc.Close()
 	}
 
 	return info, nil
@@ -167,7 +387,7 @@ func GetMergePDFInfo(inputPaths []string) (*MergeInfo, error) {
 	}
 
 	for _, path := range inputPaths {
-		doc, err := pdf.Open(path)
+		doc, err := openPDF(path)
 		if err != nil {
 			return nil, fmt.Errorf("failed to open %s: %w", path, err)
 		}
@@ -1,8 +1,15 @@
 package operations
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"image"
+	"image/png"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/docx"
@@ -23,7 +30,7 @@ func TestMergeDOCX(t *testing.T) {
 			name:          "Merge 2 documents with page breaks",
 			numDocs:       2,
 			parasPerDoc:   3,
-			expectedParas: 7, // 3 + 3 + 1 page break
+			expectedParas: 6, // 3 + 3, the second starting on a new page
 			addPageBreaks: true,
 			addSeparator:  false,
 		},
@@ -100,6 +107,38 @@ func TestMergeDOCX(t *testing.T) {
 	}
 }
 
+func TestMergeDocumentsContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputs := []string{}
+	for i := 0; i < 3; i++ {
+		doc := docx.New()
+		doc.AddParagraph(fmt.Sprintf("Doc%d", i+1))
+		path := filepath.Join(tmpDir, fmt.Sprintf("doc%d.docx", i+1))
+		if err := doc.Save(path); err != nil {
+			t.Fatalf("Failed to save test document: %v", err)
+		}
+		inputs = append(inputs, path)
+	}
+
+	// Cancel once the second document is opened
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts := DefaultMergeOptions()
+	opts.Progress = func(current, total int, stage string) {
+		if stage == "open" && current == 2 {
+			cancel()
+		}
+	}
+
+	output := filepath.Join(tmpDir, "merged.docx")
+	if err := MergeDocumentsContext(ctx, inputs, output, opts); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be saved, got %v", err)
+	}
+}
+
 func TestMergePDF(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -238,6 +277,49 @@ func TestGetMergeDOCXInfo(t *testing.T) {
 	}
 }
 
+func TestMergePDFBookmarks(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var inputs []string
+	for i, name := range []string{"intro", "report"} {
+		doc := pdf.New()
+		doc.AddPage().AddText("Cover", 20, 30, 12)
+		doc.AddPage().AddText("Body", 20, 30, 12)
+		if i == 1 {
+			doc.AddBookmark("Findings", 1, 0)
+		}
+		path := filepath.Join(tmpDir, name+".pdf")
+		if err := doc.Save(path); err != nil {
+			t.Fatalf("Failed to save test PDF: %v", err)
+		}
+		inputs = append(inputs, path)
+	}
+
+	outputPath := filepath.Join(tmpDir, "merged.pdf")
+	if err := MergePDF(inputs, outputPath); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	merged, err := pdf.Open(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to open merged PDF: %v", err)
+	}
+
+	want := []pdf.Bookmark{
+		{Title: "intro", Page: 0, Level: 0},
+		{Title: "report", Page: 2, Level: 0},
+		{Title: "Findings", Page: 3, Level: 1},
+	}
+	outline := merged.GetOutline()
+	if len(outline) != len(want) {
+		t.Fatalf("outline = %v, want %v", outline, want)
+	}
+	for i := range want {
+		if outline[i] != want[i] {
+			t.Errorf("outline[%d] = %+v, want %+v", i, outline[i], want[i])
+		}
+	}
+}
+
 func TestGetMergePDFInfo(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -270,3 +352,219 @@ func TestGetMergePDFInfo(t *testing.T) {
 		t.Errorf("Expected 5 pages, got %d", info.TotalPages)
 	}
 }
+
+func TestMergeDOCXPageBreaks(t *testing.T) {
+	tmpDir := t.TempDir()
+	inputFiles := []string{}
+
+	for i := 0; i < 2; i++ {
+		doc := docx.New()
+		doc.AddParagraph(fmt.Sprintf("Doc%d", i+1))
+		path := filepath.Join(tmpDir, fmt.Sprintf("doc%d.docx", i+1))
+		if err := doc.Save(path); err != nil {
+			t.Fatalf("Failed to save test document: %v", err)
+		}
+		inputFiles = append(inputFiles, path)
+	}
+
+	outputPath := filepath.Join(tmpDir, "merged.docx")
+	if err := MergeDOCX(inputFiles, outputPath, DefaultMergeOptions()); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	merged, err := docx.Open(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to open merged document: %v", err)
+	}
+
+	if merged.GetParagraphCount() != 2 {
+		t.Fatalf("Expected no paragraph between documents, got %d paragraphs", merged.GetParagraphCount())
+	}
+	if props := merged.Body.Paragraphs[0].Props; props != nil && props.PageBreakBefore != nil {
+		t.Errorf("Expected the first document not to start on a new page")
+	}
+	if props := merged.Body.Paragraphs[1].Props; props == nil || props.PageBreakBefore == nil {
+		t.Errorf("Expected the second document to start on a new page, got %+v", merged.Body.Paragraphs[1])
+	}
+
+	// A document starting with a table gets a page break paragraph before it
+	table := docx.New()
+	table.AddTable(1, 1)
+	table.AddParagraph("After table")
+	merged, err = MergeDOCXDocuments([]*docx.Document{merged, table}, []string{"merged.docx", "table.docx"}, DefaultMergeOptions())
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	breakPara := merged.Body.Paragraphs[2]
+	if len(breakPara.Runs) != 1 || breakPara.Runs[0].Break == nil || breakPara.Runs[0].Break.Type != "page" {
+		t.Errorf("Expected a page break paragraph before the table, got %+v", breakPara)
+	}
+	if merged.Body.Tables[0].Position != 3 {
+		t.Errorf("Expected the table after the page break, at position 3, got %d", merged.Body.Tables[0].Position)
+	}
+}
+
+func TestMergeDOCXGenerateTOC(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	report := docx.New()
+	report.AddParagraph("Quarterly Report", docx.WithStyle("Heading1"))
+	report.AddParagraph("Body")
+	notes := docx.New()
+	notes.AddParagraph("No heading here")
+
+	inputFiles := []string{filepath.Join(tmpDir, "report.docx"), filepath.Join(tmpDir, "notes.docx")}
+	for i, doc := range []*docx.Document{report, notes} {
+		if err := doc.Save(inputFiles[i]); err != nil {
+			t.Fatalf("Failed to save test document: %v", err)
+		}
+	}
+
+	opts := DefaultMergeOptions()
+	opts.GenerateTOC = true
+	outputPath := filepath.Join(tmpDir, "merged.docx")
+	if err := MergeDOCX(inputFiles, outputPath, opts); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	merged, err := docx.Open(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to open merged document: %v", err)
+	}
+
+	if text, _ := merged.GetParagraphText(0); text != "Contents" {
+		t.Errorf("Expected TOC title, got %q", text)
+	}
+
+	wantTitles := []string{"Quarterly Report", "notes"}
+	for i, want := range wantTitles {
+		para := merged.Body.Paragraphs[i+1]
+		if len(para.Hyperlinks) != 1 {
+			t.Fatalf("Expected TOC entry %d to hold a link, got %+v", i, para)
+		}
+		if text, _ := merged.GetParagraphText(i + 1); text != want {
+			t.Errorf("Expected TOC entry %q, got %q", want, text)
+		}
+
+		// The link must point to a bookmark on the first paragraph of the source
+		anchor := para.Hyperlinks[0].Anchor
+		found := false
+		for _, p := range merged.Body.Paragraphs {
+			for _, b := range p.BookmarkStarts {
+				if b.Name == anchor {
+					found = true
+				}
+			}
+		}
+		if !found {
+			t.Errorf("No bookmark found for anchor %q", anchor)
+		}
+	}
+
+	if first := merged.Body.Paragraphs[4]; len(first.BookmarkStarts) != 1 {
+		t.Errorf("Expected first merged paragraph to be bookmarked, got %+v", first)
+	}
+}
+
+func TestMergeDOCXSections(t *testing.T) {
+	report := docx.New()
+	report.AddParagraph("Report")
+	report.SetFooter(docx.FooterTypeDefault, "Report footer")
+	data, err := report.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	if report, err = docx.ReadBytes(data); err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+	notes := docx.New()
+	notes.AddTable(1, 1)
+	notes.AddParagraph("Notes")
+
+	opts := DefaultMergeOptions()
+	opts.RestartPageNumbering = true
+	opts.GenerateTOC = true
+	merged, err := MergeDOCXDocuments([]*docx.Document{report, notes}, []string{"report.docx", "notes.docx"}, opts)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	// Contents title, two entries and a page break, then the report and the paragraph ending its section
+	breaks := merged.SectionBreaks()
+	if len(breaks) != 1 || breaks[0] != 5 {
+		t.Fatalf("Expected the report's section to end at paragraph 5, got %v", breaks)
+	}
+	first := string(merged.Body.Paragraphs[5].Props.SectPr.Inner)
+	last := string(merged.Body.SectPr.Inner)
+	if !strings.Contains(first, "footerReference") || !strings.Contains(first, `w:start="1"`) {
+		t.Errorf("Expected the report section to keep its footer and restart numbering, got %s", first)
+	}
+	if !strings.Contains(last, `<w:type w:val="nextPage"/>`) || !strings.Contains(last, `w:start="1"`) || !strings.Contains(last, "footerReference") {
+		t.Errorf("Expected the notes section on a new page with its own footer and numbering, got %s", last)
+	}
+	for _, p := range merged.Body.Paragraphs {
+		if p.Props != nil && p.Props.PageBreakBefore != nil {
+			t.Errorf("Expected sections instead of page breaks, got %+v", p)
+		}
+	}
+	if merged.Body.Tables[0].Position != 6 {
+		t.Errorf("Expected the notes table at position 6, got %d", merged.Body.Tables[0].Position)
+	}
+	if len(merged.Body.Paragraphs[6].BookmarkStarts) != 1 {
+		t.Errorf("Expected the notes bookmark on its first paragraph, got %+v", merged.Body.Paragraphs[6])
+	}
+}
+
+func TestMergeDOCXDeduplicate(t *testing.T) {
+	var logo bytes.Buffer
+	if err := png.Encode(&logo, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	letter := func(heading string) *docx.Document {
+		doc := docx.New()
+		doc.SetPart("word/styles.xml", []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/>`+heading+`</w:style></w:styles>`))
+		doc.AddParagraph("Dear customer", docx.WithStyle("Heading1"))
+		if err := doc.AddImageFromBytes("logo.png", logo.Bytes()); err != nil {
+			t.Fatalf("AddImageFromBytes failed: %v", err)
+		}
+		return doc
+	}
+	docs := []*docx.Document{letter(""), letter(`<w:rPr><w:b/></w:rPr>`), letter(`<w:rPr><w:b/></w:rPr>`)}
+	names := []string{"cover.docx", "letter1.docx", "letter2.docx"}
+
+	opts := DefaultMergeOptions()
+	plain, err := MergeDOCXDocuments(docs, names, opts)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	opts.Deduplicate = true
+	merged, err := MergeDOCXDocuments(docs, names, opts)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	countImages := func(doc *docx.Document) int {
+		n := 0
+		for _, name := range doc.PartNames() {
+			if strings.HasPrefix(name, "word/media/") {
+				n++
+			}
+		}
+		return n
+	}
+	if got := countImages(plain); got != 3 {
+		t.Errorf("Expected a logo per letter without deduplication, got %d", got)
+	}
+	if got := countImages(merged); got != 1 {
+		t.Errorf("Expected a single logo with deduplication, got %d", got)
+	}
+
+	styles, _ := merged.GetPart("word/styles.xml")
+	if strings.Contains(string(styles), `"Heading1_3"`) {
+		t.Errorf("Expected the second letter's heading to be folded, got %s", styles)
+	}
+	if got := merged.Body.Paragraphs[4].Props.Style.Val; got != "Heading1_2" {
+		t.Errorf("Expected the second letter to use Heading1_2, got %s", got)
+	}
+}
@@ -0,0 +1,155 @@
+package operations
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// SanitizeMode is how the values tokens put into file names are cleaned up
+type SanitizeMode string
+
+// Sanitize modes
+const (
+	// SanitizeSafe replaces the characters file systems reject with
+	// underscores and collapses spaces; it is the default
+	SanitizeSafe SanitizeMode = "safe"
+
+	// SanitizeStrict also replaces spaces and anything but ASCII letters,
+	// digits, '-', '_' and '.', for names that need no quoting in scripts
+	// or URLs
+	SanitizeStrict SanitizeMode = "strict"
+
+	// SanitizeNone keeps values as they are, but for path separators and
+	// control characters, which could otherwise move a file elsewhere
+	SanitizeNone SanitizeMode = "none"
+)
+
+var (
+	// patternTokenPattern matches the {name} and {name:format} tokens of an output pattern
+	patternTokenPattern = regexp.MustCompile(`\{([^{}:]+)(?::([^{}]*))?\}`)
+
+	// numberFormatPattern matches the formats numbers accept, such as 03d
+	numberFormatPattern = regexp.MustCompile(`^0?\d{0,2}d$`)
+
+	strictUnsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	separatorChars    = regexp.MustCompile(`[/\\\x00-\x1f]+`)
+)
+
+// nameTokens holds the values of the tokens of an output pattern
+type nameTokens struct {
+	n       int    // {n} and {index}
+	base    string // {base}, the input file name without extension
+	title   string // {title}
+	heading string // {heading}
+
+	// field looks up the other tokens, such as the fields of a record; it
+	// takes precedence over {base}, {title}, {heading} and {date}
+	field func(name string) (string, bool)
+}
+
+// expandPattern replaces the tokens of an output pattern: {n} and {index}
+// are the part or record number, formatted as in {index:03d}; {date} is
+// today as 2006-01-02, or in the Go layout given as in {date:20060102};
+// {base}, {title} and {heading} come from tokens. Values are cleaned up as
+// set by mode. Unknown tokens are left as they are.
+func expandPattern(pattern string, tokens nameTokens, mode SanitizeMode) (string, error) {
+	if err := checkSanitizeMode(mode); err != nil {
+		return "", err
+	}
+
+	var err error
+	name := patternTokenPattern.ReplaceAllStringFunc(pattern, func(token string) string {
+		m := patternTokenPattern.FindStringSubmatch(token)
+		key, format := strings.TrimSpace(m[1]), m[2]
+		switch key {
+		case "n", "index":
+			if format == "" {
+				format = "d"
+			}
+			if !numberFormatPattern.MatchString(format) {
+				if err == nil {
+					err = fmt.Errorf("invalid number format %q in %s", format, token)
+				}
+				return token
+			}
+			return fmt.Sprintf("%"+format, tokens.n)
+		}
+
+		if tokens.field != nil {
+			if value, ok := tokens.field(key); ok {
+				return sanitizeName(value, mode)
+			}
+		}
+		switch key {
+		case "base":
+			return sanitizeName(tokens.base, mode)
+		case "title":
+			return sanitizeName(tokens.title, mode)
+		case "heading":
+			return sanitizeName(tokens.heading, mode)
+		case "date":
+			if format == "" {
+				format = time.DateOnly
+			}
+			return sanitizeName(time.Now().Format(format), mode)
+		}
+		return token
+	})
+	return name, err
+}
+
+// sanitizeName cleans up a value put into a file name as set by mode
+func sanitizeName(s string, mode SanitizeMode) string {
+	switch mode {
+	case SanitizeNone:
+		return separatorChars.ReplaceAllString(s, "_")
+	case SanitizeStrict:
+		s = strictUnsafeChars.ReplaceAllString(strings.TrimSpace(s), "_")
+		return strings.Trim(s, "_")
+	}
+	return sanitizeFilename(s)
+}
+
+// checkSanitizeMode returns an error for unknown sanitize modes; empty is SanitizeSafe
+func checkSanitizeMode(mode SanitizeMode) error {
+	switch mode {
+	case "", SanitizeSafe, SanitizeStrict, SanitizeNone:
+		return nil
+	}
+	return fmt.Errorf("unknown sanitize mode %q", mode)
+}
+
+// corePropertiesXML is the part of the core properties of a DOCX file names use
+type corePropertiesXML struct {
+	Title string `xml:"title"`
+}
+
+// docxTitle returns the title set in the document properties, or ""
+func docxTitle(doc *docx.Document) string {
+	data, ok := doc.GetPart("docProps/core.xml")
+	if !ok {
+		return ""
+	}
+	var props corePropertiesXML
+	if err := xml.Unmarshal(data, &props); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(props.Title)
+}
+
+// firstHeading returns the text of the first heading of doc, or ""
+func firstHeading(doc *docx.Document) string {
+	for i := range doc.Body.Paragraphs {
+		if docx.HeadingLevel(&doc.Body.Paragraphs[i]) > 0 {
+			if text, _ := doc.GetParagraphText(i); strings.TrimSpace(text) != "" {
+				return text
+			}
+		}
+	}
+	return ""
+}
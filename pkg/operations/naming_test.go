@@ -0,0 +1,85 @@
+package operations
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/template"
+)
+
+func TestExpandPattern(t *testing.T) {
+	tokens := nameTokens{n: 7, base: "book", title: "Q3: Sales / Costs", heading: "Über  uns"}
+	today := time.Now().Format(time.DateOnly)
+	tests := []struct {
+		pattern string
+		mode    SanitizeMode
+		want    string
+	}{
+		{"{base}_{n}", "", "book_7"},
+		{"{index:03d}-{title}", SanitizeSafe, "007-Q3_ Sales _ Costs"},
+		{"{index:03d}-{title}", SanitizeStrict, "007-Q3_Sales_Costs"},
+		{"{title}", SanitizeNone, "Q3: Sales _ Costs"},
+		{"{heading}", "", "Über uns"},
+		{"{heading}", SanitizeStrict, "ber_uns"},
+		{"{date}_{n}", "", today + "_7"},
+		{"{date:2006}", "", today[:4]},
+		{"{unknown}_{n:2d}", "", "{unknown}_ 7"},
+	}
+	for _, tt := range tests {
+		got, err := expandPattern(tt.pattern, tokens, tt.mode)
+		if err != nil || got != tt.want {
+			t.Errorf("%s (%s): expected %q, got %q (%v)", tt.pattern, tt.mode, tt.want, got, err)
+		}
+	}
+
+	if _, err := expandPattern("{n:s}", tokens, ""); err == nil {
+		t.Error("Expected an error for a number format that isn't a number")
+	}
+	if _, err := expandPattern("{n}", tokens, "lower"); err == nil {
+		t.Error("Expected an error for an unknown sanitize mode")
+	}
+}
+
+func TestSplitPatternTokens(t *testing.T) {
+	tmpDir := t.TempDir()
+	doc := docx.New()
+	doc.SetPart("docProps/core.xml", []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>Annual Report</dc:title></cp:coreProperties>`))
+	doc.AddParagraph("Preface")
+	doc.AddParagraph("Sales", docx.WithStyle("Heading1"))
+	doc.AddParagraph("Costs", docx.WithStyle("Heading1"))
+	inputPath := filepath.Join(tmpDir, "report.docx")
+	if err := doc.Save(inputPath); err != nil {
+		t.Fatalf("Failed to save test document: %v", err)
+	}
+
+	opts := SplitOptions{OutputPattern: "{index:02d} {title} - {heading}", OutputDir: tmpDir, Sanitize: SanitizeStrict}
+	files, err := SplitDOCXByParagraphs(inputPath, []ParagraphRange{{Start: 0, End: 1}, {Start: 2, End: 2}}, opts)
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	want := []string{"01 Annual_Report - Sales.docx", "02 Annual_Report - Costs.docx"} // The pattern itself is kept
+	for i := range want {
+		if filepath.Base(files[i]) != want[i] {
+			t.Errorf("Expected %s, got %s", want[i], filepath.Base(files[i]))
+		}
+	}
+
+	// Records name documents by their fields first, then by their content
+	tmpl := template.New(doc)
+	result, err := MailMerge(tmpl, []template.Data{{"Customer": "Acme"}}, MailMergeOptions{
+		OutputDir:       tmpDir,
+		FilenamePattern: "{index:03d}_{Customer}_{heading}.docx",
+	})
+	if err != nil {
+		t.Fatalf("MailMerge failed: %v", err)
+	}
+	if got := filepath.Base(result.Paths[0]); got != "001_Acme_Sales.docx" {
+		t.Errorf("Expected 001_Acme_Sales.docx, got %s", got)
+	}
+	if _, err := RecordFilename("{base}.docx", template.Data{}, 1); err == nil {
+		t.Error("Expected an error for a token records don't have")
+	}
+}
@@ -0,0 +1,19 @@
+package operations
+
+import "github.com/Palaciodiego008/docxsmith/pkg/converter"
+
+// ProgressFunc receives the progress of a long-running operation: current
+// of total steps of the named stage are done. Merges report the "open" and
+// "merge" stages per document, splits the "write" stage per part, batches
+// the "process" stage per file, and mail merges the "render" stage per
+// record followed by "write" per document or "merge" into a single one. It
+// is the converter's progress function, so the same function can follow a
+// conversion too.
+type ProgressFunc = converter.ProgressFunc
+
+// reportProgress calls fn unless it is nil
+func reportProgress(fn ProgressFunc, current, total int, stage string) {
+	if fn != nil {
+		fn(current, total, stage)
+	}
+}
@@ -0,0 +1,108 @@
+package operations
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
+)
+
+// RedactOptions holds options for redaction
+type RedactOptions struct {
+	// Remove deletes matches instead of replacing each character with a black box
+	Remove bool
+
+	// KeepMetadata leaves the document properties and revision history alone.
+	// By default the author and other identifying properties are removed and
+	// tracked changes accepted, since deleted text may hold what was redacted.
+	KeepMetadata bool
+
+	// Logger receives a record with the number of matches; nil logs nothing
+	Logger *slog.Logger
+}
+
+// Redact hides every match of the regular expressions in patterns in a DOCX
+// or PDF file and writes the result to outputPath. Returns the number of
+// matches redacted.
+func Redact(inputPath, outputPath string, patterns []string, opts RedactOptions) (int, error) {
+	if len(patterns) == 0 {
+		return 0, fmt.Errorf("at least one pattern is required")
+	}
+
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return 0, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		compiled[i] = re
+	}
+
+	start := time.Now()
+	var count int
+	var err error
+	switch fileFormat(inputPath) {
+	case ".docx":
+		count, err = redactDOCX(inputPath, outputPath, compiled, opts)
+	case ".pdf":
+		count, err = redactPDF(inputPath, outputPath, compiled, opts)
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedFormat, filepath.Ext(inputPath))
+	}
+	if err == nil {
+		loggerOrDiscard(opts.Logger).Info("document redacted", "input", inputPath, "output", outputPath, "matches", count, elapsed(start))
+	}
+	return count, err
+}
+
+// redactDOCX redacts a DOCX file
+func redactDOCX(inputPath, outputPath string, patterns []*regexp.Regexp, opts RedactOptions) (int, error) {
+	doc, err := openDOCX(inputPath)
+	if err != nil {
+		return 0, err
+	}
+	defer doc.Close()
+
+	mode := docx.RedactBlackout
+	if opts.Remove {
+		mode = docx.RedactRemove
+	}
+
+	// Revision history goes first so deleted text isn't left behind unredacted
+	if !opts.KeepMetadata {
+		doc.RemovePersonalInfo()
+	}
+
+	count := 0
+	for _, re := range patterns {
+		count += doc.Redact(re, mode)
+	}
+	return count, saveDOCX(doc, outputPath)
+}
+
+// redactPDF redacts a PDF file
+func redactPDF(inputPath, outputPath string, patterns []*regexp.Regexp, opts RedactOptions) (int, error) {
+	doc, err := openPDF(inputPath)
+	if err != nil {
+		return 0, err
+	}
+
+	mode := pdf.RedactBlackout
+	if opts.Remove {
+		mode = pdf.RedactRemove
+	}
+
+	if !opts.KeepMetadata {
+		doc.RemovePersonalInfo()
+	}
+
+	count := 0
+	for _, re := range patterns {
+		count += doc.Redact(re, mode)
+	}
+	return count, savePDF(doc, outputPath)
+}
@@ -0,0 +1,64 @@
+package operations
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
+)
+
+func TestRedact(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	docxPath := filepath.Join(tmpDir, "case.docx")
+	doc := docx.New()
+	doc.AddParagraph("Claimant: Jane Doe, jane@example.com")
+	if err := doc.Save(docxPath); err != nil {
+		t.Fatalf("Failed to save DOCX: %v", err)
+	}
+
+	outPath := filepath.Join(tmpDir, "public.docx")
+	count, err := Redact(docxPath, outPath, []string{`Jane Doe`, `\S+@\S+`}, RedactOptions{Remove: true})
+	if err != nil {
+		t.Fatalf("Redact failed for DOCX: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 matches, got %d", count)
+	}
+	redacted, err := docx.Open(outPath)
+	if err != nil {
+		t.Fatalf("Failed to open redacted DOCX: %v", err)
+	}
+	if text, _ := redacted.GetParagraphText(0); text != "Claimant: , " {
+		t.Errorf("Unexpected redacted text: %q", text)
+	}
+
+	pdfPath := filepath.Join(tmpDir, "case.pdf")
+	pdfDoc := pdf.New()
+	pdfDoc.SetMetadata("Case of Jane Doe", "Jane Doe", "Claim")
+	pdfDoc.AddPage().AddText("Claimant: Jane Doe", 20, 30, 12)
+	if err := pdfDoc.Save(pdfPath); err != nil {
+		t.Fatalf("Failed to save PDF: %v", err)
+	}
+
+	if _, err := Redact(pdfPath, pdfPath, []string{`Jane Doe`}, RedactOptions{}); err != nil {
+		t.Fatalf("Redact failed for PDF: %v", err)
+	}
+	redactedPDF, err := pdf.Open(pdfPath)
+	if err != nil {
+		t.Fatalf("Failed to open redacted PDF: %v", err)
+	}
+	text := redactedPDF.GetAllText()
+	if strings.Contains(text, "Jane") || !strings.Contains(text, "Claimant:") {
+		t.Errorf("Expected the name to be blacked out, got: %s", text)
+	}
+
+	if _, err := Redact(pdfPath, pdfPath, nil, RedactOptions{}); err == nil {
+		t.Error("Expected error without patterns")
+	}
+	if _, err := Redact(pdfPath, pdfPath, []string{"("}, RedactOptions{}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
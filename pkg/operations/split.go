@@ -1,21 +1,51 @@
 package operations
 
 import (
+	"archive/zip"
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/docx"
 	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
+	"github.com/Palaciodiego008/docxsmith/pkg/storage"
 )
 
 // SplitOptions holds options for splitting documents
 type SplitOptions struct {
-	// OutputPattern is the pattern for output files (e.g., "chapter_{n}.docx")
+	// OutputPattern is the pattern for output files (e.g., "chapter_{n}.docx").
+	// {n} or {index} is the part number, formatted as in {index:03d}; {base}
+	// the input file name without extension; {title} the heading or bookmark
+	// starting the part, else the document title or {base}; {heading} the
+	// first heading of the part; {date} today, as in {date:2006-01-02}.
 	OutputPattern string
 
+	// Sanitize is how values are cleaned up for file names; empty is SanitizeSafe
+	Sanitize SanitizeMode
+
 	// OutputDir is the directory for output files
 	OutputDir string
+
+	// ZipOutput, when set, is the path of a zip archive the parts are written
+	// into as they are made, instead of files in OutputDir. Entries are named
+	// as the files would be, relative to OutputDir, and splits return the
+	// entry names. A split that fails removes the archive.
+	ZipOutput string
+
+	// PageEstimate configures how SplitDOCXByEstimatedPages estimates pages
+	PageEstimate docx.PageEstimateOptions
+
+	// Logger receives a record for each part written; nil logs nothing
+	Logger *slog.Logger
+
+	// Progress, when set, is called as each part is written
+	Progress ProgressFunc
 }
 
 // DefaultSplitOptions returns default split options
@@ -23,15 +53,24 @@ func DefaultSplitOptions() SplitOptions {
 	return SplitOptions{
 		OutputPattern: "part_{n}",
 		OutputDir:     ".",
+		PageEstimate:  docx.DefaultPageEstimateOptions(),
 	}
 }
 
 // SplitDOCXByParagraphs splits a DOCX document by paragraph ranges
 func SplitDOCXByParagraphs(inputPath string, ranges []ParagraphRange, opts SplitOptions) ([]string, error) {
-	doc, err := docx.Open(inputPath)
+	began := time.Now()
+	doc, err := openDOCX(inputPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open document: %w", err)
 	}
+	defer doc.Close()
+
+	parts, err := newPartWriter(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer parts.abort()
 
 	outputFiles := []string{}
 	totalParagraphs := doc.GetParagraphCount()
@@ -42,42 +81,50 @@ func SplitDOCXByParagraphs(inputPath string, ranges []ParagraphRange, opts Split
 			return nil, fmt.Errorf("invalid range [%d:%d], document has %d paragraphs", r.Start, r.End, totalParagraphs)
 		}
 
-		// Create new document with paragraphs in range
-		newDoc := docx.New()
-		for j := r.Start; j <= r.End; j++ {
-			newDoc.Body.Paragraphs = append(newDoc.Body.Paragraphs, doc.Body.Paragraphs[j])
+		// Create new document with content in range
+		newDoc, err := doc.ExtractRange(r.Start, r.End)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract range [%d:%d]: %w", r.Start, r.End, err)
 		}
 
 		// Generate output filename
-		ext := filepath.Ext(inputPath)
-		base := strings.TrimSuffix(filepath.Base(inputPath), ext)
-		pattern := strings.ReplaceAll(opts.OutputPattern, "{n}", fmt.Sprintf("%d", i+1))
-		pattern = strings.ReplaceAll(pattern, "{base}", base)
-
-		if !strings.HasSuffix(pattern, ext) {
-			pattern += ext
+		outputPath, err := splitOutputPath(inputPath, opts, i+1, docxTitle(doc), firstHeading(newDoc))
+		if err != nil {
+			return nil, err
 		}
 
-		outputPath := filepath.Join(opts.OutputDir, pattern)
-
 		// Save split document
-		if err := newDoc.Save(outputPath); err != nil {
+		outputPath, err = parts.write(outputPath, newDoc)
+		if err != nil {
 			return nil, fmt.Errorf("failed to save split document: %w", err)
 		}
 
 		outputFiles = append(outputFiles, outputPath)
+		loggerOrDiscard(opts.Logger).Debug("part written", "output", outputPath)
+		reportProgress(opts.Progress, i+1, len(ranges), "write")
 	}
 
+	if err := parts.close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	loggerOrDiscard(opts.Logger).Info("document split", "input", inputPath, "parts", len(outputFiles), elapsed(began))
 	return outputFiles, nil
 }
 
 // SplitPDFByPages splits a PDF document by page ranges
 func SplitPDFByPages(inputPath string, ranges []PageRange, opts SplitOptions) ([]string, error) {
-	doc, err := pdf.Open(inputPath)
+	began := time.Now()
+	doc, err := openPDF(inputPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open PDF: %w", err)
 	}
 
+	parts, err := newPartWriter(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer parts.abort()
+
 	outputFiles := []string{}
 	totalPages := doc.GetPageCount()
 
@@ -95,33 +142,35 @@ func SplitPDFByPages(inputPath string, ranges []PageRange, opts SplitOptions) ([
 				return nil, fmt.Errorf("failed to get page %d: %w", j, err)
 			}
 
-			newPage := newDoc.AddPage()
-			newPage.Width = page.Width
-			newPage.Height = page.Height
-			newPage.Margin = page.Margin
-			newPage.Content = append(newPage.Content, page.Content...)
+			copyPDFPage(newDoc, page)
 		}
+		copyPDFBookmarks(newDoc, doc, r.Start, r.End, 0, 0)
 
-		// Generate output filename
-		ext := filepath.Ext(inputPath)
-		base := strings.TrimSuffix(filepath.Base(inputPath), ext)
-		pattern := strings.ReplaceAll(opts.OutputPattern, "{n}", fmt.Sprintf("%d", i+1))
-		pattern = strings.ReplaceAll(pattern, "{base}", base)
-
-		if !strings.HasSuffix(pattern, ext) {
-			pattern += ext
+		// Generate output filename, with the first bookmark of the part as its heading
+		heading := ""
+		if outline := newDoc.GetOutline(); len(outline) > 0 {
+			heading = outline[0].Title
+		}
+		outputPath, err := splitOutputPath(inputPath, opts, i+1, doc.Metadata.Title, heading)
+		if err != nil {
+			return nil, err
 		}
-
-		outputPath := filepath.Join(opts.OutputDir, pattern)
 
 		// Save split PDF
-		if err := newDoc.Save(outputPath); err != nil {
+		outputPath, err = parts.write(outputPath, newDoc)
+		if err != nil {
 			return nil, fmt.Errorf("failed to save split PDF: %w", err)
 		}
 
 		outputFiles = append(outputFiles, outputPath)
+		loggerOrDiscard(opts.Logger).Debug("part written", "output", outputPath)
+		reportProgress(opts.Progress, i+1, len(ranges), "write")
 	}
 
+	if err := parts.close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	loggerOrDiscard(opts.Logger).Info("document split", "input", inputPath, "parts", len(outputFiles), elapsed(began))
 	return outputFiles, nil
 }
 
@@ -131,10 +180,11 @@ func SplitDOCXByCount(inputPath string, count int, opts SplitOptions) ([]string,
 		return nil, fmt.Errorf("count must be positive")
 	}
 
-	doc, err := docx.Open(inputPath)
+	doc, err := openDOCX(inputPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open document: %w", err)
 	}
+	defer doc.Close()
 
 	totalParagraphs := doc.GetParagraphCount()
 	if totalParagraphs == 0 {
@@ -167,13 +217,45 @@ func SplitDOCXByCount(inputPath string, count int, opts SplitOptions) ([]string,
 	return SplitDOCXByParagraphs(inputPath, ranges, opts)
 }
 
+// SplitDOCXByEstimatedPages splits a DOCX every pagesPerPart pages. DOCX
+// files don't store pages, so they are estimated as set by opts.PageEstimate;
+// parts are cut before the first paragraph starting past each page limit,
+// and a paragraph longer than a part stays whole.
+func SplitDOCXByEstimatedPages(inputPath string, pagesPerPart int, opts SplitOptions) ([]string, error) {
+	if pagesPerPart <= 0 {
+		return nil, fmt.Errorf("pages per part must be positive")
+	}
+
+	doc, err := openDOCX(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open document: %w", err)
+	}
+	defer doc.Close()
+
+	starts := doc.EstimatePages(opts.PageEstimate)
+	if len(starts) == 0 {
+		return nil, fmt.Errorf("document has no paragraphs")
+	}
+
+	ranges := []ParagraphRange{{Start: 0}}
+	for i := 1; i < len(starts); i++ {
+		if (starts[i]-1)/pagesPerPart != (starts[i-1]-1)/pagesPerPart {
+			ranges[len(ranges)-1].End = i - 1
+			ranges = append(ranges, ParagraphRange{Start: i})
+		}
+	}
+	ranges[len(ranges)-1].End = len(starts) - 1
+
+	return SplitDOCXByParagraphs(inputPath, ranges, opts)
+}
+
 // SplitPDFByCount splits a PDF into N equal parts
 func SplitPDFByCount(inputPath string, count int, opts SplitOptions) ([]string, error) {
 	if count <= 0 {
 		return nil, fmt.Errorf("count must be positive")
 	}
 
-	doc, err := pdf.Open(inputPath)
+	doc, err := openPDF(inputPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open PDF: %w", err)
 	}
@@ -209,13 +291,172 @@ func SplitPDFByCount(inputPath string, count int, opts SplitOptions) ([]string,
 	return SplitPDFByPages(inputPath, ranges, opts)
 }
 
+// SplitPDFBySize splits a PDF into parts of consecutive pages, each no larger
+// than maxBytes when saved (e.g. to stay under an email attachment limit).
+// A page that alone exceeds the budget is an error.
+func SplitPDFBySize(inputPath string, maxBytes int64, opts SplitOptions) ([]string, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("max size must be positive")
+	}
+
+	doc, err := openPDF(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+
+	totalPages := doc.GetPageCount()
+	if totalPages == 0 {
+		return nil, fmt.Errorf("PDF has no pages")
+	}
+
+	// Measure each page once: its size alone, and what it adds after
+	// another page, which is a little more than what it adds to an empty PDF
+	first, err := pdfPagesSize(doc, 0)
+	if err != nil {
+		return nil, err
+	}
+	alone := make([]int64, totalPages)
+	added := make([]int64, totalPages)
+	for i := range alone {
+		if alone[i], err = pdfPagesSize(doc, i); err != nil {
+			return nil, err
+		}
+		if alone[i] > maxBytes {
+			return nil, fmt.Errorf("page %d alone exceeds %d bytes", i+1, maxBytes)
+		}
+		size, err := pdfPagesSize(doc, 0, i)
+		if err != nil {
+			return nil, err
+		}
+		added[i] = size - first
+	}
+
+	ranges := []PageRange{}
+	start := 0
+	for start < totalPages {
+		// Grow the part by the pages' sizes, then check its real size and
+		// drop pages until it fits, as numbering more objects can add a
+		// few bytes
+		size := alone[start]
+		end := start + 1
+		for end < totalPages && size+added[end] <= maxBytes {
+			size += added[end]
+			end++
+		}
+		for end-start > 1 {
+			pages := make([]int, 0, end-start)
+			for i := start; i < end; i++ {
+				pages = append(pages, i)
+			}
+			size, err := pdfPagesSize(doc, pages...)
+			if err != nil {
+				return nil, err
+			}
+			if size <= maxBytes {
+				break
+			}
+			end--
+		}
+
+		ranges = append(ranges, PageRange{Start: start, End: end - 1})
+		start = end
+	}
+
+	return SplitPDFByPages(inputPath, ranges, opts)
+}
+
+// pdfPagesSize returns the size of a PDF holding the given pages of doc
+func pdfPagesSize(doc *pdf.Document, pages ...int) (int64, error) {
+	part := pdf.New()
+	for _, i := range pages {
+		page, _ := doc.GetPage(i)
+		copyPDFPage(part, page)
+	}
+	data, err := part.Bytes()
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure pages: %w", err)
+	}
+	return int64(len(data)), nil
+}
+
+// copyPDFPage appends a copy of page to doc
+func copyPDFPage(doc *pdf.Document, page *pdf.Page) {
+	newPage := doc.AddPage()
+	newPage.Width = page.Width
+	newPage.Height = page.Height
+	newPage.Margin = page.Margin
+	newPage.Rotation = page.Rotation
+	newPage.Content = append(newPage.Content, page.Content...)
+	newPage.Annotations = append(newPage.Annotations, page.Annotations...)
+}
+
+// copyPDFBookmarks adds to doc the bookmarks of src leading to pages start
+// to end, which were copied to doc from its page offset on, one level
+// deeper than they were
+func copyPDFBookmarks(doc, src *pdf.Document, start, end, offset, depth int) {
+	for _, b := range src.GetOutline() {
+		if b.Page >= start && b.Page <= end {
+			doc.AddBookmark(b.Title, offset+b.Page-start, b.Level+depth)
+		}
+	}
+}
+
 // SplitDOCXByHeadings splits a DOCX by heading levels (smart split)
 func SplitDOCXByHeadings(inputPath string, headingLevel int, opts SplitOptions) ([]string, error) {
-	doc, err := docx.Open(inputPath)
+	began := time.Now()
+	doc, err := openDOCX(inputPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open document: %w", err)
 	}
+	defer doc.Close()
+
+	plan, err := PlanSplitDOCXByHeadings(doc, inputPath, headingLevel, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := newPartWriter(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer parts.abort()
+
+	outputFiles := []string{}
+	for _, part := range plan {
+		r := part.Range
+		newDoc, err := doc.ExtractRange(r.Start, r.End)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract range [%d:%d]: %w", r.Start, r.End, err)
+		}
+
+		outputPath, err := parts.write(part.Output, newDoc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save split document: %w", err)
+		}
+
+		outputFiles = append(outputFiles, outputPath)
+		loggerOrDiscard(opts.Logger).Debug("part written", "output", outputPath)
+		reportProgress(opts.Progress, len(outputFiles), len(plan), "write")
+	}
 
+	if err := parts.close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	loggerOrDiscard(opts.Logger).Info("document split", "input", inputPath, "parts", len(outputFiles), elapsed(began))
+	return outputFiles, nil
+}
+
+// SplitPart is a part of a document as a split would write it
+type SplitPart struct {
+	Range  ParagraphRange // Paragraphs of the part, 0-based and inclusive
+	Title  string         // Text of the heading starting the part
+	Output string         // Path the part would be saved to
+}
+
+// PlanSplitDOCXByHeadings returns the parts SplitDOCXByHeadings would write
+// for doc, opened from inputPath, without writing anything. Each part runs
+// from a heading of headingLevel to the paragraph before the next one.
+func PlanSplitDOCXByHeadings(doc *docx.Document, inputPath string, headingLevel int, opts SplitOptions) ([]SplitPart, error) {
 	// Find paragraphs with heading style
 	headingIndices := []int{}
 	for i, para := range doc.Body.Paragraphs {
@@ -228,67 +469,180 @@ func SplitDOCXByHeadings(inputPath string, headingLevel int, opts SplitOptions)
 		return nil, fmt.Errorf("no headings found at level %d", headingLevel)
 	}
 
-	// Create ranges between headings
-	ranges := []ParagraphRange{}
-	for i := 0; i < len(headingIndices); i++ {
-		start := headingIndices[i]
+	// Create ranges between headings, using heading text in filenames
+	parts := []SplitPart{}
+	for i, start := range headingIndices {
 		end := doc.GetParagraphCount() - 1
-
 		if i < len(headingIndices)-1 {
 			end = headingIndices[i+1] - 1
 		}
 
-		ranges = append(ranges, ParagraphRange{Start: start, End: end})
+		text, _ := doc.GetParagraphText(start)
+		text = strings.TrimSpace(text)
+		headingText := text
+		if runes := []rune(headingText); len(runes) > 50 {
+			headingText = string(runes[:50])
+		}
+		output, err := splitOutputPath(inputPath, opts, i+1, headingText, headingText)
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, SplitPart{
+			Range:  ParagraphRange{Start: start, End: end},
+			Title:  text,
+			Output: output,
+		})
 	}
+	return parts, nil
+}
 
-	// Use heading text in filename if possible
-	outputFiles := []string{}
-	for i, r := range ranges {
-		newDoc := docx.New()
-		for j := r.Start; j <= r.End; j++ {
-			newDoc.Body.Paragraphs = append(newDoc.Body.Paragraphs, doc.Body.Paragraphs[j])
-		}
+// SplitDOCXByBookmarks splits a DOCX at each paragraph holding a bookmark, one
+// file per bookmark, with the bookmark name available as {title}. Hidden
+// bookmarks Word maintains itself (names starting with "_", such as _GoBack or
+// _Toc...) are ignored. As with heading splits, content before the first
+// bookmark is not included.
+func SplitDOCXByBookmarks(inputPath string, opts SplitOptions) ([]string, error) {
+	began := time.Now()
+	doc, err := openDOCX(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open document: %w", err)
+	}
+	defer doc.Close()
 
-		// Try to get heading text for filename
-		headingText := ""
-		if r.Start < doc.GetParagraphCount() {
-			text, _ := doc.GetParagraphText(r.Start)
-			headingText = sanitizeFilename(text)
-			if len(headingText) > 50 {
-				headingText = headingText[:50]
+	var starts []int
+	var names []string
+	for i, para := range doc.Body.Paragraphs {
+		for _, b := range para.BookmarkStarts {
+			if !strings.HasPrefix(b.Name, "_") {
+				starts = append(starts, i)
+				names = append(names, b.Name)
+				break
 			}
 		}
+	}
 
-		// Generate filename
-		ext := filepath.Ext(inputPath)
-		base := strings.TrimSuffix(filepath.Base(inputPath), ext)
+	if len(starts) == 0 {
+		return nil, fmt.Errorf("no bookmarks found")
+	}
 
-		var pattern string
-		if headingText != "" {
-			pattern = strings.ReplaceAll(opts.OutputPattern, "{n}", fmt.Sprintf("%d", i+1))
-			pattern = strings.ReplaceAll(pattern, "{base}", base)
-			pattern = strings.ReplaceAll(pattern, "{title}", headingText)
-		} else {
-			pattern = strings.ReplaceAll(opts.OutputPattern, "{n}", fmt.Sprintf("%d", i+1))
-			pattern = strings.ReplaceAll(pattern, "{base}", base)
+	parts, err := newPartWriter(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer parts.abort()
+
+	outputFiles := []string{}
+	for i, start := range starts {
+		end := doc.GetParagraphCount() - 1
+		if i < len(starts)-1 {
+			end = starts[i+1] - 1
 		}
 
-		if !strings.HasSuffix(pattern, ext) {
-			pattern += ext
+		newDoc, err := doc.ExtractRange(start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract range [%d:%d]: %w", start, end, err)
+		}
+		outputPath, err := splitOutputPath(inputPath, opts, i+1, names[i], firstHeading(newDoc))
+		if err != nil {
+			return nil, err
+		}
+		outputPath, err = parts.write(outputPath, newDoc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save split document: %w", err)
 		}
 
-		outputPath := filepath.Join(opts.OutputDir, pattern)
+		outputFiles = append(outputFiles, outputPath)
+		loggerOrDiscard(opts.Logger).Debug("part written", "output", outputPath)
+		reportProgress(opts.Progress, i+1, len(starts), "write")
+	}
+
+	if err := parts.close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	loggerOrDiscard(opts.Logger).Info("document split", "input", inputPath, "parts", len(outputFiles), elapsed(began))
+	return outputFiles, nil
+}
+
+// SplitDOCXBySections splits a DOCX at its section breaks, one file per section.
+// Each part keeps the page setup of its section.
+func SplitDOCXBySections(inputPath string, opts SplitOptions) ([]string, error) {
+	began := time.Now()
+	doc, err := openDOCX(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open document: %w", err)
+	}
+	defer doc.Close()
+
+	breaks := doc.SectionBreaks()
+	if len(breaks) == 0 {
+		return nil, fmt.Errorf("no section breaks found")
+	}
+
+	ranges := []ParagraphRange{}
+	start := 0
+	for _, b := range breaks {
+		ranges = append(ranges, ParagraphRange{Start: start, End: b})
+		start = b + 1
+	}
+	if start < doc.GetParagraphCount() {
+		ranges = append(ranges, ParagraphRange{Start: start, End: doc.GetParagraphCount() - 1})
+	}
 
-		if err := newDoc.Save(outputPath); err != nil {
+	parts, err := newPartWriter(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer parts.abort()
+
+	outputFiles := []string{}
+	for i, r := range ranges {
+		newDoc, err := doc.ExtractRange(r.Start, r.End)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract range [%d:%d]: %w", r.Start, r.End, err)
+		}
+		outputPath, err := splitOutputPath(inputPath, opts, i+1, docxTitle(doc), firstHeading(newDoc))
+		if err != nil {
+			return nil, err
+		}
+		outputPath, err = parts.write(outputPath, newDoc)
+		if err != nil {
 			return nil, fmt.Errorf("failed to save split document: %w", err)
 		}
 
 		outputFiles = append(outputFiles, outputPath)
+		loggerOrDiscard(opts.Logger).Debug("part written", "output", outputPath)
+		reportProgress(opts.Progress, i+1, len(ranges), "write")
 	}
 
+	if err := parts.close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	loggerOrDiscard(opts.Logger).Info("document split", "input", inputPath, "parts", len(outputFiles), elapsed(began))
 	return outputFiles, nil
 }
 
+// splitOutputPath builds the output path of the nth part from
+// opts.OutputPattern. title is that of the part, or of the document when
+// the part has none, and heading the first heading of the part.
+func splitOutputPath(inputPath string, opts SplitOptions, n int, title, heading string) (string, error) {
+	ext := filepath.Ext(inputPath)
+	base := strings.TrimSuffix(filepath.Base(inputPath), ext)
+	if strings.TrimSpace(title) == "" {
+		title = base
+	}
+
+	name, err := expandPattern(opts.OutputPattern, nameTokens{n: n, base: base, title: title, heading: heading}, opts.Sanitize)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(name, ext) {
+		name += ext
+	}
+
+	return storage.Join(opts.OutputDir, name), nil
+}
+
 // ParagraphRange represents a range of paragraphs
 type ParagraphRange struct {
 	Start int
@@ -315,13 +669,8 @@ func isHeading(para *docx.Paragraph, level int) bool {
 
 // sanitizeFilename removes invalid characters from a filename
 func sanitizeFilename(s string) string {
-	// Remove invalid filename characters
-	invalid := []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|"}
-	result := s
-
-	for _, char := range invalid {
-		result = strings.ReplaceAll(result, char, "_")
-	}
+	// Replace invalid filename characters
+	result := unsafeFilenameChars.ReplaceAllString(s, "_")
 
 	// Replace multiple spaces with single space
 	result = strings.Join(strings.Fields(result), " ")
@@ -375,7 +724,7 @@ func ParsePageRanges(rangeStr string, maxPages int) ([]PageRange, error) {
 			page--
 
 			if page < 0 || page >= maxPages {
-				return nil, fmt.Errorf("page %d out of range, document has %d pages", page+1, maxPages)
+				return nil, fmt.Errorf("page %d %w, document has %d pages", page+1, ErrIndexOutOfRange, maxPages)
 			}
 
 			ranges = append(ranges, PageRange{Start: page, End: page})
@@ -384,3 +733,108 @@ func ParsePageRanges(rangeStr string, maxPages int) ([]PageRange, error) {
 
 	return ranges, nil
 }
+
+// ParseSize parses a size such as "500KB", "10MB" or "2048" (bytes) into bytes.
+// Units are binary (1KB = 1024 bytes) and case-insensitive.
+func ParseSize(sizeStr string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(sizeStr))
+
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		bytes  int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.bytes
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid size: %s", sizeStr)
+	}
+
+	return int64(value * float64(multiplier)), nil
+}
+
+// partWriter saves the parts of a split to files, or to the entries of the
+// archive at SplitOptions.ZipOutput
+type partWriter struct {
+	dir  string
+	file io.WriteCloser
+	zip  *zip.Writer
+}
+
+// newPartWriter creates the archive parts are written into, if opts asks for one
+func newPartWriter(opts SplitOptions) (*partWriter, error) {
+	w := &partWriter{dir: opts.OutputDir}
+	if opts.ZipOutput == "" {
+		return w, nil
+	}
+	file, err := storage.Create(context.Background(), opts.ZipOutput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+	w.file = file
+	w.zip = zip.NewWriter(file)
+	return w, nil
+}
+
+// write saves part to outputPath, or to the matching archive entry,
+// returning the path or entry name it was saved to
+func (w *partWriter) write(outputPath string, part interface {
+	io.WriterTo
+	Save(string) error
+}) (string, error) {
+	if w.zip == nil {
+		if storage.IsRemote(outputPath) {
+			return outputPath, storage.Write(context.Background(), outputPath, part)
+		}
+		return outputPath, part.Save(outputPath)
+	}
+
+	name := outputPath
+	if rel, err := filepath.Rel(w.dir, outputPath); err == nil && filepath.IsLocal(rel) {
+		name = rel
+	}
+	name = filepath.ToSlash(name)
+	entry, err := w.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.WriteTo(entry); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// close finishes the archive
+func (w *partWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	if err := w.zip.Close(); err != nil {
+		w.abort()
+		return err
+	}
+	file := w.file
+	w.file = nil
+	return file.Close()
+}
+
+// abort removes an archive left unfinished by a failed split; one bound
+// for object storage is dropped without being uploaded
+func (w *partWriter) abort() {
+	if f, ok := w.file.(*os.File); ok {
+		f.Close()
+		os.Remove(f.Name())
+	}
+	w.file = nil
+}
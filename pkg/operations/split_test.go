@@ -1,9 +1,14 @@
 package operations
 
 import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/docx"
@@ -334,6 +339,157 @@ func TestSplitPDFByCount(t *testing.T) {
 	}
 }
 
+func TestSplitPDFBySize(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	newPDF := func(pages int) *pdf.Document {
+		doc := pdf.New()
+		for i := 0; i < pages; i++ {
+			page := doc.AddPage()
+			page.AddText(fmt.Sprintf("Page %d", i+1), 20, 30, 12)
+		}
+		return doc
+	}
+
+	inputPath := filepath.Join(tmpDir, "input.pdf")
+	if err := newPDF(6).Save(inputPath); err != nil {
+		t.Fatalf("Failed to save test PDF: %v", err)
+	}
+
+	budget, err := newPDF(3).Bytes()
+	if err != nil {
+		t.Fatalf("Failed to encode PDF: %v", err)
+	}
+	maxBytes := int64(len(budget))
+
+	opts := DefaultSplitOptions()
+	opts.OutputDir = tmpDir
+	outputFiles, err := SplitPDFBySize(inputPath, maxBytes, opts)
+	if err != nil {
+		t.Fatalf("Split by size failed: %v", err)
+	}
+	if len(outputFiles) < 2 {
+		t.Fatalf("Expected the PDF to be split, got %v", outputFiles)
+	}
+
+	totalPages := 0
+	for _, outPath := range outputFiles {
+		info, err := os.Stat(outPath)
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", outPath, err)
+		}
+		if info.Size() > maxBytes {
+			t.Errorf("Expected %s to be at most %d bytes, got %d", outPath, maxBytes, info.Size())
+		}
+		part, err := pdf.Open(outPath)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", outPath, err)
+		}
+		totalPages += part.GetPageCount()
+	}
+	if totalPages != 6 {
+		t.Errorf("Expected 6 pages across parts, got %d", totalPages)
+	}
+
+	if _, err := SplitPDFBySize(inputPath, 100, opts); err == nil {
+		t.Error("Expected error when a single page exceeds the budget")
+	}
+	if _, err := SplitPDFBySize(inputPath, 0, opts); err == nil {
+		t.Error("Expected error for zero budget")
+	}
+}
+
+func TestSplitPDFBySizeManyPages(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	doc := pdf.New()
+	for i := 0; i < 150; i++ {
+		page := doc.AddPage()
+		page.AddText(strings.Repeat(fmt.Sprintf("Page %d ", i+1), 1+i%7), 20, 30, 12)
+	}
+	inputPath := filepath.Join(tmpDir, "input.pdf")
+	if err := doc.Save(inputPath); err != nil {
+		t.Fatalf("Failed to save test PDF: %v", err)
+	}
+
+	const maxBytes = 8 * 1024
+	opts := DefaultSplitOptions()
+	opts.OutputDir = tmpDir
+	outputFiles, err := SplitPDFBySize(inputPath, maxBytes, opts)
+	if err != nil {
+		t.Fatalf("Split by size failed: %v", err)
+	}
+	if len(outputFiles) < 5 {
+		t.Fatalf("Expected the PDF to be split into several parts, got %v", outputFiles)
+	}
+
+	totalPages := 0
+	for i, outPath := range outputFiles {
+		info, err := os.Stat(outPath)
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", outPath, err)
+		}
+		if info.Size() > maxBytes {
+			t.Errorf("Expected %s to be at most %d bytes, got %d", outPath, maxBytes, info.Size())
+		}
+		part, err := pdf.Open(outPath)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", outPath, err)
+		}
+		pages := part.GetPageCount()
+		totalPages += pages
+
+		// Parts are as full as they can be: one more page would not fit
+		if i < len(outputFiles)-1 {
+			next := make([]int, 0, pages+1)
+			for p := totalPages - pages; p <= totalPages; p++ {
+				next = append(next, p)
+			}
+			if size, err := pdfPagesSize(doc, next...); err != nil || size <= maxBytes {
+				t.Errorf("Expected %s to take pages up to the limit, with the next page it is %d bytes", outPath, size)
+			}
+		}
+	}
+	if totalPages != 150 {
+		t.Errorf("Expected 150 pages across parts, got %d", totalPages)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    int64
+		expectError bool
+	}{
+		{"2048", 2048, false},
+		{"500KB", 500 * 1024, false},
+		{"10mb", 10 * 1024 * 1024, false},
+		{"1.5 MB", 3 * 512 * 1024, false},
+		{"1GB", 1 << 30, false},
+		{"", 0, true},
+		{"-5MB", 0, true},
+		{"10XB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			size, err := ParseSize(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %q, got %d", tt.input, size)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSize(%q) failed: %v", tt.input, err)
+			}
+			if size != tt.expected {
+				t.Errorf("ParseSize(%q) = %d, want %d", tt.input, size, tt.expected)
+			}
+		})
+	}
+}
+
 func TestParsePageRanges(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -488,6 +644,306 @@ func TestSplitDOCXByHeadings(t *testing.T) {
 	}
 }
 
+func TestPlanSplitDOCXByHeadings(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("Cover")
+	doc.AddParagraph("Terms: General", docx.WithStyle("Heading1"))
+	doc.AddParagraph("Detail", docx.WithStyle("Heading2"))
+	doc.AddParagraph("Pricing", docx.WithStyle("Heading1"))
+	doc.AddParagraph("Rates")
+
+	opts := SplitOptions{OutputPattern: "{n}_{title}", OutputDir: "out"}
+	parts, err := PlanSplitDOCXByHeadings(doc, "contract.docx", 1, opts)
+	if err != nil {
+		t.Fatalf("PlanSplitDOCXByHeadings failed: %v", err)
+	}
+
+	want := []SplitPart{
+		{Range: ParagraphRange{Start: 1, End: 2}, Title: "Terms: General", Output: filepath.Join("out", "1_Terms_ General.docx")},
+		{Range: ParagraphRange{Start: 3, End: 4}, Title: "Pricing", Output: filepath.Join("out", "2_Pricing.docx")},
+	}
+	if len(parts) != len(want) {
+		t.Fatalf("Expected %d parts, got %+v", len(want), parts)
+	}
+	for i := range want {
+		if parts[i] != want[i] {
+			t.Errorf("Part %d: expected %+v, got %+v", i+1, want[i], parts[i])
+		}
+	}
+
+	if _, err := PlanSplitDOCXByHeadings(doc, "contract.docx", 3, opts); err == nil {
+		t.Error("Expected an error without headings at level 3")
+	}
+}
+
+func TestSplitDOCXByBookmarks(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	doc := docx.New()
+	doc.AddParagraph("Preamble")
+	doc.AddParagraph("Terms")
+	doc.AddParagraph("Terms detail")
+	doc.AddParagraph("Pricing")
+	doc.AddParagraph("Cursor position")
+	for _, b := range []struct {
+		index int
+		name  string
+	}{{1, "terms"}, {3, "pricing"}, {4, "_GoBack"}} {
+		if err := doc.AddBookmark(b.index, b.name); err != nil {
+			t.Fatalf("AddBookmark failed: %v", err)
+		}
+	}
+
+	inputPath := filepath.Join(tmpDir, "contract.docx")
+	if err := doc.Save(inputPath); err != nil {
+		t.Fatalf("Failed to save test document: %v", err)
+	}
+
+	opts := SplitOptions{OutputPattern: "{title}.docx", OutputDir: tmpDir}
+	outputFiles, err := SplitDOCXByBookmarks(inputPath, opts)
+	if err != nil {
+		t.Fatalf("Split by bookmarks failed: %v", err)
+	}
+
+	wantFiles := []string{filepath.Join(tmpDir, "terms.docx"), filepath.Join(tmpDir, "pricing.docx")}
+	wantParas := []int{2, 2} // Hidden bookmarks don't start a new part
+	if len(outputFiles) != len(wantFiles) {
+		t.Fatalf("Expected %d output files, got %v", len(wantFiles), outputFiles)
+	}
+	for i, outPath := range outputFiles {
+		if outPath != wantFiles[i] {
+			t.Errorf("Expected output %s, got %s", wantFiles[i], outPath)
+		}
+		part, err := docx.Open(outPath)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", outPath, err)
+		}
+		if part.GetParagraphCount() != wantParas[i] {
+			t.Errorf("Expected %d paragraphs in %s, got %d", wantParas[i], outPath, part.GetParagraphCount())
+		}
+	}
+
+	plain := filepath.Join(tmpDir, "plain.docx")
+	if err := docx.New().Save(plain); err != nil {
+		t.Fatalf("Failed to save test document: %v", err)
+	}
+	if _, err := SplitDOCXByBookmarks(plain, opts); err == nil {
+		t.Error("Expected error for document without bookmarks")
+	}
+}
+
+func TestSplitDOCXBySections(t *testing.T) {
+	tmpDir := t.TempDir()
+	sectionName := xml.Name{Space: "http://schemas.openxmlformats.org/wordprocessingml/2006/main", Local: "sectPr"}
+
+	doc := docx.New()
+	doc.AddParagraph("Portrait 1")
+	doc.AddParagraph("Portrait 2")
+	doc.AddParagraph("Landscape")
+	doc.Body.Paragraphs[1].Props = &docx.PProps{SectPr: &docx.RawElement{
+		XMLName: sectionName,
+		Inner:   []byte(`<w:headerReference w:type="default" r:id="rId9"/><w:pgSz w:w="12240" w:h="15840"/>`),
+	}}
+	doc.Body.SectPr = &docx.RawElement{
+		XMLName: sectionName,
+		Inner:   []byte(`<w:pgSz w:w="15840" w:h="12240" w:orient="landscape"/>`),
+	}
+
+	inputPath := filepath.Join(tmpDir, "report.docx")
+	if err := doc.Save(inputPath); err != nil {
+		t.Fatalf("Failed to save test document: %v", err)
+	}
+
+	outputFiles, err := SplitDOCXBySections(inputPath, SplitOptions{OutputPattern: "{base}_s{n}", OutputDir: tmpDir})
+	if err != nil {
+		t.Fatalf("Split by sections failed: %v", err)
+	}
+	if len(outputFiles) != 2 {
+		t.Fatalf("Expected 2 output files, got %v", outputFiles)
+	}
+
+	first, err := docx.Open(outputFiles[0])
+	if err != nil {
+		t.Fatalf("Failed to open first section: %v", err)
+	}
+	if first.GetParagraphCount() != 2 || len(first.SectionBreaks()) != 0 {
+		t.Errorf("Expected 2 paragraphs and no section break in first part")
+	}
+	if first.Body.SectPr == nil {
+		t.Fatal("Expected first part to keep its page setup")
+	}
+	inner := string(first.Body.SectPr.Inner)
+	if !strings.Contains(inner, `w:w="12240"`) || !strings.Contains(inner, "headerReference") {
+		t.Errorf("Expected page size and header reference, got %s", inner)
+	}
+
+	second, err := docx.Open(outputFiles[1])
+	if err != nil {
+		t.Fatalf("Failed to open second section: %v", err)
+	}
+	if second.Body.SectPr == nil || !strings.Contains(string(second.Body.SectPr.Inner), "landscape") {
+		t.Errorf("Expected second part to be landscape")
+	}
+}
+
+func TestSplitDOCXByEstimatedPages(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Five pages of ten words, the third starting with a page break
+	doc := docx.New()
+	for page := 1; page <= 5; page++ {
+		var opts []docx.ParagraphOption
+		if page == 3 {
+			opts = append(opts, docx.WithPageBreakBefore())
+		}
+		doc.AddParagraph(fmt.Sprintf("page %d one two three", page), opts...)
+		doc.AddParagraph("four five six seven eight")
+	}
+	inputPath := filepath.Join(tmpDir, "long.docx")
+	if err := doc.Save(inputPath); err != nil {
+		t.Fatalf("Failed to save test document: %v", err)
+	}
+
+	opts := DefaultSplitOptions()
+	opts.OutputDir = tmpDir
+	opts.PageEstimate.WordsPerPage = 10
+	outputFiles, err := SplitDOCXByEstimatedPages(inputPath, 2, opts)
+	if err != nil {
+		t.Fatalf("Split by estimated pages failed: %v", err)
+	}
+	if len(outputFiles) != 3 {
+		t.Fatalf("Expected 3 output files, got %v", outputFiles)
+	}
+	for i, want := range []int{4, 4, 2} {
+		part, err := docx.Open(outputFiles[i])
+		if err != nil {
+			t.Fatalf("Failed to open part %d: %v", i+1, err)
+		}
+		if got := part.GetParagraphCount(); got != want {
+			t.Errorf("Expected %d paragraphs in part %d, got %d", want, i+1, got)
+		}
+	}
+
+	if _, err := SplitDOCXByEstimatedPages(inputPath, 0, opts); err == nil {
+		t.Error("Expected an error for zero pages per part")
+	}
+}
+
+func TestSplitZipOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	doc := docx.New()
+	for i := 0; i < 6; i++ {
+		doc.AddParagraph(fmt.Sprintf("Paragraph %d", i+1))
+	}
+	inputPath := filepath.Join(tmpDir, "book.docx")
+	if err := doc.Save(inputPath); err != nil {
+		t.Fatalf("Failed to save test document: %v", err)
+	}
+
+	opts := DefaultSplitOptions()
+	opts.OutputDir = filepath.Join(tmpDir, "parts")
+	opts.OutputPattern = "chapters/{base}_{n}"
+	opts.ZipOutput = filepath.Join(tmpDir, "book.zip")
+	entries, err := SplitDOCXByCount(inputPath, 3, opts)
+	if err != nil {
+		t.Fatalf("Split to archive failed: %v", err)
+	}
+	if len(entries) != 3 || entries[0] != "chapters/book_1.docx" {
+		t.Fatalf("Expected 3 entries named after the pattern, got %v", entries)
+	}
+	if _, err := os.Stat(opts.OutputDir); !os.IsNotExist(err) {
+		t.Errorf("Expected no files outside the archive, got %v", err)
+	}
+
+	archive, err := zip.OpenReader(opts.ZipOutput)
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	defer archive.Close()
+	if len(archive.File) != 3 {
+		t.Fatalf("Expected 3 files in the archive, got %d", len(archive.File))
+	}
+	for i, f := range archive.File {
+		if f.Name != entries[i] {
+			t.Errorf("Expected entry %s, got %s", entries[i], f.Name)
+		}
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("Failed to open entry: %v", err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("Failed to read entry: %v", err)
+		}
+		part, err := docx.ReadBytes(data)
+		if err != nil {
+			t.Fatalf("Entry %s is not a document: %v", f.Name, err)
+		}
+		if part.GetParagraphCount() != 2 {
+			t.Errorf("Expected 2 paragraphs in %s, got %d", f.Name, part.GetParagraphCount())
+		}
+	}
+
+	// A failed split leaves no archive behind
+	opts.ZipOutput = filepath.Join(tmpDir, "failed.zip")
+	if _, err := SplitDOCXByParagraphs(inputPath, []ParagraphRange{{Start: 0, End: 1}, {Start: 4, End: 9}}, opts); err == nil {
+		t.Fatal("Expected an error for a range past the end")
+	}
+	if _, err := os.Stat(opts.ZipOutput); !os.IsNotExist(err) {
+		t.Errorf("Expected the unfinished archive to be removed, got %v", err)
+	}
+}
+
+func TestSplitDOCXKeepsTablesAndParts(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	doc := docx.New()
+	doc.AddParagraph("Chapter 1", docx.WithStyle("Heading1"))
+	doc.AddParagraph("Intro text")
+	doc.AddParagraph("Chapter 2", docx.WithStyle("Heading1"))
+	doc.AddTable(2, 2).SetCellText(0, 0, "Price")
+	doc.AddParagraph("Closing text")
+	styles := []byte(`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"/>`)
+	doc.SetPart("word/styles.xml", styles)
+	doc.SetPart("word/header1.xml", []byte(`<w:hdr/>`))
+
+	inputPath := filepath.Join(tmpDir, "book.docx")
+	if err := doc.Save(inputPath); err != nil {
+		t.Fatalf("Failed to save test document: %v", err)
+	}
+
+	outputFiles, err := SplitDOCXByHeadings(inputPath, 1, SplitOptions{OutputPattern: "part_{n}", OutputDir: tmpDir})
+	if err != nil {
+		t.Fatalf("Split by headings failed: %v", err)
+	}
+	if len(outputFiles) != 2 {
+		t.Fatalf("Expected 2 output files, got %v", outputFiles)
+	}
+
+	wantTables := []int{0, 1}
+	for i, outPath := range outputFiles {
+		part, err := docx.Open(outPath)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", outPath, err)
+		}
+		if part.GetTableCount() != wantTables[i] {
+			t.Errorf("Expected %d tables in %s, got %d", wantTables[i], outPath, part.GetTableCount())
+		}
+		if data, ok := part.GetPart("word/styles.xml"); !ok || string(data) != string(styles) {
+			t.Errorf("Expected styles to be carried into %s", outPath)
+		}
+		if _, ok := part.GetPart("word/header1.xml"); !ok {
+			t.Errorf("Expected header part to be carried into %s", outPath)
+		}
+	}
+
+	second, _ := docx.Open(outputFiles[1])
+	if got := second.Body.Tables[0].Position; got != 1 {
+		t.Errorf("Expected table between the heading and closing text, got position %d", got)
+	}
+}
+
 func TestSplitErrors(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -571,3 +1027,13 @@ func TestSplitByCountErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorTypes(t *testing.T) {
+	_, err := ParsePageRanges("1,7", 5)
+	if !errors.Is(err, ErrIndexOutOfRange) || !errors.Is(err, docx.ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+	if err := MergeDocuments([]string{"a.txt", "b.txt"}, "out.txt", DefaultMergeOptions()); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+	}
+}
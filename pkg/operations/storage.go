@@ -0,0 +1,29 @@
+package operations
+
+import (
+	"context"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
+	"github.com/Palaciodiego008/docxsmith/pkg/storage"
+)
+
+// Operations take storage URIs, such as s3://bucket/key, wherever they take
+// a path; these open and save documents at either. Documents opened are
+// closed by the operation once it is done with them.
+
+func openDOCX(path string) (*docx.Document, error) {
+	return storage.OpenDOCX(context.Background(), path)
+}
+
+func saveDOCX(doc *docx.Document, path string) error {
+	return storage.SaveDOCX(context.Background(), doc, path)
+}
+
+func openPDF(path string) (*pdf.Document, error) {
+	return storage.OpenPDF(context.Background(), path)
+}
+
+func savePDF(doc *pdf.Document, path string) error {
+	return storage.SavePDF(context.Background(), doc, path)
+}
@@ -0,0 +1,165 @@
+package operations
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/storage"
+)
+
+// memStore is an in-memory storage.FileStore standing in for object storage
+type memStore struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (m *memStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memStore) Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	return &memFile{store: m, name: name}, nil
+}
+
+type memFile struct {
+	bytes.Buffer
+	store *memStore
+	name  string
+}
+
+func (f *memFile) Close() error {
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+	f.store.files[f.name] = f.Bytes()
+	return nil
+}
+
+func registerMemStore(t *testing.T) *memStore {
+	t.Helper()
+	store := &memStore{files: make(map[string][]byte)}
+	storage.Register("memtest", func(bucket string) (storage.FileStore, error) {
+		return store, nil
+	})
+	return store
+}
+
+func TestOperationsOnStorageURIs(t *testing.T) {
+	store := registerMemStore(t)
+	ctx := context.Background()
+	for i := 1; i <= 2; i++ {
+		doc := docx.New()
+		for j := 1; j <= 3; j++ {
+			doc.AddParagraph(fmt.Sprintf("Document %d paragraph %d", i, j))
+		}
+		if err := storage.SaveDOCX(ctx, doc, fmt.Sprintf("memtest://bucket/in/doc%d.docx", i)); err != nil {
+			t.Fatalf("SaveDOCX failed: %v", err)
+		}
+	}
+
+	opts := DefaultMergeOptions()
+	opts.AddPageBreaks = false
+	if err := MergeDOCX([]string{"memtest://bucket/in/doc1.docx", "memtest://bucket/in/doc2.docx"}, "memtest://bucket/merged.docx", opts); err != nil {
+		t.Fatalf("MergeDOCX failed: %v", err)
+	}
+	merged, err := storage.OpenDOCX(ctx, "memtest://bucket/merged.docx")
+	if err != nil {
+		t.Fatalf("Expected the merged document in the store: %v", err)
+	}
+	if got := merged.GetParagraphCount(); got != 6 {
+		t.Errorf("Expected 6 merged paragraphs, got %d", got)
+	}
+
+	splitOpts := DefaultSplitOptions()
+	splitOpts.OutputDir = "memtest://bucket/out"
+	outputs, err := SplitDOCXByCount("memtest://bucket/merged.docx", 2, splitOpts)
+	if err != nil {
+		t.Fatalf("SplitDOCXByCount failed: %v", err)
+	}
+	if len(outputs) != 2 || outputs[0] != "memtest://bucket/out/part_1.docx" {
+		t.Fatalf("Expected 2 parts under the URI, got %v", outputs)
+	}
+	for _, name := range []string{"out/part_1.docx", "out/part_2.docx"} {
+		if _, ok := store.files[name]; !ok {
+			t.Errorf("Expected %s in the store", name)
+		}
+	}
+
+	splitOpts.ZipOutput = "memtest://bucket/parts.zip"
+	if _, err := SplitDOCXByCount("memtest://bucket/merged.docx", 3, splitOpts); err != nil {
+		t.Fatalf("Split to archive failed: %v", err)
+	}
+	data := store.files["parts.zip"]
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Expected an archive in the store: %v", err)
+	}
+	if len(archive.File) != 3 || archive.File[0].Name != "part_1.docx" {
+		t.Errorf("Expected 3 archive entries relative to the output URI, got %d", len(archive.File))
+	}
+
+	if err := MergeDOCX([]string{"memtest://bucket/in/missing.docx"}, "memtest://bucket/none.docx", opts); err == nil {
+		t.Error("Expected an error for a missing object")
+	}
+}
+
+// openFiles counts the file descriptors of the process, or -1 where they
+// can't be listed
+func openFiles() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(entries)
+}
+
+func TestOperationsCloseDocuments(t *testing.T) {
+	if openFiles() < 0 {
+		t.Skip("open files can't be counted here")
+	}
+
+	// A part of a megabyte or more keeps the file open until it is closed
+	dir := t.TempDir()
+	doc := docx.New()
+	for i := 0; i < 4; i++ {
+		doc.AddParagraph(fmt.Sprintf("Paragraph %d", i))
+	}
+	doc.SetPart("word/media/video.mp4", bytes.Repeat([]byte("video"), 1<<18))
+	input := filepath.Join(dir, "input.docx")
+	if err := doc.Save(input); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	before := openFiles()
+	opts := DefaultSplitOptions()
+	opts.OutputDir = dir
+	if _, err := SplitDOCXByCount(input, 2, opts); err != nil {
+		t.Fatalf("SplitDOCXByCount failed: %v", err)
+	}
+	if err := ExtractDOCXRange(input, filepath.Join(dir, "range.docx"), ParagraphRange{Start: 0, End: 1}); err != nil {
+		t.Fatalf("ExtractDOCXRange failed: %v", err)
+	}
+	if err := MergeDOCX([]string{input, input}, filepath.Join(dir, "merged.docx"), DefaultMergeOptions()); err != nil {
+		t.Fatalf("MergeDOCX failed: %v", err)
+	}
+	if _, err := GetMergeDOCXInfo([]string{input}); err != nil {
+		t.Fatalf("GetMergeDOCXInfo failed: %v", err)
+	}
+	if after := openFiles(); after > before {
+		t.Errorf("Expected the operations to close the documents they open, %d files open before and %d after", before, after)
+	}
+}
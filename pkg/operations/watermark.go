@@ -0,0 +1,94 @@
+package operations
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
+)
+
+// WatermarkOptions holds options for watermarking documents
+type WatermarkOptions struct {
+	Color      string  // Hex color, e.g. "C0C0C0"; defaults to light gray
+	Opacity    float64 // 0 to 1; defaults depend on the format
+	Horizontal bool    // Horizontal instead of diagonal
+
+	// Logger receives a record for the file watermarked; nil logs nothing
+	Logger *slog.Logger
+}
+
+// Watermark stamps text such as "DRAFT" or "CONFIDENTIAL" across every page
+// of a DOCX or PDF file. PDF input must have been written by DocxSmith or
+// be text-only, since pages are re-rendered from their text.
+func Watermark(inputPath, outputPath, text string, opts WatermarkOptions) error {
+	start := time.Now()
+	var err error
+	switch fileFormat(inputPath) {
+	case ".docx":
+		err = watermarkDOCX(inputPath, outputPath, text, opts)
+	case ".pdf":
+		err = watermarkPDF(inputPath, outputPath, text, opts)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, filepath.Ext(inputPath))
+	}
+	if err == nil {
+		loggerOrDiscard(opts.Logger).Info("document watermarked", "input", inputPath, "output", outputPath, elapsed(start))
+	}
+	return err
+}
+
+// watermarkDOCX adds the watermark to the headers of a DOCX file
+func watermarkDOCX(inputPath, outputPath, text string, opts WatermarkOptions) error {
+	doc, err := openDOCX(inputPath)
+	if err != nil {
+		return err
+	}
+	defer doc.Close()
+
+	var wmOpts []docx.WatermarkOption
+	if opts.Color != "" {
+		wmOpts = append(wmOpts, docx.WithWatermarkColor(opts.Color))
+	}
+	if opts.Opacity != 0 {
+		wmOpts = append(wmOpts, docx.WithWatermarkOpacity(opts.Opacity))
+	}
+	if opts.Horizontal {
+		wmOpts = append(wmOpts, docx.WithWatermarkHorizontal())
+	}
+	if err := doc.SetWatermark(text, wmOpts...); err != nil {
+		return err
+	}
+	return saveDOCX(doc, outputPath)
+}
+
+// watermarkPDF draws the watermark on every page of a PDF file
+func watermarkPDF(inputPath, outputPath, text string, opts WatermarkOptions) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("watermark text is required")
+	}
+	if opts.Opacity < 0 || opts.Opacity > 1 {
+		return fmt.Errorf("watermark opacity must be between 0 and 1, got %g", opts.Opacity)
+	}
+
+	doc, err := openPDF(inputPath)
+	if err != nil {
+		return err
+	}
+
+	var wmOpts []pdf.WatermarkOption
+	if opts.Color != "" {
+		wmOpts = append(wmOpts, pdf.WithWatermarkColor(opts.Color))
+	}
+	if opts.Opacity != 0 {
+		wmOpts = append(wmOpts, pdf.WithWatermarkOpacity(opts.Opacity))
+	}
+	if opts.Horizontal {
+		wmOpts = append(wmOpts, pdf.WithWatermarkAngle(0))
+	}
+	doc.SetWatermark(text, wmOpts...)
+	return savePDF(doc, outputPath)
+}
@@ -0,0 +1,58 @@
+package operations
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
+)
+
+func TestWatermark(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	docxPath := filepath.Join(tmpDir, "contract.docx")
+	doc := docx.New()
+	doc.AddParagraph("Terms")
+	if err := doc.Save(docxPath); err != nil {
+		t.Fatalf("Failed to save DOCX: %v", err)
+	}
+
+	draftPath := filepath.Join(tmpDir, "draft.docx")
+	if err := Watermark(docxPath, draftPath, "DRAFT", WatermarkOptions{Color: "FF0000"}); err != nil {
+		t.Fatalf("Watermark failed for DOCX: %v", err)
+	}
+	draft, err := docx.Open(draftPath)
+	if err != nil {
+		t.Fatalf("Failed to open watermarked DOCX: %v", err)
+	}
+	if !draft.HasWatermark() {
+		t.Error("Expected DOCX to have a watermark")
+	}
+
+	pdfPath := filepath.Join(tmpDir, "report.pdf")
+	pdfDoc := pdf.New()
+	pdfDoc.AddPage().AddText("Quarterly report", 20, 30, 12)
+	if err := pdfDoc.Save(pdfPath); err != nil {
+		t.Fatalf("Failed to save PDF: %v", err)
+	}
+
+	if err := Watermark(pdfPath, pdfPath, "CONFIDENTIAL", WatermarkOptions{Horizontal: true}); err != nil {
+		t.Fatalf("Watermark failed for PDF: %v", err)
+	}
+	stamped, err := pdf.Open(pdfPath)
+	if err != nil {
+		t.Fatalf("Failed to open watermarked PDF: %v", err)
+	}
+	if !strings.Contains(stamped.GetAllText(), "CONFIDENTIAL") {
+		t.Errorf("Expected PDF to contain the watermark, got: %s", stamped.GetAllText())
+	}
+
+	if err := Watermark(pdfPath, pdfPath, "DRAFT", WatermarkOptions{Opacity: 2}); err == nil {
+		t.Error("Expected error for invalid opacity")
+	}
+	if err := Watermark(filepath.Join(tmpDir, "notes.txt"), "", "DRAFT", WatermarkOptions{}); err == nil {
+		t.Error("Expected error for unsupported file type")
+	}
+}
@@ -0,0 +1,157 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// Rect is an area of a page in mm, from its top-left corner
+type Rect struct {
+	X, Y          float64
+	Width, Height float64
+}
+
+// Annotation represents a mark on a page that viewers show over its
+// content, such as a highlight, note or link
+type Annotation interface {
+	Type() string
+}
+
+// HighlightAnnotation marks an area of a page as highlighted
+type HighlightAnnotation struct {
+	Rect  Rect
+	Color string // Hex color, e.g. "FFFF00"
+}
+
+func (h HighlightAnnotation) Type() string { return "highlight" }
+
+// NoteAnnotation is a comment shown as an icon at a point of a page
+type NoteAnnotation struct {
+	X, Y  float64
+	Text  string
+	Color string // Hex color of the icon, e.g. "FFFF00"
+}
+
+func (n NoteAnnotation) Type() string { return "note" }
+
+// LinkAnnotation makes an area of a page open a URL when clicked
+type LinkAnnotation struct {
+	Rect Rect
+	URL  string
+}
+
+func (l LinkAnnotation) Type() string { return "link" }
+
+// noteIconSize is the size of the area a note's icon takes, in mm
+const noteIconSize = 7.0
+
+// AddHighlight highlights an area of the page in a hex color, yellow if
+// color is empty
+func (p *Page) AddHighlight(rect Rect, color string) {
+	if color == "" {
+		color = "FFFF00"
+	}
+	p.Annotations = append(p.Annotations, HighlightAnnotation{Rect: rect, Color: color})
+}
+
+// AddNote adds a comment whose icon sits at x, y on the page
+func (p *Page) AddNote(x, y float64, text string) {
+	p.Annotations = append(p.Annotations, NoteAnnotation{X: x, Y: y, Text: text, Color: "FFFF00"})
+}
+
+// AddLink makes an area of the page open url when clicked
+func (p *Page) AddLink(rect Rect, url string) {
+	p.Annotations = append(p.Annotations, LinkAnnotation{Rect: rect, URL: url})
+}
+
+// writeAnnotations adds the annotations of every page to the update, each
+// as an object listed in the /Annots of its page
+func (d *Document) writeAnnotations(u *pdfUpdate) {
+	for i, page := range d.Pages {
+		if len(page.Annotations) == 0 {
+			continue
+		}
+		height := page.Height
+		if height <= 0 {
+			height = 297
+		}
+
+		var refs []string
+		for _, a := range page.Annotations {
+			var obj string
+			switch a := a.(type) {
+			case HighlightAnnotation:
+				obj = highlightObject(u, a, height)
+			case NoteAnnotation:
+				rect := pdfRect(Rect{X: a.X, Y: a.Y, Width: noteIconSize, Height: noteIconSize}, height)
+				obj = fmt.Sprintf("<</Type /Annot /Subtype /Text /Rect %s /Contents %s /Name /Comment /C %s /F 4>>",
+					rect, pdfString(a.Text), pdfColor(a.Color))
+			case LinkAnnotation:
+				obj = fmt.Sprintf("<</Type /Annot /Subtype /Link /Rect %s /Border [0 0 0] /A <</S /URI /URI %s>>>>",
+					pdfRect(a.Rect, height), pdfString(a.URL))
+			default:
+				continue
+			}
+			refs = append(refs, u.addObject(obj))
+		}
+		if len(refs) > 0 {
+			u.addPageEntry(i+1, "/Annots ["+strings.Join(refs, " ")+"]")
+		}
+	}
+}
+
+// highlightObject returns a highlight annotation with an appearance that
+// multiplies its color into the page, so text under it stays readable in
+// viewers that don't draw highlights themselves
+func highlightObject(u *pdfUpdate, h HighlightAnnotation, pageHeight float64) string {
+	r, g, b := hexToRGB(h.Color)
+	w, ht := h.Rect.Width*mmToPt, h.Rect.Height*mmToPt
+	stream := fmt.Sprintf("/GS0 gs %.3f %.3f %.3f rg 0 0 %.2f %.2f re f", float64(r)/255, float64(g)/255, float64(b)/255, w, ht)
+	appearance := u.addObject(fmt.Sprintf("<</Type /XObject /Subtype /Form /BBox [0 0 %.2f %.2f] "+
+		"/Resources <</ExtGState <</GS0 <</BM /Multiply>>>>>> /Length %d>>\nstream\n%s\nendstream", w, ht, len(stream), stream))
+
+	x1 := h.Rect.X * mmToPt
+	y1 := (pageHeight - h.Rect.Y - h.Rect.Height) * mmToPt
+	x2, y2 := x1+w, y1+ht
+	return fmt.Sprintf("<</Type /Annot /Subtype /Highlight /Rect %s /QuadPoints [%.2f %.2f %.2f %.2f %.2f %.2f %.2f %.2f] /C %s /F 4 /AP <</N %s>>>>",
+		pdfRect(h.Rect, pageHeight), x1, y2, x2, y2, x1, y1, x2, y1, pdfColor(h.Color), appearance)
+}
+
+// mmToPt converts mm to PDF points
+const mmToPt = 72 / 25.4
+
+// pdfRect returns an area of a page of the given height in mm as a PDF
+// rectangle, in points from the bottom-left corner
+func pdfRect(r Rect, pageHeight float64) string {
+	return fmt.Sprintf("[%.2f %.2f %.2f %.2f]",
+		r.X*mmToPt, (pageHeight-r.Y-r.Height)*mmToPt, (r.X+r.Width)*mmToPt, (pageHeight-r.Y)*mmToPt)
+}
+
+// pdfColor returns a hex color as a PDF RGB array
+func pdfColor(hex string) string {
+	r, g, b := hexToRGB(hex)
+	return fmt.Sprintf("[%.3f %.3f %.3f]", float64(r)/255, float64(g)/255, float64(b)/255)
+}
+
+// pdfString returns text as a PDF string literal, in UTF-16 if it isn't ASCII
+func pdfString(text string) string {
+	ascii := true
+	for _, r := range text {
+		if r > 0x7e {
+			ascii = false
+			break
+		}
+	}
+	if !ascii {
+		var sb strings.Builder
+		sb.WriteString("<FEFF")
+		for _, u := range utf16.Encode([]rune(text)) {
+			fmt.Fprintf(&sb, "%04X", u)
+		}
+		sb.WriteString(">")
+		return sb.String()
+	}
+	r := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`, "\r", `\r`, "\n", `\n`)
+	return "(" + r.Replace(text) + ")"
+}
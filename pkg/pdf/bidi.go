@@ -0,0 +1,306 @@
+package pdf
+
+import (
+	"slices"
+	"unicode"
+)
+
+// bidiClass is the direction a character takes in a line of text, a
+// simplified form of the classes of the Unicode bidirectional algorithm
+type bidiClass int
+
+const (
+	bidiNeutral    bidiClass = iota // Spaces and punctuation
+	bidiL                           // Left-to-right letters
+	bidiR                           // Right-to-left letters, as in Hebrew and Arabic
+	bidiNumber                      // Digits
+	bidiSeparator                   // Punctuation within numbers, e.g. "," in "1,000"
+	bidiTerminator                  // Signs before or after numbers, e.g. "%" and "€"
+	bidiMark                        // Combining marks, which take the class of their letter
+)
+
+// isRTL reports whether r is a letter of a right-to-left script
+func isRTL(r rune) bool {
+	return r >= 0x0590 && r <= 0x08FF || r >= 0xFB1D && r <= 0xFDFF || r >= 0xFE70 && r <= 0xFEFE ||
+		r >= 0x10800 && r <= 0x10FFF || r >= 0x1E800 && r <= 0x1EFFF
+}
+
+// classify returns the bidirectional class of r
+func classify(r rune) bidiClass {
+	switch {
+	case r >= '0' && r <= '9', r >= 0x0660 && r <= 0x0669, r >= 0x06F0 && r <= 0x06F9:
+		return bidiNumber
+	case unicode.Is(unicode.Mn, r):
+		return bidiMark
+	case isRTL(r):
+		return bidiR
+	case unicode.IsLetter(r) || unicode.IsMark(r):
+		return bidiL
+	case r == ',' || r == '.' || r == ':' || r == '/':
+		return bidiSeparator
+	case r == '%' || r == '#' || r == '+' || r == '-' || r == '°' || unicode.Is(unicode.Sc, r):
+		return bidiTerminator
+	}
+	return bidiNeutral
+}
+
+// baseRTL reports whether a line is right-to-left: dir is "rtl" or "ltr",
+// or else the direction of the first letter of text
+func baseRTL(text, dir string) bool {
+	switch dir {
+	case "rtl":
+		return true
+	case "ltr":
+		return false
+	}
+	for _, r := range text {
+		switch classify(r) {
+		case bidiL:
+			return false
+		case bidiR:
+			return true
+		}
+	}
+	return false
+}
+
+// mirrored pairs characters drawn mirrored in right-to-left text
+var mirrored = map[rune]rune{
+	'(': ')', ')': '(', '[': ']', ']': '[', '{': '}', '}': '{', '<': '>', '>': '<',
+	'«': '»', '»': '«', '‹': '›', '›': '‹',
+}
+
+// visualOrder returns a line of text with its characters in the order they
+// are drawn from left to right, following the Unicode bidirectional
+// algorithm without its explicit embeddings: right-to-left words are
+// reversed, numbers read left to right among them, and brackets in
+// right-to-left text are mirrored. dir sets the base direction as in baseRTL.
+func visualOrder(text, dir string) string {
+	rtl := baseRTL(text, dir)
+	runes := []rune(text)
+	if !rtl && !slices.ContainsFunc(runes, isRTL) {
+		return text
+	}
+
+	base := bidiL
+	if rtl {
+		base = bidiR
+	}
+	n := len(runes)
+	classes := make([]bidiClass, n)
+	for i, r := range runes {
+		classes[i] = classify(r)
+	}
+
+	// Marks take the class of the character they sit on
+	prev := base
+	for i, c := range classes {
+		if c == bidiMark {
+			classes[i] = prev
+		}
+		prev = classes[i]
+	}
+
+	// A single separator between digits and signs next to digits are part of the number
+	for i := 1; i < n-1; i++ {
+		if classes[i] == bidiSeparator && classes[i-1] == bidiNumber && classes[i+1] == bidiNumber {
+			classes[i] = bidiNumber
+		}
+	}
+	for i := 0; i < n; {
+		j := i
+		for j < n && classes[j] == bidiTerminator {
+			j++
+		}
+		if j > i && (i > 0 && classes[i-1] == bidiNumber || j < n && classes[j] == bidiNumber) {
+			for k := i; k < j; k++ {
+				classes[k] = bidiNumber
+			}
+		}
+		i = max(j, i+1)
+	}
+
+	// Numbers after left-to-right text are left-to-right text
+	last := base
+	for i, c := range classes {
+		switch c {
+		case bidiL, bidiR:
+			last = c
+		case bidiNumber:
+			if last == bidiL {
+				classes[i] = bidiL
+			}
+		case bidiSeparator, bidiTerminator:
+			classes[i] = bidiNeutral
+		}
+	}
+
+	// Neutrals between text of one direction take it, others the base
+	// direction; numbers count as right-to-left here
+	direction := func(c bidiClass) bidiClass {
+		if c == bidiNumber {
+			return bidiR
+		}
+		return c
+	}
+	for i := 0; i < n; {
+		if classes[i] != bidiNeutral {
+			i++
+			continue
+		}
+		j := i
+		for j < n && classes[j] == bidiNeutral {
+			j++
+		}
+		before, after := base, base
+		if i > 0 {
+			before = direction(classes[i-1])
+		}
+		if j < n {
+			after = direction(classes[j])
+		}
+		resolved := base
+		if before == after {
+			resolved = before
+		}
+		for k := i; k < j; k++ {
+			classes[k] = resolved
+		}
+		i = j
+	}
+
+	// Embedding levels: odd levels are drawn right to left
+	levels := make([]int, n)
+	for i, c := range classes {
+		switch {
+		case c == bidiR:
+			levels[i] = 1
+		case c == bidiNumber || c == bidiL && rtl:
+			levels[i] = 2
+		}
+	}
+	baseLevel := 0
+	if rtl {
+		baseLevel = 1
+	}
+	for i := n - 1; i >= 0 && unicode.IsSpace(runes[i]); i-- {
+		levels[i] = baseLevel
+	}
+
+	for i, r := range runes {
+		if m, ok := mirrored[r]; ok && levels[i]%2 == 1 {
+			runes[i] = m
+		}
+	}
+
+	// From the highest level down, reverse every stretch at that level or above
+	for level := slices.Max(levels); level >= 1; level-- {
+		for i := 0; i < n; {
+			if levels[i] < level {
+				i++
+				continue
+			}
+			j := i
+			for j < n && levels[j] >= level {
+				j++
+			}
+			slices.Reverse(runes[i:j])
+			slices.Reverse(levels[i:j])
+			i = j
+		}
+	}
+	return string(runes)
+}
+
+// arabicLetter is how an Arabic letter joins its neighbours: its isolated
+// form in the presentation forms blocks, followed by its final, initial
+// and medial forms, and whether it joins the letter after it as well as
+// the one before
+type arabicLetter struct {
+	isolated rune
+	dual     bool
+}
+
+// arabicLetters are the letters of Arabic and Persian that change shape
+var arabicLetters = map[rune]arabicLetter{
+	0x0621: {0xFE80, false}, 0x0622: {0xFE81, false}, 0x0623: {0xFE83, false}, 0x0624: {0xFE85, false},
+	0x0625: {0xFE87, false}, 0x0626: {0xFE89, true}, 0x0627: {0xFE8D, false}, 0x0628: {0xFE8F, true},
+	0x0629: {0xFE93, false}, 0x062A: {0xFE95, true}, 0x062B: {0xFE99, true}, 0x062C: {0xFE9D, true},
+	0x062D: {0xFEA1, true}, 0x062E: {0xFEA5, true}, 0x062F: {0xFEA9, false}, 0x0630: {0xFEAB, false},
+	0x0631: {0xFEAD, false}, 0x0632: {0xFEAF, false}, 0x0633: {0xFEB1, true}, 0x0634: {0xFEB5, true},
+	0x0635: {0xFEB9, true}, 0x0636: {0xFEBD, true}, 0x0637: {0xFEC1, true}, 0x0638: {0xFEC5, true},
+	0x0639: {0xFEC9, true}, 0x063A: {0xFECD, true}, 0x0641: {0xFED1, true}, 0x0642: {0xFED5, true},
+	0x0643: {0xFED9, true}, 0x0644: {0xFEDD, true}, 0x0645: {0xFEE1, true}, 0x0646: {0xFEE5, true},
+	0x0647: {0xFEE9, true}, 0x0648: {0xFEED, false}, 0x0649: {0xFEEF, false}, 0x064A: {0xFEF1, true},
+	0x067E: {0xFB56, true}, 0x0686: {0xFB7A, true}, 0x0698: {0xFB8A, false}, 0x06A9: {0xFB8E, true},
+	0x06AF: {0xFB92, true}, 0x06CC: {0xFBFC, true},
+}
+
+// lamAlef gives the isolated form of the ligatures of lam with each alef
+var lamAlef = map[rune]rune{0x0622: 0xFEF5, 0x0623: 0xFEF7, 0x0625: 0xFEF9, 0x0627: 0xFEFB}
+
+const (
+	arabicLam     = 0x0644
+	arabicHamza   = 0x0621
+	arabicTatweel = 0x0640
+)
+
+// shapeArabic replaces the Arabic letters of text with the forms they take
+// joined to their neighbours, as fonts draw them without a shaping engine
+func shapeArabic(text string) string {
+	runes := []rune(text)
+	if !slices.ContainsFunc(runes, func(r rune) bool { return r >= 0x0621 && r <= 0x06CC }) {
+		return text
+	}
+
+	// joins reports whether the first letter from i on in the direction of
+	// step, skipping marks, joins the letter the search started beside;
+	// forward is set when that letter comes after it
+	joins := func(i, step int, forward bool) bool {
+		for ; i >= 0 && i < len(runes); i += step {
+			r := runes[i]
+			if unicode.Is(unicode.Mn, r) {
+				continue
+			}
+			if r == arabicTatweel {
+				return true
+			}
+			letter, ok := arabicLetters[r]
+			return ok && r != arabicHamza && (letter.dual || !forward)
+		}
+		return false
+	}
+
+	out := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		letter, ok := arabicLetters[r]
+		if !ok || r == arabicHamza {
+			out = append(out, r)
+			continue
+		}
+		joinsBefore := joins(i-1, -1, true)
+		if r == arabicLam && i+1 < len(runes) {
+			if ligature, ok := lamAlef[runes[i+1]]; ok {
+				if joinsBefore {
+					ligature++
+				}
+				out = append(out, ligature)
+				i++
+				continue
+			}
+		}
+		joinsAfter := letter.dual && joins(i+1, 1, false)
+		form := letter.isolated
+		switch {
+		case joinsBefore && joinsAfter:
+			form += 3
+		case joinsAfter:
+			form += 2
+		case joinsBefore:
+			form++
+		}
+		out = append(out, form)
+	}
+	return string(out)
+}
@@ -0,0 +1,158 @@
+package pdf
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Bookmark is an entry of the outline viewers show beside the pages,
+// leading to the top of a page
+type Bookmark struct {
+	Title string
+	Page  int // Index of the page, from 0
+	Level int // 0 for the top level, 1 for entries below it, and so on
+}
+
+// AddBookmark adds an entry to the outline leading to a page. Entries are
+// ordered by page, keeping the order they were added in for each page, and
+// an entry's level can be at most one deeper than the entry before it.
+func (d *Document) AddBookmark(title string, pageIndex, level int) error {
+	if pageIndex < 0 || pageIndex >= len(d.Pages) {
+		return fmt.Errorf("page index %d out of range", pageIndex)
+	}
+	if level < 0 {
+		return fmt.Errorf("bookmark level %d is negative", level)
+	}
+	d.Bookmarks = append(d.Bookmarks, Bookmark{Title: title, Page: pageIndex, Level: level})
+	return nil
+}
+
+// GetOutline returns the bookmarks in the order of the outline, with each
+// level at most one deeper than the entry before it
+func (d *Document) GetOutline() []Bookmark {
+	outline := make([]Bookmark, 0, len(d.Bookmarks))
+	for _, b := range d.Bookmarks {
+		if b.Page >= 0 && b.Page < len(d.Pages) {
+			outline = append(outline, b)
+		}
+	}
+	sort.SliceStable(outline, func(i, j int) bool { return outline[i].Page < outline[j].Page })
+
+	prev := -1
+	for i := range outline {
+		outline[i].Level = max(0, min(outline[i].Level, prev+1))
+		prev = outline[i].Level
+	}
+	return outline
+}
+
+// outlineNode is a bookmark in the tree written to the PDF
+type outlineNode struct {
+	Bookmark
+	num      int
+	parent   int // Index of the parent node, or -1 at the top level
+	children []int
+}
+
+// writeOutline adds the bookmarks to the update as the /Outlines of the
+// document, which viewers open with the outline shown
+func (d *Document) writeOutline(u *pdfUpdate) {
+	outline := d.GetOutline()
+	if len(outline) == 0 {
+		return
+	}
+
+	root := u.newObject()
+	nodes := make([]outlineNode, len(outline))
+	var top []int
+	var stack []int // Index of the last node at each level
+	for i, b := range outline {
+		nodes[i] = outlineNode{Bookmark: b, num: u.newObject(), parent: -1}
+		stack = append(stack[:b.Level], i)
+		if b.Level == 0 {
+			top = append(top, i)
+			continue
+		}
+		parent := stack[b.Level-1]
+		nodes[i].parent = parent
+		nodes[parent].children = append(nodes[parent].children, i)
+	}
+
+	ref := func(num int) string { return fmt.Sprintf("%d 0 R", num) }
+	links := func(siblings []int) {
+		for k, i := range siblings {
+			n := nodes[i]
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "<</Title %s", pdfString(n.Title))
+			if n.parent >= 0 {
+				fmt.Fprintf(&sb, " /Parent %s", ref(nodes[n.parent].num))
+			} else {
+				fmt.Fprintf(&sb, " /Parent %s", ref(root))
+			}
+			if k > 0 {
+				fmt.Fprintf(&sb, " /Prev %s", ref(nodes[siblings[k-1]].num))
+			}
+			if k < len(siblings)-1 {
+				fmt.Fprintf(&sb, " /Next %s", ref(nodes[siblings[k+1]].num))
+			}
+			if len(n.children) > 0 {
+				fmt.Fprintf(&sb, " /First %s /Last %s /Count %d",
+					ref(nodes[n.children[0]].num), ref(nodes[n.children[len(n.children)-1]].num), len(n.children))
+			}
+			height := d.Pages[n.Page].Height
+			if height <= 0 {
+				height = 297
+			}
+			fmt.Fprintf(&sb, " /Dest [%s /XYZ 0 %.2f null]>>", pageRef(n.Page+1), height*mmToPt)
+			u.setObject(n.num, sb.String())
+		}
+	}
+	links(top)
+	for _, n := range nodes {
+		links(n.children)
+	}
+
+	u.setObject(root, fmt.Sprintf("<</Type /Outlines /First %s /Last %s /Count %d>>",
+		ref(nodes[top[0]].num), ref(nodes[top[len(top)-1]].num), len(top)))
+	u.addCatalogEntry(fmt.Sprintf("/Outlines %s", ref(root)))
+	u.addCatalogEntry("/PageMode /UseOutlines")
+}
+
+// readOutline returns the bookmarks of a PDF's outline that lead to one of
+// its pages
+func readOutline(r *pdf.Reader) []Bookmark {
+	pages := make(map[string]int)
+	for i := 1; i <= r.NumPage(); i++ {
+		pages[objectID(r.Page(i).V)] = i - 1
+	}
+
+	var bookmarks []Bookmark
+	var walk func(item pdf.Value, level int)
+	walk = func(item pdf.Value, level int) {
+		// The depth is bounded in case the items of a damaged file form a loop
+		for count := 0; !item.IsNull() && level < 32 && count < 10000; count++ {
+			dest := item.Key("Dest")
+			if dest.IsNull() {
+				dest = item.Key("A").Key("D")
+			}
+			if page, ok := pages[objectID(dest.Index(0))]; ok && dest.Len() > 0 {
+				bookmarks = append(bookmarks, Bookmark{Title: item.Key("Title").Text(), Page: page, Level: level})
+			}
+			walk(item.Key("First"), level+1)
+			item = item.Key("Next")
+		}
+	}
+	walk(r.Trailer().Key("Root").Key("Outlines").Key("First"), 0)
+	return bookmarks
+}
+
+// objectID identifies the object a value was read from. The reader keeps
+// it unexported, but references to pages can only be matched to the pages
+// by it.
+func objectID(v pdf.Value) string {
+	return fmt.Sprint(reflect.ValueOf(v).FieldByName("ptr"))
+}
@@ -2,6 +2,11 @@ package pdf
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 // Document represents a PDF document structure
@@ -9,6 +14,41 @@ type Document struct {
 	FilePath string
 	Pages    []*Page
 	Metadata *Metadata
+
+	// PageNumbers is the footer stamped on every page when set, with {n}
+	// replaced by the page number and {total} by the page count
+	PageNumbers string
+
+	// Watermark is drawn beneath the content of every page when set
+	Watermark *Watermark
+
+	// Fonts are embedded in the PDF and used by text in their family
+	Fonts []Font
+
+	// FallbackFonts are the families of Fonts that characters are set in
+	// when the font of their text lacks them
+	FallbackFonts []string
+
+	// Bookmarks make up the outline viewers show beside the pages
+	Bookmarks []Bookmark
+
+	measurer *measurer
+}
+
+// Font is a TrueType font file for one style of a font family
+type Font struct {
+	Family string
+	Style  string // "", "B", "I" or "BI"
+	Data   []byte
+}
+
+// Watermark represents text drawn diagonally across each page
+type Watermark struct {
+	Text     string
+	FontSize float64
+	Color    string  // Hex color, e.g. "C0C0C0"
+	Angle    float64 // Degrees counter-clockwise
+	Opacity  float64 // 0 (invisible) to 1 (opaque)
 }
 
 // Page represents a single page in the PDF
@@ -18,6 +58,13 @@ type Page struct {
 	Width   float64
 	Height  float64
 	Margin  Margin
+
+	// Rotation is how far the page is turned clockwise when viewed, in
+	// degrees: 0, 90, 180 or 270
+	Rotation int
+
+	// Annotations are shown by viewers over the content of the page
+	Annotations []Annotation
 }
 
 // Content represents content on a page (text, image, table, etc.)
@@ -34,6 +81,15 @@ type TextContent struct {
 	Bold       bool
 	Italic     bool
 	Color      string
+
+	// Align is left (the default), center, right or justify, within Width
+	// mm from X, or up to the right margin if Width is 0
+	Align string
+	Width float64
+
+	// Direction is "rtl" or "ltr" to set the direction text is read in;
+	// otherwise it is that of its first letter
+	Direction string
 }
 
 func (t TextContent) Type() string { return "text" }
@@ -45,16 +101,22 @@ type TableContent struct {
 	ColumnWidth []float64
 	HeaderStyle *TextStyle
 	CellStyle   *TextStyle
+
+	// RowHeights are the heights of the rows in mm, worked out from the
+	// text of their cells when not given
+	RowHeights []float64
 }
 
 func (t TableContent) Type() string { return "table" }
 
-// ImageContent represents an image
+// ImageContent represents an image, read from Data or else from the file
+// at Path. PNG, JPEG and GIF images are supported; others are left out.
 type ImageContent struct {
 	Path   string
+	Data   []byte
 	X, Y   float64
-	Width  float64
-	Height float64
+	Width  float64 // Width in mm; 0 keeps the image's proportions
+	Height float64 // Height in mm; 0 keeps the image's proportions
 }
 
 func (i ImageContent) Type() string { return "image" }
@@ -67,6 +129,7 @@ type TextStyle struct {
 	Italic     bool
 	Color      string
 	Align      string
+	Direction  string // "rtl" or "ltr", or "" for that of the first letter
 }
 
 // Margin represents page margins
@@ -84,6 +147,10 @@ type Metadata struct {
 	Subject  string
 	Keywords string
 	Creator  string
+
+	// Created and Modified are the dates of the document, now when unset
+	Created  time.Time
+	Modified time.Time
 }
 
 // New creates a new empty PDF document
@@ -133,12 +200,111 @@ func (d *Document) DeletePage(index int) error {
 		return fmt.Errorf("page index %d out of range", index)
 	}
 	d.Pages = append(d.Pages[:index], d.Pages[index+1:]...)
+	d.renumberPages()
+	d.remapBookmarks(func(page int) int {
+		switch {
+		case page == index:
+			return -1
+		case page > index:
+			return page - 1
+		}
+		return page
+	})
+	return nil
+}
+
+// RotatePage turns a page clockwise by degrees, a multiple of 90; negative
+// degrees turn it counter-clockwise
+func (d *Document) RotatePage(index, degrees int) error {
+	if index < 0 || index >= len(d.Pages) {
+		return fmt.Errorf("page index %d out of range", index)
+	}
+	if degrees%90 != 0 {
+		return fmt.Errorf("rotation %d is not a multiple of 90 degrees", degrees)
+	}
+	page := d.Pages[index]
+	page.Rotation = normalizeRotation(page.Rotation + degrees)
+	return nil
+}
+
+// MovePage moves the page at index from so that it ends up at index to
+func (d *Document) MovePage(from, to int) error {
+	if from < 0 || from >= len(d.Pages) {
+		return fmt.Errorf("page index %d out of range", from)
+	}
+	if to < 0 || to >= len(d.Pages) {
+		return fmt.Errorf("page index %d out of range", to)
+	}
+	page := d.Pages[from]
+	d.Pages = slices.Insert(slices.Delete(d.Pages, from, from+1), to, page)
+	d.renumberPages()
+	d.remapBookmarks(func(page int) int {
+		switch {
+		case page == from:
+			return to
+		case from < to && page > from && page <= to:
+			return page - 1
+		case to < from && page >= to && page < from:
+			return page + 1
+		}
+		return page
+	})
+	return nil
+}
+
+// DuplicatePage inserts a copy of a page right after it
+func (d *Document) DuplicatePage(index int) error {
+	if index < 0 || index >= len(d.Pages) {
+		return fmt.Errorf("page index %d out of range", index)
+	}
+	page := *d.Pages[index]
+	page.Content = make([]Content, len(d.Pages[index].Content))
+	for i, content := range d.Pages[index].Content {
+		// Table rows are changed in place by Redact, so the copy gets its own
+		if tc, ok := content.(TableContent); ok {
+			rows := make([][]string, len(tc.Rows))
+			for j, row := range tc.Rows {
+				rows[j] = slices.Clone(row)
+			}
+			tc.Rows = rows
+			content = tc
+		}
+		page.Content[i] = content
+	}
+	page.Annotations = slices.Clone(page.Annotations)
+	d.Pages = slices.Insert(d.Pages, index+1, &page)
+	d.renumberPages()
+	d.remapBookmarks(func(page int) int {
+		if page > index {
+			return page + 1
+		}
+		return page
+	})
+	return nil
+}
+
+// remapBookmarks moves the bookmarks to the pages their pages are mapped
+// to, dropping those mapped to -1
+func (d *Document) remapBookmarks(mapPage func(int) int) {
+	kept := d.Bookmarks[:0]
+	for _, b := range d.Bookmarks {
+		if b.Page = mapPage(b.Page); b.Page >= 0 {
+			kept = append(kept, b)
+		}
+	}
+	d.Bookmarks = kept
+}
 
-	// Update page numbers
+// renumberPages numbers the pages in their order
+func (d *Document) renumberPages() {
 	for i := range d.Pages {
 		d.Pages[i].Number = i + 1
 	}
-	return nil
+}
+
+// normalizeRotation returns a rotation in degrees as 0, 90, 180 or 270
+func normalizeRotation(degrees int) int {
+	return ((degrees % 360) + 360) % 360 / 90 * 90
 }
 
 // AddText adds text content to a page
@@ -167,10 +333,22 @@ func (p *Page) AddTextStyled(text string, x, y float64, style TextStyle) {
 		Bold:       style.Bold,
 		Italic:     style.Italic,
 		Color:      style.Color,
+		Align:      style.Align,
+		Direction:  style.Direction,
 	}
 	p.Content = append(p.Content, content)
 }
 
+// AddImage adds an image file to a page
+func (p *Page) AddImage(path string, x, y, width, height float64) {
+	p.Content = append(p.Content, ImageContent{Path: path, X: x, Y: y, Width: width, Height: height})
+}
+
+// AddImageFromBytes adds an image to a page from its PNG, JPEG or GIF data
+func (p *Page) AddImageFromBytes(data []byte, x, y, width, height float64) {
+	p.Content = append(p.Content, ImageContent{Data: data, X: x, Y: y, Width: width, Height: height})
+}
+
 // GetText extracts all text from the page
 func (p *Page) GetText() string {
 	var result string
@@ -201,3 +379,128 @@ func (d *Document) SetMetadata(title, author, subject string) {
 	d.Metadata.Subject = subject
 	d.Metadata.Creator = "DocxSmith"
 }
+
+// AddFont embeds a TrueType font file as a style ("", "B", "I" or "BI") of a
+// font family, which text can then use by its FontFamily. Text in a style
+// the family lacks falls back to another of its styles, and fonts that
+// can't be read are left out, their text falling back to Arial.
+func (d *Document) AddFont(family, style string, data []byte) {
+	d.Fonts = append(d.Fonts, Font{Family: family, Style: style, Data: data})
+}
+
+// SetPageNumbers stamps a footer with the page number on every page. The
+// format may use {n} and {total}, e.g. "Page {n} of {total}".
+func (d *Document) SetPageNumbers(format string) {
+	d.PageNumbers = format
+}
+
+// WatermarkOption is a function type for configuring watermarks
+type WatermarkOption func(*Watermark)
+
+// WithWatermarkFontSize sets the watermark font size in points
+func WithWatermarkFontSize(size float64) WatermarkOption {
+	return func(wm *Watermark) {
+		wm.FontSize = size
+	}
+}
+
+// WithWatermarkColor sets the watermark color (hex, e.g. "FF0000")
+func WithWatermarkColor(color string) WatermarkOption {
+	return func(wm *Watermark) {
+		wm.Color = color
+	}
+}
+
+// WithWatermarkAngle sets the watermark angle in degrees counter-clockwise; 0 is horizontal
+func WithWatermarkAngle(angle float64) WatermarkOption {
+	return func(wm *Watermark) {
+		wm.Angle = angle
+	}
+}
+
+// WithWatermarkOpacity sets the watermark opacity, from 0 to 1
+func WithWatermarkOpacity(opacity float64) WatermarkOption {
+	return func(wm *Watermark) {
+		wm.Opacity = opacity
+	}
+}
+
+// SetWatermark draws text across every page, diagonally in light gray
+// unless configured otherwise
+func (d *Document) SetWatermark(text string, opts ...WatermarkOption) {
+	wm := &Watermark{
+		Text:     text,
+		FontSize: 60,
+		Color:    "C0C0C0",
+		Angle:    45,
+		Opacity:  0.3,
+	}
+	for _, opt := range opts {
+		opt(wm)
+	}
+	d.Watermark = wm
+}
+
+// RemoveWatermark removes the watermark set with SetWatermark
+func (d *Document) RemoveWatermark() {
+	d.Watermark = nil
+}
+
+// RedactMode selects how redacted text is hidden
+type RedactMode int
+
+const (
+	// RedactBlackout replaces each redacted character with a black box (█)
+	RedactBlackout RedactMode = iota
+	// RedactRemove deletes redacted text
+	RedactRemove
+)
+
+// redactionChar is drawn in place of each redacted character
+const redactionChar = "█"
+
+// Redact hides every match of pattern in the text and tables of every page.
+// Blacked-out text is drawn as black boxes, and table cells holding it are
+// filled black. Since pages are rendered from their content, the redacted
+// text is gone from the saved file, not just covered. Returns the number of
+// matches.
+func (d *Document) Redact(pattern *regexp.Regexp, mode RedactMode) int {
+	count := 0
+	redact := func(text string) string {
+		return pattern.ReplaceAllStringFunc(text, func(match string) string {
+			count++
+			if mode == RedactBlackout {
+				return strings.Repeat(redactionChar, utf8.RuneCountInString(match))
+			}
+			return ""
+		})
+	}
+
+	for _, page := range d.Pages {
+		for i, content := range page.Content {
+			switch c := content.(type) {
+			case TextContent:
+				c.Text = redact(c.Text)
+				page.Content[i] = c
+			case TableContent:
+				for _, row := range c.Rows {
+					for j := range row {
+						row[j] = redact(row[j])
+					}
+				}
+			}
+		}
+	}
+	return count
+}
+
+// RemovePersonalInfo clears the title, author, subject and keywords
+func (d *Document) RemovePersonalInfo() {
+	if d.Metadata == nil {
+		return
+	}
+	d.Metadata.Title = ""
+	d.Metadata.Author = ""
+	d.Metadata.Subject = ""
+	d.Metadata.Keywords = ""
+}
@@ -1,9 +1,17 @@
 package pdf
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
@@ -197,6 +205,113 @@ func TestSaveAndOpen(t *testing.T) {
 	}
 }
 
+func TestBytesRoundTrip(t *testing.T) {
+	// Any temp file would fail to be created
+	t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "missing"))
+
+	doc := New()
+	doc.AddPage().AddText("Hello bytes", 20, 30, 12)
+	doc.AddPage().AddText("Second page", 20, 30, 12)
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Error encoding PDF: %v", err)
+	}
+
+	doc2, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("Error reading PDF bytes: %v", err)
+	}
+	if doc2.GetPageCount() != 2 {
+		t.Errorf("Expected 2 pages, got %d", doc2.GetPageCount())
+	}
+	if text := doc2.GetAllText(); !contains(text, "Hello bytes") {
+		t.Errorf("Expected text to contain 'Hello bytes', got: %s", text)
+	}
+
+	if _, err := ReadBytes([]byte("not a pdf")); err == nil {
+		t.Error("Expected error for invalid data")
+	}
+}
+
+func TestPageNumbersAndWatermark(t *testing.T) {
+	doc := New()
+	doc.AddPage().AddText("First", 20, 30, 12)
+	doc.AddPage().AddText("Second", 20, 30, 12)
+	doc.SetPageNumbers("Page {n} of {total}")
+	doc.SetWatermark("DRAFT")
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Error encoding PDF: %v", err)
+	}
+
+	doc2, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("Error reading PDF bytes: %v", err)
+	}
+	if doc2.GetPageCount() != 2 {
+		t.Fatalf("Expected 2 pages, got %d", doc2.GetPageCount())
+	}
+	for i, want := range []string{"Page 1 of 2", "Page 2 of 2"} {
+		page, _ := doc2.GetPage(i)
+		text := page.GetText()
+		if !contains(text, want) {
+			t.Errorf("Expected page %d to contain %q, got: %s", i+1, want, text)
+		}
+		if !contains(text, "DRAFT") {
+			t.Errorf("Expected page %d to contain the watermark, got: %s", i+1, text)
+		}
+	}
+}
+
+func TestWatermarkOptions(t *testing.T) {
+	doc := New()
+	doc.SetWatermark("CONFIDENTIAL", WithWatermarkColor("FF0000"), WithWatermarkAngle(0), WithWatermarkOpacity(0.5), WithWatermarkFontSize(40))
+
+	want := Watermark{Text: "CONFIDENTIAL", FontSize: 40, Color: "FF0000", Angle: 0, Opacity: 0.5}
+	if doc.Watermark == nil || *doc.Watermark != want {
+		t.Errorf("Expected %+v, got %+v", want, doc.Watermark)
+	}
+
+	doc.RemoveWatermark()
+	if doc.Watermark != nil {
+		t.Error("Expected watermark to be removed")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	doc := New()
+	doc.SetMetadata("Jane Doe's claim", "Jane Doe", "Claim")
+	page := doc.AddPage()
+	page.AddText("Claimant: Jane Doe", 20, 30, 12)
+	page.Content = append(page.Content, TableContent{X: 20, Y: 50, Rows: [][]string{{"Name", "Jane Doe"}}})
+
+	if n := doc.Redact(regexp.MustCompile(`Jane Doe`), RedactBlackout); n != 2 {
+		t.Errorf("Expected 2 matches, got %d", n)
+	}
+	if text := page.Content[0].(TextContent).Text; text != "Claimant: ████████" {
+		t.Errorf("Unexpected redacted text: %q", text)
+	}
+
+	doc.RemovePersonalInfo()
+	if doc.Metadata.Author != "" || doc.Metadata.Title != "" {
+		t.Errorf("Expected metadata to be cleared, got %+v", doc.Metadata)
+	}
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Error encoding PDF: %v", err)
+	}
+	doc2, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("Error reading PDF bytes: %v", err)
+	}
+	if text := doc2.GetAllText(); contains(text, "Jane") || !contains(text, "Claimant:") {
+		t.Errorf("Expected redacted text to be gone, got: %s", text)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) &&
 		(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
@@ -211,3 +326,422 @@ func containsMiddle(s, substr string) bool {
 	}
 	return false
 }
+
+func TestSplitText(t *testing.T) {
+	doc := New()
+	style := TextStyle{FontFamily: "Arial", FontSize: 12}
+
+	lines := doc.SplitText("the quick brown fox jumps over the lazy dog", style, 40)
+	if len(lines) < 2 {
+		t.Fatalf("Expected the text to wrap, got %q", lines)
+	}
+	if strings.Join(lines, " ") != "the quick brown fox jumps over the lazy dog" {
+		t.Errorf("Expected the lines to hold every word, got %q", lines)
+	}
+
+	// Line breaks are kept and overlong words are broken
+	lines = doc.SplitText("first\n"+strings.Repeat("x", 100), style, 40)
+	if lines[0] != "first" || len(lines) < 3 || strings.Join(lines[1:], "") != strings.Repeat("x", 100) {
+		t.Errorf("Expected a line break and a broken word, got %q", lines)
+	}
+
+	// Bold text is wider, and unknown fonts are measured as Arial
+	bold := doc.SplitText(strings.Repeat("wide words ", 20), TextStyle{FontFamily: "Nowhere Sans", FontSize: 12, Bold: true}, 100)
+	plain := doc.SplitText(strings.Repeat("wide words ", 20), style, 100)
+	if len(bold) < len(plain) {
+		t.Errorf("Expected bold text to take at least as many lines, got %d and %d", len(bold), len(plain))
+	}
+}
+
+func TestImagesAndWrappedTables(t *testing.T) {
+	var picture bytes.Buffer
+	if err := png.Encode(&picture, image.NewGray(image.Rect(0, 0, 10, 10))); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+
+	doc := New()
+	page := doc.AddPage()
+	page.AddImageFromBytes(picture.Bytes(), 20, 20, 30, 30)
+	page.AddImageFromBytes(picture.Bytes(), 60, 20, 30, 30)
+	page.AddImageFromBytes([]byte("not an image"), 100, 20, 30, 30)
+	page.AddImage(filepath.Join(t.TempDir(), "missing.png"), 140, 20, 30, 30)
+	table := TableContent{X: 20, Y: 60, Rows: [][]string{{"Name", "Notes"}, {"A", strings.Repeat("wrapped ", 30)}}, ColumnWidth: []float64{40, 60}}
+	page.Content = append(page.Content, table)
+
+	heights := doc.TableRowHeights(table)
+	if len(heights) != 2 || heights[0] != 8 || heights[1] <= 8 {
+		t.Errorf("Expected a one-line row and a taller wrapped row, got %v", heights)
+	}
+
+	// Unreadable images are left out rather than failing the document
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+	if n := bytes.Count(data, []byte("/Subtype /Image")); n != 1 {
+		t.Errorf("Expected the repeated picture to be stored once, got %d images", n)
+	}
+}
+
+func TestRotateMoveAndDuplicatePages(t *testing.T) {
+	doc := New()
+	for _, text := range []string{"First", "Second", "Third"} {
+		doc.AddPage().AddText(text, 20, 30, 12)
+	}
+
+	if err := doc.RotatePage(1, 90); err != nil {
+		t.Fatalf("RotatePage failed: %v", err)
+	}
+	if err := doc.RotatePage(1, 180); err != nil {
+		t.Fatalf("RotatePage failed: %v", err)
+	}
+	if doc.Pages[1].Rotation != 270 {
+		t.Errorf("Expected rotation 270, got %d", doc.Pages[1].Rotation)
+	}
+	if err := doc.RotatePage(0, 45); err == nil {
+		t.Error("Expected error for rotation that isn't a multiple of 90")
+	}
+
+	if err := doc.MovePage(2, 0); err != nil {
+		t.Fatalf("MovePage failed: %v", err)
+	}
+	if err := doc.DuplicatePage(0); err != nil {
+		t.Fatalf("DuplicatePage failed: %v", err)
+	}
+	if err := doc.MovePage(0, 4); err == nil {
+		t.Error("Expected error for out of range page")
+	}
+
+	var order []string
+	for i, page := range doc.Pages {
+		if page.Number != i+1 {
+			t.Errorf("page %d numbered %d", i, page.Number)
+		}
+		order = append(order, strings.TrimSpace(page.GetText()))
+	}
+	if got := strings.Join(order, ","); got != "Third,Third,First,Second" {
+		t.Errorf("page order = %s", got)
+	}
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Error encoding PDF: %v", err)
+	}
+	doc2, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("Error reading PDF bytes: %v", err)
+	}
+	if doc2.GetPageCount() != 4 {
+		t.Fatalf("Expected 4 pages, got %d", doc2.GetPageCount())
+	}
+	for i, want := range []int{0, 0, 0, 270} {
+		if got := doc2.Pages[i].Rotation; got != want {
+			t.Errorf("page %d: expected rotation %d, got %d", i, want, got)
+		}
+	}
+	if text := doc2.Pages[3].GetText(); !contains(text, "Second") {
+		t.Errorf("rotated page lost its text: %q", text)
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	doc := New()
+	page := doc.AddPage()
+	page.AddText("Review this clause", 20, 30, 12)
+	page.AddHighlight(Rect{X: 20, Y: 28, Width: 50, Height: 6}, "")
+	page.AddNote(150, 28, "Check with legal (§4)")
+	page.AddLink(Rect{X: 20, Y: 40, Width: 40, Height: 6}, "https://example.com/terms?a=(1)")
+	doc.AddPage()
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Error encoding PDF: %v", err)
+	}
+	if !bytes.Contains(data, []byte("/Subtype /Highlight")) {
+		t.Error("highlight annotation not written")
+	}
+
+	doc2, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("Error reading PDF bytes: %v", err)
+	}
+	if text := doc2.GetAllText(); !contains(text, "Review this clause") {
+		t.Errorf("page content lost: %q", text)
+	}
+	annots := doc2.Pages[0].Annotations
+	if len(annots) != 3 {
+		t.Fatalf("Expected 3 annotations, got %d", len(annots))
+	}
+	if len(doc2.Pages[1].Annotations) != 0 {
+		t.Errorf("Expected no annotations on page 2, got %d", len(doc2.Pages[1].Annotations))
+	}
+
+	h, ok := annots[0].(HighlightAnnotation)
+	if !ok || h.Color != "FFFF00" {
+		t.Errorf("Expected yellow highlight, got %#v", annots[0])
+	}
+	want := Rect{X: 20, Y: 28, Width: 50, Height: 6}
+	for _, d := range []float64{h.Rect.X - want.X, h.Rect.Y - want.Y, h.Rect.Width - want.Width, h.Rect.Height - want.Height} {
+		if d > 0.1 || d < -0.1 {
+			t.Errorf("highlight area = %+v, want %+v", h.Rect, want)
+			break
+		}
+	}
+	if n, ok := annots[1].(NoteAnnotation); !ok || n.Text != "Check with legal (§4)" {
+		t.Errorf("Expected note, got %#v", annots[1])
+	}
+	if l, ok := annots[2].(LinkAnnotation); !ok || l.URL != "https://example.com/terms?a=(1)" {
+		t.Errorf("Expected link, got %#v", annots[2])
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	doc := New()
+	for i := 0; i < 4; i++ {
+		doc.AddPage().AddText("Page text", 20, 30, 12)
+	}
+
+	for _, b := range []Bookmark{
+		{"Introduction", 0, 0},
+		{"Results", 2, 0},
+		{"Scope", 1, 1},
+		{"Tables", 3, 1},
+		{"Résumé", 3, 3},
+	} {
+		if err := doc.AddBookmark(b.Title, b.Page, b.Level); err != nil {
+			t.Fatalf("AddBookmark(%q) failed: %v", b.Title, err)
+		}
+	}
+	if err := doc.AddBookmark("Missing", 4, 0); err == nil {
+		t.Error("Expected error for out of range page")
+	}
+
+	want := []Bookmark{
+		{"Introduction", 0, 0},
+		{"Scope", 1, 1},
+		{"Results", 2, 0},
+		{"Tables", 3, 1},
+		{"Résumé", 3, 2},
+	}
+	outline := doc.GetOutline()
+	if len(outline) != len(want) {
+		t.Fatalf("GetOutline() = %v", outline)
+	}
+	for i := range want {
+		if outline[i] != want[i] {
+			t.Errorf("outline[%d] = %+v, want %+v", i, outline[i], want[i])
+		}
+	}
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Error encoding PDF: %v", err)
+	}
+	doc2, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("Error reading PDF bytes: %v", err)
+	}
+	read := doc2.GetOutline()
+	if len(read) != len(want) {
+		t.Fatalf("read outline = %v", read)
+	}
+	for i := range want {
+		if read[i] != want[i] {
+			t.Errorf("read outline[%d] = %+v, want %+v", i, read[i], want[i])
+		}
+	}
+
+	// Bookmarks follow their pages
+	if err := doc2.MovePage(3, 0); err != nil {
+		t.Fatalf("MovePage failed: %v", err)
+	}
+	if err := doc2.DeletePage(2); err != nil {
+		t.Fatalf("DeletePage failed: %v", err)
+	}
+	var titles []string
+	for _, b := range doc2.GetOutline() {
+		titles = append(titles, fmt.Sprintf("%s@%d", b.Title, b.Page))
+	}
+	if got := strings.Join(titles, ","); got != "Tables@0,Résumé@0,Introduction@1,Results@2" {
+		t.Errorf("outline after moving pages = %s", got)
+	}
+}
+
+func TestMetadataRoundTrip(t *testing.T) {
+	doc := New()
+	doc.AddPage().AddText("Page text", 20, 30, 12)
+	doc.SetMetadata("Rapport annuel", "Zoë Müller", "Finances")
+	doc.SetKeywords("report 2024 ACME")
+	created := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	doc.Metadata.Created = created
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Error encoding PDF: %v", err)
+	}
+	if !bytes.Contains(data, []byte("/Type /Metadata /Subtype /XML")) || !bytes.Contains(data, []byte("<pdf:Keywords>report 2024 ACME</pdf:Keywords>")) {
+		t.Error("Expected an XMP packet with the keywords")
+	}
+
+	doc2, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("Error reading PDF bytes: %v", err)
+	}
+	m := doc2.Metadata
+	if m.Title != "Rapport annuel" || m.Author != "Zoë Müller" || m.Subject != "Finances" || m.Keywords != "report 2024 ACME" {
+		t.Errorf("read metadata = %+v", m)
+	}
+	if !m.Created.Equal(created) {
+		t.Errorf("Created = %v, want %v", m.Created, created)
+	}
+}
+
+func TestParsePDFDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"D:20240131120000Z", time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)},
+		{"D:20240131120000+01'00'", time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC)},
+		{"D:2024", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"yesterday", time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := parsePDFDate(tt.in); !got.Equal(tt.want) {
+			t.Errorf("parsePDFDate(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestVisualOrder(t *testing.T) {
+	tests := []struct {
+		text, dir, want string
+	}{
+		{"plain text", "", "plain text"},
+		{"שלום", "", "םולש"},
+		{"שלום 123", "", "123 םולש"},
+		{"abc שלום def", "", "abc םולש def"},
+		{"שלום world", "", "world םולש"},
+		{"(שלום)", "", "(םולש)"},
+		{"מחיר 1,000.50 ₪", "", "₪ 1,000.50 ריחמ"},
+		{"Hello", "rtl", "Hello"},
+		{"Hi!", "rtl", "!Hi"},
+	}
+	for _, tt := range tests {
+		if got := visualOrder(tt.text, tt.dir); got != tt.want {
+			t.Errorf("visualOrder(%q, %q) = %q, want %q", tt.text, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestShapeArabic(t *testing.T) {
+	tests := map[string]string{
+		"مرحبا": "ﻣﺮﺣﺒﺎ",
+		"لا":    "ﻻ",
+		"سلام":  "ﺳﻼﻡ",
+		"abc":   "abc",
+	}
+	for text, want := range tests {
+		if got := shapeArabic(text); got != want {
+			t.Errorf("shapeArabic(%q) = %+q, want %+q", text, got, want)
+		}
+	}
+}
+
+func TestUnicodeText(t *testing.T) {
+	// The core fonts take Windows-1252, which covers Western European text
+	doc := New()
+	doc.AddPage().AddText("Café – 10 € naïve", 20, 30, 12)
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Error encoding PDF: %v", err)
+	}
+	doc2, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("Error reading PDF bytes: %v", err)
+	}
+	if text := doc2.GetAllText(); !strings.Contains(text, "Café – 10 € naïve") {
+		t.Errorf("GetAllText() = %q", text)
+	}
+
+	// Other scripts are set in a fallback font
+	font, err := os.ReadFile("/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf")
+	if err != nil {
+		t.Skip("DejaVu Sans is not installed")
+	}
+	glyphs, ok := readGlyphs(font)
+	if !ok || !glyphs.has('ש') || !glyphs.has('é') || glyphs.has('漢') {
+		t.Errorf("readGlyphs() = %v, %v", len(glyphs), ok)
+	}
+
+	doc = New()
+	doc.AddFallbackFont("DejaVu", font)
+	page := doc.AddPage()
+	page.AddText("Shalom שלום", 20, 30, 12)
+	page.AddTextStyled("مرحبا", 20, 40, TextStyle{FontFamily: "Arial", FontSize: 12, Align: "right"})
+	data, err = doc.Bytes()
+	if err != nil {
+		t.Fatalf("Error encoding PDF: %v", err)
+	}
+	if !bytes.Contains(data, []byte("/BaseFont /utf8dejavu")) {
+		t.Error("Expected the fallback font to be embedded")
+	}
+	doc2, err = ReadBytes(data)
+	if err != nil {
+		t.Fatalf("Error reading PDF bytes: %v", err)
+	}
+	text := doc2.GetAllText()
+	if !strings.Contains(text, "Shalom םולש") || !strings.Contains(text, "ﺎﺒﺣﺮﻣ") {
+		t.Errorf("GetAllText() = %q", text)
+	}
+}
+
+func TestMalformedPDF(t *testing.T) {
+	doc := New()
+	doc.AddPage().AddText("Hello", 20, 30, 12)
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Error encoding PDF: %v", err)
+	}
+
+	if _, err := ReadBytes([]byte("not a pdf")); !errors.Is(err, ErrInvalidPDF) {
+		t.Errorf("Expected ErrInvalidPDF, got %v", err)
+	}
+	// Truncated and corrupted copies fail with an error or read, but don't panic
+	for _, size := range []int{10, len(data) / 3, len(data) / 2, len(data) - 40} {
+		if _, err := ReadBytes(data[:size]); err != nil && !errors.Is(err, ErrInvalidPDF) {
+			t.Errorf("Expected ErrInvalidPDF for %d bytes, got %v", size, err)
+		}
+	}
+	corrupted := bytes.ReplaceAll(data, []byte(" obj"), []byte(" jbo"))
+	if _, err := ReadBytes(corrupted); err != nil && !errors.Is(err, ErrInvalidPDF) {
+		t.Errorf("Expected ErrInvalidPDF for corrupted objects, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "broken.pdf")
+	os.WriteFile(path, data[:len(data)/2], 0o644)
+	if _, err := Open(path); err != nil && !errors.Is(err, ErrInvalidPDF) {
+		t.Errorf("Expected Open to return ErrInvalidPDF, got %v", err)
+	}
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.pdf")); err == nil || errors.Is(err, ErrInvalidPDF) {
+		t.Errorf("Expected a missing file not to be reported as invalid, got %v", err)
+	}
+}
+
+func FuzzReadBytes(f *testing.F) {
+	doc := New()
+	doc.AddPage().AddText("Hello", 20, 30, 12)
+	data, err := doc.Bytes()
+	if err != nil {
+		f.Fatalf("Error encoding PDF: %v", err)
+	}
+	f.Add(data)
+	f.Add(data[:len(data)/2])
+	f.Add([]byte("%PDF-1.4\ntrailer<</Root 1 0 R>>\n%%EOF"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if _, err := ReadBytes(data); err != nil && !errors.Is(err, ErrInvalidPDF) {
+			t.Errorf("Expected ErrInvalidPDF, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package pdf
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// SetKeywords sets the keywords of the document, e.g. "invoice 2024 ACME"
+func (d *Document) SetKeywords(keywords string) {
+	if d.Metadata == nil {
+		d.Metadata = &Metadata{}
+	}
+	d.Metadata.Keywords = keywords
+}
+
+// XMP returns the metadata as an XMP packet, the form PDF/A and document
+// management systems read it in. Dates left unset are given as now.
+func (m *Metadata) XMP() []byte {
+	created, modified := m.Created, m.Modified
+	if created.IsZero() {
+		created = time.Now()
+	}
+	if modified.IsZero() {
+		modified = created
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<?xpacket begin=\"\uFEFF\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	sb.WriteString(`<x:xmpmeta xmlns:x="adobe:ns:meta/">` + "\n")
+	sb.WriteString(`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` + "\n")
+	sb.WriteString(`<rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:pdf="http://ns.adobe.com/pdf/1.3/" xmlns:xmp="http://ns.adobe.com/xap/1.0/">` + "\n")
+	sb.WriteString("<dc:format>application/pdf</dc:format>\n")
+	if m.Title != "" {
+		fmt.Fprintf(&sb, "<dc:title><rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt></dc:title>\n", html.EscapeString(m.Title))
+	}
+	if m.Author != "" {
+		fmt.Fprintf(&sb, "<dc:creator><rdf:Seq><rdf:li>%s</rdf:li></rdf:Seq></dc:creator>\n", html.EscapeString(m.Author))
+	}
+	if m.Subject != "" {
+		fmt.Fprintf(&sb, "<dc:description><rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt></dc:description>\n", html.EscapeString(m.Subject))
+	}
+	if m.Keywords != "" {
+		fmt.Fprintf(&sb, "<pdf:Keywords>%s</pdf:Keywords>\n", html.EscapeString(m.Keywords))
+	}
+	if m.Creator != "" {
+		fmt.Fprintf(&sb, "<xmp:CreatorTool>%s</xmp:CreatorTool>\n", html.EscapeString(m.Creator))
+	}
+	fmt.Fprintf(&sb, "<xmp:CreateDate>%s</xmp:CreateDate>\n", created.Format(time.RFC3339))
+	fmt.Fprintf(&sb, "<xmp:ModifyDate>%s</xmp:ModifyDate>\n", modified.Format(time.RFC3339))
+	sb.WriteString("</rdf:Description>\n</rdf:RDF>\n</x:xmpmeta>\n")
+	sb.WriteString(`<?xpacket end="w"?>`)
+	return []byte(sb.String())
+}
+
+// writeXMP adds the metadata to the update as the XMP packet of the
+// document
+func (d *Document) writeXMP(u *pdfUpdate) {
+	if d.Metadata == nil {
+		return
+	}
+	xmp := d.Metadata.XMP()
+	ref := u.addObject(fmt.Sprintf("<</Type /Metadata /Subtype /XML /Length %d>>\nstream\n%s\nendstream", len(xmp), xmp))
+	u.addCatalogEntry("/Metadata " + ref)
+}
+
+// readMetadata returns the document information of a PDF, with DocxSmith
+// as the creator if it names none
+func readMetadata(r *pdf.Reader) *Metadata {
+	info := r.Trailer().Key("Info")
+	m := &Metadata{
+		Title:    info.Key("Title").Text(),
+		Author:   info.Key("Author").Text(),
+		Subject:  info.Key("Subject").Text(),
+		Keywords: info.Key("Keywords").Text(),
+		Creator:  info.Key("Creator").Text(),
+		Created:  parsePDFDate(info.Key("CreationDate").Text()),
+		Modified: parsePDFDate(info.Key("ModDate").Text()),
+	}
+	if m.Creator == "" {
+		m.Creator = "DocxSmith"
+	}
+	return m
+}
+
+// pdfDatePattern matches a PDF date such as D:20240131120000+01'00'
+var pdfDatePattern = regexp.MustCompile(`^D:(\d{4})(\d{2})?(\d{2})?(\d{2})?(\d{2})?(\d{2})?([Zz+-])?(\d{2})?'?(\d{2})?`)
+
+// parsePDFDate parses a PDF date, returning the zero time if it isn't one
+func parsePDFDate(s string) time.Time {
+	m := pdfDatePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return time.Time{}
+	}
+	field := func(i int, def string) string {
+		if m[i] == "" {
+			return def
+		}
+		return m[i]
+	}
+	zone := "Z"
+	if m[7] == "+" || m[7] == "-" {
+		zone = m[7] + field(8, "00") + ":" + field(9, "00")
+	}
+	t, err := time.Parse(time.RFC3339, fmt.Sprintf("%s-%s-%sT%s:%s:%s%s",
+		m[1], field(2, "01"), field(3, "01"), field(4, "00"), field(5, "00"), field(6, "00"), zone))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
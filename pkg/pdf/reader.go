@@ -1,28 +1,55 @@
 package pdf
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"math"
 
 	"github.com/ledongthuc/pdf"
 )
 
+// ErrInvalidPDF is returned for files that aren't a readable PDF, such as
+// truncated files or ones with a broken cross-reference table
+var ErrInvalidPDF = errors.New("invalid PDF")
+
 // Open opens and reads a PDF file
-func Open(filePath string) (*Document, error) {
-	doc := &Document{
-		FilePath: filePath,
-		Pages:    []*Page{},
-		Metadata: &Metadata{
-			Creator: "DocxSmith",
-		},
-	}
+func Open(filePath string) (doc *Document, err error) {
+	defer recoverInvalid(&doc, &err)
 
 	// Open PDF file
 	f, r, err := pdf.Open(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open PDF: %w", err)
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			return nil, fmt.Errorf("failed to open PDF: %w", err)
+		}
+		return nil, fmt.Errorf("failed to open PDF: %w: %w", ErrInvalidPDF, err)
 	}
 	defer f.Close()
 
+	doc = read(r)
+	doc.FilePath = filePath
+	return doc, nil
+}
+
+// recoverInvalid turns a panic of the PDF parser, which panics on some
+// malformed files, into ErrInvalidPDF. It must be deferred directly.
+func recoverInvalid(doc **Document, err *error) {
+	if p := recover(); p != nil {
+		*doc, *err = nil, fmt.Errorf("%w: %v", ErrInvalidPDF, p)
+	}
+}
+
+// read extracts the pages of a parsed PDF
+func read(r *pdf.Reader) *Document {
+	doc := &Document{
+		Pages:    []*Page{},
+		Metadata: readMetadata(r),
+	}
+
 	// Get number of pages
 	numPages := r.NumPage()
 
@@ -47,6 +74,11 @@ func Open(filePath string) (*Document, error) {
 			continue
 		}
 
+		if rotate := p.V.Key("Rotate"); !rotate.IsNull() {
+			page.Rotation = normalizeRotation(int(rotate.Int64()))
+		}
+		page.Annotations = readAnnotations(p.V.Key("Annots"), page.Height)
+
 		// Extract text from page
 		text, err := p.GetPlainText(nil)
 		if err == nil && text != "" {
@@ -66,13 +98,68 @@ func Open(filePath string) (*Document, error) {
 
 		doc.Pages = append(doc.Pages, page)
 	}
+	doc.Bookmarks = readOutline(r)
 
-	return doc, nil
+	return doc
 }
 
-// ReadBytes reads a PDF from bytes
-func ReadBytes(data []byte) (*Document, error) {
-	// For now, this is a placeholder
-	// In production, you would use a library that supports reading from bytes
-	return nil, fmt.Errorf("reading PDF from bytes not yet implemented")
+// ReadBytes reads a PDF from bytes without touching the filesystem
+func ReadBytes(data []byte) (doc *Document, err error) {
+	defer recoverInvalid(&doc, &err)
+
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w: %w", ErrInvalidPDF, err)
+	}
+	return read(r), nil
+}
+
+// ReadFrom reads a PDF from an io.Reader
+func ReadFrom(r io.Reader) (*Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data: %w", err)
+	}
+	return ReadBytes(data)
+}
+
+// readAnnotations returns the highlights, notes and links of a page's
+// /Annots array; other kinds of annotation are left out
+func readAnnotations(annots pdf.Value, pageHeight float64) []Annotation {
+	var result []Annotation
+	for i := 0; i < annots.Len(); i++ {
+		a := annots.Index(i)
+		r := a.Key("Rect")
+		if r.Len() != 4 {
+			continue
+		}
+		x1, y1, x2, y2 := r.Index(0).Float64(), r.Index(1).Float64(), r.Index(2).Float64(), r.Index(3).Float64()
+		rect := Rect{
+			X:      min(x1, x2) / mmToPt,
+			Y:      pageHeight - max(y1, y2)/mmToPt,
+			Width:  math.Abs(x2-x1) / mmToPt,
+			Height: math.Abs(y2-y1) / mmToPt,
+		}
+
+		switch a.Key("Subtype").Name() {
+		case "Highlight":
+			result = append(result, HighlightAnnotation{Rect: rect, Color: hexColor(a.Key("C"))})
+		case "Text":
+			result = append(result, NoteAnnotation{X: rect.X, Y: rect.Y, Text: a.Key("Contents").Text(), Color: hexColor(a.Key("C"))})
+		case "Link":
+			if uri := a.Key("A").Key("URI"); !uri.IsNull() {
+				result = append(result, LinkAnnotation{Rect: rect, URL: uri.RawString()})
+			}
+		}
+	}
+	return result
+}
+
+// hexColor returns a PDF RGB array as a hex color, or "" if it isn't one
+func hexColor(c pdf.Value) string {
+	if c.Len() != 3 {
+		return ""
+	}
+	return fmt.Sprintf("%02X%02X%02X",
+		int(math.Round(c.Index(0).Float64()*255)), int(math.Round(c.Index(1).Float64()*255)), int(math.Round(c.Index(2).Float64()*255)))
 }
@@ -0,0 +1,432 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// AddFallbackFont embeds a TrueType font for the characters the fonts of
+// text lack, such as Arabic, Hebrew or CJK characters in Arial. Fallback
+// fonts are tried in the order they were added. When none of them has a
+// character, fonts installed on the system are looked for, and characters
+// no font has are drawn as "?". Like other embedded fonts, only the glyphs
+// used are stored in the PDF.
+func (d *Document) AddFallbackFont(family string, data []byte) {
+	d.AddFont(family, "", data)
+	d.FallbackFonts = append(d.FallbackFonts, family)
+}
+
+// fontSet records the fonts text can be set in: the styles loaded for each
+// embedded family, keyed by the lower-cased family name, the characters of
+// each, and the families to set characters in that a text's font lacks
+type fontSet struct {
+	styles   map[string]map[string]bool
+	glyphs   map[string]glyphSet
+	fallback []string
+	system   bool // Whether fonts of the system were looked for
+}
+
+// fontRun is a stretch of text set in one font
+type fontRun struct {
+	family, style string
+	text          string
+}
+
+// hasGlyph reports whether family, as resolve returns it, can draw r
+func (f *fontSet) hasGlyph(family string, r rune) bool {
+	key := strings.ToLower(family)
+	if key == "symbol" || key == "zapfdingbats" {
+		return true
+	}
+	if coreFonts[key] {
+		_, ok := toCP1252(r)
+		return ok
+	}
+	glyphs, ok := f.glyphs[key]
+	return !ok || glyphs.has(r)
+}
+
+// runs splits text set in a family and style, as resolve returns them,
+// into the stretches each font draws: characters the family lacks are set
+// in the first fallback family that has them. Spaces and marks stay in
+// the font of the text before them.
+func (f *fontSet) runs(text, family, style string) []fontRun {
+	var runs []fontRun
+	var sb strings.Builder
+	current := family
+	for _, r := range text {
+		font := family
+		if sb.Len() > 0 && (unicode.IsSpace(r) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf)) {
+			font = current
+		} else if !f.hasGlyph(family, r) {
+			for _, fallback := range f.fallback {
+				if f.hasGlyph(fallback, r) {
+					font = fallback
+					break
+				}
+			}
+		}
+		if font != current && sb.Len() > 0 {
+			runs = append(runs, f.run(current, family, style, sb.String()))
+			sb.Reset()
+		}
+		current = font
+		sb.WriteRune(r)
+	}
+	if sb.Len() > 0 || len(runs) == 0 {
+		runs = append(runs, f.run(current, family, style, sb.String()))
+	}
+	return runs
+}
+
+// run returns text set in font, in the style closest to that of family
+func (f *fontSet) run(font, family, style, text string) fontRun {
+	if font == family {
+		return fontRun{family: font, style: style, text: text}
+	}
+	font, style = f.resolve(font, style)
+	return fontRun{family: font, style: style, text: text}
+}
+
+// encode returns the text of a run as gofpdf takes it: in Windows-1252 for
+// the core fonts, with "?" for characters it lacks, and UTF-8 otherwise
+func (r fontRun) encode() string {
+	if !coreFonts[strings.ToLower(r.family)] {
+		return r.text
+	}
+	b := make([]byte, 0, len(r.text))
+	for _, c := range r.text {
+		if code, ok := toCP1252(c); ok {
+			b = append(b, code)
+		} else {
+			b = append(b, '?')
+		}
+	}
+	return string(b)
+}
+
+// cell draws text from the current position like pdf.Cell, in family and
+// style at the current font size, and leaves the font set to them
+func (f *fontSet) cell(pdf *gofpdf.Fpdf, family, style, text string, height float64) {
+	size, _ := pdf.GetFontSize()
+	for _, run := range f.runs(text, family, style) {
+		pdf.SetFont(run.family, run.style, size)
+		s := run.encode()
+		pdf.Cell(pdf.GetStringWidth(s), height, s)
+	}
+	pdf.SetFont(family, style, size)
+}
+
+// text draws text with its baseline starting at x, y like pdf.Text, in
+// family and style at the current font size
+func (f *fontSet) text(pdf *gofpdf.Fpdf, family, style string, x, y float64, text string) {
+	size, _ := pdf.GetFontSize()
+	for _, run := range f.runs(text, family, style) {
+		pdf.SetFont(run.family, run.style, size)
+		s := run.encode()
+		pdf.Text(x, y, s)
+		x += pdf.GetStringWidth(s)
+	}
+	pdf.SetFont(family, style, size)
+}
+
+// width returns the width of text in family and style at the current font
+// size, in mm
+func (f *fontSet) width(pdf *gofpdf.Fpdf, family, style, text string) float64 {
+	size, _ := pdf.GetFontSize()
+	width := 0.0
+	for _, run := range f.runs(text, family, style) {
+		pdf.SetFont(run.family, run.style, size)
+		width += pdf.GetStringWidth(run.encode())
+	}
+	pdf.SetFont(family, style, size)
+	return width
+}
+
+// useSystemFonts adds fonts installed on the system as fallbacks when
+// text has characters no loaded font has. They are looked for once.
+func (f *fontSet) useSystemFonts(pdf *gofpdf.Fpdf, text string) {
+	if f.system {
+		return
+	}
+	missing := false
+	for _, r := range text {
+		if _, ok := toCP1252(r); !ok && !unicode.IsSpace(r) && !f.loaded(r) {
+			missing = true
+			break
+		}
+	}
+	if !missing {
+		return
+	}
+	f.system = true
+	for _, font := range systemFonts() {
+		if f.styles[font.family] == nil && loadFont(pdf, font.family, "", font.data) {
+			f.styles[font.family] = map[string]bool{"": true}
+			f.glyphs[font.family] = font.glyphs
+			f.fallback = append(f.fallback, font.family)
+		}
+	}
+}
+
+// loaded reports whether an embedded font has r
+func (f *fontSet) loaded(r rune) bool {
+	for _, glyphs := range f.glyphs {
+		if glyphs.has(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// cp1252 maps the characters of Windows-1252 outside Latin-1 to their codes
+var cp1252 = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// toCP1252 returns the Windows-1252 code of r, the encoding of the core
+// fonts, reporting whether it has one
+func toCP1252(r rune) (byte, bool) {
+	if r < 0x80 || r >= 0xA0 && r <= 0xFF {
+		return byte(r), true
+	}
+	code, ok := cp1252[r]
+	return code, ok
+}
+
+// glyphSet is the characters a font has, as sorted ranges
+type glyphSet []runeRange
+
+type runeRange struct{ lo, hi rune }
+
+// has reports whether the font has r
+func (g glyphSet) has(r rune) bool {
+	i := sort.Search(len(g), func(i int) bool { return g[i].hi >= r })
+	return i < len(g) && g[i].lo <= r
+}
+
+// readGlyphs returns the characters of a TrueType font from its cmap
+// table, reporting false if it has none it can read
+func readGlyphs(data []byte) (glyphSet, bool) {
+	u16 := func(off int) int {
+		if off < 0 || off+2 > len(data) {
+			return 0
+		}
+		return int(binary.BigEndian.Uint16(data[off:]))
+	}
+	u32 := func(off int) int {
+		if off < 0 || off+4 > len(data) {
+			return 0
+		}
+		return int(binary.BigEndian.Uint32(data[off:]))
+	}
+
+	cmap := -1
+	for i, n := 0, u16(4); i < n; i++ {
+		rec := 12 + 16*i
+		if rec+16 > len(data) {
+			break
+		}
+		if string(data[rec:rec+4]) == "cmap" {
+			cmap = u32(rec + 8)
+		}
+	}
+	if cmap < 0 {
+		return nil, false
+	}
+
+	// Prefer the full Unicode subtable to the one of the first 65536 characters
+	sub := -1
+	for i, n := 0, u16(cmap+2); i < n; i++ {
+		rec := cmap + 4 + 8*i
+		platform, encoding, offset := u16(rec), u16(rec+2), cmap+u32(rec+4)
+		switch format := u16(offset); {
+		case format == 12 && (platform == 3 && encoding == 10 || platform == 0):
+			sub = offset
+		case format == 4 && (platform == 3 && encoding == 1 || platform == 0) && sub < 0:
+			sub = offset
+		}
+	}
+	if sub < 0 {
+		return nil, false
+	}
+
+	var ranges glyphSet
+	add := func(lo, hi rune) {
+		if n := len(ranges); n > 0 && ranges[n-1].hi+1 >= lo {
+			ranges[n-1].hi = max(ranges[n-1].hi, hi)
+			return
+		}
+		ranges = append(ranges, runeRange{lo, hi})
+	}
+	switch u16(sub) {
+	case 4:
+		segments := u16(sub+6) / 2
+		ends, starts := sub+14, sub+16+2*segments
+		offsets := starts + 4*segments
+		for i := 0; i < segments; i++ {
+			start, end := u16(starts+2*i), u16(ends+2*i)
+			if start == 0xFFFF || end < start {
+				continue
+			}
+			offset := u16(offsets + 2*i)
+			if offset == 0 {
+				add(rune(start), rune(end))
+				continue
+			}
+			for c := start; c <= end; c++ {
+				if glyph := u16(offsets + 2*i + offset + 2*(c-start)); glyph != 0 {
+					add(rune(c), rune(c))
+				}
+			}
+		}
+	case 12:
+		for i, n := 0, u32(sub+12); i < n && sub+16+12*i+12 <= len(data); i++ {
+			group := sub + 16 + 12*i
+			add(rune(u32(group)), rune(u32(group+4)))
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].lo < ranges[j].lo })
+	return ranges, len(ranges) > 0
+}
+
+// systemFont is a font installed on the system that covers many scripts
+type systemFont struct {
+	family string
+	data   []byte
+	glyphs glyphSet
+}
+
+// systemFontNames are the fonts looked for as fallbacks, in order. Fonts
+// in OpenType CFF and collection files can't be embedded by gofpdf.
+var systemFontNames = []string{
+	"notosans-regular.ttf", "notosansarabic-regular.ttf", "notosanshebrew-regular.ttf",
+	"notonaskharabic-regular.ttf", "dejavusans.ttf", "freesans.ttf", "arial unicode.ttf",
+	"arialuni.ttf", "arial.ttf", "droidsansfallbackfull.ttf", "droidsansfallback.ttf",
+	"notosanssc-regular.ttf", "notosansjp-regular.ttf", "notosanskr-regular.ttf", "simhei.ttf",
+}
+
+// systemFontDirs returns the directories fonts are installed in
+func systemFontDirs() []string {
+	dirs := []string{"/usr/share/fonts", "/usr/local/share/fonts", "/Library/Fonts", "/System/Library/Fonts"}
+	if windir := os.Getenv("WINDIR"); windir != "" {
+		dirs = append(dirs, filepath.Join(windir, "Fonts"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".fonts"), filepath.Join(home, ".local", "share", "fonts"),
+			filepath.Join(home, "Library", "Fonts"))
+	}
+	return dirs
+}
+
+// systemFonts returns the fallback fonts installed on the system, read once
+var systemFonts = sync.OnceValue(func() []systemFont {
+	found := make(map[string]string)
+	for _, dir := range systemFontDirs() {
+		filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			name := strings.ToLower(entry.Name())
+			if !entry.IsDir() && found[name] == "" {
+				found[name] = path
+			}
+			return nil
+		})
+	}
+
+	var fonts []systemFont
+	for _, name := range systemFontNames {
+		path, ok := found[name]
+		if !ok {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if glyphs, ok := readGlyphs(data); ok {
+			// Named apart from the core fonts, such as Arial on Windows
+			family := "system" + strings.ReplaceAll(strings.TrimSuffix(name, ".ttf"), " ", "")
+			fonts = append(fonts, systemFont{family: family, data: data, glyphs: glyphs})
+		}
+	}
+	return fonts
+})
+
+// allText returns the text drawn on the pages of the document, with
+// Arabic letters in the forms they are drawn in
+func (d *Document) allText() string {
+	var sb strings.Builder
+	for _, page := range d.Pages {
+		for _, content := range page.Content {
+			switch c := content.(type) {
+			case TextContent:
+				sb.WriteString(c.Text)
+			case TableContent:
+				for _, row := range c.Rows {
+					for _, cell := range row {
+						sb.WriteString(cell)
+					}
+				}
+			}
+		}
+	}
+	if d.Watermark != nil {
+		sb.WriteString(d.Watermark.Text)
+	}
+	sb.WriteString(d.PageNumbers)
+	return shapeArabic(sb.String())
+}
+
+// toUnicodePattern finds the character maps of the Unicode fonts gofpdf writes
+var toUnicodePattern = regexp.MustCompile(`/ToUnicode (\d+) 0 R`)
+
+// writeToUnicode replaces the character maps of the Unicode fonts, which
+// map all codes to themselves in one range, with maps of a range for each
+// block of 256 codes, as the PDF specification requires and readers
+// extracting text expect
+func writeToUnicode(u *pdfUpdate) {
+	matches := toUnicodePattern.FindAllSubmatch(u.data, -1)
+	if len(matches) == 0 {
+		return
+	}
+
+	var ranges []string
+	for hi := 0; hi < 0x100; hi++ {
+		if hi < 0xD8 || hi > 0xDF { // Surrogates are no characters of their own
+			ranges = append(ranges, fmt.Sprintf("<%02X00> <%02XFF> <%02X00>", hi, hi, hi))
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	sb.WriteString("/CIDSystemInfo <</Registry (Adobe) /Ordering (UCS) /Supplement 0>> def\n")
+	sb.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	sb.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for len(ranges) > 0 { // A section may hold up to 100 ranges
+		n := min(len(ranges), 100)
+		fmt.Fprintf(&sb, "%d beginbfrange\n%s\nendbfrange\n", n, strings.Join(ranges[:n], "\n"))
+		ranges = ranges[n:]
+	}
+	sb.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend")
+	cmap := fmt.Sprintf("<</Length %d>>\nstream\n%s\nendstream", sb.Len(), sb.String())
+
+	for _, m := range matches {
+		num, _ := strconv.Atoi(string(m[1]))
+		u.replaceObject(num, cmap)
+	}
+}
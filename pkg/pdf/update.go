@@ -0,0 +1,182 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Patterns finding the end of the file written by gofpdf
+var (
+	startxrefPattern = regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF\s*$`)
+	sizePattern      = regexp.MustCompile(`/Size (\d+)`)
+	rootPattern      = regexp.MustCompile(`/Root (\d+) 0 R`)
+)
+
+// pdfUpdate collects the changes gofpdf can't make itself to a PDF it has
+// written: entries added to the dictionaries of its pages and catalog,
+// objects replaced, and new objects. They are appended to the file as an
+// incremental update.
+type pdfUpdate struct {
+	data     []byte
+	trailer  []byte // Entries of the trailer dictionary
+	prev     int    // Offset of the cross-reference section
+	size     int    // Number of objects, and the number of the first new one
+	root     int    // Number of the catalog
+	entries  map[int][]string
+	replaced map[int]string
+	objects  []string
+}
+
+// newUpdate prepares an update of a PDF written by gofpdf
+func newUpdate(data []byte) (*pdfUpdate, error) {
+	m := startxrefPattern.FindSubmatch(data)
+	if m == nil {
+		return nil, fmt.Errorf("failed to update PDF: no startxref")
+	}
+	prev, _ := strconv.Atoi(string(m[1]))
+
+	start := bytes.LastIndex(data, []byte("trailer"))
+	if start < 0 {
+		return nil, fmt.Errorf("failed to update PDF: no trailer")
+	}
+	trailer := data[start:]
+	open, end := bytes.Index(trailer, []byte("<<")), bytes.LastIndex(trailer, []byte(">>"))
+	if open < 0 || end < open {
+		return nil, fmt.Errorf("failed to update PDF: malformed trailer")
+	}
+	trailer = bytes.TrimSpace(trailer[open+2 : end])
+
+	s := sizePattern.FindSubmatch(trailer)
+	if s == nil {
+		return nil, fmt.Errorf("failed to update PDF: trailer has no /Size")
+	}
+	size, _ := strconv.Atoi(string(s[1]))
+	r := rootPattern.FindSubmatch(trailer)
+	if r == nil {
+		return nil, fmt.Errorf("failed to update PDF: trailer has no /Root")
+	}
+	root, _ := strconv.Atoi(string(r[1]))
+
+	return &pdfUpdate{data: data, trailer: trailer, prev: prev, size: size, root: root,
+		entries: make(map[int][]string), replaced: make(map[int]string)}, nil
+}
+
+// pageRef returns a reference to page n, numbered from 1. gofpdf numbers
+// the object of page n 1+2n.
+func pageRef(n int) string {
+	return fmt.Sprintf("%d 0 R", 1+2*n)
+}
+
+// addPageEntry adds an entry such as "/Rotate 90" to the dictionary of
+// page n, numbered from 1
+func (u *pdfUpdate) addPageEntry(n int, entry string) {
+	u.entries[1+2*n] = append(u.entries[1+2*n], entry)
+}
+
+// addCatalogEntry adds an entry such as "/PageMode /UseOutlines" to the
+// document catalog
+func (u *pdfUpdate) addCatalogEntry(entry string) {
+	u.entries[u.root] = append(u.entries[u.root], entry)
+}
+
+// addObject adds an object and returns a reference to it
+func (u *pdfUpdate) addObject(obj string) string {
+	num := u.newObject()
+	u.setObject(num, obj)
+	return fmt.Sprintf("%d 0 R", num)
+}
+
+// newObject reserves the number of an object to be set with setObject,
+// for objects that refer to each other
+func (u *pdfUpdate) newObject() int {
+	u.objects = append(u.objects, "null")
+	return u.size + len(u.objects) - 1
+}
+
+// replaceObject replaces object num of the PDF gofpdf wrote
+func (u *pdfUpdate) replaceObject(num int, obj string) {
+	u.replaced[num] = obj
+}
+
+// setObject sets an object reserved with newObject
+func (u *pdfUpdate) setObject(num int, obj string) {
+	u.objects[num-u.size] = obj
+}
+
+// bytes returns the PDF with the update appended, or as it was if there
+// is nothing to change
+func (u *pdfUpdate) bytes() ([]byte, error) {
+	if len(u.entries) == 0 && len(u.replaced) == 0 && len(u.objects) == 0 {
+		return u.data, nil
+	}
+
+	out := bytes.NewBuffer(append([]byte(nil), u.data...))
+	if !bytes.HasSuffix(u.data, []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	offsets := make(map[int]int)
+
+	changed := make([]int, 0, len(u.entries))
+	for num := range u.entries {
+		changed = append(changed, num)
+	}
+	sort.Ints(changed)
+	for _, num := range changed {
+		dict, err := objectDict(u.data, num)
+		if err != nil {
+			return nil, err
+		}
+		offsets[num] = out.Len()
+		fmt.Fprintf(out, "%d 0 obj\n%s", num, bytes.TrimSuffix(dict, []byte(">>")))
+		for _, entry := range u.entries[num] {
+			fmt.Fprintf(out, "\n%s", entry)
+		}
+		out.WriteString(">>\nendobj\n")
+	}
+	replaced := make([]int, 0, len(u.replaced))
+	for num := range u.replaced {
+		replaced = append(replaced, num)
+	}
+	sort.Ints(replaced)
+	for _, num := range replaced {
+		offsets[num] = out.Len()
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", num, u.replaced[num])
+	}
+	for i, obj := range u.objects {
+		num := u.size + i
+		offsets[num] = out.Len()
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", num, obj)
+	}
+
+	nums := make([]int, 0, len(offsets))
+	for num := range offsets {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	xref := out.Len()
+	out.WriteString("xref\n")
+	for _, num := range nums {
+		fmt.Fprintf(out, "%d 1\n%010d 00000 n \n", num, offsets[num])
+	}
+	trailer := sizePattern.ReplaceAll(u.trailer, []byte(fmt.Sprintf("/Size %d", u.size+len(u.objects))))
+	fmt.Fprintf(out, "trailer\n<<\n%s\n/Prev %d\n>>\nstartxref\n%d\n%%%%EOF\n", trailer, u.prev, xref)
+	return out.Bytes(), nil
+}
+
+// objectDict returns the dictionary of object num as gofpdf wrote it
+func objectDict(data []byte, num int) ([]byte, error) {
+	header := []byte(fmt.Sprintf("\n%d 0 obj\n", num))
+	i := bytes.Index(data, header)
+	if i < 0 {
+		return nil, fmt.Errorf("failed to update PDF: object %d not found", num)
+	}
+	body := data[i+len(header):]
+	end := bytes.Index(body, []byte("\nendobj"))
+	if end < 0 {
+		return nil, fmt.Errorf("failed to update PDF: object %d not terminated", num)
+	}
+	return bytes.TrimSpace(body[:end]), nil
+}
@@ -1,26 +1,117 @@
 package pdf
 
 import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/jung-kurt/gofpdf"
 )
 
 // Save saves the PDF document to a file
 func (d *Document) Save(filePath string) error {
+	data, err := d.encode()
+	if err != nil {
+		return fmt.Errorf("failed to save PDF: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to save PDF: %w", err)
+	}
+
+	return nil
+}
+
+// WriteTo writes the PDF document to w
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	data, err := d.encode()
+	if err != nil {
+		return 0, fmt.Errorf("failed to write PDF: %w", err)
+	}
+	n, err := w.Write(data)
+	if err != nil {
+		return int64(n), fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return int64(n), nil
+}
+
+// Bytes returns the encoded PDF document
+func (d *Document) Bytes() ([]byte, error) {
+	data, err := d.encode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return data, nil
+}
+
+// encode renders the document and adds what gofpdf can't write: the
+// rotation and annotations of pages, the outline, the XMP metadata and
+// character maps of Unicode fonts that readers can extract text with
+func (d *Document) encode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.render().Output(&buf); err != nil {
+		return nil, err
+	}
+
+	u, err := newUpdate(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	for i, page := range d.Pages {
+		if rotation := normalizeRotation(page.Rotation); rotation != 0 {
+			u.addPageEntry(i+1, fmt.Sprintf("/Rotate %d", rotation))
+		}
+	}
+	d.writeAnnotations(u)
+	d.writeOutline(u)
+	d.writeXMP(u)
+	writeToUnicode(u)
+	return u.bytes()
+}
+
+// render lays out the document's pages
+func (d *Document) render() *gofpdf.Fpdf {
 	pdf := gofpdf.New("P", "mm", "A4", "")
 
 	// Set metadata
 	if d.Metadata != nil {
-		pdf.SetTitle(d.Metadata.Title, false)
-		pdf.SetAuthor(d.Metadata.Author, false)
-		pdf.SetSubject(d.Metadata.Subject, false)
-		pdf.SetCreator(d.Metadata.Creator, false)
+		pdf.SetTitle(d.Metadata.Title, true)
+		pdf.SetAuthor(d.Metadata.Author, true)
+		pdf.SetSubject(d.Metadata.Subject, true)
+		pdf.SetKeywords(d.Metadata.Keywords, true)
+		pdf.SetCreator(d.Metadata.Creator, true)
+		if !d.Metadata.Created.IsZero() {
+			pdf.SetCreationDate(d.Metadata.Created)
+		}
+		if !d.Metadata.Modified.IsZero() {
+			pdf.SetModificationDate(d.Metadata.Modified)
+		}
+	}
+
+	fonts := loadFonts(pdf, d.Fonts, d.FallbackFonts)
+	fonts.useSystemFonts(pdf, d.allText())
+
+	// Stamps are drawn by gofpdf as each page is started and finished
+	if d.Watermark != nil && d.Watermark.Text != "" {
+		pdf.SetHeaderFunc(func() { renderWatermark(pdf, *d.Watermark, fonts) })
+	}
+	if d.PageNumbers != "" {
+		pdf.AliasNbPages("{total}")
+		pdf.SetFooterFunc(func() { renderPageNumber(pdf, d.PageNumbers, fonts) })
 	}
 
 	// Process each page
 	for _, page := range d.Pages {
-		pdf.AddPage()
+		if page.Width > 0 && page.Height > 0 {
+			pdf.AddPageFormat("P", gofpdf.SizeType{Wd: page.Width, Ht: page.Height})
+		} else {
+			pdf.AddPage()
+		}
 
 		// Set margins
 		pdf.SetMargins(page.Margin.Left, page.Margin.Top, page.Margin.Right)
@@ -30,24 +121,170 @@ func (d *Document) Save(filePath string) error {
 		for _, content := range page.Content {
 			switch c := content.(type) {
 			case TextContent:
-				renderText(pdf, c)
+				renderText(pdf, c, fonts)
 			case TableContent:
-				renderTable(pdf, c)
+				heights := c.RowHeights
+				if len(heights) != len(c.Rows) {
+					heights = d.TableRowHeights(c)
+				}
+				renderTable(pdf, c, heights, fonts)
+			case ImageContent:
+				renderImage(pdf, c)
 			}
 		}
 	}
 
-	// Save to file
-	err := pdf.OutputFileAndClose(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to save PDF: %w", err)
+	return pdf
+}
+
+// coreFonts are the font families every PDF reader provides
+var coreFonts = map[string]bool{"arial": true, "helvetica": true, "times": true, "courier": true, "symbol": true, "zapfdingbats": true}
+
+// loadFonts embeds the fonts gofpdf can read and returns the styles and
+// characters loaded, with the fallback families among them
+func loadFonts(pdf *gofpdf.Fpdf, fonts []Font, fallback []string) *fontSet {
+	loaded := &fontSet{styles: make(map[string]map[string]bool), glyphs: make(map[string]glyphSet)}
+	for _, f := range fonts {
+		family := strings.ToLower(f.Family)
+		if family == "" || coreFonts[family] || !loadFont(pdf, family, f.Style, f.Data) {
+			continue
+		}
+		if loaded.styles[family] == nil {
+			loaded.styles[family] = make(map[string]bool)
+			if glyphs, ok := readGlyphs(f.Data); ok {
+				loaded.glyphs[family] = glyphs
+			}
+		}
+		loaded.styles[family][f.Style] = true
+	}
+	for _, family := range fallback {
+		if key := strings.ToLower(family); loaded.styles[key] != nil {
+			loaded.fallback = append(loaded.fallback, key)
+		}
 	}
+	return loaded
+}
 
-	return nil
+// loadFont adds a TrueType font to the PDF, reporting whether it could be
+// read. gofpdf panics on some malformed files and records an error on others.
+func loadFont(pdf *gofpdf.Fpdf, family, style string, data []byte) (ok bool) {
+	if len(data) < 4 || (string(data[:4]) != "\x00\x01\x00\x00" && string(data[:4]) != "true") {
+		return false
+	}
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+		if pdf.Err() {
+			pdf.ClearError()
+			ok = false
+		}
+	}()
+	pdf.AddUTF8FontFromBytes(family, style, data)
+	return true
+}
+
+// resolve returns the family and style text should be set in: an embedded
+// family in the closest style it has, a core font, or Arial
+func (f *fontSet) resolve(family, style string) (string, string) {
+	key := strings.ToLower(family)
+	if key == "" {
+		return "Arial", style
+	}
+	if coreFonts[key] {
+		return family, style
+	}
+	styles, ok := f.styles[key]
+	if !ok {
+		return "Arial", style
+	}
+	for _, s := range []string{style, strings.Trim(style, "I"), strings.Trim(style, "B"), ""} {
+		if styles[s] {
+			return key, s
+		}
+	}
+	for _, s := range []string{"B", "I", "BI"} {
+		if styles[s] {
+			return key, s
+		}
+	}
+	return "Arial", style
+}
+
+// measurer measures text for SplitText in the fonts of a document
+type measurer struct {
+	pdf    *gofpdf.Fpdf
+	fonts  *fontSet
+	loaded int // Number of the document's fonts loaded
+}
+
+// SplitText breaks text into the lines it takes up in a width of the given
+// number of millimetres, measured in the font of style. Line breaks in the
+// text are kept, and words longer than a line are broken between characters.
+func (d *Document) SplitText(text string, style TextStyle, width float64) []string {
+	if d.measurer == nil || d.measurer.loaded != len(d.Fonts) {
+		m := &measurer{pdf: gofpdf.New("P", "mm", "A4", ""), loaded: len(d.Fonts)}
+		m.fonts = loadFonts(m.pdf, d.Fonts, d.FallbackFonts)
+		d.measurer = m
+	}
+	d.measurer.fonts.useSystemFonts(d.measurer.pdf, shapeArabic(text))
+
+	fontStyle := ""
+	if style.Bold {
+		fontStyle += "B"
+	}
+	if style.Italic {
+		fontStyle += "I"
+	}
+	fonts := d.measurer.fonts
+	family, fontStyle := fonts.resolve(style.FontFamily, fontStyle)
+	d.measurer.pdf.SetFont(family, fontStyle, style.FontSize)
+	return splitLines(func(s string) float64 {
+		return fonts.width(d.measurer.pdf, family, fontStyle, shapeArabic(s))
+	}, text, width)
 }
 
-// renderText renders text content
-func renderText(pdf *gofpdf.Fpdf, tc TextContent) {
+// splitLines wraps text at spaces into lines no wider than width
+func splitLines(measure func(string) float64, text string, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if measure(candidate) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+
+			// A word wider than a line is broken after the last character that fits
+			for measure(word) > width {
+				runes := []rune(word)
+				n := 1
+				for n < len(runes) && measure(string(runes[:n+1])) <= width {
+					n++
+				}
+				if n == len(runes) {
+					break
+				}
+				lines = append(lines, string(runes[:n]))
+				word = string(runes[n:])
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// renderText renders text content, in the order its characters are read
+// in and with Arabic letters joined
+func renderText(pdf *gofpdf.Fpdf, tc TextContent, fonts *fontSet) {
 	// Set font style
 	style := ""
 	if tc.Bold {
@@ -58,10 +295,7 @@ func renderText(pdf *gofpdf.Fpdf, tc TextContent) {
 	}
 
 	// Set font
-	fontFamily := tc.FontFamily
-	if fontFamily == "" {
-		fontFamily = "Arial"
-	}
+	fontFamily, style := fonts.resolve(tc.FontFamily, style)
 	pdf.SetFont(fontFamily, style, tc.FontSize)
 
 	// Set text color
@@ -72,55 +306,296 @@ func renderText(pdf *gofpdf.Fpdf, tc TextContent) {
 		pdf.SetTextColor(0, 0, 0)
 	}
 
+	// Aligned text starts where its width leaves it in the space it's aligned in
+	width := tc.Width
+	if width <= 0 {
+		pageWidth, _ := pdf.GetPageSize()
+		_, _, right, _ := pdf.GetMargins()
+		width = pageWidth - right - tc.X
+	}
+	text := visualOrder(shapeArabic(tc.Text), tc.Direction)
+	x := tc.X
+	switch tc.Align {
+	case "center":
+		x += (width - textWidth(pdf, fonts, fontFamily, style, text)) / 2
+	case "right":
+		x += width - textWidth(pdf, fonts, fontFamily, style, text)
+	case "justify":
+		if words := strings.Fields(text); len(words) > 1 && !strings.Contains(text, redactionChar) {
+			renderJustified(pdf, fonts, fontFamily, style, words, x, tc.Y, width, tc.FontSize*0.35)
+			return
+		}
+	}
+
 	// Set position and write text
-	pdf.SetXY(tc.X, tc.Y)
-	pdf.Cell(0, tc.FontSize*0.35, tc.Text)
+	pdf.SetXY(x, tc.Y)
+	if !strings.Contains(text, redactionChar) {
+		fonts.cell(pdf, fontFamily, style, text, tc.FontSize*0.35)
+		return
+	}
+
+	// Redacted characters are drawn as black boxes; the core fonts have no glyph for them
+	r, g, b := pdf.GetFillColor()
+	pdf.SetFillColor(0, 0, 0)
+	boxWidth := pdf.GetStringWidth("M")
+	for _, segment := range splitRedacted(text) {
+		if strings.HasPrefix(segment, redactionChar) {
+			n := utf8.RuneCountInString(segment)
+			pdf.CellFormat(float64(n)*boxWidth, tc.FontSize*0.35, "", "", 0, "", true, 0, "")
+		} else {
+			fonts.cell(pdf, fontFamily, style, segment, tc.FontSize*0.35)
+		}
+	}
+	pdf.SetFillColor(r, g, b)
+}
+
+// textWidth returns the width of text in family and style at the current
+// font size, counting redacted characters as the boxes they are drawn as
+func textWidth(pdf *gofpdf.Fpdf, fonts *fontSet, family, style, text string) float64 {
+	width := 0.0
+	for _, segment := range splitRedacted(text) {
+		if strings.HasPrefix(segment, redactionChar) {
+			width += float64(utf8.RuneCountInString(segment)) * pdf.GetStringWidth("M")
+		} else {
+			width += fonts.width(pdf, family, style, segment)
+		}
+	}
+	return width
 }
 
-// renderTable renders a table
-func renderTable(pdf *gofpdf.Fpdf, tc TableContent) {
-	pdf.SetXY(tc.X, tc.Y)
+// renderJustified draws words spread out to fill width, with equal space
+// between them
+func renderJustified(pdf *gofpdf.Fpdf, fonts *fontSet, family, style string, words []string, x, y, width, height float64) {
+	widths := make([]float64, len(words))
+	used := 0.0
+	for i, word := range words {
+		widths[i] = fonts.width(pdf, family, style, word)
+		used += widths[i]
+	}
+	gap := (width - used) / float64(len(words)-1)
+	for i, word := range words {
+		pdf.SetXY(x, y)
+		fonts.cell(pdf, family, style, word, height)
+		x += widths[i] + gap
+	}
+}
 
-	// Calculate column widths if not provided
-	colWidths := tc.ColumnWidth
-	if len(colWidths) == 0 && len(tc.Rows) > 0 && len(tc.Rows[0]) > 0 {
-		numCols := len(tc.Rows[0])
-		availableWidth := 170.0 // A4 width minus margins
-		colWidth := availableWidth / float64(numCols)
-		colWidths = make([]float64, numCols)
-		for i := range colWidths {
-			colWidths[i] = colWidth
+// splitRedacted splits text into runs of redacted and plain characters
+func splitRedacted(text string) []string {
+	var segments []string
+	for text != "" {
+		i := 0
+		redacted := strings.HasPrefix(text, redactionChar)
+		for i < len(text) && strings.HasPrefix(text[i:], redactionChar) == redacted {
+			_, size := utf8.DecodeRuneInString(text[i:])
+			i += size
 		}
+		segments = append(segments, text[:i])
+		text = text[i:]
 	}
+	return segments
+}
+
+// Spacing of table cells, in mm
+const (
+	tableCellPadding = 1.0 // Between the border and the text
+	tableRowHeight   = 8.0 // Least height of a row
+)
+
+// LineHeight returns the distance between lines of text in a font size in
+// points, in mm
+func LineHeight(fontSize float64) float64 {
+	return fontSize * 25.4 / 72 * 1.3
+}
 
-	// Render rows
+// TableRowHeights returns the height of each row of a table, in mm: the
+// height of its tallest cell once the text is wrapped to the column width
+func (d *Document) TableRowHeights(tc TableContent) []float64 {
+	widths := tableColumnWidths(tc)
+	heights := make([]float64, len(tc.Rows))
 	for i, row := range tc.Rows {
+		heights[i] = tableRowHeight
+		style := tableRowStyle(tc, i)
 		for j, cell := range row {
-			if j >= len(colWidths) {
+			if j >= len(widths) {
 				break
 			}
+			lines := len(d.SplitText(cell, style, widths[j]-2*tableCellPadding))
+			if h := float64(lines)*LineHeight(style.FontSize) + 2*tableCellPadding; h > heights[i] {
+				heights[i] = h
+			}
+		}
+	}
+	return heights
+}
 
-			// Use header style for first row, cell style for others
+// tableColumnWidths returns the column widths of a table, splitting the
+// width of an A4 page between the margins evenly if they aren't given
+func tableColumnWidths(tc TableContent) []float64 {
+	if len(tc.ColumnWidth) > 0 || len(tc.Rows) == 0 || len(tc.Rows[0]) == 0 {
+		return tc.ColumnWidth
+	}
+	numCols := len(tc.Rows[0])
+	availableWidth := 170.0 // A4 width minus margins
+	colWidths := make([]float64, numCols)
+	for i := range colWidths {
+		colWidths[i] = availableWidth / float64(numCols)
+	}
+	return colWidths
+}
+
+// tableRowStyle returns the text style of a table row: the header style for
+// the first row, and the cell style for the others
+func tableRowStyle(tc TableContent, row int) TextStyle {
+	switch {
+	case row == 0 && tc.HeaderStyle != nil:
+		style := *tc.HeaderStyle
+		style.Bold = true
+		return style
+	case tc.CellStyle != nil:
+		return *tc.CellStyle
+	default:
+		return TextStyle{FontFamily: "Arial", FontSize: 10}
+	}
+}
+
+// renderTable renders a table, wrapping the text of each cell within its column
+func renderTable(pdf *gofpdf.Fpdf, tc TableContent, heights []float64, fonts *fontSet) {
+	widths := tableColumnWidths(tc)
+	pdf.SetTextColor(0, 0, 0)
+
+	y := tc.Y
+	for i, row := range tc.Rows {
+		style := tableRowStyle(tc, i)
+		fontStyle := ""
+		if style.Bold {
+			fontStyle = "B"
+		}
+		family, fontStyle := fonts.resolve(style.FontFamily, fontStyle)
+		pdf.SetFont(family, fontStyle, style.FontSize)
+		lineHeight := LineHeight(style.FontSize)
+
+		x := tc.X
+		for j, cell := range row {
+			if j >= len(widths) {
+				break
+			}
+
+			// Use a gray background for the header row and white for others
 			if i == 0 && tc.HeaderStyle != nil {
-				pdf.SetFont(tc.HeaderStyle.FontFamily, "B", tc.HeaderStyle.FontSize)
-				pdf.SetFillColor(200, 200, 200) // Light gray background
-			} else if tc.CellStyle != nil {
-				style := ""
-				if tc.CellStyle.Bold {
-					style = "B"
-				}
-				pdf.SetFont(tc.CellStyle.FontFamily, style, tc.CellStyle.FontSize)
-				pdf.SetFillColor(255, 255, 255) // White background
+				pdf.SetFillColor(200, 200, 200)
 			} else {
-				pdf.SetFont("Arial", "", 10)
 				pdf.SetFillColor(255, 255, 255)
 			}
 
-			// Draw cell with border
-			pdf.CellFormat(colWidths[j], 8, cell, "1", 0, "L", true, 0, "")
+			// Cells with redacted text are blacked out entirely
+			if strings.Contains(cell, redactionChar) {
+				pdf.SetFillColor(0, 0, 0)
+				cell = ""
+			}
+
+			// Draw the cell with its border, and its lines centered vertically;
+			// right-to-left text is set against the right border
+			pdf.Rect(x, y, widths[j], heights[i], "FD")
+			measure := func(s string) float64 { return fonts.width(pdf, family, fontStyle, shapeArabic(s)) }
+			lines := splitLines(measure, cell, widths[j]-2*tableCellPadding)
+			dir := "ltr"
+			if baseRTL(cell, "") {
+				dir = "rtl"
+			}
+			top := y + (heights[i]-float64(len(lines))*lineHeight)/2
+			for k, line := range lines {
+				line = visualOrder(shapeArabic(line), dir)
+				lineX := x
+				if dir == "rtl" {
+					lineX = x + widths[j] - 2*pdf.GetCellMargin() - fonts.width(pdf, family, fontStyle, line)
+				}
+				pdf.SetXY(lineX, top+float64(k)*lineHeight)
+				fonts.cell(pdf, family, fontStyle, line, lineHeight)
+			}
+			x += widths[j]
 		}
-		pdf.Ln(-1) // New line
+		y += heights[i]
 	}
+	pdf.SetXY(tc.X, y)
+}
+
+// renderImage draws an image, given as data or a file, leaving out images
+// gofpdf can't read. Images are registered under a hash of their data, so
+// an image placed many times is stored once.
+func renderImage(pdf *gofpdf.Fpdf, ic ImageContent) {
+	if pdf.Err() {
+		return
+	}
+	options := gofpdf.ImageOptions{ReadDpi: true}
+	name := ic.Path
+	if ic.Data != nil {
+		sum := sha1.Sum(ic.Data)
+		name = hex.EncodeToString(sum[:])
+		options.ImageType = imageType(ic.Data)
+		if options.ImageType == "" {
+			return
+		}
+		pdf.RegisterImageOptionsReader(name, options, bytes.NewReader(ic.Data))
+	}
+	pdf.ImageOptions(name, ic.X, ic.Y, ic.Width, ic.Height, false, options, 0, "")
+	if pdf.Err() {
+		pdf.ClearError()
+	}
+}
+
+// imageType returns the gofpdf image type of image data, or "" for formats
+// PDF can't hold
+func imageType(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG")):
+		return "png"
+	case bytes.HasPrefix(data, []byte("\xff\xd8")):
+		return "jpg"
+	case bytes.HasPrefix(data, []byte("GIF8")):
+		return "gif"
+	}
+	return ""
+}
+
+// renderWatermark draws a watermark rotated around the center of the current page
+func renderWatermark(pdf *gofpdf.Fpdf, wm Watermark, fonts *fontSet) {
+	fontSize := wm.FontSize
+	if fontSize <= 0 {
+		fontSize = 60
+	}
+	opacity := wm.Opacity
+	if opacity <= 0 || opacity > 1 {
+		opacity = 1
+	}
+
+	pdf.SetFont("Arial", "B", fontSize)
+	r, g, b := hexToRGB(wm.Color)
+	pdf.SetTextColor(r, g, b)
+	pdf.SetAlpha(opacity, "Normal")
+
+	text := visualOrder(shapeArabic(wm.Text), "")
+	width, height := pdf.GetPageSize()
+	cx, cy := width/2, height/2
+	pdf.TransformBegin()
+	pdf.TransformRotate(wm.Angle, cx, cy)
+	fonts.text(pdf, "Arial", "B", cx-fonts.width(pdf, "Arial", "B", text)/2, cy+fontSize*0.35/2, text)
+	pdf.TransformEnd()
+
+	pdf.SetAlpha(1, "Normal")
+}
+
+// renderPageNumber writes the page number footer of the current page
+func renderPageNumber(pdf *gofpdf.Fpdf, format string, fonts *fontSet) {
+	text := visualOrder(shapeArabic(strings.ReplaceAll(format, "{n}", strconv.Itoa(pdf.PageNo()))), "")
+
+	pdf.SetFont("Arial", "", 9)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetY(-12)
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	pdf.SetX(left + (pageWidth-left-right-fonts.width(pdf, "Arial", "", text))/2 - pdf.GetCellMargin())
+	fonts.cell(pdf, "Arial", "", text, 5)
 }
 
 // hexToRGB converts hex color to RGB
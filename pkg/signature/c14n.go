@@ -0,0 +1,240 @@
+package signature
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// xmlNode is an element of a parsed XML document, keeping the prefixes
+// and namespace declarations as written so it can be canonicalized
+type xmlNode struct {
+	prefix, local string
+	decls         map[string]string // Namespace declarations, by prefix; "" for the default namespace
+	attrs         []xml.Attr        // Other attributes, with prefixes in Name.Space
+	children      []any             // *xmlNode or string
+	parent        *xmlNode
+}
+
+// parseXML parses a document into a tree of elements, leaving out
+// comments and processing instructions
+func parseXML(data []byte) (*xmlNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var root, current *xmlNode
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{prefix: t.Name.Space, local: t.Name.Local, decls: map[string]string{}, parent: current}
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					n.decls[""] = a.Value
+				case a.Name.Space == "xmlns":
+					n.decls[a.Name.Local] = a.Value
+				default:
+					n.attrs = append(n.attrs, a)
+				}
+			}
+			if current == nil {
+				if root != nil {
+					return nil, fmt.Errorf("more than one root element")
+				}
+				root = n
+			} else {
+				current.children = append(current.children, n)
+			}
+			current = n
+		case xml.EndElement:
+			if current == nil {
+				return nil, fmt.Errorf("unexpected end element %s", t.Name.Local)
+			}
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, string(t))
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("no root element")
+	}
+	return root, nil
+}
+
+// namespaces returns the namespaces in scope at an element, by prefix
+func (n *xmlNode) namespaces() map[string]string {
+	var chain []*xmlNode
+	for e := n; e != nil; e = e.parent {
+		chain = append(chain, e)
+	}
+	scope := map[string]string{}
+	for i := len(chain) - 1; i >= 0; i-- {
+		for prefix, uri := range chain[i].decls {
+			scope[prefix] = uri
+		}
+	}
+	return scope
+}
+
+// attr returns the value of an unprefixed attribute
+func (n *xmlNode) attr(name string) string {
+	for _, a := range n.attrs {
+		if a.Name.Space == "" && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// child returns the first child element with a local name
+func (n *xmlNode) child(local string) *xmlNode {
+	for _, c := range n.children {
+		if e, ok := c.(*xmlNode); ok && e.local == local {
+			return e
+		}
+	}
+	return nil
+}
+
+// elements returns the child elements with a local name
+func (n *xmlNode) elements(local string) []*xmlNode {
+	var found []*xmlNode
+	for _, c := range n.children {
+		if e, ok := c.(*xmlNode); ok && e.local == local {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// text returns the text of an element and its descendants, or "" for a
+// missing element
+func (n *xmlNode) text() string {
+	if n == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, c := range n.children {
+		switch v := c.(type) {
+		case string:
+			sb.WriteString(v)
+		case *xmlNode:
+			sb.WriteString(v.text())
+		}
+	}
+	return sb.String()
+}
+
+// find returns the first element, the node itself included, with an Id
+// attribute of the given value
+func (n *xmlNode) find(id string) *xmlNode {
+	if n.attr("Id") == id {
+		return n
+	}
+	for _, c := range n.children {
+		if e, ok := c.(*xmlNode); ok {
+			if found := e.find(id); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// canonicalize returns an element and its descendants in Canonical XML 1.0
+// form, without comments, as XML signatures digest and sign them
+func canonicalize(n *xmlNode) []byte {
+	var buf bytes.Buffer
+	writeCanonical(&buf, n, map[string]string{}, n.namespaces())
+	return buf.Bytes()
+}
+
+// writeCanonical writes an element whose parent rendered the namespaces
+// rendered and which has the namespaces in scope
+func writeCanonical(buf *bytes.Buffer, n *xmlNode, rendered, scope map[string]string) {
+	name := n.local
+	if n.prefix != "" {
+		name = n.prefix + ":" + n.local
+	}
+	buf.WriteString("<" + name)
+
+	// Namespace declarations not already in effect, default first, then by prefix
+	var prefixes []string
+	for prefix, uri := range scope {
+		if rendered[prefix] != uri {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			buf.WriteString(` xmlns="` + escapeAttr(scope[prefix]) + `"`)
+		} else {
+			buf.WriteString(` xmlns:` + prefix + `="` + escapeAttr(scope[prefix]) + `"`)
+		}
+	}
+
+	// Attributes by namespace URI, then local name; unprefixed ones have no namespace
+	attrs := append([]xml.Attr(nil), n.attrs...)
+	uri := func(a xml.Attr) string {
+		if a.Name.Space == "" {
+			return ""
+		}
+		if a.Name.Space == "xml" {
+			return "http://www.w3.org/XML/1998/namespace"
+		}
+		return scope[a.Name.Space]
+	}
+	sort.SliceStable(attrs, func(i, j int) bool {
+		if ui, uj := uri(attrs[i]), uri(attrs[j]); ui != uj {
+			return ui < uj
+		}
+		return attrs[i].Name.Local < attrs[j].Name.Local
+	})
+	for _, a := range attrs {
+		attrName := a.Name.Local
+		if a.Name.Space != "" {
+			attrName = a.Name.Space + ":" + a.Name.Local
+		}
+		buf.WriteString(" " + attrName + `="` + escapeAttr(a.Value) + `"`)
+	}
+	buf.WriteString(">")
+
+	for _, c := range n.children {
+		switch v := c.(type) {
+		case string:
+			buf.WriteString(escapeText(v))
+		case *xmlNode:
+			childScope := scope
+			if len(v.decls) > 0 {
+				childScope = make(map[string]string, len(scope)+len(v.decls))
+				for prefix, uri := range scope {
+					childScope[prefix] = uri
+				}
+				for prefix, uri := range v.decls {
+					childScope[prefix] = uri
+				}
+			}
+			writeCanonical(buf, v, scope, childScope)
+		}
+	}
+	buf.WriteString("</" + name + ">")
+}
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+)
+
+func escapeText(s string) string { return textEscaper.Replace(s) }
+func escapeAttr(s string) string { return attrEscaper.Replace(s) }
@@ -0,0 +1,633 @@
+package signature
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names the Open Packaging Conventions give digital signature parts and relationships
+const (
+	originPart     = "_xmlsignatures/origin.sigs"
+	originRelsPart = "_xmlsignatures/_rels/origin.sigs.rels"
+	packageRels    = "_rels/.rels"
+	contentTypes   = "[Content_Types].xml"
+
+	relTypeOrigin    = "http://schemas.openxmlformats.org/package/2006/relationships/digital-signature/origin"
+	relTypeSignature = "http://schemas.openxmlformats.org/package/2006/relationships/digital-signature/signature"
+
+	contentTypeOrigin    = "application/vnd.openxmlformats-package.digital-signature-origin"
+	contentTypeSignature = "application/vnd.openxmlformats-package.digital-signature-xmlsignature+xml"
+
+	relationshipsNS = "http://schemas.openxmlformats.org/package/2006/relationships"
+	digsigNS        = "http://schemas.openxmlformats.org/package/2006/digital-signature"
+	xmldsigNS       = "http://www.w3.org/2000/09/xmldsig#"
+	officeDigsigNS  = "http://schemas.microsoft.com/office/2006/digsig"
+
+	algC14N                  = "http://www.w3.org/TR/2001/REC-xml-c14n-20010315"
+	algC14NWithComments      = "http://www.w3.org/TR/2001/REC-xml-c14n-20010315#WithComments"
+	algRelationshipTransform = "http://schemas.openxmlformats.org/package/2006/RelationshipTransform"
+	algSHA256                = "http://www.w3.org/2001/04/xmlenc#sha256"
+	algRSASHA256             = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	algECDSASHA256           = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256"
+)
+
+// digestMethods are the digest algorithms signatures are verified with
+var digestMethods = map[string]func() hash.Hash{
+	"http://www.w3.org/2000/09/xmldsig#sha1":        sha1.New,
+	algSHA256:                                       sha256.New,
+	"http://www.w3.org/2001/04/xmldsig-more#sha384": sha512.New384,
+	"http://www.w3.org/2001/04/xmlenc#sha512":       sha512.New,
+}
+
+// signatureMethods are the signature algorithms signatures are verified
+// with, by the hash they sign
+var signatureMethods = map[string]crypto.Hash{
+	"http://www.w3.org/2000/09/xmldsig#rsa-sha1": crypto.SHA1,
+	algRSASHA256: crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha384": crypto.SHA384,
+	"http://www.w3.org/2001/04/xmldsig-more#rsa-sha512": crypto.SHA512,
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha1": crypto.SHA1,
+	algECDSASHA256: crypto.SHA256,
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha384": crypto.SHA384,
+	"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha512": crypto.SHA512,
+}
+
+// opcPackage is the content of a DOCX archive
+type opcPackage struct {
+	names []string // In archive order
+	parts map[string][]byte
+}
+
+func readPackage(data []byte) (*opcPackage, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DOCX archive: %w", err)
+	}
+	pkg := &opcPackage{parts: make(map[string][]byte)}
+	for _, f := range r.File {
+		if strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		pkg.names = append(pkg.names, f.Name)
+		pkg.parts[f.Name] = content
+	}
+	return pkg, nil
+}
+
+// set adds or replaces a part
+func (p *opcPackage) set(name string, data []byte) {
+	if _, ok := p.parts[name]; !ok {
+		p.names = append(p.names, name)
+	}
+	p.parts[name] = data
+}
+
+func (p *opcPackage) bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range p.names {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(p.parts[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// contentType returns the content type [Content_Types].xml gives a part
+func (p *opcPackage) contentType(name string) string {
+	var types struct {
+		Defaults []struct {
+			Extension   string `xml:"Extension,attr"`
+			ContentType string `xml:"ContentType,attr"`
+		} `xml:"Default"`
+		Overrides []struct {
+			PartName    string `xml:"PartName,attr"`
+			ContentType string `xml:"ContentType,attr"`
+		} `xml:"Override"`
+	}
+	if err := xml.Unmarshal(p.parts[contentTypes], &types); err != nil {
+		return ""
+	}
+	for _, o := range types.Overrides {
+		if strings.EqualFold(strings.TrimPrefix(o.PartName, "/"), name) {
+			return o.ContentType
+		}
+	}
+	ext := strings.TrimPrefix(path.Ext(name), ".")
+	for _, d := range types.Defaults {
+		if strings.EqualFold(d.Extension, ext) {
+			return d.ContentType
+		}
+	}
+	return ""
+}
+
+// relationship is an entry of a relationships part
+type relationship struct {
+	ID         string `xml:"Id,attr"`
+	Type       string `xml:"Type,attr"`
+	Target     string `xml:"Target,attr"`
+	TargetMode string `xml:"TargetMode,attr"`
+}
+
+func parseRelationships(data []byte) []relationship {
+	var rels struct {
+		Relationships []relationship `xml:"Relationship"`
+	}
+	_ = xml.Unmarshal(data, &rels)
+	return rels.Relationships
+}
+
+// relsTarget returns the part a relationship of a relationships part points to
+func relsTarget(relsName, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	source := path.Dir(path.Dir(relsName)) // _xmlsignatures/_rels/origin.sigs.rels -> _xmlsignatures
+	return path.Clean(path.Join(source, target))
+}
+
+// addRelationship adds a relationship to a relationships part, creating
+// the part when it doesn't exist
+func (p *opcPackage) addRelationship(relsName, relType, target string) {
+	rels := parseRelationships(p.parts[relsName])
+	used := make(map[string]bool, len(rels))
+	for _, rel := range rels {
+		used[rel.ID] = true
+	}
+	id := "rId1"
+	for n := 1; used[id]; n++ {
+		id = "rId" + strconv.Itoa(n)
+	}
+	entry := `<Relationship Id="` + id + `" Type="` + relType + `" Target="` + escapeAttr(target) + `"/>`
+
+	data, ok := p.parts[relsName]
+	end := bytes.LastIndex(data, []byte("</Relationships>"))
+	if !ok || end < 0 {
+		p.set(relsName, []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="`+relationshipsNS+`">`+entry+`</Relationships>`))
+		return
+	}
+	p.set(relsName, []byte(string(data[:end])+entry+string(data[end:])))
+}
+
+// addContentType adds a Default or Override entry to [Content_Types].xml
+func (p *opcPackage) addContentType(entry string) {
+	data := p.parts[contentTypes]
+	if bytes.Contains(data, []byte(entry)) {
+		return
+	}
+	end := bytes.LastIndex(data, []byte("</Types>"))
+	if end < 0 {
+		return
+	}
+	p.set(contentTypes, []byte(string(data[:end])+entry+string(data[end:])))
+}
+
+// relationshipTransform applies the relationship transform of the Open
+// Packaging Conventions: the relationships selected by ID or type, sorted
+// by ID and stripped down to their attributes, in canonical form
+func relationshipTransform(data []byte, ids, types map[string]bool) []byte {
+	var selected []relationship
+	for _, rel := range parseRelationships(data) {
+		if ids[rel.ID] || types[rel.Type] {
+			selected = append(selected, rel)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].ID < selected[j].ID })
+
+	var buf bytes.Buffer
+	buf.WriteString(`<Relationships xmlns="` + relationshipsNS + `">`)
+	for _, rel := range selected {
+		mode := rel.TargetMode
+		if mode == "" {
+			mode = "Internal"
+		}
+		fmt.Fprintf(&buf, `<Relationship Id="%s" Target="%s" TargetMode="%s" Type="%s"></Relationship>`,
+			escapeAttr(rel.ID), escapeAttr(rel.Target), escapeAttr(mode), escapeAttr(rel.Type))
+	}
+	buf.WriteString(`</Relationships>`)
+	return buf.Bytes()
+}
+
+// signedParts returns the parts a new signature covers: all but the
+// content types and the signature parts themselves
+func (p *opcPackage) signedParts() []string {
+	var names []string
+	for _, name := range p.names {
+		if name == contentTypes || strings.HasPrefix(name, "_xmlsignatures/") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// manifestReference returns the Reference element for a part in the
+// package object's manifest
+func (p *opcPackage) manifestReference(name string) string {
+	uri := "/" + name + "?ContentType=" + p.contentType(name)
+	content := p.parts[name]
+	transforms := ""
+	if strings.HasSuffix(name, ".rels") {
+		ids := make(map[string]bool)
+		var sb strings.Builder
+		for _, rel := range parseRelationships(content) {
+			if rel.Type == relTypeOrigin {
+				continue // Left out so later signatures don't change what this one covers
+			}
+			ids[rel.ID] = true
+			sb.WriteString(`<mdssi:RelationshipReference xmlns:mdssi="` + digsigNS + `" SourceId="` + escapeAttr(rel.ID) + `"></mdssi:RelationshipReference>`)
+		}
+		transforms = `<Transforms><Transform Algorithm="` + algRelationshipTransform + `">` + sb.String() +
+			`</Transform><Transform Algorithm="` + algC14N + `"></Transform></Transforms>`
+		content = relationshipTransform(content, ids, nil)
+	}
+	sum := sha256.Sum256(content)
+	return `<Reference URI="` + escapeAttr(uri) + `">` + transforms + `<DigestMethod Algorithm="` + algSHA256 + `"></DigestMethod><DigestValue>` +
+		base64.StdEncoding.EncodeToString(sum[:]) + `</DigestValue></Reference>`
+}
+
+// SignDOCX signs a DOCX document with an XML signature covering all of its
+// parts, and returns the signed document. Signatures already in the
+// document stay valid, and any later change to it, even saving it again
+// with docxsmith, invalidates the new one.
+func SignDOCX(data []byte, signer *Signer, opts Options) ([]byte, error) {
+	pkg, err := readPackage(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := pkg.parts[contentTypes]; !ok {
+		return nil, fmt.Errorf("%w: missing %s", ErrUnsupportedFormat, contentTypes)
+	}
+
+	method := algRSASHA256
+	switch signer.Key.Public().(type) {
+	case *rsa.PublicKey:
+	case *ecdsa.PublicKey:
+		method = algECDSASHA256
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", signer.Key.Public())
+	}
+
+	// Package object: a digest of every part, and the signing time
+	var manifest strings.Builder
+	for _, name := range pkg.signedParts() {
+		manifest.WriteString(pkg.manifestReference(name))
+	}
+	packageObject := `<Object Id="idPackageObject"><Manifest>` + manifest.String() + `</Manifest>` +
+		`<SignatureProperties><SignatureProperty Id="idSignatureTime" Target="#idPackageSignature">` +
+		`<mdssi:SignatureTime xmlns:mdssi="` + digsigNS + `"><mdssi:Format>YYYY-MM-DDThh:mm:ssTZD</mdssi:Format>` +
+		`<mdssi:Value>` + opts.signingTime().Format(time.RFC3339) + `</mdssi:Value></mdssi:SignatureTime>` +
+		`</SignatureProperty></SignatureProperties></Object>`
+
+	// Office object: Word shows the comments as the purpose of the
+	// signature, and expects the rest of the details Office records
+	officeObject := `<Object Id="idOfficeObject"><SignatureProperties><SignatureProperty Id="idOfficeV1Details" Target="#idPackageSignature">` +
+		`<SignatureInfoV1 xmlns="` + officeDigsigNS + `"><SetupID></SetupID><SignatureText></SignatureText><SignatureImage></SignatureImage>` +
+		`<SignatureComments>` + escapeText(opts.Reason) + `</SignatureComments><WindowsVersion>10.0</WindowsVersion><OfficeVersion>16.0</OfficeVersion>` +
+		`<ApplicationVersion>16.0</ApplicationVersion><Monitors>1</Monitors><HorizontalResolution>1920</HorizontalResolution><VerticalResolution>1080</VerticalResolution>` +
+		`<ColorDepth>32</ColorDepth><SignatureProviderId>{00000000-0000-0000-0000-000000000000}</SignatureProviderId><SignatureProviderUrl></SignatureProviderUrl>` +
+		`<SignatureProviderDetails>9</SignatureProviderDetails><SignatureType>1</SignatureType></SignatureInfoV1></SignatureProperty></SignatureProperties></Object>`
+
+	build := func(signedInfo, signatureValue string) string {
+		return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+			`<Signature xmlns="` + xmldsigNS + `" Id="idPackageSignature">` + signedInfo +
+			`<SignatureValue>` + signatureValue + `</SignatureValue>` +
+			`<KeyInfo><X509Data><X509Certificate>` + base64.StdEncoding.EncodeToString(signer.Certificate.Raw) + `</X509Certificate></X509Data></KeyInfo>` +
+			packageObject + officeObject + `</Signature>`
+	}
+
+	// The objects are digested in canonical form, inside the signature
+	draft, err := parseXML([]byte(build("", "")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build signature: %w", err)
+	}
+	signedInfo := `<SignedInfo><CanonicalizationMethod Algorithm="` + algC14N + `"></CanonicalizationMethod><SignatureMethod Algorithm="` + method + `"></SignatureMethod>`
+	for _, id := range []string{"idPackageObject", "idOfficeObject"} {
+		sum := sha256.Sum256(canonicalize(draft.find(id)))
+		signedInfo += `<Reference Type="http://www.w3.org/2000/09/xmldsig#Object" URI="#` + id + `"><DigestMethod Algorithm="` + algSHA256 + `"></DigestMethod>` +
+			`<DigestValue>` + base64.StdEncoding.EncodeToString(sum[:]) + `</DigestValue></Reference>`
+	}
+	signedInfo += `</SignedInfo>`
+
+	withInfo, err := parseXML([]byte(build(signedInfo, "")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build signature: %w", err)
+	}
+	digest := sha256.Sum256(canonicalize(withInfo.child("SignedInfo")))
+	value, err := signer.Key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	if key, ok := signer.Key.Public().(*ecdsa.PublicKey); ok {
+		if value, err = ecdsaRaw(value, key); err != nil {
+			return nil, err
+		}
+	}
+
+	// Store the signature and link it from the package's signature origin
+	var sigPart string
+	for n := 1; ; n++ {
+		sigPart = "_xmlsignatures/sig" + strconv.Itoa(n) + ".xml"
+		if _, taken := pkg.parts[sigPart]; !taken {
+			break
+		}
+	}
+	pkg.set(sigPart, []byte(build(signedInfo, base64.StdEncoding.EncodeToString(value))))
+	if _, ok := pkg.parts[originPart]; !ok {
+		pkg.set(originPart, nil)
+	}
+	hasOrigin := false
+	for _, rel := range parseRelationships(pkg.parts[packageRels]) {
+		hasOrigin = hasOrigin || rel.Type == relTypeOrigin
+	}
+	if !hasOrigin {
+		pkg.addRelationship(packageRels, relTypeOrigin, originPart)
+	}
+	pkg.addRelationship(originRelsPart, relTypeSignature, path.Base(sigPart))
+	pkg.addContentType(`<Default Extension="sigs" ContentType="` + contentTypeOrigin + `"/>`)
+	pkg.addContentType(`<Override PartName="/` + sigPart + `" ContentType="` + contentTypeSignature + `"/>`)
+
+	return pkg.bytes()
+}
+
+// ecdsaRaw converts an ASN.1 ECDSA signature to the r and s values side
+// by side that XML signatures hold
+func ecdsaRaw(der []byte, key *ecdsa.PublicKey) ([]byte, error) {
+	var sig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("failed to read ECDSA signature: %w", err)
+	}
+	size := (key.Curve.Params().BitSize + 7) / 8
+	raw := make([]byte, 2*size)
+	sig.R.FillBytes(raw[:size])
+	sig.S.FillBytes(raw[size:])
+	return raw, nil
+}
+
+// VerifyDOCX returns the XML signatures of a DOCX document, each checked
+// against the parts it covers
+func VerifyDOCX(data []byte) ([]Signature, error) {
+	pkg, err := readPackage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var sigParts []string
+	for _, rel := range parseRelationships(pkg.parts[packageRels]) {
+		if rel.Type != relTypeOrigin {
+			continue
+		}
+		origin := relsTarget(packageRels, rel.Target)
+		originRels := path.Join(path.Dir(origin), "_rels", path.Base(origin)+".rels")
+		for _, sig := range parseRelationships(pkg.parts[originRels]) {
+			if sig.Type == relTypeSignature {
+				sigParts = append(sigParts, relsTarget(originRels, sig.Target))
+			}
+		}
+	}
+	if len(sigParts) == 0 {
+		return nil, ErrNotSigned
+	}
+
+	signatures := make([]Signature, 0, len(sigParts))
+	for _, name := range sigParts {
+		signatures = append(signatures, pkg.verifySignature(name))
+	}
+	return signatures, nil
+}
+
+// verifySignature checks the signature in a signature part
+func (p *opcPackage) verifySignature(name string) Signature {
+	var sig Signature
+	invalid := func(format string, args ...any) Signature {
+		sig.Err = fmt.Errorf("%w: %s", ErrInvalidSignature, fmt.Sprintf(format, args...))
+		return sig
+	}
+
+	data, ok := p.parts[name]
+	if !ok {
+		return invalid("missing signature part %s", name)
+	}
+	root, err := parseXML(data)
+	if err != nil || root.local != "Signature" {
+		return invalid("malformed signature part %s", name)
+	}
+
+	var certs []*x509.Certificate
+	if keyInfo := root.child("KeyInfo"); keyInfo != nil {
+		for _, x509Data := range keyInfo.elements("X509Data") {
+			for _, c := range x509Data.elements("X509Certificate") {
+				der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(c.text()), ""))
+				if err != nil {
+					continue
+				}
+				if cert, err := x509.ParseCertificate(der); err == nil {
+					certs = append(certs, cert)
+				}
+			}
+		}
+	}
+	if len(certs) == 0 {
+		return invalid("no signing certificate")
+	}
+	sig.Certificate = certs[0]
+	sig.Signer = certs[0].Subject.CommonName
+	if t := descendant(root, "SignatureTime"); t != nil {
+		if v := t.child("Value"); v != nil {
+			sig.Time, _ = time.Parse(time.RFC3339, strings.TrimSpace(v.text()))
+		}
+	}
+	if c := descendant(root, "SignatureComments"); c != nil {
+		sig.Reason = c.text()
+	}
+	sig.Trusted = trusted(sig.Certificate, certs[1:], sig.Time)
+
+	signedInfo := root.child("SignedInfo")
+	if signedInfo == nil {
+		return invalid("no SignedInfo")
+	}
+	if m := signedInfo.child("CanonicalizationMethod"); m == nil || m.attr("Algorithm") != algC14N {
+		return invalid("unsupported canonicalization method")
+	}
+
+	// Each reference of the signed info, and of the manifests it covers
+	for _, ref := range signedInfo.elements("Reference") {
+		if err := p.checkReference(root, ref); err != nil {
+			return invalid("%v", err)
+		}
+	}
+	for _, object := range root.elements("Object") {
+		if manifest := object.child("Manifest"); manifest != nil {
+			for _, ref := range manifest.elements("Reference") {
+				if err := p.checkReference(root, ref); err != nil {
+					return invalid("%v", err)
+				}
+			}
+		}
+	}
+
+	method := signedInfo.child("SignatureMethod")
+	if method == nil {
+		return invalid("no signature method")
+	}
+	hashAlg, ok := signatureMethods[method.attr("Algorithm")]
+	if !ok {
+		return invalid("unsupported signature method %s", method.attr("Algorithm"))
+	}
+	value, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(root.child("SignatureValue").text()), ""))
+	if err != nil {
+		return invalid("malformed signature value")
+	}
+	h := hashAlg.New()
+	h.Write(canonicalize(signedInfo))
+	digest := h.Sum(nil)
+
+	switch key := sig.Certificate.PublicKey.(type) {
+	case *rsa.PublicKey:
+		err = rsa.VerifyPKCS1v15(key, hashAlg, digest, value)
+	case *ecdsa.PublicKey:
+		half := len(value) / 2
+		if !ecdsa.Verify(key, digest, new(big.Int).SetBytes(value[:half]), new(big.Int).SetBytes(value[half:])) {
+			err = fmt.Errorf("signature value doesn't match")
+		}
+	default:
+		err = fmt.Errorf("unsupported key type %T", key)
+	}
+	if err != nil {
+		return invalid("%v", err)
+	}
+	return sig
+}
+
+// checkReference checks the digest of a reference to an element of the
+// signature or to a part of the package
+func (p *opcPackage) checkReference(root, ref *xmlNode) error {
+	uri := ref.attr("URI")
+	method := ref.child("DigestMethod")
+	if method == nil {
+		return fmt.Errorf("reference %s has no digest method", uri)
+	}
+	newHash, ok := digestMethods[method.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("unsupported digest method %s", method.attr("Algorithm"))
+	}
+
+	var content []byte
+	var transforms []*xmlNode
+	if t := ref.child("Transforms"); t != nil {
+		transforms = t.elements("Transform")
+	}
+	if strings.HasPrefix(uri, "#") {
+		target := root.find(uri[1:])
+		if target == nil {
+			return fmt.Errorf("reference %s not found", uri)
+		}
+		for _, t := range transforms {
+			if alg := t.attr("Algorithm"); alg != algC14N && alg != algC14NWithComments {
+				return fmt.Errorf("unsupported transform %s", alg)
+			}
+		}
+		content = canonicalize(target)
+	} else {
+		partURI, _, _ := strings.Cut(uri, "?")
+		name, err := url.PathUnescape(strings.TrimPrefix(partURI, "/"))
+		if err != nil {
+			return fmt.Errorf("malformed reference %s", uri)
+		}
+		var found bool
+		if content, found = p.parts[name]; !found {
+			return fmt.Errorf("signed part %s was removed", name)
+		}
+		for _, t := range transforms {
+			switch alg := t.attr("Algorithm"); alg {
+			case algRelationshipTransform:
+				ids, types := make(map[string]bool), make(map[string]bool)
+				for _, c := range t.children {
+					if e, ok := c.(*xmlNode); ok {
+						switch e.local {
+						case "RelationshipReference":
+							ids[e.attr("SourceId")] = true
+						case "RelationshipsGroupReference":
+							types[e.attr("SourceType")] = true
+						}
+					}
+				}
+				content = relationshipTransform(content, ids, types)
+			case algC14N, algC14NWithComments:
+				if n, err := parseXML(content); err == nil {
+					content = canonicalize(n)
+				}
+			default:
+				return fmt.Errorf("unsupported transform %s", alg)
+			}
+		}
+	}
+
+	h := newHash()
+	h.Write(content)
+	want, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(ref.child("DigestValue").text()), ""))
+	if err != nil || subtle.ConstantTimeCompare(h.Sum(nil), want) != 1 {
+		if strings.HasPrefix(uri, "#") {
+			return fmt.Errorf("signature element %s was changed", uri)
+		}
+		partURI, _, _ := strings.Cut(uri, "?")
+		return fmt.Errorf("part %s was changed after signing", strings.TrimPrefix(partURI, "/"))
+	}
+	return nil
+}
+
+// descendant returns the first element below n with a local name
+func descendant(n *xmlNode, local string) *xmlNode {
+	for _, c := range n.children {
+		if e, ok := c.(*xmlNode); ok {
+			if e.local == local {
+				return e
+			}
+			if found := descendant(e, local); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,368 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/digitorus/pkcs7"
+)
+
+const (
+	byteRangePlaceholder = "/ByteRange [0 0000000000 0000000000 0000000000]"
+
+	// Size of a visible signature placed without a Rect, in points
+	defaultSignatureWidth, defaultSignatureHeight = 200.0, 50.0
+)
+
+var (
+	byteRangePattern = regexp.MustCompile(`/ByteRange\s*\[\s*(\d+)\s+(\d+)\s+(\d+)\s+(\d+)\s*\]`)
+	sigFieldPattern  = regexp.MustCompile(`/FT\s*/Sig\b`)
+)
+
+// SignPDF signs a PDF document with a detached PKCS#7 signature, added as
+// an incremental update so earlier signatures stay valid, and returns the
+// signed document. Encrypted documents can't be signed.
+func SignPDF(data []byte, signer *Signer, opts Options) ([]byte, error) {
+	f, err := parsePDF(data)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := f.object(f.root)
+	if err != nil {
+		return nil, err
+	}
+	catalog = readDict(catalog)
+	pagesRef, ok := parseRef(dictEntries(catalog)["/Pages"])
+	if !ok {
+		return nil, fmt.Errorf("%w: no page tree", ErrUnsupportedFormat)
+	}
+	pages, err := f.pages(pagesRef, 0)
+	if err != nil {
+		return nil, err
+	}
+	pageNum := max(opts.Page, 1)
+	if pageNum > len(pages) {
+		return nil, fmt.Errorf("page %d: %w (document has %d pages)", pageNum, ErrIndexOutOfRange, len(pages))
+	}
+	pageRef := pages[pageNum-1]
+	page, err := f.object(pageRef)
+	if err != nil {
+		return nil, err
+	}
+	page = readDict(page)
+
+	// New objects take the numbers after the last one in use
+	next := f.size
+	newRef := func() pdfRef {
+		next++
+		return pdfRef{next - 1, 0}
+	}
+	sigRef, fieldRef := newRef(), newRef()
+	updates := make(map[pdfRef]string)
+
+	signingTime := opts.signingTime()
+	contentsSize := 8192
+	for _, c := range append([]*x509.Certificate{signer.Certificate}, signer.Chain...) {
+		contentsSize += len(c.Raw)
+	}
+	sigDict := "<< /Type /Sig /Filter /Adobe.PPKLite /SubFilter /adbe.pkcs7.detached " + byteRangePlaceholder +
+		" /Contents <" + strings.Repeat("0", 2*contentsSize) + ">" +
+		" /M " + pdfString(pdfDate(signingTime)) + " /Name " + pdfString(signer.Certificate.Subject.CommonName)
+	if opts.Reason != "" {
+		sigDict += " /Reason " + pdfString(opts.Reason)
+	}
+	if opts.Location != "" {
+		sigDict += " /Location " + pdfString(opts.Location)
+	}
+	updates[sigRef] = sigDict + " >>"
+
+	name := "Signature" + strconv.Itoa(len(sigFieldPattern.FindAllIndex(data, -1))+1)
+	rect := [4]float64{}
+	field := "<< /Type /Annot /Subtype /Widget /FT /Sig /T " + pdfString(name) + " /V " + sigRef.String() + " /F 132 /P " + pageRef.String()
+	if opts.Visible {
+		rect = opts.Rect
+		if rect == [4]float64{} {
+			rect = [4]float64{36, 36, 36 + defaultSignatureWidth, 36 + defaultSignatureHeight}
+		}
+		fontRef, apRef := newRef(), newRef()
+		updates[fontRef] = "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"
+		updates[apRef] = signatureAppearance(rect, fontRef, signer, opts, signingTime)
+		field += " /AP << /N " + apRef.String() + " >>"
+	}
+	updates[fieldRef] = field + fmt.Sprintf(" /Rect [%s %s %s %s] >>", pdfNumber(rect[0]), pdfNumber(rect[1]), pdfNumber(rect[2]), pdfNumber(rect[3]))
+
+	// The field goes in the page's annotations and the form's fields
+	if page, err = f.appendToArray(page, "/Annots", fieldRef.String(), updates); err != nil {
+		return nil, err
+	}
+	updates[pageRef] = page
+
+	acroForm, hasForm := dictEntries(catalog)["/AcroForm"]
+	formRef, formIsRef := parseRef(acroForm)
+	switch {
+	case !hasForm:
+		catalog = setEntry(catalog, "/AcroForm", "<< /Fields ["+fieldRef.String()+"] /SigFlags 3 >>")
+	case formIsRef:
+		form, err := f.object(formRef)
+		if err != nil {
+			return nil, err
+		}
+		if form, err = f.appendToArray(readDict(form), "/Fields", fieldRef.String(), updates); err != nil {
+			return nil, err
+		}
+		updates[formRef] = setEntry(form, "/SigFlags", "3")
+	default:
+		form, err := f.appendToArray(readDict(acroForm), "/Fields", fieldRef.String(), updates)
+		if err != nil {
+			return nil, err
+		}
+		catalog = setEntry(catalog, "/AcroForm", setEntry(form, "/SigFlags", "3"))
+	}
+	updates[f.root] = catalog
+
+	out := f.writeUpdate(updates, next)
+
+	// Sign everything but the signature's own contents
+	sigAt := bytes.LastIndex(out, []byte(byteRangePlaceholder))
+	contentsStart := sigAt + bytes.Index(out[sigAt:], []byte("/Contents <")) + len("/Contents ")
+	contentsEnd := contentsStart + 2*contentsSize + 2
+	byteRange := fmt.Sprintf("/ByteRange [0 %d %d %d]", contentsStart, contentsEnd, len(out)-contentsEnd)
+	copy(out[sigAt:], byteRange+strings.Repeat(" ", len(byteRangePlaceholder)-len(byteRange)))
+
+	signed := append(append([]byte(nil), out[:contentsStart]...), out[contentsEnd:]...)
+	sd, err := pkcs7.NewSignedData(signed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	sd.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+	if err := sd.AddSignerChain(signer.Certificate, signer.Key, signer.Chain, pkcs7.SignerInfoConfig{}); err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	sd.Detach()
+	der, err := sd.Finish()
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	if len(der) > contentsSize {
+		return nil, fmt.Errorf("signature of %d bytes doesn't fit the %d reserved", len(der), contentsSize)
+	}
+	hex.Encode(out[contentsStart+1:], der)
+	return out, nil
+}
+
+// writeUpdate appends the updated and new objects to the file, with a
+// cross-reference section and trailer of the same kind as the file's
+func (f *pdfFile) writeUpdate(updates map[pdfRef]string, size int) []byte {
+	var buf bytes.Buffer
+	buf.Write(f.data)
+	if !bytes.HasSuffix(f.data, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+
+	refs := make([]pdfRef, 0, len(updates)+1)
+	for ref := range updates {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].num < refs[j].num })
+	offsets := make(map[int]int, len(refs)+1)
+	for _, ref := range refs {
+		offsets[ref.num] = buf.Len()
+		fmt.Fprintf(&buf, "%d %d obj\n%s\nendobj\n", ref.num, ref.gen, updates[ref])
+	}
+
+	trailer := fmt.Sprintf("/Root %s /Prev %d", f.root, f.prev)
+	if f.info != "" {
+		trailer += " " + f.info
+	}
+	if f.id != "" {
+		trailer += " " + f.id
+	}
+
+	xrefAt := buf.Len()
+	if f.xrefStream {
+		// Files with cross-reference streams are updated with one
+		xref := pdfRef{size, 0}
+		size++
+		refs = append(refs, xref)
+		offsets[xref.num] = xrefAt
+		var index []string
+		var rows bytes.Buffer
+		for _, section := range xrefSections(refs) {
+			index = append(index, strconv.Itoa(section[0].num), strconv.Itoa(len(section)))
+			for _, ref := range section {
+				row := [7]byte{1}
+				binary.BigEndian.PutUint32(row[1:5], uint32(offsets[ref.num]))
+				binary.BigEndian.PutUint16(row[5:], uint16(ref.gen))
+				rows.Write(row[:])
+			}
+		}
+		fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /XRef /Size %d %s /W [1 4 2] /Index [%s] /Length %d >>\nstream\n",
+			xref.num, size, trailer, strings.Join(index, " "), rows.Len())
+		buf.Write(rows.Bytes())
+		buf.WriteString("\nendstream\nendobj\n")
+	} else {
+		buf.WriteString("xref\n")
+		for _, section := range xrefSections(refs) {
+			fmt.Fprintf(&buf, "%d %d\n", section[0].num, len(section))
+			for _, ref := range section {
+				fmt.Fprintf(&buf, "%010d %05d n\r\n", offsets[ref.num], ref.gen)
+			}
+		}
+		fmt.Fprintf(&buf, "trailer\n<< /Size %d %s >>\n", size, trailer)
+	}
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", xrefAt)
+	return buf.Bytes()
+}
+
+// xrefSections splits sorted references into runs of consecutive numbers
+func xrefSections(refs []pdfRef) [][]pdfRef {
+	var sections [][]pdfRef
+	for i, ref := range refs {
+		if i == 0 || ref.num != refs[i-1].num+1 {
+			sections = append(sections, nil)
+		}
+		sections[len(sections)-1] = append(sections[len(sections)-1], ref)
+	}
+	return sections
+}
+
+// signatureAppearance returns the form drawn for a visible signature: a
+// frame around the signer's name, the date and the reason
+func signatureAppearance(rect [4]float64, font pdfRef, signer *Signer, opts Options, at time.Time) string {
+	width, height := math.Abs(rect[2]-rect[0]), math.Abs(rect[3]-rect[1])
+	lines := []string{"Digitally signed by " + signer.Certificate.Subject.CommonName, "Date: " + at.Format("2006-01-02 15:04:05 MST")}
+	if opts.Reason != "" {
+		lines = append(lines, "Reason: "+opts.Reason)
+	}
+	if opts.Location != "" {
+		lines = append(lines, "Location: "+opts.Location)
+	}
+	size := math.Min(10, (height-6)/float64(len(lines))/1.2)
+	size = math.Max(size, 1)
+
+	var content strings.Builder
+	fmt.Fprintf(&content, "q 0.5 w 0 0 0 RG 0.25 0.25 %s %s re S Q\n", pdfNumber(width-0.5), pdfNumber(height-0.5))
+	fmt.Fprintf(&content, "BT /F1 %s Tf 0 0 0 rg %s TL 4 %s Td\n", pdfNumber(size), pdfNumber(size*1.2), pdfNumber(height-3-size))
+	for i, line := range lines {
+		if i > 0 {
+			content.WriteString("T* ")
+		}
+		content.WriteString(pdfString(line) + " Tj\n")
+	}
+	content.WriteString("ET")
+
+	return fmt.Sprintf("<< /Type /XObject /Subtype /Form /BBox [0 0 %s %s] /Resources << /Font << /F1 %s >> >> /Length %d >>\nstream\n%s\nendstream",
+		pdfNumber(width), pdfNumber(height), font, content.Len(), content.String())
+}
+
+// pdfNumber formats a number as PDF expects, without exponents
+func pdfNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// pdfDate formats a time as a PDF date string
+func pdfDate(t time.Time) string {
+	return "D:" + t.UTC().Format("20060102150405") + "+00'00'"
+}
+
+// parsePDFDate reads a PDF date string, with or without its time zone
+func parsePDFDate(s string) time.Time {
+	s = strings.TrimPrefix(s, "D:")
+	s = strings.ReplaceAll(strings.TrimSuffix(s, "'"), "'", "")
+	for _, layout := range []string{"20060102150405-0700", "20060102150405Z", "20060102150405"} {
+		if t, err := time.Parse(layout, strings.Replace(s, "Z00", "Z", 1)); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// VerifyPDF returns the signatures of a PDF document, each checked against
+// the bytes it covers
+func VerifyPDF(data []byte) ([]Signature, error) {
+	var signatures []Signature
+	for _, m := range byteRangePattern.FindAllSubmatchIndex(data, -1) {
+		var r [4]int
+		for i := range r {
+			r[i], _ = strconv.Atoi(string(data[m[2+2*i]:m[3+2*i]]))
+		}
+		if r[0] != 0 && r[1] == 0 {
+			continue // Placeholder of an unfinished signature
+		}
+		signatures = append(signatures, verifyPDFSignature(data, m[0], r))
+	}
+	if len(signatures) == 0 {
+		return nil, ErrNotSigned
+	}
+	return signatures, nil
+}
+
+// verifyPDFSignature checks the signature whose byte range is at a position of the file
+func verifyPDFSignature(data []byte, at int, r [4]int) Signature {
+	var sig Signature
+	invalid := func(format string, args ...any) Signature {
+		sig.Err = fmt.Errorf("%w: %s", ErrInvalidSignature, fmt.Sprintf(format, args...))
+		return sig
+	}
+
+	// The signature dictionary holds the byte range
+	dictStart := bytes.LastIndex(data[:at], []byte("obj"))
+	dictEnd := bytes.Index(data[at:], []byte("endobj"))
+	if dictStart >= 0 && dictEnd >= 0 {
+		entries := dictEntries(readDict(string(data[dictStart : at+dictEnd])))
+		sig.Reason = unescapePDFString(entries["/Reason"])
+		sig.Location = unescapePDFString(entries["/Location"])
+		sig.Time = parsePDFDate(unescapePDFString(entries["/M"]))
+	}
+
+	if r[0] != 0 || r[1] < 0 || r[2] < r[1] || r[3] < 0 || r[2]+r[3] > len(data) {
+		return invalid("byte range outside the file")
+	}
+	sig.Partial = r[2]+r[3] < len(data)
+
+	contents := strings.TrimSpace(string(data[r[1]:r[2]]))
+	der, err := hex.DecodeString(strings.TrimSuffix(strings.TrimPrefix(contents, "<"), ">"))
+	if err != nil {
+		return invalid("malformed signature contents")
+	}
+	var outer asn1.RawValue
+	if _, err := asn1.Unmarshal(der, &outer); err == nil {
+		der = outer.FullBytes // Drop the padding after the signature
+	} else {
+		der = bytes.TrimRight(der, "\x00")
+	}
+	p7, err := pkcs7.Parse(der)
+	if err != nil {
+		return invalid("malformed signature: %v", err)
+	}
+	p7.Content = append(append([]byte(nil), data[:r[1]]...), data[r[2]:r[2]+r[3]]...)
+
+	if cert := p7.GetOnlySigner(); cert != nil {
+		sig.Certificate = cert
+		sig.Signer = cert.Subject.CommonName
+	}
+	var signingTime time.Time
+	if err := p7.UnmarshalSignedAttribute(pkcs7.OIDAttributeSigningTime, &signingTime); err == nil {
+		sig.Time = signingTime
+	}
+	if err := p7.Verify(); err != nil {
+		if sig.Certificate == nil {
+			return invalid("%v", err)
+		}
+		return invalid("document was changed after signing: %v", err)
+	}
+	if sig.Certificate != nil {
+		sig.Trusted = trusted(sig.Certificate, p7.Certificates, sig.Time)
+	}
+	return sig
+}
@@ -0,0 +1,461 @@
+package signature
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pdfRef is a reference to an indirect object of a PDF file
+type pdfRef struct {
+	num, gen int
+}
+
+func (r pdfRef) String() string {
+	return strconv.Itoa(r.num) + " " + strconv.Itoa(r.gen) + " R"
+}
+
+// pdfFile is what an incremental update needs to know of a PDF file
+type pdfFile struct {
+	data       []byte
+	root       pdfRef
+	size       int    // Number of object numbers in use, the trailer's /Size
+	prev       int    // Offset of the last cross-reference section
+	xrefStream bool   // Whether the last section is a cross-reference stream
+	info, id   string // /Info and /ID entries of the trailer, carried over as written
+
+	objStreams map[int]string // Objects packed in object streams, read on first use
+}
+
+var (
+	startXrefPattern = regexp.MustCompile(`startxref\s+(\d+)`)
+	refPattern       = regexp.MustCompile(`^(\d+)\s+(\d+)\s+R`)
+	objStmPattern    = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+)
+
+// parsePDF reads the trailer of a PDF file
+func parsePDF(data []byte) (*pdfFile, error) {
+	matches := startXrefPattern.FindAllSubmatch(data, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: no cross-reference table", ErrUnsupportedFormat)
+	}
+	prev, _ := strconv.Atoi(string(matches[len(matches)-1][1]))
+	if prev <= 0 || prev >= len(data) {
+		return nil, fmt.Errorf("%w: bad cross-reference offset", ErrUnsupportedFormat)
+	}
+
+	f := &pdfFile{data: data, prev: prev}
+	var trailer string
+	if bytes.HasPrefix(bytes.TrimLeft(data[prev:], " \r\n\t"), []byte("xref")) {
+		at := bytes.Index(data[prev:], []byte("trailer"))
+		if at < 0 {
+			return nil, fmt.Errorf("%w: no trailer", ErrUnsupportedFormat)
+		}
+		trailer = readDict(string(data[prev+at:]))
+	} else {
+		f.xrefStream = true
+		trailer = readDict(string(data[prev:]))
+	}
+	if trailer == "" {
+		return nil, fmt.Errorf("%w: no trailer", ErrUnsupportedFormat)
+	}
+
+	entries := dictEntries(trailer)
+	if _, ok := entries["/Encrypt"]; ok {
+		return nil, fmt.Errorf("%w: encrypted PDF documents can't be signed", ErrUnsupportedFormat)
+	}
+	root, ok := parseRef(entries["/Root"])
+	if !ok {
+		return nil, fmt.Errorf("%w: no document catalog", ErrUnsupportedFormat)
+	}
+	f.root = root
+	f.size, _ = strconv.Atoi(entries["/Size"])
+	if f.size <= 0 {
+		return nil, fmt.Errorf("%w: no trailer /Size", ErrUnsupportedFormat)
+	}
+	if v, ok := entries["/Info"]; ok {
+		f.info = "/Info " + v
+	}
+	if v, ok := entries["/ID"]; ok {
+		f.id = "/ID " + v
+	}
+	return f, nil
+}
+
+func parseRef(value string) (pdfRef, bool) {
+	m := refPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return pdfRef{}, false
+	}
+	num, _ := strconv.Atoi(m[1])
+	gen, _ := strconv.Atoi(m[2])
+	return pdfRef{num, gen}, true
+}
+
+// object returns the content of an indirect object, the newest version of
+// it when the file was updated
+func (f *pdfFile) object(ref pdfRef) (string, error) {
+	pattern := regexp.MustCompile(`(?:^|[^0-9])` + strconv.Itoa(ref.num) + `\s+` + strconv.Itoa(ref.gen) + `\s+obj\b`)
+	if locs := pattern.FindAllIndex(f.data, -1); len(locs) > 0 {
+		start := locs[len(locs)-1][1]
+		end := bytes.Index(f.data[start:], []byte("endobj"))
+		if end < 0 {
+			return "", fmt.Errorf("%w: object %d is not terminated", ErrUnsupportedFormat, ref.num)
+		}
+		return strings.TrimSpace(string(f.data[start : start+end])), nil
+	}
+
+	if f.objStreams == nil {
+		f.objStreams = f.readObjectStreams()
+	}
+	if obj, ok := f.objStreams[ref.num]; ok {
+		return obj, nil
+	}
+	return "", fmt.Errorf("%w: object %d not found", ErrUnsupportedFormat, ref.num)
+}
+
+// readObjectStreams returns the objects packed in the compressed object
+// streams of the file, by object number
+func (f *pdfFile) readObjectStreams() map[int]string {
+	objects := make(map[int]string)
+	for _, loc := range objStmPattern.FindAllIndex(f.data, -1) {
+		dictStart := bytes.LastIndex(f.data[:loc[0]], []byte("obj"))
+		if dictStart < 0 {
+			continue
+		}
+		dict := readDict(string(f.data[dictStart:]))
+		entries := dictEntries(dict)
+		streamAt := bytes.Index(f.data[dictStart:], []byte("stream"))
+		if streamAt < 0 {
+			continue
+		}
+		start := dictStart + streamAt + len("stream")
+		if start < len(f.data) && f.data[start] == '\r' {
+			start++
+		}
+		if start < len(f.data) && f.data[start] == '\n' {
+			start++
+		}
+		length, err := strconv.Atoi(entries["/Length"])
+		if err != nil || start+length > len(f.data) {
+			continue
+		}
+		content := f.data[start : start+length]
+		if strings.Contains(entries["/Filter"], "/FlateDecode") {
+			r, err := zlib.NewReader(bytes.NewReader(content))
+			if err != nil {
+				continue
+			}
+			content, err = io.ReadAll(r)
+			if err != nil {
+				continue
+			}
+		}
+
+		n, _ := strconv.Atoi(entries["/N"])
+		first, _ := strconv.Atoi(entries["/First"])
+		if first > len(content) {
+			continue
+		}
+		header := strings.Fields(string(content[:first]))
+		for i := 0; i+1 < len(header) && i/2 < n; i += 2 {
+			num, _ := strconv.Atoi(header[i])
+			offset, _ := strconv.Atoi(header[i+1])
+			end := len(content)
+			if i+3 < len(header) {
+				if next, err := strconv.Atoi(header[i+3]); err == nil {
+					end = first + next
+				}
+			}
+			if first+offset <= end && end <= len(content) {
+				objects[num] = strings.TrimSpace(string(content[first+offset : end]))
+			}
+		}
+	}
+	return objects
+}
+
+// readDict returns the dictionary starting at the first "<<" of s
+func readDict(s string) string {
+	start := strings.Index(s, "<<")
+	if start < 0 {
+		return ""
+	}
+	end := skipValue(s, start)
+	if end <= start {
+		return ""
+	}
+	return s[start:end]
+}
+
+// dictEntries returns the values of a dictionary by key, as written
+func dictEntries(dict string) map[string]string {
+	entries := make(map[string]string)
+	forEachEntry(dict, func(key string, start, end int) {
+		entries[key] = strings.TrimSpace(dict[start:end])
+	})
+	return entries
+}
+
+// forEachEntry calls fn for each key of a dictionary, with where its value starts and ends
+func forEachEntry(dict string, fn func(key string, start, end int)) {
+	if !strings.HasPrefix(dict, "<<") {
+		return
+	}
+	i := 2
+	for {
+		i = skipSpace(dict, i)
+		if i >= len(dict) || strings.HasPrefix(dict[i:], ">>") || dict[i] != '/' {
+			return
+		}
+		keyEnd := skipValue(dict, i)
+		key := dict[i:keyEnd]
+		start := skipSpace(dict, keyEnd)
+		end := skipValue(dict, start)
+		if end <= start {
+			return
+		}
+		fn(key, start, end)
+		i = end
+	}
+}
+
+// skipSpace returns the position of the first character at or after i
+// that isn't white space or part of a comment
+func skipSpace(s string, i int) int {
+	for i < len(s) {
+		switch s[i] {
+		case ' ', '\t', '\r', '\n', '\f', 0:
+			i++
+		case '%':
+			for i < len(s) && s[i] != '\n' && s[i] != '\r' {
+				i++
+			}
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// isDelimiter reports whether a character ends names, numbers and keywords
+func isDelimiter(c byte) bool {
+	return strings.IndexByte(" \t\r\n\f\x00()<>[]{}/%", c) >= 0
+}
+
+// skipValue returns the position just after the object starting at i:
+// a dictionary, array, string, name, number, reference or keyword
+func skipValue(s string, i int) int {
+	i = skipSpace(s, i)
+	if i >= len(s) {
+		return i
+	}
+	switch {
+	case strings.HasPrefix(s[i:], "<<"):
+		i += 2
+		for {
+			i = skipSpace(s, i)
+			if i >= len(s) {
+				return i
+			}
+			if strings.HasPrefix(s[i:], ">>") {
+				return i + 2
+			}
+			i = skipValue(s, i)
+		}
+	case s[i] == '<':
+		end := strings.IndexByte(s[i:], '>')
+		if end < 0 {
+			return len(s)
+		}
+		return i + end + 1
+	case s[i] == '[':
+		i++
+		for {
+			i = skipSpace(s, i)
+			if i >= len(s) {
+				return i
+			}
+			if s[i] == ']' {
+				return i + 1
+			}
+			i = skipValue(s, i)
+		}
+	case s[i] == '(':
+		depth := 0
+		for ; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+		}
+		return i
+	case s[i] == '/':
+		i++
+		for i < len(s) && !isDelimiter(s[i]) {
+			i++
+		}
+		return i
+	case s[i] == ']' || s[i] == '>' || s[i] == ')' || s[i] == '{' || s[i] == '}':
+		return i + 1 // Stray delimiter; step over it
+	default:
+		start := i
+		for i < len(s) && !isDelimiter(s[i]) {
+			i++
+		}
+		// An object number and generation followed by R is a single reference
+		if m := refPattern.FindStringIndex(s[start:]); m != nil && m[1] > i-start {
+			return start + m[1]
+		}
+		return i
+	}
+}
+
+// setEntry sets a key of a dictionary to a value, adding it when missing
+func setEntry(dict, key, value string) string {
+	replaced := ""
+	forEachEntry(dict, func(k string, start, end int) {
+		if k == key && replaced == "" {
+			replaced = dict[:start] + value + dict[end:]
+		}
+	})
+	if replaced != "" {
+		return replaced
+	}
+	end := strings.LastIndex(dict, ">>")
+	return strings.TrimRight(dict[:end], " \r\n\t") + " " + key + " " + value + " >>"
+}
+
+// appendToArray appends an item to the array a dictionary holds under a
+// key, creating it when missing. Arrays held in objects of their own are
+// rewritten into updates.
+func (f *pdfFile) appendToArray(dict, key, item string, updates map[pdfRef]string) (string, error) {
+	value, ok := dictEntries(dict)[key]
+	if !ok {
+		return setEntry(dict, key, "["+item+"]"), nil
+	}
+	if ref, isRef := parseRef(value); isRef {
+		array, found := updates[ref]
+		if !found {
+			var err error
+			if array, err = f.object(ref); err != nil {
+				return "", err
+			}
+		}
+		if !strings.HasPrefix(array, "[") {
+			return "", fmt.Errorf("%w: %s is not an array", ErrUnsupportedFormat, key)
+		}
+		updates[ref] = strings.TrimSuffix(strings.TrimSpace(array), "]") + " " + item + "]"
+		return dict, nil
+	}
+	if !strings.HasPrefix(value, "[") {
+		return "", fmt.Errorf("%w: %s is not an array", ErrUnsupportedFormat, key)
+	}
+	return setEntry(dict, key, strings.TrimSuffix(value, "]")+" "+item+"]"), nil
+}
+
+// pages returns the page objects of the page tree below a node, in order
+func (f *pdfFile) pages(node pdfRef, depth int) ([]pdfRef, error) {
+	if depth > 32 {
+		return nil, fmt.Errorf("%w: page tree too deep", ErrUnsupportedFormat)
+	}
+	obj, err := f.object(node)
+	if err != nil {
+		return nil, err
+	}
+	entries := dictEntries(readDict(obj))
+	if entries["/Type"] != "/Pages" {
+		return []pdfRef{node}, nil
+	}
+	kids := entries["/Kids"]
+	if ref, ok := parseRef(kids); ok {
+		if kids, err = f.object(ref); err != nil {
+			return nil, err
+		}
+	}
+	var pages []pdfRef
+	for i := skipSpace(kids, 1); i < len(kids) && kids[i] != ']'; i = skipSpace(kids, i) {
+		end := skipValue(kids, i)
+		if kid, ok := parseRef(kids[i:end]); ok {
+			found, err := f.pages(kid, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			pages = append(pages, found...)
+		}
+		if end <= i {
+			break
+		}
+		i = end
+	}
+	return pages, nil
+}
+
+// pdfString returns text as a PDF literal string, with characters outside
+// Latin-1 replaced by question marks
+func pdfString(text string) string {
+	var sb strings.Builder
+	sb.WriteByte('(')
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r < 32 || r > 255:
+			sb.WriteByte('?')
+		case r < 128:
+			sb.WriteRune(r)
+		default:
+			sb.WriteString(`\` + strconv.FormatInt(int64(r), 8))
+		}
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
+
+// unescapePDFString returns the text of a PDF literal string
+func unescapePDFString(s string) string {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "("), ")")
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch c := s[i]; {
+		case c == 'n':
+			sb.WriteByte('\n')
+		case c == 'r':
+			sb.WriteByte('\r')
+		case c == 't':
+			sb.WriteByte('\t')
+		case c >= '0' && c <= '7':
+			end := i
+			for end < len(s) && end < i+3 && s[end] >= '0' && s[end] <= '7' {
+				end++
+			}
+			n, _ := strconv.ParseInt(s[i:end], 8, 32)
+			sb.WriteRune(rune(n))
+			i = end - 1
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
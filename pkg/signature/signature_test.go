@@ -0,0 +1,286 @@
+package signature
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// testSigner returns a signer with a self-signed certificate
+func testSigner(t *testing.T, key crypto.Signer) *Signer {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Jane Signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate failed: %v", err)
+	}
+	return &Signer{Certificate: cert, Key: key}
+}
+
+func rsaSigner(t *testing.T) *Signer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	return testSigner(t, key)
+}
+
+func testDOCX(t *testing.T) []byte {
+	t.Helper()
+	doc := docx.New()
+	doc.AddParagraph("This agreement is binding.")
+	data, err := doc.ToBytes()
+	if err != nil {
+		t.Fatalf("ToBytes failed: %v", err)
+	}
+	return data
+}
+
+// replacePart returns a DOCX archive with one part's content changed
+func replacePart(t *testing.T, data []byte, name string, fn func([]byte) []byte) []byte {
+	t.Helper()
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, f := range r.File {
+		rc, _ := f.Open()
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		if f.Name == name {
+			content = fn(content)
+		}
+		out, _ := w.Create(f.Name)
+		out.Write(content)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestLoadPKCS12(t *testing.T) {
+	signer := rsaSigner(t)
+	p12, err := pkcs12.Modern.Encode(signer.Key, signer.Certificate, nil, "secret")
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	loaded, err := LoadPKCS12(p12, "secret")
+	if err != nil {
+		t.Fatalf("LoadPKCS12 failed: %v", err)
+	}
+	if loaded.Certificate.Subject.CommonName != "Jane Signer" || loaded.Key == nil {
+		t.Errorf("Unexpected signer: %+v", loaded)
+	}
+	if _, err := LoadPKCS12(p12, "wrong"); err == nil {
+		t.Error("Expected an error for a wrong password")
+	}
+}
+
+func TestSignDOCX(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	signers := map[string]*Signer{"rsa": rsaSigner(t), "ecdsa": testSigner(t, ecKey)}
+
+	for name, signer := range signers {
+		t.Run(name, func(t *testing.T) {
+			data := testDOCX(t)
+			if _, err := VerifyDOCX(data); !errors.Is(err, ErrNotSigned) {
+				t.Fatalf("Expected ErrNotSigned, got %v", err)
+			}
+
+			at := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+			signed, err := Sign(data, signer, Options{Reason: "Approved & final", Time: at})
+			if err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+			sigs, err := Verify(signed)
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if len(sigs) != 1 || !sigs[0].Valid() {
+				t.Fatalf("Expected one valid signature, got %+v", sigs)
+			}
+			if s := sigs[0]; s.Signer != "Jane Signer" || s.Reason != "Approved & final" || !s.Time.Equal(at) || s.Trusted {
+				t.Errorf("Unexpected signature details: %+v", s)
+			}
+
+			// The signed document still opens
+			doc, err := docx.ReadBytes(signed)
+			if err != nil {
+				t.Fatalf("ReadBytes failed: %v", err)
+			}
+			if !strings.Contains(doc.GetText(), "binding") {
+				t.Errorf("Expected the text to survive signing, got %q", doc.GetText())
+			}
+
+			// A second signature leaves the first valid
+			twice, err := SignDOCX(signed, signer, Options{})
+			if err != nil {
+				t.Fatalf("SignDOCX failed: %v", err)
+			}
+			sigs, err = VerifyDOCX(twice)
+			if err != nil || len(sigs) != 2 || !sigs[0].Valid() || !sigs[1].Valid() {
+				t.Fatalf("Expected two valid signatures, got %+v, %v", sigs, err)
+			}
+
+			tampered := replacePart(t, signed, "word/document.xml", func(b []byte) []byte {
+				return bytes.Replace(b, []byte("binding"), []byte("void"), 1)
+			})
+			sigs, err = VerifyDOCX(tampered)
+			if err != nil || len(sigs) != 1 {
+				t.Fatalf("Expected one signature, got %+v, %v", sigs, err)
+			}
+			if !errors.Is(sigs[0].Err, ErrInvalidSignature) || !strings.Contains(sigs[0].Err.Error(), "word/document.xml") {
+				t.Errorf("Expected the changed part to be reported, got %v", sigs[0].Err)
+			}
+		})
+	}
+}
+
+func TestSignDOCXRelationships(t *testing.T) {
+	signed, err := SignDOCX(testDOCX(t), rsaSigner(t), Options{})
+	if err != nil {
+		t.Fatalf("SignDOCX failed: %v", err)
+	}
+
+	// Relationships are signed one by one: retargeting one breaks the signature
+	tampered := replacePart(t, signed, "_rels/.rels", func(b []byte) []byte {
+		return bytes.Replace(b, []byte(`Target="word/document.xml"`), []byte(`Target="word/other.xml"`), 1)
+	})
+	sigs, err := VerifyDOCX(tampered)
+	if err != nil || len(sigs) != 1 || sigs[0].Valid() {
+		t.Errorf("Expected the changed relationship to invalidate the signature, got %+v, %v", sigs, err)
+	}
+
+	// Reformatting a relationships part doesn't
+	reformatted := replacePart(t, signed, "_rels/.rels", func(b []byte) []byte {
+		return bytes.ReplaceAll(b, []byte("><"), []byte(">\n  <"))
+	})
+	sigs, err = VerifyDOCX(reformatted)
+	if err != nil || len(sigs) != 1 || !sigs[0].Valid() {
+		t.Errorf("Expected the signature to survive reformatting, got %+v, %v", sigs, err)
+	}
+}
+
+func testPDF(t *testing.T, pages int) []byte {
+	t.Helper()
+	doc := pdf.New()
+	for i := 0; i < pages; i++ {
+		doc.AddPage().AddText("Invoice total: 100 EUR", 72, 72, 12)
+	}
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+	return data
+}
+
+func TestSignPDF(t *testing.T) {
+	signer := rsaSigner(t)
+	data := testPDF(t, 2)
+	if _, err := VerifyPDF(data); !errors.Is(err, ErrNotSigned) {
+		t.Fatalf("Expected ErrNotSigned, got %v", err)
+	}
+
+	signed, err := Sign(data, signer, Options{Reason: "Approved", Location: "Madrid", Visible: true, Page: 2})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if !bytes.HasPrefix(signed, data) {
+		t.Error("Expected the signature to be appended as an incremental update")
+	}
+	sigs, err := Verify(signed)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(sigs) != 1 || !sigs[0].Valid() || sigs[0].Partial {
+		t.Fatalf("Expected one valid signature of the whole file, got %+v", sigs)
+	}
+	if s := sigs[0]; s.Signer != "Jane Signer" || s.Reason != "Approved" || s.Location != "Madrid" || s.Time.IsZero() {
+		t.Errorf("Unexpected signature details: %+v", s)
+	}
+
+	// The signed document still reads
+	doc, err := pdf.ReadBytes(signed)
+	if err != nil {
+		t.Fatalf("ReadBytes failed: %v", err)
+	}
+	if doc.GetPageCount() != 2 || !strings.Contains(doc.GetAllText(), "Invoice total") {
+		t.Errorf("Expected 2 pages with their text, got %d: %q", doc.GetPageCount(), doc.GetAllText())
+	}
+
+	// A second signature leaves the first valid, covering part of the file
+	twice, err := SignPDF(signed, signer, Options{})
+	if err != nil {
+		t.Fatalf("SignPDF failed: %v", err)
+	}
+	sigs, err = VerifyPDF(twice)
+	if err != nil || len(sigs) != 2 {
+		t.Fatalf("Expected two signatures, got %+v, %v", sigs, err)
+	}
+	if !sigs[0].Valid() || !sigs[0].Partial || !sigs[1].Valid() || sigs[1].Partial {
+		t.Errorf("Unexpected signatures: %+v", sigs)
+	}
+
+	tampered := bytes.Replace(signed, []byte("100 EUR"), []byte("900 EUR"), 1)
+	if bytes.Equal(tampered, signed) {
+		tampered = append([]byte(nil), signed...)
+		tampered[20] ^= 1 // The text is compressed; change another signed byte
+	}
+	sigs, err = VerifyPDF(tampered)
+	if err != nil || len(sigs) != 1 || !errors.Is(sigs[0].Err, ErrInvalidSignature) {
+		t.Errorf("Expected the change to invalidate the signature, got %+v, %v", sigs, err)
+	}
+
+	if _, err := SignPDF(data, signer, Options{Visible: true, Page: 3}); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange for a missing page, got %v", err)
+	}
+}
+
+func TestPDFDictionaries(t *testing.T) {
+	dict := readDict(`1 0 obj << /Type /Page /Annots [1 0 R] /Resources << /Font << /F1 5 0 R >> >> /T (a \) b) /Contents <41>>> endobj`)
+	entries := dictEntries(dict)
+	if entries["/Contents"] != "<41>" || entries["/Annots"] != "[1 0 R]" || entries["/T"] != `(a \) b)` {
+		t.Errorf("Unexpected entries: %q", entries)
+	}
+	if got := setEntry(dict, "/Annots", "[1 0 R 2 0 R]"); !strings.Contains(got, "/Annots [1 0 R 2 0 R] /Resources") {
+		t.Errorf("Expected the value to be replaced, got %s", got)
+	}
+	if got := setEntry("<< /Type /Catalog >>", "/AcroForm", "<< >>"); got != "<< /Type /Catalog /AcroForm << >> >>" {
+		t.Errorf("Expected the entry to be added, got %s", got)
+	}
+	if got := unescapePDFString(pdfString("Café (draft)")); got != "Café (draft)" {
+		t.Errorf("Expected the string to round trip, got %q", got)
+	}
+}
@@ -0,0 +1,145 @@
+// Package signature signs DOCX and PDF documents with a certificate and
+// verifies the signatures they carry. DOCX documents get XML signatures laid
+// out as the Open Packaging Conventions define them, the kind Word adds;
+// PDF documents get detached PKCS#7 signatures added as an incremental
+// update, the kind Acrobat adds.
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+var (
+	// ErrNotSigned is returned by Verify for documents without signatures
+	ErrNotSigned = errors.New("document is not signed")
+
+	// ErrInvalidSignature is wrapped by the Err of signatures whose signed
+	// content changed or whose signature value doesn't match
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrIndexOutOfRange is returned for pages outside the document
+	ErrIndexOutOfRange = docx.ErrIndexOutOfRange
+
+	// ErrUnsupportedFormat is returned for documents that are neither DOCX nor PDF
+	ErrUnsupportedFormat = docx.ErrUnsupportedFormat
+)
+
+// Signer holds the certificate and private key documents are signed with
+type Signer struct {
+	Certificate *x509.Certificate
+	Key         crypto.Signer       // RSA or ECDSA key of the certificate
+	Chain       []*x509.Certificate // Intermediate certificates, included in PDF signatures
+}
+
+// LoadPKCS12 reads a signer from a PKCS#12 (.p12 or .pfx) file's content
+func LoadPKCS12(data []byte, password string) (*Signer, error) {
+	key, cert, chain, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return &Signer{Certificate: cert, Key: signer, Chain: chain}, nil
+}
+
+// Options holds the details recorded with a signature
+type Options struct {
+	Reason   string    // Why the document is signed, e.g. "Approved"
+	Location string    // Where it was signed (PDF only)
+	Time     time.Time // Signing time; now when zero
+
+	// Visible draws the signature on a page of PDF documents, with the
+	// signer's name, the date and the reason; otherwise it is only listed
+	// in the reader's signature panel
+	Visible bool
+	Page    int        // Page of a visible signature, from 1; 1 when 0
+	Rect    [4]float64 // Box of a visible signature: left, bottom, right and top in points from the bottom left corner; 200 by 50 points at the bottom left when zero
+}
+
+// Signature is a signature found in a document
+type Signature struct {
+	Signer      string // Common name of the certificate's subject
+	Certificate *x509.Certificate
+	Time        time.Time // Signing time the signature records
+	Reason      string
+	Location    string
+
+	// Trusted reports whether the certificate chains to a root the system
+	// trusts; self-signed certificates aren't
+	Trusted bool
+
+	// Partial is set on PDF signatures that don't cover the whole file,
+	// because the document was updated after signing, e.g. by adding
+	// another signature
+	Partial bool
+
+	// Err tells why the signature is invalid, wrapping
+	// ErrInvalidSignature; nil when the signed content is unchanged
+	Err error
+}
+
+// Valid reports whether the signed content is unchanged since signing
+func (s Signature) Valid() bool {
+	return s.Err == nil
+}
+
+// Sign signs a DOCX or PDF document, telling them apart by their content,
+// and returns the signed document
+func Sign(data []byte, signer *Signer, opts Options) ([]byte, error) {
+	switch {
+	case isPDF(data):
+		return SignPDF(data, signer, opts)
+	case isZip(data):
+		return SignDOCX(data, signer, opts)
+	default:
+		return nil, fmt.Errorf("%w: expected a DOCX or PDF document", ErrUnsupportedFormat)
+	}
+}
+
+// Verify returns the signatures of a DOCX or PDF document, or ErrNotSigned
+// when it has none. Check each signature's Err to know whether it is valid.
+func Verify(data []byte) ([]Signature, error) {
+	switch {
+	case isPDF(data):
+		return VerifyPDF(data)
+	case isZip(data):
+		return VerifyDOCX(data)
+	default:
+		return nil, fmt.Errorf("%w: expected a DOCX or PDF document", ErrUnsupportedFormat)
+	}
+}
+
+func isPDF(data []byte) bool { return bytes.HasPrefix(data, []byte("%PDF")) }
+func isZip(data []byte) bool { return bytes.HasPrefix(data, []byte("PK\x03\x04")) }
+
+// signingTime returns the time a signature records
+func (o Options) signingTime() time.Time {
+	if o.Time.IsZero() {
+		return time.Now().UTC().Truncate(time.Second)
+	}
+	return o.Time.UTC().Truncate(time.Second)
+}
+
+// trusted reports whether a certificate chains to a root the system trusts
+func trusted(cert *x509.Certificate, intermediates []*x509.Certificate, at time.Time) bool {
+	pool := x509.NewCertPool()
+	for _, c := range intermediates {
+		pool.AddCert(c)
+	}
+	_, err := cert.Verify(x509.VerifyOptions{
+		Intermediates: pool,
+		CurrentTime:   at,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err == nil
+}
@@ -0,0 +1,299 @@
+// Package snippets keeps a library of named fragments, such as a signature
+// block or a standard clause, cut from documents and inserted into others.
+// A library is a single zip file holding each snippet as a DOCX document
+// along with an index, so fragments carry their styles, lists and images
+// with them.
+package snippets
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+var (
+	// ErrNotFound is returned for names the library has no snippet for
+	ErrNotFound = errors.New("snippet not found")
+
+	// ErrInvalidName is returned for snippet names other than letters,
+	// digits, dots, dashes and underscores
+	ErrInvalidName = errors.New("invalid snippet name")
+)
+
+// indexName is the library entry listing the snippets
+const indexName = "snippets.json"
+
+// previewLength is the number of characters of text kept as a preview
+const previewLength = 60
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Kind tells what a snippet holds
+type Kind string
+
+const (
+	KindParagraphs Kind = "paragraphs" // A range of paragraphs, with the tables between them
+	KindTable      Kind = "table"      // A single table
+)
+
+// Info describes a snippet of a library
+type Info struct {
+	Name        string    `json:"name"`
+	Kind        Kind      `json:"kind"`
+	Description string    `json:"description,omitempty"`
+	Preview     string    `json:"preview,omitempty"` // Start of the snippet's text
+	Saved       time.Time `json:"saved"`
+}
+
+// Library is a set of named snippets
+type Library struct {
+	infos map[string]Info
+	data  map[string][]byte // DOCX content of each snippet
+}
+
+// New returns an empty library
+func New() *Library {
+	return &Library{infos: make(map[string]Info), data: make(map[string][]byte)}
+}
+
+// Open reads a library file. A file that doesn't exist yet gives an empty
+// library, which Save creates.
+func Open(path string) (*Library, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snippet library: %w", err)
+	}
+	return ReadBytes(data)
+}
+
+// ReadBytes reads a library held in memory
+func ReadBytes(data []byte) (*Library, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippet library: %w", err)
+	}
+
+	l := New()
+	var infos []Info
+	entries := make(map[string]*zip.File)
+	for _, f := range r.File {
+		entries[f.Name] = f
+	}
+	index, ok := entries[indexName]
+	if !ok {
+		return nil, fmt.Errorf("failed to read snippet library: %s is missing", indexName)
+	}
+	content, err := readEntry(index)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &infos); err != nil {
+		return nil, fmt.Errorf("failed to read snippet library index: %w", err)
+	}
+
+	for _, info := range infos {
+		f, ok := entries[entryName(info.Name)]
+		if !ok {
+			return nil, fmt.Errorf("failed to read snippet library: %s is missing", entryName(info.Name))
+		}
+		content, err := readEntry(f)
+		if err != nil {
+			return nil, err
+		}
+		l.infos[info.Name] = info
+		l.data[info.Name] = content
+	}
+	return l, nil
+}
+
+func readEntry(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+	}
+	return data, nil
+}
+
+// entryName returns the name of the library entry holding a snippet
+func entryName(name string) string {
+	return "snippets/" + name + ".docx"
+}
+
+// Save writes the library to a file. It is written to a temporary file in
+// the same directory first, so a failed save leaves the previous library
+// intact.
+func (l *Library) Save(path string) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := l.WriteTo(tmpFile); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return fmt.Errorf("failed to move file into place: %w", err)
+	}
+	return nil
+}
+
+// WriteTo writes the library as a zip archive to w
+func (l *Library) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	zw := zip.NewWriter(cw)
+
+	infos := l.List()
+	index, err := json.MarshalIndent(infos, "", "  ")
+	if err != nil {
+		return cw.n, fmt.Errorf("failed to write snippet library index: %w", err)
+	}
+	if err := writeEntry(zw, indexName, index); err != nil {
+		return cw.n, err
+	}
+	for _, info := range infos {
+		if err := writeEntry(zw, entryName(info.Name), l.data[info.Name]); err != nil {
+			return cw.n, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return cw.n, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return cw.n, nil
+}
+
+func writeEntry(zw *zip.Writer, name string, data []byte) error {
+	f, err := zw.Create(name)
+	if err == nil {
+		_, err = f.Write(data)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// SaveRange adds the paragraphs of src from start to end (inclusive), and
+// the tables between them, as a snippet, replacing any snippet of that name
+func (l *Library) SaveRange(name, description string, src *docx.Document, start, end int) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	part, err := src.ExtractRange(start, end)
+	if err != nil {
+		return err
+	}
+	return l.add(Info{Name: name, Kind: KindParagraphs, Description: description}, part)
+}
+
+// SaveTable adds the table of src at index as a snippet, replacing any
+// snippet of that name
+func (l *Library) SaveTable(name, description string, src *docx.Document, index int) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	part, err := src.ExtractTable(index)
+	if err != nil {
+		return err
+	}
+	return l.add(Info{Name: name, Kind: KindTable, Description: description}, part)
+}
+
+func (l *Library) add(info Info, part *docx.Document) error {
+	data, err := part.ToBytes()
+	if err != nil {
+		return fmt.Errorf("failed to save snippet %s: %w", info.Name, err)
+	}
+	preview := []rune(strings.TrimSpace(part.GetTextIn(docx.SearchScope{Body: true, Tables: true})))
+	if len(preview) > previewLength {
+		preview = append(preview[:previewLength-3], []rune("...")...)
+	}
+	info.Preview = string(preview)
+	info.Saved = time.Now().UTC().Truncate(time.Second)
+	l.infos[info.Name] = info
+	l.data[info.Name] = data
+	return nil
+}
+
+// List returns the snippets of the library, sorted by name
+func (l *Library) List() []Info {
+	infos := make([]Info, 0, len(l.infos))
+	for _, info := range l.infos {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Get returns a snippet as a document of its own
+func (l *Library) Get(name string) (*docx.Document, Info, error) {
+	info, ok := l.infos[name]
+	if !ok {
+		return nil, Info{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	doc, err := docx.ReadBytes(l.data[name])
+	if err != nil {
+		return nil, info, fmt.Errorf("failed to read snippet %s: %w", name, err)
+	}
+	return doc, info, nil
+}
+
+// Remove deletes a snippet from the library
+func (l *Library) Remove(name string) error {
+	if _, ok := l.infos[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	delete(l.infos, name)
+	delete(l.data, name)
+	return nil
+}
+
+// InsertSnippet inserts a snippet before the paragraph of doc at index at,
+// bringing along the styles, lists and images it uses (see
+// docx.Document.CopyParagraphsFrom)
+func (l *Library) InsertSnippet(doc *docx.Document, name string, at int) error {
+	snippet, info, err := l.Get(name)
+	if err != nil {
+		return err
+	}
+	if info.Kind == KindTable {
+		return doc.CopyTableFrom(snippet, 0, at)
+	}
+	return doc.CopyParagraphsFrom(snippet, 0, snippet.GetParagraphCount()-1, at)
+}
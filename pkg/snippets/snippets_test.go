@@ -0,0 +1,80 @@
+package snippets
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+func TestLibrary(t *testing.T) {
+	src := docx.New()
+	src.AddParagraph("Terms")
+	src.AddTable(2, 2).SetCellText(0, 0, "Fee")
+	src.AddParagraph("Yours sincerely,")
+	src.AddParagraph("Jane Doe, Director", docx.WithBold())
+
+	lib := New()
+	if err := lib.SaveRange("signature-block", "Closing and signature", src, 1, 2); err != nil {
+		t.Fatalf("SaveRange failed: %v", err)
+	}
+	if err := lib.SaveTable("fees", "", src, 0); err != nil {
+		t.Fatalf("SaveTable failed: %v", err)
+	}
+	if err := lib.SaveRange("../evil", "", src, 0, 0); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("Expected ErrInvalidName, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "library.zip")
+	if err := lib.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	lib, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	infos := lib.List()
+	if len(infos) != 2 || infos[0].Name != "fees" || infos[0].Kind != KindTable || infos[1].Name != "signature-block" {
+		t.Fatalf("Unexpected snippets: %+v", infos)
+	}
+	if got := infos[1].Preview; got != "Yours sincerely, Jane Doe, Director" || infos[1].Description != "Closing and signature" {
+		t.Errorf("Unexpected snippet details: %+v", infos[1])
+	}
+
+	doc := docx.New()
+	doc.AddParagraph("Dear client,")
+	doc.AddParagraph("P.S.")
+	if err := lib.InsertSnippet(doc, "signature-block", 1); err != nil {
+		t.Fatalf("InsertSnippet failed: %v", err)
+	}
+	if err := lib.InsertSnippet(doc, "fees", 1); err != nil {
+		t.Fatalf("InsertSnippet failed: %v", err)
+	}
+	if got := doc.GetText(); got != "Dear client, Yours sincerely, Jane Doe, Director P.S." {
+		t.Errorf("Unexpected text: %q", got)
+	}
+	if p := doc.Body.Paragraphs[2]; p.Runs[0].Props == nil || p.Runs[0].Props.Bold == nil {
+		t.Error("Expected the snippet to keep its formatting")
+	}
+	if doc.GetTableCount() != 1 || doc.Body.Tables[0].Position != 1 {
+		t.Errorf("Expected the table before paragraph 1, got %d tables", doc.GetTableCount())
+	}
+
+	if err := lib.InsertSnippet(doc, "missing", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if err := lib.Remove("fees"); err != nil || len(lib.List()) != 1 {
+		t.Errorf("Expected the snippet to be removed, got %v", err)
+	}
+
+	empty, err := Open(filepath.Join(t.TempDir(), "new.zip"))
+	if err != nil || len(empty.List()) != 0 {
+		t.Errorf("Expected a missing file to give an empty library, got %v", err)
+	}
+	if _, err := ReadBytes([]byte("not a zip")); err == nil || !strings.Contains(err.Error(), "snippet library") {
+		t.Errorf("Expected an error for invalid data, got %v", err)
+	}
+}
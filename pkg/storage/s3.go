@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3 stores files as objects of an Amazon S3 bucket, or of a service with
+// the same API such as MinIO, signing requests with AWS Signature Version 4.
+// Requests are anonymous when no access key is set.
+type S3 struct {
+	Bucket string
+
+	// Region of the bucket; defaults to us-east-1
+	Region string
+
+	// Endpoint of a service other than AWS, e.g. "http://localhost:9000",
+	// which is given the bucket in the path rather than the host name
+	Endpoint string
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // For temporary credentials
+
+	// Client makes the requests; defaults to http.DefaultClient
+	Client *http.Client
+
+	service string // Of the signing scope; "s3" when empty
+	scheme  string // Of the URIs named in errors; "s3" when empty
+}
+
+// S3FromEnv returns the store of a bucket configured from the environment
+// the AWS tools use: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+// AWS_SESSION_TOKEN, AWS_REGION (or AWS_DEFAULT_REGION) and
+// AWS_ENDPOINT_URL_S3 (or AWS_ENDPOINT_URL)
+func S3FromEnv(bucket string) *S3 {
+	return &S3{
+		Bucket:          bucket,
+		Region:          firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+		Endpoint:        firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// Open streams an object
+func (s *S3) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Create returns a writer uploading an object on Close
+func (s *S3) Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &objectWriter{put: func(data []byte) error {
+		resp, err := s.do(ctx, http.MethodPut, name, data)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}}, nil
+}
+
+// do sends a signed request for an object, returning successful responses
+func (s *S3) do(ctx context.Context, method, name string, body []byte) (*http.Response, error) {
+	region := s.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	var target string
+	key := escapeObjectKey(name)
+	switch {
+	case s.Endpoint != "":
+		target = strings.TrimSuffix(s.Endpoint, "/") + "/" + escapeObjectKey(s.Bucket) + "/" + key
+	case strings.Contains(s.Bucket, "."):
+		// Certificates don't cover bucket names with dots in the host name
+		target = "https://s3." + region + ".amazonaws.com/" + s.Bucket + "/" + key
+	default:
+		target = "https://" + s.Bucket + ".s3." + region + ".amazonaws.com/" + key
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	if s.AccessKeyID != "" {
+		service := s.service
+		if service == "" {
+			service = "s3"
+		}
+		signV4(req, body, s.AccessKeyID, s.SecretAccessKey, s.SessionToken, region, service, time.Now())
+	}
+	scheme := s.scheme
+	if scheme == "" {
+		scheme = "s3"
+	}
+	return send(s.Client, req, scheme+"://"+s.Bucket+"/"+name)
+}
+
+// send sends a request, turning error responses into errors naming uri
+func send(client *http.Client, req *http.Request, uri string) (*http.Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && req.Method == http.MethodGet {
+		return nil, &fs.PathError{Op: "open", Path: uri, Err: fs.ErrNotExist}
+	}
+	// Both S3 and Cloud Storage describe errors in an XML document
+	var apiErr struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(data, &apiErr) == nil && apiErr.Code != "" {
+		return nil, fmt.Errorf("%s %s: %s: %s: %s", req.Method, uri, resp.Status, apiErr.Code, apiErr.Message)
+	}
+	return nil, fmt.Errorf("%s %s: %s", req.Method, uri, resp.Status)
+}
+
+// signV4 signs a request with AWS Signature Version 4, in its headers
+func signV4(req *http.Request, body []byte, accessKey, secretKey, sessionToken, region, service string, now time.Time) {
+	now = now.UTC()
+	date := now.Format("20060102")
+	stamp := now.Format("20060102T150405Z")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	signature := hex.EncodeToString(hmacSHA256(signingKeyV4(secretKey, date, region, service), stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// signingKeyV4 derives the key signing requests of a day, region and service
+func signingKeyV4(secretKey, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// escapeObjectKey percent-encodes an object key for a URL path as
+// Signature Version 4 expects: all but letters, digits, '-', '_', '.', '~'
+// and the slashes
+func escapeObjectKey(key string) string {
+	var sb strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+// objectWriter holds the content of an object in memory and uploads it on Close
+type objectWriter struct {
+	buf    bytes.Buffer
+	put    func(data []byte) error
+	closed bool
+}
+
+func (w *objectWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, fs.ErrClosed
+	}
+	return w.buf.Write(p)
+}
+
+func (w *objectWriter) Close() error {
+	if w.closed {
+		return fs.ErrClosed
+	}
+	w.closed = true
+	return w.put(w.buf.Bytes())
+}
+
+// firstEnv returns the first of the environment variables that is set
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
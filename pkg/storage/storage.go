@@ -0,0 +1,234 @@
+// Package storage reads and writes documents wherever they are kept: on
+// disk, or in object storage such as Amazon S3 (s3://bucket/key) and Google
+// Cloud Storage (gs://bucket/object). The operations package and the CLI
+// take such URIs anywhere they take a path, so server deployments can work
+// on object storage directly, without temporary files. Other schemes can
+// be added with Register.
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+	"github.com/Palaciodiego008/docxsmith/pkg/pdf"
+)
+
+// ErrUnknownScheme is returned for URIs of a scheme no store is registered for
+var ErrUnknownScheme = errors.New("unknown storage scheme")
+
+// FileStore reads and writes named files. Names are relative to the store,
+// such as the key of an object in a bucket. Missing files fail to open with
+// an error matching fs.ErrNotExist.
+type FileStore interface {
+	// Open returns a reader of the content of a file
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+
+	// Create returns a writer replacing the content of a file. The content
+	// is only stored once Close returns without error; object stores keep
+	// it in memory until then, and store nothing if Close is never called.
+	Create(ctx context.Context, name string) (io.WriteCloser, error)
+}
+
+// Local stores files on disk, relative to Dir when it is set
+type Local struct {
+	Dir string
+}
+
+// Open opens a file on disk
+func (l Local) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return os.Open(l.path(name))
+}
+
+// Create creates or truncates a file on disk
+func (l Local) Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return os.Create(l.path(name))
+}
+
+func (l Local) path(name string) string {
+	if l.Dir == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(l.Dir, name)
+}
+
+var (
+	storesMu sync.RWMutex
+	stores   = map[string]func(bucket string) (FileStore, error){
+		"s3": func(bucket string) (FileStore, error) { return S3FromEnv(bucket), nil },
+		"gs": func(bucket string) (FileStore, error) { return GCSFromEnv(bucket), nil },
+	}
+)
+
+// Register makes URIs of a scheme, such as "azure" for azure://container/blob,
+// resolve to the store open returns for their host. It replaces the store
+// of a scheme registered before, built-in ones included.
+func Register(scheme string, open func(host string) (FileStore, error)) {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	stores[strings.ToLower(scheme)] = open
+}
+
+// IsRemote reports whether path is a URI such as s3://bucket/key rather
+// than a path on disk. file:// URIs are on disk.
+func IsRemote(path string) bool {
+	scheme, _, ok := splitURI(path)
+	return ok && scheme != "file"
+}
+
+// Resolve returns the store a path or URI names a file of, and the name of
+// the file in it. Paths, and file:// URIs, name files on disk.
+func Resolve(uri string) (FileStore, string, error) {
+	scheme, rest, ok := splitURI(uri)
+	if !ok {
+		return Local{}, uri, nil
+	}
+	if scheme == "file" {
+		return Local{}, filepath.FromSlash(rest), nil
+	}
+
+	storesMu.RLock()
+	open, ok := stores[scheme]
+	storesMu.RUnlock()
+	if !ok {
+		return nil, "", fmt.Errorf("%w %q in %s", ErrUnknownScheme, scheme, uri)
+	}
+	host, name, _ := strings.Cut(rest, "/")
+	if host == "" || name == "" {
+		return nil, "", fmt.Errorf("%s: expected %s://bucket/name", uri, scheme)
+	}
+	store, err := open(host)
+	if err != nil {
+		return nil, "", err
+	}
+	return store, name, nil
+}
+
+// splitURI splits scheme://rest; single letters are drive names, not schemes
+func splitURI(s string) (scheme, rest string, ok bool) {
+	scheme, rest, ok = strings.Cut(s, "://")
+	if !ok || len(scheme) < 2 || strings.ContainsAny(scheme, `/\.`) {
+		return "", "", false
+	}
+	return strings.ToLower(scheme), rest, true
+}
+
+// Join joins a directory, or a URI standing for one, with a file name
+func Join(dir, name string) string {
+	if !IsRemote(dir) {
+		return filepath.Join(dir, name)
+	}
+	return strings.TrimSuffix(dir, "/") + "/" + strings.TrimPrefix(filepath.ToSlash(name), "/")
+}
+
+// Open returns a reader of a file at a path or URI
+func Open(ctx context.Context, uri string) (io.ReadCloser, error) {
+	store, name, err := Resolve(uri)
+	if err != nil {
+		return nil, err
+	}
+	return store.Open(ctx, name)
+}
+
+// Create returns a writer of a file at a path or URI; see FileStore.Create
+func Create(ctx context.Context, uri string) (io.WriteCloser, error) {
+	store, name, err := Resolve(uri)
+	if err != nil {
+		return nil, err
+	}
+	return store.Create(ctx, name)
+}
+
+// ReadFile reads a whole file at a path or URI
+func ReadFile(ctx context.Context, uri string) ([]byte, error) {
+	rc, err := Open(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// WriteFile writes a whole file at a path or URI
+func WriteFile(ctx context.Context, uri string, data []byte) error {
+	return Write(ctx, uri, bytes.NewReader(data))
+}
+
+// OpenDOCX opens a DOCX file at a path or URI. Files on disk are opened with
+// docx.Open, which leaves large media in the file until the document is
+// closed; others are read whole.
+func OpenDOCX(ctx context.Context, uri string) (*docx.Document, error) {
+	if !IsRemote(uri) {
+		_, name, _ := Resolve(uri)
+		return docx.Open(name)
+	}
+	rc, err := Open(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return docx.ReadFrom(rc)
+}
+
+// SaveDOCX saves a DOCX document to a path or URI
+func SaveDOCX(ctx context.Context, doc *docx.Document, uri string) error {
+	if !IsRemote(uri) {
+		_, name, _ := Resolve(uri)
+		return doc.Save(name)
+	}
+	return Write(ctx, uri, doc)
+}
+
+// OpenPDF opens a PDF file at a path or URI
+func OpenPDF(ctx context.Context, uri string) (*pdf.Document, error) {
+	if !IsRemote(uri) {
+		_, name, _ := Resolve(uri)
+		return pdf.Open(name)
+	}
+	rc, err := Open(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return pdf.ReadFrom(rc)
+}
+
+// SavePDF saves a PDF document to a path or URI
+func SavePDF(ctx context.Context, doc *pdf.Document, uri string) error {
+	if !IsRemote(uri) {
+		_, name, _ := Resolve(uri)
+		return doc.Save(name)
+	}
+	return Write(ctx, uri, doc)
+}
+
+// Write stores what src writes, such as a document, as the file at a path or URI
+func Write(ctx context.Context, uri string, src io.WriterTo) error {
+	w, err := Create(ctx, uri)
+	if err != nil {
+		return err
+	}
+	if _, err := src.WriteTo(w); err != nil {
+		if f, ok := w.(*os.File); ok {
+			f.Close()
+		}
+		return fmt.Errorf("failed to write %s: %w", uri, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", uri, err)
+	}
+	return nil
+}
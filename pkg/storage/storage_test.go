@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		uri, name string
+		store     string
+	}{
+		{"report.docx", "report.docx", "storage.Local"},
+		{`C:\docs\report.docx`, `C:\docs\report.docx`, "storage.Local"},
+		{"file:///tmp/report.docx", filepath.FromSlash("/tmp/report.docx"), "storage.Local"},
+		{"s3://bucket/reports/q3.docx", "reports/q3.docx", "*storage.S3"},
+		{"GS://bucket/q3.docx", "q3.docx", "*storage.GCS"},
+	}
+	for _, tt := range tests {
+		store, name, err := Resolve(tt.uri)
+		if err != nil {
+			t.Errorf("%s: Resolve failed: %v", tt.uri, err)
+			continue
+		}
+		if got := fmt.Sprintf("%T", store); got != tt.store || name != tt.name {
+			t.Errorf("%s: expected %s %q, got %s %q", tt.uri, tt.store, tt.name, got, name)
+		}
+	}
+
+	if _, _, err := Resolve("ftp://host/file.docx"); !errors.Is(err, ErrUnknownScheme) {
+		t.Errorf("Expected ErrUnknownScheme, got %v", err)
+	}
+	if _, _, err := Resolve("s3://bucket"); err == nil {
+		t.Error("Expected an error for a URI without an object name")
+	}
+
+	if got := Join("s3://bucket/out/", "part_1.docx"); got != "s3://bucket/out/part_1.docx" {
+		t.Errorf("Unexpected join %q", got)
+	}
+	if got := Join("out", "part_1.docx"); got != filepath.Join("out", "part_1.docx") {
+		t.Errorf("Unexpected join %q", got)
+	}
+}
+
+func TestSigningKeyV4(t *testing.T) {
+	// Example from the AWS Signature Version 4 documentation
+	key := signingKeyV4("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	if got := hex.EncodeToString(key); got != "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d" {
+		t.Errorf("Unexpected signing key %s", got)
+	}
+}
+
+// fakeObjectServer stores objects by path, as S3 and Cloud Storage do with
+// path-style requests, checking each request with authorize
+func fakeObjectServer(t *testing.T, authorize func(*http.Request) bool) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorize(r) {
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.EscapedPath()], _ = io.ReadAll(r.Body)
+		case http.MethodGet:
+			data, ok := objects[r.URL.EscapedPath()]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestS3(t *testing.T) {
+	server := fakeObjectServer(t, func(r *http.Request) bool {
+		auth := r.Header.Get("Authorization")
+		return strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") &&
+			strings.Contains(auth, "/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") &&
+			r.Header.Get("X-Amz-Content-Sha256") != ""
+	})
+	Register("s3test", func(bucket string) (FileStore, error) {
+		return &S3{Bucket: bucket, Region: "eu-west-1", Endpoint: server.URL, AccessKeyID: "AKID", SecretAccessKey: "secret"}, nil
+	})
+
+	ctx := context.Background()
+	doc := docx.New()
+	doc.AddParagraph("Stored remotely")
+	uri := "s3test://docs/reports/Q3 (final).docx"
+	if err := SaveDOCX(ctx, doc, uri); err != nil {
+		t.Fatalf("SaveDOCX failed: %v", err)
+	}
+	loaded, err := OpenDOCX(ctx, uri)
+	if err != nil {
+		t.Fatalf("OpenDOCX failed: %v", err)
+	}
+	if text, _ := loaded.GetParagraphText(0); text != "Stored remotely" {
+		t.Errorf("Expected the saved paragraph, got %q", text)
+	}
+	if _, err := ReadFile(ctx, "s3test://docs/reports/Q3%20%28final%29.docx"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected keys to be escaped once, got %v", err)
+	}
+
+	if _, err := OpenDOCX(ctx, "s3test://docs/missing.docx"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got %v", err)
+	}
+	anonymous := &S3{Bucket: "docs", Endpoint: server.URL}
+	if _, err := anonymous.Open(ctx, "reports/Q3 (final).docx"); err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("Expected the error code of the service, got %v", err)
+	}
+
+	// Nothing is uploaded until Close
+	w, err := Create(ctx, "s3test://docs/draft.txt")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	io.WriteString(w, "draft")
+	if _, err := ReadFile(ctx, "s3test://docs/draft.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected no object before Close, got %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if data, err := ReadFile(ctx, "s3test://docs/draft.txt"); err != nil || string(data) != "draft" {
+		t.Errorf("Expected the object after Close, got %q (%v)", data, err)
+	}
+}
+
+func TestGCSToken(t *testing.T) {
+	server := fakeObjectServer(t, func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer token" && strings.HasPrefix(r.URL.Path, "/bucket/")
+	})
+	store := &GCS{Bucket: "bucket", Token: "token", Endpoint: strings.TrimPrefix(server.URL, "http://")}
+
+	ctx := context.Background()
+	w, err := store.Create(ctx, "a/b.txt")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	io.WriteString(w, "hello")
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	rc, err := store.Open(ctx, "a/b.txt")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer rc.Close()
+	if data, _ := io.ReadAll(rc); string(data) != "hello" {
+		t.Errorf("Expected hello, got %q", data)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	if err := WriteFile(ctx, filepath.Join(dir, "a.txt"), []byte("local")); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	rc, err := Local{Dir: dir}.Open(ctx, "a.txt")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer rc.Close()
+	if data, _ := io.ReadAll(rc); string(data) != "local" {
+		t.Errorf("Expected local, got %q", data)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := ReadFile(canceled, filepath.Join(dir, "a.txt")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
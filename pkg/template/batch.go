@@ -0,0 +1,81 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// BatchOptions holds options for RenderBatch
+type BatchOptions struct {
+	// Render configures the rendering of each record
+	Render RenderOptions
+
+	// Workers is the number of records rendered at once; defaults to the
+	// number of CPUs
+	Workers int
+}
+
+// DefaultBatchOptions returns default batch rendering options
+func DefaultBatchOptions() BatchOptions {
+	return BatchOptions{Render: DefaultOptions()}
+}
+
+// RenderBatch renders a template once per record with a pool of workers,
+// returning the documents in record order. The workers share the template,
+// which rendering only reads, so partials and filters must not be added
+// while a batch runs. It stops at the first record that fails, returning
+// its error.
+func RenderBatch(tmpl *Template, records []Data, opts BatchOptions) ([]*docx.Document, error) {
+	return RenderBatchContext(context.Background(), tmpl, records, opts)
+}
+
+// RenderBatchContext renders a batch like RenderBatch until ctx is canceled
+// or its deadline passes, returning ctx's error then
+func RenderBatchContext(ctx context.Context, tmpl *Template, records []Data, opts BatchOptions) ([]*docx.Document, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, len(records))
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	docs := make([]*docx.Document, len(records))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				doc, err := tmpl.RenderContext(ctx, records[i], opts.Render)
+				if err != nil {
+					cancel(fmt.Errorf("record %d: %w", i+1, err))
+					continue
+				}
+				docs[i] = doc
+			}
+		}()
+	}
+	for i := range records {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
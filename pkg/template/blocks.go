@@ -0,0 +1,72 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+var (
+	blockOpenPattern  = regexp.MustCompile(`\{\{(?:(?:range|if)\s|separator\}\})`)
+	separatorPattern  = regexp.MustCompile(`\{\{separator\}\}`)
+	blockClosePattern = regexp.MustCompile(`\{\{end\}\}`)
+	elsePattern       = regexp.MustCompile(`\{\{else(?:\s+if\s+(.+?))?\}\}`)
+)
+
+// blockDepth returns how many more blocks a paragraph opens than it closes
+func blockDepth(text string) int {
+	return len(blockOpenPattern.FindAllStringIndex(text, -1)) - len(blockClosePattern.FindAllStringIndex(text, -1))
+}
+
+// isBlockStart reports whether a paragraph starts a loop or conditional
+// that continues in the following paragraphs
+func isBlockStart(text string) bool {
+	return blockDepth(text) > 0
+}
+
+// findBlockEnd returns the index of the paragraph closing the block that
+// starts at paras[start], skipping over nested blocks
+func findBlockEnd(paras []docx.Paragraph, start int) (int, error) {
+	depth := 0
+	for i := start; i < len(paras); i++ {
+		depth += blockDepth(extractParagraphText(&paras[i]))
+		if depth <= 0 {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no matching {{end}} found for %s", extractParagraphText(&paras[start]))
+}
+
+// findElses returns the indexes of the {{else}} and {{else if}} paragraphs
+// belonging to the block paras[0] opens, skipping those of nested blocks.
+// paras runs from the opening to the closing paragraph.
+func findElses(paras []docx.Paragraph) []int {
+	var elses []int
+	depth := 0
+	for i := range paras {
+		text := extractParagraphText(&paras[i])
+		// Paragraphs holding blocks of their own have their own elses
+		if i > 0 && depth == 1 && elsePattern.MatchString(text) && !blockOpenPattern.MatchString(text) {
+			elses = append(elses, i)
+		}
+		depth += blockDepth(text)
+	}
+	return elses
+}
+
+// renderBlock renders a loop, conditional or separator. paras runs from the
+// paragraph opening the block to the one closing it.
+func (t *Template) renderBlock(paras []docx.Paragraph, sc *scope, opts RenderOptions) ([]docx.Paragraph, error) {
+	text := extractParagraphText(&paras[0])
+	if rangePattern.MatchString(text) {
+		return t.processLoop(paras, sc, opts)
+	}
+	if blockOpenPattern.FindString(text) == "{{separator}}" {
+		if sc.isLastItem() {
+			return []docx.Paragraph{}, nil
+		}
+		return t.renderParagraphs(paras[1:len(paras)-1], sc, opts)
+	}
+	return t.processConditional(paras, sc, opts)
+}
@@ -0,0 +1,151 @@
+package template
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// CacheOptions configures a Cache
+type CacheOptions struct {
+	// MaxEntries is the most templates kept; the least recently used one
+	// is dropped to make room for another. At least one is kept.
+	MaxEntries int
+
+	// Setup, when set, prepares each template once it is loaded, before it
+	// is shared, e.g. to register functions and partials. Templates failing
+	// it are not cached.
+	Setup func(*Template) error
+}
+
+// DefaultCacheOptions returns default cache options
+func DefaultCacheOptions() CacheOptions {
+	return CacheOptions{
+		MaxEntries: 64,
+	}
+}
+
+// Cache keeps parsed templates in memory, so services rendering the same
+// templates over and over don't unzip and parse them each time. Templates
+// are keyed by path and reloaded once the file's modification time or size
+// changes. A Cache is safe for concurrent use, and so is rendering the
+// templates it returns; they are shared, so don't change them.
+type Cache struct {
+	opts    CacheOptions
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	order   *list.List // Of *cacheEntry, most recently used first
+}
+
+// cacheEntry is a template being loaded, or loaded, from a version of a file
+type cacheEntry struct {
+	path    string
+	modTime time.Time
+	size    int64
+	elem    *list.Element
+
+	ready chan struct{} // Closed once tmpl or err is set
+	tmpl  *Template
+	err   error
+}
+
+// NewCache creates an empty cache
+func NewCache(opts CacheOptions) *Cache {
+	if opts.MaxEntries < 1 {
+		opts.MaxEntries = 1
+	}
+	return &Cache{
+		opts:    opts,
+		entries: make(map[string]*cacheEntry),
+		order:   list.New(),
+	}
+}
+
+// Get returns the template at a path, loading it unless the cache holds the
+// file as it is now. Callers asking for a template while it loads wait for
+// it rather than loading it again.
+func (c *Cache) Get(path string) (*Template, error) {
+	path = filepath.Clean(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template: %w", err)
+	}
+
+	c.mu.Lock()
+	e, ok := c.entries[path]
+	if ok && e.modTime.Equal(info.ModTime()) && e.size == info.Size() {
+		c.order.MoveToFront(e.elem)
+		c.mu.Unlock()
+		<-e.ready
+		return e.tmpl, e.err
+	}
+	if ok {
+		c.remove(e)
+	}
+	e = &cacheEntry{path: path, modTime: info.ModTime(), size: info.Size(), ready: make(chan struct{})}
+	e.elem = c.order.PushFront(e)
+	c.entries[path] = e
+	for c.order.Len() > c.opts.MaxEntries {
+		c.remove(c.order.Back().Value.(*cacheEntry))
+	}
+	c.mu.Unlock()
+
+	e.tmpl, e.err = c.load(path)
+	close(e.ready)
+	if e.err != nil {
+		c.mu.Lock()
+		if c.entries[path] == e {
+			c.remove(e)
+		}
+		c.mu.Unlock()
+	}
+	return e.tmpl, e.err
+}
+
+// load reads a template wholly into memory: unlike with Load, no file is
+// kept open for large media, which rendered documents would share
+func (c *Cache) load(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template: %w", err)
+	}
+	doc, err := docx.ReadBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template: %w", err)
+	}
+	doc.FilePath = path
+
+	tmpl := &Template{doc: doc, filePath: path}
+	if c.opts.Setup != nil {
+		if err := c.opts.Setup(tmpl); err != nil {
+			return nil, fmt.Errorf("failed to set up template %s: %w", path, err)
+		}
+	}
+	return tmpl, nil
+}
+
+// Remove drops the template at a path from the cache
+func (c *Cache) Remove(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[filepath.Clean(path)]; ok {
+		c.remove(e)
+	}
+}
+
+// Len returns the number of templates in the cache
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *Cache) remove(e *cacheEntry) {
+	c.order.Remove(e.elem)
+	delete(c.entries, e.path)
+}
@@ -8,85 +8,61 @@ import (
 	"github.com/Palaciodiego008/docxsmith/pkg/docx"
 )
 
-// processConditional processes a {{if .Condition}}...{{end}} directive
-func (t *Template) processConditional(doc *docx.Document, startIdx int, data Data, opts RenderOptions) ([]docx.Paragraph, int, error) {
+// ifPattern matches {{if .Condition}} and expressions such as {{if eq .Status "approved"}}
+var ifPattern = regexp.MustCompile(`\{\{if\s+(.+?)\}\}`)
+
+// processConditional processes a {{if ...}}...{{else if ...}}...{{else}}...{{end}}
+// directive. paras runs from the if paragraph to its {{end}}; the branches
+// may hold nested loops and conditionals. The first branch whose condition
+// holds is rendered.
+func (t *Template) processConditional(paras []docx.Paragraph, sc *scope, opts RenderOptions) ([]docx.Paragraph, error) {
 	// Find the if directive
-	startText := extractParagraphText(&doc.Body.Paragraphs[startIdx])
-	ifPattern := regexp.MustCompile(`\{\{if\s+\.([a-zA-Z0-9_]+)\}\}`)
+	startText := extractParagraphText(&paras[0])
 	matches := ifPattern.FindStringSubmatch(startText)
 
 	if len(matches) < 2 {
-		return nil, 0, fmt.Errorf("invalid if directive: %s", startText)
+		return nil, fmt.Errorf("invalid if directive: %s", startText)
 	}
 
-	conditionName := matches[1]
-
-	// Get the condition value
-	conditionValue, err := getValueFromData(data, conditionName)
-	if err != nil {
-		if opts.StrictMode {
-			return nil, 0, fmt.Errorf("condition variable %s not found", conditionName)
+	// Each branch runs from its directive to the next one
+	starts := append([]int{0}, findElses(paras)...)
+	for b, start := range starts {
+		end := len(paras) - 1
+		if b+1 < len(starts) {
+			end = starts[b+1]
 		}
-		conditionValue = false
-	}
-
-	// Evaluate condition
-	condition := evaluateCondition(conditionValue)
 
-	// Find the end directive
-	endIdx := -1
-	elseIdx := -1
-	for i := startIdx + 1; i < len(doc.Body.Paragraphs); i++ {
-		text := extractParagraphText(&doc.Body.Paragraphs[i])
-		if strings.Contains(text, "{{else}}") && elseIdx == -1 {
-			elseIdx = i
+		expr := matches[1]
+		if b > 0 {
+			expr = elsePattern.FindStringSubmatch(extractParagraphText(&paras[start]))[1]
 		}
-		if strings.Contains(text, "{{end}}") {
-			endIdx = i
-			break
+		condition, err := evaluateBranch(expr, sc, opts)
+		if err != nil {
+			return nil, err
 		}
-	}
-
-	if endIdx == -1 {
-		return nil, 0, fmt.Errorf("no matching {{end}} found for {{if}}")
-	}
-
-	result := []docx.Paragraph{}
 
-	if condition {
-		// Include content from startIdx+1 to elseIdx-1 (or endIdx-1 if no else)
-		endContentIdx := endIdx
-		if elseIdx != -1 {
-			endContentIdx = elseIdx
+		if condition {
+			return t.renderParagraphs(paras[start+1:end], sc, opts)
 		}
+	}
+	return []docx.Paragraph{}, nil
+}
 
-		for i := startIdx + 1; i < endContentIdx; i++ {
-			para := cloneParagraph(&doc.Body.Paragraphs[i])
-			// Replace variables in the paragraph
-			if err := t.replaceParagraphVariables(&para, data, opts); err != nil {
-				if opts.StrictMode {
-					return nil, 0, err
-				}
-			}
-			result = append(result, para)
-		}
-	} else if elseIdx != -1 {
-		// Include content from elseIdx+1 to endIdx-1
-		for i := elseIdx + 1; i < endIdx; i++ {
-			para := cloneParagraph(&doc.Body.Paragraphs[i])
-			// Replace variables in the paragraph
-			if err := t.replaceParagraphVariables(&para, data, opts); err != nil {
-				if opts.StrictMode {
-					return nil, 0, err
-				}
-			}
-			result = append(result, para)
+// evaluateBranch evaluates the condition of a branch; an empty condition is
+// a plain {{else}}, which always holds
+func evaluateBranch(expr string, sc *scope, opts RenderOptions) (bool, error) {
+	if expr == "" {
+		return true, nil
+	}
+	value, err := evaluateExpression(expr, sc, opts)
+	if err != nil {
+		opts.report.add(IssueInvalidExpression, "{{if "+expr+"}}", "%v", err)
+		if opts.report != nil {
+			return false, nil
 		}
+		return false, fmt.Errorf("invalid condition %q: %w", expr, err)
 	}
-
-	// Return result and number of paragraphs consumed
-	consumed := endIdx - startIdx + 1
-	return result, consumed, nil
+	return evaluateCondition(value), nil
 }
 
 // evaluateCondition evaluates a condition value to boolean
@@ -112,102 +88,146 @@ func evaluateCondition(value interface{}) bool {
 	}
 }
 
-// processTable processes variables in table cells
-func (t *Template) processTable(table *docx.Table, data Data, opts RenderOptions) error {
-	// Check if table has range directive in first row
-	if len(table.Rows) > 0 {
-		firstRowText := ""
-		if len(table.Rows[0].Cells) > 0 && len(table.Rows[0].Cells[0].Content) > 0 {
-			firstRowText = extractParagraphText(&table.Rows[0].Cells[0].Content[0])
-		}
-
-		// Check for range directive
-		if strings.Contains(firstRowText, "{{range") {
-			return t.processTableLoop(table, data, opts)
+// processTable processes variables in table cells and repeats the rows
+// holding a range directive. A row whose first cell starts a loop, such as
+// "{{range .Items}}{{.Item.Name}}", is repeated for each item; it may close
+// the loop with {{end}} in its last cell. A row holding nothing but the
+// directive repeats the row after it instead. Other rows are left in place.
+func (t *Template) processTable(table *docx.Table, sc *scope, opts RenderOptions) error {
+	for i := 0; i < len(table.Rows); i++ {
+		row := &table.Rows[i]
+		if len(row.Cells) > 0 && isBlockStart(cellText(&row.Cells[0])) && rangePattern.MatchString(cellText(&row.Cells[0])) {
+			rows, consumed, err := t.processTableLoop(table.Rows[i:], sc, opts)
+			if err != nil {
+				return err
+			}
+			table.Rows = append(table.Rows[:i], append(rows, table.Rows[i+consumed:]...)...)
+			i += len(rows) - 1
+			continue
 		}
-	}
 
-	// Regular table - just replace variables in each cell
-	for i := range table.Rows {
-		for j := range table.Rows[i].Cells {
-			for k := range table.Rows[i].Cells[j].Content {
-				para := &table.Rows[i].Cells[j].Content[k]
-				if err := t.replaceParagraphVariables(para, data, opts); err != nil {
-					if opts.StrictMode {
-						return err
-					}
-				}
-			}
+		if err := t.renderRow(row, sc, opts); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-// processTableLoop processes a range directive in a table
-func (t *Template) processTableLoop(table *docx.Table, data Data, opts RenderOptions) error {
-	if len(table.Rows) < 2 {
-		return fmt.Errorf("table loop requires at least 2 rows (directive + template)")
-	}
-
-	// Parse range directive from first row
-	firstRowText := extractParagraphText(&table.Rows[0].Cells[0].Content[0])
-	rangePattern := regexp.MustCompile(`\{\{range\s+\.([a-zA-Z0-9_]+)\}\}`)
-	matches := rangePattern.FindStringSubmatch(firstRowText)
-
+// processTableLoop repeats the template row of a row loop for each item.
+// rows starts at the row holding the range directive; the number of rows
+// the loop took up is returned along with the generated ones.
+func (t *Template) processTableLoop(rows []docx.TblRow, sc *scope, opts RenderOptions) ([]docx.TblRow, int, error) {
+	directive := cellText(&rows[0].Cells[0])
+	matches := rangePattern.FindStringSubmatch(directive)
 	if len(matches) < 2 {
-		return fmt.Errorf("invalid range directive in table: %s", firstRowText)
+		return nil, 0, fmt.Errorf("invalid range directive in table: %s", directive)
 	}
-
 	collectionName := matches[1]
 
-	// Get the collection
-	collection, err := getValueFromData(data, collectionName)
-	if err != nil {
-		if opts.StrictMode {
-			return fmt.Errorf("collection %s not found", collectionName)
+	// The directive and its {{end}} aren't part of the repeated row
+	templateRow := cloneTableRow(&rows[0])
+	removeFromRow(&templateRow, matches[0], false)
+	if blockDepth(rowText(&templateRow)) < 0 {
+		removeFromRow(&templateRow, "{{end}}", true)
+	}
+	consumed := 1
+	if strings.TrimSpace(rowText(&templateRow)) == "" {
+		if len(rows) < 2 {
+			return nil, 0, fmt.Errorf("table loop requires a row to repeat after %s", matches[0])
 		}
-		return nil
+		templateRow = rows[1]
+		consumed = 2
 	}
 
-	// Convert to slice
-	collectionSlice, err := toSlice(collection)
+	// Get the items
+	collectionSlice, err := loopItems(sc, collectionName, opts)
 	if err != nil {
-		return fmt.Errorf("collection %s is not iterable: %w", collectionName, err)
+		return nil, 0, err
 	}
 
-	// The second row is the template
-	templateRow := table.Rows[1]
-
-	// Remove first two rows (directive + template)
-	table.Rows = table.Rows[2:]
-
 	// Generate rows for each item
 	newRows := []docx.TblRow{}
-
-	for _, item := range collectionSlice {
-		// Clone the template row
+	for idx, item := range collectionSlice {
 		newRow := cloneTableRow(&templateRow)
+		if err := t.renderRow(&newRow, sc.loopScope(item, idx, len(collectionSlice)), opts); err != nil {
+			return nil, 0, err
+		}
+		newRows = append(newRows, newRow)
+	}
+
+	return newRows, consumed, nil
+}
 
-		// Replace variables in each cell
-		for i := range newRow.Cells {
-			for j := range newRow.Cells[i].Content {
-				para := &newRow.Cells[i].Content[j]
-				if err := t.replaceLoopVariables(para, item, opts); err != nil {
-					if opts.StrictMode {
-						return err
-					}
+// renderRow renders the paragraphs in each cell of a row
+func (t *Template) renderRow(row *docx.TblRow, sc *scope, opts RenderOptions) error {
+	for i := range row.Cells {
+		cell := &row.Cells[i]
+		for j := 0; j < len(cell.Content); j++ {
+			if block := t.richBlock(&cell.Content[j], sc, opts); block != nil {
+				paras := block.paragraphs(&cell.Content[j])
+				if len(paras) == 0 {
+					paras = []docx.Paragraph{paragraphWithText(&cell.Content[j], "")} // Cells need a paragraph
 				}
+				cell.Content = append(cell.Content[:j], append(paras, cell.Content[j+1:]...)...)
+				j += len(paras) - 1
+				continue
+			}
+			if err := t.renderParagraph(&cell.Content[j], sc, opts); err != nil {
+				return err
 			}
 		}
+	}
+	return nil
+}
 
-		newRows = append(newRows, newRow)
+// cellText returns the text of all paragraphs in a cell
+func cellText(cell *docx.TblCell) string {
+	var text string
+	for i := range cell.Content {
+		text += extractParagraphText(&cell.Content[i])
+	}
+	return text
+}
+
+// rowText returns the text of all cells in a row
+func rowText(row *docx.TblRow) string {
+	var text string
+	for i := range row.Cells {
+		text += cellText(&row.Cells[i])
+	}
+	return text
+}
+
+// removeFromRow removes the first occurrence of s from the text of a row,
+// or the last one when last is set
+func removeFromRow(row *docx.TblRow, s string, last bool) {
+	var texts []*docx.Text
+	for i := range row.Cells {
+		for j := range row.Cells[i].Content {
+			for _, run := range paragraphRuns(&row.Cells[i].Content[j]) {
+				for k := range run.Text {
+					texts = append(texts, &run.Text[k])
+				}
+			}
+		}
 	}
 
-	// Prepend new rows to existing rows
-	table.Rows = append(newRows, table.Rows...)
+	for n := range texts {
+		text := texts[n]
+		if last {
+			text = texts[len(texts)-1-n]
+		}
 
-	return nil
+		idx := strings.Index(text.Content, s)
+		if last {
+			idx = strings.LastIndex(text.Content, s)
+		}
+		if idx >= 0 {
+			text.Content = text.Content[:idx] + text.Content[idx+len(s):]
+			return
+		}
+	}
 }
 
 // cloneTableRow creates a deep copy of a table row
@@ -223,9 +243,8 @@ func cloneTableRow(row *docx.TblRow) docx.TblRow {
 
 		// Copy cell properties
 		if cell.Props != nil {
-			newCell.Props = &docx.TcPr{
-				Width: cell.Props.Width,
-			}
+			props := *cell.Props
+			newCell.Props = &props
 		}
 
 		// Clone each paragraph
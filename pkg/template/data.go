@@ -0,0 +1,111 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseData parses JSON or YAML data
+func ParseData(content []byte) (Data, error) {
+	var result Data
+	if err := json.Unmarshal(content, &result); err == nil {
+		return result, nil
+	}
+	if err := yaml.Unmarshal(content, &result); err == nil {
+		return result, nil
+	}
+	return nil, fmt.Errorf("failed to parse data as JSON or YAML")
+}
+
+// Set sets the value at a dotted path such as "Customer.Name", creating the
+// maps on the way and replacing values that are not maps
+func (d Data) Set(path string, value interface{}) error {
+	keys := strings.Split(path, ".")
+	for _, k := range keys {
+		if k == "" {
+			return fmt.Errorf("invalid key %q", path)
+		}
+	}
+	current := map[string]interface{}(d)
+	for _, k := range keys[:len(keys)-1] {
+		switch next := current[k].(type) {
+		case map[string]interface{}:
+			current = next
+		case Data:
+			current = next
+		default:
+			m := make(map[string]interface{})
+			current[k] = m
+			current = m
+		}
+	}
+	current[keys[len(keys)-1]] = value
+	return nil
+}
+
+// SetPairs sets the values of Key=Value pairs, such as those given on the
+// command line. Keys may be dotted paths; values are set as strings.
+func (d Data) SetPairs(pairs []string) error {
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid pair %q, expected Key=Value", pair)
+		}
+		if err := d.Set(strings.TrimSpace(key), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnvData returns the environment variables whose names start with prefix
+// as data, keyed by the rest of the name: with the prefix DOCX_,
+// DOCX_Customer=Acme sets Customer. A double underscore separates the keys
+// of a nested value, so DOCX_Customer__Name sets Customer.Name.
+func EnvData(prefix string) Data {
+	data := Data{}
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		key, ok := strings.CutPrefix(name, prefix)
+		if !ok || key == "" {
+			continue
+		}
+		// Names that don't make a valid path, such as DOCX___X, are skipped
+		_ = data.Set(strings.ReplaceAll(key, "__", "."), value)
+	}
+	return data
+}
+
+// Merge copies the values of other into the data, merging nested maps so
+// other only replaces the values it sets
+func (d Data) Merge(other Data) {
+	mergeMaps(d, other)
+}
+
+// mergeMaps copies src into dst, merging the maps both hold under a key
+func mergeMaps(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if sv, ok := asMap(v); ok {
+			if dv, ok := asMap(dst[k]); ok {
+				mergeMaps(dv, sv)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// asMap returns a value as a map, when it is one
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case Data:
+		return m, true
+	}
+	return nil, false
+}
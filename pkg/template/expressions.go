@@ -0,0 +1,263 @@
+package template
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// evaluateExpression evaluates a condition such as `.IsPaid`,
+// `eq .Status "approved"` or `and (gt .Total 1000) (not .Exempt)`.
+// Functions come first and take their arguments after them, as in Go
+// templates; parentheses group nested calls.
+func evaluateExpression(expr string, sc *scope, opts RenderOptions) (interface{}, error) {
+	tokens, err := tokenizeExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens, scope: sc, opts: opts}
+	value, err := p.parseCall()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in expression %q", p.tokens[p.pos].text, expr)
+	}
+	return value, nil
+}
+
+// exprFuncs are the functions available in conditions
+var exprFuncs = map[string]func(args []interface{}) (interface{}, error){
+	"eq": func(args []interface{}) (interface{}, error) {
+		// eq .A .B .C is true when A equals B or C, as in Go templates
+		if len(args) < 2 {
+			return nil, fmt.Errorf("eq needs at least 2 arguments")
+		}
+		for _, arg := range args[1:] {
+			if compareValues(args[0], arg) == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	},
+	"ne": comparison("ne", func(c int) bool { return c != 0 }),
+	"lt": comparison("lt", func(c int) bool { return c < 0 }),
+	"le": comparison("le", func(c int) bool { return c <= 0 }),
+	"gt": comparison("gt", func(c int) bool { return c > 0 }),
+	"ge": comparison("ge", func(c int) bool { return c >= 0 }),
+	"and": func(args []interface{}) (interface{}, error) {
+		for _, arg := range args {
+			if !evaluateCondition(arg) {
+				return false, nil
+			}
+		}
+		return len(args) > 0, nil
+	},
+	"or": func(args []interface{}) (interface{}, error) {
+		for _, arg := range args {
+			if evaluateCondition(arg) {
+				return true, nil
+			}
+		}
+		return false, nil
+	},
+	"not": func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("not needs 1 argument")
+		}
+		return !evaluateCondition(args[0]), nil
+	},
+}
+
+// comparison returns a function comparing two arguments
+func comparison(name string, test func(int) bool) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s needs 2 arguments", name)
+		}
+		return test(compareValues(args[0], args[1])), nil
+	}
+}
+
+// compareValues compares two values numerically when both are numbers (or
+// numeric strings), and as text otherwise
+func compareValues(a, b interface{}) int {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// toFloat converts numbers and numeric strings to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// exprToken is a token of a condition
+type exprToken struct {
+	kind rune // '(', ')' and '|', 's' for strings, 'w' for words (names, paths, numbers)
+	text string
+}
+
+// tokenizeExpression splits a condition into tokens. Word's curly quotes are
+// accepted around strings, since Word replaces straight quotes as you type.
+func tokenizeExpression(expr string) ([]exprToken, error) {
+	var tokens []exprToken
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == '|':
+			tokens = append(tokens, exprToken{kind: r, text: string(r)})
+			i++
+		case r == '"' || r == '“' || r == '”':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != '"' && runes[j] != '”' && runes[j] != '“'; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				b.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string in expression %q", expr)
+			}
+			tokens = append(tokens, exprToken{kind: 's', text: b.String()})
+			i = j + 1
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune("()|", runes[j]) {
+				j++
+			}
+			tokens = append(tokens, exprToken{kind: 'w', text: string(runes[i:j])})
+			i = j
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	return tokens, nil
+}
+
+// exprParser evaluates tokens as it parses them
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+	scope  *scope
+	opts   RenderOptions
+}
+
+// parseCall parses a function call or a single operand
+func (p *exprParser) parseCall() (interface{}, error) {
+	tok := p.tokens[p.pos]
+	fn, isFunc := exprFuncs[tok.text]
+	if tok.kind != 'w' || !isFunc {
+		return p.parseOperand()
+	}
+	p.pos++
+
+	var args []interface{}
+	for p.pos < len(p.tokens) && p.tokens[p.pos].kind != ')' {
+		arg, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return fn(args)
+}
+
+// parseOperand parses a variable, literal or parenthesized call
+func (p *exprParser) parseOperand() (interface{}, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("missing operand")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+
+	switch tok.kind {
+	case '(':
+		if p.pos >= len(p.tokens) {
+			return nil, fmt.Errorf("missing operand")
+		}
+		value, err := p.parseCall()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != ')' {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return value, nil
+	case ')', '|':
+		return nil, fmt.Errorf("unexpected %s", tok.text)
+	case 's':
+		return tok.text, nil
+	}
+
+	switch {
+	case strings.HasPrefix(tok.text, "."):
+		value, err := p.scope.lookup(tok.text[1:])
+		if err != nil {
+			reportLookup(p.opts.report, tok.text, "condition variable", tok.text[1:], err)
+			if p.opts.StrictMode {
+				return nil, &ErrTemplateVariableMissing{Name: tok.text[1:], Use: "condition variable"}
+			}
+			return nil, nil
+		}
+		return value, nil
+	case tok.text == "true" || tok.text == "false":
+		return tok.text == "true", nil
+	case tok.text == "nil":
+		return nil, nil
+	}
+	if n, err := strconv.ParseFloat(tok.text, 64); err == nil {
+		return n, nil
+	}
+	if _, isFunc := exprFuncs[tok.text]; isFunc {
+		return nil, fmt.Errorf("function %s must be wrapped in parentheses when used as an argument", tok.text)
+	}
+	return nil, fmt.Errorf("unknown name %q", tok.text)
+}
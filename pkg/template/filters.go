@@ -0,0 +1,321 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FilterFunc formats a value in a pipeline such as {{.Price | currency "USD"}}.
+// value is the output of the previous stage and args are the arguments
+// written after the filter name.
+type FilterFunc func(value interface{}, args ...interface{}) (interface{}, error)
+
+// errUnknownFilter is returned for pipelines using a filter that isn't registered
+var errUnknownFilter = errors.New("unknown filter")
+
+// errRequired is returned by the required filter for an empty or missing value
+var errRequired = errors.New("value is required")
+
+// RegisterFunc makes a filter available to the template's pipelines.
+// A filter with the name of a built-in one replaces it.
+func (t *Template) RegisterFunc(name string, fn FilterFunc) {
+	if t.funcs == nil {
+		t.funcs = make(map[string]FilterFunc)
+	}
+	t.funcs[name] = fn
+}
+
+// filter returns the registered or built-in filter with the given name,
+// formatting numbers and dates for loc (English when nil)
+func (t *Template) filter(name string, loc *locale) (FilterFunc, bool) {
+	if fn, ok := t.funcs[name]; ok {
+		return fn, true
+	}
+	if loc == nil {
+		loc = english
+	}
+	if fn, ok := localeFilters[name]; ok {
+		return fn(loc), true
+	}
+	fn, ok := builtinFilters[name]
+	return fn, ok
+}
+
+// applyFilters passes a value through a pipeline such as
+// `| currency "USD"` or `| trim | upper`
+func (t *Template) applyFilters(value interface{}, pipeline string, sc *scope, opts RenderOptions) (interface{}, error) {
+	tokens, err := tokenizeExpression(pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens, scope: sc, opts: opts}
+	for p.pos < len(p.tokens) {
+		if p.tokens[p.pos].kind != '|' {
+			return nil, fmt.Errorf("unexpected %q in pipeline %q", p.tokens[p.pos].text, pipeline)
+		}
+		p.pos++
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != 'w' {
+			return nil, fmt.Errorf("missing filter name in pipeline %q", pipeline)
+		}
+		name := p.tokens[p.pos].text
+		fn, ok := t.filter(name, opts.locale)
+		if !ok {
+			return nil, fmt.Errorf("%w %q", errUnknownFilter, name)
+		}
+		p.pos++
+
+		var args []interface{}
+		for p.pos < len(p.tokens) && p.tokens[p.pos].kind != '|' {
+			arg, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+
+		if value, err = fn(value, args...); err != nil {
+			return nil, fmt.Errorf("filter %s: %w", name, err)
+		}
+	}
+	return value, nil
+}
+
+// builtinFilters are the filters available to every template
+var builtinFilters = map[string]FilterFunc{
+	"upper": stringFilter(strings.ToUpper),
+	"lower": stringFilter(strings.ToLower),
+	"title": stringFilter(titleCase),
+	"trim":  stringFilter(strings.TrimSpace),
+	"default": func(value interface{}, args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("needs 1 argument")
+		}
+		if toString(value) == "" {
+			return args[0], nil
+		}
+		return value, nil
+	},
+	"required": func(value interface{}, args ...interface{}) (interface{}, error) {
+		if len(args) != 0 {
+			return nil, fmt.Errorf("takes no arguments")
+		}
+		if toString(value) == "" {
+			return nil, errRequired
+		}
+		return value, nil
+	},
+	"checkbox": func(value interface{}, args ...interface{}) (interface{}, error) {
+		if len(args) != 0 && len(args) != 2 {
+			return nil, fmt.Errorf("needs no arguments, or the checked and unchecked text")
+		}
+		checked, unchecked := checkedBox, uncheckedBox
+		if len(args) == 2 {
+			checked, unchecked = toString(args[0]), toString(args[1])
+		}
+		if evaluateCondition(value) {
+			return checked, nil
+		}
+		return unchecked, nil
+	},
+	"join": func(value interface{}, args ...interface{}) (interface{}, error) {
+		if len(args) > 1 {
+			return nil, fmt.Errorf("needs at most 1 argument")
+		}
+		items, ok := listItems(value)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a list", value)
+		}
+		sep := ", "
+		if len(args) == 1 {
+			sep = toString(args[0])
+		}
+		texts := make([]string, len(items))
+		for i, item := range items {
+			texts[i] = toString(item)
+		}
+		return strings.Join(texts, sep), nil
+	},
+	"truncate": func(value interface{}, args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("needs 1 argument")
+		}
+		n, ok := toFloat(args[0])
+		if !ok || n < 0 {
+			return nil, fmt.Errorf("invalid length %v", args[0])
+		}
+		runes := []rune(toString(value))
+		if len(runes) <= int(n) {
+			return string(runes), nil
+		}
+		return string(runes[:int(n)]) + "…", nil
+	},
+	"replace": func(value interface{}, args ...interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("needs 2 arguments")
+		}
+		return strings.ReplaceAll(toString(value), toString(args[0]), toString(args[1])), nil
+	},
+}
+
+// localeFilters are the built-in filters formatting for the locale of
+// RenderOptions.Locale
+var localeFilters = map[string]func(loc *locale) FilterFunc{
+	"number": func(loc *locale) FilterFunc {
+		return func(value interface{}, args ...interface{}) (interface{}, error) {
+			n, ok := toFloat(value)
+			if !ok {
+				return nil, fmt.Errorf("%v is not a number", value)
+			}
+			decimals := 0
+			if len(args) > 0 {
+				d, ok := toFloat(args[0])
+				if !ok || d < 0 {
+					return nil, fmt.Errorf("invalid decimals %v", args[0])
+				}
+				decimals = int(d)
+			}
+			return loc.formatNumber(n, decimals), nil
+		}
+	},
+	"currency": func(loc *locale) FilterFunc {
+		return func(value interface{}, args ...interface{}) (interface{}, error) {
+			n, ok := toFloat(value)
+			if !ok {
+				return nil, fmt.Errorf("%v is not a number", value)
+			}
+			code := "USD"
+			if len(args) > 0 {
+				code = strings.ToUpper(toString(args[0]))
+			}
+			return loc.formatCurrency(n, code), nil
+		}
+	},
+	"date": func(loc *locale) FilterFunc {
+		return func(value interface{}, args ...interface{}) (interface{}, error) {
+			date, err := toTime(value)
+			if err != nil {
+				return nil, err
+			}
+			layout := "2006-01-02"
+			if len(args) > 0 {
+				layout = toString(args[0])
+			}
+			return loc.formatDate(date, layout), nil
+		}
+	},
+}
+
+// stringFilter adapts a string function to a filter
+func stringFilter(fn func(string) string) FilterFunc {
+	return func(value interface{}, args ...interface{}) (interface{}, error) {
+		return fn(toString(value)), nil
+	}
+}
+
+// toString formats a value for the document, rendering nil as empty
+func toString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// titleCase capitalizes the first letter of each word
+func titleCase(s string) string {
+	runes := []rune(s)
+	for i, r := range runes {
+		if i == 0 || unicode.IsSpace(runes[i-1]) {
+			runes[i] = unicode.ToUpper(r)
+		}
+	}
+	return string(runes)
+}
+
+// currencies maps ISO currency codes to their symbol and number of decimals
+var currencies = map[string]struct {
+	symbol   string
+	decimals int
+}{
+	"USD": {"$", 2},
+	"EUR": {"€", 2},
+	"GBP": {"£", 2},
+	"JPY": {"¥", 0},
+	"CNY": {"¥", 2},
+	"INR": {"₹", 2},
+	"KRW": {"₩", 0},
+	"BRL": {"R$", 2},
+	"MXN": {"$", 2},
+	"CAD": {"$", 2},
+	"AUD": {"$", 2},
+}
+
+// formatCurrency formats an amount such as -$1,234.50. Unknown codes are
+// written before the amount, as in "CHF 1,234.50".
+func formatCurrency(amount float64, code string) string {
+	symbol, decimals := code+" ", 2
+	if c, ok := currencies[code]; ok {
+		symbol, decimals = c.symbol, c.decimals
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return sign + symbol + formatNumber(amount, decimals)
+}
+
+// formatNumber formats a number with thousands separators and a fixed number
+// of decimals, such as 1,234.50
+func formatNumber(n float64, decimals int) string {
+	s := strconv.FormatFloat(math.Abs(n), 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if n < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString("." + fraction)
+	}
+	return b.String()
+}
+
+// dateLayouts are the formats accepted for dates given as strings
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// toTime converts times and date strings to time.Time
+func toTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		if v != nil {
+			return *v, nil
+		}
+	case string:
+		for _, layout := range dateLayouts {
+			if date, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return date, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("%v is not a date", value)
+}
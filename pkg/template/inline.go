@@ -0,0 +1,273 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// inlineDirectivePattern matches the directives of blocks written inside a
+// single paragraph, such as "Dear {{if .Formal}}Mr. {{.Last}}{{else}}{{.First}}{{end}},"
+// or "{{range .Names}}{{.Item}}{{separator}}, {{end}}{{end}}"
+var inlineDirectivePattern = regexp.MustCompile(`\{\{(?:(range|if|separator)(?:\s+([^{}]*?))?|else(?:\s+if\s+([^{}]*?))?|end)\}\}`)
+
+// renderParagraph renders a paragraph on its own: merge fields if enabled,
+// loops and conditionals that open and close within it, then its variables
+func (t *Template) renderParagraph(para *docx.Paragraph, sc *scope, opts RenderOptions) error {
+	if opts.MergeFields {
+		if err := t.replaceMergeFields(para, sc, opts); err != nil {
+			return err
+		}
+	}
+
+	text := extractParagraphText(para)
+	if blockOpenPattern.MatchString(text) && blockDepth(text) == 0 {
+		return t.renderInlineBlocks(para, sc, opts)
+	}
+	return t.replaceParagraphVariables(para, sc, opts)
+}
+
+// inlineNode is a piece of a paragraph: text, or a loop or conditional
+type inlineNode struct {
+	start, end int // Span of a text node in the paragraph text
+
+	block     string // "if" or "range"; empty for text
+	directive string // The opening directive, for range and error messages
+	branches  []inlineBranch
+}
+
+// inlineBranch is the body of a loop, or one branch of a conditional
+type inlineBranch struct {
+	condition string // Empty for {{else}} and loops
+	body      []inlineNode
+}
+
+// inlineFragment is a piece of rendered text and the run it comes from
+type inlineFragment struct {
+	run  int
+	text string
+	last bool // Ends the run, so it keeps the run's tabs, breaks and drawings
+}
+
+// renderInlineBlocks renders the blocks of a paragraph. Text keeps the
+// formatting of the run it was written in, so a directive may sit in the
+// middle of a run and a block may span runs. Blocks are only looked for in
+// the paragraph's own runs, not in its links and fields.
+func (t *Template) renderInlineBlocks(para *docx.Paragraph, sc *scope, opts RenderOptions) error {
+	// Where the text of each run lies in the paragraph text
+	var sb strings.Builder
+	spans := make([][2]int, len(para.Runs))
+	for i, run := range para.Runs {
+		spans[i][0] = sb.Len()
+		for _, t := range run.Text {
+			sb.WriteString(t.Content)
+		}
+		spans[i][1] = sb.Len()
+	}
+	text := sb.String()
+
+	p := &inlineParser{text: text, directives: inlineDirectivePattern.FindAllStringSubmatchIndex(text, -1)}
+	nodes, stop, err := p.parse(0)
+	if err != nil {
+		return err
+	}
+	if stop != nil {
+		return fmt.Errorf("unexpected %s", text[stop[0]:stop[1]])
+	}
+
+	r := &inlineRenderer{t: t, text: text, spans: spans, opts: opts}
+	if err := r.render(nodes, sc); err != nil {
+		return err
+	}
+
+	// Rebuild the runs from the fragments, merging those of the same run
+	var runs []docx.Run
+	var from []int
+	for i, f := range r.fragments {
+		orig := &para.Runs[f.run]
+		if i > 0 && r.fragments[i-1].run == f.run && !r.fragments[i-1].last {
+			run := &runs[len(runs)-1]
+			run.Text[0].Content += f.text
+			if f.last {
+				copyRunContent(run, orig)
+			}
+			continue
+		}
+
+		run := docx.Run{Props: orig.Props, Text: []docx.Text{{Content: f.text, Space: "preserve"}}}
+		if f.last {
+			copyRunContent(&run, orig)
+		}
+		if f.text == "" && len(orig.Text) == 0 {
+			run.Text = nil
+		}
+		runs = append(runs, run)
+		from = append(from, f.run)
+	}
+	para.ReplaceRuns(runs, from)
+	return nil
+}
+
+// copyRunContent copies the non-text content of a run
+func copyRunContent(dst, src *docx.Run) {
+	dst.Tab = src.Tab
+	dst.Break = src.Break
+	dst.Drawing = src.Drawing
+	dst.AlternateContent = src.AlternateContent
+	dst.Pict = src.Pict
+	dst.FldChar = src.FldChar
+	dst.InstrText = src.InstrText
+}
+
+// inlineParser builds the block tree of a paragraph from its directives
+type inlineParser struct {
+	text       string
+	directives [][]int
+	next       int
+}
+
+// parse parses nodes from offset until an {{else}} or {{end}} closing the
+// enclosing block, returning that directive, or until the end of the text
+func (p *inlineParser) parse(offset int) ([]inlineNode, []int, error) {
+	var nodes []inlineNode
+	for p.next < len(p.directives) {
+		d := p.directives[p.next]
+		p.next++
+		nodes = append(nodes, inlineNode{start: offset, end: d[0]})
+		offset = d[1]
+
+		if d[2] < 0 {
+			return nodes, d, nil // {{else}} or {{end}}
+		}
+
+		node := inlineNode{block: p.text[d[2]:d[3]], directive: p.text[d[0]:d[1]]}
+		if (node.block == "separator") != (d[4] < 0) {
+			return nil, nil, fmt.Errorf("invalid directive %s", node.directive)
+		}
+		condition := ""
+		if node.block == "if" {
+			condition = p.text[d[4]:d[5]]
+		}
+		for {
+			body, stop, err := p.parse(offset)
+			if err != nil {
+				return nil, nil, err
+			}
+			if stop == nil {
+				return nil, nil, fmt.Errorf("no matching {{end}} found for %s", node.directive)
+			}
+			node.branches = append(node.branches, inlineBranch{condition: condition, body: body})
+			offset = stop[1]
+
+			if p.text[stop[0]:stop[1]] == "{{end}}" {
+				break
+			}
+			if node.block != "if" {
+				return nil, nil, fmt.Errorf("{{else}} is not supported in %s", node.directive)
+			}
+			if condition == "" {
+				return nil, nil, fmt.Errorf("%s after {{else}} in %s", p.text[stop[0]:stop[1]], node.directive)
+			}
+			condition = ""
+			if stop[6] >= 0 {
+				condition = p.text[stop[6]:stop[7]]
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	nodes = append(nodes, inlineNode{start: offset, end: len(p.text)})
+	return nodes, nil, nil
+}
+
+// inlineRenderer renders the block tree of a paragraph into fragments
+type inlineRenderer struct {
+	t         *Template
+	text      string
+	spans     [][2]int
+	opts      RenderOptions
+	fragments []inlineFragment
+}
+
+// render renders nodes with the variables of a scope
+func (r *inlineRenderer) render(nodes []inlineNode, sc *scope) error {
+	for _, node := range nodes {
+		var err error
+		switch node.block {
+		case "":
+			err = r.renderText(node, sc)
+		case "if":
+			err = r.renderConditional(node, sc)
+		case "range":
+			err = r.renderLoop(node, sc)
+		case "separator":
+			if !sc.isLastItem() {
+				err = r.render(node.branches[0].body, sc)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderText splits a text node between the runs it spans
+func (r *inlineRenderer) renderText(node inlineNode, sc *scope) error {
+	for i, span := range r.spans {
+		if span[0] == span[1] {
+			// Runs without text, such as tabs, follow the text before them
+			if node.start <= span[0] && span[0] <= node.end {
+				r.fragments = append(r.fragments, inlineFragment{run: i, last: true})
+			}
+			continue
+		}
+
+		lo, hi := max(node.start, span[0]), min(node.end, span[1])
+		if lo >= hi {
+			continue
+		}
+		text, err := r.t.replaceVariables(r.text[lo:hi], sc, r.opts)
+		if err != nil {
+			return err
+		}
+		r.fragments = append(r.fragments, inlineFragment{run: i, text: text, last: hi == span[1]})
+	}
+	return nil
+}
+
+// renderConditional renders the first branch whose condition holds
+func (r *inlineRenderer) renderConditional(node inlineNode, sc *scope) error {
+	for _, branch := range node.branches {
+		condition, err := evaluateBranch(strings.TrimSpace(branch.condition), sc, r.opts)
+		if err != nil {
+			return err
+		}
+		if condition {
+			return r.render(branch.body, sc)
+		}
+	}
+	return nil
+}
+
+// renderLoop renders the body of a loop once per item
+func (r *inlineRenderer) renderLoop(node inlineNode, sc *scope) error {
+	matches := rangePattern.FindStringSubmatch(node.directive)
+	if len(matches) < 2 {
+		return fmt.Errorf("invalid range directive: %s", node.directive)
+	}
+	collectionName := matches[1]
+
+	items, err := loopItems(sc, collectionName, r.opts)
+	if err != nil {
+		return err
+	}
+
+	for idx, item := range items {
+		if err := r.render(node.branches[0].body, sc.loopScope(item, idx, len(items))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// locale holds how numbers, amounts and dates are written in a language
+type locale struct {
+	decimal  string // Decimal separator
+	group    string // Thousands separator
+	symbolAt string // Where the currency symbol goes: "before" (€1.00) or "after" (1,00 €)
+	months   [12]string
+	days     [7]string // From Sunday
+}
+
+var (
+	englishMonths = [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	englishDays   = [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+)
+
+// english is the locale used when RenderOptions.Locale is empty
+var english = &locale{".", ",", "before", englishMonths, englishDays}
+
+// locales maps lower-case language tags to their locale. Tags with a region,
+// such as de-AT, use the language's locale unless they are listed.
+var locales = map[string]*locale{
+	"en":    english,
+	"en-gb": english,
+	"de": {",", ".", "after",
+		[12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		[7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"}},
+	"de-ch": {".", "\u2019", "before",
+		[12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		[7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"}},
+	"fr": {",", "\u202f", "after",
+		[12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		[7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"}},
+	"es": {",", ".", "after",
+		[12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		[7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"}},
+	"es-mx": {".", ",", "before",
+		[12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		[7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"}},
+	"it": {",", ".", "after",
+		[12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		[7]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"}},
+	"pt": {",", "\u00a0", "after",
+		[12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		[7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"}},
+	"pt-br": {",", ".", "before",
+		[12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		[7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"}},
+	"nl": {",", ".", "before",
+		[12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+		[7]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"}},
+}
+
+// findLocale returns the locale of a language tag such as "de" or "pt-BR",
+// English when tag is empty
+func findLocale(tag string) (*locale, error) {
+	if tag == "" {
+		return english, nil
+	}
+	tag = strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+	if l, ok := locales[tag]; ok {
+		return l, nil
+	}
+	lang, _, _ := strings.Cut(tag, "-")
+	if l, ok := locales[lang]; ok {
+		return l, nil
+	}
+	return nil, fmt.Errorf("unsupported locale %q", tag)
+}
+
+// formatNumber formats a number with thousands separators and a fixed
+// number of decimals, such as 1,234.50 in English or 1.234,50 in German
+func (l *locale) formatNumber(n float64, decimals int) string {
+	return l.localize(formatNumber(n, decimals))
+}
+
+// formatCurrency formats an amount such as -$1,234.50, or -1.234,50 € in
+// locales writing the symbol after the amount
+func (l *locale) formatCurrency(amount float64, code string) string {
+	if l.symbolAt != "after" {
+		return l.localize(formatCurrency(amount, code))
+	}
+	symbol, decimals := code, 2
+	if c, ok := currencies[code]; ok {
+		symbol, decimals = c.symbol, c.decimals
+	}
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return sign + l.formatNumber(amount, decimals) + "\u00a0" + symbol
+}
+
+// localize swaps the English separators of a formatted number for the locale's
+func (l *locale) localize(s string) string {
+	if l.decimal == "." && l.group == "," {
+		return s
+	}
+	return strings.NewReplacer(",", l.group, ".", l.decimal).Replace(s)
+}
+
+// dateNames are the parts of Go date layouts naming months and days,
+// longest first
+var dateNames = []string{"January", "Monday", "Jan", "Mon"}
+
+// formatDate formats a date with a Go layout, writing the names of months
+// and days in the locale's language
+func (l *locale) formatDate(date time.Time, layout string) string {
+	if l.months == englishMonths {
+		return date.Format(layout)
+	}
+	var b strings.Builder
+	for layout != "" {
+		i, name := len(layout), ""
+		for _, n := range dateNames {
+			if j := strings.Index(layout, n); j >= 0 && (j < i || j == i && len(n) > len(name)) {
+				i, name = j, n
+			}
+		}
+		b.WriteString(date.Format(layout[:i]))
+		switch name {
+		case "January":
+			b.WriteString(l.months[date.Month()-1])
+		case "Jan":
+			b.WriteString(abbreviate(l.months[date.Month()-1]))
+		case "Monday":
+			b.WriteString(l.days[date.Weekday()])
+		case "Mon":
+			b.WriteString(abbreviate(l.days[date.Weekday()]))
+		}
+		layout = layout[i+len(name):]
+	}
+	return b.String()
+}
+
+// abbreviate shortens a month or day name to its first three letters
+func abbreviate(name string) string {
+	if runes := []rune(name); len(runes) > 3 {
+		return string(runes[:3])
+	}
+	return name
+}
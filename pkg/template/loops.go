@@ -4,171 +4,71 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
-	"strings"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/docx"
 )
 
-// processLoop processes a {{range .Items}}...{{end}} directive
-func (t *Template) processLoop(doc *docx.Document, startIdx int, data Data, opts RenderOptions) ([]docx.Paragraph, int, error) {
+// rangePattern matches {{range .Items}} and dotted paths such as {{range .Item.Lines}}
+var rangePattern = regexp.MustCompile(`\{\{range\s+\.([a-zA-Z0-9_]+(?:\.[a-zA-Z0-9_]+)*)\}\}`)
+
+// processLoop processes a {{range .Items}}...{{end}} directive. paras runs
+// from the range paragraph to its {{end}}; the body may hold nested loops
+// and conditionals, rendered with a scope per item.
+func (t *Template) processLoop(paras []docx.Paragraph, sc *scope, opts RenderOptions) ([]docx.Paragraph, error) {
 	result := []docx.Paragraph{}
 
 	// Find the range directive
-	startText := extractParagraphText(&doc.Body.Paragraphs[startIdx])
-	rangePattern := regexp.MustCompile(`\{\{range\s+\.([a-zA-Z0-9_]+)\}\}`)
+	startText := extractParagraphText(&paras[0])
 	matches := rangePattern.FindStringSubmatch(startText)
 
 	if len(matches) < 2 {
-		return nil, 0, fmt.Errorf("invalid range directive: %s", startText)
+		return nil, fmt.Errorf("invalid range directive: %s", startText)
 	}
 
 	collectionName := matches[1]
 
-	// Get the collection from data
-	collection, err := getValueFromData(data, collectionName)
+	// Get the items from the innermost scope defining the collection
+	collectionSlice, err := loopItems(sc, collectionName, opts)
 	if err != nil {
-		if opts.StrictMode {
-			return nil, 0, fmt.Errorf("collection %s not found", collectionName)
-		}
-		return result, 1, nil // Return empty result
-	}
-
-	// Find the end directive
-	endIdx := -1
-	for i := startIdx + 1; i < len(doc.Body.Paragraphs); i++ {
-		text := extractParagraphText(&doc.Body.Paragraphs[i])
-		if strings.Contains(text, "{{end}}") {
-			endIdx = i
-			break
-		}
-	}
-
-	if endIdx == -1 {
-		return nil, 0, fmt.Errorf("no matching {{end}} found for {{range}}")
+		return nil, err
 	}
 
 	// Get template paragraphs (between start and end)
-	templateParas := doc.Body.Paragraphs[startIdx+1 : endIdx]
+	templateParas := paras[1 : len(paras)-1]
 
-	// Convert collection to slice
-	collectionSlice, err := toSlice(collection)
-	if err != nil {
-		return nil, 0, fmt.Errorf("collection %s is not iterable: %w", collectionName, err)
-	}
-
-	// Iterate over collection
+	// Iterate over collection, rendering the body with the item in scope
 	for idx, item := range collectionSlice {
-		// Create data context for this iteration
-		itemData := Data{
-			"Index": idx,
-			"Item":  item,
-		}
-
-		// Merge with parent data
-		for k, v := range data {
-			if k != collectionName {
-				itemData[k] = v
-			}
-		}
-
-		// Render each template paragraph with item data
-		for _, templatePara := range templateParas {
-			// Clone paragraph
-			newPara := cloneParagraph(&templatePara)
-
-			// Replace {{.Item.Field}} with actual values
-			if err := t.replaceLoopVariables(&newPara, item, opts); err != nil {
-				if opts.StrictMode {
-					return nil, 0, err
-				}
-			}
-
-			// Also replace {{.Index}}
-			if err := t.replaceParagraphVariables(&newPara, itemData, opts); err != nil {
-				if opts.StrictMode {
-					return nil, 0, err
-				}
-			}
-
-			result = append(result, newPara)
+		rendered, err := t.renderParagraphs(templateParas, sc.loopScope(item, idx, len(collectionSlice)), opts)
+		if err != nil {
+			return nil, err
 		}
+		result = append(result, rendered...)
 	}
 
-	// Return result and number of paragraphs consumed (start + templates + end)
-	consumed := endIdx - startIdx + 1
-	return result, consumed, nil
+	return result, nil
 }
 
-// replaceLoopVariables replaces {{.Item.Field}} variables
-func (t *Template) replaceLoopVariables(para *docx.Paragraph, item interface{}, opts RenderOptions) error {
-	itemPattern := regexp.MustCompile(`\{\{\.Item\.([a-zA-Z0-9_]+)\}\}`)
-
-	for i := range para.Runs {
-		for j := range para.Runs[i].Text {
-			text := &para.Runs[i].Text[j]
-
-			// Find all item variables
-			matches := itemPattern.FindAllStringSubmatch(text.Content, -1)
-
-			for _, match := range matches {
-				if len(match) < 2 {
-					continue
-				}
-
-				fieldName := match[1]
-				placeholder := match[0]
-
-				// Get field value from item
-				value, err := getFieldValue(item, fieldName)
-				if err != nil {
-					if opts.StrictMode {
-						return fmt.Errorf("field %s not found in item", fieldName)
-					}
-					value = opts.DefaultValue
-				}
-
-				// Replace in text
-				text.Content = strings.ReplaceAll(text.Content, placeholder, fmt.Sprint(value))
-			}
-		}
-	}
-
-	return nil
-}
-
-// getFieldValue gets a field value from a struct or map
-func getFieldValue(item interface{}, fieldName string) (interface{}, error) {
-	// If item is a map
-	if m, ok := item.(map[string]interface{}); ok {
-		if val, exists := m[fieldName]; exists {
-			return val, nil
-		}
-		return nil, fmt.Errorf("field %s not found", fieldName)
-	}
-
-	// If item is Data
-	if d, ok := item.(Data); ok {
-		if val, exists := d[fieldName]; exists {
-			return val, nil
+// loopItems returns the items of the collection a loop ranges over. A
+// missing collection renders nothing unless in strict mode.
+func loopItems(sc *scope, collectionName string, opts RenderOptions) ([]interface{}, error) {
+	collection, err := sc.lookup(collectionName)
+	if err != nil {
+		reportLookup(opts.report, "{{range ."+collectionName+"}}", "collection", collectionName, err)
+		if opts.StrictMode {
+			return nil, &ErrTemplateVariableMissing{Name: collectionName, Use: "collection"}
 		}
-		return nil, fmt.Errorf("field %s not found", fieldName)
+		return nil, nil
 	}
 
-	// Use reflection for structs
-	rv := reflect.ValueOf(item)
-	if rv.Kind() == reflect.Ptr {
-		rv = rv.Elem()
-	}
-
-	if rv.Kind() == reflect.Struct {
-		field := rv.FieldByName(fieldName)
-		if field.IsValid() {
-			return field.Interface(), nil
+	items, err := toSlice(collection)
+	if err != nil {
+		opts.report.add(IssueTypeMismatch, "{{range ."+collectionName+"}}", "collection %s is a %T, not a list", collectionName, collection)
+		if opts.report != nil {
+			return nil, nil
 		}
-		return nil, fmt.Errorf("field %s not found", fieldName)
+		return nil, fmt.Errorf("collection %s is not iterable: %w", collectionName, err)
 	}
-
-	return nil, fmt.Errorf("item is not a struct or map")
+	return items, nil
 }
 
 // toSlice converts various types to a slice
@@ -202,19 +102,18 @@ func cloneParagraph(p *docx.Paragraph) docx.Paragraph {
 
 		// Copy text
 		copy(newRun.Text, run.Text)
+		newRun.FldChar = run.FldChar
+		newRun.InstrText = run.InstrText
 
 		// Copy properties
 		if run.Props != nil {
-			newRun.Props = &docx.RProps{
-				Bold:   run.Props.Bold,
-				Italic: run.Props.Italic,
-				Size:   run.Props.Size,
-				Color:  run.Props.Color,
-			}
+			props := *run.Props
+			newRun.Props = &props
 		}
 
 		newPara.Runs[i] = newRun
 	}
+	newPara.Fields = p.Fields
 
 	// Copy properties
 	if p.Props != nil {
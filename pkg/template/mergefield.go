@@ -0,0 +1,217 @@
+package template
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// mergeField is a parsed MERGEFIELD instruction, such as
+// MERGEFIELD "First Name" \b "Dear " \* Upper
+type mergeField struct {
+	name   string
+	before string // \b text, written before a non-empty value
+	after  string // \f text, written after a non-empty value
+	format string // \* format switch: Upper, Lower, FirstCap or Caps
+}
+
+// parseMergeField parses a field instruction, reporting false if it is not a MERGEFIELD
+func parseMergeField(instr string) (mergeField, bool) {
+	tokens := fieldTokens(instr)
+	if len(tokens) < 2 || !strings.EqualFold(tokens[0], "MERGEFIELD") {
+		return mergeField{}, false
+	}
+
+	field := mergeField{name: tokens[1]}
+	for i := 2; i+1 < len(tokens); i++ {
+		arg := tokens[i+1]
+		switch strings.ToLower(tokens[i]) {
+		case `\b`:
+			field.before = arg
+		case `\f`:
+			field.after = arg
+		case `\*`:
+			if !strings.EqualFold(arg, "MERGEFORMAT") {
+				field.format = arg
+			}
+		default:
+			continue
+		}
+		i++ // The switch's argument is consumed
+	}
+	return field, true
+}
+
+// fieldTokens splits a field instruction into words and quoted strings
+func fieldTokens(instr string) []string {
+	var tokens []string
+	var current strings.Builder
+	inQuotes, started := false, false
+	for _, r := range instr {
+		switch {
+		case r == '"':
+			if inQuotes {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				started = false
+			}
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			if started {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// value renders the field for the data in scope. A missing value renders as
+// the default value and, in strict mode, fails.
+func (f mergeField) value(sc *scope, opts RenderOptions) (string, error) {
+	value, err := sc.lookup(f.name)
+	if err != nil {
+		placeholder := "MERGEFIELD " + f.name
+		reportLookup(opts.report, placeholder, "merge field", f.name, err)
+		if opts.StrictMode {
+			return "", &ErrTemplateVariableMissing{Name: f.name, Use: "merge field"}
+		}
+		return opts.DefaultValue, nil
+	}
+
+	text := toString(value)
+	switch strings.ToLower(f.format) {
+	case "upper":
+		text = strings.ToUpper(text)
+	case "lower":
+		text = strings.ToLower(text)
+	case "firstcap":
+		text = capitalize(text)
+	case "caps":
+		words := strings.Fields(text)
+		for i, w := range words {
+			words[i] = capitalize(w)
+		}
+		text = strings.Join(words, " ")
+	}
+	if text != "" {
+		text = f.before + text + f.after
+	}
+	return text, nil
+}
+
+// capitalize upper-cases the first letter of s
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// replaceMergeFields replaces the MERGEFIELD fields of a paragraph, simple
+// or complex, with their values as plain text in the formatting of the
+// field's result. Other fields are left alone.
+func (t *Template) replaceMergeFields(para *docx.Paragraph, sc *scope, opts RenderOptions) error {
+	runs, from, err := replaceComplexMergeFields(para.Runs, sc, opts)
+	if err != nil {
+		return err
+	}
+	// Loops share the fields of the paragraphs they copy
+	para.Fields = slices.Clone(para.Fields)
+	para.ReplaceRuns(runs, from)
+
+	for i := len(para.Fields) - 1; i >= 0; i-- {
+		f := para.Fields[i]
+		field, ok := parseMergeField(f.Instr)
+		if !ok {
+			continue
+		}
+		text, err := field.value(sc, opts)
+		if err != nil {
+			return err
+		}
+		para.ReplaceField(i, mergeFieldRun(f.Runs, text))
+	}
+	return nil
+}
+
+// replaceComplexMergeFields replaces the runs of each complex MERGEFIELD,
+// from its begin to its end field character, with a run of its value.
+// from holds the index of the run each new run was built from.
+func replaceComplexMergeFields(runs []docx.Run, sc *scope, opts RenderOptions) (result []docx.Run, from []int, err error) {
+	for i := 0; i < len(runs); i++ {
+		if runs[i].FldChar == nil || runs[i].FldChar.Type != "begin" {
+			result = append(result, runs[i])
+			from = append(from, i)
+			continue
+		}
+
+		// Find the instruction, the result and the matching end, skipping nested fields
+		var instr strings.Builder
+		separate, end, depth := -1, -1, 0
+		for j := i; j < len(runs) && end < 0; j++ {
+			if c := runs[j].FldChar; c != nil {
+				switch c.Type {
+				case "begin":
+					depth++
+				case "separate":
+					if depth == 1 && separate < 0 {
+						separate = j
+					}
+				case "end":
+					if depth--; depth == 0 {
+						end = j
+					}
+				}
+			}
+			if depth == 1 && separate < 0 {
+				for _, it := range runs[j].InstrText {
+					instr.WriteString(it.Content)
+				}
+			}
+		}
+
+		field, ok := parseMergeField(instr.String())
+		if !ok || end < 0 {
+			result = append(result, runs[i])
+			from = append(from, i)
+			continue
+		}
+		text, err := field.value(sc, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		var shown []docx.Run
+		if separate >= 0 {
+			shown = runs[separate+1 : end]
+		}
+		result = append(result, mergeFieldRun(shown, text))
+		from = append(from, i)
+		i = end
+	}
+	return result, from, nil
+}
+
+// mergeFieldRun is a run of text formatted as the first text run of a
+// field's result, such as «Name»
+func mergeFieldRun(shown []docx.Run, text string) docx.Run {
+	run := docx.Run{Text: []docx.Text{{Space: "preserve", Content: text}}}
+	for _, r := range shown {
+		if len(r.Text) > 0 {
+			run.Props = r.Props
+			break
+		}
+	}
+	return run
+}
@@ -0,0 +1,103 @@
+package template
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// complexMergeField returns the runs Word writes for a MERGEFIELD: the
+// instruction between begin and separate, then the «Name» result
+func complexMergeField(instr, shown string, props *docx.RProps) []docx.Run {
+	return []docx.Run{
+		{FldChar: &docx.FldChar{Type: "begin"}},
+		{InstrText: []docx.InstrText{{Space: "preserve", Content: instr}}},
+		{FldChar: &docx.FldChar{Type: "separate"}},
+		{Props: props, Text: []docx.Text{{Content: shown}}},
+		{FldChar: &docx.FldChar{Type: "end"}},
+	}
+}
+
+func TestMergeFields(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("Dear ")
+	bold := &docx.RProps{Bold: &docx.Bold{}}
+	p := &doc.Body.Paragraphs[0]
+	p.Runs = append(p.Runs, complexMergeField(` MERGEFIELD "First Name" \* Upper \* MERGEFORMAT `, "«First Name»", bold)...)
+	p.Runs = append(p.Runs, docx.Run{Text: []docx.Text{{Content: ","}}})
+
+	doc.AddParagraph("")
+	doc.Body.Paragraphs[1].Fields = []docx.SimpleField{
+		{Instr: ` MERGEFIELD City \b "in " \f "." `, Runs: []docx.Run{{Text: []docx.Text{{Content: "«City»"}}}}},
+		{Instr: ` PAGE `, Runs: []docx.Run{{Text: []docx.Text{{Content: "1"}}}}},
+	}
+	doc.AddParagraph("Welcome to ")
+	greeting := &doc.Body.Paragraphs[2]
+	greeting.Runs = append(greeting.Runs, docx.Run{Text: []docx.Text{{Content: ", ada."}}})
+	greeting.Fields = []docx.SimpleField{{Position: 1, Instr: ` MERGEFIELD City `, Runs: []docx.Run{{Text: []docx.Text{{Content: "«City»"}}}}}}
+
+	tmpl := New(doc)
+	opts := DefaultOptions()
+	opts.MergeFields = true
+	result, err := tmpl.Render(Data{"First Name": "ada", "City": "London"}, opts)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	if text, _ := result.GetParagraphText(0); text != "Dear ADA," {
+		t.Errorf("Expected %q, got %q", "Dear ADA,", text)
+	}
+	if runs := result.Body.Paragraphs[0].Runs; len(runs) != 3 || runs[1].Props == nil || runs[1].Props.Bold == nil {
+		t.Errorf("Expected the value to keep the field's formatting, got %+v", runs)
+	}
+
+	cityPara := result.Body.Paragraphs[1]
+	if text := extractParagraphText(&cityPara); text != "in London.1" {
+		t.Errorf("Expected %q, got %q", "in London.1", text)
+	}
+	if len(cityPara.Fields) != 1 || cityPara.Fields[0].Instr != " PAGE " {
+		t.Errorf("Expected other fields to be kept, got %+v", cityPara.Fields)
+	}
+	if text, _ := result.GetParagraphText(2); text != "Welcome to London, ada." {
+		t.Errorf("Expected the value in the field's place, got %q", text)
+	}
+
+	// Without the option the fields are left alone
+	plain, err := tmpl.Render(Data{"First Name": "ada"}, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if text, _ := plain.GetParagraphText(0); text != "Dear «First Name»," {
+		t.Errorf("Expected the field untouched, got %q", text)
+	}
+}
+
+func TestMergeFieldsMissing(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("Hello ")
+	doc.Body.Paragraphs[0].Runs = append(doc.Body.Paragraphs[0].Runs, complexMergeField("MERGEFIELD Name", "«Name»", nil)...)
+	tmpl := New(doc)
+
+	opts := DefaultOptions()
+	opts.MergeFields = true
+	opts.DefaultValue = "friend"
+	result, err := tmpl.Render(Data{}, opts)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if text, _ := result.GetParagraphText(0); text != "Hello friend" {
+		t.Errorf("Expected the default value, got %q", text)
+	}
+
+	opts.StrictMode = true
+	_, err = tmpl.Render(Data{}, opts)
+	var missing *ErrTemplateVariableMissing
+	if !errors.As(err, &missing) || missing.Name != "Name" || missing.Use != "merge field" {
+		t.Errorf("Expected a missing merge field error, got %v", err)
+	}
+
+	if report := tmpl.Validate(Data{}); report.Count(IssueMissingVariable) != 1 {
+		t.Errorf("Expected Validate to report the merge field, got %+v", report.Issues)
+	}
+}
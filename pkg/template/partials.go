@@ -0,0 +1,93 @@
+package template
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// includePattern matches a paragraph holding only {{include "name"}}
+var includePattern = regexp.MustCompile(`^\s*\{\{include\s+["“”]([^"“”]+)["“”]\s*\}\}\s*$`)
+
+// maxIncludeDepth limits how deeply partials may include other partials
+const maxIncludeDepth = 10
+
+// AddPartial registers a document that {{include "name"}} inserts, such as
+// a signature block or terms and conditions maintained in one place
+func (t *Template) AddPartial(name string, doc *docx.Document) {
+	if t.partials == nil {
+		t.partials = make(map[string]*docx.Document)
+	}
+	t.partials[name] = doc
+}
+
+// partial returns the partial with the given name. Names not registered
+// with AddPartial are loaded as .docx files, relative to the template's
+// directory when it was loaded from a file; opened reports those, which the
+// caller closes.
+func (t *Template) partial(name string) (doc *docx.Document, opened bool, err error) {
+	if doc, ok := t.partials[name]; ok {
+		return doc, false, nil
+	}
+	if !strings.EqualFold(filepath.Ext(name), ".docx") {
+		return nil, false, fmt.Errorf("partial %q not found", name)
+	}
+
+	path := name
+	if !filepath.IsAbs(path) && t.filePath != "" {
+		path = filepath.Join(filepath.Dir(t.filePath), path)
+	}
+	doc, err = docx.Open(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load partial %q: %w", name, err)
+	}
+	return doc, true, nil
+}
+
+// expandIncludes replaces each {{include}} paragraph of the body with the
+// content of the partial, along with its styles, numbering and images, so
+// that the partial is rendered with the data like the rest of the template.
+// stack holds the partials being expanded, to catch includes that loop.
+func (t *Template) expandIncludes(doc *docx.Document, stack []string) error {
+	for i := 0; i < len(doc.Body.Paragraphs); i++ {
+		matches := includePattern.FindStringSubmatch(extractParagraphText(&doc.Body.Paragraphs[i]))
+		if matches == nil {
+			continue
+		}
+		name := matches[1]
+
+		for _, parent := range stack {
+			if parent == name {
+				return fmt.Errorf("partial %q includes itself", name)
+			}
+		}
+		if len(stack) >= maxIncludeDepth {
+			return fmt.Errorf("partials nested more than %d levels deep", maxIncludeDepth)
+		}
+
+		partial, opened, err := t.partial(name)
+		if err != nil {
+			return err
+		}
+		if opened {
+			defer partial.Close()
+		}
+
+		// Expand the partial's own includes on a copy, leaving it reusable
+		expanded := partial.Clone()
+		if err := t.expandIncludes(expanded, append(stack, name)); err != nil {
+			return err
+		}
+
+		if err := doc.InsertDocument(i, expanded); err != nil {
+			return fmt.Errorf("failed to include partial %q: %w", name, err)
+		}
+		i += len(expanded.Body.Paragraphs)
+		doc.Body.SpliceParagraphs(i, 1)
+		i--
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// FetchOptions configures how FetchData requests data
+type FetchOptions struct {
+	// Headers are sent with the request, e.g. {"X-Api-Key": "..."}
+	Headers map[string]string
+
+	// BearerToken, when set, is sent as an Authorization: Bearer header
+	BearerToken string
+
+	// Username and Password, when Username is set, are sent as basic auth
+	Username string
+	Password string
+
+	// Timeout limits the whole request; none when zero
+	Timeout time.Duration
+
+	// MaxSize is the largest response read, in bytes; no limit when zero
+	MaxSize int64
+
+	// Client sends the request; http.DefaultClient when nil
+	Client *http.Client
+}
+
+// DefaultFetchOptions returns default fetch options
+func DefaultFetchOptions() FetchOptions {
+	return FetchOptions{
+		Timeout: 30 * time.Second,
+		MaxSize: 32 << 20,
+	}
+}
+
+// IsURL reports whether a data path is an http or https URL
+func IsURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// FetchData gets JSON or YAML data from an http or https URL, such as a
+// REST endpoint. Responses other than 2xx are errors.
+func FetchData(ctx context.Context, url string, opts FetchOptions) (Data, error) {
+	if !IsURL(url) {
+		return nil, fmt.Errorf("not an http or https URL: %s", url)
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json, application/yaml;q=0.9, */*;q=0.5")
+	for name, value := range opts.Headers {
+		req.Header.Set(name, value)
+	}
+	if opts.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.BearerToken)
+	}
+	if opts.Username != "" {
+		req.SetBasicAuth(opts.Username, opts.Password)
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch data: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to fetch data: %s returned %s", url, resp.Status)
+	}
+
+	var body io.Reader = resp.Body
+	if opts.MaxSize > 0 {
+		body = io.LimitReader(resp.Body, opts.MaxSize+1)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if opts.MaxSize > 0 && int64(len(content)) > opts.MaxSize {
+		return nil, fmt.Errorf("response from %s is larger than %d bytes", url, opts.MaxSize)
+	}
+	return ParseData(content)
+}
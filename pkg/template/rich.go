@@ -0,0 +1,143 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// Glyphs booleans render as with RichValues and the checkbox filter
+const (
+	checkedBox   = "☑"
+	uncheckedBox = "☐"
+)
+
+// wholeVariablePattern matches a paragraph holding nothing but a variable
+var wholeVariablePattern = regexp.MustCompile(`^\s*\{\{\.?([a-zA-Z0-9_]+(?:\.[a-zA-Z0-9_]+)*)\s*\}\}\s*$`)
+
+// richBlock is what a paragraph holding nothing but a list or map variable
+// renders to with RichValues
+type richBlock struct {
+	items []string    // List items, one bulleted paragraph each
+	rows  [][2]string // Map entries as key and value, sorted by key
+}
+
+// richBlock returns the list or map a paragraph holding nothing but that
+// variable renders, or nil when RichValues is off or the paragraph is
+// anything else
+func (t *Template) richBlock(para *docx.Paragraph, sc *scope, opts RenderOptions) *richBlock {
+	if !opts.RichValues {
+		return nil
+	}
+	m := wholeVariablePattern.FindStringSubmatch(extractParagraphText(para))
+	if m == nil || isKeyword(m[1]) {
+		return nil
+	}
+	value, err := sc.lookup(m[1])
+	if err != nil || value == nil {
+		return nil
+	}
+
+	if items, ok := listItems(value); ok {
+		block := &richBlock{}
+		for _, item := range items {
+			block.items = append(block.items, richText(item))
+		}
+		return block
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Map {
+		block := &richBlock{}
+		for _, k := range rv.MapKeys() {
+			block.rows = append(block.rows, [2]string{fmt.Sprint(k.Interface()), richText(rv.MapIndex(k).Interface())})
+		}
+		sort.Slice(block.rows, func(i, j int) bool { return block.rows[i][0] < block.rows[j][0] })
+		return block
+	}
+	return nil
+}
+
+// paragraphs renders the block as paragraphs formatted like para: a
+// bulleted paragraph per list item, or a "Key: Value" paragraph per map
+// entry where a table can't go
+func (b *richBlock) paragraphs(para *docx.Paragraph) []docx.Paragraph {
+	var paras []docx.Paragraph
+	for _, item := range b.items {
+		paras = append(paras, paragraphWithText(para, "• "+item))
+	}
+	for _, row := range b.rows {
+		paras = append(paras, paragraphWithText(para, row[0]+": "+row[1]))
+	}
+	return paras
+}
+
+// table renders the map entries of the block as a two-column table
+func (b *richBlock) table() docx.Table {
+	table := docx.New().AddTable(len(b.rows), 2)
+	for i, row := range b.rows {
+		_ = table.SetCellText(i, 0, row[0], docx.WithBold())
+		_ = table.SetCellText(i, 1, row[1])
+	}
+	return *table
+}
+
+// paragraphWithText returns a copy of para holding text in the formatting
+// of its first run
+func paragraphWithText(para *docx.Paragraph, text string) docx.Paragraph {
+	p := cloneParagraph(para)
+	run := docx.Run{Text: []docx.Text{{Space: "preserve", Content: text}}}
+	if len(p.Runs) > 0 {
+		run.Props = p.Runs[0].Props
+	}
+	p.Runs = []docx.Run{run}
+	return p
+}
+
+// insertTable inserts a table after the body paragraph at index, before
+// the tables and content controls already there
+func insertTable(body *docx.Body, index int, table docx.Table) {
+	table.Position = index + 1
+	at := len(body.Tables)
+	for i := range body.Tables {
+		if body.Tables[i].Position > index {
+			at = i
+			break
+		}
+	}
+	body.Tables = append(body.Tables[:at], append([]docx.Table{table}, body.Tables[at:]...)...)
+}
+
+// richText formats a value inline for RichValues: booleans as checkbox
+// glyphs and lists as their items separated by commas
+func richText(value interface{}) string {
+	if b, ok := value.(bool); ok {
+		if b {
+			return checkedBox
+		}
+		return uncheckedBox
+	}
+	if items, ok := listItems(value); ok {
+		texts := make([]string, len(items))
+		for i, item := range items {
+			texts[i] = richText(item)
+		}
+		return strings.Join(texts, ", ")
+	}
+	return toString(value)
+}
+
+// listItems returns the items of a slice or array other than []byte
+func listItems(value interface{}) ([]interface{}, bool) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
@@ -0,0 +1,320 @@
+package template
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// jsonSchemaVersion is the JSON Schema draft GetSchema targets
+const jsonSchemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema describes the data a template expects, as a JSON Schema
+type Schema struct {
+	Type       string             `json:"type"`
+	Format     string             `json:"format,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+}
+
+// JSON returns the schema as an indented JSON Schema document
+func (s *Schema) JSON() ([]byte, error) {
+	doc := struct {
+		Version string `json:"$schema"`
+		*Schema
+	}{jsonSchemaVersion, s}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// Skeleton returns example data matching the schema, with empty strings,
+// zeros, false and a single item per list, to fill in and save as JSON or YAML
+func (s *Schema) Skeleton() interface{} {
+	switch s.Type {
+	case "object":
+		data := make(map[string]interface{}, len(s.Properties))
+		for name, prop := range s.Properties {
+			data[name] = prop.Skeleton()
+		}
+		return data
+	case "array":
+		return []interface{}{s.Items.Skeleton()}
+	case "number":
+		return 0
+	case "boolean":
+		return false
+	default:
+		return ""
+	}
+}
+
+// GetSchema infers the structure of the data the template expects: the
+// variables it uses, dotted paths as nested objects, collections ranged over
+// as lists of the fields their items use, values only tested by {{if}} as
+// booleans, and values compared to numbers or formatted with number filters
+// as numbers. Partials are included.
+func (t *Template) GetSchema() *Schema {
+	doc := t.doc.Clone()
+	if err := t.expandIncludes(doc, nil); err != nil {
+		doc = t.doc
+	}
+
+	root := &Schema{Type: "object"}
+	inf := &schemaInference{root: root}
+	for i := range doc.Body.Paragraphs {
+		inf.paragraph(&doc.Body.Paragraphs[i])
+	}
+
+	for i := range doc.Body.Tables {
+		inf.loops = nil
+		for _, row := range doc.Body.Tables[i].Rows {
+			// A row loop holding only its directive applies to the next row
+			carried := len(inf.loops) > 0
+			for c := range row.Cells {
+				for p := range row.Cells[c].Content {
+					inf.paragraph(&row.Cells[c].Content[p])
+				}
+			}
+			if carried || strings.TrimSpace(rowLoopDirectivePattern.ReplaceAllString(rowText(&row), "")) != "" {
+				inf.loops = nil
+			}
+			inf.blocks = nil
+		}
+	}
+
+	finishSchema(root)
+	return root
+}
+
+// rowLoopDirectivePattern matches the directives of a row loop
+var rowLoopDirectivePattern = regexp.MustCompile(`\{\{(?:range\s[^{}]*|end)\}\}`)
+
+// schemaInference tracks the loops enclosing the directives being read
+type schemaInference struct {
+	root   *Schema
+	loops  []*Schema // Item schema of each enclosing loop
+	blocks []bool    // Whether each open block is a loop
+}
+
+// paragraph records the variables used by the directives of a paragraph
+func (inf *schemaInference) paragraph(para *docx.Paragraph) {
+	for _, directive := range directivePattern.FindAllString(extractParagraphText(para), -1) {
+		inner := strings.TrimSpace(directive[2 : len(directive)-2])
+		switch {
+		case strings.HasPrefix(inner, "range "):
+			var item *Schema
+			if m := rangePattern.FindStringSubmatch(directive); m != nil {
+				if list := inf.resolve(m[1]); list != nil {
+					setType(list, "array")
+					if list.Items == nil {
+						list.Items = &Schema{}
+					}
+					item = list.Items
+				}
+			}
+			if item == nil {
+				item = &Schema{} // Not from the data; record its fields nowhere
+			}
+			inf.loops = append(inf.loops, item)
+			inf.blocks = append(inf.blocks, true)
+		case strings.HasPrefix(inner, "if "):
+			inf.expression(inner[3:])
+			inf.blocks = append(inf.blocks, false)
+		case inner == "separator":
+			inf.blocks = append(inf.blocks, false)
+		case strings.HasPrefix(inner, "else if "):
+			inf.expression(inner[len("else if "):])
+		case inner == "end":
+			if n := len(inf.blocks); n > 0 {
+				if inf.blocks[n-1] && len(inf.loops) > 0 {
+					inf.loops = inf.loops[:len(inf.loops)-1]
+				}
+				inf.blocks = inf.blocks[:n-1]
+			}
+		default:
+			m := variablePattern.FindStringSubmatch(directive)
+			if m == nil || isKeyword(m[1]) {
+				continue
+			}
+			inf.pipeline(m[1], m[2])
+		}
+	}
+}
+
+// pipeline records a variable and the type its filters expect
+func (inf *schemaInference) pipeline(path, pipeline string) {
+	node := inf.resolve(path)
+	if node == nil {
+		return
+	}
+	useType(node, "string")
+
+	tokens, err := tokenizeExpression(pipeline)
+	if pipeline == "" || err != nil {
+		return
+	}
+	for i, tok := range tokens {
+		switch {
+		case tok.kind == '|' && i+1 < len(tokens):
+			// The first filter sees the variable itself
+			if i == 0 {
+				switch tokens[i+1].text {
+				case "currency", "number":
+					useType(node, "number")
+				case "date":
+					node.Format = "date"
+				}
+			}
+		case tok.kind == 'w' && strings.HasPrefix(tok.text, "."):
+			if arg := inf.resolve(tok.text[1:]); arg != nil {
+				useType(arg, "string")
+			}
+		}
+	}
+}
+
+// expression records the variables of a condition. Those tested on their
+// own or combined with and, or and not are booleans; those compared are
+// numbers or strings, after the literals they are compared with.
+func (inf *schemaInference) expression(expr string) {
+	tokens, err := tokenizeExpression(expr)
+	if err != nil {
+		return
+	}
+
+	// The function each parenthesized call applies
+	funcs := []string{""}
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.kind == '(':
+			funcs = append(funcs, "")
+		case tok.kind == ')':
+			if len(funcs) > 1 {
+				funcs = funcs[:len(funcs)-1]
+			}
+		case tok.kind == 'w' && exprFuncs[tok.text] != nil && funcs[len(funcs)-1] == "":
+			funcs[len(funcs)-1] = tok.text
+		case tok.kind == 'w' && strings.HasPrefix(tok.text, "."):
+			node := inf.resolve(tok.text[1:])
+			if node == nil {
+				continue
+			}
+			switch fn := funcs[len(funcs)-1]; fn {
+			case "", "and", "or", "not":
+				useType(node, "boolean")
+			default:
+				useType(node, comparedType(fn, tokens, i))
+			}
+		}
+	}
+}
+
+// comparedType returns the type of a variable compared by fn, from the
+// literals among the other arguments of the call
+func comparedType(fn string, tokens []exprToken, index int) string {
+	for _, step := range []int{1, -1} {
+		depth := 0
+		for i := index + step; i >= 0 && i < len(tokens) && depth >= 0; i += step {
+			switch tokens[i].kind {
+			case '(', ')':
+				if (tokens[i].kind == '(') == (step > 0) {
+					depth++
+				} else {
+					depth--
+				}
+			case 's':
+				if depth == 0 {
+					return "string"
+				}
+			case 'w':
+				if _, err := strconv.ParseFloat(tokens[i].text, 64); err == nil && depth == 0 {
+					return "number"
+				}
+			}
+		}
+	}
+	if fn == "eq" || fn == "ne" {
+		return "string"
+	}
+	return "number"
+}
+
+// resolve returns the schema of a variable path, creating it as needed.
+// Item and the loop helpers such as Index refer to the innermost loop and
+// Parent to the one enclosing it, as when rendering; other names are
+// top-level data.
+func (inf *schemaInference) resolve(path string) *Schema {
+	keys := strings.Split(path, ".")
+	level := len(inf.loops) - 1
+	for len(keys) > 0 && keys[0] == "Parent" && level >= 0 {
+		keys = keys[1:]
+		level--
+	}
+
+	node := inf.root
+	if len(keys) > 0 && level >= 0 && level < len(inf.loops) {
+		switch keys[0] {
+		case "Item":
+			node, keys = inf.loops[level], keys[1:]
+		case "Index", "IsFirst", "IsLast", "Count":
+			return nil
+		}
+	}
+
+	for _, key := range keys {
+		setType(node, "object")
+		if node.Properties == nil {
+			node.Properties = make(map[string]*Schema)
+		}
+		child, ok := node.Properties[key]
+		if !ok {
+			child = &Schema{}
+			node.Properties[key] = child
+		}
+		node = child
+	}
+	return node
+}
+
+// scalarRank orders scalar types from least to most specific
+var scalarRank = map[string]int{"": 0, "boolean": 1, "string": 2, "number": 3}
+
+// useType records a scalar use of a value, keeping the most specific type
+// seen; objects and lists keep their type
+func useType(s *Schema, typ string) {
+	if rank, ok := scalarRank[s.Type]; ok && scalarRank[typ] > rank {
+		s.Type = typ
+	}
+}
+
+// setType makes a value an object or list
+func setType(s *Schema, typ string) {
+	if s.Type != "object" && s.Type != "array" {
+		s.Type = typ
+		s.Format = ""
+	}
+}
+
+// finishSchema gives values that were never used on their own a type:
+// loop items are strings, and objects and lists without fields stay as they are
+func finishSchema(s *Schema) {
+	switch s.Type {
+	case "":
+		s.Type = "string"
+	case "object":
+		for _, prop := range s.Properties {
+			finishSchema(prop)
+		}
+	case "array":
+		if s.Items == nil {
+			s.Items = &Schema{}
+		}
+		finishSchema(s.Items)
+	}
+	if s.Type != "string" {
+		s.Format = ""
+	}
+}
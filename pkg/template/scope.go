@@ -0,0 +1,60 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+// scope holds the variables visible while rendering: the data at the root,
+// then the item and index of each enclosing loop. Names are looked up from
+// the innermost loop outwards, so loop bodies can still use the top-level data.
+type scope struct {
+	vars   Data
+	parent *scope
+}
+
+// newScope creates the root scope for the render data
+func newScope(data Data) *scope {
+	return &scope{vars: data}
+}
+
+// loopScope creates the scope of one loop iteration over count items. Item
+// and Index refer to the current element, IsFirst and IsLast tell whether
+// it is the first or last one and Count is the number of items; Parent
+// holds the variables of the enclosing loop, so nested loops can reach the
+// outer item as {{.Parent.Item.Field}}.
+func (s *scope) loopScope(item interface{}, index, count int) *scope {
+	return &scope{
+		vars: Data{
+			"Item":    item,
+			"Index":   index,
+			"IsFirst": index == 0,
+			"IsLast":  index == count-1,
+			"Count":   count,
+			"Parent":  s.vars,
+		},
+		parent: s,
+	}
+}
+
+// isLastItem reports whether the innermost loop is at its last item, or
+// true outside of loops, where there is nothing to separate
+func (s *scope) isLastItem() bool {
+	for sc := s; sc != nil; sc = sc.parent {
+		if last, ok := sc.vars["IsLast"].(bool); ok && sc.parent != nil {
+			return last
+		}
+	}
+	return true
+}
+
+// lookup resolves a dotted variable path such as "Item.Customer.Name"
+func (s *scope) lookup(path string) (interface{}, error) {
+	keys := strings.Split(path, ".")
+	for sc := s; sc != nil; sc = sc.parent {
+		if value, ok := sc.vars[keys[0]]; ok {
+			return resolvePath(value, keys[1:])
+		}
+	}
+	return nil, fmt.Errorf("key %s not found", keys[0])
+}
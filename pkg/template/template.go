@@ -1,7 +1,10 @@
 package template
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -14,6 +17,8 @@ import (
 type Template struct {
 	doc      *docx.Document
 	filePath string
+	funcs    map[string]FilterFunc
+	partials map[string]*docx.Document
 }
 
 // Data represents template data
@@ -29,6 +34,27 @@ type RenderOptions struct {
 
 	// RemoveEmptyParagraphs removes paragraphs that become empty after rendering
 	RemoveEmptyParagraphs bool
+
+	// MergeFields also fills Word MERGEFIELD fields from the data, by field
+	// name, so templates made for Word's mail merge work unchanged
+	MergeFields bool
+
+	// Locale is the language tag, such as "de" or "pt-BR", the number,
+	// currency and date filters format for: its decimal and thousands
+	// separators, where the currency symbol goes, and month and day names.
+	// English when empty.
+	Locale string
+
+	// RichValues renders values by their type: booleans as ☑ or ☐, lists
+	// as their items separated by commas, and a paragraph holding nothing
+	// but a list or map as a bulleted paragraph per item or, in the body,
+	// a two-column table of the map's keys and values
+	RichValues bool
+
+	locale *locale // The locale of Locale, set when rendering starts
+
+	// report collects problems instead of failing, for Validate
+	report *ValidationReport
 }
 
 // DefaultOptions returns default rendering options
@@ -60,122 +86,166 @@ func Load(filePath string) (*Template, error) {
 	}, nil
 }
 
+// Close releases the file a template read with Load keeps its large media
+// parts in (see docx.Open). Documents rendered from the template read those
+// parts from the same file, so close it once they are saved. Partials added
+// with AddPartial are left open.
+func (t *Template) Close() error {
+	return t.doc.Close()
+}
+
 // Render renders the template with the given data
 func (t *Template) Render(data Data, opts RenderOptions) (*docx.Document, error) {
+	return t.RenderContext(context.Background(), data, opts)
+}
+
+// RenderContext renders the template like Render, checking ctx between
+// paragraphs and tables and returning its error once it is canceled or its
+// deadline passes
+func (t *Template) RenderContext(ctx context.Context, data Data, opts RenderOptions) (*docx.Document, error) {
+	var err error
+	if opts.locale, err = findLocale(opts.Locale); err != nil {
+		return nil, err
+	}
+
 	// Clone the document to avoid modifying the original
 	renderedDoc := t.doc.Clone()
+	root := newScope(data)
+
+	// Partials are rendered along with the rest of the template
+	if err := t.expandIncludes(renderedDoc, nil); err != nil {
+		return nil, err
+	}
 
 	// Process all paragraphs
+	var mapTables []int // Paragraphs holding only a map, for RichValues
 	for i := 0; i < len(renderedDoc.Body.Paragraphs); i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		para := &renderedDoc.Body.Paragraphs[i]
 
-		// Extract text from paragraph
-		text := extractParagraphText(para)
-
-		// Check for loop directive
-		if strings.Contains(text, "{{range") {
-			// Handle loop
-			loopResult, consumed, err := t.processLoop(renderedDoc, i, data, opts)
+		// Loops and conditionals are rendered as a whole, including nested blocks
+		if isBlockStart(extractParagraphText(para)) {
+			end, err := findBlockEnd(renderedDoc.Body.Paragraphs, i)
 			if err != nil {
-				return nil, fmt.Errorf("error processing loop at paragraph %d: %w", i, err)
+				return nil, fmt.Errorf("error processing paragraph %d: %w", i, err)
 			}
 
-			// Replace the loop paragraphs
-			if consumed > 0 {
-				// Remove original loop paragraphs
-				newParas := append(renderedDoc.Body.Paragraphs[:i], renderedDoc.Body.Paragraphs[i+consumed:]...)
-				// Insert rendered paragraphs
-				renderedDoc.Body.Paragraphs = append(newParas[:i], append(loopResult, newParas[i:]...)...)
-				i += len(loopResult) - 1
+			result, err := t.renderBlock(renderedDoc.Body.Paragraphs[i:end+1], root, opts)
+			if err != nil {
+				return nil, fmt.Errorf("error processing block at paragraph %d: %w", i, err)
 			}
+
+			// Replace the block paragraphs with the rendered ones
+			renderedDoc.Body.SpliceParagraphs(i, end-i+1, result...)
+			i += len(result) - 1
 			continue
 		}
 
-		// Check for conditional directive
-		if strings.Contains(text, "{{if") {
-			// Handle conditional
-			condResult, consumed, err := t.processConditional(renderedDoc, i, data, opts)
-			if err != nil {
-				return nil, fmt.Errorf("error processing conditional at paragraph %d: %w", i, err)
+		// Lists and maps with RichValues; tables go in once the body's own
+		// tables are rendered
+		if block := t.richBlock(para, root, opts); block != nil {
+			if len(block.rows) > 0 {
+				mapTables = append(mapTables, i)
+				continue
 			}
-
-			if consumed > 0 {
-				// Replace the conditional paragraphs
-				newParas := append(renderedDoc.Body.Paragraphs[:i], renderedDoc.Body.Paragraphs[i+consumed:]...)
-				if condResult != nil {
-					renderedDoc.Body.Paragraphs = append(newParas[:i], append(condResult, newParas[i:]...)...)
-					i += len(condResult) - 1
-				} else {
-					renderedDoc.Body.Paragraphs = newParas
-					i--
-				}
+			if paras := block.paragraphs(para); len(paras) > 0 {
+				renderedDoc.Body.Paragraphs[i] = paras[0]
+				renderedDoc.Body.SpliceParagraphs(i+1, 0, paras[1:]...)
+				i += len(paras) - 1
+				continue
 			}
+			renderedDoc.Body.SpliceParagraphs(i, 1)
+			i--
 			continue
 		}
 
 		// Replace variables in paragraph
-		if err := t.replaceParagraphVariables(para, data, opts); err != nil {
-			if opts.StrictMode {
-				return nil, fmt.Errorf("error replacing variables in paragraph %d: %w", i, err)
-			}
+		if err := t.renderParagraph(para, root, opts); err != nil {
+			return nil, fmt.Errorf("error rendering paragraph %d: %w", i, err)
 		}
 
 		// Remove if empty and option is set
 		if opts.RemoveEmptyParagraphs && isParagraphEmpty(para) {
-			renderedDoc.Body.Paragraphs = append(
-				renderedDoc.Body.Paragraphs[:i],
-				renderedDoc.Body.Paragraphs[i+1:]...,
-			)
+			renderedDoc.Body.SpliceParagraphs(i, 1)
 			i--
 		}
 	}
 
 	// Process tables
-	for _, table := range renderedDoc.Body.Tables {
-		if err := t.processTable(&table, data, opts); err != nil {
+	for i := range renderedDoc.Body.Tables {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := t.processTable(&renderedDoc.Body.Tables[i], root, opts); err != nil {
 			return nil, fmt.Errorf("error processing table: %w", err)
 		}
 	}
 
+	// Paragraphs holding only a map become tables, from the last so the
+	// indexes of the others stay valid
+	for i := len(mapTables) - 1; i >= 0; i-- {
+		index := mapTables[i]
+		block := t.richBlock(&renderedDoc.Body.Paragraphs[index], root, opts)
+		insertTable(renderedDoc.Body, index, block.table())
+		renderedDoc.Body.SpliceParagraphs(index, 1)
+	}
+
 	return renderedDoc, nil
 }
 
-// replaceParagraphVariables replaces variables in a paragraph
-func (t *Template) replaceParagraphVariables(para *docx.Paragraph, data Data, opts RenderOptions) error {
-	// Support both {{VARIABLE}} and {{.VARIABLE}} formats
-	varPatterns := []*regexp.Regexp{
-		regexp.MustCompile(`\{\{([a-zA-Z0-9_]+)\}\}`),     // {{VARIABLE}}
-		regexp.MustCompile(`\{\{\.([a-zA-Z0-9_]+)\}\}`),   // {{.VARIABLE}}
+// renderParagraphs renders a list of paragraphs, such as the body of a loop,
+// returning new paragraphs and leaving the originals untouched
+func (t *Template) renderParagraphs(paras []docx.Paragraph, sc *scope, opts RenderOptions) ([]docx.Paragraph, error) {
+	result := []docx.Paragraph{}
+	for i := 0; i < len(paras); i++ {
+		if isBlockStart(extractParagraphText(&paras[i])) {
+			end, err := findBlockEnd(paras, i)
+			if err != nil {
+				return nil, err
+			}
+			rendered, err := t.renderBlock(paras[i:end+1], sc, opts)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, rendered...)
+			i = end
+			continue
+		}
+
+		if block := t.richBlock(&paras[i], sc, opts); block != nil {
+			result = append(result, block.paragraphs(&paras[i])...)
+			continue
+		}
+
+		para := cloneParagraph(&paras[i])
+		if err := t.renderParagraph(&para, sc, opts); err != nil {
+			return nil, err
+		}
+		if opts.RemoveEmptyParagraphs && isParagraphEmpty(&para) {
+			continue
+		}
+		result = append(result, para)
 	}
+	return result, nil
+}
 
-	for i := range para.Runs {
-		for j := range para.Runs[i].Text {
-			text := &para.Runs[i].Text[j]
-
-			// Try both patterns
-			for _, pattern := range varPatterns {
-				matches := pattern.FindAllStringSubmatch(text.Content, -1)
-
-				for _, match := range matches {
-					if len(match) < 2 {
-						continue
-					}
-
-					varName := match[1]
-					placeholder := match[0]
-
-					// Get value from data
-					value, err := getValueFromData(data, varName)
-					if err != nil {
-						if opts.StrictMode {
-							return fmt.Errorf("variable %s not found", varName)
-						}
-						value = opts.DefaultValue
-					}
-
-					// Replace in text
-					text.Content = strings.ReplaceAll(text.Content, placeholder, fmt.Sprint(value))
-				}
+// variablePattern matches {{VARIABLE}}, {{.VARIABLE}} and dotted paths such
+// as {{.Item.Name}}, optionally followed by a pipeline of filters such as
+// {{.Price | currency "USD"}}
+var variablePattern = regexp.MustCompile(`\{\{\.?([a-zA-Z0-9_]+(?:\.[a-zA-Z0-9_]+)*)\s*(\|[^{}]*)?\}\}`)
+
+// replaceParagraphVariables replaces variables in a paragraph
+func (t *Template) replaceParagraphVariables(para *docx.Paragraph, sc *scope, opts RenderOptions) error {
+	for _, run := range paragraphRuns(para) {
+		for j := range run.Text {
+			text := &run.Text[j]
+
+			content, err := t.replaceVariables(text.Content, sc, opts)
+			text.Content = content
+			if err != nil {
+				return err
 			}
 		}
 	}
@@ -183,12 +253,105 @@ func (t *Template) replaceParagraphVariables(para *docx.Paragraph, data Data, op
 	return nil
 }
 
-// getValueFromData retrieves a value from the data map
-func getValueFromData(data Data, key string) (interface{}, error) {
-	// Support nested keys with dot notation
-	keys := strings.Split(key, ".")
+// replaceVariables replaces the variables in a piece of text. Missing
+// variables and filter failures are only reported in strict mode, except
+// for variables piped through required, which always are. A missing
+// variable given a value by a filter such as default is not missing.
+func (t *Template) replaceVariables(text string, sc *scope, opts RenderOptions) (string, error) {
+	var failure, required error
+	text = variablePattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		m := variablePattern.FindStringSubmatch(placeholder)
+		varName, pipeline := m[1], m[2]
+		if isKeyword(varName) {
+			return placeholder
+		}
+
+		// Get value from the innermost scope defining it
+		value, lookupErr := sc.lookup(varName)
+		found := lookupErr == nil
+		missing := func() string {
+			reportLookup(opts.report, placeholder, "variable", varName, lookupErr)
+			if failure == nil {
+				failure = &ErrTemplateVariableMissing{Name: varName, Use: "variable"}
+			}
+			return opts.DefaultValue
+		}
+		if pipeline == "" {
+			if !found {
+				return missing()
+			}
+			if opts.RichValues {
+				return richText(value)
+			}
+			return fmt.Sprint(value)
+		}
+
+		// Filters such as default still see the missing value
+		value, err := t.applyFilters(value, pipeline, sc, opts)
+		switch {
+		case errors.Is(err, errRequired):
+			opts.report.add(IssueMissingVariable, placeholder, "variable %s is required", varName)
+			if required == nil && opts.report == nil {
+				required = &ErrTemplateVariableMissing{Name: varName, Use: "required variable"}
+			}
+			return opts.DefaultValue
+		case err != nil && found:
+			if errors.Is(err, errUnknownFilter) {
+				opts.report.add(IssueUnknownDirective, placeholder, "%v", err)
+			} else {
+				opts.report.add(IssueTypeMismatch, placeholder, "variable %s: %v", varName, err)
+			}
+			if failure == nil {
+				failure = fmt.Errorf("variable %s: %w", varName, err)
+			}
+			return placeholder
+		case !found && (err != nil || toString(value) == ""):
+			return missing()
+		}
+		if opts.RichValues {
+			return richText(value)
+		}
+		return toString(value)
+	})
+	if required != nil {
+		return text, required
+	}
+	if failure != nil && opts.StrictMode {
+		return text, failure
+	}
+	return text, nil
+}
+
+// isKeyword reports whether a name is a directive rather than a variable
+func isKeyword(name string) bool {
+	return name == "end" || name == "else" || name == "separator"
+}
+
+// ErrTemplateVariableMissing is returned in strict mode for a variable,
+// loop collection or condition variable missing from the data. Use
+// errors.As to get the name.
+type ErrTemplateVariableMissing struct {
+	// Name is the variable's path, such as "Customer.Name"
+	Name string
+
+	// Use is how the template uses it: "variable", "required variable",
+	// "collection" or "condition variable"
+	Use string
+}
+
+func (e *ErrTemplateVariableMissing) Error() string {
+	use := e.Use
+	if use == "" {
+		use = "variable"
+	}
+	return fmt.Sprintf("%s %s not found", use, e.Name)
+}
+
+// errNotAccessible is returned for paths going through values that have no fields
+var errNotAccessible = errors.New("value has no fields")
 
-	var current interface{} = data
+// resolvePath follows a path of keys through maps and struct fields
+func resolvePath(current interface{}, keys []string) (interface{}, error) {
 	for _, k := range keys {
 		switch v := current.(type) {
 		case map[string]interface{}:
@@ -217,7 +380,7 @@ func getValueFromData(data Data, key string) (interface{}, error) {
 					return nil, fmt.Errorf("field %s not found", k)
 				}
 			} else {
-				return nil, fmt.Errorf("cannot access key %s on type %T", k, current)
+				return nil, fmt.Errorf("%w: cannot access key %s on type %T", errNotAccessible, k, current)
 			}
 		}
 	}
@@ -227,13 +390,34 @@ func getValueFromData(data Data, key string) (interface{}, error) {
 
 // extractParagraphText extracts all text from a paragraph
 func extractParagraphText(para *docx.Paragraph) string {
-	var text string
-	for _, run := range para.Runs {
+	var sb strings.Builder
+	for _, run := range paragraphRuns(para) {
 		for _, t := range run.Text {
-			text += t.Content
+			sb.WriteString(t.Content)
+		}
+	}
+	return sb.String()
+}
+
+// paragraphRuns returns the runs of a paragraph in document order,
+// including those inside hyperlinks and fields
+func paragraphRuns(para *docx.Paragraph) []*docx.Run {
+	var runs []*docx.Run
+	for _, child := range para.Content() {
+		switch c := child.(type) {
+		case *docx.Run:
+			runs = append(runs, c)
+		case *docx.Hyperlink:
+			for i := range c.Runs {
+				runs = append(runs, &c.Runs[i])
+			}
+		case *docx.SimpleField:
+			for i := range c.Runs {
+				runs = append(runs, &c.Runs[i])
+			}
 		}
 	}
-	return text
+	return runs
 }
 
 // isParagraphEmpty checks if a paragraph is empty
@@ -252,42 +436,41 @@ func (t *Template) RenderToFile(data Data, outputPath string, opts RenderOptions
 	return doc.Save(outputPath)
 }
 
-// GetVariables returns all variables found in the template
-func (t *Template) GetVariables() []string {
-	// Support both {{VARIABLE}} and {{.VARIABLE}} formats
-	varPatterns := []*regexp.Regexp{
-		regexp.MustCompile(`\{\{([a-zA-Z0-9_]+)\}\}`),     // {{VARIABLE}}
-		regexp.MustCompile(`\{\{\.([a-zA-Z0-9_]+)\}\}`),   // {{.VARIABLE}}
+// RenderTo renders the template and writes the .docx to w
+func (t *Template) RenderTo(data Data, w io.Writer, opts RenderOptions) error {
+	doc, err := t.Render(data, opts)
+	if err != nil {
+		return err
 	}
-	varSet := make(map[string]bool)
 
-	// Check paragraphs
-	for _, para := range t.doc.Body.Paragraphs {
-		text := extractParagraphText(&para)
-		for _, pattern := range varPatterns {
-			matches := pattern.FindAllStringSubmatch(text, -1)
-			for _, match := range matches {
-				if len(match) >= 2 {
-					varSet[match[1]] = true
-				}
+	_, err = doc.WriteTo(w)
+	return err
+}
+
+// GetVariables returns all variables found in the template, dotted paths
+// such as Customer.Name included. Directives such as {{end}} and
+// {{separator}} aren't variables.
+func (t *Template) GetVariables() []string {
+	varSet := make(map[string]bool)
+	collect := func(para *docx.Paragraph) {
+		for _, match := range variablePattern.FindAllStringSubmatch(extractParagraphText(para), -1) {
+			if !isKeyword(match[1]) {
+				varSet[match[1]] = true
 			}
 		}
 	}
 
+	// Check paragraphs
+	for i := range t.doc.Body.Paragraphs {
+		collect(&t.doc.Body.Paragraphs[i])
+	}
+
 	// Check tables
 	for _, table := range t.doc.Body.Tables {
 		for _, row := range table.Rows {
 			for _, cell := range row.Cells {
-				for _, para := range cell.Content {
-					text := extractParagraphText(&para)
-					for _, pattern := range varPatterns {
-						matches := pattern.FindAllStringSubmatch(text, -1)
-						for _, match := range matches {
-							if len(match) >= 2 {
-								varSet[match[1]] = true
-							}
-						}
-					}
+				for i := range cell.Content {
+					collect(&cell.Content[i])
 				}
 			}
 		}
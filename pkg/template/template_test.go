@@ -1,9 +1,19 @@
 package template
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/Palaciodiego008/docxsmith/pkg/docx"
 )
@@ -360,6 +370,21 @@ func TestGetVariables(t *testing.T) {
 			expectedVarCount: 2,
 			expectedVars:     []string{"Title", "Author"},
 		},
+		{
+			name: "Loop with separator",
+			paragraphs: []string{
+				"{{range .Items}}{{.Name}}{{separator}}, {{end}}",
+				"{{if .Paid}}Paid{{else}}Due {{Total}}{{end}}",
+			},
+			expectedVarCount: 2,
+			expectedVars:     []string{"Name", "Total"},
+		},
+		{
+			name:             "Dotted paths",
+			paragraphs:       []string{"{{.Customer.Name}}, {{.Customer.City | upper}}"},
+			expectedVarCount: 2,
+			expectedVars:     []string{"Customer.Name", "Customer.City"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -512,3 +537,1288 @@ func TestComplexScenario(t *testing.T) {
 		t.Errorf("Expected at least %d non-empty paragraphs, got %d", len(expectedTexts), actualCount)
 	}
 }
+
+func TestNestedLoops(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("{{range .Orders}}")
+	doc.AddParagraph("Order {{.Item.Number}} for {{.Customer}}")
+	doc.AddParagraph("{{range .Item.Lines}}")
+	doc.AddParagraph("{{.Parent.Item.Number}}.{{.Index}} {{.Item.Product}} x{{.Item.Qty}}")
+	doc.AddParagraph("{{if .Item.Backordered}}")
+	doc.AddParagraph("(backordered)")
+	doc.AddParagraph("{{end}}")
+	doc.AddParagraph("{{end}}")
+	doc.AddParagraph("{{end}}")
+	doc.AddParagraph("Done")
+
+	type line struct {
+		Product     string
+		Qty         int
+		Backordered bool
+	}
+	data := Data{
+		"Customer": "Acme",
+		"Orders": []map[string]interface{}{
+			{"Number": "A1", "Lines": []line{{"Bolts", 10, false}, {"Nuts", 5, true}}},
+			{"Number": "B2", "Lines": []line{}},
+			{"Number": "C3", "Lines": []line{{"Gears", 2, false}}},
+		},
+	}
+
+	result, err := New(doc).Render(data, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	expected := []string{
+		"Order A1 for Acme",
+		"A1.0 Bolts x10",
+		"A1.1 Nuts x5",
+		"(backordered)",
+		"Order B2 for Acme",
+		"Order C3 for Acme",
+		"C3.0 Gears x2",
+		"Done",
+	}
+	if len(result.Body.Paragraphs) != len(expected) {
+		t.Fatalf("Expected %d paragraphs, got %d", len(expected), len(result.Body.Paragraphs))
+	}
+	for i, want := range expected {
+		if text := extractParagraphText(&result.Body.Paragraphs[i]); text != want {
+			t.Errorf("Paragraph %d: expected %q, got %q", i, want, text)
+		}
+	}
+}
+
+func TestUnclosedLoop(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("{{range .Orders}}")
+	doc.AddParagraph("{{range .Item.Lines}}")
+	doc.AddParagraph("{{end}}")
+
+	if _, err := New(doc).Render(Data{"Orders": []int{1}}, DefaultOptions()); err == nil {
+		t.Error("Expected error for unclosed loop")
+	}
+}
+
+func TestConditionalExpressions(t *testing.T) {
+	data := Data{
+		"Status": "approved",
+		"Total":  1500,
+		"Limit":  "1000",
+		"Exempt": false,
+		"Order":  map[string]interface{}{"Rush": true},
+	}
+
+	tests := []struct {
+		condition string
+		expected  bool
+	}{
+		{`eq .Status "approved"`, true},
+		{`eq .Status “approved”`, true}, // Word's curly quotes
+		{`eq .Status "pending" "approved"`, true},
+		{`ne .Status "approved"`, false},
+		{`gt .Total 1000`, true},
+		{`le .Total 1000`, false},
+		{`gt .Total .Limit`, true},
+		{`lt "apple" "banana"`, true},
+		{`not .Exempt`, true},
+		{`and (gt .Total 1000) (not .Exempt)`, true},
+		{`and (gt .Total 1000) .Exempt`, false},
+		{`or .Exempt (eq .Status "rejected") .Order.Rush`, true},
+		{`not (or .Exempt .Missing)`, true},
+		{`.Order.Rush`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			doc := docx.New()
+			doc.AddParagraph("{{if " + tt.condition + "}}")
+			doc.AddParagraph("yes")
+			doc.AddParagraph("{{else}}")
+			doc.AddParagraph("no")
+			doc.AddParagraph("{{end}}")
+
+			result, err := New(doc).Render(data, DefaultOptions())
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			want := map[bool]string{true: "yes", false: "no"}[tt.expected]
+			if text := extractParagraphText(&result.Body.Paragraphs[0]); text != want {
+				t.Errorf("Expected %q, got %q", want, text)
+			}
+		})
+	}
+}
+
+func TestElseIfChain(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("{{if gt .Total 10000}}")
+	doc.AddParagraph("Tier: enterprise")
+	doc.AddParagraph("{{else if gt .Total 1000}}")
+	doc.AddParagraph("Tier: business")
+	doc.AddParagraph("{{if .Trial}}")
+	doc.AddParagraph("(trial)")
+	doc.AddParagraph("{{else}}")
+	doc.AddParagraph("(paid)")
+	doc.AddParagraph("{{end}}")
+	doc.AddParagraph("{{else}}")
+	doc.AddParagraph("Tier: starter")
+	doc.AddParagraph("{{end}}")
+
+	for total, want := range map[int][]string{
+		50000: {"Tier: enterprise"},
+		5000:  {"Tier: business", "(trial)"},
+		50:    {"Tier: starter"},
+	} {
+		result, err := New(doc).Render(Data{"Total": total, "Trial": true}, DefaultOptions())
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if len(result.Body.Paragraphs) != len(want) {
+			t.Fatalf("Total %d: expected %d paragraphs, got %d", total, len(want), len(result.Body.Paragraphs))
+		}
+		for i, w := range want {
+			if text := extractParagraphText(&result.Body.Paragraphs[i]); text != w {
+				t.Errorf("Total %d: expected %q, got %q", total, w, text)
+			}
+		}
+	}
+}
+
+func TestInvalidConditions(t *testing.T) {
+	for _, condition := range []string{`eq .Status`, `bogus .Status`, `and (gt .Total 1`, `eq .Status "open`} {
+		doc := docx.New()
+		doc.AddParagraph("{{if " + condition + "}}")
+		doc.AddParagraph("yes")
+		doc.AddParagraph("{{end}}")
+
+		if _, err := New(doc).Render(Data{"Status": "open", "Total": 1}, DefaultOptions()); err == nil {
+			t.Errorf("Expected error for condition %q", condition)
+		}
+	}
+
+	doc := docx.New()
+	doc.AddParagraph("{{if eq .Missing 1}}")
+	doc.AddParagraph("yes")
+	doc.AddParagraph("{{end}}")
+	if _, err := New(doc).Render(Data{}, RenderOptions{StrictMode: true}); err == nil {
+		t.Error("Expected error for missing variable in strict mode")
+	}
+}
+
+func TestFilters(t *testing.T) {
+	data := Data{
+		"Name":    "  ada lovelace ",
+		"Price":   1234.5,
+		"Debt":    -99,
+		"Yen":     "150000",
+		"Date":    time.Date(2024, 3, 9, 14, 30, 0, 0, time.UTC),
+		"DateStr": "2024-12-25",
+		"Empty":   "",
+		"Code":    "EUR",
+	}
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{`{{.Name | trim | upper}}`, "ADA LOVELACE"},
+		{`{{.Name|trim|title}}`, "Ada Lovelace"},
+		{`{{.Price | currency "USD"}}`, "$1,234.50"},
+		{`{{.Price | currency .Code}}`, "€1,234.50"},
+		{`{{.Debt | currency}}`, "-$99.00"},
+		{`{{.Yen | currency "JPY"}}`, "¥150,000"},
+		{`{{.Price | currency "CHF"}}`, "CHF 1,234.50"},
+		{`{{.Price | number 1}}`, "1,234.5"},
+		{`{{.Date | date "2006-01-02"}}`, "2024-03-09"},
+		{`{{.Date | date “Jan 2, 2006 15:04”}}`, "Mar 9, 2024 14:30"},
+		{`{{.DateStr | date "02/01/2006"}}`, "25/12/2024"},
+		{`{{.Empty | default "N/A"}}`, "N/A"},
+		{`{{.Missing | default "N/A"}}`, "N/A"},
+		{`{{.Name | trim | truncate 3}}`, "ada…"},
+		{`{{.Name | trim | replace " " "_"}}`, "ada_lovelace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			doc := docx.New()
+			doc.AddParagraph(tt.template)
+
+			result, err := New(doc).Render(data, RenderOptions{StrictMode: !strings.Contains(tt.template, "Missing")})
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if text := extractParagraphText(&result.Body.Paragraphs[0]); text != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, text)
+			}
+		})
+	}
+}
+
+func TestLocaleFilters(t *testing.T) {
+	data := Data{
+		"Price": 1234.5,
+		"Debt":  -99,
+		"Date":  time.Date(2024, 3, 9, 14, 30, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		locale   string
+		template string
+		expected string
+	}{
+		{"de", `{{.Price | number 2}}`, "1.234,50"},
+		{"de-DE", `{{.Price | currency "EUR"}}`, "1.234,50\u00a0€"},
+		{"de", `{{.Debt | currency "EUR"}}`, "-99,00\u00a0€"},
+		{"fr", `{{.Price | number 1}}`, "1\u202f234,5"},
+		{"pt-BR", `{{.Price | currency "BRL"}}`, "R$1.234,50"},
+		{"de-CH", `{{.Price | currency "CHF"}}`, "CHF 1\u2019234.50"},
+		{"en-US", `{{.Price | currency}}`, "$1,234.50"},
+		{"fr", `{{.Date | date "Monday 2 January 2006"}}`, "samedi 9 mars 2024"},
+		{"es", `{{.Date | date "Mon 2 Jan 2006, 15:04"}}`, "sáb 9 mar 2024, 14:30"},
+		{"de", `{{.Date | date "02.01.2006"}}`, "09.03.2024"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale+" "+tt.template, func(t *testing.T) {
+			doc := docx.New()
+			doc.AddParagraph(tt.template)
+
+			result, err := New(doc).Render(data, RenderOptions{StrictMode: true, Locale: tt.locale})
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if text := extractParagraphText(&result.Body.Paragraphs[0]); text != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, text)
+			}
+		})
+	}
+
+	if _, err := New(docx.New()).Render(data, RenderOptions{Locale: "xx"}); err == nil {
+		t.Error("Expected an error for an unsupported locale")
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph(`{{.Name | shout 3}} {{.Name | upper}}`)
+
+	tmpl := New(doc)
+	tmpl.RegisterFunc("shout", func(value interface{}, args ...interface{}) (interface{}, error) {
+		n, _ := args[0].(float64)
+		return fmt.Sprint(value) + strings.Repeat("!", int(n)), nil
+	})
+	// Registered filters replace built-in ones
+	tmpl.RegisterFunc("upper", func(value interface{}, args ...interface{}) (interface{}, error) {
+		return "custom", nil
+	})
+
+	vars := tmpl.GetVariables()
+	if len(vars) != 1 || vars[0] != "Name" {
+		t.Errorf("Expected variables [Name], got %v", vars)
+	}
+
+	result, err := tmpl.Render(Data{"Name": "hey"}, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if text := extractParagraphText(&result.Body.Paragraphs[0]); text != "hey!!! custom" {
+		t.Errorf("Expected %q, got %q", "hey!!! custom", text)
+	}
+
+}
+
+func TestFilterErrors(t *testing.T) {
+	data := Data{"Name": "Ada", "Price": 10}
+	for _, placeholder := range []string{`{{.Name | bogus}}`, `{{.Name | currency}}`, `{{.Price | date "2006"}}`, `{{.Name |}}`, `{{.Name | truncate}}`} {
+		doc := docx.New()
+		doc.AddParagraph(placeholder)
+
+		if _, err := New(doc).Render(data, RenderOptions{StrictMode: true}); err == nil {
+			t.Errorf("Expected error for %s in strict mode", placeholder)
+		}
+
+		// Outside strict mode the placeholder is left for the author to fix
+		result, err := New(doc).Render(data, DefaultOptions())
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if text := extractParagraphText(&result.Body.Paragraphs[0]); text != placeholder {
+			t.Errorf("Expected %s to be kept, got %q", placeholder, text)
+		}
+	}
+}
+
+func TestInlineBlocks(t *testing.T) {
+	data := Data{
+		"Formal": true,
+		"First":  "Ada",
+		"Last":   "Lovelace",
+		"Tags":   []string{"math", "poetry"},
+		"Total":  1500,
+	}
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{`Dear {{if .Formal}}Ms. {{.Last}}{{else}}{{.First}}{{end}},`, "Dear Ms. Lovelace,"},
+		{`Dear {{if not .Formal}}{{.First}}{{else}}Ms. {{.Last}}{{end}},`, "Dear Ms. Lovelace,"},
+		{`Tier: {{if gt .Total 10000}}gold{{else if gt .Total 1000}}silver{{else}}bronze{{end}}`, "Tier: silver"},
+		{`Tags: {{range .Tags}}[{{.Item}}]{{end}}`, "Tags: [math][poetry]"},
+		{`{{range .Tags}}{{if eq .Index 0}}{{.Item}}{{else}}, {{.Item}}{{end}}{{end}}.`, "math, poetry."},
+		{`{{range .Missing}}x{{end}}none`, "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			doc := docx.New()
+			doc.AddParagraph(tt.template)
+
+			result, err := New(doc).Render(data, DefaultOptions())
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if text := extractParagraphText(&result.Body.Paragraphs[0]); text != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, text)
+			}
+		})
+	}
+}
+
+func TestInlineBlocksAcrossRuns(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("Dear {{if .Formal}}")
+	para := &doc.Body.Paragraphs[0]
+	bold := &docx.RProps{Bold: &docx.Bold{}}
+	para.Runs = append(para.Runs,
+		docx.Run{Props: bold, Text: []docx.Text{{Content: "Ms. {{.Last}}"}}},
+		docx.Run{Text: []docx.Text{{Content: "{{else}}{{.First}}{{end}},"}}},
+	)
+
+	result, err := New(doc).Render(Data{"Formal": true, "Last": "Lovelace"}, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	runs := result.Body.Paragraphs[0].Runs
+	if text := extractParagraphText(&result.Body.Paragraphs[0]); text != "Dear Ms. Lovelace," {
+		t.Fatalf("Expected %q, got %q", "Dear Ms. Lovelace,", text)
+	}
+	// The name keeps the bold formatting of the run it was written in
+	found := false
+	for _, run := range runs {
+		if len(run.Text) > 0 && run.Text[0].Content == "Ms. Lovelace" {
+			found = run.Props != nil && run.Props.Bold != nil
+		}
+	}
+	if !found {
+		t.Errorf("Expected the name in its own bold run, got %+v", runs)
+	}
+}
+
+func TestVariablesInLinks(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("Contact ")
+	para := &doc.Body.Paragraphs[0]
+	para.Hyperlinks = []docx.Hyperlink{{Anchor: "contact", Position: 1, Runs: []docx.Run{{Text: []docx.Text{{Content: "{{.Name}}"}}}}}}
+	doc.AddParagraph("")
+	doc.Body.Paragraphs[1].Hyperlinks = []docx.Hyperlink{{Anchor: "top", Runs: []docx.Run{{Text: []docx.Text{{Content: "Back to top"}}}}}}
+
+	tmpl := New(doc)
+	if vars := tmpl.GetVariables(); len(vars) != 1 || vars[0] != "Name" {
+		t.Errorf("Expected the variable in the link, got %v", vars)
+	}
+	opts := DefaultOptions()
+	opts.RemoveEmptyParagraphs = true
+	result, err := tmpl.Render(Data{"Name": "Ada"}, opts)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if text := extractParagraphText(&result.Body.Paragraphs[0]); text != "Contact Ada" {
+		t.Errorf("Expected %q, got %q", "Contact Ada", text)
+	}
+	if len(result.Body.Paragraphs) != 2 {
+		t.Errorf("Expected the paragraph holding only a link to be kept, got %d paragraphs", len(result.Body.Paragraphs))
+	}
+}
+
+func TestInlineBlocksInTables(t *testing.T) {
+	doc := docx.New()
+	table := doc.AddTable(1, 2)
+	table.SetCellText(0, 0, "{{range .Tags}}{{.Item}} {{end}}")
+	table.SetCellText(0, 1, "{{if .Paid}}Paid{{else}}Due{{end}}")
+
+	result, err := New(doc).Render(Data{"Tags": []string{"a", "b"}, "Paid": false}, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	rendered := &result.Body.Tables[0]
+	if len(rendered.Rows) != 1 {
+		t.Fatalf("Expected the inline loop to stay in its cell, got %d rows", len(rendered.Rows))
+	}
+	for col, want := range []string{"a b ", "Due"} {
+		if text, _ := rendered.GetCellText(0, col); text != want {
+			t.Errorf("Cell %d: expected %q, got %q", col, want, text)
+		}
+	}
+}
+
+func TestInlineBlocksInLoopBody(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("{{if .Show}}")
+	doc.AddParagraph("{{range .Items}}")
+	doc.AddParagraph("{{.Item.Name}}{{if .Item.Rush}} (rush){{else}} (standard){{end}}")
+	doc.AddParagraph("{{end}}")
+	doc.AddParagraph("{{else}}")
+	doc.AddParagraph("Hidden")
+	doc.AddParagraph("{{end}}")
+
+	data := Data{
+		"Show": true,
+		"Items": []map[string]interface{}{
+			{"Name": "Bolts", "Rush": true},
+			{"Name": "Nuts", "Rush": false},
+		},
+	}
+	result, err := New(doc).Render(data, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	want := []string{"Bolts (rush)", "Nuts (standard)"}
+	if len(result.Body.Paragraphs) != len(want) {
+		t.Fatalf("Expected %d paragraphs, got %d", len(want), len(result.Body.Paragraphs))
+	}
+	for i, w := range want {
+		if text := extractParagraphText(&result.Body.Paragraphs[i]); text != w {
+			t.Errorf("Paragraph %d: expected %q, got %q", i, w, text)
+		}
+	}
+}
+
+func TestInvalidInlineBlocks(t *testing.T) {
+	for _, template := range []string{
+		`{{if .A}}x{{else}}y{{else}}z{{end}}`,
+		`{{range .Tags}}x{{else}}y{{end}}`,
+		`{{if bogus .A}}x{{end}}`,
+		`{{end}}{{if .A}}x`,
+	} {
+		doc := docx.New()
+		doc.AddParagraph(template)
+		if _, err := New(doc).Render(Data{"A": true, "Tags": []string{"t"}}, DefaultOptions()); err == nil {
+			t.Errorf("Expected error for %q", template)
+		}
+	}
+}
+
+func TestPartials(t *testing.T) {
+	signature := docx.New()
+	signature.AddParagraph("Regards,")
+	signature.AddParagraph("{{.Sender}}")
+
+	doc := docx.New()
+	doc.AddParagraph("Dear {{.Name}},")
+	doc.AddParagraph(`{{include "signature"}}`)
+
+	tmpl := New(doc)
+	tmpl.AddPartial("signature", signature)
+
+	// Rendering twice shows the partial is left untouched
+	for _, sender := range []string{"Ada", "Grace"} {
+		result, err := tmpl.Render(Data{"Name": "Bob", "Sender": sender}, DefaultOptions())
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		want := []string{"Dear Bob,", "Regards,", sender}
+		if len(result.Body.Paragraphs) != len(want) {
+			t.Fatalf("Expected %d paragraphs, got %d", len(want), len(result.Body.Paragraphs))
+		}
+		for i, w := range want {
+			if text := extractParagraphText(&result.Body.Paragraphs[i]); text != w {
+				t.Errorf("Paragraph %d: expected %q, got %q", i, w, text)
+			}
+		}
+	}
+}
+
+func TestPartialsInLoopsAndFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	line := docx.New()
+	line.AddParagraph("- {{.Item}}")
+	if err := line.Save(filepath.Join(dir, "line.docx")); err != nil {
+		t.Fatalf("Failed to save partial: %v", err)
+	}
+
+	// A partial may include other partials
+	list := docx.New()
+	list.AddParagraph("{{range .Items}}")
+	list.AddParagraph(`{{include “line.docx”}}`)
+	list.AddParagraph("{{end}}")
+
+	doc := docx.New()
+	doc.AddParagraph(`{{include "list"}}`)
+	if err := doc.Save(filepath.Join(dir, "main.docx")); err != nil {
+		t.Fatalf("Failed to save template: %v", err)
+	}
+
+	// Files are found next to the template
+	tmpl, err := Load(filepath.Join(dir, "main.docx"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	tmpl.AddPartial("list", list)
+
+	result, err := tmpl.Render(Data{"Items": []string{"a", "b"}}, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if text := result.GetText(); !strings.Contains(text, "- a") || !strings.Contains(text, "- b") {
+		t.Errorf("Expected the included line per item, got %q", text)
+	}
+}
+
+func TestPartialErrors(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph(`{{include "missing"}}`)
+	if _, err := New(doc).Render(Data{}, DefaultOptions()); err == nil {
+		t.Error("Expected error for unknown partial")
+	}
+
+	loop := docx.New()
+	loop.AddParagraph(`{{include "loop"}}`)
+	tmpl := New(loop)
+	tmpl.AddPartial("loop", loop)
+	if _, err := tmpl.Render(Data{}, DefaultOptions()); err == nil || !strings.Contains(err.Error(), "includes itself") {
+		t.Errorf("Expected error for recursive partial, got %v", err)
+	}
+}
+
+func TestTableRowLoops(t *testing.T) {
+	doc := docx.New()
+	table := doc.AddTable(4, 2)
+	table.SetCellText(0, 0, "Item")
+	table.SetCellText(0, 1, "Qty")
+	table.SetCellText(1, 0, "{{range .Items}}{{.Item.Name}}")
+	table.SetCellText(1, 1, "{{.Item.Qty}}{{end}}")
+	table.SetCellText(2, 0, "Total")
+	table.SetCellText(2, 1, "{{.Total}}")
+	table.SetCellText(3, 0, "{{range .Notes}}{{.Index}}")
+	table.SetCellText(3, 1, "{{.Item}}")
+
+	data := Data{
+		"Items": []map[string]interface{}{
+			{"Name": "Bolts", "Qty": 10},
+			{"Name": "Nuts", "Qty": 5},
+		},
+		"Total": 15,
+		"Notes": []string{"Fragile"},
+	}
+	result, err := New(doc).Render(data, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	want := [][]string{{"Item", "Qty"}, {"Bolts", "10"}, {"Nuts", "5"}, {"Total", "15"}, {"0", "Fragile"}}
+	rendered := &result.Body.Tables[0]
+	if len(rendered.Rows) != len(want) {
+		t.Fatalf("Expected %d rows, got %d", len(want), len(rendered.Rows))
+	}
+	for r, cells := range want {
+		for c, w := range cells {
+			if text, _ := rendered.GetCellText(r, c); text != w {
+				t.Errorf("Cell (%d, %d): expected %q, got %q", r, c, w, text)
+			}
+		}
+	}
+}
+
+func TestTableDirectiveRow(t *testing.T) {
+	// A row holding only the directive repeats the row after it
+	doc := docx.New()
+	table := doc.AddTable(4, 2)
+	table.SetCellText(0, 0, "Name")
+	table.SetCellText(1, 0, "{{range .Items}}")
+	table.SetCellText(2, 0, "{{.Item}}")
+	table.SetCellText(3, 0, "End")
+
+	result, err := New(doc).Render(Data{"Items": []string{"a", "b", "c"}}, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	rendered := &result.Body.Tables[0]
+	var got []string
+	for r := range rendered.Rows {
+		text, _ := rendered.GetCellText(r, 0)
+		got = append(got, text)
+	}
+	if want := "Name a b c End"; strings.Join(got, " ") != want {
+		t.Errorf("Expected rows %q, got %q", want, strings.Join(got, " "))
+	}
+
+	// A missing collection removes the loop rows outside strict mode
+	result, err = New(doc).Render(Data{}, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if n := len(result.Body.Tables[0].Rows); n != 2 {
+		t.Errorf("Expected 2 rows, got %d", n)
+	}
+	if _, err := New(doc).Render(Data{}, RenderOptions{StrictMode: true}); err == nil {
+		t.Error("Expected error for missing collection in strict mode")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("Dear {{.Name}}, {{.Title | shout}}")
+	doc.AddParagraph("{{if .Paid}}")
+	doc.AddParagraph("{{.Amount | currency}}")
+	doc.AddParagraph("{{end}}")
+	doc.AddParagraph("{{range .Lines}}{{.Item.Product}}{{end}}")
+	doc.AddParagraph("{{range .Customer}}x{{end}} {{.Customer.Name.First}}")
+	doc.AddParagraph("{{template \"x\"}}")
+	table := doc.AddTable(2, 2)
+	table.SetCellText(0, 0, "{{range .Lines}}{{.Item.Qty}}")
+	table.SetCellText(0, 1, "{{.Item.Price}}{{end}}")
+	table.SetCellText(1, 0, "{{.Missing}}")
+
+	data := Data{
+		"Paid":     true,
+		"Amount":   "lots",
+		"Lines":    []map[string]interface{}{{"Product": "Bolts", "Qty": 1}},
+		"Customer": map[string]interface{}{"Name": "Acme"},
+	}
+	report := New(doc).Validate(data)
+	if report.Valid() {
+		t.Fatal("Expected issues")
+	}
+
+	want := map[IssueKind][]string{
+		IssueMissingVariable:  {"variable Name not found", "variable Title not found", "variable Item.Price not found", "variable Missing not found"},
+		IssueUnknownDirective: {`unknown filter "shout"`, `unknown directive {{template "x"}}`},
+		IssueTypeMismatch:     {"collection Customer is a map[string]interface {}, not a list", "Amount: filter currency: lots is not a number", "cannot access key First"},
+	}
+	for kind, messages := range want {
+		for _, message := range messages {
+			found := false
+			for _, issue := range report.Issues {
+				if issue.Kind == kind && strings.Contains(issue.Message, message) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected %s issue %q, got:\n%v", kind, message, report.Issues)
+			}
+		}
+	}
+	if n := report.Count(IssueUnbalancedBlock); n != 0 {
+		t.Errorf("Expected no unbalanced blocks, got %d", n)
+	}
+}
+
+func TestValidateStructure(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("{{if .A}}")
+	doc.AddParagraph("{{range .B}}")
+	doc.AddParagraph("{{end}}")
+	doc.AddParagraph("{{else}} {{end}} {{end}}")
+	doc.AddParagraph(`{{if eq .A "open}}x{{end}}`)
+
+	report := New(doc).Validate(Data{"A": true})
+	var got []string
+	for _, issue := range report.Issues {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		"paragraph 4: {{end}} without a matching {{if}} or {{range}} (unbalanced-block)",
+		`paragraph 5: {{if eq .A "open}}: unterminated string in expression "eq .A \"open" (invalid-expression)`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	valid := docx.New()
+	valid.AddParagraph("Hello {{.Name | upper}}")
+	if report := New(valid).Validate(Data{"Name": "Ada"}); !report.Valid() {
+		t.Errorf("Expected valid template, got %v", report.Issues)
+	}
+}
+
+func TestGetSchema(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("Invoice {{.Number}} for {{.Customer.Name}}")
+	doc.AddParagraph("{{if .IsPaid}}")
+	doc.AddParagraph("Paid on {{.PaidOn | date \"Jan 2\"}}")
+	doc.AddParagraph("{{else if gt .Balance 0}}")
+	doc.AddParagraph("{{.Balance | currency .Currency}} due")
+	doc.AddParagraph("{{end}}")
+	doc.AddParagraph("{{range .Orders}}")
+	doc.AddParagraph("{{.Item.ID}}{{if eq .Item.Status \"open\"}} (open){{end}}")
+	doc.AddParagraph("{{range .Item.Tags}}{{.Item}} of {{.Parent.Item.ID}}{{end}}")
+	doc.AddParagraph("{{end}}")
+	table := doc.AddTable(2, 2)
+	table.SetCellText(0, 0, "{{range .Lines}}{{.Item.Product}}")
+	table.SetCellText(0, 1, "{{.Item.Qty | number}}{{end}}")
+	table.SetCellText(1, 0, "{{.Total}}")
+
+	schema := New(doc).GetSchema()
+	data, err := schema.JSON()
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if got["$schema"] != jsonSchemaVersion {
+		t.Errorf("Expected $schema, got %v", got["$schema"])
+	}
+
+	typeOf := func(path ...string) string {
+		s := schema
+		for _, key := range path {
+			if key == "[]" {
+				s = s.Items
+			} else {
+				s = s.Properties[key]
+			}
+			if s == nil {
+				return "<missing>"
+			}
+		}
+		return s.Type
+	}
+	for path, want := range map[string]string{
+		"Number":              "string",
+		"Customer":            "object",
+		"Customer.Name":       "string",
+		"IsPaid":              "boolean",
+		"PaidOn":              "string",
+		"Balance":             "number",
+		"Currency":            "string",
+		"Orders":              "array",
+		"Orders.[].ID":        "string",
+		"Orders.[].Status":    "string",
+		"Orders.[].Tags":      "array",
+		"Orders.[].Tags.[]":   "string",
+		"Lines.[].Product":    "string",
+		"Lines.[].Qty":        "number",
+		"Total":               "string",
+		"Item":                "<missing>",
+		"Orders.[].Tags.[].x": "<missing>",
+	} {
+		if got := typeOf(strings.Split(path, ".")...); got != want {
+			t.Errorf("%s: expected %s, got %s", path, want, got)
+		}
+	}
+	if schema.Properties["PaidOn"].Format != "date" {
+		t.Error("Expected PaidOn to be formatted as a date")
+	}
+
+	// The skeleton has one item per list
+	skeleton := schema.Skeleton().(map[string]interface{})
+	orders := skeleton["Orders"].([]interface{})
+	if len(orders) != 1 || orders[0].(map[string]interface{})["ID"] != "" || skeleton["IsPaid"] != false {
+		t.Errorf("Unexpected skeleton: %v", skeleton)
+	}
+}
+
+func TestRenderLeavesTemplateUntouched(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("Dear {{.Name | upper}},")
+	doc.AddParagraph("{{if .VIP}}Welcome back{{else}}Welcome{{end}}")
+	doc.AddParagraph("{{range .Items}}")
+	doc.AddParagraph("- {{.Item}}")
+	doc.AddParagraph("{{end}}")
+	table := doc.AddTable(2, 1)
+	table.SetCellText(0, 0, "{{range .Items}}{{.Item}}{{end}}")
+	table.SetCellText(1, 0, "{{.Name}}")
+	tmpl := New(doc)
+
+	before := doc.GetText()
+	variables := tmpl.GetVariables()
+
+	records := []Data{
+		{"Name": "Ana", "VIP": true, "Items": []string{"Tea"}},
+		{"Name": "Bob", "VIP": false, "Items": []string{"Cake", "Pie"}},
+	}
+	for _, data := range records {
+		tmpl.Validate(data)
+		result, err := tmpl.Render(data, DefaultOptions())
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		text := result.GetText()
+		if !strings.Contains(text, strings.ToUpper(data["Name"].(string))) {
+			t.Errorf("Expected %s in %q", data["Name"], text)
+		}
+	}
+
+	if after := doc.GetText(); after != before {
+		t.Errorf("Rendering changed the template:\nbefore: %q\nafter:  %q", before, after)
+	}
+	if got := tmpl.GetVariables(); len(got) != len(variables) {
+		t.Errorf("Expected variables %v after rendering, got %v", variables, got)
+	}
+}
+
+func TestMissingVariableError(t *testing.T) {
+	tests := []struct {
+		text string
+		name string
+		use  string
+	}{
+		{"Dear {{.Customer.Name}}", "Customer.Name", "variable"},
+		{"{{range .Items}}{{.Item}}{{end}}", "Items", "collection"},
+		{"{{if .Paid}}paid{{end}}", "Paid", "condition variable"},
+	}
+	for _, tt := range tests {
+		doc := docx.New()
+		doc.AddParagraph(tt.text)
+		_, err := New(doc).Render(Data{}, RenderOptions{StrictMode: true})
+
+		var missing *ErrTemplateVariableMissing
+		if !errors.As(err, &missing) {
+			t.Errorf("%s: expected ErrTemplateVariableMissing, got %v", tt.text, err)
+			continue
+		}
+		if missing.Name != tt.name || missing.Use != tt.use {
+			t.Errorf("%s: expected %s %s, got %s %s", tt.text, tt.use, tt.name, missing.Use, missing.Name)
+		}
+	}
+}
+
+func TestRenderContext(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("Hello {{name}}")
+	tmpl := New(doc)
+
+	result, err := tmpl.RenderContext(context.Background(), Data{"name": "Ada"}, DefaultOptions())
+	if err != nil {
+		t.Fatalf("RenderContext failed: %v", err)
+	}
+	if got := result.GetText(); !strings.Contains(got, "Hello Ada") {
+		t.Errorf("Expected the variable to be replaced, got %q", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := tmpl.RenderContext(ctx, Data{"name": "Ada"}, DefaultOptions()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestDataFromPairsAndEnv(t *testing.T) {
+	t.Setenv("DOCXTEST_Name", "Ada")
+	t.Setenv("DOCXTEST_Customer__City", "London")
+	t.Setenv("DOCXTEST_Total", "10")
+
+	data := Data{"Customer": map[string]interface{}{"Name": "Acme", "City": "Paris"}, "Total": "5"}
+	data.Merge(EnvData("DOCXTEST_"))
+	if err := data.SetPairs([]string{"Total=12", "Items.Count=3"}); err != nil {
+		t.Fatalf("SetPairs failed: %v", err)
+	}
+
+	doc := docx.New()
+	doc.AddParagraph("{{.Name}} {{.Customer.Name}} {{.Customer.City}} {{.Total}} {{.Items.Count}}")
+	result, err := New(doc).Render(data, RenderOptions{StrictMode: true})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if got := result.GetText(); got != "Ada Acme London 12 3" {
+		t.Errorf("Expected the merged data, got %q", got)
+	}
+
+	for _, pair := range []string{"Total", "=1", "Items..Count=1"} {
+		if err := data.SetPairs([]string{pair}); err == nil {
+			t.Errorf("Expected an error for %q", pair)
+		}
+	}
+}
+
+func TestFetchData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		switch {
+		case r.URL.Path == "/yaml":
+			fmt.Fprint(w, "Name: Ada\n")
+		case r.Header.Get("X-Api-Key") != "secret" || user != "ada" || pass != "pw":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			fmt.Fprint(w, `{"Name": "Ada", "Items": [1, 2]}`)
+		}
+	}))
+	defer server.Close()
+
+	opts := DefaultFetchOptions()
+	opts.Headers = map[string]string{"X-Api-Key": "secret"}
+	opts.Username, opts.Password = "ada", "pw"
+	data, err := FetchData(context.Background(), server.URL+"/data", opts)
+	if err != nil {
+		t.Fatalf("FetchData failed: %v", err)
+	}
+	if data["Name"] != "Ada" {
+		t.Errorf("Expected Name Ada, got %v", data["Name"])
+	}
+
+	if data, err := FetchData(context.Background(), server.URL+"/yaml", DefaultFetchOptions()); err != nil || data["Name"] != "Ada" {
+		t.Errorf("Expected YAML data, got %v, %v", data, err)
+	}
+	if _, err := FetchData(context.Background(), server.URL+"/data", DefaultFetchOptions()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected an error for the 401 response, got %v", err)
+	}
+	opts.MaxSize = 4
+	if _, err := FetchData(context.Background(), server.URL+"/data", opts); err == nil {
+		t.Error("Expected an error for a response larger than MaxSize")
+	}
+	if _, err := FetchData(context.Background(), "ftp://example.com/data.json", opts); err == nil {
+		t.Error("Expected an error for a URL that isn't http or https")
+	}
+}
+
+func TestDefaultAndRequiredPlaceholders(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph(`{{.Name | default "N/A"}} <{{.Email | required}}>`)
+	tmpl := New(doc)
+
+	// default covers a missing value even in strict mode
+	result, err := tmpl.Render(Data{"Email": "ada@example.com"}, RenderOptions{StrictMode: true})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if got := result.GetText(); got != "N/A <ada@example.com>" {
+		t.Errorf("Expected the default, got %q", got)
+	}
+
+	// required fails whether or not strict mode is on
+	for _, data := range []Data{{}, {"Email": ""}} {
+		_, err := tmpl.Render(data, DefaultOptions())
+		var missing *ErrTemplateVariableMissing
+		if !errors.As(err, &missing) || missing.Name != "Email" || missing.Use != "required variable" {
+			t.Errorf("Expected a required variable error for %v, got %v", data, err)
+		}
+	}
+
+	report := tmpl.Validate(Data{})
+	if report.Count(IssueMissingVariable) != 1 || !strings.Contains(report.Issues[0].Message, "Email is required") {
+		t.Errorf("Expected one issue for Email, got %v", report.Issues)
+	}
+}
+
+func TestRichValues(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("Paid: {{.Paid}} Shipped: {{.Shipped}} Tags: {{.Tags}}")
+	doc.AddParagraph("{{.Tags}}")
+	doc.AddParagraph("{{.Specs}}")
+	doc.AddParagraph("{{range .Orders}}")
+	doc.AddParagraph("{{.Item.Options}}")
+	doc.AddParagraph("{{end}}")
+	doc.AddParagraph(`{{.Paid | checkbox "yes" "no"}} {{.Tags | join "/"}}`)
+	table := doc.AddTable(1, 1)
+	table.SetCellText(0, 0, "{{.Tags}}")
+
+	data := Data{
+		"Paid":    true,
+		"Shipped": false,
+		"Tags":    []string{"red", "large"},
+		"Specs":   map[string]interface{}{"Weight": "2 kg", "Color": "Red", "Fragile": true},
+		"Orders":  []Data{{"Options": map[string]interface{}{"Gift": "yes"}}},
+	}
+	result, err := New(doc).Render(data, RenderOptions{StrictMode: true, RichValues: true})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	var texts []string
+	for i := range result.Body.Paragraphs {
+		texts = append(texts, extractParagraphText(&result.Body.Paragraphs[i]))
+	}
+	want := []string{"Paid: ☑ Shipped: ☐ Tags: red, large", "• red", "• large", "Gift: yes", "yes red/large"}
+	if strings.Join(texts, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected paragraphs %q, got %q", want, texts)
+	}
+
+	if len(result.Body.Tables) != 2 {
+		t.Fatalf("Expected the map as a second table, got %d tables", len(result.Body.Tables))
+	}
+	specs := result.Body.Tables[0]
+	if specs.Position != 3 || len(specs.Rows) != 3 {
+		t.Fatalf("Expected a 3-row table before paragraph 3, got %d rows at %d", len(specs.Rows), specs.Position)
+	}
+	if got := cellText(&specs.Rows[0].Cells[0]) + "=" + cellText(&specs.Rows[0].Cells[1]); got != "Color=Red" {
+		t.Errorf("Expected the keys sorted, got %q", got)
+	}
+	if got := cellText(&specs.Rows[1].Cells[1]); got != "☑" {
+		t.Errorf("Expected a checkbox for a boolean, got %q", got)
+	}
+	if cell := result.Body.Tables[1].Rows[0].Cells[0]; len(cell.Content) != 2 || extractParagraphText(&cell.Content[1]) != "• large" {
+		t.Errorf("Expected the list as bulleted paragraphs in the cell, got %+v", cell.Content)
+	}
+
+	plain, err := New(doc).Render(data, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if got := extractParagraphText(&plain.Body.Paragraphs[0]); got != "Paid: true Shipped: false Tags: [red large]" {
+		t.Errorf("Expected plain values without RichValues, got %q", got)
+	}
+}
+
+func TestLoopHelpersAndSeparators(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("{{range .Names}}{{if .IsLast}}and {{end}}{{.Item}}{{separator}}, {{end}}{{end}}.")
+	doc.AddParagraph("{{range .Names}}")
+	doc.AddParagraph("{{.Index}}/{{.Count}} {{.Item}}{{if .IsFirst}} (first){{end}}")
+	doc.AddParagraph("{{separator}}")
+	doc.AddParagraph("---")
+	doc.AddParagraph("{{end}}")
+	doc.AddParagraph("{{end}}")
+	table := doc.AddTable(1, 1)
+	table.SetCellText(0, 0, "{{range .Names}}{{.Item}}{{separator}} | {{end}}{{end}}")
+
+	tmpl := New(doc)
+	result, err := tmpl.Render(Data{"Names": []string{"Ada", "Grace", "Alan"}}, RenderOptions{StrictMode: true})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	var texts []string
+	for i := range result.Body.Paragraphs {
+		texts = append(texts, extractParagraphText(&result.Body.Paragraphs[i]))
+	}
+	want := []string{"Ada, Grace, and Alan.", "0/3 Ada (first)", "---", "1/3 Grace", "---", "2/3 Alan"}
+	if strings.Join(texts, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected paragraphs %q, got %q", want, texts)
+	}
+	if got := cellText(&result.Body.Tables[0].Rows[0].Cells[0]); got != "Ada | Grace | Alan" {
+		t.Errorf("Expected separators between the items in the cell, got %q", got)
+	}
+
+	if report := tmpl.Validate(Data{"Names": []string{"Ada"}}); !report.Valid() {
+		t.Errorf("Expected the template to be valid, got %v", report.Issues)
+	}
+	bad := docx.New()
+	bad.AddParagraph("{{separator}}, {{end}}")
+	if report := New(bad).Validate(Data{}); report.Count(IssueUnbalancedBlock) != 1 {
+		t.Errorf("Expected a separator outside of a loop to be reported, got %v", report.Issues)
+	}
+}
+
+func TestRenderBatch(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("Invoice {{.Number}} for {{.Customer | upper}}")
+	tmpl := New(doc)
+
+	var records []Data
+	for i := 0; i < 50; i++ {
+		records = append(records, Data{"Number": i, "Customer": fmt.Sprintf("customer %d", i)})
+	}
+	opts := DefaultBatchOptions()
+	opts.Workers = 4
+	docs, err := RenderBatch(tmpl, records, opts)
+	if err != nil {
+		t.Fatalf("RenderBatch failed: %v", err)
+	}
+	if len(docs) != len(records) {
+		t.Fatalf("Expected %d documents, got %d", len(records), len(docs))
+	}
+	for i, d := range docs {
+		want := fmt.Sprintf("Invoice %d for CUSTOMER %d", i, i)
+		if got := extractParagraphText(&d.Body.Paragraphs[0]); got != want {
+			t.Errorf("Document %d: expected %q, got %q", i, want, got)
+		}
+	}
+	if got := extractParagraphText(&doc.Body.Paragraphs[0]); !strings.Contains(got, "{{.Number}}") {
+		t.Errorf("Expected the template to be left unchanged, got %q", got)
+	}
+
+	records[7] = Data{"Number": 7}
+	opts.Render.StrictMode = true
+	if _, err := RenderBatch(tmpl, records, opts); err == nil || !strings.Contains(err.Error(), "record 8") {
+		t.Errorf("Expected an error for record 8, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := RenderBatchContext(ctx, tmpl, records, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if docs, err := RenderBatch(tmpl, nil, opts); err != nil || len(docs) != 0 {
+		t.Errorf("Expected no documents for no records, got %d, %v", len(docs), err)
+	}
+}
+
+func BenchmarkRenderBatch(b *testing.B) {
+	doc := docx.New()
+	doc.AddParagraph("Invoice {{.Number}} for {{.Customer}}")
+	doc.AddParagraph("{{range .Lines}}")
+	doc.AddParagraph("{{.Item.Description}}: {{.Item.Amount | currency \"USD\"}}")
+	doc.AddParagraph("{{end}}")
+	doc.AddParagraph("Total: {{.Total | currency \"USD\"}}")
+	tmpl := New(doc)
+
+	records := make([]Data, 100)
+	for i := range records {
+		lines := make([]interface{}, 20)
+		for j := range lines {
+			lines[j] = map[string]interface{}{"Description": fmt.Sprintf("Item %d", j), "Amount": float64(j) * 9.5}
+		}
+		records[i] = Data{"Number": i, "Customer": "Acme", "Lines": lines, "Total": 1805.0}
+	}
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			opts := DefaultBatchOptions()
+			opts.Workers = workers
+			for i := 0; i < b.N; i++ {
+				if _, err := RenderBatch(tmpl, records, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	paths := make([]string, 3)
+	for i := range paths {
+		doc := docx.New()
+		doc.AddParagraph(fmt.Sprintf("Template %d: {{.Name | shout}}", i))
+		paths[i] = filepath.Join(dir, fmt.Sprintf("t%d.docx", i))
+		if err := doc.Save(paths[i]); err != nil {
+			t.Fatalf("Failed to save template: %v", err)
+		}
+	}
+
+	setups := 0
+	opts := DefaultCacheOptions()
+	opts.MaxEntries = 2
+	opts.Setup = func(tmpl *Template) error {
+		setups++
+		tmpl.RegisterFunc("shout", func(value interface{}, args ...interface{}) (interface{}, error) {
+			return strings.ToUpper(toString(value)) + "!", nil
+		})
+		return nil
+	}
+	cache := NewCache(opts)
+
+	first, err := cache.Get(paths[0])
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if again, _ := cache.Get(paths[0]); again != first || setups != 1 {
+		t.Errorf("Expected the cached template, got a new one (%d setups)", setups)
+	}
+	rendered, err := first.Render(Data{"Name": "ada"}, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if text, _ := rendered.GetParagraphText(0); text != "Template 0: ADA!" {
+		t.Errorf("Expected Setup's function to be used, got %q", text)
+	}
+
+	// A changed file is reloaded
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(paths[0], later, later); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	if reloaded, _ := cache.Get(paths[0]); reloaded == first || setups != 2 {
+		t.Error("Expected a modified template to be reloaded")
+	}
+
+	// The least recently used template makes room
+	cache.Get(paths[1])
+	cache.Get(paths[0])
+	cache.Get(paths[2])
+	if cache.Len() != 2 {
+		t.Errorf("Expected 2 cached templates, got %d", cache.Len())
+	}
+	setups = 0
+	cache.Get(paths[0])
+	cache.Get(paths[1])
+	if setups != 1 {
+		t.Errorf("Expected only the evicted template to be reloaded, got %d loads", setups)
+	}
+
+	if _, err := cache.Get(filepath.Join(dir, "missing.docx")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist, got %v", err)
+	}
+	opts.Setup = func(*Template) error { return errors.New("no partials") }
+	failing := NewCache(opts)
+	if _, err := failing.Get(paths[0]); err == nil || failing.Len() != 0 {
+		t.Errorf("Expected a failed setup to be returned and not cached, got %v", err)
+	}
+}
+
+func TestCacheConcurrentGet(t *testing.T) {
+	doc := docx.New()
+	doc.AddParagraph("Hello {{.Name}}")
+	path := filepath.Join(t.TempDir(), "hello.docx")
+	if err := doc.Save(path); err != nil {
+		t.Fatalf("Failed to save template: %v", err)
+	}
+
+	var loads atomic.Int32
+	opts := DefaultCacheOptions()
+	opts.Setup = func(*Template) error {
+		loads.Add(1)
+		return nil
+	}
+	cache := NewCache(opts)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tmpl, err := cache.Get(path)
+			if err != nil {
+				t.Errorf("Get failed: %v", err)
+				return
+			}
+			name := fmt.Sprintf("user %d", i)
+			rendered, err := tmpl.Render(Data{"Name": name}, DefaultOptions())
+			if err != nil {
+				t.Errorf("Render failed: %v", err)
+				return
+			}
+			if text, _ := rendered.GetParagraphText(0); text != "Hello "+name {
+				t.Errorf("Expected %q, got %q", "Hello "+name, text)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := loads.Load(); n != 1 {
+		t.Errorf("Expected the template to be loaded once, got %d", n)
+	}
+}
+
+func BenchmarkCacheGet(b *testing.B) {
+	doc := docx.New()
+	for i := 0; i < 200; i++ {
+		doc.AddParagraph(fmt.Sprintf("Clause %d for {{.Customer}}", i))
+	}
+	path := filepath.Join(b.TempDir(), "contract.docx")
+	if err := doc.Save(path); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("Load", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := Load(path); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Cache", func(b *testing.B) {
+		cache := NewCache(DefaultCacheOptions())
+		for i := 0; i < b.N; i++ {
+			if _, err := cache.Get(path); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,239 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Palaciodiego008/docxsmith/pkg/docx"
+)
+
+// IssueKind classifies the problems Validate finds
+type IssueKind string
+
+const (
+	IssueMissingVariable   IssueKind = "missing-variable"
+	IssueMissingPartial    IssueKind = "missing-partial"
+	IssueUnknownDirective  IssueKind = "unknown-directive"
+	IssueInvalidExpression IssueKind = "invalid-expression"
+	IssueUnbalancedBlock   IssueKind = "unbalanced-block"
+	IssueTypeMismatch      IssueKind = "type-mismatch"
+	IssueRenderError       IssueKind = "render-error"
+)
+
+// Issue is a problem found in a template or its data
+type Issue struct {
+	Kind IssueKind `json:"kind"`
+	// Location is where the problem is, such as "paragraph 3" or
+	// "table 1, row 2, cell 1", or the placeholder involved when found
+	// while rendering
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+// String formats the issue as "location: message (kind)"
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s (%s)", i.Location, i.Message, i.Kind)
+}
+
+// ValidationReport lists the problems found by Validate
+type ValidationReport struct {
+	Issues []Issue
+	seen   map[Issue]bool
+}
+
+// Valid reports whether no problems were found
+func (r *ValidationReport) Valid() bool {
+	return len(r.Issues) == 0
+}
+
+// Count returns the number of issues of a kind
+func (r *ValidationReport) Count(kind IssueKind) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// add records an issue once; a nil report ignores it, so rendering code can
+// report problems whether or not it is validating
+func (r *ValidationReport) add(kind IssueKind, location, format string, args ...interface{}) {
+	if r == nil {
+		return
+	}
+	issue := Issue{Kind: kind, Location: location, Message: fmt.Sprintf(format, args...)}
+	if r.seen[issue] {
+		return
+	}
+	if r.seen == nil {
+		r.seen = make(map[Issue]bool)
+	}
+	r.seen[issue] = true
+	r.Issues = append(r.Issues, issue)
+}
+
+// reportLookup reports a variable that couldn't be looked up: either it is
+// missing or its path goes through a value that has no fields
+func reportLookup(r *ValidationReport, location, what, name string, err error) {
+	if errors.Is(err, errNotAccessible) {
+		r.add(IssueTypeMismatch, location, "%s %s: %v", what, name, err)
+		return
+	}
+	r.add(IssueMissingVariable, location, "%s %s not found", what, name)
+}
+
+// Validate checks the template and data without producing a document. It
+// reports every unknown directive and unbalanced {{if}} or {{range}} block,
+// then renders the template in a dry run to find all missing variables and
+// values of the wrong type, such as a loop over a non-list or a currency
+// filter applied to text. MERGEFIELD fields are checked too. The dry run is
+// skipped when the template's structure is invalid.
+func (t *Template) Validate(data Data) *ValidationReport {
+	report := &ValidationReport{}
+
+	doc := t.doc.Clone()
+	if err := t.expandIncludes(doc, nil); err != nil {
+		report.add(IssueMissingPartial, "template", "%v", err)
+		return report
+	}
+	t.checkStructure(doc, report)
+	if report.Count(IssueUnbalancedBlock) > 0 {
+		return report
+	}
+
+	opts := DefaultOptions()
+	opts.MergeFields = true
+	opts.report = report
+	if _, err := t.Render(data, opts); err != nil {
+		report.add(IssueRenderError, "template", "%v", err)
+	}
+	return report
+}
+
+// directivePattern matches anything written between {{ and }}
+var directivePattern = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+
+// blockCheck tracks the blocks open while checking a template's structure
+type blockCheck struct {
+	t      *Template
+	report *ValidationReport
+	open   []openBlock
+}
+
+// openBlock is a block whose {{end}} hasn't been found yet
+type openBlock struct {
+	directive string
+	location  string
+}
+
+// checkStructure reports unknown directives and unbalanced blocks. Blocks
+// may span body paragraphs; in tables they must close within their row,
+// except for row loops.
+func (t *Template) checkStructure(doc *docx.Document, report *ValidationReport) {
+	body := &blockCheck{t: t, report: report}
+	for i := range doc.Body.Paragraphs {
+		body.check(&doc.Body.Paragraphs[i], fmt.Sprintf("paragraph %d", i+1))
+	}
+	body.unclosed()
+
+	for i := range doc.Body.Tables {
+		for r, row := range doc.Body.Tables[i].Rows {
+			rowCheck := &blockCheck{t: t, report: report}
+			for c := range row.Cells {
+				for p := range row.Cells[c].Content {
+					rowCheck.check(&row.Cells[c].Content[p], fmt.Sprintf("table %d, row %d, cell %d", i+1, r+1, c+1))
+				}
+			}
+
+			// A row loop may leave its range open to repeat the row
+			if len(rowCheck.open) == 1 && len(row.Cells) > 0 && rangePattern.MatchString(rowCheck.open[0].directive) &&
+				strings.HasPrefix(strings.TrimSpace(cellText(&row.Cells[0])), rowCheck.open[0].directive) {
+				continue
+			}
+			rowCheck.unclosed()
+		}
+	}
+}
+
+// check checks the directives of a paragraph
+func (c *blockCheck) check(para *docx.Paragraph, location string) {
+	for _, directive := range directivePattern.FindAllString(extractParagraphText(para), -1) {
+		inner := strings.TrimSpace(directive[2 : len(directive)-2])
+		switch {
+		case strings.HasPrefix(inner, "if ") || inner == "if":
+			if strings.TrimSpace(strings.TrimPrefix(inner, "if")) == "" {
+				c.report.add(IssueInvalidExpression, location, "%s has no condition", directive)
+			} else if _, err := tokenizeExpression(inner[3:]); err != nil {
+				c.report.add(IssueInvalidExpression, location, "%s: %v", directive, err)
+			}
+			c.open = append(c.open, openBlock{directive, location})
+		case strings.HasPrefix(inner, "range ") || inner == "range":
+			if !rangePattern.MatchString(directive) {
+				c.report.add(IssueUnknownDirective, location, "%s should be {{range .Collection}}", directive)
+			}
+			c.open = append(c.open, openBlock{directive, location})
+		case inner == "separator":
+			if !slices.ContainsFunc(c.open, func(b openBlock) bool { return strings.HasPrefix(b.directive, "{{range") }) {
+				c.report.add(IssueUnbalancedBlock, location, "%s outside of a {{range}} block", directive)
+			}
+			c.open = append(c.open, openBlock{directive, location})
+		case inner == "else" || strings.HasPrefix(inner, "else if "):
+			if len(c.open) == 0 || !strings.HasPrefix(c.open[len(c.open)-1].directive, "{{if") {
+				c.report.add(IssueUnbalancedBlock, location, "%s outside of an {{if}} block", directive)
+			}
+		case inner == "end":
+			if len(c.open) == 0 {
+				c.report.add(IssueUnbalancedBlock, location, "{{end}} without a matching {{if}} or {{range}}")
+			} else {
+				c.open = c.open[:len(c.open)-1]
+			}
+		case strings.HasPrefix(inner, "include"):
+			// Includes were expanded, so this one shares its paragraph
+			c.report.add(IssueUnknownDirective, location, "%s must be alone in its paragraph", directive)
+		default:
+			m := variablePattern.FindStringSubmatch(directive)
+			if m == nil || m[0] != directive {
+				c.report.add(IssueUnknownDirective, location, "unknown directive %s", directive)
+				continue
+			}
+			c.checkFilters(m[2], directive, location)
+		}
+	}
+}
+
+// checkFilters reports the unknown filters of a pipeline
+func (c *blockCheck) checkFilters(pipeline, directive, location string) {
+	if pipeline == "" {
+		return
+	}
+	tokens, err := tokenizeExpression(pipeline)
+	if err != nil {
+		c.report.add(IssueInvalidExpression, location, "%s: %v", directive, err)
+		return
+	}
+	for i, tok := range tokens {
+		if tok.kind != '|' {
+			continue
+		}
+		if i+1 >= len(tokens) || tokens[i+1].kind != 'w' {
+			c.report.add(IssueInvalidExpression, location, "%s: missing filter name", directive)
+			continue
+		}
+		if _, ok := c.t.filter(tokens[i+1].text, nil); !ok {
+			c.report.add(IssueUnknownDirective, location, "%s: unknown filter %q", directive, tokens[i+1].text)
+		}
+	}
+}
+
+// unclosed reports the blocks left open
+func (c *blockCheck) unclosed() {
+	for _, block := range c.open {
+		c.report.add(IssueUnbalancedBlock, block.location, "%s has no matching {{end}}", block.directive)
+	}
+	c.open = nil
+}